@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains definitions of Capacity Buffer related objects.
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +kubebuilder:storageversions
+// +kubebuilder:resource:shortName=cb;cbs
+
+// CapacityBuffer is a way to keep spare capacity in the cluster, so that
+// new pods can be scheduled without waiting for a scale-up. Cluster Autoscaler
+// treats the buffer as a number of always pending virtual pods requesting the
+// resources of a single unit of the buffer, and adds nodes for the units that
+// don't fit on the existing ones.
+//
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".spec.replicas"
+// +kubebuilder:printcolumn:name="Provisioned",type="integer",JSONPath=".status.provisionedReplicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CapacityBuffer struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	//
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains specification of the CapacityBuffer object.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#spec-and-status.
+	//
+	// +kubebuilder:validation:Required
+	Spec CapacityBufferSpec `json:"spec"`
+	// Status of the CapacityBuffer. CA constantly reconciles this field.
+	//
+	// +optional
+	Status CapacityBufferStatus `json:"status,omitempty"`
+}
+
+// CapacityBufferList is a object for list of CapacityBuffer.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CapacityBufferList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	//
+	// +optional
+	metav1.ListMeta `json:"metadata"`
+	// Items, list of CapacityBuffer returned from API.
+	//
+	// +optional
+	Items []CapacityBuffer `json:"items"`
+}
+
+// CapacityBufferSpec is a specification of the spare capacity kept in the
+// cluster.
+type CapacityBufferSpec struct {
+	// Replicas is the number of units of capacity kept in the buffer.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Resources are the resources of a single unit of the buffer, as if
+	// requested by a pod.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinProperties=1
+	Resources v1.ResourceList `json:"resources"`
+
+	// NodeSelector restricts the nodes the buffer is kept on to the ones
+	// matching all the labels, as if it was the node selector of a pod.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// CapacityBufferStatus represents the status of the CapacityBuffer.
+type CapacityBufferStatus struct {
+	// ProvisionedReplicas is the number of units of the buffer that fit on
+	// the nodes of the cluster. Nodes that are being provisioned are not counted.
+	//
+	// +optional
+	ProvisionedReplicas int32 `json:"provisionedReplicas"`
+
+	// Conditions represent the observations of a Capacity Buffer's
+	// current state. Cluster Autoscaler sets the Provisioned condition.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// The following constants list all currently available Conditions Type for
+// CapacityBuffer.
+const (
+	// Provisioned indicates that all the units of the buffer fit on the
+	// nodes of the cluster.
+	Provisioned string = "Provisioned"
+)
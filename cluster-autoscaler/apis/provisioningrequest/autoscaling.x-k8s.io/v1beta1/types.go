@@ -197,6 +197,10 @@ const (
 	// ProvisioningClassCheckCapacity denotes that CA will check if current cluster state can fulfill this request,
 	// and reserve the capacity for a specified time.
 	ProvisioningClassCheckCapacity string = "check-capacity.autoscaling.x-k8s.io"
+	// ProvisioningClassCheckCapacityWithHold denotes that CA will check if current cluster state can fulfill this request,
+	// and hold the capacity until the pods consuming the request are created or the hold expires. The hold time can be
+	// set in seconds by 'HoldTTLSeconds' key in 'Parameters'.
+	ProvisioningClassCheckCapacityWithHold string = "check-capacity-with-hold.autoscaling.x-k8s.io"
 	// ProvisioningClassBestEffortAtomicScaleUp denotes that CA try to provision the capacity
 	// in an atomic manner.
 	ProvisioningClassBestEffortAtomicScaleUp string = "best-effort-atomic-scale-up.autoscaling.x-k8s.io"
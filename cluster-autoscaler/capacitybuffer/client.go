@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	capacityBufferClientCallTimeout = 4 * time.Second
+)
+
+// CapacityBufferGVR identifies the CapacityBuffer custom resource.
+var CapacityBufferGVR = v1alpha1.SchemeGroupVersion.WithResource("capacitybuffers")
+
+// CapacityBufferClient represents client for v1alpha1 CapacityBuffer CRD.
+type CapacityBufferClient struct {
+	client dynamic.Interface
+	lister cache.GenericLister
+}
+
+// NewCapacityBufferClient configures and returns a CapacityBufferClient.
+func NewCapacityBufferClient(client dynamic.Interface, stopChannel <-chan struct{}) (*CapacityBufferClient, error) {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 1*time.Hour)
+	lister := factory.ForResource(CapacityBufferGVR).Lister()
+	factory.Start(stopChannel)
+	informersSynced := factory.WaitForCacheSync(stopChannel)
+	for _, synced := range informersSynced {
+		if !synced {
+			return nil, fmt.Errorf("can't create Capacity Buffer lister")
+		}
+	}
+	klog.V(2).Info("Successful initial Capacity Buffer sync")
+	return &CapacityBufferClient{
+		client: client,
+		lister: lister,
+	}, nil
+}
+
+// CapacityBuffers gets all CapacityBuffer CRs.
+func (c *CapacityBufferClient) CapacityBuffers() ([]*v1alpha1.CapacityBuffer, error) {
+	objects, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching capacityBuffers: %w", err)
+	}
+	cbs := make([]*v1alpha1.CapacityBuffer, 0, len(objects))
+	for _, object := range objects {
+		cb, err := fromUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+		cbs = append(cbs, cb)
+	}
+	return cbs, nil
+}
+
+// UpdateCapacityBufferStatus updates the status of the given CapacityBuffer CR and returns the updated
+// instance or the original one in case of an error.
+func (c *CapacityBufferClient) UpdateCapacityBufferStatus(cb *v1alpha1.CapacityBuffer) (*v1alpha1.CapacityBuffer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), capacityBufferClientCallTimeout)
+	defer cancel()
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cb)
+	if err != nil {
+		return cb, err
+	}
+	updated, err := c.client.Resource(CapacityBufferGVR).Namespace(cb.Namespace).UpdateStatus(ctx, &unstructured.Unstructured{Object: object}, metav1.UpdateOptions{})
+	if err != nil {
+		return cb, err
+	}
+	updatedCb, err := fromUnstructured(updated)
+	if err != nil {
+		return cb, err
+	}
+	klog.V(4).Infof("Updated CapacityBuffer %s/%s, status: %q,", updatedCb.Namespace, updatedCb.Name, updatedCb.Status)
+	return updatedCb, nil
+}
+
+func fromUnstructured(object runtime.Object) (*v1alpha1.CapacityBuffer, error) {
+	u, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected CapacityBuffer object type %T", object)
+	}
+	cb := &v1alpha1.CapacityBuffer{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cb); err != nil {
+		return nil, fmt.Errorf("failed to convert CapacityBuffer %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+	}
+	return cb, nil
+}
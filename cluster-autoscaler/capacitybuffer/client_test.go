@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+)
+
+func testCapacityBuffer(namespace, name string, replicas int32) *v1alpha1.CapacityBuffer {
+	return &v1alpha1.CapacityBuffer{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			UID:       "cb-uid",
+		},
+		Spec: v1alpha1.CapacityBufferSpec{
+			Replicas:     replicas,
+			Resources:    apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("4Gi")},
+			NodeSelector: map[string]string{"pool": "buffer"},
+		},
+	}
+}
+
+func TestCapacityBufferClient(t *testing.T) {
+	client, dynamicClient := NewFakeCapacityBufferClient(t, testCapacityBuffer("ns", "cb1", 3), testCapacityBuffer("other", "cb2", 1))
+
+	cbs, err := client.CapacityBuffers()
+	assert.NoError(t, err)
+	assert.Len(t, cbs, 2)
+	var cb *v1alpha1.CapacityBuffer
+	for _, c := range cbs {
+		if c.Name == "cb1" {
+			cb = c
+		}
+	}
+	if assert.NotNil(t, cb) {
+		assert.Equal(t, "ns", cb.Namespace)
+		assert.Equal(t, int32(3), cb.Spec.Replicas)
+		assert.Equal(t, resource.MustParse("4Gi"), cb.Spec.Resources[apiv1.ResourceMemory])
+
+		cb.Status.ProvisionedReplicas = 2
+		updated, err := client.UpdateCapacityBufferStatus(cb)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), updated.Status.ProvisionedReplicas)
+		assert.Equal(t, int32(2), GetCapacityBuffer(t, dynamicClient, "ns", "cb1").Status.ProvisionedReplicas)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// CapacityBufferPodAnnotationKey is a key used to annotate the virtual pods of a CapacityBuffer, set to its name.
+	CapacityBufferPodAnnotationKey = "cluster-autoscaler.kubernetes.io/capacity-buffer"
+	// capacityBufferKind is the kind of the CapacityBuffer objects.
+	capacityBufferKind = "CapacityBuffer"
+)
+
+// PodsForCapacityBuffer returns the always pending virtual pods, one per unit of the
+// CapacityBuffer. They are never created in the cluster.
+func PodsForCapacityBuffer(cb *v1alpha1.CapacityBuffer) []*apiv1.Pod {
+	pods := make([]*apiv1.Pod, 0, cb.Spec.Replicas)
+	for i := 0; i < int(cb.Spec.Replicas); i++ {
+		name := fmt.Sprintf("capacity-buffer-%s-%d", cb.Name, i)
+		pods = append(pods, &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cb.Namespace,
+				UID:       types.UID(fmt.Sprintf("%s/%s", cb.Namespace, name)),
+				Annotations: map[string]string{
+					// Scale-down keeps room for the pods like for node group headroom placeholders.
+					pod_util.HeadroomPlaceholderAnnotationKey: cb.Name,
+					CapacityBufferPodAnnotationKey:            cb.Name,
+				},
+				OwnerReferences:   []metav1.OwnerReference{ownerReference(cb)},
+				CreationTimestamp: cb.CreationTimestamp,
+			},
+			Spec: apiv1.PodSpec{
+				NodeSelector: cb.Spec.NodeSelector,
+				Containers: []apiv1.Container{{
+					Name:      "buffer",
+					Resources: apiv1.ResourceRequirements{Requests: cb.Spec.Resources.DeepCopy()},
+				}},
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+			},
+		})
+	}
+	return pods
+}
+
+// ownerReference injects owner reference that points to the CapacityBuffer object.
+// This allows CA to group the pods as coming from one controller.
+func ownerReference(cb *v1alpha1.CapacityBuffer) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       capacityBufferKind,
+		Name:       cb.Name,
+		UID:        cb.UID,
+		Controller: ptr.To(true),
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+func TestPodsForCapacityBuffer(t *testing.T) {
+	cb := testCapacityBuffer("ns", "cb1", 3)
+	pods := PodsForCapacityBuffer(cb)
+	assert.Len(t, pods, 3)
+	names := make(map[string]bool)
+	for _, pod := range pods {
+		names[pod.Name] = true
+		assert.Equal(t, "ns", pod.Namespace)
+		assert.Equal(t, "cb1", pod.Annotations[CapacityBufferPodAnnotationKey])
+		assert.True(t, pod_util.IsHeadroomPlaceholderPod(pod))
+		assert.Equal(t, cb.Spec.NodeSelector, pod.Spec.NodeSelector)
+		assert.Equal(t, cb.Spec.Resources, pod.Spec.Containers[0].Resources.Requests)
+		if assert.Len(t, pod.OwnerReferences, 1) {
+			assert.Equal(t, cb.UID, pod.OwnerReferences[0].UID)
+			assert.True(t, *pod.OwnerReferences[0].Controller)
+		}
+	}
+	assert.Len(t, names, 3)
+
+	cb.Spec.Replicas = 0
+	assert.Empty(t, PodsForCapacityBuffer(cb))
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// NewFakeCapacityBufferClient mock CapacityBufferClient for tests. The fake dynamic client it
+// uses is returned as well, to check the objects written by the client.
+func NewFakeCapacityBufferClient(t *testing.T, cbs ...*v1alpha1.CapacityBuffer) (*CapacityBufferClient, *fake.FakeDynamicClient) {
+	t.Helper()
+	objects := make([]runtime.Object, 0, len(cbs))
+	for _, cb := range cbs {
+		cb = cb.DeepCopy()
+		cb.APIVersion = v1alpha1.SchemeGroupVersion.String()
+		cb.Kind = capacityBufferKind
+		object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cb)
+		if err != nil {
+			t.Fatalf("Failed to convert CapacityBuffer %s/%s to unstructured: %v", cb.Namespace, cb.Name, err)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: object})
+	}
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		CapacityBufferGVR: "CapacityBufferList",
+	}, objects...)
+	client, err := NewCapacityBufferClient(dynamicClient, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("Failed to create Capacity Buffer client. Error was: %v", err)
+	}
+	return client, dynamicClient
+}
+
+// GetCapacityBuffer returns the CapacityBuffer stored in the fake dynamic client.
+func GetCapacityBuffer(t *testing.T, dynamicClient *fake.FakeDynamicClient, namespace, name string) *v1alpha1.CapacityBuffer {
+	t.Helper()
+	object, err := dynamicClient.Tracker().Get(CapacityBufferGVR, namespace, name)
+	if err != nil {
+		t.Fatalf("Failed to get CapacityBuffer %s/%s: %v", namespace, name, err)
+	}
+	cb, err := fromUnstructured(object)
+	if err != nil {
+		t.Fatalf("Failed to convert CapacityBuffer %s/%s: %v", namespace, name, err)
+	}
+	return cb
+}
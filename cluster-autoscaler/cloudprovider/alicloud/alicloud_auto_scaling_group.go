@@ -74,6 +74,11 @@ func (asg *Asg) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity is not implemented.
+func (asg *Asg) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
 // DecreaseTargetSize decreases the target size of the node group. This function
 // doesn't permit to delete any existing node and can be used only to reduce the
 // request for new nodes that have not been yet fulfilled. Delta should be negative.
@@ -71,6 +71,8 @@ type asg struct {
 	maxSize        int
 	curSize        int
 	lastUpdateTime time.Time
+	// warmPoolSize is the number of instances in the warm pool of the ASG.
+	warmPoolSize int
 
 	AvailabilityZones       []string
 	LaunchConfigurationName string
@@ -149,6 +151,7 @@ func (m *asgCache) register(asg *asg) *asg {
 		}
 
 		existing.curSize = asg.curSize
+		existing.warmPoolSize = asg.warmPoolSize
 
 		// Those information are mainly required to create templates when scaling
 		// from zero
@@ -544,6 +547,7 @@ func (m *asgCache) buildAsgFromAWS(g *autoscaling.Group) (*asg, error) {
 		maxSize: spec.MaxSize,
 
 		curSize:                 int(aws.Int64Value(g.DesiredCapacity)),
+		warmPoolSize:            int(aws.Int64Value(g.WarmPoolSize)),
 		AvailabilityZones:       aws.StringValueSlice(g.AvailabilityZones),
 		LaunchConfigurationName: aws.StringValue(g.LaunchConfigurationName),
 		Tags:                    g.Tags,
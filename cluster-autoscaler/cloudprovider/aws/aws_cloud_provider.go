@@ -285,6 +285,12 @@ func (ng *AwsNodeGroup) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity returns the number of instances in the warm pool of the ASG, which are launched before the ASG
+// launches new instances.
+func (ng *AwsNodeGroup) WarmCapacity() (int, error) {
+	return ng.asg.warmPoolSize, nil
+}
+
 // DecreaseTargetSize decreases the target size of the node group. This function
 // doesn't permit to delete any existing node and can be used only to reduce the
 // request for new nodes that have not been yet fulfilled. Delta should be negative.
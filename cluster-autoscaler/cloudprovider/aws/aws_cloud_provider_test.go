@@ -329,6 +329,35 @@ func TestTargetSize(t *testing.T) {
 	a.AssertNumberOfCalls(t, "DescribeAutoScalingGroupsPages", 1)
 }
 
+func TestWarmCapacity(t *testing.T) {
+	a := &autoScalingMock{}
+	provider := testProvider(t, newTestAwsManagerWithAsgs(t, a, nil, []string{"1:5:test-asg"}))
+	asgs := provider.NodeGroups()
+
+	a.On("DescribeAutoScalingGroupsPages",
+		&autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: aws.StringSlice([]string{"test-asg"}),
+			MaxRecords:            aws.Int64(maxRecordsReturnedByAPI),
+		},
+		mock.AnythingOfType("func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool"),
+	).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool)
+		output := testNamedDescribeAutoScalingGroupsOutput("test-asg", 2, "test-instance-id", "second-test-instance-id")
+		output.AutoScalingGroups[0].WarmPoolSize = aws.Int64(3)
+		fn(output, false)
+	}).Return(nil)
+
+	warmCapacity, err := asgs[0].WarmCapacity()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, warmCapacity)
+
+	provider.Refresh()
+
+	warmCapacity, err = asgs[0].WarmCapacity()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, warmCapacity)
+}
+
 func TestIncreaseSize(t *testing.T) {
 	a := &autoScalingMock{}
 	provider := testProvider(t, newTestAwsManagerWithAsgs(t, a, nil, []string{"1:5:test-asg"}))
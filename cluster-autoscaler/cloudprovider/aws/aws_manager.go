@@ -19,7 +19,6 @@ limitations under the License.
 package aws
 
 import (
-	"errors"
 	"fmt"
 	"math/rand"
 	"regexp"
@@ -257,6 +256,24 @@ func (m *AwsManager) GetAsgOptions(asg asg, defaults config.NodeGroupAutoscaling
 		}
 	}
 
+	if stringOpt, found := options[config.DefaultScaleDownDelayAfterAddKey]; found {
+		if opt, err := time.ParseDuration(stringOpt); err != nil {
+			klog.Warningf("failed to convert asg %s %s tag to duration: %v",
+				asg.Name, config.DefaultScaleDownDelayAfterAddKey, err)
+		} else {
+			defaults.ScaleDownDelayAfterAdd = opt
+		}
+	}
+
+	if stringOpt, found := options[config.DefaultMaxNewNodesPerMinuteKey]; found {
+		if opt, err := strconv.Atoi(stringOpt); err != nil {
+			klog.Warningf("failed to convert asg %s %s tag to int: %v",
+				asg.Name, config.DefaultMaxNewNodesPerMinuteKey, err)
+		} else {
+			defaults.MaxNewNodesPerMinute = opt
+		}
+	}
+
 	return &defaults
 }
 
@@ -522,33 +539,24 @@ func parseASGAutoDiscoverySpecs(o cloudprovider.NodeGroupDiscoveryOptions) ([]as
 	return cfgs, nil
 }
 
+// asgAutoDiscoveryScheme is the scheme of the ASG auto-discovery definitions, asg:tag=<key>[=<value>][,<key>[=<value>]...].
+var asgAutoDiscoveryScheme = cloudprovider.AutoDiscoveryScheme{
+	Discoverer: autoDiscovererTypeASG,
+	ListKey:    asgAutoDiscovererKeyTag,
+}
+
+func init() {
+	cloudprovider.RegisterAutoDiscoveryScheme(cloudprovider.AwsProviderName, asgAutoDiscoveryScheme)
+}
+
 func parseASGAutoDiscoverySpec(spec string) (asgAutoDiscoveryConfig, error) {
 	cfg := asgAutoDiscoveryConfig{}
 
-	tokens := strings.SplitN(spec, ":", 2)
-	if len(tokens) != 2 {
-		return cfg, fmt.Errorf("invalid node group auto discovery spec specified via --node-group-auto-discovery: %s", spec)
-	}
-	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeASG {
-		return cfg, fmt.Errorf("unsupported discoverer specified: %s", discoverer)
-	}
-	param := tokens[1]
-	kv := strings.SplitN(param, "=", 2)
-	if len(kv) != 2 {
-		return cfg, fmt.Errorf("invalid key=value pair %s", kv)
-	}
-	k, v := kv[0], kv[1]
-	if k != asgAutoDiscovererKeyTag {
-		return cfg, fmt.Errorf("unsupported parameter key \"%s\" is specified for discoverer \"%s\". The only supported key is \"%s\"", k, discoverer, asgAutoDiscovererKeyTag)
-	}
-	if v == "" {
-		return cfg, errors.New("tag value not supplied")
-	}
-	p := strings.Split(v, ",")
-	if len(p) == 0 {
-		return cfg, fmt.Errorf("invalid ASG tag for auto discovery specified: ASG tag must not be empty")
+	parsed, err := cloudprovider.ParseAutoDiscoverySpec(spec, asgAutoDiscoveryScheme)
+	if err != nil {
+		return cfg, err
 	}
+	p := strings.Split(parsed.Params[asgAutoDiscovererKeyTag], ",")
 	cfg.Tags = make(map[string]string, len(p))
 	for _, label := range p {
 		lp := strings.SplitN(label, "=", 2)
@@ -179,6 +179,8 @@ func TestGetAsgOptions(t *testing.T) {
 				config.DefaultScaleDownGpuUtilizationThresholdKey: "0.7",
 				config.DefaultScaleDownUnreadyTimeKey:             "25m",
 				config.DefaultIgnoreDaemonSetsUtilizationKey:      "true",
+				config.DefaultScaleDownDelayAfterAddKey:           "2m",
+				config.DefaultMaxNewNodesPerMinuteKey:             "20",
 			},
 			expected: &config.NodeGroupAutoscalingOptions{
 				ScaleDownUtilizationThreshold:    0.42,
@@ -186,6 +188,8 @@ func TestGetAsgOptions(t *testing.T) {
 				ScaleDownUnneededTime:            time.Hour,
 				ScaleDownUnreadyTime:             25 * time.Minute,
 				IgnoreDaemonSetsUtilization:      true,
+				ScaleDownDelayAfterAdd:           2 * time.Minute,
+				MaxNewNodesPerMinute:             20,
 			},
 		},
 		{
@@ -17,15 +17,22 @@ limitations under the License.
 package azure
 
 import (
-	"fmt"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
-	"strings"
 )
 
 const (
 	autoDiscovererTypeLabel = "label"
 )
 
+// labelAutoDiscoveryScheme is the scheme of the label auto-discovery definitions, label:<key>=<value>[,<key>=<value>...].
+var labelAutoDiscoveryScheme = cloudprovider.AutoDiscoveryScheme{
+	Discoverer: autoDiscovererTypeLabel,
+}
+
+func init() {
+	cloudprovider.RegisterAutoDiscoveryScheme(cloudprovider.AzureProviderName, labelAutoDiscoveryScheme)
+}
+
 // A labelAutoDiscoveryConfig specifies how to auto-discover Azure node groups.
 type labelAutoDiscoveryConfig struct {
 	// Key-values to match on.
@@ -48,31 +55,11 @@ func ParseLabelAutoDiscoverySpecs(o cloudprovider.NodeGroupDiscoveryOptions) ([]
 
 // parseLabelAutoDiscoverySpec parses a single spec and returns the corresponding node group spec.
 func parseLabelAutoDiscoverySpec(spec string) (labelAutoDiscoveryConfig, error) {
-	cfg := labelAutoDiscoveryConfig{
-		Selector: make(map[string]string),
-	}
-
-	tokens := strings.Split(spec, ":")
-	if len(tokens) != 2 {
-		return cfg, fmt.Errorf("spec \"%s\" should be discoverer:key=value,key=value", spec)
-	}
-	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeLabel {
-		return cfg, fmt.Errorf("unsupported discoverer specified: %s", discoverer)
-	}
-
-	for _, arg := range strings.Split(tokens[1], ",") {
-		kv := strings.Split(arg, "=")
-		if len(kv) != 2 {
-			return cfg, fmt.Errorf("invalid key=value pair %s", kv)
-		}
-		k, v := kv[0], kv[1]
-		if k == "" || v == "" {
-			return cfg, fmt.Errorf("empty value not allowed in key=value tag pairs")
-		}
-		cfg.Selector[k] = v
+	parsed, err := cloudprovider.ParseAutoDiscoverySpec(spec, labelAutoDiscoveryScheme)
+	if err != nil {
+		return labelAutoDiscoveryConfig{}, err
 	}
-	return cfg, nil
+	return labelAutoDiscoveryConfig{Selector: parsed.Params}, nil
 }
 
 func matchDiscoveryConfig(labels map[string]*string, configs []labelAutoDiscoveryConfig) bool {
@@ -282,6 +282,9 @@ func (m *AzureManager) GetScaleSetOptions(scaleSetName string, defaults config.N
 	if opt, ok := getDurationOption(options, scaleSetName, config.DefaultScaleDownUnreadyTimeKey); ok {
 		defaults.ScaleDownUnreadyTime = opt
 	}
+	if opt, ok := getDurationOption(options, scaleSetName, config.DefaultScaleDownDelayAfterAddKey); ok {
+		defaults.ScaleDownDelayAfterAdd = opt
+	}
 
 	return &defaults
 }
@@ -315,6 +315,11 @@ func (scaleSet *ScaleSet) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity is not implemented.
+func (scaleSet *ScaleSet) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
 // GetScaleSetVms returns list of nodes for the given scale set.
 func (scaleSet *ScaleSet) GetScaleSetVms() ([]compute.VirtualMachineScaleSetVM, *retry.Error) {
 	klog.V(4).Infof("GetScaleSetVms: starts")
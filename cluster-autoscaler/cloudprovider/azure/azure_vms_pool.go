@@ -177,3 +177,8 @@ func (agentPool *VMsPool) TemplateNodeInfo() (*schedulerframework.NodeInfo, erro
 func (agentPool *VMsPool) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
+
+// WarmCapacity is not implemented.
+func (agentPool *VMsPool) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
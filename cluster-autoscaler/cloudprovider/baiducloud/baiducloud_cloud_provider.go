@@ -283,6 +283,11 @@ func (asg *Asg) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity is not implemented.
+func (asg *Asg) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
 // DeleteNodes deletes nodes from this node group. Error is returned either on
 // failure or if the given node doesn't belong to this node group. This function
 // should wait until node group size is updated. Implementation required.
@@ -43,6 +43,10 @@ func NewCloudProvider(opts config.AutoscalingOptions, informerFactory informers.
 		return nil
 	}
 
+	if err := cloudprovider.ValidateAutoDiscoverySpecs(opts.CloudProviderName, do.NodeGroupAutoDiscoverySpecs); err != nil {
+		klog.Fatalf("Failed to validate --node-group-auto-discovery: %v", err)
+	}
+
 	provider := buildCloudProvider(opts, do, rl, informerFactory)
 	if provider != nil {
 		return provider
@@ -190,9 +190,17 @@ type NodeGroup interface {
 	//   as a result of this call. The cloud provider is responsible for ensuring that before returning from the method.
 	// Implementation is optional. If implemented, CA will take advantage of the method while scaling up
 	// GenericScaleUp ProvisioningClass, guaranteeing that all instances required for such a ProvisioningRequest
-	// are provisioned atomically.
+	// are provisioned atomically, and while scaling up the node groups of a gang (--node-group-gang). If an
+	// atomic scale-up spans several node groups and one of them fails, CA decreases the target size of the
+	// node groups already scaled up with DecreaseTargetSize.
 	AtomicIncreaseSize(delta int) error
 
+	// WarmCapacity returns the number of nodes the node group can add from pre-warmed capacity, e.g. instances
+	// in a warm pool, stopped instances or preserved boot volumes, which become ready faster than new instances.
+	// Implementation is optional. If implemented, the warm-capacity expander prefers node groups that can add
+	// the nodes of a scale-up from warm capacity.
+	WarmCapacity() (int, error)
+
 	// DeleteNodes deletes nodes from this node group. Error is returned either on
 	// failure or if the given node doesn't belong to this node group. This function
 	// should wait until node group size is updated. Implementation required.
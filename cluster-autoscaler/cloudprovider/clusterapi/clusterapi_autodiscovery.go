@@ -18,16 +18,32 @@ package clusterapi
 
 import (
 	"fmt"
-	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 )
 
+// clusterAPIAutoDiscoveryScheme is the scheme of the auto-discovery definitions,
+// clusterapi:[clusterName=<name>][,namespace=<namespace>][,<label>=<value>...].
+var clusterAPIAutoDiscoveryScheme = cloudprovider.AutoDiscoveryScheme{
+	Discoverer:       autoDiscovererTypeClusterAPI,
+	AllowEmptyValues: true,
+	AllowNoParams:    true,
+	Validate: func(spec cloudprovider.AutoDiscoverySpec) error {
+		_, err := clusterAPIAutoDiscoveryConfigFromSpec(spec)
+		return err
+	},
+}
+
+func init() {
+	cloudprovider.RegisterAutoDiscoveryScheme(cloudprovider.ClusterAPIProviderName, clusterAPIAutoDiscoveryScheme)
+}
+
 type clusterAPIAutoDiscoveryConfig struct {
 	clusterName   string
 	namespace     string
@@ -35,29 +51,19 @@ type clusterAPIAutoDiscoveryConfig struct {
 }
 
 func parseAutoDiscoverySpec(spec string) (*clusterAPIAutoDiscoveryConfig, error) {
-	cfg := &clusterAPIAutoDiscoveryConfig{
-		labelSelector: labels.NewSelector(),
+	parsed, err := cloudprovider.ParseAutoDiscoverySpec(spec, clusterAPIAutoDiscoveryScheme)
+	if err != nil {
+		return &clusterAPIAutoDiscoveryConfig{labelSelector: labels.NewSelector()}, errors.NewAutoscalerError(errors.ConfigurationError, err.Error())
 	}
+	return clusterAPIAutoDiscoveryConfigFromSpec(parsed)
+}
 
-	tokens := strings.Split(spec, ":")
-	if len(tokens) != 2 {
-		return cfg, errors.NewAutoscalerError(errors.ConfigurationError, fmt.Sprintf("spec \"%s\" should be discoverer:key=value,key=value", spec))
-	}
-	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeClusterAPI {
-		return cfg, errors.NewAutoscalerError(errors.ConfigurationError, fmt.Sprintf("unsupported discoverer specified: %s", discoverer))
+func clusterAPIAutoDiscoveryConfigFromSpec(spec cloudprovider.AutoDiscoverySpec) (*clusterAPIAutoDiscoveryConfig, error) {
+	cfg := &clusterAPIAutoDiscoveryConfig{
+		labelSelector: labels.NewSelector(),
 	}
 
-	for _, arg := range strings.Split(tokens[1], ",") {
-		if len(arg) == 0 {
-			continue
-		}
-		kv := strings.Split(arg, "=")
-		if len(kv) != 2 {
-			return cfg, errors.NewAutoscalerError(errors.ConfigurationError, fmt.Sprintf("invalid key=value pair %s", kv))
-		}
-		k, v := kv[0], kv[1]
-
+	for k, v := range spec.Params {
 		switch k {
 		case autoDiscovererClusterNameKey:
 			cfg.clusterName = v
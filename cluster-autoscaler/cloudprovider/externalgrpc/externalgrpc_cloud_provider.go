@@ -36,6 +36,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	klog "k8s.io/klog/v2"
@@ -333,9 +334,38 @@ func BuildExternalGrpc(
 	if err != nil {
 		klog.Fatalf("Could not create gRPC client: %v", err)
 	}
+	if err := registerNodeInfoComparator(client, grpcTimeout); err != nil {
+		klog.Warningf("Using the generic node info comparator, failed to get node info comparator options: %v", err)
+	}
 	return newExternalGrpcCloudProvider(client, grpcTimeout, rl)
 }
 
+// registerNodeInfoComparator registers the node info comparator of the external cloud provider, which ignores
+// the labels and resources it returns in addition to the generic ones. Nothing is registered if the
+// cloud provider doesn't implement NodeInfoComparatorOptions.
+func registerNodeInfoComparator(client protos.CloudProviderClient, grpcTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcTimeout)
+	defer cancel()
+	klog.V(5).Info("Performing gRPC call NodeInfoComparatorOptions")
+	res, err := client.NodeInfoComparatorOptions(ctx, &protos.NodeInfoComparatorOptionsRequest{})
+	if err != nil {
+		st, ok := status.FromError(err)
+		if ok && st.Code() == codes.Unimplemented {
+			return nil
+		}
+		klog.V(1).Infof("Error on gRPC call NodeInfoComparatorOptions: %v", err)
+		return err
+	}
+	ignoredLabels := res.GetIgnoredLabels()
+	ignoredResources := res.GetIgnoredResources()
+	nodegroupset.RegisterNodeInfoComparatorBuilder(cloudprovider.ExternalGrpcProviderName,
+		func(extraIgnoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) nodegroupset.NodeInfoComparator {
+			labels := append(append([]string{}, ignoredLabels...), extraIgnoredLabels...)
+			return nodegroupset.CreateCustomNodeInfoComparator(labels, ignoredResources, ratioOpts)
+		})
+	return nil
+}
+
 // cloudConfig is the struct hoding the configs to connect to the external cluster autoscaler provider service.
 // sigs.k8s.io/yaml actually reads the json tag
 type cloudConfig struct {
@@ -27,8 +27,13 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
 func TestCloudProvider_NodeGroups(t *testing.T) {
@@ -543,3 +548,57 @@ func TestCloudProvider_Refresh(t *testing.T) {
 	err = c.Refresh()
 	assert.Error(t, err)
 }
+
+func TestRegisterNodeInfoComparator(t *testing.T) {
+	client, m, teardown := setupTest(t)
+	defer teardown()
+
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n1.Labels["pool"] = "a"
+	n1.Status.Capacity["example.com/foo"] = *resource.NewQuantity(1, resource.DecimalSI)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n2.Labels["pool"] = "b"
+	n2.Status.Capacity["example.com/foo"] = *resource.NewQuantity(2, resource.DecimalSI)
+	ni1 := schedulerframework.NewNodeInfo()
+	ni1.SetNode(n1)
+	ni2 := schedulerframework.NewNodeInfo()
+	ni2.SetNode(n2)
+	ratioOpts := config.NewDefaultNodeGroupDifferenceRatios()
+
+	// test correct call
+	m.On(
+		"NodeInfoComparatorOptions", mock.Anything, mock.Anything,
+	).Return(
+		&protos.NodeInfoComparatorOptionsResponse{IgnoredResources: []string{"example.com/foo"}},
+		nil,
+	).Once()
+
+	err := registerNodeInfoComparator(client, defaultGRPCTimeout)
+	assert.NoError(t, err)
+	builder, found := nodegroupset.GetNodeInfoComparatorBuilder(cloudprovider.ExternalGrpcProviderName)
+	assert.True(t, found)
+	assert.False(t, builder(nil, ratioOpts)(ni1, ni2))
+	assert.True(t, builder([]string{"pool"}, ratioOpts)(ni1, ni2))
+
+	// test grpc error
+	m.On(
+		"NodeInfoComparatorOptions", mock.Anything, mock.Anything,
+	).Return(
+		&protos.NodeInfoComparatorOptionsResponse{},
+		fmt.Errorf("mock error"),
+	).Once()
+
+	err = registerNodeInfoComparator(client, defaultGRPCTimeout)
+	assert.Error(t, err)
+
+	// test grpc error Unimplemented
+	m.On(
+		"NodeInfoComparatorOptions", mock.Anything, mock.Anything,
+	).Return(
+		&protos.NodeInfoComparatorOptionsResponse{},
+		status.Error(codes.Unimplemented, "mock error"),
+	).Once()
+
+	err = registerNodeInfoComparator(client, defaultGRPCTimeout)
+	assert.NoError(t, err)
+}
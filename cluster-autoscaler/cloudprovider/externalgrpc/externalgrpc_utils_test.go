@@ -73,6 +73,11 @@ func (c *cloudProviderServerMock) Refresh(ctx context.Context, req *protos.Refre
 	return args.Get(0).(*protos.RefreshResponse), args.Error(1)
 }
 
+func (c *cloudProviderServerMock) NodeInfoComparatorOptions(ctx context.Context, req *protos.NodeInfoComparatorOptionsRequest) (*protos.NodeInfoComparatorOptionsResponse, error) {
+	args := c.Called(ctx, req)
+	return args.Get(0).(*protos.NodeInfoComparatorOptionsResponse), args.Error(1)
+}
+
 func (c *cloudProviderServerMock) NodeGroupTargetSize(ctx context.Context, req *protos.NodeGroupTargetSizeRequest) (*protos.NodeGroupTargetSizeResponse, error) {
 	args := c.Called(ctx, req)
 	return args.Get(0).(*protos.NodeGroupTargetSizeResponse), args.Error(1)
@@ -42,6 +42,7 @@ const (
 	CloudProvider_GetAvailableGPUTypes_FullMethodName        = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/GetAvailableGPUTypes"
 	CloudProvider_Cleanup_FullMethodName                     = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/Cleanup"
 	CloudProvider_Refresh_FullMethodName                     = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/Refresh"
+	CloudProvider_NodeInfoComparatorOptions_FullMethodName   = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/NodeInfoComparatorOptions"
 	CloudProvider_NodeGroupTargetSize_FullMethodName         = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/NodeGroupTargetSize"
 	CloudProvider_NodeGroupIncreaseSize_FullMethodName       = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/NodeGroupIncreaseSize"
 	CloudProvider_NodeGroupDeleteNodes_FullMethodName        = "/clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider/NodeGroupDeleteNodes"
@@ -77,6 +78,10 @@ type CloudProviderClient interface {
 	Cleanup(ctx context.Context, in *CleanupRequest, opts ...grpc.CallOption) (*CleanupResponse, error)
 	// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
 	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error)
+	// NodeInfoComparatorOptions returns the labels and resources ignored when comparing nodes
+	// of node groups to find similar node groups, e.g. for balance-similar-node-groups.
+	// Implementation optional: if unimplemented return error code 12 (for `Unimplemented`)
+	NodeInfoComparatorOptions(ctx context.Context, in *NodeInfoComparatorOptionsRequest, opts ...grpc.CallOption) (*NodeInfoComparatorOptionsResponse, error)
 	// NodeGroupTargetSize returns the current target size of the node group. It is possible
 	// that the number of nodes in Kubernetes is different at the moment but should be equal
 	// to the size of a node group once everything stabilizes (new nodes finish startup and
@@ -189,6 +194,15 @@ func (c *cloudProviderClient) Refresh(ctx context.Context, in *RefreshRequest, o
 	return out, nil
 }
 
+func (c *cloudProviderClient) NodeInfoComparatorOptions(ctx context.Context, in *NodeInfoComparatorOptionsRequest, opts ...grpc.CallOption) (*NodeInfoComparatorOptionsResponse, error) {
+	out := new(NodeInfoComparatorOptionsResponse)
+	err := c.cc.Invoke(ctx, CloudProvider_NodeInfoComparatorOptions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cloudProviderClient) NodeGroupTargetSize(ctx context.Context, in *NodeGroupTargetSizeRequest, opts ...grpc.CallOption) (*NodeGroupTargetSizeResponse, error) {
 	out := new(NodeGroupTargetSizeResponse)
 	err := c.cc.Invoke(ctx, CloudProvider_NodeGroupTargetSize_FullMethodName, in, out, opts...)
@@ -278,6 +292,10 @@ type CloudProviderServer interface {
 	Cleanup(context.Context, *CleanupRequest) (*CleanupResponse, error)
 	// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
 	Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error)
+	// NodeInfoComparatorOptions returns the labels and resources ignored when comparing nodes
+	// of node groups to find similar node groups, e.g. for balance-similar-node-groups.
+	// Implementation optional: if unimplemented return error code 12 (for `Unimplemented`)
+	NodeInfoComparatorOptions(context.Context, *NodeInfoComparatorOptionsRequest) (*NodeInfoComparatorOptionsResponse, error)
 	// NodeGroupTargetSize returns the current target size of the node group. It is possible
 	// that the number of nodes in Kubernetes is different at the moment but should be equal
 	// to the size of a node group once everything stabilizes (new nodes finish startup and
@@ -339,6 +357,9 @@ func (UnimplementedCloudProviderServer) Cleanup(context.Context, *CleanupRequest
 func (UnimplementedCloudProviderServer) Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
 }
+func (UnimplementedCloudProviderServer) NodeInfoComparatorOptions(context.Context, *NodeInfoComparatorOptionsRequest) (*NodeInfoComparatorOptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NodeInfoComparatorOptions not implemented")
+}
 func (UnimplementedCloudProviderServer) NodeGroupTargetSize(context.Context, *NodeGroupTargetSizeRequest) (*NodeGroupTargetSizeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NodeGroupTargetSize not implemented")
 }
@@ -517,6 +538,24 @@ func _CloudProvider_Refresh_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CloudProvider_NodeInfoComparatorOptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeInfoComparatorOptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudProviderServer).NodeInfoComparatorOptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CloudProvider_NodeInfoComparatorOptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudProviderServer).NodeInfoComparatorOptions(ctx, req.(*NodeInfoComparatorOptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CloudProvider_NodeGroupTargetSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NodeGroupTargetSizeRequest)
 	if err := dec(in); err != nil {
@@ -682,6 +721,10 @@ var CloudProvider_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Refresh",
 			Handler:    _CloudProvider_Refresh_Handler,
 		},
+		{
+			MethodName: "NodeInfoComparatorOptions",
+			Handler:    _CloudProvider_NodeInfoComparatorOptions_Handler,
+		},
 		{
 			MethodName: "NodeGroupTargetSize",
 			Handler:    _CloudProvider_NodeGroupTargetSize_Handler,
@@ -66,13 +66,26 @@ var (
 		"https://www.googleapis.com/auth/servicecontrol",
 	}
 
-	validMIGAutoDiscovererKeys = strings.Join([]string{
-		migAutoDiscovererKeyPrefix,
-		migAutoDiscovererKeyMinNodes,
-		migAutoDiscovererKeyMaxNodes,
-	}, ", ")
+	// migAutoDiscoveryScheme is the scheme of the MIG auto-discovery definitions, mig:namePrefix=<prefix>,min=<min>,max=<max>.
+	migAutoDiscoveryScheme = cloudprovider.AutoDiscoveryScheme{
+		Discoverer: autoDiscovererTypeMIG,
+		Keys: []string{
+			migAutoDiscovererKeyPrefix,
+			migAutoDiscovererKeyMinNodes,
+			migAutoDiscovererKeyMaxNodes,
+		},
+		RequiredKeys: []string{migAutoDiscovererKeyPrefix, migAutoDiscovererKeyMaxNodes},
+		Validate: func(spec cloudprovider.AutoDiscoverySpec) error {
+			_, err := migAutoDiscoveryConfigFromSpec(spec)
+			return err
+		},
+	}
 )
 
+func init() {
+	cloudprovider.RegisterAutoDiscoveryScheme(cloudprovider.GceProviderName, migAutoDiscoveryScheme)
+}
+
 // GceManager handles GCE communication and data caching.
 type GceManager interface {
 	// Refresh triggers refresh of cached resources.
@@ -587,6 +600,9 @@ func (m *gceManagerImpl) GetMigOptions(mig Mig, defaults config.NodeGroupAutosca
 	if opt, ok := getDurationOption(options, migRef.Name, config.DefaultMaxNodeProvisionTimeKey); ok {
 		defaults.MaxNodeProvisionTime = opt
 	}
+	if opt, ok := getDurationOption(options, migRef.Name, config.DefaultScaleDownDelayAfterAddKey); ok {
+		defaults.ScaleDownDelayAfterAdd = opt
+	}
 
 	return &defaults
 }
@@ -637,43 +653,30 @@ type migAutoDiscoveryConfig struct {
 }
 
 func parseMIGAutoDiscoverySpec(spec string) (migAutoDiscoveryConfig, error) {
-	cfg := migAutoDiscoveryConfig{}
-
-	tokens := strings.Split(spec, ":")
-	if len(tokens) != 2 {
-		return cfg, fmt.Errorf("spec \"%s\" should be discoverer:key=value,key=value", spec)
-	}
-	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeMIG {
-		return cfg, fmt.Errorf("unsupported discoverer specified: %s", discoverer)
+	parsed, err := cloudprovider.ParseAutoDiscoverySpec(spec, migAutoDiscoveryScheme)
+	if err != nil {
+		return migAutoDiscoveryConfig{}, err
 	}
+	return migAutoDiscoveryConfigFromSpec(parsed)
+}
 
-	for _, arg := range strings.Split(tokens[1], ",") {
-		kv := strings.Split(arg, "=")
-		if len(kv) != 2 {
-			return cfg, fmt.Errorf("invalid key=value pair %s", kv)
-		}
-		k, v := kv[0], kv[1]
+func migAutoDiscoveryConfigFromSpec(spec cloudprovider.AutoDiscoverySpec) (migAutoDiscoveryConfig, error) {
+	cfg := migAutoDiscoveryConfig{}
 
-		var err error
-		switch k {
-		case migAutoDiscovererKeyPrefix:
-			if cfg.Re, err = regexp.Compile(fmt.Sprintf("^%s.+", v)); err != nil {
-				return cfg, fmt.Errorf("invalid instance group name prefix \"%s\" - \"^%s.+\" must be a valid RE2 regexp", v, v)
-			}
-		case migAutoDiscovererKeyMinNodes:
-			if cfg.MinSize, err = strconv.Atoi(v); err != nil {
-				return cfg, fmt.Errorf("invalid minimum nodes: %s", v)
-			}
-		case migAutoDiscovererKeyMaxNodes:
-			if cfg.MaxSize, err = strconv.Atoi(v); err != nil {
-				return cfg, fmt.Errorf("invalid maximum nodes: %s", v)
-			}
-		default:
-			return cfg, fmt.Errorf("unsupported key \"%s\" is specified for discoverer \"%s\". Supported keys are \"%s\"", k, discoverer, validMIGAutoDiscovererKeys)
+	var err error
+	prefix := spec.Params[migAutoDiscovererKeyPrefix]
+	if cfg.Re, err = regexp.Compile(fmt.Sprintf("^%s.+", prefix)); err != nil {
+		return cfg, fmt.Errorf("invalid instance group name prefix \"%s\" - \"^%s.+\" must be a valid RE2 regexp", prefix, prefix)
+	}
+	if minNodes, found := spec.Params[migAutoDiscovererKeyMinNodes]; found {
+		if cfg.MinSize, err = strconv.Atoi(minNodes); err != nil {
+			return cfg, fmt.Errorf("invalid minimum nodes: %s", minNodes)
 		}
 	}
-	if cfg.Re == nil || cfg.Re.String() == "^.+" {
+	if cfg.MaxSize, err = strconv.Atoi(spec.Params[migAutoDiscovererKeyMaxNodes]); err != nil {
+		return cfg, fmt.Errorf("invalid maximum nodes: %s", spec.Params[migAutoDiscovererKeyMaxNodes])
+	}
+	if cfg.Re.String() == "^.+" {
 		return cfg, errors.New("empty instance group name prefix supplied")
 	}
 	if cfg.MinSize > cfg.MaxSize {
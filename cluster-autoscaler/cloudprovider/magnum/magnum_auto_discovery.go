@@ -17,7 +17,6 @@ limitations under the License.
 package magnum
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -30,6 +29,20 @@ const (
 	magnumAutoDiscovererKeyRole = "role"
 )
 
+// magnumAutoDiscoveryScheme is the scheme of the auto-discovery definitions, magnum:role=<role>[,<role2>].
+var magnumAutoDiscoveryScheme = cloudprovider.AutoDiscoveryScheme{
+	Discoverer: autoDiscovererTypeMagnum,
+	ListKey:    magnumAutoDiscovererKeyRole,
+	Validate: func(spec cloudprovider.AutoDiscoverySpec) error {
+		_, err := magnumAutoDiscoveryConfigFromSpec(spec)
+		return err
+	},
+}
+
+func init() {
+	cloudprovider.RegisterAutoDiscoveryScheme(cloudprovider.MagnumProviderName, magnumAutoDiscoveryScheme)
+}
+
 type magnumAutoDiscoveryConfig struct {
 	Roles []string
 }
@@ -52,40 +65,18 @@ func parseMagnumAutoDiscoverySpecs(o cloudprovider.NodeGroupDiscoveryOptions) ([
 // The spec format is:
 // magnum:role=<role>[,<role2>]
 func parseMagnumAutoDiscoverySpec(spec string) (magnumAutoDiscoveryConfig, error) {
-	cfg := magnumAutoDiscoveryConfig{}
-
-	// Split the spec into two parts, the discoverer (magnum)
-	// and the discovery parameter (role=value).
-	tokens := strings.Split(spec, ":")
-	if len(tokens) != 2 {
-		return cfg, fmt.Errorf("invalid node group auto discovery spec specified via --node-group-auto-discovery: %s", spec)
-	}
-	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeMagnum {
-		return cfg, fmt.Errorf("unsupported discoverer specified: %s", discoverer)
-	}
-
-	// Split the discovery parameter into a key value pair.
-	param := tokens[1]
-	kv := strings.SplitN(param, "=", 2)
-	if len(kv) != 2 {
-		return cfg, fmt.Errorf("invalid discovery key=value pair %s", kv)
-	}
-
-	k, v := kv[0], kv[1]
-	if k != magnumAutoDiscovererKeyRole {
-		return cfg, fmt.Errorf("unsupported parameter key %q is specified for discoverer %q. The only supported key is %q", k, discoverer, magnumAutoDiscovererKeyRole)
+	parsed, err := cloudprovider.ParseAutoDiscoverySpec(spec, magnumAutoDiscoveryScheme)
+	if err != nil {
+		return magnumAutoDiscoveryConfig{}, err
 	}
+	return magnumAutoDiscoveryConfigFromSpec(parsed)
+}
 
-	if v == "" {
-		return cfg, errors.New("role value not supplied")
-	}
+func magnumAutoDiscoveryConfigFromSpec(spec cloudprovider.AutoDiscoverySpec) (magnumAutoDiscoveryConfig, error) {
+	cfg := magnumAutoDiscoveryConfig{}
 
 	// Allow specifying multiple roles in a single spec, comma separated.
-	roles := strings.Split(v, ",")
-	if len(roles) == 0 {
-		return cfg, errors.New("no roles specified")
-	}
+	roles := strings.Split(spec.Params[magnumAutoDiscovererKeyRole], ",")
 
 	// Check that all roles are valid.
 	for _, r := range roles {
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AutoDiscoverySpec is a parsed node group auto-discovery definition passed with --node-group-auto-discovery,
+// in the format <discoverer>:<key>=<value>[,<key>=<value>...].
+type AutoDiscoverySpec struct {
+	// Discoverer is the name of the discoverer, e.g. asg or mig.
+	Discoverer string
+	// Params are the values of the parameters by key. If a key is given more than once, the last value is used.
+	Params map[string]string
+}
+
+// AutoDiscoveryScheme describes the definitions accepted by a node group auto-discoverer of a cloud provider.
+type AutoDiscoveryScheme struct {
+	// Discoverer is the name of the discoverer, the part of the definition before the first colon.
+	Discoverer string
+	// Keys are the supported keys of the parameters. Any key is supported if it's empty, e.g. for label selectors.
+	Keys []string
+	// RequiredKeys are the keys every definition has to set.
+	RequiredKeys []string
+	// ListKey, if set, is the only key of the definition, and its value is the comma separated rest
+	// of the definition, e.g. the tags of asg:tag=key1=value1,key2.
+	ListKey string
+	// AllowEmptyValues allows parameters without a value, e.g. key= matching an empty label.
+	AllowEmptyValues bool
+	// AllowNoParams allows definitions without parameters, e.g. clusterapi: discovering everything.
+	AllowNoParams bool
+	// Validate optionally validates the values of the parameters of a parsed definition when the
+	// definitions are validated at startup, e.g. by converting them to the cloud provider config.
+	Validate func(spec AutoDiscoverySpec) error
+}
+
+var (
+	autoDiscoverySchemesLock sync.Mutex
+	// autoDiscoverySchemes are the registered schemes by cloud provider name and discoverer.
+	autoDiscoverySchemes = map[string]map[string]AutoDiscoveryScheme{}
+)
+
+// RegisterAutoDiscoveryScheme registers a node group auto-discovery scheme supported by the cloud provider,
+// replacing the one of the same discoverer registered before if any. Cloud providers register their
+// schemes when their package is initialized, so that the definitions are validated at startup.
+func RegisterAutoDiscoveryScheme(cloudProviderName string, scheme AutoDiscoveryScheme) {
+	autoDiscoverySchemesLock.Lock()
+	defer autoDiscoverySchemesLock.Unlock()
+	if autoDiscoverySchemes[cloudProviderName] == nil {
+		autoDiscoverySchemes[cloudProviderName] = make(map[string]AutoDiscoveryScheme)
+	}
+	autoDiscoverySchemes[cloudProviderName][scheme.Discoverer] = scheme
+}
+
+// ValidateAutoDiscoverySpecs parses and validates the node group auto-discovery definitions with the schemes
+// registered by the cloud provider. Definitions of cloud providers that didn't register any scheme are left
+// to the cloud provider to validate.
+func ValidateAutoDiscoverySpecs(cloudProviderName string, specs []string) error {
+	autoDiscoverySchemesLock.Lock()
+	schemes, found := autoDiscoverySchemes[cloudProviderName]
+	autoDiscoverySchemesLock.Unlock()
+	if !found {
+		return nil
+	}
+	for _, spec := range specs {
+		discoverer, _, _ := strings.Cut(spec, ":")
+		scheme, found := schemes[discoverer]
+		if !found {
+			discoverers := make([]string, 0, len(schemes))
+			for d := range schemes {
+				discoverers = append(discoverers, d)
+			}
+			sort.Strings(discoverers)
+			return fmt.Errorf("invalid node group auto-discovery spec %q: unsupported discoverer %q, the %s cloud provider supports %s", spec, discoverer, cloudProviderName, strings.Join(discoverers, ", "))
+		}
+		parsed, err := ParseAutoDiscoverySpec(spec, scheme)
+		if err == nil && scheme.Validate != nil {
+			err = scheme.Validate(parsed)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid node group auto-discovery spec %q: %v", spec, err)
+		}
+	}
+	return nil
+}
+
+// ParseAutoDiscoverySpec parses a node group auto-discovery definition according to the scheme.
+// The values of the parameters are not validated, beyond being set unless the scheme allows empty values.
+func ParseAutoDiscoverySpec(spec string, scheme AutoDiscoveryScheme) (AutoDiscoverySpec, error) {
+	parsed := AutoDiscoverySpec{Params: make(map[string]string)}
+
+	tokens := strings.SplitN(spec, ":", 2)
+	if len(tokens) != 2 {
+		return parsed, fmt.Errorf("spec \"%s\" should be discoverer:key=value,key=value", spec)
+	}
+	parsed.Discoverer = tokens[0]
+	if parsed.Discoverer != scheme.Discoverer {
+		return parsed, fmt.Errorf("unsupported discoverer specified: %s", parsed.Discoverer)
+	}
+
+	args := strings.Split(tokens[1], ",")
+	if scheme.ListKey != "" {
+		args = []string{tokens[1]}
+	}
+	for _, arg := range args {
+		if arg == "" && scheme.ListKey == "" {
+			continue
+		}
+		kv := strings.Split(arg, "=")
+		if scheme.ListKey != "" {
+			kv = strings.SplitN(arg, "=", 2)
+		}
+		if len(kv) != 2 {
+			return parsed, fmt.Errorf("invalid key=value pair %s", kv)
+		}
+		k, v := kv[0], kv[1]
+		if k == "" {
+			return parsed, fmt.Errorf("empty key in key=value pair %s", arg)
+		}
+		if !scheme.supportsKey(k) {
+			return parsed, fmt.Errorf("unsupported key \"%s\" is specified for discoverer \"%s\". Supported keys are \"%s\"", k, parsed.Discoverer, strings.Join(scheme.supportedKeys(), ","))
+		}
+		if v == "" && !scheme.AllowEmptyValues {
+			return parsed, fmt.Errorf("value of key \"%s\" not supplied", k)
+		}
+		parsed.Params[k] = v
+	}
+	if len(parsed.Params) == 0 && !scheme.AllowNoParams {
+		return parsed, fmt.Errorf("no parameters specified for discoverer \"%s\"", parsed.Discoverer)
+	}
+	for _, k := range scheme.RequiredKeys {
+		if _, found := parsed.Params[k]; !found {
+			return parsed, fmt.Errorf("key \"%s\" is required for discoverer \"%s\"", k, parsed.Discoverer)
+		}
+	}
+	return parsed, nil
+}
+
+func (s AutoDiscoveryScheme) supportedKeys() []string {
+	if s.ListKey != "" {
+		return []string{s.ListKey}
+	}
+	return s.Keys
+}
+
+func (s AutoDiscoveryScheme) supportsKey(key string) bool {
+	keys := s.supportedKeys()
+	if len(keys) == 0 {
+		return true
+	}
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAutoDiscoverySpec(t *testing.T) {
+	keyValueScheme := AutoDiscoveryScheme{
+		Discoverer:   "kv",
+		Keys:         []string{"a", "b"},
+		RequiredKeys: []string{"a"},
+	}
+	anyKeyScheme := AutoDiscoveryScheme{
+		Discoverer:       "any",
+		AllowEmptyValues: true,
+		AllowNoParams:    true,
+	}
+	listScheme := AutoDiscoveryScheme{
+		Discoverer: "list",
+		ListKey:    "tag",
+	}
+	testCases := []struct {
+		name       string
+		spec       string
+		scheme     AutoDiscoveryScheme
+		wantParams map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "key values",
+			spec:       "kv:a=1,b=2",
+			scheme:     keyValueScheme,
+			wantParams: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:       "empty args are skipped",
+			spec:       "kv:a=1,,",
+			scheme:     keyValueScheme,
+			wantParams: map[string]string{"a": "1"},
+		},
+		{
+			name:    "missing discoverer",
+			spec:    "a=1",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "wrong discoverer",
+			spec:    "list:a=1",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported key",
+			spec:    "kv:a=1,c=2",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "missing required key",
+			spec:    "kv:b=2",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "empty value",
+			spec:    "kv:a=",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			spec:    "kv:=1",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "missing separator",
+			spec:    "kv:a",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:    "too many separators",
+			spec:    "kv:a=1=2",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:       "any key with empty value",
+			spec:       "any:foo=,bar=baz",
+			scheme:     anyKeyScheme,
+			wantParams: map[string]string{"foo": "", "bar": "baz"},
+		},
+		{
+			name:       "no params",
+			spec:       "any:",
+			scheme:     anyKeyScheme,
+			wantParams: map[string]string{},
+		},
+		{
+			name:    "no params not allowed",
+			spec:    "kv:",
+			scheme:  keyValueScheme,
+			wantErr: true,
+		},
+		{
+			name:       "list",
+			spec:       "list:tag=k1=v1,k2",
+			scheme:     listScheme,
+			wantParams: map[string]string{"tag": "k1=v1,k2"},
+		},
+		{
+			name:    "list with wrong key",
+			spec:    "list:label=k1",
+			scheme:  listScheme,
+			wantErr: true,
+		},
+		{
+			name:    "empty list",
+			spec:    "list:tag=",
+			scheme:  listScheme,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseAutoDiscoverySpec(tc.spec, tc.scheme)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.scheme.Discoverer, parsed.Discoverer)
+			assert.Equal(t, tc.wantParams, parsed.Params)
+		})
+	}
+}
+
+func TestValidateAutoDiscoverySpecs(t *testing.T) {
+	RegisterAutoDiscoveryScheme("test-provider", AutoDiscoveryScheme{
+		Discoverer: "kv",
+		Keys:       []string{"a"},
+		Validate: func(spec AutoDiscoverySpec) error {
+			if spec.Params["a"] != "1" {
+				return errors.New("a must be 1")
+			}
+			return nil
+		},
+	})
+	RegisterAutoDiscoveryScheme("test-provider", AutoDiscoveryScheme{
+		Discoverer: "list",
+		ListKey:    "tag",
+	})
+
+	assert.NoError(t, ValidateAutoDiscoverySpecs("test-provider", []string{"kv:a=1", "list:tag=k1"}))
+	assert.NoError(t, ValidateAutoDiscoverySpecs("test-provider", nil))
+	assert.Error(t, ValidateAutoDiscoverySpecs("test-provider", []string{"kv:a=1", "kv:b=1"}))
+	assert.Error(t, ValidateAutoDiscoverySpecs("test-provider", []string{"kv:a=2"}))
+	err := ValidateAutoDiscoverySpecs("test-provider", []string{"asg:tag=k1"})
+	assert.ErrorContains(t, err, "supports kv, list")
+	// Providers without registered schemes validate the definitions themselves.
+	assert.NoError(t, ValidateAutoDiscoverySpecs("unregistered-provider", []string{"asg:tag=k1"}))
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common/auth"
+)
+
+const (
+	// computeBasePath is the API version of the OCI Compute service.
+	computeBasePath = "20160918"
+	// computeEndpointTemplate is the endpoint template of the OCI Compute service.
+	computeEndpointTemplate = "https://iaas.{region}.{secondLevelDomain}"
+
+	// CapacityAvailable is the availability status of a shape that has host capacity in an availability domain.
+	CapacityAvailable = "AVAILABLE"
+	// CapacityOutOfHostCapacity is the availability status of a shape that has no host capacity in an availability
+	// domain.
+	CapacityOutOfHostCapacity = "OUT_OF_HOST_CAPACITY"
+	// CapacityHardwareNotSupported is the availability status of a shape that is not offered in an availability domain.
+	CapacityHardwareNotSupported = "HARDWARE_NOT_SUPPORTED"
+)
+
+// CapacityReportInstanceShapeConfig is the shape configuration of a flexible shape in a capacity report.
+type CapacityReportInstanceShapeConfig struct {
+	Ocpus       *float32 `mandatory:"false" json:"ocpus,omitempty"`
+	MemoryInGBs *float32 `mandatory:"false" json:"memoryInGBs,omitempty"`
+}
+
+// CreateCapacityReportShapeAvailabilityDetails is a shape whose capacity is requested in a capacity report.
+type CreateCapacityReportShapeAvailabilityDetails struct {
+	InstanceShape       *string                            `mandatory:"true" json:"instanceShape"`
+	InstanceShapeConfig *CapacityReportInstanceShapeConfig `mandatory:"false" json:"instanceShapeConfig,omitempty"`
+}
+
+// CreateComputeCapacityReportDetails are the shapes and availability domain of a capacity report.
+type CreateComputeCapacityReportDetails struct {
+	CompartmentID       *string                                        `mandatory:"true" json:"compartmentId"`
+	AvailabilityDomain  *string                                        `mandatory:"true" json:"availabilityDomain"`
+	ShapeAvailabilities []CreateCapacityReportShapeAvailabilityDetails `mandatory:"true" json:"shapeAvailabilities"`
+}
+
+// CreateComputeCapacityReportRequest is the request of the Compute CreateComputeCapacityReport operation.
+type CreateComputeCapacityReportRequest struct {
+	CreateComputeCapacityReportDetails `contributesTo:"body"`
+}
+
+// CapacityReportShapeAvailability is the capacity of a shape in a capacity report.
+type CapacityReportShapeAvailability struct {
+	InstanceShape      *string `mandatory:"false" json:"instanceShape"`
+	AvailabilityStatus *string `mandatory:"false" json:"availabilityStatus"`
+	AvailableCount     *int64  `mandatory:"false" json:"availableCount"`
+}
+
+// ComputeCapacityReport is the capacity of the requested shapes in an availability domain.
+type ComputeCapacityReport struct {
+	ShapeAvailabilities []CapacityReportShapeAvailability `mandatory:"false" json:"shapeAvailabilities"`
+}
+
+// CreateComputeCapacityReportResponse is the response of the Compute CreateComputeCapacityReport operation.
+type CreateComputeCapacityReportResponse struct {
+	RawResponse           *http.Response
+	ComputeCapacityReport `presentIn:"body"`
+	OpcRequestID          *string `presentIn:"header" name:"opc-request-id"`
+}
+
+// CapacityReportClient is an interface around the OCI Compute capacity report calls we require.
+type CapacityReportClient interface {
+	CreateComputeCapacityReport(context.Context, CreateComputeCapacityReportRequest) (CreateComputeCapacityReportResponse, error)
+}
+
+// CapacityReportClientImpl is the implementation of a client of the capacity reports of the OCI Compute service.
+type CapacityReportClientImpl struct {
+	common.BaseClient
+}
+
+// NewCapacityReportClient creates a client of the capacity reports of the OCI Compute service using the given
+// configuration provider and rate limiter.
+func NewCapacityReportClient(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter, circuitBreaker *CircuitBreaker) (*CapacityReportClientImpl, error) {
+	provider, err := auth.GetGenericConfigurationProvider(configProvider)
+	if err != nil {
+		return nil, err
+	}
+	baseClient, err := common.NewClientWithConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	region, err := provider.Region()
+	if err != nil {
+		return nil, err
+	}
+	baseClient.BasePath = computeBasePath
+	baseClient.Host = common.StringToRegion(region).EndpointForTemplate("iaas", computeEndpointTemplate)
+	baseClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&baseClient)
+	rateLimiter.Apply(&baseClient)
+	LogRequestIDs(&baseClient)
+	return &CapacityReportClientImpl{BaseClient: baseClient}, nil
+}
+
+// CreateComputeCapacityReport reports the host capacity of the specified shapes in an availability domain.
+func (c *CapacityReportClientImpl) CreateComputeCapacityReport(ctx context.Context, req CreateComputeCapacityReportRequest) (CreateComputeCapacityReportResponse, error) {
+	var response CreateComputeCapacityReportResponse
+	httpRequest, err := common.MakeDefaultHTTPRequestWithTaggedStruct(http.MethodPost, "/computeCapacityReports", req)
+	if err != nil {
+		return response, err
+	}
+
+	httpResponse, err := c.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// HasHostCapacity returns true if any of the availability domains has host capacity for at least one instance of the
+// shape. Capacity reports only cover what is free at the time of the report, so they are used to skip node groups
+// that cannot launch anything rather than to size scale-ups.
+func HasHostCapacity(client CapacityReportClient, shape *Shape, compartmentID string, availabilityDomains []string) (bool, error) {
+	shapeAvailability := CreateCapacityReportShapeAvailabilityDetails{
+		InstanceShape: common.String(shape.Name),
+	}
+	if strings.HasSuffix(shape.Name, ".Flex") {
+		shapeAvailability.InstanceShapeConfig = &CapacityReportInstanceShapeConfig{
+			Ocpus:       common.Float32(shape.CPU),
+			MemoryInGBs: common.Float32(shape.MemoryInBytes / 1024 / 1024 / 1024),
+		}
+	}
+
+	for _, availabilityDomain := range availabilityDomains {
+		resp, err := client.CreateComputeCapacityReport(context.Background(), CreateComputeCapacityReportRequest{
+			CreateComputeCapacityReportDetails: CreateComputeCapacityReportDetails{
+				CompartmentID:       common.String(compartmentID),
+				AvailabilityDomain:  common.String(availabilityDomain),
+				ShapeAvailabilities: []CreateCapacityReportShapeAvailabilityDetails{shapeAvailability},
+			},
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to create capacity report of shape %s in %s", shape.Name, availabilityDomain)
+		}
+		for _, availability := range resp.ShapeAvailabilities {
+			if availability.AvailabilityStatus == nil || *availability.AvailabilityStatus != CapacityAvailable {
+				continue
+			}
+			// The available count is only reported for some shapes, an available shape without it has capacity.
+			if availability.AvailableCount == nil || *availability.AvailableCount > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+)
+
+type mockCapacityReportClient struct {
+	// availabilities holds the shape availability per availability domain.
+	availabilities map[string]CapacityReportShapeAvailability
+	err            error
+	requests       []CreateComputeCapacityReportRequest
+}
+
+func (m *mockCapacityReportClient) CreateComputeCapacityReport(_ context.Context, req CreateComputeCapacityReportRequest) (CreateComputeCapacityReportResponse, error) {
+	m.requests = append(m.requests, req)
+	if m.err != nil {
+		return CreateComputeCapacityReportResponse{}, m.err
+	}
+	availability, ok := m.availabilities[*req.AvailabilityDomain]
+	if !ok {
+		return CreateComputeCapacityReportResponse{}, nil
+	}
+	return CreateComputeCapacityReportResponse{
+		ComputeCapacityReport: ComputeCapacityReport{
+			ShapeAvailabilities: []CapacityReportShapeAvailability{availability},
+		},
+	}, nil
+}
+
+func TestHasHostCapacity(t *testing.T) {
+	availabilityDomains := []string{"fake:PHX-AD-1", "fake:PHX-AD-2"}
+	outOfCapacity := CapacityReportShapeAvailability{AvailabilityStatus: common.String(CapacityOutOfHostCapacity)}
+
+	testCases := map[string]struct {
+		availabilities map[string]CapacityReportShapeAvailability
+		err            error
+		expected       bool
+		expectedError  bool
+	}{
+		"available in one availability domain": {
+			availabilities: map[string]CapacityReportShapeAvailability{
+				"fake:PHX-AD-1": outOfCapacity,
+				"fake:PHX-AD-2": {AvailabilityStatus: common.String(CapacityAvailable), AvailableCount: common.Int64(3)},
+			},
+			expected: true,
+		},
+		"available without count": {
+			availabilities: map[string]CapacityReportShapeAvailability{
+				"fake:PHX-AD-1": {AvailabilityStatus: common.String(CapacityAvailable)},
+			},
+			expected: true,
+		},
+		"available with zero count": {
+			availabilities: map[string]CapacityReportShapeAvailability{
+				"fake:PHX-AD-1": {AvailabilityStatus: common.String(CapacityAvailable), AvailableCount: common.Int64(0)},
+			},
+		},
+		"out of host capacity": {
+			availabilities: map[string]CapacityReportShapeAvailability{
+				"fake:PHX-AD-1": outOfCapacity,
+				"fake:PHX-AD-2": {AvailabilityStatus: common.String(CapacityHardwareNotSupported)},
+			},
+		},
+		"report failed": {
+			err:           errors.New("internal error"),
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &mockCapacityReportClient{availabilities: tc.availabilities, err: tc.err}
+			got, err := HasHostCapacity(client, &Shape{Name: "VM.Standard2.4", CPU: 4}, "ocid1.compartment.oc1..aaaaaaaa1", availabilityDomains)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("got error %v ; wanted error %v", err, tc.expectedError)
+			}
+			if got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestHasHostCapacityFlexShape(t *testing.T) {
+	client := &mockCapacityReportClient{}
+	shape := &Shape{Name: "VM.Standard.E4.Flex", CPU: 2, MemoryInBytes: 32 * 1024 * 1024 * 1024}
+	if _, err := HasHostCapacity(client, shape, "ocid1.compartment.oc1..aaaaaaaa1", []string{"fake:PHX-AD-1"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	shapeConfig := client.requests[0].ShapeAvailabilities[0].InstanceShapeConfig
+	if shapeConfig == nil || *shapeConfig.Ocpus != 2 || *shapeConfig.MemoryInGBs != 32 {
+		t.Errorf("got shape config %+v ; wanted 2 OCPUs and 32GB", shapeConfig)
+	}
+}
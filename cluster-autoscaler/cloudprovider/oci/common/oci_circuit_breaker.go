@@ -0,0 +1,65 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// CircuitBreaker holds the settings of the SDK circuit breakers of OCI clients. Every client gets a circuit breaker of
+// its own, so a failing regional endpoint of one service only fails the calls to that service fast instead of
+// stalling the autoscaler's loop on retries, while the calls to healthy services are still sent.
+type CircuitBreaker struct {
+	failureRateThreshold float64
+	minimumRequests      uint32
+	resetTimeout         time.Duration
+}
+
+// NewCircuitBreaker creates the settings of circuit breakers that open once failureRateThreshold (0-1] of at least
+// minimumRequests calls failed and let a call through again after resetTimeout. Settings that are not positive
+// default to the ones of the SDK.
+func NewCircuitBreaker(failureRateThreshold float64, minimumRequests int, resetTimeout time.Duration) *CircuitBreaker {
+	b := &CircuitBreaker{
+		failureRateThreshold: common.CircuitBreakerDefaultFailureRateThreshold,
+		minimumRequests:      common.CircuitBreakerDefaultVolumeThreshold,
+		resetTimeout:         common.CircuitBreakerDefaultResetTimeout,
+	}
+	if failureRateThreshold > 0 {
+		b.failureRateThreshold = failureRateThreshold
+	}
+	if minimumRequests > 0 {
+		b.minimumRequests = uint32(minimumRequests)
+	}
+	if resetTimeout > 0 {
+		b.resetTimeout = resetTimeout
+	}
+	return b
+}
+
+// Apply sets a new circuit breaker on the specified client. It must be called after SetCustomClientConfiguration,
+// which replaces the circuit breaker of the client. Apply on a nil CircuitBreaker is a no-op.
+func (b *CircuitBreaker) Apply(client *common.BaseClient) {
+	if b == nil {
+		return
+	}
+	client.Configuration.CircuitBreaker = common.NewCircuitBreaker(common.NewCircuitBreakerSettingWithOptions(
+		common.WithName(client.Host),
+		common.WithServiceName(client.Host),
+		common.WithFailureRateThreshold(b.failureRateThreshold),
+		common.WithMinimumRequests(b.minimumRequests),
+		common.WithOpenStateWindow(b.resetTimeout),
+	))
+}
+
+// validateCircuitBreakerFailureRateThreshold returns an error if the failure rate threshold is not a ratio.
+func validateCircuitBreakerFailureRateThreshold(failureRateThreshold float64) error {
+	if failureRateThreshold < 0 || failureRateThreshold > 1 {
+		return fmt.Errorf("circuit-breaker-failure-rate-threshold %v must be between 0 and 1", failureRateThreshold)
+	}
+	return nil
+}
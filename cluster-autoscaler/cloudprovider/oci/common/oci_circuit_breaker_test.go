@@ -0,0 +1,55 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+)
+
+func TestCircuitBreakerApply(t *testing.T) {
+	breaker := NewCircuitBreaker(0.5, 4, time.Minute)
+	compute := common.BaseClient{Host: "https://iaas.us-phoenix-1.oraclecloud.com"}
+	limits := common.BaseClient{Host: "https://limits.us-phoenix-1.oci.oraclecloud.com"}
+	breaker.Apply(&compute)
+	breaker.Apply(&limits)
+
+	if compute.Configuration.CircuitBreaker == nil || limits.Configuration.CircuitBreaker == nil {
+		t.Fatalf("got no circuit breaker ; wanted one per client")
+	}
+	if compute.Configuration.CircuitBreaker == limits.Configuration.CircuitBreaker {
+		t.Errorf("got a circuit breaker shared by the clients ; wanted one per client")
+	}
+	if got := compute.Configuration.CircuitBreaker.Cb.Name(); got != compute.Host {
+		t.Errorf("got circuit breaker %s ; wanted %s", got, compute.Host)
+	}
+	setting := compute.Configuration.CircuitBreaker.Cbst.String()
+	for _, expected := range []string{"openStateWindow=1m0s", "failureRateThreshold=0.5", "minimumRequests=4"} {
+		if !strings.Contains(setting, expected) {
+			t.Errorf("got setting %s ; wanted %s", setting, expected)
+		}
+	}
+}
+
+func TestCircuitBreakerDefaults(t *testing.T) {
+	client := common.BaseClient{Host: "https://iaas.us-phoenix-1.oraclecloud.com"}
+	NewCircuitBreaker(0, 0, 0).Apply(&client)
+	setting := client.Configuration.CircuitBreaker.Cbst.String()
+	for _, expected := range []string{"openStateWindow=30s", "failureRateThreshold=0.8", "minimumRequests=10"} {
+		if !strings.Contains(setting, expected) {
+			t.Errorf("got setting %s ; wanted %s", setting, expected)
+		}
+	}
+
+	var disabled *CircuitBreaker
+	client = common.BaseClient{}
+	disabled.Apply(&client)
+	if client.Configuration.CircuitBreaker != nil {
+		t.Errorf("got a circuit breaker ; wanted none")
+	}
+}
@@ -5,15 +5,19 @@ Copyright 2021-2023 Oracle and/or its affiliates.
 package common
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/gcfg.v1"
+	apiv1 "k8s.io/api/core/v1"
 	ipconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
 	npconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
 	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 )
 
 // CloudConfig holds the cloud config for OCI provider.
@@ -24,6 +28,120 @@ type CloudConfig struct {
 		Region                 string        `gcfg:"region"`
 		UseInstancePrinciples  bool          `gcfg:"use-instance-principals"`
 		UseNonMemberAnnotation bool          `gcfg:"use-non-member-annotation"`
+		// FullRefreshInterval (full-refresh-interval) is how often the instances of an instance pool are listed when
+		// neither the instance pool nor its instances changed. Refreshes in between only list the instance pools and
+		// the instances of each compartment, so instances that OCI terminated or replaced are still noticed on the
+		// next refresh. Defaults to 30m, or to every refresh if drain-terminating-nodes is set.
+		FullRefreshInterval time.Duration `gcfg:"full-refresh-interval"`
+		// KubeReserved, SystemReserved and EvictionHard use the same format as the corresponding kubelet flags and
+		// are deducted from the capacity of template nodes when computing their allocatable resources.
+		KubeReserved   string `gcfg:"kube-reserved"`
+		SystemReserved string `gcfg:"system-reserved"`
+		EvictionHard   string `gcfg:"eviction-hard"`
+		// CheckServiceLimits caps scale-up requests to what the OCI Limits service reports as still available.
+		CheckServiceLimits bool `gcfg:"check-service-limits"`
+		// CheckHostCapacity fails scale-ups of instance pools that compute capacity reports show cannot launch any
+		// instances, so the core autoscaler backs off from them without waiting for the launch to fail.
+		CheckHostCapacity bool `gcfg:"check-host-capacity"`
+		// FlexShapeMemoryPerOcpu overrides the memory per OCPU assumed for flexible shapes configured without memory,
+		// e.g. "VM.Standard.E4.Flex=16,VM.Standard.A1.Flex=6".
+		FlexShapeMemoryPerOcpu string `gcfg:"flex-shape-memory-per-ocpu"`
+		// ReportVCPUs (report-vcpus) makes the template nodes of instance pools report the vCPUs kubelet advertises
+		// rather than the OCPUs of their shape, i.e. 2 vCPUs per OCPU unless the shape has Arm processors or its
+		// platform config disables symmetric multi-threading. Template nodes of node pools always report vCPUs.
+		ReportVCPUs bool `gcfg:"report-vcpus"`
+		// AutoprovisioningInstanceConfigurationID are the instance configurations new instance pools are created from
+		// when node group auto-provisioning is enabled. Each instance configuration provides the shape it launches as
+		// a machine type.
+		AutoprovisioningInstanceConfigurationID []string `gcfg:"autoprovisioning-instance-configuration-id"`
+		// AutoprovisioningAvailabilityDomain and AutoprovisioningSubnetID are the placement of autoprovisioned instance pools.
+		AutoprovisioningAvailabilityDomain string `gcfg:"autoprovisioning-availability-domain"`
+		AutoprovisioningSubnetID           string `gcfg:"autoprovisioning-subnet-id"`
+		// DrainTerminatingNodes (drain-terminating-nodes) cordons, taints and drains the nodes of instances that OCI
+		// terminates without the autoscaler asking for it, e.g. preempted instances, as soon as they are seen
+		// terminating. The taint key is oci.oraclecloud.com/instance-terminating.
+		DrainTerminatingNodes bool `gcfg:"drain-terminating-nodes"`
+		// TerminationNoticeTaint (termination-notice-taint) is the key of a taint that an agent on the nodes, e.g. one
+		// polling the instance metadata for preemption notices, sets when the instance of its node is about to be
+		// terminated. If drain-terminating-nodes is set, nodes are drained as soon as they are tainted with it rather
+		// than when their instances are seen terminating in the next refresh.
+		TerminationNoticeTaint string `gcfg:"termination-notice-taint"`
+		// ReadQPS (read-qps) is the maximum rate of read (Get/List) OCI API calls per second, summed over all the
+		// clients of the provider. Read calls are not rate limited unless it is positive.
+		ReadQPS float32 `gcfg:"read-qps"`
+		// ReadBurst (read-burst) is the number of read calls that may exceed ReadQPS in a burst. Defaults to ReadQPS.
+		ReadBurst int `gcfg:"read-burst"`
+		// MutateQPS (mutate-qps) is the maximum rate of mutating (Create/Update/Detach/Delete/Terminate) OCI API calls
+		// per second, summed over all the clients of the provider. Mutating calls are not rate limited unless it is
+		// positive.
+		MutateQPS float32 `gcfg:"mutate-qps"`
+		// MutateBurst (mutate-burst) is the number of mutating calls that may exceed MutateQPS in a burst. Defaults
+		// to MutateQPS.
+		MutateBurst int `gcfg:"mutate-burst"`
+		// MaxInFlightMutations (max-in-flight-mutations) is the maximum number of resizes, detaches and terminations
+		// of node groups that may be in flight at the same time, so that a large scale-down does not exceed the
+		// concurrency limit of work requests of the tenancy. Mutations are not limited unless it is positive.
+		MaxInFlightMutations int `gcfg:"max-in-flight-mutations"`
+		// MaxInFlightMutationsPerNodeGroup (max-in-flight-mutations-per-node-group) is the maximum number of resizes,
+		// detaches and terminations that may be in flight at the same time per node group. Mutations are not limited
+		// unless it is positive.
+		MaxInFlightMutationsPerNodeGroup int `gcfg:"max-in-flight-mutations-per-node-group"`
+		// MaxInFlightMutationWait (max-in-flight-mutation-wait) is how long a resize, detach or termination waits for
+		// the mutations in flight before it fails. Defaults to 5m.
+		MaxInFlightMutationWait time.Duration `gcfg:"max-in-flight-mutation-wait"`
+		// CircuitBreakerEnabled (circuit-breaker-enabled) gives every OCI client a circuit breaker that fails calls
+		// fast while the endpoint of its service keeps failing.
+		CircuitBreakerEnabled bool `gcfg:"circuit-breaker-enabled"`
+		// CircuitBreakerFailureRateThreshold (circuit-breaker-failure-rate-threshold) is the ratio of failed calls,
+		// between 0 and 1, that opens a circuit breaker. Defaults to 0.8.
+		CircuitBreakerFailureRateThreshold float64 `gcfg:"circuit-breaker-failure-rate-threshold"`
+		// CircuitBreakerMinimumRequests (circuit-breaker-minimum-requests) is the number of calls a client must have
+		// sent before its circuit breaker may open. Defaults to 10.
+		CircuitBreakerMinimumRequests int `gcfg:"circuit-breaker-minimum-requests"`
+		// CircuitBreakerResetTimeout (circuit-breaker-reset-timeout) is how long an open circuit breaker fails calls
+		// before it lets one through to check if the endpoint recovered. Defaults to 30s.
+		CircuitBreakerResetTimeout time.Duration `gcfg:"circuit-breaker-reset-timeout"`
+		// MaxNodeProvisionTime (max-node-provision-time) overrides the max node provision time of node groups by
+		// shape, e.g. "BM.GPU4.8=45m,BM.Standard.E4.128=30m". The cluster-autoscaler/max-node-provision-time freeform
+		// tag of an instance pool or node pool takes precedence.
+		MaxNodeProvisionTime string `gcfg:"max-node-provision-time"`
+		// MonitoringNamespace (monitoring-namespace) is the OCI Monitoring namespace the scale-up, scale-down and
+		// failed launch counts of node groups are published to. Metrics are not published unless it is set.
+		MonitoringNamespace string `gcfg:"monitoring-namespace"`
+		// MonitoringCompartmentID (monitoring-compartment-id) is the compartment metrics are published to. Defaults to
+		// the compartment of the node groups.
+		MonitoringCompartmentID string `gcfg:"monitoring-compartment-id"`
+		// TagLaunchedInstances (tag-launched-instances) adds the managed-by, cluster-autoscaler/cluster-name and
+		// cluster-autoscaler/node-group freeform tags to the instances of node groups, e.g. for cost attribution. The
+		// instances of instance pools are tagged one by one, which requires permission to update instances.
+		TagLaunchedInstances bool `gcfg:"tag-launched-instances"`
+		// ClusterName (cluster-name) is the value of the cluster-autoscaler/cluster-name freeform tag.
+		ClusterName string `gcfg:"cluster-name"`
+		// PreTerminationFunctionID (pre-termination-function-id) is the OCI Function invoked with the node group and
+		// the instances and node names the autoscaler is about to terminate, after their nodes were drained.
+		PreTerminationFunctionID string `gcfg:"pre-termination-function-id"`
+		// PreTerminationFunctionEndpoint (pre-termination-function-endpoint) is the invoke endpoint of the function,
+		// e.g. "https://abcdefgh.us-phoenix-1.functions.oci.oraclecloud.com".
+		PreTerminationFunctionEndpoint string `gcfg:"pre-termination-function-endpoint"`
+		// PreTerminationTimeout (pre-termination-timeout) is how long the function may run. Defaults to 30s.
+		PreTerminationTimeout time.Duration `gcfg:"pre-termination-timeout"`
+		// PreTerminationFailurePolicy (pre-termination-failure-policy) is either "continue", to terminate the
+		// instances even if the function fails, or "abort", to fail the scale-down. Defaults to "continue".
+		PreTerminationFailurePolicy string `gcfg:"pre-termination-failure-policy"`
+		// CredentialsReloadInterval (credentials-reload-interval) is how often the API key credentials of the OCI
+		// config file are reloaded so that rotated keys are used without a restart. Defaults to 1m.
+		CredentialsReloadInterval time.Duration `gcfg:"credentials-reload-interval"`
+		// ShapeSnapshotFile (shape-snapshot-file) is a JSON file, e.g. mounted from a ConfigMap, with the capacity and
+		// hourly prices of shapes. The shapes are used when they cannot be listed, and the prices to price nodes.
+		ShapeSnapshotFile string `gcfg:"shape-snapshot-file"`
+		// PreferShapeSnapshot (prefer-shape-snapshot) uses the shapes of the snapshot without listing shapes, for
+		// tenancies that cannot reach the OCI APIs that list them.
+		PreferShapeSnapshot bool `gcfg:"prefer-shape-snapshot"`
+		// SecondaryVnicResourceName (secondary-vnic-resource-name) is the extended resource, e.g.
+		// "oci.oraclecloud.com/sriov-vnic", the template nodes of instance pools have one of per secondary VNIC their
+		// instance configuration attaches, so pods requesting it scale up the instance pools with SR-IOV VNICs. It must
+		// be the resource the device plugin advertises on the nodes. Template nodes do not have it unless it is set.
+		SecondaryVnicResourceName string `gcfg:"secondary-vnic-resource-name"`
 	}
 }
 
@@ -84,5 +202,121 @@ func CreateCloudConfig(cloudConfigPath string, configProvider common.Configurati
 		}
 		cloudConfig.Global.CompartmentID = tenancyID
 	}
+
+	if _, err := cloudConfig.KubeletReservation(); err != nil {
+		return nil, err
+	}
+	if _, err := cloudConfig.FlexShapeMemoryPerOcpuInGBs(); err != nil {
+		return nil, err
+	}
+	if _, err := cloudConfig.MaxNodeProvisionTimeByShape(); err != nil {
+		return nil, err
+	}
+	if namespace := strings.ToLower(cloudConfig.Global.MonitoringNamespace); strings.HasPrefix(namespace, "oci_") || strings.HasPrefix(namespace, "oracle_") {
+		return nil, fmt.Errorf("monitoring-namespace %q must not start with oci_ or oracle_", cloudConfig.Global.MonitoringNamespace)
+	}
+	if err := validateCircuitBreakerFailureRateThreshold(cloudConfig.Global.CircuitBreakerFailureRateThreshold); err != nil {
+		return nil, err
+	}
+	if cloudConfig.Global.PreTerminationFunctionID != "" && cloudConfig.Global.PreTerminationFunctionEndpoint == "" {
+		return nil, errors.New("pre-termination-function-endpoint is required when pre-termination-function-id is set")
+	}
+	if err := validatePreTerminationFailurePolicy(cloudConfig.Global.PreTerminationFailurePolicy); err != nil {
+		return nil, err
+	}
+	if cloudConfig.Global.PreferShapeSnapshot && cloudConfig.Global.ShapeSnapshotFile == "" {
+		return nil, errors.New("shape-snapshot-file is required when prefer-shape-snapshot is set")
+	}
+	if _, err := cloudConfig.ShapeSnapshot(); err != nil {
+		return nil, err
+	}
+	if name := cloudConfig.Global.SecondaryVnicResourceName; name != "" && !v1helper.IsExtendedResourceName(apiv1.ResourceName(name)) {
+		return nil, fmt.Errorf("secondary-vnic-resource-name %q is not an extended resource name", name)
+	}
+	if len(cloudConfig.Global.AutoprovisioningInstanceConfigurationID) > 0 &&
+		(cloudConfig.Global.AutoprovisioningAvailabilityDomain == "" || cloudConfig.Global.AutoprovisioningSubnetID == "") {
+		return nil, errors.New("autoprovisioning-availability-domain and autoprovisioning-subnet-id are required when autoprovisioning-instance-configuration-id is set")
+	}
 	return cloudConfig, nil
 }
+
+// KubeletReservation returns the kubelet reservation that should be applied to template nodes.
+func (c *CloudConfig) KubeletReservation() (*KubeletReservation, error) {
+	return NewKubeletReservation(c.Global.KubeReserved, c.Global.SystemReserved, c.Global.EvictionHard)
+}
+
+// FlexShapeMemoryPerOcpuInGBs returns the memory per OCPU assumed for flexible shapes configured without memory.
+func (c *CloudConfig) FlexShapeMemoryPerOcpuInGBs() (map[string]float32, error) {
+	memoryPerOcpu, err := ParseFlexShapeMemoryPerOcpu(c.Global.FlexShapeMemoryPerOcpu)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid flex-shape-memory-per-ocpu value %q", c.Global.FlexShapeMemoryPerOcpu)
+	}
+	return memoryPerOcpu, nil
+}
+
+// MaxNodeProvisionTimeByShape returns the max node provision time of node groups by shape.
+func (c *CloudConfig) MaxNodeProvisionTimeByShape() (map[string]time.Duration, error) {
+	byShape, err := ParseMaxNodeProvisionTimeByShape(c.Global.MaxNodeProvisionTime)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid max-node-provision-time value %q", c.Global.MaxNodeProvisionTime)
+	}
+	return byShape, nil
+}
+
+// ShapeSnapshot returns the snapshot of shape capacity and prices, or nil if there is none.
+func (c *CloudConfig) ShapeSnapshot() (*ShapeSnapshot, error) {
+	if c.Global.ShapeSnapshotFile == "" {
+		return nil, nil
+	}
+	return LoadShapeSnapshot(c.Global.ShapeSnapshotFile)
+}
+
+// MonitoringPublisher returns the publisher of OCI Monitoring metrics, or nil if metrics are not published.
+func (c *CloudConfig) MonitoringPublisher(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter) (*MonitoringPublisher, error) {
+	if c.Global.MonitoringNamespace == "" {
+		return nil, nil
+	}
+	client, err := NewMonitoringClient(configProvider, clientConfig, rateLimiter, c.CircuitBreaker())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create monitoring client")
+	}
+	compartmentID := c.Global.MonitoringCompartmentID
+	if compartmentID == "" {
+		compartmentID = c.Global.CompartmentID
+	}
+	return NewMonitoringPublisher(client, c.Global.MonitoringNamespace, compartmentID), nil
+}
+
+// PreTerminationHook returns the hook invoked before instances are terminated, or nil if there is none.
+func (c *CloudConfig) PreTerminationHook(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter) (*PreTerminationHook, error) {
+	if c.Global.PreTerminationFunctionID == "" {
+		return nil, nil
+	}
+	client, err := NewFunctionsInvokeClient(configProvider, clientConfig, rateLimiter, c.CircuitBreaker(),
+		c.Global.PreTerminationFunctionEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create functions invoke client")
+	}
+	return NewPreTerminationHook(client, c.Global.PreTerminationFunctionID, c.Global.PreTerminationTimeout,
+		c.Global.PreTerminationFailurePolicy), nil
+}
+
+// RateLimiter returns the rate limiter that should be applied to all OCI clients.
+func (c *CloudConfig) RateLimiter() *RateLimiter {
+	return NewRateLimiter(c.Global.ReadQPS, c.Global.ReadBurst, c.Global.MutateQPS, c.Global.MutateBurst)
+}
+
+// MutationLimiter returns the limiter of the mutations in flight, or nil if they are not limited.
+func (c *CloudConfig) MutationLimiter() *MutationLimiter {
+	return NewMutationLimiter(c.Global.MaxInFlightMutations, c.Global.MaxInFlightMutationsPerNodeGroup,
+		c.Global.MaxInFlightMutationWait)
+}
+
+// CircuitBreaker returns the settings of the circuit breakers of the OCI clients, or nil if they are disabled.
+func (c *CloudConfig) CircuitBreaker() *CircuitBreaker {
+	if !c.Global.CircuitBreakerEnabled {
+		return nil
+	}
+	return NewCircuitBreaker(c.Global.CircuitBreakerFailureRateThreshold, c.Global.CircuitBreakerMinimumRequests,
+		c.Global.CircuitBreakerResetTimeout)
+}
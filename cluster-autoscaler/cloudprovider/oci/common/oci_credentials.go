@@ -0,0 +1,157 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/klog/v2"
+)
+
+// DefaultCredentialsReloadInterval is how often the API key credentials are reloaded unless configured otherwise.
+const DefaultCredentialsReloadInterval = time.Minute
+
+// credentials are the API key credentials of a configuration provider at one point in time.
+type credentials struct {
+	tenancyOCID    string
+	userOCID       string
+	keyFingerprint string
+	region         string
+	keyID          string
+	privateKey     *rsa.PrivateKey
+}
+
+// ReloadingConfigurationProvider serves the API key credentials of a configuration provider, e.g. one reading the
+// mounted OCI config file, and reloads them periodically so rotated keys are used without restarting the autoscaler.
+// The clients do not have to be rebuilt as the SDK asks the configuration provider for the key to sign every request.
+// Instance principals and workload identity are not wrapped, the SDK refreshes their tokens on its own.
+type ReloadingConfigurationProvider struct {
+	provider common.ConfigurationProvider
+	interval time.Duration
+
+	mu          sync.RWMutex
+	credentials *credentials
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewReloadingConfigurationProvider loads the credentials of the specified provider and reloads them every interval
+// until Stop is called. The interval defaults to DefaultCredentialsReloadInterval when it is not positive.
+func NewReloadingConfigurationProvider(provider common.ConfigurationProvider, interval time.Duration) (*ReloadingConfigurationProvider, error) {
+	c, err := loadCredentials(provider)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultCredentialsReloadInterval
+	}
+	p := &ReloadingConfigurationProvider{
+		provider:    provider,
+		interval:    interval,
+		credentials: c,
+		stopCh:      make(chan struct{}),
+	}
+	go wait.Until(p.Reload, interval, p.stopCh)
+	return p, nil
+}
+
+// Reload replaces the credentials if they changed. The previous credentials are kept if the new ones cannot be
+// loaded, e.g. while the files of a rotated key are only partially updated.
+func (p *ReloadingConfigurationProvider) Reload() {
+	c, err := loadCredentials(p.provider)
+	if err != nil {
+		klog.Warningf("unable to reload OCI credentials, keeping the previous ones: %v", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.credentials.keyID == c.keyID && p.credentials.privateKey.Equal(c.privateKey) {
+		return
+	}
+	klog.Infof("reloaded OCI credentials with key fingerprint %s", c.keyFingerprint)
+	p.credentials = c
+}
+
+// Stop stops reloading the credentials. Stop on a nil ReloadingConfigurationProvider is a no-op.
+func (p *ReloadingConfigurationProvider) Stop() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+func (p *ReloadingConfigurationProvider) current() *credentials {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.credentials
+}
+
+// PrivateRSAKey implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	return p.current().privateKey, nil
+}
+
+// KeyID implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) KeyID() (string, error) {
+	return p.current().keyID, nil
+}
+
+// TenancyOCID implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) TenancyOCID() (string, error) {
+	return p.current().tenancyOCID, nil
+}
+
+// UserOCID implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) UserOCID() (string, error) {
+	return p.current().userOCID, nil
+}
+
+// KeyFingerprint implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) KeyFingerprint() (string, error) {
+	return p.current().keyFingerprint, nil
+}
+
+// Region implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) Region() (string, error) {
+	return p.current().region, nil
+}
+
+// AuthType implements common.ConfigurationProvider.
+func (p *ReloadingConfigurationProvider) AuthType() (common.AuthConfig, error) {
+	return p.provider.AuthType()
+}
+
+// loadCredentials reads all credentials of the provider so that a key and the key ID it belongs to are swapped
+// together.
+func loadCredentials(provider common.ConfigurationProvider) (*credentials, error) {
+	var c credentials
+	var err error
+	if c.tenancyOCID, err = provider.TenancyOCID(); err != nil {
+		return nil, fmt.Errorf("unable to load tenancy OCID: %v", err)
+	}
+	if c.userOCID, err = provider.UserOCID(); err != nil {
+		return nil, fmt.Errorf("unable to load user OCID: %v", err)
+	}
+	if c.keyFingerprint, err = provider.KeyFingerprint(); err != nil {
+		return nil, fmt.Errorf("unable to load key fingerprint: %v", err)
+	}
+	if c.region, err = provider.Region(); err != nil {
+		return nil, fmt.Errorf("unable to load region: %v", err)
+	}
+	if c.keyID, err = provider.KeyID(); err != nil {
+		return nil, fmt.Errorf("unable to load key ID: %v", err)
+	}
+	if c.privateKey, err = provider.PrivateRSAKey(); err != nil {
+		return nil, fmt.Errorf("unable to load private key: %v", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+)
+
+type mockConfigurationProvider struct {
+	mu          sync.Mutex
+	fingerprint string
+	key         *rsa.PrivateKey
+	err         error
+}
+
+func (m *mockConfigurationProvider) set(fingerprint string, key *rsa.PrivateKey, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fingerprint, m.key, m.err = fingerprint, key, err
+}
+
+func (m *mockConfigurationProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.key, m.err
+}
+
+func (m *mockConfigurationProvider) KeyID() (string, error) {
+	fingerprint, err := m.KeyFingerprint()
+	return "ocid1.tenancy.oc1..aaaaaaaa/ocid1.user.oc1..aaaaaaaa/" + fingerprint, err
+}
+
+func (m *mockConfigurationProvider) TenancyOCID() (string, error) {
+	return "ocid1.tenancy.oc1..aaaaaaaa", nil
+}
+
+func (m *mockConfigurationProvider) UserOCID() (string, error) {
+	return "ocid1.user.oc1..aaaaaaaa", nil
+}
+
+func (m *mockConfigurationProvider) KeyFingerprint() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fingerprint, nil
+}
+
+func (m *mockConfigurationProvider) Region() (string, error) {
+	return "us-phoenix-1", nil
+}
+
+func (m *mockConfigurationProvider) AuthType() (common.AuthConfig, error) {
+	return common.AuthConfig{AuthType: common.UserPrincipal}, nil
+}
+
+func TestReloadingConfigurationProvider(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockConfigurationProvider{fingerprint: "aa:aa", key: oldKey}
+	provider, err := NewReloadingConfigurationProvider(mock, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Stop()
+
+	assertCredentials := func(expectedKeyID string, expectedKey *rsa.PrivateKey) {
+		t.Helper()
+		keyID, _ := provider.KeyID()
+		if keyID != expectedKeyID {
+			t.Errorf("got key ID %v ; wanted %v", keyID, expectedKeyID)
+		}
+		key, _ := provider.PrivateRSAKey()
+		if !key.Equal(expectedKey) {
+			t.Errorf("got a different private key than wanted")
+		}
+	}
+	assertCredentials("ocid1.tenancy.oc1..aaaaaaaa/ocid1.user.oc1..aaaaaaaa/aa:aa", oldKey)
+
+	// The key file of a rotated key is not readable yet.
+	mock.set("bb:bb", nil, errors.New("can not read PrivateKey"))
+	provider.Reload()
+	assertCredentials("ocid1.tenancy.oc1..aaaaaaaa/ocid1.user.oc1..aaaaaaaa/aa:aa", oldKey)
+
+	mock.set("bb:bb", newKey, nil)
+	provider.Reload()
+	assertCredentials("ocid1.tenancy.oc1..aaaaaaaa/ocid1.user.oc1..aaaaaaaa/bb:bb", newKey)
+
+	if ok, err := common.IsConfigurationProviderValid(provider); !ok {
+		t.Errorf("got invalid configuration provider ; wanted a valid one: %v", err)
+	}
+
+	// Stop is idempotent and a no-op on a nil provider.
+	provider.Stop()
+	var none *ReloadingConfigurationProvider
+	none.Stop()
+}
+
+func TestNewReloadingConfigurationProviderInvalid(t *testing.T) {
+	mock := &mockConfigurationProvider{fingerprint: "aa:aa", err: errors.New("can not read PrivateKey")}
+	if _, err := NewReloadingConfigurationProvider(mock, time.Hour); err == nil {
+		t.Errorf("got no error for invalid credentials ; wanted an error")
+	}
+}
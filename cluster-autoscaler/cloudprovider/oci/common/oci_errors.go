@@ -0,0 +1,179 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+)
+
+const (
+	// ErrorCodeLimitExceeded is reported when a service limit prevents launching more instances.
+	ErrorCodeLimitExceeded = "LimitExceeded"
+	// ErrorCodeQuotaExceeded is reported when a compartment quota prevents launching more instances.
+	ErrorCodeQuotaExceeded = "QuotaExceeded"
+	// ErrorCodeOutOfCapacity is reported when an availability domain is out of host capacity for a shape.
+	ErrorCodeOutOfCapacity = "OutOfCapacity"
+	// ErrorCodeTooManyRequests is reported when OCI throttles API calls. Throttling is transient, so it is not treated
+	// as running out of resources.
+	ErrorCodeTooManyRequests = "TooManyRequests"
+	// ErrorCodeNotAuthorizedOrNotFound is reported when a resource does not exist or is not accessible.
+	ErrorCodeNotAuthorizedOrNotFound = "NotAuthorizedOrNotFound"
+	// ErrorCodeInternalServerError and ErrorCodeInternalError are reported for errors without a more specific code.
+	ErrorCodeInternalServerError = "InternalServerError"
+	ErrorCodeInternalError       = "InternalError"
+	// ErrorCodeNodeCycling is reported when a node group is not resized because OKE is cycling its nodes.
+	ErrorCodeNodeCycling = "NodeCycling"
+)
+
+// outOfResourcesErrorCodes are the error codes that mean a node group cannot grow until resources are freed or limits
+// are raised, so the core autoscaler should back off from it.
+var outOfResourcesErrorCodes = map[string]bool{
+	ErrorCodeLimitExceeded: true,
+	ErrorCodeQuotaExceeded: true,
+	ErrorCodeOutOfCapacity: true,
+}
+
+// outOfResourcesPhrases are the phrases that identify internal errors caused by quotas, service limits or capacity.
+var outOfResourcesPhrases = []string{
+	"quota",
+	"service limit",
+	"limitexceeded",
+	"limit exceeded",
+	"out of host capacity",
+	"out of capacity",
+}
+
+// ErrorClass returns the cloudprovider.InstanceErrorClass of the specified OCI error code and message. Internal errors
+// are only considered out of resources if their message mentions a quota, service limit or lack of capacity.
+func ErrorClass(code, message string) cloudprovider.InstanceErrorClass {
+	if outOfResourcesErrorCodes[code] {
+		return cloudprovider.OutOfResourcesErrorClass
+	}
+	if code == ErrorCodeInternalServerError || code == ErrorCodeInternalError {
+		lowerMessage := strings.ToLower(message)
+		for _, phrase := range outOfResourcesPhrases {
+			if strings.Contains(lowerMessage, phrase) {
+				return cloudprovider.OutOfResourcesErrorClass
+			}
+		}
+	}
+	return cloudprovider.OtherErrorClass
+}
+
+// IsOutOfCapacity returns true if the specified error message reports that an availability domain is out of host
+// capacity for a shape.
+func IsOutOfCapacity(message string) bool {
+	lowerMessage := strings.ToLower(message)
+	return strings.Contains(lowerMessage, strings.ToLower(ErrorCodeOutOfCapacity)) ||
+		strings.Contains(lowerMessage, "out of host capacity")
+}
+
+// ServiceLimitError is returned when the service limits do not allow a node group to grow by the requested number of
+// instances. It carries the InstanceErrorInfo the failed part of the scale-up should be reported with.
+type ServiceLimitError struct {
+	NodeGroupID string
+	Requested   int
+	Available   int
+}
+
+// Error implements the error interface.
+func (e *ServiceLimitError) Error() string {
+	return fmt.Sprintf("%s: service limits only allow %d of the %d requested instances in node group %s",
+		ErrorCodeLimitExceeded, e.Available, e.Requested, e.NodeGroupID)
+}
+
+// ErrorInfo returns the InstanceErrorInfo describing the error, which is always out of resources.
+func (e *ServiceLimitError) ErrorInfo() cloudprovider.InstanceErrorInfo {
+	return cloudprovider.InstanceErrorInfo{
+		ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+		ErrorCode:    ErrorCodeLimitExceeded,
+		ErrorMessage: e.Error(),
+	}
+}
+
+// HostCapacityError is returned when a capacity report shows that a node group cannot launch any instances.
+type HostCapacityError struct {
+	NodeGroupID string
+}
+
+// Error implements the error interface.
+func (e *HostCapacityError) Error() string {
+	return fmt.Sprintf("%s: no availability domain has host capacity for the shape of node group %s", ErrorCodeOutOfCapacity, e.NodeGroupID)
+}
+
+// ErrorInfo returns the InstanceErrorInfo describing the error, which is always out of resources.
+func (e *HostCapacityError) ErrorInfo() cloudprovider.InstanceErrorInfo {
+	return cloudprovider.InstanceErrorInfo{
+		ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+		ErrorCode:    ErrorCodeOutOfCapacity,
+		ErrorMessage: e.Error(),
+	}
+}
+
+// PreemptibleUnavailableError is returned when a preemptible node group with an on-demand fallback node group is not
+// scaled up, because it recently ran out of capacity or its instances were preempted in a storm.
+type PreemptibleUnavailableError struct {
+	NodeGroupID         string
+	FallbackNodeGroupID string
+	Reason              string
+	Until               time.Time
+}
+
+// Error implements the error interface.
+func (e *PreemptibleUnavailableError) Error() string {
+	return fmt.Sprintf("%s: preemptible node group %s is unavailable until %s since %s, scale up node group %s instead",
+		ErrorCodeOutOfCapacity, e.NodeGroupID, e.Until.Format(time.RFC3339), e.Reason, e.FallbackNodeGroupID)
+}
+
+// ErrorInfo returns the InstanceErrorInfo describing the error, which is always out of resources.
+func (e *PreemptibleUnavailableError) ErrorInfo() cloudprovider.InstanceErrorInfo {
+	return cloudprovider.InstanceErrorInfo{
+		ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+		ErrorCode:    ErrorCodeOutOfCapacity,
+		ErrorMessage: e.Error(),
+	}
+}
+
+// NodeCyclingError is returned when a node group is not resized because OKE is cycling its nodes, e.g. to upgrade their
+// image or Kubernetes version, so the autoscaler does not fight the cycling.
+type NodeCyclingError struct {
+	NodeGroupID   string
+	WorkRequestID string
+}
+
+// Error implements the error interface.
+func (e *NodeCyclingError) Error() string {
+	return fmt.Sprintf("%s: node group %s is not resized while work request %s cycles its nodes",
+		ErrorCodeNodeCycling, e.NodeGroupID, e.WorkRequestID)
+}
+
+// ErrorInfo returns the InstanceErrorInfo describing the error. Cycling is transient, so it is not out of resources.
+func (e *NodeCyclingError) ErrorInfo() cloudprovider.InstanceErrorInfo {
+	return cloudprovider.InstanceErrorInfo{
+		ErrorClass:   cloudprovider.OtherErrorClass,
+		ErrorCode:    ErrorCodeNodeCycling,
+		ErrorMessage: e.Error(),
+	}
+}
+
+// ResizedExternallyError is returned when a node group is not resized because it was resized outside the autoscaler,
+// e.g. in the OCI console, since its size was last refreshed. The requested size was based on the stale size, so
+// resizing to it would revert the external resize. The new size is adopted, so the resize can be retried from it.
+type ResizedExternallyError struct {
+	NodeGroupID   string
+	CachedSize    int
+	ActualSize    int
+	RequestedSize int
+}
+
+// Error implements the error interface.
+func (e *ResizedExternallyError) Error() string {
+	return fmt.Sprintf("node group %s was resized from %d to %d outside the autoscaler, not resizing it to %d",
+		e.NodeGroupID, e.CachedSize, e.ActualSize, e.RequestedSize)
+}
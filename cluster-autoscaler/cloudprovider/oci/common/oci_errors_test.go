@@ -0,0 +1,98 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+)
+
+func TestErrorClass(t *testing.T) {
+	testCases := map[string]struct {
+		code     string
+		message  string
+		expected cloudprovider.InstanceErrorClass
+	}{
+		"limit exceeded": {
+			code:     "LimitExceeded",
+			expected: cloudprovider.OutOfResourcesErrorClass,
+		},
+		"out of capacity": {
+			code:     "OutOfCapacity",
+			expected: cloudprovider.OutOfResourcesErrorClass,
+		},
+		"throttled": {
+			code:     "TooManyRequests",
+			expected: cloudprovider.OtherErrorClass,
+		},
+		"not found": {
+			code:     "NotAuthorizedOrNotFound",
+			expected: cloudprovider.OtherErrorClass,
+		},
+		"internal error mentioning quota": {
+			code:     "InternalServerError",
+			message:  "blah blah quota exceeded blah blah",
+			expected: cloudprovider.OutOfResourcesErrorClass,
+		},
+		"internal error mentioning host capacity": {
+			code:     "InternalError",
+			message:  "Out of host capacity.",
+			expected: cloudprovider.OutOfResourcesErrorClass,
+		},
+		"internal error mentioning rate limit": {
+			code:     "InternalServerError",
+			message:  "request exceeded the rate limit, retry later",
+			expected: cloudprovider.OtherErrorClass,
+		},
+		"internal error": {
+			code:     "InternalServerError",
+			message:  "something went wrong",
+			expected: cloudprovider.OtherErrorClass,
+		},
+		"unknown": {
+			code:     "unknown",
+			expected: cloudprovider.OtherErrorClass,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if class := ErrorClass(tc.code, tc.message); class != tc.expected {
+				t.Errorf("got %v ; wanted %v", class, tc.expected)
+			}
+		})
+	}
+}
+
+func TestServiceLimitErrorInfo(t *testing.T) {
+	err := &ServiceLimitError{NodeGroupID: "ocid1.instancepool.oc1.phx.aaaaaaaa1", Requested: 3, Available: 1}
+	info := err.ErrorInfo()
+	if info.ErrorClass != cloudprovider.OutOfResourcesErrorClass {
+		t.Errorf("got error class %v ; wanted %v", info.ErrorClass, cloudprovider.OutOfResourcesErrorClass)
+	}
+	if info.ErrorCode != ErrorCodeLimitExceeded {
+		t.Errorf("got error code %q ; wanted %q", info.ErrorCode, ErrorCodeLimitExceeded)
+	}
+}
+
+func TestIsOutOfCapacity(t *testing.T) {
+	testCases := map[string]struct {
+		message  string
+		expected bool
+	}{
+		"error code":     {message: "OutOfCapacity: failed to launch instance", expected: true},
+		"host capacity":  {message: "Out of host capacity.", expected: true},
+		"limit exceeded": {message: "LimitExceeded: standard-e4-core-count", expected: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsOutOfCapacity(tc.message); got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
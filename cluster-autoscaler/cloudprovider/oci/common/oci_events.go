@@ -0,0 +1,63 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ResizedExternallyReason is the reason of the events reporting a node group that was resized outside the
+	// autoscaler, e.g. in the OCI console.
+	ResizedExternallyReason = "NodeGroupResizedExternally"
+
+	// statusConfigMapNamespace and statusConfigMapName identify the status ConfigMap of the autoscaler in its default
+	// namespace. Node group events are recorded on it, so they are listed by
+	// "kubectl describe configmap cluster-autoscaler-status -n kube-system" next to the events of the core autoscaler.
+	statusConfigMapNamespace = "kube-system"
+	statusConfigMapName      = "cluster-autoscaler-status"
+	// eventSource is the component node group events are reported by.
+	eventSource = "cluster-autoscaler"
+)
+
+// RecordNodeGroupEvent records an event about a node group on the status ConfigMap of the autoscaler. Events are not
+// recorded if the client is nil.
+func RecordNodeGroupEvent(kubeClient kubernetes.Interface, eventType, reason, message string) {
+	if kubeClient == nil {
+		return
+	}
+	t := time.Now()
+	now := metav1.NewTime(t)
+	event := &apiv1.Event{
+		// Events are named like the ones of the client-go event recorder.
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", statusConfigMapName, t.UnixNano()),
+			Namespace: statusConfigMapNamespace,
+		},
+		InvolvedObject: apiv1.ObjectReference{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+			Namespace:  statusConfigMapNamespace,
+			Name:       statusConfigMapName,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         apiv1.EventSource{Component: eventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := kubeClient.CoreV1().Events(statusConfigMapNamespace).Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+		klog.Warningf("unable to record event %q: %v", message, err)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"strings"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/klog/v2"
+)
+
+// gpuShapeProduct maps the family of a GPU shape to the product name the NVIDIA GPU feature discovery reports for its GPUs.
+type gpuShapeProduct struct {
+	shapeFamily string
+	product     string
+}
+
+// gpuShapeProducts holds the known GPU shape families. More specific families must come before the families they are a
+// prefix of, e.g. GPU.A100-v2 before GPU.A100.
+var gpuShapeProducts = []gpuShapeProduct{
+	{shapeFamily: "GPU2.", product: "Tesla-P100-PCIE-16GB"},
+	{shapeFamily: "GPU3.", product: "Tesla-V100-SXM2-16GB"},
+	{shapeFamily: "GPU4.", product: "NVIDIA-A100-SXM4-40GB"},
+	{shapeFamily: "GPU.A100-v2.", product: "NVIDIA-A100-SXM4-80GB"},
+	{shapeFamily: "GPU.A10.", product: "NVIDIA-A10"},
+	{shapeFamily: "GPU.H100.", product: "NVIDIA-H100-80GB-HBM3"},
+	{shapeFamily: "GPU.L40S.", product: "NVIDIA-L40S"},
+	{shapeFamily: "GPU.A100.", product: "NVIDIA-A100-SXM4-40GB"},
+}
+
+// GPUProductForShape returns the GPU product of the specified shape, or an empty string if the shape has no known GPU.
+func GPUProductForShape(shape string) string {
+	// Strip the VM. or BM. prefix.
+	parts := strings.SplitN(shape, ".", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	for _, p := range gpuShapeProducts {
+		if strings.HasPrefix(parts[1], p.shapeFamily) {
+			return p.product
+		}
+	}
+	return ""
+}
+
+// AvailableGPUTypes returns the GPU products of the template nodes of the node groups, i.e. the values of the
+// GPUProductLabel the nodes of the node groups are expected to have.
+func AvailableGPUTypes(nodeGroups []cloudprovider.NodeGroup) map[string]struct{} {
+	gpuTypes := map[string]struct{}{}
+	for _, nodeGroup := range nodeGroups {
+		nodeInfo, err := nodeGroup.TemplateNodeInfo()
+		if err != nil {
+			klog.V(4).Infof("unable to get the GPU type of node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		if gpuType := nodeInfo.Node().Labels[consts.GPUProductLabel]; gpuType != "" {
+			gpuTypes[gpuType] = struct{}{}
+		}
+	}
+	return gpuTypes
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import "testing"
+
+func TestGPUProductForShape(t *testing.T) {
+	testCases := map[string]string{
+		"VM.GPU2.1":           "Tesla-P100-PCIE-16GB",
+		"BM.GPU3.8":           "Tesla-V100-SXM2-16GB",
+		"BM.GPU4.8":           "NVIDIA-A100-SXM4-40GB",
+		"BM.GPU.A100-v2.8":    "NVIDIA-A100-SXM4-80GB",
+		"VM.GPU.A10.2":        "NVIDIA-A10",
+		"BM.GPU.H100.8":       "NVIDIA-H100-80GB-HBM3",
+		"VM.Standard.E4.Flex": "",
+		"":                    "",
+	}
+
+	for shape, expected := range testCases {
+		if got := GPUProductForShape(shape); got != expected {
+			t.Errorf("got %q for shape %q ; wanted %q", got, shape, expected)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common/auth"
+)
+
+const (
+	// limitsServiceComputeName is the name of the compute service in the OCI Limits service.
+	limitsServiceComputeName = "compute"
+	// limitsServiceBasePath is the API version of the OCI Limits service.
+	limitsServiceBasePath = "20190729"
+	// limitsServiceEndpointTemplate is the endpoint template of the OCI Limits service.
+	limitsServiceEndpointTemplate = "https://limits.{region}.{secondLevelDomain}"
+)
+
+// GetResourceAvailabilityRequest is the request of the Limits GetResourceAvailability operation.
+type GetResourceAvailabilityRequest struct {
+	ServiceName        *string `mandatory:"true" contributesTo:"path" name:"serviceName"`
+	LimitName          *string `mandatory:"true" contributesTo:"path" name:"limitName"`
+	CompartmentID      *string `mandatory:"true" contributesTo:"query" name:"compartmentId"`
+	AvailabilityDomain *string `mandatory:"false" contributesTo:"query" name:"availabilityDomain"`
+}
+
+// ResourceAvailability is the usage and availability of a specific service limit.
+type ResourceAvailability struct {
+	Used      *int64 `mandatory:"false" json:"used"`
+	Available *int64 `mandatory:"false" json:"available"`
+}
+
+// GetResourceAvailabilityResponse is the response of the Limits GetResourceAvailability operation.
+type GetResourceAvailabilityResponse struct {
+	RawResponse          *http.Response
+	ResourceAvailability `presentIn:"body"`
+	OpcRequestID         *string `presentIn:"header" name:"opc-request-id"`
+}
+
+// LimitsClient is an interface around the OCI Limits service calls we require.
+type LimitsClient interface {
+	GetResourceAvailability(context.Context, GetResourceAvailabilityRequest) (GetResourceAvailabilityResponse, error)
+}
+
+// LimitsClientImpl is the implementation of a client of the OCI Limits service.
+type LimitsClientImpl struct {
+	common.BaseClient
+}
+
+// NewLimitsClient creates a client of the OCI Limits service using the given configuration provider and rate limiter.
+func NewLimitsClient(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter, circuitBreaker *CircuitBreaker) (*LimitsClientImpl, error) {
+	provider, err := auth.GetGenericConfigurationProvider(configProvider)
+	if err != nil {
+		return nil, err
+	}
+	baseClient, err := common.NewClientWithConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	region, err := provider.Region()
+	if err != nil {
+		return nil, err
+	}
+	baseClient.BasePath = limitsServiceBasePath
+	baseClient.Host = common.StringToRegion(region).EndpointForTemplate("limits", limitsServiceEndpointTemplate)
+	baseClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&baseClient)
+	rateLimiter.Apply(&baseClient)
+	LogRequestIDs(&baseClient)
+	return &LimitsClientImpl{BaseClient: baseClient}, nil
+}
+
+// GetResourceAvailability gets the usage and availability of the specified service limit.
+func (c *LimitsClientImpl) GetResourceAvailability(ctx context.Context, req GetResourceAvailabilityRequest) (GetResourceAvailabilityResponse, error) {
+	var response GetResourceAvailabilityResponse
+	httpRequest, err := common.MakeDefaultHTTPRequestWithTaggedStruct(http.MethodGet,
+		"/services/{serviceName}/limits/{limitName}/resourceAvailability", req)
+	if err != nil {
+		return response, err
+	}
+
+	httpResponse, err := c.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// shapeServiceLimitNames maps the prefix of the name of a shape family to the name of the compute service limit that
+// constrains it. See https://docs.oracle.com/en-us/iaas/Content/General/Concepts/servicelimits.htm#computelimits
+var shapeServiceLimitNames = map[string]string{
+	"VM.Standard2.":   "standard2-core-count",
+	"BM.Standard2.":   "standard2-core-count",
+	"VM.Standard3.":   "standard3-core-count",
+	"BM.Standard3.":   "standard3-core-count",
+	"VM.Standard.E2.": "standard-e2-core-ad-count",
+	"BM.Standard.E2.": "standard-e2-core-ad-count",
+	"VM.Standard.E3.": "standard-e3-core-ad-count",
+	"BM.Standard.E3.": "standard-e3-core-ad-count",
+	"VM.Standard.E4.": "standard-e4-core-count",
+	"BM.Standard.E4.": "standard-e4-core-count",
+	"VM.Standard.E5.": "standard-e5-core-count",
+	"BM.Standard.E5.": "standard-e5-core-count",
+	"VM.Standard.A1.": "standard-a1-core-count",
+	"BM.Standard.A1.": "standard-a1-core-count",
+	"VM.Optimized3.":  "optimized3-core-count",
+	"BM.Optimized3.":  "optimized3-core-count",
+	"VM.GPU2.":        "gpu2-count",
+	"BM.GPU2.":        "gpu2-count",
+	"VM.GPU3.":        "gpu3-count",
+	"BM.GPU3.":        "gpu3-count",
+	"BM.GPU4.":        "gpu4-count",
+	"VM.GPU.A10.":     "gpu-a10-count",
+	"BM.GPU.A10.":     "gpu-a10-count",
+}
+
+// ShapeServiceLimit returns the name of the compute service limit that constrains instances of the specified shape,
+// or an empty string if the limit of the shape is not known.
+func ShapeServiceLimit(shape *Shape) string {
+	for prefix, limitName := range shapeServiceLimitNames {
+		if strings.HasPrefix(shape.Name, prefix) {
+			return limitName
+		}
+	}
+	return ""
+}
+
+// ServiceLimitUnitsPerInstance returns the number of units of the specified compute service limit a single instance of
+// the specified shape consumes. Core limits are consumed per OCPU, GPU limits per GPU and any other limit per instance.
+func ServiceLimitUnitsPerInstance(limitName string, shape *Shape) int64 {
+	var units int64 = 1
+	switch {
+	case strings.Contains(limitName, "-core-"):
+		units = int64(shape.CPU)
+	case strings.HasPrefix(limitName, "gpu"):
+		units = int64(shape.GPU)
+	}
+	if units < 1 {
+		units = 1
+	}
+	return units
+}
+
+// AvailableInstanceCount returns the number of additional instances of the specified shape allowed by the given
+// service limit in the specified compartment and availability domains. If the limit is scoped to the region rather
+// than an availability domain, the regional availability is used instead.
+func AvailableInstanceCount(client LimitsClient, limitName string, unitsPerInstance int64, compartmentID string, availabilityDomains []string) (int, error) {
+	if unitsPerInstance < 1 {
+		unitsPerInstance = 1
+	}
+
+	getAvailable := func(availabilityDomain *string) (int64, error) {
+		resp, err := client.GetResourceAvailability(context.Background(), GetResourceAvailabilityRequest{
+			ServiceName:        common.String(limitsServiceComputeName),
+			LimitName:          common.String(limitName),
+			CompartmentID:      common.String(compartmentID),
+			AvailabilityDomain: availabilityDomain,
+		})
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to get resource availability of limit %q", limitName)
+		}
+		if resp.Available == nil {
+			return 0, fmt.Errorf("resource availability of limit %q is unknown", limitName)
+		}
+		return *resp.Available, nil
+	}
+
+	var available int64
+	for _, availabilityDomain := range availabilityDomains {
+		availableInAD, err := getAvailable(common.String(availabilityDomain))
+		if err != nil {
+			// The limit may be regional rather than AD specific.
+			if serviceErr, ok := common.IsServiceError(errors.Cause(err)); ok && serviceErr.GetHTTPStatusCode() == http.StatusBadRequest {
+				regionalAvailable, regionalErr := getAvailable(nil)
+				if regionalErr != nil {
+					return 0, regionalErr
+				}
+				return int(regionalAvailable / unitsPerInstance), nil
+			}
+			return 0, err
+		}
+		available += availableInAD
+	}
+	return int(available / unitsPerInstance), nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+)
+
+type mockServiceError struct {
+	statusCode   int
+	code         string
+	message      string
+	opcRequestID string
+}
+
+func (e mockServiceError) GetHTTPStatusCode() int  { return e.statusCode }
+func (e mockServiceError) GetMessage() string      { return e.message }
+func (e mockServiceError) GetCode() string         { return e.code }
+func (e mockServiceError) GetOpcRequestID() string { return e.opcRequestID }
+func (e mockServiceError) Error() string           { return e.message }
+
+type mockLimitsClient struct {
+	// available holds the availability per availability domain, the regional availability is keyed by "".
+	available map[string]int64
+	// regional makes availability domain scoped requests fail like they do for regional limits.
+	regional bool
+	err      error
+}
+
+func (m *mockLimitsClient) GetResourceAvailability(_ context.Context, req GetResourceAvailabilityRequest) (GetResourceAvailabilityResponse, error) {
+	if m.err != nil {
+		return GetResourceAvailabilityResponse{}, m.err
+	}
+	var availabilityDomain string
+	if req.AvailabilityDomain != nil {
+		availabilityDomain = *req.AvailabilityDomain
+	}
+	if m.regional && availabilityDomain != "" {
+		return GetResourceAvailabilityResponse{}, mockServiceError{statusCode: 400, code: "InvalidParameter", message: "limit is not AD specific"}
+	}
+	return GetResourceAvailabilityResponse{
+		ResourceAvailability: ResourceAvailability{
+			Available: common.Int64(m.available[availabilityDomain]),
+		},
+	}, nil
+}
+
+func TestShapeServiceLimit(t *testing.T) {
+	testCases := map[string]struct {
+		shape         *Shape
+		expectedName  string
+		expectedUnits int64
+	}{
+		"flex shape": {
+			shape:         &Shape{Name: "VM.Standard.E4.Flex", CPU: 4},
+			expectedName:  "standard-e4-core-count",
+			expectedUnits: 4,
+		},
+		"E3 flex shape": {
+			shape:         &Shape{Name: "VM.Standard.E3.Flex", CPU: 2},
+			expectedName:  "standard-e3-core-ad-count",
+			expectedUnits: 2,
+		},
+		"fixed shape": {
+			shape:         &Shape{Name: "VM.Standard2.8", CPU: 8},
+			expectedName:  "standard2-core-count",
+			expectedUnits: 8,
+		},
+		"bare metal shape": {
+			shape:         &Shape{Name: "BM.Standard2.52", CPU: 52},
+			expectedName:  "standard2-core-count",
+			expectedUnits: 52,
+		},
+		"GPU shape": {
+			shape:         &Shape{Name: "VM.GPU3.2", CPU: 12, GPU: 2},
+			expectedName:  "gpu3-count",
+			expectedUnits: 2,
+		},
+		"unknown shape": {
+			shape:         &Shape{Name: "VM.Unknown.1", CPU: 1},
+			expectedName:  "",
+			expectedUnits: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			limitName := ShapeServiceLimit(tc.shape)
+			if limitName != tc.expectedName {
+				t.Errorf("got limit name %q ; wanted %q", limitName, tc.expectedName)
+			}
+			if units := ServiceLimitUnitsPerInstance(limitName, tc.shape); units != tc.expectedUnits {
+				t.Errorf("got units %d ; wanted %d", units, tc.expectedUnits)
+			}
+		})
+	}
+}
+
+func TestAvailableInstanceCount(t *testing.T) {
+	client := &mockLimitsClient{
+		available: map[string]int64{
+			"hash:PHX-AD-1": 10,
+			"hash:PHX-AD-2": 7,
+		},
+	}
+
+	count, err := AvailableInstanceCount(client, "standard-e4-core-count", 4, "ocid1.compartment.oc1..aaaaaaaa1", []string{"hash:PHX-AD-1", "hash:PHX-AD-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	// 17 available cores allow 4 more instances with 4 cores each.
+	if count != 4 {
+		t.Errorf("got %d available instances ; wanted 4", count)
+	}
+}
+
+func TestAvailableInstanceCountRegionalLimit(t *testing.T) {
+	client := &mockLimitsClient{
+		available: map[string]int64{
+			"": 12,
+		},
+		regional: true,
+	}
+
+	count, err := AvailableInstanceCount(client, "gpu3-count", 2, "ocid1.compartment.oc1..aaaaaaaa1", []string{"hash:PHX-AD-1", "hash:PHX-AD-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if count != 6 {
+		t.Errorf("got %d available instances ; wanted 6", count)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"sync"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	caNamespace = "cluster_autoscaler"
+	// nodeGroupLabel is the label holding the OCID of the node group of a metric.
+	nodeGroupLabel = "node_group"
+)
+
+var (
+	nodeGroupTargetSize = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "oci_node_group_target_size",
+			Help:      "Target size of each OCI node group.",
+		}, []string{nodeGroupLabel},
+	)
+	nodeGroupRunningInstances = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "oci_node_group_running_instances",
+			Help:      "Number of running instances of each OCI node group.",
+		}, []string{nodeGroupLabel},
+	)
+	nodeGroupProvisioningInstances = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "oci_node_group_provisioning_instances",
+			Help:      "Number of instances of each OCI node group that are still being launched.",
+		}, []string{nodeGroupLabel},
+	)
+	nodeGroupFailedLaunches = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "oci_node_group_failed_launches",
+			Help:      "Number of instances of each OCI node group that failed to launch.",
+		}, []string{nodeGroupLabel},
+	)
+
+	// reportedNodeGroups holds the node groups metrics were last reported for, so the metrics of node groups that
+	// went away can be deleted.
+	reportedNodeGroups   = map[string]bool{}
+	reportedNodeGroupsMu sync.Mutex
+)
+
+// RegisterMetrics registers all OCI metrics.
+func RegisterMetrics() {
+	legacyregistry.MustRegister(nodeGroupTargetSize)
+	legacyregistry.MustRegister(nodeGroupRunningInstances)
+	legacyregistry.MustRegister(nodeGroupProvisioningInstances)
+	legacyregistry.MustRegister(nodeGroupFailedLaunches)
+}
+
+// NodeGroupHealth holds the target size of a node group and how many of its instances are running, provisioning or
+// failed to launch. The difference between the target size and the running instances is how far the node group lags
+// behind the capacity the autoscaler asked for.
+type NodeGroupHealth struct {
+	TargetSize     int
+	Running        int
+	Provisioning   int
+	FailedLaunches int
+}
+
+// NewNodeGroupHealth returns the health of a node group with the specified target size and instances.
+func NewNodeGroupHealth(targetSize int, instances []cloudprovider.Instance) NodeGroupHealth {
+	health := NodeGroupHealth{TargetSize: targetSize}
+	for _, instance := range instances {
+		if instance.Status == nil {
+			continue
+		}
+		switch {
+		case instance.Status.ErrorInfo != nil:
+			health.FailedLaunches++
+		case instance.Status.State == cloudprovider.InstanceRunning:
+			health.Running++
+		case instance.Status.State == cloudprovider.InstanceCreating:
+			health.Provisioning++
+		}
+	}
+	return health
+}
+
+// ReportNodeGroupHealth sets the metrics of the node groups to their health, and deletes the metrics of the node
+// groups they were reported for before that are not among them.
+func ReportNodeGroupHealth(healthByNodeGroup map[string]NodeGroupHealth) {
+	reportedNodeGroupsMu.Lock()
+	defer reportedNodeGroupsMu.Unlock()
+
+	for nodeGroupID := range reportedNodeGroups {
+		if _, ok := healthByNodeGroup[nodeGroupID]; !ok {
+			for _, gauge := range []*k8smetrics.GaugeVec{nodeGroupTargetSize, nodeGroupRunningInstances, nodeGroupProvisioningInstances, nodeGroupFailedLaunches} {
+				gauge.Delete(map[string]string{nodeGroupLabel: nodeGroupID})
+			}
+			delete(reportedNodeGroups, nodeGroupID)
+		}
+	}
+	for nodeGroupID, health := range healthByNodeGroup {
+		nodeGroupTargetSize.WithLabelValues(nodeGroupID).Set(float64(health.TargetSize))
+		nodeGroupRunningInstances.WithLabelValues(nodeGroupID).Set(float64(health.Running))
+		nodeGroupProvisioningInstances.WithLabelValues(nodeGroupID).Set(float64(health.Provisioning))
+		nodeGroupFailedLaunches.WithLabelValues(nodeGroupID).Set(float64(health.FailedLaunches))
+		reportedNodeGroups[nodeGroupID] = true
+	}
+}
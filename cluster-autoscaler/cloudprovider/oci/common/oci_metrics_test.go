@@ -0,0 +1,67 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestNewNodeGroupHealth(t *testing.T) {
+	instance := func(state cloudprovider.InstanceState, errorInfo *cloudprovider.InstanceErrorInfo) cloudprovider.Instance {
+		return cloudprovider.Instance{Status: &cloudprovider.InstanceStatus{State: state, ErrorInfo: errorInfo}}
+	}
+	instances := []cloudprovider.Instance{
+		instance(cloudprovider.InstanceRunning, nil),
+		instance(cloudprovider.InstanceRunning, nil),
+		instance(cloudprovider.InstanceCreating, nil),
+		instance(cloudprovider.InstanceCreating, &cloudprovider.InstanceErrorInfo{ErrorClass: cloudprovider.OutOfResourcesErrorClass}),
+		instance(0, &cloudprovider.InstanceErrorInfo{ErrorClass: cloudprovider.OtherErrorClass}),
+		instance(cloudprovider.InstanceDeleting, nil),
+		{},
+	}
+
+	expected := NodeGroupHealth{TargetSize: 5, Running: 2, Provisioning: 1, FailedLaunches: 2}
+	if got := NewNodeGroupHealth(5, instances); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v ; wanted %+v", got, expected)
+	}
+}
+
+func TestReportNodeGroupHealth(t *testing.T) {
+	registry := k8smetrics.NewKubeRegistry()
+	registry.MustRegister(nodeGroupTargetSize, nodeGroupRunningInstances, nodeGroupProvisioningInstances, nodeGroupFailedLaunches)
+
+	ReportNodeGroupHealth(map[string]NodeGroupHealth{
+		"ocid1.instancepool.oc1.phx.aaaaaaaa1": {TargetSize: 3, Running: 1, Provisioning: 1, FailedLaunches: 1},
+		"ocid1.instancepool.oc1.phx.aaaaaaaa2": {TargetSize: 1, Running: 1},
+	})
+	if got, err := testutil.GetGaugeMetricValue(nodeGroupTargetSize.WithLabelValues("ocid1.instancepool.oc1.phx.aaaaaaaa1")); err != nil || got != 3 {
+		t.Errorf("got target size %v (%v) ; wanted 3", got, err)
+	}
+	if got, err := testutil.GetGaugeMetricValue(nodeGroupFailedLaunches.WithLabelValues("ocid1.instancepool.oc1.phx.aaaaaaaa1")); err != nil || got != 1 {
+		t.Errorf("got failed launches %v (%v) ; wanted 1", got, err)
+	}
+
+	// The metrics of node groups that went away are deleted.
+	ReportNodeGroupHealth(map[string]NodeGroupHealth{
+		"ocid1.instancepool.oc1.phx.aaaaaaaa2": {TargetSize: 2, Running: 2},
+	})
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	for _, family := range families {
+		if len(family.Metric) != 1 {
+			t.Errorf("got %d %s metrics ; wanted 1", len(family.Metric), family.GetName())
+		}
+	}
+	if got, err := testutil.GetGaugeMetricValue(nodeGroupRunningInstances.WithLabelValues("ocid1.instancepool.oc1.phx.aaaaaaaa2")); err != nil || got != 2 {
+		t.Errorf("got running instances %v (%v) ; wanted 2", got, err)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common/auth"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// monitoringBasePath is the API version of the OCI Monitoring service.
+	monitoringBasePath = "20180401"
+	// monitoringIngestionEndpointTemplate is the endpoint template of the metric ingestion of the OCI Monitoring service.
+	monitoringIngestionEndpointTemplate = "https://telemetry-ingestion.{region}.oci.{secondLevelDomain}"
+	// monitoringMaxMetricStreams is the maximum number of metric streams a single PostMetricData request may contain.
+	monitoringMaxMetricStreams = 50
+
+	// ScaleUpCountMetric is the number of instances a node group was scaled up by.
+	ScaleUpCountMetric = "ScaleUpCount"
+	// ScaleDownCountMetric is the number of instances a node group was scaled down by.
+	ScaleDownCountMetric = "ScaleDownCount"
+	// FailedLaunchCountMetric is the number of instances a node group failed to launch.
+	FailedLaunchCountMetric = "FailedLaunchCount"
+	// NodeGroupDimension is the dimension holding the OCID of the node group of a metric.
+	NodeGroupDimension = "nodeGroupId"
+)
+
+// Datapoint is a single value of a metric.
+type Datapoint struct {
+	Timestamp *common.SDKTime `mandatory:"true" json:"timestamp"`
+	Value     *float64        `mandatory:"true" json:"value"`
+	Count     *int            `mandatory:"false" json:"count,omitempty"`
+}
+
+// MetricDataDetails are the datapoints of a metric stream.
+type MetricDataDetails struct {
+	Namespace     *string           `mandatory:"true" json:"namespace"`
+	CompartmentID *string           `mandatory:"true" json:"compartmentId"`
+	Name          *string           `mandatory:"true" json:"name"`
+	Dimensions    map[string]string `mandatory:"true" json:"dimensions"`
+	Datapoints    []Datapoint       `mandatory:"true" json:"datapoints"`
+}
+
+// PostMetricDataDetails are the metric streams of a PostMetricData request.
+type PostMetricDataDetails struct {
+	MetricData []MetricDataDetails `mandatory:"true" json:"metricData"`
+}
+
+// PostMetricDataRequest is the request of the Monitoring PostMetricData operation.
+type PostMetricDataRequest struct {
+	PostMetricDataDetails `contributesTo:"body"`
+}
+
+// PostMetricDataResponse is the response of the Monitoring PostMetricData operation.
+type PostMetricDataResponse struct {
+	RawResponse  *http.Response
+	OpcRequestID *string `presentIn:"header" name:"opc-request-id"`
+}
+
+// MonitoringClient is an interface around the OCI Monitoring service calls we require.
+type MonitoringClient interface {
+	PostMetricData(context.Context, PostMetricDataRequest) (PostMetricDataResponse, error)
+}
+
+// MonitoringClientImpl is the implementation of a client of the metric ingestion of the OCI Monitoring service.
+type MonitoringClientImpl struct {
+	common.BaseClient
+}
+
+// NewMonitoringClient creates a client of the OCI Monitoring service using the given configuration provider and rate
+// limiter.
+func NewMonitoringClient(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter, circuitBreaker *CircuitBreaker) (*MonitoringClientImpl, error) {
+	provider, err := auth.GetGenericConfigurationProvider(configProvider)
+	if err != nil {
+		return nil, err
+	}
+	baseClient, err := common.NewClientWithConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	region, err := provider.Region()
+	if err != nil {
+		return nil, err
+	}
+	baseClient.BasePath = monitoringBasePath
+	baseClient.Host = common.StringToRegion(region).EndpointForTemplate("telemetry-ingestion", monitoringIngestionEndpointTemplate)
+	baseClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&baseClient)
+	rateLimiter.Apply(&baseClient)
+	LogRequestIDs(&baseClient)
+	return &MonitoringClientImpl{BaseClient: baseClient}, nil
+}
+
+// PostMetricData publishes the specified metric datapoints.
+func (c *MonitoringClientImpl) PostMetricData(ctx context.Context, req PostMetricDataRequest) (PostMetricDataResponse, error) {
+	var response PostMetricDataResponse
+	httpRequest, err := common.MakeDefaultHTTPRequestWithTaggedStruct(http.MethodPost, "/metrics", req)
+	if err != nil {
+		return response, err
+	}
+
+	httpResponse, err := c.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// MonitoringPublisher buffers scale events of node groups as OCI Monitoring metrics and publishes them on Flush. All
+// methods of a nil MonitoringPublisher are no-ops, so callers do not need to check if publishing is enabled.
+type MonitoringPublisher struct {
+	client        MonitoringClient
+	namespace     string
+	compartmentID string
+
+	mu      sync.Mutex
+	pending []MetricDataDetails
+}
+
+// NewMonitoringPublisher creates a publisher of metrics in the specified namespace and compartment.
+func NewMonitoringPublisher(client MonitoringClient, namespace, compartmentID string) *MonitoringPublisher {
+	return &MonitoringPublisher{
+		client:        client,
+		namespace:     namespace,
+		compartmentID: compartmentID,
+	}
+}
+
+// RecordScaleUp records that the node group was scaled up by the specified number of instances.
+func (p *MonitoringPublisher) RecordScaleUp(nodeGroupID string, count int) {
+	p.record(ScaleUpCountMetric, nodeGroupID, count)
+}
+
+// RecordScaleDown records that the node group was scaled down by the specified number of instances.
+func (p *MonitoringPublisher) RecordScaleDown(nodeGroupID string, count int) {
+	p.record(ScaleDownCountMetric, nodeGroupID, count)
+}
+
+// RecordFailedLaunches records that the node group failed to launch the specified number of instances.
+func (p *MonitoringPublisher) RecordFailedLaunches(nodeGroupID string, count int) {
+	p.record(FailedLaunchCountMetric, nodeGroupID, count)
+}
+
+func (p *MonitoringPublisher) record(name, nodeGroupID string, count int) {
+	if p == nil || count <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, MetricDataDetails{
+		Namespace:     common.String(p.namespace),
+		CompartmentID: common.String(p.compartmentID),
+		Name:          common.String(name),
+		Dimensions:    map[string]string{NodeGroupDimension: nodeGroupID},
+		Datapoints: []Datapoint{{
+			Timestamp: &common.SDKTime{Time: time.Now()},
+			Value:     common.Float64(float64(count)),
+		}},
+	})
+}
+
+// Flush publishes the recorded metrics. Metrics that could not be published are dropped rather than retried, as
+// retrying would delay the autoscaler's loop and the datapoints would be stale by then.
+func (p *MonitoringPublisher) Flush() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	pending := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+
+	for len(pending) > 0 {
+		batch := pending
+		if len(batch) > monitoringMaxMetricStreams {
+			batch = batch[:monitoringMaxMetricStreams]
+		}
+		pending = pending[len(batch):]
+
+		resp, err := p.client.PostMetricData(context.Background(), PostMetricDataRequest{
+			PostMetricDataDetails: PostMetricDataDetails{MetricData: batch},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "unable to publish %d metric(s) to namespace %s (opc-request-id: %s)",
+				len(batch)+len(pending), p.namespace, OpcRequestID(err))
+		}
+		klog.V(5).InfoS("Published metrics", "namespace", p.namespace, "count", len(batch),
+			"opcRequestID", ResponseOpcRequestID(resp.OpcRequestID))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockMonitoringClient struct {
+	requests []PostMetricDataRequest
+	err      error
+}
+
+func (m *mockMonitoringClient) PostMetricData(_ context.Context, req PostMetricDataRequest) (PostMetricDataResponse, error) {
+	m.requests = append(m.requests, req)
+	return PostMetricDataResponse{}, m.err
+}
+
+func TestMonitoringPublisher(t *testing.T) {
+	client := &mockMonitoringClient{}
+	publisher := NewMonitoringPublisher(client, "cluster_autoscaler", "ocid1.compartment.oc1..aaaaaaaa1")
+
+	publisher.RecordScaleUp("ocid1.instancepool.oc1.phx.aaaaaaaa1", 2)
+	publisher.RecordScaleDown("ocid1.instancepool.oc1.phx.aaaaaaaa1", 1)
+	publisher.RecordFailedLaunches("ocid1.instancepool.oc1.phx.aaaaaaaa2", 3)
+	// Nothing happened, so nothing is published.
+	publisher.RecordScaleUp("ocid1.instancepool.oc1.phx.aaaaaaaa2", 0)
+
+	if err := publisher.Flush(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(client.requests) != 1 {
+		t.Fatalf("got %d requests ; wanted 1", len(client.requests))
+	}
+	metricData := client.requests[0].MetricData
+	expected := []struct {
+		name      string
+		nodeGroup string
+		value     float64
+	}{
+		{ScaleUpCountMetric, "ocid1.instancepool.oc1.phx.aaaaaaaa1", 2},
+		{ScaleDownCountMetric, "ocid1.instancepool.oc1.phx.aaaaaaaa1", 1},
+		{FailedLaunchCountMetric, "ocid1.instancepool.oc1.phx.aaaaaaaa2", 3},
+	}
+	if len(metricData) != len(expected) {
+		t.Fatalf("got %d metric streams ; wanted %d", len(metricData), len(expected))
+	}
+	for i, e := range expected {
+		if *metricData[i].Name != e.name || metricData[i].Dimensions[NodeGroupDimension] != e.nodeGroup ||
+			*metricData[i].Datapoints[0].Value != e.value {
+			t.Errorf("got %s{%v}=%v ; wanted %s{%s}=%v", *metricData[i].Name, metricData[i].Dimensions,
+				*metricData[i].Datapoints[0].Value, e.name, e.nodeGroup, e.value)
+		}
+		if *metricData[i].Namespace != "cluster_autoscaler" || *metricData[i].CompartmentID != "ocid1.compartment.oc1..aaaaaaaa1" {
+			t.Errorf("got namespace %s and compartment %s", *metricData[i].Namespace, *metricData[i].CompartmentID)
+		}
+	}
+
+	// Published metrics are not published again.
+	if err := publisher.Flush(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("got %d requests ; wanted 1", len(client.requests))
+	}
+}
+
+func TestMonitoringPublisherBatches(t *testing.T) {
+	client := &mockMonitoringClient{}
+	publisher := NewMonitoringPublisher(client, "cluster_autoscaler", "ocid1.compartment.oc1..aaaaaaaa1")
+	for i := 0; i < monitoringMaxMetricStreams+1; i++ {
+		publisher.RecordScaleUp("ocid1.instancepool.oc1.phx.aaaaaaaa1", 1)
+	}
+
+	if err := publisher.Flush(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("got %d requests ; wanted 2", len(client.requests))
+	}
+	if got := len(client.requests[0].MetricData); got != monitoringMaxMetricStreams {
+		t.Errorf("got %d metric streams in the first request ; wanted %d", got, monitoringMaxMetricStreams)
+	}
+	if got := len(client.requests[1].MetricData); got != 1 {
+		t.Errorf("got %d metric streams in the second request ; wanted 1", got)
+	}
+}
+
+func TestMonitoringPublisherErrors(t *testing.T) {
+	client := &mockMonitoringClient{err: errors.New("service unavailable")}
+	publisher := NewMonitoringPublisher(client, "cluster_autoscaler", "ocid1.compartment.oc1..aaaaaaaa1")
+	publisher.RecordScaleUp("ocid1.instancepool.oc1.phx.aaaaaaaa1", 1)
+
+	if err := publisher.Flush(); err == nil {
+		t.Fatalf("expected an error")
+	}
+	// Metrics that could not be published are dropped.
+	if err := publisher.Flush(); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("got %d requests ; wanted 1", len(client.requests))
+	}
+}
+
+func TestNilMonitoringPublisher(t *testing.T) {
+	var publisher *MonitoringPublisher
+	publisher.RecordScaleUp("ocid1.instancepool.oc1.phx.aaaaaaaa1", 1)
+	if err := publisher.Flush(); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// DefaultMaxInFlightMutationWait is how long a mutating operation waits for others to finish unless configured
+// otherwise.
+const DefaultMaxInFlightMutationWait = 5 * time.Minute
+
+// MutationLimiter limits how many mutating operations, i.e. resizing a node group or detaching and terminating its
+// instances, are in flight at the same time, both per node group and in total. Every such operation starts OCI work
+// requests, and tenancies limit how many work requests may run concurrently, so a large scale-down touching many node
+// groups at once would otherwise fail with errors that only get worse as the core autoscaler retries. A slot should
+// only be held while the API call starting the operation is made, not while waiting for its work requests. A single
+// MutationLimiter should be shared by all the node groups of a manager. All methods of a nil MutationLimiter are
+// no-ops.
+type MutationLimiter struct {
+	// total holds a token for every operation in flight, it is nil if the total is not limited.
+	total chan struct{}
+	// perNodeGroup is the maximum number of operations in flight per node group, it is not limited unless positive.
+	perNodeGroup int
+	// maxWait is how long an operation waits for a slot before it fails.
+	maxWait time.Duration
+
+	mu         sync.Mutex
+	nodeGroups map[string]chan struct{}
+}
+
+// NewMutationLimiter creates a limiter of the mutating operations in flight in total and per node group. Either is
+// not limited unless it is positive. Operations fail after waiting maxWait for a slot, DefaultMaxInFlightMutationWait
+// unless it is positive. Returns nil if neither is limited.
+func NewMutationLimiter(maxInFlight, maxInFlightPerNodeGroup int, maxWait time.Duration) *MutationLimiter {
+	if maxInFlight <= 0 && maxInFlightPerNodeGroup <= 0 {
+		return nil
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultMaxInFlightMutationWait
+	}
+	l := &MutationLimiter{
+		perNodeGroup: maxInFlightPerNodeGroup,
+		maxWait:      maxWait,
+		nodeGroups:   map[string]chan struct{}{},
+	}
+	if maxInFlight > 0 {
+		l.total = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// Acquire waits until another mutating operation of the node group may start, and returns the function that must be
+// called once it started. Returns an error if the context is done or the max wait elapses first.
+func (l *MutationLimiter) Acquire(ctx context.Context, nodeGroupID string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.maxWait)
+	defer cancel()
+
+	// The slot of the node group is taken first, so operations waiting for a busy node group do not hold slots of
+	// the total that operations of other node groups could use.
+	nodeGroup := l.nodeGroupSemaphore(nodeGroupID)
+	if err := acquireSemaphore(ctx, nodeGroup, nodeGroupID); err != nil {
+		return nil, err
+	}
+	if err := acquireSemaphore(ctx, l.total, nodeGroupID); err != nil {
+		releaseSemaphore(nodeGroup)
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			releaseSemaphore(l.total)
+			releaseSemaphore(nodeGroup)
+		})
+	}, nil
+}
+
+// nodeGroupSemaphore returns the semaphore of the node group, or nil if node groups are not limited.
+func (l *MutationLimiter) nodeGroupSemaphore(nodeGroupID string) chan struct{} {
+	if l.perNodeGroup <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	semaphore, ok := l.nodeGroups[nodeGroupID]
+	if !ok {
+		semaphore = make(chan struct{}, l.perNodeGroup)
+		l.nodeGroups[nodeGroupID] = semaphore
+	}
+	return semaphore
+}
+
+func acquireSemaphore(ctx context.Context, semaphore chan struct{}, nodeGroupID string) error {
+	if semaphore == nil {
+		return nil
+	}
+	select {
+	case semaphore <- struct{}{}:
+		return nil
+	default:
+		klog.V(4).Infof("waiting for in-flight mutations to finish before mutating node group %s", nodeGroupID)
+	}
+	select {
+	case semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "gave up waiting for in-flight mutations to finish before mutating node group %s", nodeGroupID)
+	}
+}
+
+func releaseSemaphore(semaphore chan struct{}) {
+	if semaphore != nil {
+		<-semaphore
+	}
+}
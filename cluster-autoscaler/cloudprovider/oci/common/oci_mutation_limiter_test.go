@@ -0,0 +1,140 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// acquired acquires a slot for the node group in the background, and sends the function releasing it once it did.
+func acquired(l *MutationLimiter, nodeGroupID string) <-chan func() {
+	done := make(chan func(), 1)
+	go func() {
+		if release, err := l.Acquire(context.Background(), nodeGroupID); err == nil {
+			done <- release
+		}
+	}()
+	return done
+}
+
+func TestMutationLimiter(t *testing.T) {
+	if NewMutationLimiter(0, 0, 0) != nil {
+		t.Errorf("got a limiter ; wanted nil if nothing is limited")
+	}
+	var nilLimiter *MutationLimiter
+	release, err := nilLimiter.Acquire(context.Background(), "ocid1.instancepool.oc1.phx.aaaaaaaa1")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	release()
+
+	testCases := map[string]struct {
+		maxInFlight             int
+		maxInFlightPerNodeGroup int
+		// held are the node groups of the mutations in flight.
+		held []string
+		// nodeGroup is the node group of the next mutation.
+		nodeGroup string
+		blocked   bool
+	}{
+		"below both limits": {
+			maxInFlight:             2,
+			maxInFlightPerNodeGroup: 1,
+			held:                    []string{"a"},
+			nodeGroup:               "b",
+		},
+		"at the limit of the node group": {
+			maxInFlight:             2,
+			maxInFlightPerNodeGroup: 1,
+			held:                    []string{"a"},
+			nodeGroup:               "a",
+			blocked:                 true,
+		},
+		"at the total limit": {
+			maxInFlight:             2,
+			maxInFlightPerNodeGroup: 1,
+			held:                    []string{"a", "b"},
+			nodeGroup:               "c",
+			blocked:                 true,
+		},
+		"node groups not limited": {
+			maxInFlight: 2,
+			held:        []string{"a"},
+			nodeGroup:   "a",
+		},
+		"total not limited": {
+			maxInFlightPerNodeGroup: 1,
+			held:                    []string{"a", "b"},
+			nodeGroup:               "c",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			l := NewMutationLimiter(tc.maxInFlight, tc.maxInFlightPerNodeGroup, 0)
+			var releases []func()
+			for _, nodeGroup := range tc.held {
+				release, err := l.Acquire(context.Background(), nodeGroup)
+				if err != nil {
+					t.Fatalf("unexpected error: %+v", err)
+				}
+				releases = append(releases, release)
+			}
+
+			done := acquired(l, tc.nodeGroup)
+			select {
+			case release := <-done:
+				if tc.blocked {
+					t.Errorf("got mutation of %s started ; wanted it blocked", tc.nodeGroup)
+				}
+				release()
+				return
+			case <-time.After(50 * time.Millisecond):
+				if !tc.blocked {
+					t.Fatalf("got mutation of %s blocked ; wanted it started", tc.nodeGroup)
+				}
+			}
+
+			// Releasing a slot twice does not free a slot of another mutation.
+			releases[0]()
+			releases[0]()
+			select {
+			case release := <-done:
+				release()
+			case <-time.After(time.Second):
+				t.Errorf("got mutation of %s blocked after a release ; wanted it started", tc.nodeGroup)
+			}
+		})
+	}
+}
+
+func TestMutationLimiterWait(t *testing.T) {
+	l := NewMutationLimiter(2, 1, 50*time.Millisecond)
+	release, err := l.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if _, err := l.Acquire(context.Background(), "b"); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// The wait for the busy node group gives up after the max wait.
+	if _, err := l.Acquire(context.Background(), "a"); err == nil {
+		t.Errorf("got a slot of a busy node group ; wanted an error after the max wait")
+	}
+	// The wait gives up when the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Acquire(ctx, "c"); err == nil {
+		t.Errorf("got a slot beyond the total limit ; wanted an error once the context is done")
+	}
+	// A failed wait for the total does not keep the slot of its node group.
+	release()
+	if _, err := l.Acquire(context.Background(), "c"); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+}
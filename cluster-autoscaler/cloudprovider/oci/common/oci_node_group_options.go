@@ -0,0 +1,74 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+// MaxNodeProvisionTimeTag is the freeform tag key that overrides how long the autoscaler waits for the nodes of an
+// instance pool or node pool to register before it considers them failed, e.g. "45m".
+const MaxNodeProvisionTimeTag = "cluster-autoscaler/max-node-provision-time"
+
+// ParseMaxNodeProvisionTimeByShape parses the max node provision time of node groups by shape,
+// e.g. "BM.GPU4.8=45m,BM.Standard.E4.128=30m".
+func ParseMaxNodeProvisionTimeByShape(value string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected <shape>=<duration> but got %q", pair)
+		}
+		provisionTime, err := parseMaxNodeProvisionTime(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSpace(kv[0])] = provisionTime
+	}
+	return result, nil
+}
+
+// MaxNodeProvisionTime returns the max node provision time of a node group with the given freeform tags and shape.
+// The freeform tag takes precedence over the shape default. Returns 0 if neither is set.
+func MaxNodeProvisionTime(freeformTags map[string]string, shapeName string, byShape map[string]time.Duration) (time.Duration, error) {
+	if value, ok := freeformTags[MaxNodeProvisionTimeTag]; ok && value != "" {
+		provisionTime, err := parseMaxNodeProvisionTime(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s freeform tag: %v", MaxNodeProvisionTimeTag, err)
+		}
+		return provisionTime, nil
+	}
+	return byShape[shapeName], nil
+}
+
+// NodeGroupOptions returns the defaults with MaxNodeProvisionTime overridden, or nil if there is no override so that
+// the defaults are used as they are.
+func NodeGroupOptions(defaults config.NodeGroupAutoscalingOptions, maxNodeProvisionTime time.Duration) *config.NodeGroupAutoscalingOptions {
+	if maxNodeProvisionTime <= 0 {
+		return nil
+	}
+	options := defaults
+	options.MaxNodeProvisionTime = maxNodeProvisionTime
+	return &options
+}
+
+func parseMaxNodeProvisionTime(value string) (time.Duration, error) {
+	provisionTime, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if provisionTime <= 0 {
+		return 0, fmt.Errorf("max node provision time %q must be positive", strings.TrimSpace(value))
+	}
+	return provisionTime, nil
+}
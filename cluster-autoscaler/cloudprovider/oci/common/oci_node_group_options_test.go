@@ -0,0 +1,137 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+func TestParseMaxNodeProvisionTimeByShape(t *testing.T) {
+	testCases := map[string]struct {
+		value       string
+		expected    map[string]time.Duration
+		expectedErr bool
+	}{
+		"empty": {
+			value:    "",
+			expected: map[string]time.Duration{},
+		},
+		"several shapes": {
+			value: "BM.GPU4.8=45m, BM.Standard.E4.128=30m",
+			expected: map[string]time.Duration{
+				"BM.GPU4.8":          45 * time.Minute,
+				"BM.Standard.E4.128": 30 * time.Minute,
+			},
+		},
+		"missing duration": {
+			value:       "BM.GPU4.8",
+			expectedErr: true,
+		},
+		"invalid duration": {
+			value:       "BM.GPU4.8=45",
+			expectedErr: true,
+		},
+		"non-positive duration": {
+			value:       "BM.GPU4.8=0s",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseMaxNodeProvisionTimeByShape(tc.value)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("got %v ; wanted %v", got, tc.expected)
+			}
+			for shape, provisionTime := range tc.expected {
+				if got[shape] != provisionTime {
+					t.Errorf("got %v for shape %s ; wanted %v", got[shape], shape, provisionTime)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxNodeProvisionTime(t *testing.T) {
+	byShape := map[string]time.Duration{"BM.GPU4.8": 45 * time.Minute}
+
+	testCases := map[string]struct {
+		freeformTags map[string]string
+		shape        string
+		expected     time.Duration
+		expectedErr  bool
+	}{
+		"no override": {
+			shape:    "VM.Standard2.4",
+			expected: 0,
+		},
+		"shape": {
+			shape:    "BM.GPU4.8",
+			expected: 45 * time.Minute,
+		},
+		"tag takes precedence over shape": {
+			freeformTags: map[string]string{MaxNodeProvisionTimeTag: "1h"},
+			shape:        "BM.GPU4.8",
+			expected:     time.Hour,
+		},
+		"invalid tag": {
+			freeformTags: map[string]string{MaxNodeProvisionTimeTag: "soon"},
+			shape:        "BM.GPU4.8",
+			expectedErr:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := MaxNodeProvisionTime(tc.freeformTags, tc.shape, byShape)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNodeGroupOptions(t *testing.T) {
+	defaults := config.NodeGroupAutoscalingOptions{
+		ScaleDownUnneededTime: 10 * time.Minute,
+		MaxNodeProvisionTime:  15 * time.Minute,
+	}
+
+	if got := NodeGroupOptions(defaults, 0); got != nil {
+		t.Errorf("got %+v ; wanted nil", got)
+	}
+
+	got := NodeGroupOptions(defaults, 45*time.Minute)
+	if got == nil {
+		t.Fatalf("got nil options")
+	}
+	if got.MaxNodeProvisionTime != 45*time.Minute {
+		t.Errorf("got max node provision time %v ; wanted %v", got.MaxNodeProvisionTime, 45*time.Minute)
+	}
+	if got.ScaleDownUnneededTime != defaults.ScaleDownUnneededTime {
+		t.Errorf("got scale down unneeded time %v ; wanted %v", got.ScaleDownUnneededTime, defaults.ScaleDownUnneededTime)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// InvalidNodeGroupReason is the reason of the events reporting a misconfigured node group.
+	InvalidNodeGroupReason = "InvalidNodeGroup"
+	// ValidNodeGroupReason is the reason of the events reporting that a misconfigured node group was fixed.
+	ValidNodeGroupReason = "ValidNodeGroup"
+)
+
+// IsMisconfiguration returns true if the error that failed the validation of a node group means it is misconfigured,
+// rather than that the OCI API could not be reached or failed transiently.
+func IsMisconfiguration(err error) bool {
+	if IsNotFound(err) {
+		return true
+	}
+	if _, ok := common.IsServiceError(err); ok {
+		return false
+	}
+	var netErr net.Error
+	return !errors.As(err, &netErr)
+}
+
+// IsNotFound returns true if the error is an OCI service error reporting that a resource does not exist or is not
+// accessible.
+func IsNotFound(err error) bool {
+	serviceErr, ok := common.IsServiceError(err)
+	return ok && serviceErr.GetHTTPStatusCode() == http.StatusNotFound
+}
+
+// NodeGroupValidator reports misconfigured node groups, e.g. instance pools that do not exist or whose instance
+// configuration cannot be resolved, as warning events on the status ConfigMap of the autoscaler. A node group is only
+// reported when its problem changes, not on every refresh. A nil NodeGroupValidator considers every node group valid.
+type NodeGroupValidator struct {
+	kubeClient kubernetes.Interface
+
+	mu sync.Mutex
+	// problems holds the problem last reported for each misconfigured node group.
+	problems map[string]string
+	// valid holds the node groups that were validated and found to be configured correctly.
+	valid map[string]bool
+}
+
+// NewNodeGroupValidator creates a validator that records events with the specified client. Problems are only logged
+// if the client is nil.
+func NewNodeGroupValidator(kubeClient kubernetes.Interface) *NodeGroupValidator {
+	return &NodeGroupValidator{
+		kubeClient: kubeClient,
+		problems:   map[string]string{},
+		valid:      map[string]bool{},
+	}
+}
+
+// IsValid returns true if the node group was validated and found to be configured correctly, so it does not need to
+// be validated again.
+func (v *NodeGroupValidator) IsValid(nodeGroupID string) bool {
+	if v == nil {
+		return true
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.valid[nodeGroupID]
+}
+
+// Report records the outcome of the validation of a node group, i.e. the problem with its configuration or nil if it
+// is configured correctly.
+func (v *NodeGroupValidator) Report(nodeGroupID string, problem error) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if problem == nil {
+		v.valid[nodeGroupID] = true
+		if _, ok := v.problems[nodeGroupID]; ok {
+			delete(v.problems, nodeGroupID)
+			klog.Infof("Node group %s is configured correctly now", nodeGroupID)
+			RecordNodeGroupEvent(v.kubeClient, apiv1.EventTypeNormal, ValidNodeGroupReason, fmt.Sprintf("Node group %s is configured correctly", nodeGroupID))
+		}
+		return
+	}
+
+	delete(v.valid, nodeGroupID)
+	message := fmt.Sprintf("Node group %s is misconfigured: %v", nodeGroupID, problem)
+	if v.problems[nodeGroupID] == message {
+		return
+	}
+	v.problems[nodeGroupID] = message
+	klog.Warning(message)
+	RecordNodeGroupEvent(v.kubeClient, apiv1.EventTypeWarning, InvalidNodeGroupReason, message)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeGroupValidator(t *testing.T) {
+	const nodeGroupID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	kubeClient := fake.NewSimpleClientset()
+	validator := NewNodeGroupValidator(kubeClient)
+	events := func() []apiv1.Event {
+		list, err := kubeClient.CoreV1().Events(statusConfigMapNamespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		return list.Items
+	}
+
+	if validator.IsValid(nodeGroupID) {
+		t.Errorf("got valid ; wanted not validated yet")
+	}
+
+	// A problem is reported once, not on every refresh.
+	validator.Report(nodeGroupID, errors.New("subnet ocid1.subnet.oc1.phx.aaaaaaaa1 does not exist"))
+	validator.Report(nodeGroupID, errors.New("subnet ocid1.subnet.oc1.phx.aaaaaaaa1 does not exist"))
+	if validator.IsValid(nodeGroupID) {
+		t.Errorf("got valid ; wanted misconfigured")
+	}
+	if got := events(); len(got) != 1 || got[0].Type != apiv1.EventTypeWarning || got[0].Reason != InvalidNodeGroupReason ||
+		got[0].InvolvedObject.Name != statusConfigMapName {
+		t.Fatalf("got events %+v ; wanted one %s warning on the status ConfigMap", got, InvalidNodeGroupReason)
+	}
+
+	// A different problem is reported again.
+	validator.Report(nodeGroupID, errors.New("instance pool does not exist"))
+	if got := events(); len(got) != 2 {
+		t.Errorf("got %d events ; wanted 2", len(got))
+	}
+
+	// The fix is reported too.
+	validator.Report(nodeGroupID, nil)
+	if !validator.IsValid(nodeGroupID) {
+		t.Errorf("got misconfigured ; wanted valid")
+	}
+	got := events()
+	if len(got) != 3 {
+		t.Fatalf("got %d events ; wanted 3", len(got))
+	}
+	var normal int
+	for _, event := range got {
+		if event.Type == apiv1.EventTypeNormal && event.Reason == ValidNodeGroupReason {
+			normal++
+		}
+	}
+	if normal != 1 {
+		t.Errorf("got %d %s events ; wanted 1", normal, ValidNodeGroupReason)
+	}
+
+	// Node groups that were always valid are not reported.
+	validator.Report("ocid1.instancepool.oc1.phx.aaaaaaaa2", nil)
+	if got := events(); len(got) != 3 {
+		t.Errorf("got %d events ; wanted 3", len(got))
+	}
+}
+
+func TestNodeGroupValidatorNoop(t *testing.T) {
+	var validator *NodeGroupValidator
+	validator.Report("ocid1.instancepool.oc1.phx.aaaaaaaa1", errors.New("instance pool does not exist"))
+	if !validator.IsValid("ocid1.instancepool.oc1.phx.aaaaaaaa1") {
+		t.Errorf("got misconfigured ; wanted every node group to be valid")
+	}
+
+	// Problems are only logged without a client.
+	validator = NewNodeGroupValidator(nil)
+	validator.Report("ocid1.instancepool.oc1.phx.aaaaaaaa1", errors.New("instance pool does not exist"))
+	if validator.IsValid("ocid1.instancepool.oc1.phx.aaaaaaaa1") {
+		t.Errorf("got valid ; wanted misconfigured")
+	}
+}
+
+func TestIsMisconfiguration(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"not found": {
+			err:      mockServiceError{statusCode: 404, code: ErrorCodeNotAuthorizedOrNotFound},
+			expected: true,
+		},
+		"throttled": {
+			err:      mockServiceError{statusCode: 429, code: ErrorCodeTooManyRequests},
+			expected: false,
+		},
+		"network error": {
+			err:      &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			expected: false,
+		},
+		"missing shape": {
+			err:      errors.New(`shape "VM.Standard.E9.Flex" does not exist`),
+			expected: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsMisconfiguration(tc.err); got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
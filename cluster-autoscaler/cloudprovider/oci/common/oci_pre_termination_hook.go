@@ -0,0 +1,173 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common/auth"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// functionsInvokeBasePath is the API version of the invoke endpoints of the OCI Functions service.
+	functionsInvokeBasePath = "20181201"
+
+	// PreTerminationFailurePolicyContinue terminates the instances even if the pre-termination hook fails.
+	PreTerminationFailurePolicyContinue = "continue"
+	// PreTerminationFailurePolicyAbort fails the scale-down without terminating the instances if the pre-termination
+	// hook fails.
+	PreTerminationFailurePolicyAbort = "abort"
+	// DefaultPreTerminationTimeout is how long the pre-termination hook may run unless configured otherwise.
+	DefaultPreTerminationTimeout = 30 * time.Second
+)
+
+// PreTerminationInstance is an instance that is about to be terminated.
+type PreTerminationInstance struct {
+	InstanceID string `json:"instanceId"`
+	NodeName   string `json:"nodeName,omitempty"`
+}
+
+// PreTerminationPayload is the payload the pre-termination function is invoked with.
+type PreTerminationPayload struct {
+	NodeGroupID string                   `json:"nodeGroupId"`
+	Instances   []PreTerminationInstance `json:"instances"`
+}
+
+// InvokeFunctionRequest is the request of the Functions InvokeFunction operation.
+type InvokeFunctionRequest struct {
+	FunctionID            *string `mandatory:"true" contributesTo:"path" name:"functionId"`
+	FnInvokeType          *string `mandatory:"false" contributesTo:"header" name:"fn-invoke-type"`
+	PreTerminationPayload `contributesTo:"body"`
+}
+
+// InvokeFunctionResponse is the response of the Functions InvokeFunction operation.
+type InvokeFunctionResponse struct {
+	RawResponse  *http.Response
+	OpcRequestID *string `presentIn:"header" name:"opc-request-id"`
+}
+
+// FunctionsInvokeClient is an interface around the OCI Functions invoke calls we require.
+type FunctionsInvokeClient interface {
+	InvokeFunction(context.Context, InvokeFunctionRequest) (InvokeFunctionResponse, error)
+}
+
+// FunctionsInvokeClientImpl is the implementation of a client of the invoke endpoint of an OCI Function.
+type FunctionsInvokeClientImpl struct {
+	common.BaseClient
+}
+
+// NewFunctionsInvokeClient creates a client of the specified invoke endpoint of the OCI Functions service using the
+// given configuration provider and rate limiter.
+func NewFunctionsInvokeClient(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *RateLimiter, circuitBreaker *CircuitBreaker, endpoint string) (*FunctionsInvokeClientImpl, error) {
+	provider, err := auth.GetGenericConfigurationProvider(configProvider)
+	if err != nil {
+		return nil, err
+	}
+	baseClient, err := common.NewClientWithConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	baseClient.BasePath = functionsInvokeBasePath
+	baseClient.Host = endpoint
+	baseClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&baseClient)
+	rateLimiter.Apply(&baseClient)
+	LogRequestIDs(&baseClient)
+	return &FunctionsInvokeClientImpl{BaseClient: baseClient}, nil
+}
+
+// InvokeFunction invokes the specified function synchronously.
+func (c *FunctionsInvokeClientImpl) InvokeFunction(ctx context.Context, req InvokeFunctionRequest) (InvokeFunctionResponse, error) {
+	var response InvokeFunctionResponse
+	httpRequest, err := common.MakeDefaultHTTPRequestWithTaggedStruct(http.MethodPost, "/functions/{functionId}/actions/invoke", req)
+	if err != nil {
+		return response, err
+	}
+
+	httpResponse, err := c.Call(ctx, &httpRequest)
+	defer common.CloseBodyIfValid(httpResponse)
+	response.RawResponse = httpResponse
+	if err != nil {
+		return response, err
+	}
+
+	err = common.UnmarshalResponse(httpResponse, &response)
+	return response, err
+}
+
+// PreTerminationHook invokes an OCI Function with the instances the autoscaler is about to terminate, after their
+// nodes were drained, e.g. to deregister them from external systems. Run on a nil PreTerminationHook is a no-op.
+type PreTerminationHook struct {
+	client        FunctionsInvokeClient
+	functionID    string
+	timeout       time.Duration
+	failurePolicy string
+}
+
+// NewPreTerminationHook creates a hook that invokes the specified function.
+func NewPreTerminationHook(client FunctionsInvokeClient, functionID string, timeout time.Duration, failurePolicy string) *PreTerminationHook {
+	if timeout <= 0 {
+		timeout = DefaultPreTerminationTimeout
+	}
+	if failurePolicy == "" {
+		failurePolicy = PreTerminationFailurePolicyContinue
+	}
+	return &PreTerminationHook{
+		client:        client,
+		functionID:    functionID,
+		timeout:       timeout,
+		failurePolicy: failurePolicy,
+	}
+}
+
+// Run invokes the function with the instances of the node group. It only returns an error if the function failed
+// and the failure policy is PreTerminationFailurePolicyAbort.
+func (h *PreTerminationHook) Run(nodeGroupID string, instances []OciRef) error {
+	if h == nil || len(instances) == 0 {
+		return nil
+	}
+
+	payload := PreTerminationPayload{NodeGroupID: nodeGroupID}
+	for _, instance := range instances {
+		payload.Instances = append(payload.Instances, PreTerminationInstance{InstanceID: instance.InstanceID, NodeName: instance.Name})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	resp, err := h.client.InvokeFunction(ctx, InvokeFunctionRequest{
+		FunctionID:            common.String(h.functionID),
+		FnInvokeType:          common.String("sync"),
+		PreTerminationPayload: payload,
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "pre-termination function %s failed for %d instance(s) of node group %s (opc-request-id: %s)",
+			h.functionID, len(instances), nodeGroupID, OpcRequestID(err))
+		if h.failurePolicy == PreTerminationFailurePolicyAbort {
+			return err
+		}
+		klog.Warningf("terminating instances anyway: %v", err)
+		return nil
+	}
+	klog.V(4).InfoS("Invoked pre-termination function", "nodeGroup", nodeGroupID, "instances", len(instances),
+		"opcRequestID", ResponseOpcRequestID(resp.OpcRequestID))
+	return nil
+}
+
+// validatePreTerminationFailurePolicy returns an error if the failure policy is not known.
+func validatePreTerminationFailurePolicy(failurePolicy string) error {
+	switch failurePolicy {
+	case "", PreTerminationFailurePolicyContinue, PreTerminationFailurePolicyAbort:
+		return nil
+	default:
+		return fmt.Errorf("pre-termination-failure-policy %q must be %s or %s", failurePolicy,
+			PreTerminationFailurePolicyContinue, PreTerminationFailurePolicyAbort)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockFunctionsInvokeClient struct {
+	requests []InvokeFunctionRequest
+	deadline time.Time
+	err      error
+}
+
+func (m *mockFunctionsInvokeClient) InvokeFunction(ctx context.Context, req InvokeFunctionRequest) (InvokeFunctionResponse, error) {
+	m.requests = append(m.requests, req)
+	m.deadline, _ = ctx.Deadline()
+	return InvokeFunctionResponse{}, m.err
+}
+
+func TestPreTerminationHook(t *testing.T) {
+	instances := []OciRef{
+		{InstanceID: "ocid1.instance.oc1.phx.aaaaaaaa1", Name: "10.0.0.1"},
+		{InstanceID: "ocid1.instance.oc1.phx.aaaaaaaa2", Name: "10.0.0.2"},
+	}
+
+	testCases := map[string]struct {
+		failurePolicy string
+		err           error
+		expectedErr   bool
+	}{
+		"succeeded": {
+			failurePolicy: PreTerminationFailurePolicyAbort,
+		},
+		"failed and continue": {
+			failurePolicy: PreTerminationFailurePolicyContinue,
+			err:           errors.New("function timed out"),
+		},
+		"failed and continue by default": {
+			err: errors.New("function timed out"),
+		},
+		"failed and abort": {
+			failurePolicy: PreTerminationFailurePolicyAbort,
+			err:           errors.New("function timed out"),
+			expectedErr:   true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &mockFunctionsInvokeClient{err: tc.err}
+			hook := NewPreTerminationHook(client, "ocid1.fnfunc.oc1.phx.aaaaaaaa1", time.Minute, tc.failurePolicy)
+
+			err := hook.Run("ocid1.instancepool.oc1.phx.aaaaaaaa1", instances)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("got error %v ; wanted error %v", err, tc.expectedErr)
+			}
+			if len(client.requests) != 1 {
+				t.Fatalf("got %d requests ; wanted 1", len(client.requests))
+			}
+			req := client.requests[0]
+			if *req.FunctionID != "ocid1.fnfunc.oc1.phx.aaaaaaaa1" || req.NodeGroupID != "ocid1.instancepool.oc1.phx.aaaaaaaa1" {
+				t.Errorf("got function %s and node group %s", *req.FunctionID, req.NodeGroupID)
+			}
+			if len(req.Instances) != 2 || req.Instances[1].InstanceID != "ocid1.instance.oc1.phx.aaaaaaaa2" ||
+				req.Instances[1].NodeName != "10.0.0.2" {
+				t.Errorf("got instances %+v ; wanted %+v", req.Instances, instances)
+			}
+			if remaining := time.Until(client.deadline); remaining <= 0 || remaining > time.Minute {
+				t.Errorf("got deadline in %v ; wanted within %v", remaining, time.Minute)
+			}
+		})
+	}
+}
+
+func TestPreTerminationHookNoop(t *testing.T) {
+	var hook *PreTerminationHook
+	if err := hook.Run("ocid1.instancepool.oc1.phx.aaaaaaaa1", []OciRef{{InstanceID: "ocid1.instance.oc1.phx.aaaaaaaa1"}}); err != nil {
+		t.Errorf("got %v ; wanted nil", err)
+	}
+
+	client := &mockFunctionsInvokeClient{}
+	hook = NewPreTerminationHook(client, "ocid1.fnfunc.oc1.phx.aaaaaaaa1", 0, "")
+	if err := hook.Run("ocid1.instancepool.oc1.phx.aaaaaaaa1", nil); err != nil {
+		t.Errorf("got %v ; wanted nil", err)
+	}
+	if len(client.requests) != 0 {
+		t.Errorf("got %d requests ; wanted 0", len(client.requests))
+	}
+}
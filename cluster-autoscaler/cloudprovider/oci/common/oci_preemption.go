@@ -0,0 +1,189 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// TerminatingInstanceTaintKey taints the nodes of instances that OCI is terminating without the autoscaler asking
+	// for it, e.g. preempted instances, so no new pods are scheduled on them while they are drained.
+	TerminatingInstanceTaintKey = "oci.oraclecloud.com/instance-terminating"
+	// mirrorPodAnnotation is set on the static pods of a node, which cannot be evicted.
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// DrainTerminatingNodes cordons, taints and drains the nodes of the specified instances, so their pods are rescheduled
+// before the instances disappear instead of after. Nodes that are already tainted are drained again, so pods whose
+// eviction failed, e.g. because of a PodDisruptionBudget, are retried. DaemonSet, static, completed and terminating
+// pods are not evicted.
+func DrainTerminatingNodes(kubeClient kubernetes.Interface, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+
+	terminating := make(map[string]bool, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		terminating[instanceID] = true
+	}
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !terminating[strings.TrimPrefix(node.Spec.ProviderID, "oci://")] {
+			continue
+		}
+		if err := drainTerminatingNode(kubeClient, node); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// drainTerminatingNode cordons and taints the node unless it is already tainted, and evicts its remaining pods.
+func drainTerminatingNode(kubeClient kubernetes.Interface, node *apiv1.Node) error {
+	if !hasTerminatingInstanceTaint(node) {
+		klog.Infof("draining node %s since its instance %s is being terminated", node.Name, node.Spec.ProviderID)
+		if err := cordonTerminatingNode(kubeClient, node); err != nil {
+			return err
+		}
+	}
+	return evictPods(kubeClient, node.Name)
+}
+
+// TerminationNoticeWatcher drains nodes as soon as they are tainted with a termination notice taint, e.g. by an agent
+// on the node that polls the instance metadata for the preemption notice of its instance, instead of waiting for the
+// instance to be seen terminating in the next refresh of the instance pools.
+type TerminationNoticeWatcher struct {
+	kubeClient kubernetes.Interface
+	taintKey   string
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewTerminationNoticeWatcher watches the nodes and drains the ones tainted with the specified taint key until Stop is
+// called. The nodes are drained again whenever they are updated, e.g. by their heartbeats, until they are gone, so
+// failed evictions are retried.
+func NewTerminationNoticeWatcher(kubeClient kubernetes.Interface, taintKey string) *TerminationNoticeWatcher {
+	w := &TerminationNoticeWatcher{
+		kubeClient: kubeClient,
+		taintKey:   taintKey,
+		stopCh:     make(chan struct{}),
+	}
+	factory := informers.NewSharedInformerFactory(kubeClient, 0)
+	factory.Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.onNode,
+		UpdateFunc: func(_, obj interface{}) { w.onNode(obj) },
+	})
+	factory.Start(w.stopCh)
+	return w
+}
+
+func (w *TerminationNoticeWatcher) onNode(obj interface{}) {
+	node, ok := obj.(*apiv1.Node)
+	if !ok || !hasTaint(node, w.taintKey) {
+		return
+	}
+	if err := drainTerminatingNode(w.kubeClient, node); err != nil {
+		klog.Errorf("unable to drain node %s with a termination notice: %v", node.Name, err)
+	}
+}
+
+// Stop stops watching the nodes. Stop on a nil TerminationNoticeWatcher is a no-op.
+func (w *TerminationNoticeWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stopCh) })
+}
+
+func hasTerminatingInstanceTaint(node *apiv1.Node) bool {
+	return hasTaint(node, TerminatingInstanceTaintKey)
+}
+
+func hasTaint(node *apiv1.Node, taintKey string) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == taintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// cordonTerminatingNode marks the node unschedulable and taints it with TerminatingInstanceTaintKey.
+func cordonTerminatingNode(kubeClient kubernetes.Interface, node *apiv1.Node) error {
+	node = node.DeepCopy()
+	node.Spec.Unschedulable = true
+	node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
+		Key:    TerminatingInstanceTaintKey,
+		Effect: apiv1.TaintEffectNoSchedule,
+	})
+	_, err := kubeClient.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Errorf("failed to cordon node %s %+v", node.Name, err)
+	}
+	return err
+}
+
+// evictPods evicts the pods of the node that are recreated elsewhere when evicted.
+func evictPods(kubeClient kubernetes.Interface, nodeName string) error {
+	pods, err := kubeClient.CoreV1().Pods(apiv1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, pod := range pods.Items {
+		if !isEvictable(&pod) {
+			continue
+		}
+		err := kubeClient.PolicyV1().Evictions(pod.Namespace).Evict(context.Background(), &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		})
+		if err != nil {
+			klog.Errorf("failed to evict pod %s/%s from node %s %+v", pod.Namespace, pod.Name, nodeName, err)
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func isEvictable(pod *apiv1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+		return false
+	}
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
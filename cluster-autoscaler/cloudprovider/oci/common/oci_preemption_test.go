@@ -0,0 +1,115 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+func TestDrainTerminatingNodes(t *testing.T) {
+	node := func(name, providerID string) *apiv1.Node {
+		return &apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiv1.NodeSpec{ProviderID: providerID},
+		}
+	}
+	pod := func(name, nodeName string, ownerKind string) *apiv1.Pod {
+		p := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       apiv1.PodSpec{NodeName: nodeName},
+			Status:     apiv1.PodStatus{Phase: apiv1.PodRunning},
+		}
+		if ownerKind != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner"}}
+		}
+		return p
+	}
+
+	kubeClient := fake.NewSimpleClientset(
+		node("preempted", "ocid1.instance.oc1.phx.aaa1"),
+		node("running", "ocid1.instance.oc1.phx.aaa2"),
+		pod("app", "preempted", "ReplicaSet"),
+		pod("agent", "preempted", "DaemonSet"),
+	)
+
+	if err := DrainTerminatingNodes(kubeClient, []string{"ocid1.instance.oc1.phx.aaa1"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	preempted, _ := kubeClient.CoreV1().Nodes().Get(context.Background(), "preempted", metav1.GetOptions{})
+	if !preempted.Spec.Unschedulable || !hasTerminatingInstanceTaint(preempted) {
+		t.Errorf("got node spec %+v ; wanted a cordoned and tainted node", preempted.Spec)
+	}
+	running, _ := kubeClient.CoreV1().Nodes().Get(context.Background(), "running", metav1.GetOptions{})
+	if running.Spec.Unschedulable || hasTerminatingInstanceTaint(running) {
+		t.Errorf("got node spec %+v ; wanted an untouched node", running.Spec)
+	}
+
+	var evicted []string
+	for _, action := range kubeClient.Actions() {
+		if create, ok := action.(core.CreateAction); ok && action.GetSubresource() == "eviction" {
+			evicted = append(evicted, create.GetObject().(metav1.Object).GetName())
+		}
+	}
+	if len(evicted) != 1 || evicted[0] != "app" {
+		t.Errorf("got evicted pods %v ; wanted [app]", evicted)
+	}
+
+	// Nodes that are already tainted are not updated again, but the evictions of their remaining pods are retried.
+	kubeClient.ClearActions()
+	if err := DrainTerminatingNodes(kubeClient, []string{"ocid1.instance.oc1.phx.aaa1"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	evicted = nil
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("got unexpected action %v on an already tainted node", action)
+		}
+		if create, ok := action.(core.CreateAction); ok && action.GetSubresource() == "eviction" {
+			evicted = append(evicted, create.GetObject().(metav1.Object).GetName())
+		}
+	}
+	if len(evicted) != 1 || evicted[0] != "app" {
+		t.Errorf("got evicted pods %v ; wanted [app] to be evicted again", evicted)
+	}
+}
+
+func TestTerminationNoticeWatcher(t *testing.T) {
+	noticed := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "noticed"},
+		Spec: apiv1.NodeSpec{
+			ProviderID: "ocid1.instance.oc1.phx.aaa1",
+			Taints:     []apiv1.Taint{{Key: "example.com/preemption-notice", Effect: apiv1.TaintEffectNoSchedule}},
+		},
+	}
+	running := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "running"},
+		Spec:       apiv1.NodeSpec{ProviderID: "ocid1.instance.oc1.phx.aaa2"},
+	}
+	kubeClient := fake.NewSimpleClientset(noticed, running)
+
+	w := NewTerminationNoticeWatcher(kubeClient, "example.com/preemption-notice")
+	defer w.Stop()
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), "noticed", metav1.GetOptions{})
+		return err == nil && node.Spec.Unschedulable && hasTerminatingInstanceTaint(node), nil
+	})
+	if err != nil {
+		t.Fatalf("node with a termination notice was not cordoned and tainted: %v", err)
+	}
+	node, _ := kubeClient.CoreV1().Nodes().Get(context.Background(), "running", metav1.GetOptions{})
+	if node.Spec.Unschedulable || hasTerminatingInstanceTaint(node) {
+		t.Errorf("got node spec %+v ; wanted an untouched node", node.Spec)
+	}
+}
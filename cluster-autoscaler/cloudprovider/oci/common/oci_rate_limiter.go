@@ -0,0 +1,69 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"math"
+	"net/http"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RateLimiter holds separate token buckets for read (Get/List) and mutating (Create/Update/Detach/Delete/Terminate)
+// OCI API calls. A single RateLimiter should be shared by all the clients of a manager so the limits apply to the
+// total rate of calls.
+type RateLimiter struct {
+	read   flowcontrol.RateLimiter
+	mutate flowcontrol.RateLimiter
+}
+
+// NewRateLimiter creates a RateLimiter with the specified QPS and burst. Rate limiting of the corresponding
+// operations is disabled unless the QPS is positive, and the burst defaults to the QPS when it is not positive.
+func NewRateLimiter(readQPS float32, readBurst int, mutateQPS float32, mutateBurst int) *RateLimiter {
+	return &RateLimiter{
+		read:   newTokenBucketRateLimiter(readQPS, readBurst),
+		mutate: newTokenBucketRateLimiter(mutateQPS, mutateBurst),
+	}
+}
+
+// Apply rate limits all requests sent by the specified client.
+func (r *RateLimiter) Apply(client *common.BaseClient) {
+	client.HTTPClient = &rateLimitedDispatcher{dispatcher: client.HTTPClient, rateLimiter: r}
+}
+
+// limiterFor returns the token bucket the specified HTTP request draws from.
+func (r *RateLimiter) limiterFor(request *http.Request) flowcontrol.RateLimiter {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead:
+		return r.read
+	default:
+		return r.mutate
+	}
+}
+
+func newTokenBucketRateLimiter(qps float32, burst int) flowcontrol.RateLimiter {
+	if qps <= 0 {
+		return flowcontrol.NewFakeAlwaysRateLimiter()
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(float64(qps)))
+	}
+	return flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+}
+
+// rateLimitedDispatcher waits for a token of the request's rate limiter before dispatching it.
+type rateLimitedDispatcher struct {
+	dispatcher  common.HTTPRequestDispatcher
+	rateLimiter *RateLimiter
+}
+
+// Do implements common.HTTPRequestDispatcher.
+func (d *rateLimitedDispatcher) Do(request *http.Request) (*http.Response, error) {
+	if err := d.rateLimiter.limiterFor(request).Wait(request.Context()); err != nil {
+		return nil, err
+	}
+	return d.dispatcher.Do(request)
+}
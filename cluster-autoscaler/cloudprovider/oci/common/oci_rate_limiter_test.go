@@ -0,0 +1,86 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type countingDispatcher struct {
+	count int
+}
+
+func (d *countingDispatcher) Do(_ *http.Request) (*http.Response, error) {
+	d.count++
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestRateLimitedDispatcher(t *testing.T) {
+	testCases := map[string]struct {
+		method        string
+		expectedCount int
+	}{
+		"reads are limited by the read burst": {
+			method:        http.MethodGet,
+			expectedCount: 3,
+		},
+		"mutations are limited by the mutate burst": {
+			method:        http.MethodPut,
+			expectedCount: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			next := &countingDispatcher{}
+			dispatcher := &rateLimitedDispatcher{dispatcher: next, rateLimiter: NewRateLimiter(0.001, 3, 0.001, 1)}
+
+			// Requests beyond the burst wait for a token until their context is done.
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			for i := 0; i < 5; i++ {
+				request, _ := http.NewRequestWithContext(ctx, tc.method, "https://iaas.us-phoenix-1.oraclecloud.com", nil)
+				_, _ = dispatcher.Do(request)
+			}
+			if next.count != tc.expectedCount {
+				t.Errorf("got %d dispatched requests ; wanted %d", next.count, tc.expectedCount)
+			}
+		})
+	}
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	testCases := map[string]struct {
+		qps float32
+	}{
+		"unset qps disables rate limiting": {
+			qps: 0,
+		},
+		"negative qps disables rate limiting": {
+			qps: -1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			next := &countingDispatcher{}
+			dispatcher := &rateLimitedDispatcher{dispatcher: next, rateLimiter: NewRateLimiter(tc.qps, 0, tc.qps, 0)}
+			for i := 0; i < 100; i++ {
+				for _, method := range []string{http.MethodGet, http.MethodPost} {
+					request, _ := http.NewRequest(method, "https://iaas.us-phoenix-1.oraclecloud.com", nil)
+					if _, err := dispatcher.Do(request); err != nil {
+						t.Fatalf("unexpected error: %+v", err)
+					}
+				}
+			}
+			if next.count != 200 {
+				t.Errorf("got %d dispatched requests ; wanted 200", next.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"net/http"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/klog/v2"
+)
+
+// OpcRequestIDHeader is the header OCI returns the unique ID of a request in. Oracle support uses the ID to locate the
+// request, so it should be logged with every failed operation.
+const OpcRequestIDHeader = "opc-request-id"
+
+// LogRequestIDs logs the opc-request-id of every response received by the specified client. Failed calls are logged
+// at level 2 and successful calls at level 5.
+func LogRequestIDs(client *common.BaseClient) {
+	client.HTTPClient = &requestIDLoggingDispatcher{dispatcher: client.HTTPClient}
+}
+
+// OpcRequestID returns the opc-request-id of the specified error if it was returned by an OCI service, or the empty
+// string otherwise.
+func OpcRequestID(err error) string {
+	if serviceErr, ok := common.IsServiceError(err); ok {
+		return serviceErr.GetOpcRequestID()
+	}
+	return ""
+}
+
+// ResponseOpcRequestID returns the value of the OpcRequestId field of an OCI response, or the empty string if the
+// response does not have one.
+func ResponseOpcRequestID(opcRequestID *string) string {
+	if opcRequestID == nil {
+		return ""
+	}
+	return *opcRequestID
+}
+
+// requestIDLoggingDispatcher logs the opc-request-id of the response of every request it dispatches.
+type requestIDLoggingDispatcher struct {
+	dispatcher common.HTTPRequestDispatcher
+}
+
+// Do implements common.HTTPRequestDispatcher.
+func (d *requestIDLoggingDispatcher) Do(request *http.Request) (*http.Response, error) {
+	response, err := d.dispatcher.Do(request)
+	if err != nil {
+		klog.V(2).InfoS("OCI API call failed", "method", request.Method, "host", request.URL.Host, "path", request.URL.Path,
+			"opcRequestID", request.Header.Get(OpcRequestIDHeader), "err", err)
+		return response, err
+	}
+
+	opcRequestID := response.Header.Get(OpcRequestIDHeader)
+	if response.StatusCode >= http.StatusBadRequest {
+		klog.V(2).InfoS("OCI API call failed", "method", request.Method, "host", request.URL.Host, "path", request.URL.Path,
+			"status", response.StatusCode, "opcRequestID", opcRequestID)
+	} else {
+		klog.V(5).InfoS("OCI API call succeeded", "method", request.Method, "host", request.URL.Host, "path", request.URL.Path,
+			"status", response.StatusCode, "opcRequestID", opcRequestID)
+	}
+	return response, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type headerDispatcher struct {
+	statusCode int
+	header     http.Header
+}
+
+func (d *headerDispatcher) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: d.statusCode, Header: d.header}, nil
+}
+
+func TestRequestIDLoggingDispatcher(t *testing.T) {
+	testCases := map[string]struct {
+		statusCode int
+	}{
+		"successful call": {
+			statusCode: http.StatusOK,
+		},
+		"failed call": {
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(OpcRequestIDHeader, "AAAA/BBBB/CCCC")
+			dispatcher := &requestIDLoggingDispatcher{dispatcher: &headerDispatcher{statusCode: tc.statusCode, header: header}}
+
+			request, _ := http.NewRequest(http.MethodPut, "https://iaas.us-phoenix-1.oraclecloud.com/20160918/instancePools/1", nil)
+			response, err := dispatcher.Do(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if response.StatusCode != tc.statusCode || response.Header.Get(OpcRequestIDHeader) != "AAAA/BBBB/CCCC" {
+				t.Errorf("got response %+v ; wanted the response of the wrapped dispatcher", response)
+			}
+		})
+	}
+}
+
+func TestOpcRequestID(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected string
+	}{
+		"service error": {
+			err:      mockServiceError{opcRequestID: "AAAA/BBBB/CCCC"},
+			expected: "AAAA/BBBB/CCCC",
+		},
+		"other error": {
+			err:      errors.New("connection refused"),
+			expected: "",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := OpcRequestID(tc.err); got != tc.expected {
+				t.Errorf("got %q ; wanted %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResponseOpcRequestID(t *testing.T) {
+	opcRequestID := "AAAA/BBBB/CCCC"
+	if got := ResponseOpcRequestID(&opcRequestID); got != opcRequestID {
+		t.Errorf("got %q ; wanted %q", got, opcRequestID)
+	}
+	if got := ResponseOpcRequestID(nil); got != "" {
+		t.Errorf("got %q ; wanted the empty string", got)
+	}
+}
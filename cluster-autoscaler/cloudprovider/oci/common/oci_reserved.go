@@ -0,0 +1,196 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// evictionSignalMemoryAvailable is the kubelet eviction signal for available memory.
+	evictionSignalMemoryAvailable = "memory.available"
+	// evictionSignalNodeFsAvailable is the kubelet eviction signal for available ephemeral storage.
+	evictionSignalNodeFsAvailable = "nodefs.available"
+)
+
+// The flag values may be quoted, e.g. --kube-reserved="cpu=100m,memory=1Gi".
+var (
+	kubeReservedArgRegexp   = regexp.MustCompile(`-?-kube-reserved[= ]("[^"]*"|'[^']*'|\S*)`)
+	systemReservedArgRegexp = regexp.MustCompile(`-?-system-reserved[= ]("[^"]*"|'[^']*'|\S*)`)
+	evictionHardArgRegexp   = regexp.MustCompile(`-?-eviction-hard[= ]("[^"]*"|'[^']*'|\S*)`)
+)
+
+// KubeletReservation holds the resources kubelet holds back from a node's capacity when computing
+// allocatable, i.e. kube-reserved, system-reserved and the hard eviction thresholds.
+type KubeletReservation struct {
+	KubeReserved   apiv1.ResourceList
+	SystemReserved apiv1.ResourceList
+	// EvictionHard holds the absolute hard eviction thresholds.
+	EvictionHard apiv1.ResourceList
+	// EvictionHardRatio holds the hard eviction thresholds specified as a percentage of capacity.
+	EvictionHardRatio map[apiv1.ResourceName]float64
+}
+
+// NewKubeletReservation builds a KubeletReservation from kube-reserved, system-reserved and eviction-hard values
+// specified in the same format as the corresponding kubelet flags, e.g. "cpu=100m,memory=1Gi" and
+// "memory.available<100Mi,nodefs.available<10%". Empty values are ignored.
+func NewKubeletReservation(kubeReserved, systemReserved, evictionHard string) (*KubeletReservation, error) {
+	r := &KubeletReservation{}
+	var err error
+	if r.KubeReserved, err = parseReservedResources(kubeReserved); err != nil {
+		return nil, fmt.Errorf("invalid kube-reserved value %q: %v", kubeReserved, err)
+	}
+	if r.SystemReserved, err = parseReservedResources(systemReserved); err != nil {
+		return nil, fmt.Errorf("invalid system-reserved value %q: %v", systemReserved, err)
+	}
+	if r.EvictionHard, r.EvictionHardRatio, err = parseEvictionHard(evictionHard); err != nil {
+		return nil, fmt.Errorf("invalid eviction-hard value %q: %v", evictionHard, err)
+	}
+	return r, nil
+}
+
+// KubeletReservationFromArgs builds a KubeletReservation from the --kube-reserved, --system-reserved and
+// --eviction-hard flags found in the specified kubelet arguments. Any flag that is not present is taken from defaults.
+func KubeletReservationFromArgs(kubeletArgs string, defaults *KubeletReservation) (*KubeletReservation, error) {
+	kubeReserved := kubeletArgValue(kubeReservedArgRegexp, kubeletArgs)
+	systemReserved := kubeletArgValue(systemReservedArgRegexp, kubeletArgs)
+	evictionHard := kubeletArgValue(evictionHardArgRegexp, kubeletArgs)
+
+	r, err := NewKubeletReservation(kubeReserved, systemReserved, evictionHard)
+	if err != nil {
+		return nil, err
+	}
+	if defaults == nil {
+		return r, nil
+	}
+	if kubeReserved == "" {
+		r.KubeReserved = defaults.KubeReserved
+	}
+	if systemReserved == "" {
+		r.SystemReserved = defaults.SystemReserved
+	}
+	if evictionHard == "" {
+		r.EvictionHard = defaults.EvictionHard
+		r.EvictionHardRatio = defaults.EvictionHardRatio
+	}
+	return r, nil
+}
+
+// kubeletArgValue returns the value of the flag matched by the specified regexp in the kubelet arguments, without
+// surrounding quotes.
+func kubeletArgValue(re *regexp.Regexp, kubeletArgs string) string {
+	submatches := re.FindStringSubmatch(kubeletArgs)
+	if len(submatches) != 2 {
+		return ""
+	}
+	value := submatches[1]
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
+
+// Allocatable returns the allocatable resources of a node with the specified capacity. Resources are never
+// reduced below zero. A nil reservation results in allocatable being equal to capacity.
+func (r *KubeletReservation) Allocatable(capacity apiv1.ResourceList) apiv1.ResourceList {
+	allocatable := capacity.DeepCopy()
+	if r == nil {
+		return allocatable
+	}
+
+	for name, quantity := range allocatable {
+		reserved := resource.NewQuantity(0, quantity.Format)
+		for _, list := range []apiv1.ResourceList{r.KubeReserved, r.SystemReserved, r.EvictionHard} {
+			if q, ok := list[name]; ok {
+				reserved.Add(q)
+			}
+		}
+		if ratio, ok := r.EvictionHardRatio[name]; ok {
+			reserved.Add(*resource.NewQuantity(int64(float64(quantity.Value())*ratio), quantity.Format))
+		}
+		if reserved.IsZero() {
+			continue
+		}
+		quantity.Sub(*reserved)
+		if quantity.Sign() < 0 {
+			quantity = *resource.NewQuantity(0, quantity.Format)
+		}
+		allocatable[name] = quantity
+	}
+	return allocatable
+}
+
+// parseReservedResources parses a list of resource reservations in the form of "cpu=100m,memory=1Gi".
+func parseReservedResources(value string) (apiv1.ResourceList, error) {
+	result := apiv1.ResourceList{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected <resource>=<quantity> but got %q", pair)
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		result[apiv1.ResourceName(strings.TrimSpace(kv[0]))] = quantity
+	}
+	return result, nil
+}
+
+// parseEvictionHard parses a list of hard eviction thresholds in the form of "memory.available<100Mi,nodefs.available<10%".
+// Only the memory.available and nodefs.available signals affect allocatable, any other signal is ignored.
+func parseEvictionHard(value string) (apiv1.ResourceList, map[apiv1.ResourceName]float64, error) {
+	absolute := apiv1.ResourceList{}
+	ratios := map[apiv1.ResourceName]float64{}
+	for _, threshold := range strings.Split(value, ",") {
+		threshold = strings.TrimSpace(threshold)
+		if threshold == "" {
+			continue
+		}
+		kv := strings.SplitN(threshold, "<", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("expected <signal><<quantity> but got %q", threshold)
+		}
+
+		var name apiv1.ResourceName
+		switch strings.TrimSpace(kv[0]) {
+		case evictionSignalMemoryAvailable:
+			name = apiv1.ResourceMemory
+		case evictionSignalNodeFsAvailable:
+			name = apiv1.ResourceEphemeralStorage
+		default:
+			continue
+		}
+
+		quantity := strings.TrimSpace(kv[1])
+		if strings.HasSuffix(quantity, "%") {
+			percentage, err := strconv.ParseFloat(strings.TrimSuffix(quantity, "%"), 64)
+			if err != nil {
+				return nil, nil, err
+			}
+			if percentage < 0 || percentage > 100 {
+				return nil, nil, fmt.Errorf("percentage must be between 0 and 100 but got %q", quantity)
+			}
+			ratios[name] = percentage / 100
+			continue
+		}
+		q, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+		absolute[name] = q
+	}
+	return absolute, ratios, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestKubeletReservationAllocatable(t *testing.T) {
+	capacity := apiv1.ResourceList{
+		apiv1.ResourcePods:             resource.MustParse("110"),
+		apiv1.ResourceCPU:              resource.MustParse("4"),
+		apiv1.ResourceMemory:           resource.MustParse("16Gi"),
+		apiv1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+	}
+
+	testCases := map[string]struct {
+		kubeReserved   string
+		systemReserved string
+		evictionHard   string
+		expected       apiv1.ResourceList
+		expectedErr    bool
+	}{
+		"no reservation": {
+			expected: capacity,
+		},
+		"kube and system reserved": {
+			kubeReserved:   "cpu=100m,memory=1Gi",
+			systemReserved: "cpu=100m, memory=512Mi",
+			expected: apiv1.ResourceList{
+				apiv1.ResourcePods:             resource.MustParse("110"),
+				apiv1.ResourceCPU:              resource.MustParse("3800m"),
+				apiv1.ResourceMemory:           resource.MustParse("14848Mi"),
+				apiv1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			},
+		},
+		"eviction hard": {
+			evictionHard: "memory.available<100Mi,nodefs.available<10%,imagefs.available<15%",
+			expected: apiv1.ResourceList{
+				apiv1.ResourcePods:             resource.MustParse("110"),
+				apiv1.ResourceCPU:              resource.MustParse("4"),
+				apiv1.ResourceMemory:           resource.MustParse("16284Mi"),
+				apiv1.ResourceEphemeralStorage: resource.MustParse("92160Mi"),
+			},
+		},
+		"reservation larger than capacity": {
+			kubeReserved: "cpu=5",
+			expected: apiv1.ResourceList{
+				apiv1.ResourcePods:             resource.MustParse("110"),
+				apiv1.ResourceCPU:              resource.MustParse("0"),
+				apiv1.ResourceMemory:           resource.MustParse("16Gi"),
+				apiv1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			},
+		},
+		"invalid kube reserved": {
+			kubeReserved: "cpu",
+			expectedErr:  true,
+		},
+		"invalid eviction hard": {
+			evictionHard: "memory.available<101%",
+			expectedErr:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			reservation, err := NewKubeletReservation(tc.kubeReserved, tc.systemReserved, tc.evictionHard)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected err but not nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			allocatable := reservation.Allocatable(capacity)
+			for name, expected := range tc.expected {
+				if got := allocatable[name]; got.Cmp(expected) != 0 {
+					t.Errorf("got %s %s ; wanted %s", name, got.String(), expected.String())
+				}
+			}
+		})
+	}
+}
+
+func TestKubeletReservationFromArgs(t *testing.T) {
+	defaults, err := NewKubeletReservation("cpu=100m", "memory=1Gi", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	reservation, err := KubeletReservationFromArgs("--max-pods=31 --kube-reserved=cpu=200m,memory=2Gi --eviction-hard memory.available<500Mi", defaults)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if got := reservation.KubeReserved[apiv1.ResourceCPU]; got.Cmp(resource.MustParse("200m")) != 0 {
+		t.Errorf("got kube-reserved cpu %s ; wanted 200m", got.String())
+	}
+	if got := reservation.SystemReserved[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("got system-reserved memory %s ; wanted 1Gi from defaults", got.String())
+	}
+	if got := reservation.EvictionHard[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("500Mi")) != 0 {
+		t.Errorf("got eviction-hard memory %s ; wanted 500Mi", got.String())
+	}
+}
+
+func TestKubeletReservationFromQuotedArgs(t *testing.T) {
+	reservation, err := KubeletReservationFromArgs(`--kube-reserved="cpu=200m, memory=2Gi" --system-reserved='memory=1Gi' --eviction-hard="memory.available<500Mi"`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if got := reservation.KubeReserved[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("2Gi")) != 0 {
+		t.Errorf("got kube-reserved memory %s ; wanted 2Gi", got.String())
+	}
+	if got := reservation.SystemReserved[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("got system-reserved memory %s ; wanted 1Gi", got.String())
+	}
+	if got := reservation.EvictionHard[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("500Mi")) != 0 {
+		t.Errorf("got eviction-hard memory %s ; wanted 500Mi", got.String())
+	}
+}
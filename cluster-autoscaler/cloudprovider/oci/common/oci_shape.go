@@ -7,6 +7,10 @@ package common
 import (
 	"context"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +21,33 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// windowsOperatingSystem is the operating system of Windows images.
+	windowsOperatingSystem = "Windows"
+	// defaultBootVolumeSizeInGBs is the size of boot volumes launched from an image without an explicit size. It is
+	// also the minimum size of any boot volume, so it is assumed for instances launched from an existing boot volume.
+	defaultBootVolumeSizeInGBs = 50
+	// bootVolumeReservedPercent is the percentage of the boot volume the ephemeral-storage capacity of a node is derated
+	// by. kubelet reports the size of the root filesystem, which excludes the image's /boot and EFI system partitions
+	// as well as the filesystem's own metadata, so the template must not claim the whole volume.
+	bootVolumeReservedPercent = 10
+)
+
+// DefaultFlexShapeMemoryPerOcpuInGBs holds the amount of memory per OCPU OCI provisions for a flexible shape when the
+// memory is not explicitly configured.
+var DefaultFlexShapeMemoryPerOcpuInGBs = map[string]float32{
+	"VM.Standard.E3.Flex": 16,
+	"VM.Standard.E4.Flex": 16,
+	"VM.Standard.E5.Flex": 16,
+	"VM.Standard3.Flex":   16,
+	"VM.Optimized3.Flex":  14,
+	"VM.Standard.A1.Flex": 6,
+}
+
+// fallbackFlexShapeMemoryPerOcpuInGBs is the amount of memory per OCPU assumed for flexible shapes that have no
+// known default, i.e. the minimum any flexible shape can be configured with.
+const fallbackFlexShapeMemoryPerOcpuInGBs = 1
+
 // ShapeGetter returns the oci shape attributes for the pool.
 type ShapeGetter interface {
 	GetNodePoolShape(*oke.NodePool, int64) (*Shape, error)
@@ -24,10 +55,11 @@ type ShapeGetter interface {
 	Refresh()
 }
 
-// ShapeClient is an interface around the GetInstanceConfiguration and ListShapes calls.
+// ShapeClient is an interface around the GetInstanceConfiguration, ListShapes and GetImage calls.
 type ShapeClient interface {
 	GetInstanceConfiguration(context.Context, core.GetInstanceConfigurationRequest) (core.GetInstanceConfigurationResponse, error)
 	ListShapes(context.Context, core.ListShapesRequest) (core.ListShapesResponse, error)
+	GetImage(context.Context, core.GetImageRequest) (core.GetImageResponse, error)
 }
 
 // ShapeClientImpl is the implementation for fetching shape information.
@@ -48,28 +80,61 @@ func (cc ShapeClientImpl) ListShapes(ctx context.Context, req core.ListShapesReq
 	return cc.ComputeClient.ListShapes(ctx, req)
 }
 
+// GetImage gets the image.
+func (cc ShapeClientImpl) GetImage(ctx context.Context, req core.GetImageRequest) (core.GetImageResponse, error) {
+	return cc.ComputeClient.GetImage(ctx, req)
+}
+
 // Shape includes the resource attributes of a given shape which should be used
 // for constructing node templates.
 type Shape struct {
-	Name                    string
-	CPU                     float32
+	Name string
+	// CPU is in OCPUs for instance pools and in vCPUs for node pools.
+	CPU float32
+	// ThreadsPerCore is the number of vCPUs per OCPU of the shape, see ShapeThreadsPerCore.
+	ThreadsPerCore          int
 	GPU                     int
 	MemoryInBytes           float32
 	EphemeralStorageInBytes float32
+	// ImageID is the image instances are launched from, if known.
+	ImageID string
+	// OperatingSystem is the operating system of the image, e.g. "Oracle Linux" or "Windows", if known.
+	OperatingSystem string
+	// LaunchConfigHash is the digest of the image and user data instances are launched with, see LaunchConfigHash.
+	LaunchConfigHash string
+	// NumaNodesPerSocket is the NUMA nodes per socket setting of bare metal shapes, e.g. "NPS2", if configured.
+	NumaNodesPerSocket string
+	// SecondaryVnics is the number of secondary VNICs, e.g. for SR-IOV, instances are launched with.
+	SecondaryVnics int
 }
 
-// CreateShapeGetter creates a new oci shape getter.
-func CreateShapeGetter(shapeClient ShapeClient) ShapeGetter {
+// IsWindows returns true if instances of the shape run Windows.
+func (s *Shape) IsWindows() bool {
+	return strings.EqualFold(s.OperatingSystem, windowsOperatingSystem)
+}
+
+// CreateShapeGetter creates a new oci shape getter. flexShapeMemoryPerOcpuInGBs holds the memory per OCPU assumed for
+// flexible shapes configured without memory; DefaultFlexShapeMemoryPerOcpuInGBs is used if it is nil.
+func CreateShapeGetter(shapeClient ShapeClient, flexShapeMemoryPerOcpuInGBs map[string]float32) ShapeGetter {
+	if flexShapeMemoryPerOcpuInGBs == nil {
+		flexShapeMemoryPerOcpuInGBs = DefaultFlexShapeMemoryPerOcpuInGBs
+	}
 	return &shapeGetterImpl{
-		shapeClient: shapeClient,
-		cache:       map[string]*Shape{},
+		shapeClient:                 shapeClient,
+		cache:                       map[string]*Shape{},
+		imageOperatingSystems:       map[string]string{},
+		flexShapeMemoryPerOcpuInGBs: flexShapeMemoryPerOcpuInGBs,
 	}
 }
 
 type shapeGetterImpl struct {
 	shapeClient ShapeClient
 	cache       map[string]*Shape
-	mu          sync.Mutex
+	// imageOperatingSystems caches the operating system of images by image ID. Images are immutable, so it is never
+	// cleared.
+	imageOperatingSystems       map[string]string
+	mu                          sync.Mutex
+	flexShapeMemoryPerOcpuInGBs map[string]float32
 }
 
 // Refresh clears out the cache to be populated again as the pool shapes are re-requested
@@ -82,10 +147,17 @@ func (osf *shapeGetterImpl) Refresh() {
 func (osf *shapeGetterImpl) GetNodePoolShape(np *oke.NodePool, ephemeralStorage int64) (*Shape, error) {
 	shapeName := *np.NodeShape
 	if np.NodeShapeConfig != nil {
+		ocpus := getFloat32(np.NodeShapeConfig.Ocpus)
+		memoryInGBs := ocpus * osf.memoryPerOcpuInGBs(shapeName)
+		if np.NodeShapeConfig.MemoryInGBs != nil {
+			memoryInGBs = *np.NodeShapeConfig.MemoryInGBs
+		}
 		return &Shape{
-			CPU: *np.NodeShapeConfig.Ocpus * 2,
+			Name:           shapeName,
+			CPU:            ocpus * 2,
+			ThreadsPerCore: ShapeThreadsPerCore(shapeName, nil),
 			// num_bytes * kilo * mega * giga
-			MemoryInBytes:           *np.NodeShapeConfig.MemoryInGBs * 1024 * 1024 * 1024,
+			MemoryInBytes:           memoryInGBs * 1024 * 1024 * 1024,
 			GPU:                     0,
 			EphemeralStorageInBytes: float32(ephemeralStorage),
 		}, nil
@@ -115,7 +187,9 @@ func (osf *shapeGetterImpl) GetNodePoolShape(np *oke.NodePool, ephemeralStorage
 	// Update the cache based on latest results
 	for _, s := range resp.Items {
 		osf.cache[*s.Shape] = &Shape{
+			Name:                    *s.Shape,
 			CPU:                     getFloat32(s.Ocpus) * 2, // convert ocpu to vcpu
+			ThreadsPerCore:          ShapeThreadsPerCore(*s.Shape, nil),
 			GPU:                     getInt(s.Gpus),
 			MemoryInBytes:           getFloat32(s.MemoryInGBs) * 1024 * 1024 * 1024,
 			EphemeralStorageInBytes: float32(ephemeralStorage),
@@ -155,6 +229,7 @@ func (osf *shapeGetterImpl) GetInstancePoolShape(ip *core.InstancePool) (*Shape,
 	}
 
 	if instanceDetails, ok := instanceConfig.InstanceDetails.(core.ComputeInstanceDetails); ok {
+		shape.SecondaryVnics = len(instanceDetails.SecondaryVnics)
 		// flexible shape use details or look up the static shape details below.
 		if instanceDetails.LaunchDetails != nil && instanceDetails.LaunchDetails.ShapeConfig != nil {
 			if instanceDetails.LaunchDetails.Shape != nil {
@@ -162,8 +237,8 @@ func (osf *shapeGetterImpl) GetInstancePoolShape(ip *core.InstancePool) (*Shape,
 			}
 			if instanceDetails.LaunchDetails.ShapeConfig.Ocpus != nil {
 				shape.CPU = *instanceDetails.LaunchDetails.ShapeConfig.Ocpus
-				// Minimum amount of memory unless explicitly set higher
-				shape.MemoryInBytes = *instanceDetails.LaunchDetails.ShapeConfig.Ocpus * 1024 * 1024 * 1024
+				// Default amount of memory of the shape unless explicitly set
+				shape.MemoryInBytes = *instanceDetails.LaunchDetails.ShapeConfig.Ocpus * osf.memoryPerOcpuInGBs(shape.Name) * 1024 * 1024 * 1024
 			}
 			if instanceDetails.LaunchDetails.ShapeConfig.MemoryInGBs != nil {
 				shape.MemoryInBytes = *instanceDetails.LaunchDetails.ShapeConfig.MemoryInGBs * 1024 * 1024 * 1024
@@ -206,6 +281,28 @@ func (osf *shapeGetterImpl) GetInstancePoolShape(ip *core.InstancePool) (*Shape,
 				}
 			}
 		}
+
+		if instanceDetails.LaunchDetails != nil {
+			shape.ThreadsPerCore = ShapeThreadsPerCore(shape.Name, instanceDetails.LaunchDetails.PlatformConfig)
+			shape.CPU = EnabledCores(shape.CPU, instanceDetails.LaunchDetails.PlatformConfig)
+			shape.NumaNodesPerSocket = ShapeNumaNodesPerSocket(instanceDetails.LaunchDetails.PlatformConfig)
+			switch sourceDetails := instanceDetails.LaunchDetails.SourceDetails.(type) {
+			case core.InstanceConfigurationInstanceSourceViaImageDetails:
+				if sourceDetails.ImageId != nil {
+					shape.ImageID = *sourceDetails.ImageId
+					shape.OperatingSystem = osf.imageOperatingSystem(*sourceDetails.ImageId)
+				}
+				bootVolumeSizeInGBs := int64(defaultBootVolumeSizeInGBs)
+				if sourceDetails.BootVolumeSizeInGBs != nil {
+					bootVolumeSizeInGBs = *sourceDetails.BootVolumeSizeInGBs
+				}
+				shape.EphemeralStorageInBytes = float32(bootVolumeEphemeralStorageInBytes(bootVolumeSizeInGBs))
+			case core.InstanceConfigurationInstanceSourceViaBootVolumeDetails:
+				// The size of the existing boot volume is not looked up.
+				shape.EphemeralStorageInBytes = float32(bootVolumeEphemeralStorageInBytes(defaultBootVolumeSizeInGBs))
+			}
+			shape.LaunchConfigHash = LaunchConfigHash(shape.ImageID, instanceDetails.LaunchDetails.Metadata)
+		}
 	} else {
 		return nil, fmt.Errorf("(compute) instance configuration for instance-pool %s not found", *ip.Id)
 	}
@@ -219,6 +316,143 @@ func (osf *shapeGetterImpl) GetInstancePoolShape(ip *core.InstancePool) (*Shape,
 	return shape, nil
 }
 
+// imageOperatingSystem returns the operating system of the image, or an empty string if it cannot be looked up.
+func (osf *shapeGetterImpl) imageOperatingSystem(imageID string) string {
+	if operatingSystem, ok := osf.imageOperatingSystems[imageID]; ok {
+		return operatingSystem
+	}
+	resp, err := osf.shapeClient.GetImage(context.Background(), core.GetImageRequest{
+		ImageId: common.String(imageID),
+	})
+	if err != nil {
+		// Templates of Linux nodes are the better guess than no template at all, so this is not an error.
+		klog.Warningf("unable to get the operating system of image %s: %v", imageID, err)
+		return ""
+	}
+	var operatingSystem string
+	if resp.OperatingSystem != nil {
+		operatingSystem = *resp.OperatingSystem
+	}
+	osf.imageOperatingSystems[imageID] = operatingSystem
+	return operatingSystem
+}
+
+// armShapeFamily matches the names of shapes with Arm processors, which have a single thread per core.
+var armShapeFamily = regexp.MustCompile(`\.A[0-9]+\.`)
+
+// ShapeThreadsPerCore returns the number of vCPUs kubelet reports per OCPU of the specified shape, i.e. 1 for Arm shapes
+// and for shapes launched with symmetric multi-threading disabled in their platform config, and 2 for any other shape.
+func ShapeThreadsPerCore(shapeName string, platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig) int {
+	if armShapeFamily.MatchString(shapeName) {
+		return 1
+	}
+
+	var isSymmetricMultiThreadingEnabled *bool
+	switch config := platformConfig.(type) {
+	case core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig:
+		isSymmetricMultiThreadingEnabled = config.IsSymmetricMultiThreadingEnabled
+	case core.InstanceConfigurationAmdMilanBmGpuLaunchInstancePlatformConfig:
+		isSymmetricMultiThreadingEnabled = config.IsSymmetricMultiThreadingEnabled
+	case core.InstanceConfigurationAmdRomeBmLaunchInstancePlatformConfig:
+		isSymmetricMultiThreadingEnabled = config.IsSymmetricMultiThreadingEnabled
+	case core.InstanceConfigurationAmdRomeBmGpuLaunchInstancePlatformConfig:
+		isSymmetricMultiThreadingEnabled = config.IsSymmetricMultiThreadingEnabled
+	case core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig:
+		isSymmetricMultiThreadingEnabled = config.IsSymmetricMultiThreadingEnabled
+	}
+	if isSymmetricMultiThreadingEnabled != nil && !*isSymmetricMultiThreadingEnabled {
+		return 1
+	}
+	return 2
+}
+
+// IsPodShape returns true if the shape is the shape of the pods of virtual nodes, e.g. "Pod.Standard.E4.Flex".
+func IsPodShape(shapeName string) bool {
+	return strings.HasPrefix(shapeName, "Pod.")
+}
+
+// EnabledCores returns the number of the specified OCPUs that remain enabled by the platform config of a bare metal
+// shape, i.e. its PercentageOfCoresEnabled of the OCPUs rounded down to whole cores but at least one core.
+func EnabledCores(ocpus float32, platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig) float32 {
+	var percentageOfCoresEnabled *int
+	switch config := platformConfig.(type) {
+	case core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig:
+		percentageOfCoresEnabled = config.PercentageOfCoresEnabled
+	case core.InstanceConfigurationAmdRomeBmLaunchInstancePlatformConfig:
+		percentageOfCoresEnabled = config.PercentageOfCoresEnabled
+	case core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig:
+		percentageOfCoresEnabled = config.PercentageOfCoresEnabled
+	}
+	if percentageOfCoresEnabled == nil || *percentageOfCoresEnabled <= 0 || *percentageOfCoresEnabled >= 100 {
+		return ocpus
+	}
+	enabled := float32(math.Floor(float64(ocpus) * float64(*percentageOfCoresEnabled) / 100))
+	if enabled < 1 {
+		return 1
+	}
+	return enabled
+}
+
+// ShapeNumaNodesPerSocket returns the NUMA nodes per socket the platform config of a bare metal shape sets, e.g. "NPS2",
+// or an empty string if it does not set any.
+func ShapeNumaNodesPerSocket(platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig) string {
+	switch config := platformConfig.(type) {
+	case core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig:
+		return string(config.NumaNodesPerSocket)
+	case core.InstanceConfigurationAmdMilanBmGpuLaunchInstancePlatformConfig:
+		return string(config.NumaNodesPerSocket)
+	case core.InstanceConfigurationAmdRomeBmLaunchInstancePlatformConfig:
+		return string(config.NumaNodesPerSocket)
+	case core.InstanceConfigurationAmdRomeBmGpuLaunchInstancePlatformConfig:
+		return string(config.NumaNodesPerSocket)
+	case core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig:
+		return string(config.NumaNodesPerSocket)
+	}
+	return ""
+}
+
+// bootVolumeEphemeralStorageInBytes returns the ephemeral-storage capacity of a node with a boot volume of the specified size.
+func bootVolumeEphemeralStorageInBytes(sizeInGBs int64) int64 {
+	sizeInBytes := sizeInGBs * 1024 * 1024 * 1024
+	return sizeInBytes - sizeInBytes*bootVolumeReservedPercent/100
+}
+
+// memoryPerOcpuInGBs returns the memory per OCPU assumed for the specified flexible shape when its memory is not configured.
+func (osf *shapeGetterImpl) memoryPerOcpuInGBs(shapeName string) float32 {
+	if memoryPerOcpu, ok := osf.flexShapeMemoryPerOcpuInGBs[shapeName]; ok {
+		return memoryPerOcpu
+	}
+	return fallbackFlexShapeMemoryPerOcpuInGBs
+}
+
+// ParseFlexShapeMemoryPerOcpu parses a list of flexible shape memory per OCPU defaults in the form of
+// "VM.Standard.E4.Flex=16,VM.Standard.A1.Flex=6" and merges them on top of DefaultFlexShapeMemoryPerOcpuInGBs.
+func ParseFlexShapeMemoryPerOcpu(value string) (map[string]float32, error) {
+	result := make(map[string]float32, len(DefaultFlexShapeMemoryPerOcpuInGBs))
+	for shapeName, memoryPerOcpu := range DefaultFlexShapeMemoryPerOcpuInGBs {
+		result[shapeName] = memoryPerOcpu
+	}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected <shape>=<memory in GBs per OCPU> but got %q", pair)
+		}
+		memoryPerOcpu, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 32)
+		if err != nil {
+			return nil, err
+		}
+		if memoryPerOcpu <= 0 {
+			return nil, fmt.Errorf("memory per OCPU of shape %q must be positive", strings.TrimSpace(kv[0]))
+		}
+		result[strings.TrimSpace(kv[0])] = float32(memoryPerOcpu)
+	}
+	return result, nil
+}
+
 // getFloat32 is a helper to get a float32 pointer value or default to 0.
 func getFloat32(f *float32) float32 {
 	if f == nil {
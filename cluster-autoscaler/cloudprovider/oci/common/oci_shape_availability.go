@@ -0,0 +1,77 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// CheckShapeAvailability cross-checks the shape of a node group against the shape catalog of the compartment. It
+// returns a problem if the shape is not offered in some of the availability domains the node group launches instances
+// in, or not offered at all when no availability domains are specified, e.g. because it was retired. Launches of such
+// a shape fail however often they are retried, so the node group must not be scaled up. It returns an error if the
+// shape catalog could not be listed.
+func CheckShapeAvailability(ctx context.Context, client ShapeClient, compartmentID *string, shapeName string, availabilityDomains []string) (problem error, err error) {
+	if len(availabilityDomains) == 0 {
+		offered, err := isShapeOffered(ctx, client, compartmentID, nil, shapeName)
+		if err != nil {
+			return nil, err
+		}
+		if !offered {
+			return fmt.Errorf("shape %s is retired or not offered in this region", shapeName), nil
+		}
+		return nil, nil
+	}
+
+	var unavailable []string
+	for _, ad := range availabilityDomains {
+		offered, err := isShapeOffered(ctx, client, compartmentID, common.String(ad), shapeName)
+		if err != nil {
+			return nil, err
+		}
+		if !offered {
+			unavailable = append(unavailable, ad)
+		}
+	}
+	if len(unavailable) == len(availabilityDomains) {
+		return fmt.Errorf("shape %s is retired or not offered in availability domain(s) %s",
+			shapeName, strings.Join(unavailable, ", ")), nil
+	}
+	if len(unavailable) > 0 {
+		return fmt.Errorf("shape %s is not offered in availability domain(s) %s",
+			shapeName, strings.Join(unavailable, ", ")), nil
+	}
+	return nil, nil
+}
+
+// isShapeOffered returns true if the shape is listed in the shape catalog of the compartment, optionally restricted to
+// an availability domain.
+func isShapeOffered(ctx context.Context, client ShapeClient, compartmentID, availabilityDomain *string, shapeName string) (bool, error) {
+	var page *string
+	for {
+		resp, err := client.ListShapes(ctx, core.ListShapesRequest{
+			CompartmentId:      compartmentID,
+			AvailabilityDomain: availabilityDomain,
+			Page:               page,
+			Limit:              common.Int(500),
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, shape := range resp.Items {
+			if shape.Shape != nil && *shape.Shape == shapeName {
+				return true, nil
+			}
+		}
+		if page = resp.OpcNextPage; page == nil {
+			return false, nil
+		}
+	}
+}
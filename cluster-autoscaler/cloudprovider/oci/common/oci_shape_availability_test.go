@@ -0,0 +1,109 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// availabilityDomainShapeClient lists the shapes offered in each availability domain one shape per page, and the
+// shapes offered in any of them if no availability domain is requested.
+type availabilityDomainShapeClient struct {
+	mockShapeClient
+	shapes map[string][]string
+}
+
+func (c *availabilityDomainShapeClient) ListShapes(_ context.Context, req core.ListShapesRequest) (core.ListShapesResponse, error) {
+	var names []string
+	if req.AvailabilityDomain != nil {
+		names = c.shapes[*req.AvailabilityDomain]
+	} else {
+		// Map iteration order is random, sort the availability domains so that every page comes from the same list.
+		availabilityDomains := make([]string, 0, len(c.shapes))
+		for availabilityDomain := range c.shapes {
+			availabilityDomains = append(availabilityDomains, availabilityDomain)
+		}
+		sort.Strings(availabilityDomains)
+		for _, availabilityDomain := range availabilityDomains {
+			names = append(names, c.shapes[availabilityDomain]...)
+		}
+	}
+	page := 0
+	if req.Page != nil {
+		fmt.Sscan(*req.Page, &page)
+	}
+	if page >= len(names) {
+		return core.ListShapesResponse{}, nil
+	}
+	resp := core.ListShapesResponse{Items: []core.Shape{{Shape: common.String(names[page])}}}
+	if page+1 < len(names) {
+		resp.OpcNextPage = common.String(fmt.Sprint(page + 1))
+	}
+	return resp, nil
+}
+
+func TestCheckShapeAvailability(t *testing.T) {
+	client := &availabilityDomainShapeClient{shapes: map[string][]string{
+		"AD-1": {"VM.Standard.E4.Flex", "VM.Standard.E5.Flex"},
+		"AD-2": {"VM.Standard.E5.Flex"},
+	}}
+
+	testCases := map[string]struct {
+		shape               string
+		availabilityDomains []string
+		expectedProblem     string
+	}{
+		"offered in every availability domain": {
+			shape:               "VM.Standard.E5.Flex",
+			availabilityDomains: []string{"AD-1", "AD-2"},
+		},
+		"not offered in one availability domain": {
+			shape:               "VM.Standard.E4.Flex",
+			availabilityDomains: []string{"AD-1", "AD-2"},
+			expectedProblem:     "shape VM.Standard.E4.Flex is not offered in availability domain(s) AD-2",
+		},
+		"not offered in any availability domain": {
+			shape:               "VM.Standard2.4",
+			availabilityDomains: []string{"AD-1", "AD-2"},
+			expectedProblem:     "shape VM.Standard2.4 is retired or not offered in availability domain(s) AD-1, AD-2",
+		},
+		"offered in the region": {
+			shape: "VM.Standard.E4.Flex",
+		},
+		"retired": {
+			shape:           "VM.Standard2.4",
+			expectedProblem: "shape VM.Standard2.4 is retired or not offered in this region",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			problem, err := CheckShapeAvailability(context.Background(), client, common.String("ocid1.compartment.oc1..aaaaaaaa1"), tc.shape, tc.availabilityDomains)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			got := ""
+			if problem != nil {
+				got = problem.Error()
+			}
+			if got != tc.expectedProblem {
+				t.Errorf("got %q ; wanted %q", got, tc.expectedProblem)
+			}
+		})
+	}
+
+	client.err = fmt.Errorf("service unavailable")
+	failingClient := &client.mockShapeClient
+	if _, err := CheckShapeAvailability(context.Background(), failingClient, nil, "VM.Standard2.4", nil); err == nil || !strings.Contains(err.Error(), "service unavailable") {
+		t.Errorf("got %v ; wanted the error listing the shapes", err)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/klog/v2"
+)
+
+// ShapeSnapshot holds the capacity and hourly prices of shapes, e.g. loaded from a file mounted from a ConfigMap, for
+// tenancies where the autoscaler cannot reach the endpoints that list shapes or publish prices.
+type ShapeSnapshot struct {
+	Shapes []SnapshotShape `json:"shapes"`
+}
+
+// SnapshotShape is the capacity and the hourly prices of a shape. OCI prices the OCPUs, memory and GPUs of a shape
+// separately, prices that are not set are not charged.
+type SnapshotShape struct {
+	Shape                string  `json:"shape"`
+	Ocpus                float32 `json:"ocpus,omitempty"`
+	MemoryInGBs          float32 `json:"memoryInGBs,omitempty"`
+	Gpus                 int     `json:"gpus,omitempty"`
+	PricePerOcpuHour     float64 `json:"pricePerOcpuHour,omitempty"`
+	PricePerMemoryGBHour float64 `json:"pricePerMemoryGBHour,omitempty"`
+	PricePerGpuHour      float64 `json:"pricePerGpuHour,omitempty"`
+}
+
+// LoadShapeSnapshot reads a shape snapshot from the specified JSON file.
+func LoadShapeSnapshot(path string) (*ShapeSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read shape snapshot: %v", err)
+	}
+	var snapshot ShapeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to parse shape snapshot %s: %v", path, err)
+	}
+	for i, shape := range snapshot.Shapes {
+		if shape.Shape == "" {
+			return nil, fmt.Errorf("shape %d of shape snapshot %s has no name", i, path)
+		}
+	}
+	return &snapshot, nil
+}
+
+// shape returns the snapshot of the specified shape.
+func (s *ShapeSnapshot) shape(name string) (SnapshotShape, bool) {
+	for _, shape := range s.Shapes {
+		if shape.Shape == name {
+			return shape, true
+		}
+	}
+	return SnapshotShape{}, false
+}
+
+// coreShapes returns the shapes of the snapshot the way ListShapes returns them.
+func (s *ShapeSnapshot) coreShapes() []core.Shape {
+	shapes := make([]core.Shape, 0, len(s.Shapes))
+	for _, shape := range s.Shapes {
+		shapes = append(shapes, core.Shape{
+			Shape:       common.String(shape.Shape),
+			Ocpus:       common.Float32(shape.Ocpus),
+			MemoryInGBs: common.Float32(shape.MemoryInGBs),
+			Gpus:        common.Int(shape.Gpus),
+		})
+	}
+	return shapes
+}
+
+// WrapShapeClient returns a ShapeClient that lists the shapes of the snapshot if prefer is set or if the shapes cannot
+// be listed with the specified client. It returns the client as it is if the snapshot is nil.
+func (s *ShapeSnapshot) WrapShapeClient(client ShapeClient, prefer bool) ShapeClient {
+	if s == nil {
+		return client
+	}
+	return &snapshotShapeClient{ShapeClient: client, snapshot: s, prefer: prefer}
+}
+
+// snapshotShapeClient lists the shapes of a snapshot instead of, or when it fails to, list them with its ShapeClient.
+type snapshotShapeClient struct {
+	ShapeClient
+	snapshot *ShapeSnapshot
+	prefer   bool
+}
+
+// ListShapes lists the shapes of the snapshot if they are preferred or if they cannot be listed otherwise.
+func (c *snapshotShapeClient) ListShapes(ctx context.Context, req core.ListShapesRequest) (core.ListShapesResponse, error) {
+	if !c.prefer {
+		resp, err := c.ShapeClient.ListShapes(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		klog.Warningf("unable to list shapes, using the shape snapshot instead: %v", err)
+	}
+	return core.ListShapesResponse{Items: c.snapshot.coreShapes()}, nil
+}
+
+// PricingModel returns a pricing model based on the prices of the snapshot, or nil if the snapshot is nil.
+// reportsVCPUs is set if the CPU capacity of template nodes is in vCPUs rather than OCPUs.
+func (s *ShapeSnapshot) PricingModel(reportsVCPUs bool) cloudprovider.PricingModel {
+	if s == nil {
+		return nil
+	}
+	return &snapshotPricingModel{snapshot: s, reportsVCPUs: reportsVCPUs}
+}
+
+// snapshotPricingModel prices nodes by the prices of their shape in a snapshot, and pods by the lowest prices of any
+// shape in the snapshot.
+type snapshotPricingModel struct {
+	snapshot     *ShapeSnapshot
+	reportsVCPUs bool
+}
+
+// NodePrice implements cloudprovider.PricingModel.
+func (p *snapshotPricingModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	shapeName := node.Labels[apiv1.LabelInstanceTypeStable]
+	if IsPodShape(shapeName) {
+		// Virtual nodes are free, their pods are billed by the resources they request, see PodPrice.
+		return 0, nil
+	}
+	shape, ok := p.snapshot.shape(shapeName)
+	if !ok {
+		return 0, fmt.Errorf("shape %q of node %s is not in the shape snapshot", shapeName, node.Name)
+	}
+
+	ocpus := float64(node.Status.Capacity.Cpu().MilliValue()) / 1000
+	if p.reportsVCPUs {
+		ocpus /= float64(ShapeThreadsPerCore(shapeName, nil))
+	}
+	memoryInGBs := float64(node.Status.Capacity.Memory().Value()) / (1024 * 1024 * 1024)
+	gpus := node.Status.Capacity[consts.ResourceGPU]
+
+	price := ocpus*shape.PricePerOcpuHour + memoryInGBs*shape.PricePerMemoryGBHour +
+		float64(gpus.Value())*shape.PricePerGpuHour
+	return price * endTime.Sub(startTime).Hours(), nil
+}
+
+// PodPrice implements cloudprovider.PricingModel. The requests of the pod are priced at the lowest price per vCPU,
+// per GB of memory and per GPU of any shape.
+func (p *snapshotPricingModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	pricePerVCPUHour, pricePerMemoryGBHour, pricePerGpuHour := math.MaxFloat64, math.MaxFloat64, math.MaxFloat64
+	for _, shape := range p.snapshot.Shapes {
+		if shape.PricePerOcpuHour > 0 {
+			pricePerVCPUHour = math.Min(pricePerVCPUHour, shape.PricePerOcpuHour/float64(ShapeThreadsPerCore(shape.Shape, nil)))
+		}
+		if shape.PricePerMemoryGBHour > 0 {
+			pricePerMemoryGBHour = math.Min(pricePerMemoryGBHour, shape.PricePerMemoryGBHour)
+		}
+		if shape.PricePerGpuHour > 0 {
+			pricePerGpuHour = math.Min(pricePerGpuHour, shape.PricePerGpuHour)
+		}
+	}
+
+	var vcpus, memoryInGBs, gpus float64
+	for _, container := range pod.Spec.Containers {
+		vcpus += float64(container.Resources.Requests.Cpu().MilliValue()) / 1000
+		memoryInGBs += float64(container.Resources.Requests.Memory().Value()) / (1024 * 1024 * 1024)
+		if request, ok := container.Resources.Requests[consts.ResourceGPU]; ok {
+			gpus += float64(request.Value())
+		}
+	}
+
+	var price float64
+	if vcpus > 0 && pricePerVCPUHour < math.MaxFloat64 {
+		price += vcpus * pricePerVCPUHour
+	}
+	if memoryInGBs > 0 && pricePerMemoryGBHour < math.MaxFloat64 {
+		price += memoryInGBs * pricePerMemoryGBHour
+	}
+	if gpus > 0 && pricePerGpuHour < math.MaxFloat64 {
+		price += gpus * pricePerGpuHour
+	}
+	return price * endTime.Sub(startTime).Hours(), nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const testShapeSnapshot = `{
+  "shapes": [
+    {"shape": "VM.Standard.E4.Flex", "pricePerOcpuHour": 0.025, "pricePerMemoryGBHour": 0.0015},
+    {"shape": "VM.Standard.A1.Flex", "pricePerOcpuHour": 0.01, "pricePerMemoryGBHour": 0.0015},
+    {"shape": "VM.GPU.A10.1", "ocpus": 15, "memoryInGBs": 240, "gpus": 1, "pricePerGpuHour": 2}
+  ]
+}`
+
+func writeShapeSnapshot(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "shapes.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadShapeSnapshot(t *testing.T) {
+	testCases := map[string]struct {
+		content     string
+		shapes      int
+		expectedErr bool
+	}{
+		"valid": {
+			content: testShapeSnapshot,
+			shapes:  3,
+		},
+		"invalid json": {
+			content:     `{"shapes": [`,
+			expectedErr: true,
+		},
+		"shape without name": {
+			content:     `{"shapes": [{"ocpus": 1}]}`,
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			snapshot, err := LoadShapeSnapshot(writeShapeSnapshot(t, tc.content))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("got error %v ; wanted error %v", err, tc.expectedErr)
+			}
+			if err == nil && len(snapshot.Shapes) != tc.shapes {
+				t.Errorf("got %d shapes ; wanted %d", len(snapshot.Shapes), tc.shapes)
+			}
+		})
+	}
+
+	if _, err := LoadShapeSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("got no error for a missing shape snapshot ; wanted an error")
+	}
+}
+
+func TestShapeSnapshotWrapShapeClient(t *testing.T) {
+	snapshot, err := LoadShapeSnapshot(writeShapeSnapshot(t, testShapeSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := core.ListShapesResponse{Items: []core.Shape{{Shape: common.String("VM.Standard2.1")}}}
+
+	testCases := map[string]struct {
+		prefer        bool
+		err           error
+		expectedShape string
+	}{
+		"live shapes": {
+			expectedShape: "VM.Standard2.1",
+		},
+		"snapshot preferred": {
+			prefer:        true,
+			expectedShape: "VM.Standard.E4.Flex",
+		},
+		"live call failed": {
+			err:           errors.New("dial tcp: i/o timeout"),
+			expectedShape: "VM.Standard.E4.Flex",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := snapshot.WrapShapeClient(&mockShapeClient{err: tc.err, listShapeResp: live}, tc.prefer)
+			resp, err := client.ListShapes(context.Background(), core.ListShapesRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := *resp.Items[0].Shape; got != tc.expectedShape {
+				t.Errorf("got %v ; wanted %v", got, tc.expectedShape)
+			}
+		})
+	}
+
+	var none *ShapeSnapshot
+	mock := &mockShapeClient{}
+	if client := none.WrapShapeClient(mock, true); client != mock {
+		t.Errorf("got a wrapped client for a nil shape snapshot ; wanted the client as it is")
+	}
+}
+
+func TestShapeSnapshotPricingModel(t *testing.T) {
+	snapshot, err := LoadShapeSnapshot(writeShapeSnapshot(t, testShapeSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	end := start.Add(2 * time.Hour)
+
+	node := func(shape, cpu, memory, gpu string) *apiv1.Node {
+		return &apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node",
+				Labels: map[string]string{apiv1.LabelInstanceTypeStable: shape},
+			},
+			Status: apiv1.NodeStatus{Capacity: apiv1.ResourceList{
+				apiv1.ResourceCPU:    resource.MustParse(cpu),
+				apiv1.ResourceMemory: resource.MustParse(memory),
+				"nvidia.com/gpu":     resource.MustParse(gpu),
+			}},
+		}
+	}
+
+	testCases := map[string]struct {
+		reportsVCPUs  bool
+		node          *apiv1.Node
+		expectedPrice float64
+		expectedErr   bool
+	}{
+		"ocpus": {
+			node:          node("VM.Standard.E4.Flex", "2", "32Gi", "0"),
+			expectedPrice: (2*0.025 + 32*0.0015) * 2,
+		},
+		"vcpus": {
+			reportsVCPUs:  true,
+			node:          node("VM.Standard.E4.Flex", "4", "32Gi", "0"),
+			expectedPrice: (2*0.025 + 32*0.0015) * 2,
+		},
+		"vcpus of an arm shape": {
+			reportsVCPUs:  true,
+			node:          node("VM.Standard.A1.Flex", "4", "24Gi", "0"),
+			expectedPrice: (4*0.01 + 24*0.0015) * 2,
+		},
+		"gpus": {
+			node:          node("VM.GPU.A10.1", "15", "240Gi", "1"),
+			expectedPrice: 2 * 2,
+		},
+		"virtual node": {
+			reportsVCPUs:  true,
+			node:          node("Pod.Standard.E4.Flex", "1000", "4Ti", "0"),
+			expectedPrice: 0,
+		},
+		"unknown shape": {
+			node:        node("VM.Standard2.1", "1", "15Gi", "0"),
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			price, err := snapshot.PricingModel(tc.reportsVCPUs).NodePrice(tc.node, start, end)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("got error %v ; wanted error %v", err, tc.expectedErr)
+			}
+			if math.Abs(price-tc.expectedPrice) > 1e-9 {
+				t.Errorf("got %v ; wanted %v", price, tc.expectedPrice)
+			}
+		})
+	}
+
+	pod := &apiv1.Pod{Spec: apiv1.PodSpec{Containers: []apiv1.Container{{
+		Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse("500m"),
+			apiv1.ResourceMemory: resource.MustParse("2Gi"),
+		}},
+	}}}}
+	price, err := snapshot.PricingModel(true).PodPrice(pod, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cheapest vCPU is an Arm OCPU, the cheapest GB of memory is the same for every shape.
+	if expectedPrice := (0.5*0.01 + 2*0.0015) * 2; math.Abs(price-expectedPrice) > 1e-9 {
+		t.Errorf("got %v ; wanted %v", price, expectedPrice)
+	}
+
+	var none *ShapeSnapshot
+	if none.PricingModel(true) != nil {
+		t.Errorf("got a pricing model for a nil shape snapshot ; wanted nil")
+	}
+}
@@ -19,6 +19,7 @@ type mockShapeClient struct {
 	err                   error
 	listShapeResp         core.ListShapesResponse
 	getInstanceConfigResp core.GetInstanceConfigurationResponse
+	getImageResp          core.GetImageResponse
 }
 
 func (m *mockShapeClient) ListShapes(_ context.Context, _ core.ListShapesRequest) (core.ListShapesResponse, error) {
@@ -29,6 +30,10 @@ func (m *mockShapeClient) GetInstanceConfiguration(context.Context, core.GetInst
 	return m.getInstanceConfigResp, m.err
 }
 
+func (m *mockShapeClient) GetImage(context.Context, core.GetImageRequest) (core.GetImageResponse, error) {
+	return m.getImageResp, m.err
+}
+
 var launchDetails = core.InstanceConfigurationLaunchInstanceDetails{
 	CompartmentId:     nil,
 	DisplayName:       nil,
@@ -95,7 +100,9 @@ func TestNodePoolGetShape(t *testing.T) {
 		"basic shape": {
 			shape: "VM.Standard1.2",
 			expected: &Shape{
+				Name:                    "VM.Standard1.2",
 				CPU:                     4,
+				ThreadsPerCore:          2,
 				MemoryInBytes:           16 * 1024 * 1024 * 1024,
 				GPU:                     0,
 				EphemeralStorageInBytes: -1,
@@ -108,16 +115,33 @@ func TestNodePoolGetShape(t *testing.T) {
 				MemoryInGBs: common.Float32(64),
 			},
 			expected: &Shape{
+				Name:                    "VM.Standard.E3.Flex",
 				CPU:                     8,
+				ThreadsPerCore:          2,
 				MemoryInBytes:           4 * 16 * 1024 * 1024 * 1024,
 				GPU:                     0,
 				EphemeralStorageInBytes: -1,
 			},
 		},
+		"extended memory flex shape": {
+			shape: "VM.Standard.E4.Flex",
+			shapeConfig: &oke.NodeShapeConfig{
+				Ocpus:       common.Float32(2),
+				MemoryInGBs: common.Float32(256),
+			},
+			expected: &Shape{
+				Name:                    "VM.Standard.E4.Flex",
+				CPU:                     4,
+				ThreadsPerCore:          2,
+				MemoryInBytes:           256 * 1024 * 1024 * 1024,
+				GPU:                     0,
+				EphemeralStorageInBytes: -1,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
-		shapeGetter := CreateShapeGetter(shapeClient)
+		shapeGetter := CreateShapeGetter(shapeClient, nil)
 
 		t.Run(name, func(t *testing.T) {
 			shape, err := shapeGetter.GetNodePoolShape(&oke.NodePool{NodeShape: &tc.shape, NodeShapeConfig: tc.shapeConfig}, -1)
@@ -154,16 +178,17 @@ func TestGetInstancePoolShape(t *testing.T) {
 		"flex shape": {
 			shape: "VM.Standard.E3.Flex",
 			expected: &Shape{
-				Name:          "VM.Standard.E3.Flex",
-				CPU:           8,
-				MemoryInBytes: float32(128) * 1024 * 1024 * 1024,
-				GPU:           0,
+				Name:           "VM.Standard.E3.Flex",
+				CPU:            8,
+				ThreadsPerCore: 2,
+				MemoryInBytes:  float32(128) * 1024 * 1024 * 1024,
+				GPU:            0,
 			},
 		},
 	}
 
 	for name, tc := range testCases {
-		shapeGetter := CreateShapeGetter(shapeClient)
+		shapeGetter := CreateShapeGetter(shapeClient, nil)
 
 		t.Run(name, func(t *testing.T) {
 			shape, err := shapeGetter.GetInstancePoolShape(&core.InstancePool{Id: &tc.shape, InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1")})
@@ -190,3 +215,319 @@ func TestGetInstancePoolShape(t *testing.T) {
 		})
 	}
 }
+
+func TestGetInstancePoolShapeBootVolume(t *testing.T) {
+	launchDetails := launchDetails
+	launchDetails.SourceDetails = core.InstanceConfigurationInstanceSourceViaImageDetails{
+		ImageId:             common.String("ocid1.image.oc1.phx.aaaaaaaa1"),
+		BootVolumeSizeInGBs: common.Int64(100),
+		BootVolumeVpusPerGB: common.Int64(20),
+	}
+	shapeClient := &mockShapeClient{
+		getInstanceConfigResp: core.GetInstanceConfigurationResponse{
+			InstanceConfiguration: core.InstanceConfiguration{
+				Id:              common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+				InstanceDetails: core.ComputeInstanceDetails{LaunchDetails: &launchDetails},
+			},
+		},
+	}
+
+	shape, err := CreateShapeGetter(shapeClient, nil).GetInstancePoolShape(&core.InstancePool{
+		Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
+		InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 90% of the 100GB boot volume.
+	expectedEphemeralStorage := float32(96636764160)
+	if shape.EphemeralStorageInBytes != expectedEphemeralStorage {
+		t.Errorf("got ephemeral storage %f ; wanted %f", shape.EphemeralStorageInBytes, expectedEphemeralStorage)
+	}
+}
+
+func TestGetInstancePoolShapeDefaultBootVolume(t *testing.T) {
+	testCases := map[string]core.InstanceConfigurationInstanceSourceDetails{
+		"image without boot volume size": core.InstanceConfigurationInstanceSourceViaImageDetails{
+			ImageId: common.String("ocid1.image.oc1.phx.aaaaaaaa1"),
+		},
+		"existing boot volume": core.InstanceConfigurationInstanceSourceViaBootVolumeDetails{
+			BootVolumeId: common.String("ocid1.bootvolume.oc1.phx.aaaaaaaa1"),
+		},
+	}
+
+	for name, sourceDetails := range testCases {
+		t.Run(name, func(t *testing.T) {
+			launchDetails := launchDetails
+			launchDetails.SourceDetails = sourceDetails
+			shapeClient := &mockShapeClient{
+				getInstanceConfigResp: core.GetInstanceConfigurationResponse{
+					InstanceConfiguration: core.InstanceConfiguration{
+						Id:              common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+						InstanceDetails: core.ComputeInstanceDetails{LaunchDetails: &launchDetails},
+					},
+				},
+			}
+
+			shape, err := CreateShapeGetter(shapeClient, nil).GetInstancePoolShape(&core.InstancePool{
+				Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
+				InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedEphemeralStorage := float32(bootVolumeEphemeralStorageInBytes(defaultBootVolumeSizeInGBs))
+			if shape.EphemeralStorageInBytes != expectedEphemeralStorage {
+				t.Errorf("got ephemeral storage %f ; wanted %f", shape.EphemeralStorageInBytes, expectedEphemeralStorage)
+			}
+		})
+	}
+}
+
+func TestNodePoolGetShapeDefaultMemory(t *testing.T) {
+	testCases := map[string]struct {
+		shape                 string
+		memoryPerOcpu         map[string]float32
+		expectedMemoryInBytes float32
+	}{
+		"known shape family": {
+			shape:                 "VM.Standard.E4.Flex",
+			expectedMemoryInBytes: 2 * 16 * 1024 * 1024 * 1024,
+		},
+		"unknown shape family": {
+			shape:                 "VM.Unknown.Flex",
+			expectedMemoryInBytes: 2 * 1024 * 1024 * 1024,
+		},
+		"configured shape family": {
+			shape:                 "VM.Standard.E4.Flex",
+			memoryPerOcpu:         map[string]float32{"VM.Standard.E4.Flex": 8},
+			expectedMemoryInBytes: 2 * 8 * 1024 * 1024 * 1024,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			shapeGetter := CreateShapeGetter(&mockShapeClient{}, tc.memoryPerOcpu)
+			shape, err := shapeGetter.GetNodePoolShape(&oke.NodePool{
+				NodeShape:       common.String(tc.shape),
+				NodeShapeConfig: &oke.NodeShapeConfig{Ocpus: common.Float32(2)},
+			}, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shape.MemoryInBytes != tc.expectedMemoryInBytes {
+				t.Errorf("got memory %f ; wanted %f", shape.MemoryInBytes, tc.expectedMemoryInBytes)
+			}
+		})
+	}
+}
+
+func TestParseFlexShapeMemoryPerOcpu(t *testing.T) {
+	memoryPerOcpu, err := ParseFlexShapeMemoryPerOcpu("VM.Standard.E4.Flex=8, VM.Custom.Flex=4")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := memoryPerOcpu["VM.Standard.E4.Flex"]; got != 8 {
+		t.Errorf("got %f ; wanted 8", got)
+	}
+	if got := memoryPerOcpu["VM.Custom.Flex"]; got != 4 {
+		t.Errorf("got %f ; wanted 4", got)
+	}
+	if got := memoryPerOcpu["VM.Standard.A1.Flex"]; got != DefaultFlexShapeMemoryPerOcpuInGBs["VM.Standard.A1.Flex"] {
+		t.Errorf("got %f ; wanted default %f", got, DefaultFlexShapeMemoryPerOcpuInGBs["VM.Standard.A1.Flex"])
+	}
+
+	for _, value := range []string{"VM.Standard.E4.Flex", "VM.Standard.E4.Flex=abc", "VM.Standard.E4.Flex=0"} {
+		if _, err := ParseFlexShapeMemoryPerOcpu(value); err == nil {
+			t.Errorf("expected error for %q but got nil", value)
+		}
+	}
+}
+
+func TestShapeThreadsPerCore(t *testing.T) {
+	testCases := map[string]struct {
+		shape          string
+		platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig
+		expected       int
+	}{
+		"x86 VM shape": {
+			shape:    "VM.Standard.E4.Flex",
+			expected: 2,
+		},
+		"Arm VM shape": {
+			shape:    "VM.Standard.A1.Flex",
+			expected: 1,
+		},
+		"Arm BM shape": {
+			shape:    "BM.Standard.A1.160",
+			expected: 1,
+		},
+		"BM shape with SMT enabled": {
+			shape: "BM.Standard.E4.128",
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{
+				IsSymmetricMultiThreadingEnabled: common.Bool(true),
+			},
+			expected: 2,
+		},
+		"BM shape with SMT disabled": {
+			shape: "BM.Standard.E4.128",
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{
+				IsSymmetricMultiThreadingEnabled: common.Bool(false),
+			},
+			expected: 1,
+		},
+		"BM shape with SMT disabled on Intel": {
+			shape: "BM.Standard3.64",
+			platformConfig: core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig{
+				IsSymmetricMultiThreadingEnabled: common.Bool(false),
+			},
+			expected: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := ShapeThreadsPerCore(tc.shape, tc.platformConfig); got != tc.expected {
+				t.Errorf("got %d ; wanted %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEnabledCores(t *testing.T) {
+	testCases := map[string]struct {
+		ocpus          float32
+		platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig
+		expected       float32
+	}{
+		"no platform config": {
+			ocpus:    128,
+			expected: 128,
+		},
+		"all cores enabled": {
+			ocpus: 128,
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{
+				PercentageOfCoresEnabled: common.Int(100),
+			},
+			expected: 128,
+		},
+		"half of the cores enabled": {
+			ocpus: 128,
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{
+				PercentageOfCoresEnabled: common.Int(50),
+			},
+			expected: 64,
+		},
+		"partial cores are not enabled": {
+			ocpus: 64,
+			platformConfig: core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig{
+				PercentageOfCoresEnabled: common.Int(33),
+			},
+			expected: 21,
+		},
+		"at least one core is enabled": {
+			ocpus: 64,
+			platformConfig: core.InstanceConfigurationAmdRomeBmLaunchInstancePlatformConfig{
+				PercentageOfCoresEnabled: common.Int(1),
+			},
+			expected: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := EnabledCores(tc.ocpus, tc.platformConfig); got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestShapeNumaNodesPerSocket(t *testing.T) {
+	testCases := map[string]struct {
+		platformConfig core.InstanceConfigurationLaunchInstancePlatformConfig
+		expected       string
+	}{
+		"no platform config": {
+			expected: "",
+		},
+		"not set": {
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{},
+			expected:       "",
+		},
+		"AMD BM shape": {
+			platformConfig: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfig{
+				NumaNodesPerSocket: core.InstanceConfigurationAmdMilanBmLaunchInstancePlatformConfigNumaNodesPerSocketNps4,
+			},
+			expected: "NPS4",
+		},
+		"Intel BM shape": {
+			platformConfig: core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfig{
+				NumaNodesPerSocket: core.InstanceConfigurationIntelIcelakeBmLaunchInstancePlatformConfigNumaNodesPerSocketNps2,
+			},
+			expected: "NPS2",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := ShapeNumaNodesPerSocket(tc.platformConfig); got != tc.expected {
+				t.Errorf("got %q ; wanted %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetInstancePoolShapeOperatingSystem(t *testing.T) {
+	testCases := map[string]struct {
+		operatingSystem string
+		expectedWindows bool
+	}{
+		"oracle linux": {
+			operatingSystem: "Oracle Linux",
+		},
+		"windows": {
+			operatingSystem: "Windows",
+			expectedWindows: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			launchDetails := launchDetails
+			launchDetails.SourceDetails = core.InstanceConfigurationInstanceSourceViaImageDetails{
+				ImageId: common.String("ocid1.image.oc1.phx.aaaaaaaa1"),
+			}
+			shapeClient := &mockShapeClient{
+				getInstanceConfigResp: core.GetInstanceConfigurationResponse{
+					InstanceConfiguration: core.InstanceConfiguration{
+						Id:              common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+						InstanceDetails: core.ComputeInstanceDetails{LaunchDetails: &launchDetails},
+					},
+				},
+				getImageResp: core.GetImageResponse{
+					Image: core.Image{
+						Id:              common.String("ocid1.image.oc1.phx.aaaaaaaa1"),
+						OperatingSystem: common.String(tc.operatingSystem),
+					},
+				},
+			}
+
+			shape, err := CreateShapeGetter(shapeClient, nil).GetInstancePoolShape(&core.InstancePool{
+				Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
+				InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shape.OperatingSystem != tc.operatingSystem {
+				t.Errorf("got operating system %s ; wanted %s", shape.OperatingSystem, tc.operatingSystem)
+			}
+			if shape.IsWindows() != tc.expectedWindows {
+				t.Errorf("got windows %v ; wanted %v", shape.IsWindows(), tc.expectedWindows)
+			}
+		})
+	}
+}
@@ -8,6 +8,43 @@ import (
 	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
 )
 
+const (
+	// ManagedByTag is the freeform tag key identifying what manages the instances launched by the autoscaler.
+	ManagedByTag = "managed-by"
+	// ManagedByTagValue is the value of the ManagedByTag freeform tag of the instances launched by the autoscaler.
+	ManagedByTagValue = "cluster-autoscaler"
+	// ClusterNameTag is the freeform tag key holding the name of the cluster of the instances launched by the autoscaler.
+	ClusterNameTag = "cluster-autoscaler/cluster-name"
+	// NodeGroupTag is the freeform tag key holding the OCID of the node group of the instances launched by the autoscaler.
+	NodeGroupTag = "cluster-autoscaler/node-group"
+)
+
+// WithLaunchedInstanceTags returns the specified freeform tags with the tags identifying instances launched by the
+// autoscaler for the specified cluster and node group added, and whether any tag was added or changed. The specified
+// freeform tags are not modified. The cluster name tag is omitted if the cluster name is empty.
+func WithLaunchedInstanceTags(freeformTags map[string]string, clusterName, nodeGroupID string) (map[string]string, bool) {
+	launchedInstanceTags := map[string]string{
+		ManagedByTag: ManagedByTagValue,
+		NodeGroupTag: nodeGroupID,
+	}
+	if clusterName != "" {
+		launchedInstanceTags[ClusterNameTag] = clusterName
+	}
+
+	result := make(map[string]string, len(freeformTags)+len(launchedInstanceTags))
+	for key, value := range freeformTags {
+		result[key] = value
+	}
+	changed := false
+	for key, value := range launchedInstanceTags {
+		if result[key] != value {
+			result[key] = value
+			changed = true
+		}
+	}
+	return result, changed
+}
+
 // TagsGetter returns the oci tags for the pool.
 type TagsGetter interface {
 	GetNodePoolFreeformTags(*oke.NodePool) (map[string]string, error)
@@ -0,0 +1,65 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithLaunchedInstanceTags(t *testing.T) {
+	const nodeGroupID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+
+	testCases := map[string]struct {
+		freeformTags    map[string]string
+		clusterName     string
+		expected        map[string]string
+		expectedChanged bool
+	}{
+		"untagged": {
+			freeformTags: map[string]string{"team": "ml"},
+			clusterName:  "cluster-1",
+			expected: map[string]string{
+				"team":         "ml",
+				ManagedByTag:   ManagedByTagValue,
+				ClusterNameTag: "cluster-1",
+				NodeGroupTag:   nodeGroupID,
+			},
+			expectedChanged: true,
+		},
+		"already tagged": {
+			freeformTags: map[string]string{
+				ManagedByTag:   ManagedByTagValue,
+				ClusterNameTag: "cluster-1",
+				NodeGroupTag:   nodeGroupID,
+			},
+			clusterName: "cluster-1",
+			expected: map[string]string{
+				ManagedByTag:   ManagedByTagValue,
+				ClusterNameTag: "cluster-1",
+				NodeGroupTag:   nodeGroupID,
+			},
+		},
+		"no cluster name": {
+			expected: map[string]string{
+				ManagedByTag: ManagedByTagValue,
+				NodeGroupTag: nodeGroupID,
+			},
+			expectedChanged: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, changed := WithLaunchedInstanceTags(tc.freeformTags, tc.clusterName, nodeGroupID)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+			if changed != tc.expectedChanged {
+				t.Errorf("got changed %v ; wanted %v", changed, tc.expectedChanged)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ Copyright 2021-2023 Oracle and/or its affiliates.
 package common
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -155,3 +157,55 @@ func BuildGenericLabels(ocid string, nodeName, shape, availabilityDomain string)
 
 	return result
 }
+
+const (
+	// WindowsOS is the value of the OS labels of Windows nodes.
+	WindowsOS = "windows"
+	// WindowsTaintKey is the key of the NoSchedule taint of Windows template nodes, which keeps pods that do not
+	// tolerate it from triggering scale-ups of Windows node groups.
+	WindowsTaintKey = "os"
+)
+
+// SetWindowsOS makes the template node a Windows node by setting its OS labels and tainting it with WindowsTaintKey.
+func SetWindowsOS(node *apiv1.Node) {
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	node.Labels[kubeletapis.LabelOS] = WindowsOS
+	node.Labels[apiv1.LabelOSStable] = WindowsOS
+	node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
+		Key:    WindowsTaintKey,
+		Value:  WindowsOS,
+		Effect: apiv1.TaintEffectNoSchedule,
+	})
+}
+
+// IsWindowsNode returns true if the node is labeled as a Windows node.
+func IsWindowsNode(node *apiv1.Node) bool {
+	return node.Labels[apiv1.LabelOSStable] == WindowsOS
+}
+
+const (
+	// LaunchConfigHashLabel is the label holding a digest of the image and user data instances of a node group are
+	// launched with, so that node groups with the same shape but different images or bootstrap scripts are not
+	// considered similar.
+	LaunchConfigHashLabel = "oci.oraclecloud.com/launch-config-hash"
+	// NumaNodesPerSocketLabel is the label holding the NUMA nodes per socket setting, e.g. "NPS2", of the bare metal
+	// instances of a node group, so that workloads sensitive to the topology manager can select node groups by it.
+	NumaNodesPerSocketLabel = "oci.oraclecloud.com/numa-nodes-per-socket"
+	// userDataMetadataKey is the instance metadata key of the base64 encoded user data (cloud-init script).
+	userDataMetadataKey = "user_data"
+	// launchConfigHashLength is the number of hex digits of the digest kept in LaunchConfigHashLabel.
+	launchConfigHashLength = 16
+)
+
+// LaunchConfigHash returns a digest of the image and the user data of the instance metadata, or an empty string if
+// neither is known.
+func LaunchConfigHash(imageID string, metadata map[string]string) string {
+	userData := metadata[userDataMetadataKey]
+	if imageID == "" && userData == "" {
+		return ""
+	}
+	digest := sha256.Sum256([]byte(imageID + "\x00" + userData))
+	return hex.EncodeToString(digest[:])[:launchConfigHashLength]
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package common
+
+import (
+	"testing"
+)
+
+func TestLaunchConfigHash(t *testing.T) {
+	base := LaunchConfigHash("ocid1.image.oc1.phx.aaaaaaaa1", map[string]string{"user_data": "Zm9v"})
+	if len(base) != launchConfigHashLength {
+		t.Errorf("got hash %q of length %d ; wanted length %d", base, len(base), launchConfigHashLength)
+	}
+
+	testCases := map[string]struct {
+		imageID       string
+		metadata      map[string]string
+		expectedEqual bool
+	}{
+		"same image and user data": {
+			imageID:       "ocid1.image.oc1.phx.aaaaaaaa1",
+			metadata:      map[string]string{"user_data": "Zm9v", "ssh_authorized_keys": "ssh-rsa AAAA"},
+			expectedEqual: true,
+		},
+		"different image": {
+			imageID:  "ocid1.image.oc1.phx.aaaaaaaa2",
+			metadata: map[string]string{"user_data": "Zm9v"},
+		},
+		"different user data": {
+			imageID:  "ocid1.image.oc1.phx.aaaaaaaa1",
+			metadata: map[string]string{"user_data": "YmFy"},
+		},
+		"no user data": {
+			imageID: "ocid1.image.oc1.phx.aaaaaaaa1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := LaunchConfigHash(tc.imageID, tc.metadata)
+			if (got == base) != tc.expectedEqual {
+				t.Errorf("got hash %q, base hash %q ; wanted equal %v", got, base, tc.expectedEqual)
+			}
+		})
+	}
+
+	if got := LaunchConfigHash("", nil); got != "" {
+		t.Errorf("got %q ; wanted empty hash", got)
+	}
+}
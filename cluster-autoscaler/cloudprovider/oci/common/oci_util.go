@@ -18,6 +18,7 @@ import (
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	npconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
 )
 
@@ -95,7 +96,8 @@ func AnnotateNode(kubeClient kubernetes.Interface, nodeName string, key string,
 	}
 
 	if v := annotations[key]; v != value {
-		node.Annotations[key] = value
+		annotations[key] = value
+		node.SetAnnotations(annotations)
 		_, err := kubeClient.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Errorf("failed to annotate node %s %+v", nodeName, err)
@@ -128,7 +130,8 @@ func LabelNode(kubeClient kubernetes.Interface, nodeName string, key string, val
 	}
 
 	if v := labels[key]; v != value {
-		node.Labels[key] = value
+		labels[key] = value
+		node.SetLabels(labels)
 		_, err := kubeClient.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Errorf("failed to label node %s %+v", nodeName, err)
@@ -186,8 +189,13 @@ func GetAllPoolTypes(groups []string) (string, error) {
 	for _, group := range groups {
 		ocidParts := strings.Split(group, ".")
 		if len(ocidParts) >= 2 {
+			ocidType := ocidParts[1]
+			// virtual node pools are managed along with node pools
+			if ocidType == npconsts.OciVirtualNodePoolResourceIdent {
+				ocidType = npconsts.OciNodePoolResourceIdent
+			}
 			// we just need to populate the map key
-			ocidTypes[ocidParts[1]] = nil
+			ocidTypes[ocidType] = nil
 		} else {
 			return "", fmt.Errorf("unsupported ocid value. Could not determine ocid type of %s", group)
 		}
@@ -14,6 +14,8 @@ const (
 	OciUseWorkloadIdentityEnvVar = "OCI_USE_WORKLOAD_IDENTITY"
 	// OciUseInstancePrincipalEnvVar is an env var that indicates whether to use an instance principal
 	OciUseInstancePrincipalEnvVar = "OCI_USE_INSTANCE_PRINCIPAL"
+	// OciUseFakeClientsEnvVar is an env var that indicates whether to use in-memory fake OCI clients instead of a tenancy
+	OciUseFakeClientsEnvVar = "OCI_USE_FAKE_CLIENTS"
 	// OciUseNonPoolMemberAnnotationEnvVar is an env var indicating that non-members of instance pools will get a special annotation
 	OciUseNonPoolMemberAnnotationEnvVar = "OCI_USE_NON_POOL_MEMBER_ANNOTATION"
 	// OciCompartmentEnvVar indicates to only use instance pools in this specific compartment
@@ -24,8 +26,12 @@ const (
 	OciRefreshInterval = "OCI_REFRESH_INTERVAL"
 	// DefaultRefreshInterval is the default rate to refresh the cache
 	DefaultRefreshInterval = 5 * time.Minute
+	// DefaultFullRefreshInterval is the default rate to list the instances of instance pools that did not change
+	DefaultFullRefreshInterval = 30 * time.Minute
 	// ResourceGPU is the GPU resource type
 	ResourceGPU v1.ResourceName = "nvidia.com/gpu"
+	// GPUProductLabel is the label describing the GPU model of the node, as set by the NVIDIA GPU feature discovery
+	GPUProductLabel = "nvidia.com/gpu.product"
 
 	// OciAnnotationCompartmentID the well known annotation string for compartment ids
 	OciAnnotationCompartmentID = "oci.oraclecloud.com/compartment-id"
@@ -44,6 +50,8 @@ const (
 
 	// OciInstancePoolResourceIdent resource identifier in the ocid
 	OciInstancePoolResourceIdent = "instancepool"
+	// OciInstanceConfigurationResourceIdent resource identifier in the ocid of an instance configuration
+	OciInstanceConfigurationResourceIdent = "instanceconfiguration"
 	// OciInstancePoolLaunchOp is an instance pools operation type
 	OciInstancePoolLaunchOp = "LaunchInstancesInPool"
 	// InstanceStateUnfulfilled is a status indicating that the instance pool was unable to fulfill the operation
@@ -51,6 +59,31 @@ const (
 	// InstanceIDUnfulfilled is the generic placeholder name for upcoming instances
 	InstanceIDUnfulfilled = "instance_placeholder"
 
+	// ServiceLimitNameTag is the freeform tag key that overrides the name of the compute service limit of the instance pool's shape
+	ServiceLimitNameTag = "cluster-autoscaler/service-limit-name"
+
+	// PlacementPolicyTag is the freeform tag key that selects how an instance pool with several placement configurations
+	// places the instances it scales up by, either PlacementPolicySpread or PlacementPolicyPacked
+	PlacementPolicyTag = "cluster-autoscaler/placement-policy"
+	// PlacementPolicySpread spreads instances evenly across the availability domains of the instance pool, like OCI does
+	PlacementPolicySpread = "spread"
+	// PlacementPolicyPacked places instances in the first availability domain of the instance pool that is not backed off,
+	// so the next one is only used once the first one runs out of capacity
+	PlacementPolicyPacked = "packed"
+
+	// FallbackInstancePoolTag is the freeform tag key of a preemptible instance pool whose value is the ID of the
+	// on-demand instance pool it falls back to. The preemptible instance pool is reported unavailable for a while when
+	// it runs out of capacity or its instances are preempted in a storm, so the fallback is scaled up instead.
+	FallbackInstancePoolTag = "cluster-autoscaler/fallback-instance-pool"
+
+	// AutoprovisionedFromTag is the freeform tag key set on autoprovisioned instance pools. Its value is the ID of the instance
+	// configuration the instance pool was created from.
+	AutoprovisionedFromTag = "cluster-autoscaler/autoprovisioned-from"
+	// AutoprovisionedInstancePoolIdent is the resource identifier of autoprovisioned instance pools that do not exist yet
+	AutoprovisionedInstancePoolIdent = "autoprovisioned"
+	// AutoprovisionedInstancePoolMaxSize is the maximum size of autoprovisioned instance pools
+	AutoprovisionedInstancePoolMaxSize = 1000
+
 	// OciInstancePoolIDNonPoolMember indicates a kubernetes node doesn't belong to any OCI Instance Pool.
 	OciInstancePoolIDNonPoolMember = "non_pool_member"
 )
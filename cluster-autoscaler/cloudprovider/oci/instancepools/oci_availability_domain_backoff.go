@@ -0,0 +1,184 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	// availabilityDomainInitialBackoff is how long an availability domain is not used by an instance pool after it ran
+	// out of capacity for the first time.
+	availabilityDomainInitialBackoff = 5 * time.Minute
+	// availabilityDomainMaxBackoff caps the backoff of availability domains that repeatedly run out of capacity.
+	availabilityDomainMaxBackoff = time.Hour
+	// availabilityDomainBackoffReset is how long after its backoff expired an availability domain starts again from
+	// availabilityDomainInitialBackoff.
+	availabilityDomainBackoffReset = 3 * time.Hour
+)
+
+type availabilityDomainBackoffInfo struct {
+	duration     time.Duration
+	backoffUntil time.Time
+}
+
+// availabilityDomainBackoff remembers the availability domains that ran out of capacity for an instance configuration,
+// backing them off exponentially like the core autoscaler backs off node groups. It also remembers the placement
+// configurations instance pools had before they were restricted to the availability domains that are not backed off.
+type availabilityDomainBackoff struct {
+	mu sync.Mutex
+	// backoffInfo is keyed by instance configuration and availability domain, as capacity depends on the shape.
+	backoffInfo map[string]availabilityDomainBackoffInfo
+	// placements holds the placement configurations of restricted instance pools.
+	placements map[string][]core.InstancePoolPlacementConfiguration
+}
+
+func newAvailabilityDomainBackoff() *availabilityDomainBackoff {
+	return &availabilityDomainBackoff{
+		backoffInfo: map[string]availabilityDomainBackoffInfo{},
+		placements:  map[string][]core.InstancePoolPlacementConfiguration{},
+	}
+}
+
+func availabilityDomainBackoffKey(instanceConfigurationID, availabilityDomain string) string {
+	return instanceConfigurationID + "/" + availabilityDomain
+}
+
+// backoff backs off the availability domain for the instance configuration.
+func (b *availabilityDomainBackoff) backoff(instanceConfigurationID, availabilityDomain string, now time.Time) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := availabilityDomainBackoffKey(instanceConfigurationID, availabilityDomain)
+	duration := availabilityDomainInitialBackoff
+	if info, found := b.backoffInfo[key]; found && info.backoffUntil.Add(availabilityDomainBackoffReset).After(now) {
+		duration = info.duration
+		if info.backoffUntil.Before(now) {
+			// Ran out of capacity again soon after the last backoff expired.
+			duration = 2 * info.duration
+			if duration > availabilityDomainMaxBackoff {
+				duration = availabilityDomainMaxBackoff
+			}
+		}
+	}
+	backoffUntil := now.Add(duration)
+	b.backoffInfo[key] = availabilityDomainBackoffInfo{duration: duration, backoffUntil: backoffUntil}
+	return backoffUntil
+}
+
+// isBackedOff returns true if the availability domain is backed off for the instance configuration.
+func (b *availabilityDomainBackoff) isBackedOff(instanceConfigurationID, availabilityDomain string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info, found := b.backoffInfo[availabilityDomainBackoffKey(instanceConfigurationID, availabilityDomain)]
+	return found && info.backoffUntil.After(now)
+}
+
+// originalPlacements returns the placement configurations the instance pool had before it was restricted, or its
+// current ones if it is not restricted.
+func (b *availabilityDomainBackoff) originalPlacements(instancePool *core.InstancePool) []core.InstancePoolPlacementConfiguration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if placements, restricted := b.placements[*instancePool.Id]; restricted {
+		return placements
+	}
+	return instancePool.PlacementConfigurations
+}
+
+// availablePlacements returns the original placement configurations of the instance pool whose availability domains
+// are not backed off for its instance configuration.
+func (b *availabilityDomainBackoff) availablePlacements(instancePool *core.InstancePool, now time.Time) []core.InstancePoolPlacementConfiguration {
+	var available []core.InstancePoolPlacementConfiguration
+	for _, placement := range b.originalPlacements(instancePool) {
+		if placement.AvailabilityDomain != nil && !b.isBackedOff(*instancePool.InstanceConfigurationId, *placement.AvailabilityDomain, now) {
+			available = append(available, placement)
+		}
+	}
+	return available
+}
+
+// placementPolicy returns the placement policy of the instance pool, see consts.PlacementPolicyTag.
+func placementPolicy(instancePool *core.InstancePool) (string, error) {
+	policy, ok := instancePool.FreeformTags[consts.PlacementPolicyTag]
+	if !ok || policy == "" {
+		return consts.PlacementPolicySpread, nil
+	}
+	switch policy {
+	case consts.PlacementPolicySpread, consts.PlacementPolicyPacked:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid %s freeform tag %q: expected %s or %s", consts.PlacementPolicyTag, policy,
+		consts.PlacementPolicySpread, consts.PlacementPolicyPacked)
+}
+
+// placementsForPolicy returns the available placement configurations the instance pool scales up in according to its
+// placement policy, i.e. the first of them if it is packed and all of them otherwise. Instance pools with an invalid
+// placement policy are spread like OCI spreads them.
+func placementsForPolicy(instancePool *core.InstancePool, available []core.InstancePoolPlacementConfiguration) []core.InstancePoolPlacementConfiguration {
+	if policy, _ := placementPolicy(instancePool); policy == consts.PlacementPolicyPacked && len(available) > 1 {
+		return available[:1]
+	}
+	return available
+}
+
+// restrict records the placement configurations the instance pool had before it was first restricted.
+func (b *availabilityDomainBackoff) restrict(instancePool *core.InstancePool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, restricted := b.placements[*instancePool.Id]; !restricted {
+		b.placements[*instancePool.Id] = instancePool.PlacementConfigurations
+	}
+}
+
+// unrestrict forgets the placement configurations of an instance pool that has all of them again.
+func (b *availabilityDomainBackoff) unrestrict(instancePoolID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.placements, instancePoolID)
+}
+
+// shortAvailabilityDomains returns the availability domains of the placement configurations that launched fewer
+// instances than the others. OCI does not report which availability domain ran out of capacity, but instance pools
+// spread instances evenly across their placement configurations, so the availability domains that fell behind are the
+// ones out of capacity. If none fell behind, all of them are.
+func shortAvailabilityDomains(placements []core.InstancePoolPlacementConfiguration, instanceSummaries []core.InstanceSummary) []string {
+	launched := map[string]int{}
+	for _, instanceSummary := range instanceSummaries {
+		if instanceSummary.AvailabilityDomain != nil && countLaunchedInstances([]core.InstanceSummary{instanceSummary}) > 0 {
+			launched[*instanceSummary.AvailabilityDomain]++
+		}
+	}
+
+	most := 0
+	for _, placement := range placements {
+		if placement.AvailabilityDomain != nil && launched[*placement.AvailabilityDomain] > most {
+			most = launched[*placement.AvailabilityDomain]
+		}
+	}
+
+	var short, all []string
+	for _, placement := range placements {
+		if placement.AvailabilityDomain == nil {
+			continue
+		}
+		all = append(all, *placement.AvailabilityDomain)
+		if launched[*placement.AvailabilityDomain] < most {
+			short = append(short, *placement.AvailabilityDomain)
+		}
+	}
+	if len(short) == 0 {
+		return all
+	}
+	return short
+}
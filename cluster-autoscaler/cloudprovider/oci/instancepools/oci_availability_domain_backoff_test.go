@@ -0,0 +1,94 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestAvailabilityDomainBackoff(t *testing.T) {
+	const (
+		instanceConfigurationID = "ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"
+		availabilityDomain      = "fake:PHX-AD-1"
+	)
+	b := newAvailabilityDomainBackoff()
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		at       time.Time
+		expected time.Duration
+	}{
+		{name: "first backoff", at: now, expected: availabilityDomainInitialBackoff},
+		{name: "while backed off", at: now.Add(time.Minute), expected: availabilityDomainInitialBackoff},
+		{name: "soon after backoff", at: now.Add(time.Minute + availabilityDomainInitialBackoff + time.Second), expected: 2 * availabilityDomainInitialBackoff},
+		{name: "long after backoff", at: now.Add(2 * availabilityDomainBackoffReset), expected: availabilityDomainInitialBackoff},
+	}
+	for _, tc := range testCases {
+		if got := b.backoff(instanceConfigurationID, availabilityDomain, tc.at); !got.Equal(tc.at.Add(tc.expected)) {
+			t.Errorf("%s: got backoff until %v ; wanted %v", tc.name, got, tc.at.Add(tc.expected))
+		}
+	}
+
+	if !b.isBackedOff(instanceConfigurationID, availabilityDomain, now.Add(2*availabilityDomainBackoffReset)) {
+		t.Errorf("got not backed off ; wanted backed off")
+	}
+	if b.isBackedOff("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa2", availabilityDomain, now) {
+		t.Errorf("got backed off for another instance configuration ; wanted not backed off")
+	}
+}
+
+func TestShortAvailabilityDomains(t *testing.T) {
+	placements := []core.InstancePoolPlacementConfiguration{
+		{AvailabilityDomain: common.String("fake:PHX-AD-1")},
+		{AvailabilityDomain: common.String("fake:PHX-AD-2")},
+		{AvailabilityDomain: common.String("fake:PHX-AD-3")},
+	}
+	instance := func(availabilityDomain, state string) core.InstanceSummary {
+		return core.InstanceSummary{AvailabilityDomain: common.String(availabilityDomain), State: common.String(state)}
+	}
+
+	testCases := map[string]struct {
+		instances []core.InstanceSummary
+		expected  []string
+	}{
+		"one availability domain fell behind": {
+			instances: []core.InstanceSummary{
+				instance("fake:PHX-AD-1", "Running"),
+				instance("fake:PHX-AD-3", "Provisioning"),
+			},
+			expected: []string{"fake:PHX-AD-2"},
+		},
+		"terminated instances are not launched": {
+			instances: []core.InstanceSummary{
+				instance("fake:PHX-AD-1", "Running"),
+				instance("fake:PHX-AD-2", "Terminated"),
+				instance("fake:PHX-AD-3", "Running"),
+			},
+			expected: []string{"fake:PHX-AD-2"},
+		},
+		"none fell behind": {
+			instances: []core.InstanceSummary{
+				instance("fake:PHX-AD-1", "Running"),
+				instance("fake:PHX-AD-2", "Running"),
+				instance("fake:PHX-AD-3", "Running"),
+			},
+			expected: []string{"fake:PHX-AD-1", "fake:PHX-AD-2", "fake:PHX-AD-3"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := shortAvailabilityDomains(placements, tc.instances); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools"
 	npconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
@@ -73,6 +74,9 @@ func (ocp *OciCloudProvider) HasInstance(n *apiv1.Node) (bool, error) {
 // Implementation optional.
 func (ocp *OciCloudProvider) Pricing() (cloudprovider.PricingModel, caerrors.AutoscalerError) {
 	klog.Info("Pricing called")
+	if pricingModel := ocp.poolManager.GetPricingModel(); pricingModel != nil {
+		return pricingModel, nil
+	}
 	return nil, cloudprovider.ErrNotImplemented
 }
 
@@ -80,11 +84,12 @@ func (ocp *OciCloudProvider) Pricing() (cloudprovider.PricingModel, caerrors.Aut
 // Implementation optional.
 func (ocp *OciCloudProvider) GetAvailableMachineTypes() ([]string, error) {
 	klog.Info("GetAvailableMachineTypes called")
-	return nil, cloudprovider.ErrNotImplemented
+	return ocp.poolManager.GetAutoprovisioningShapes()
 }
 
 // NewNodeGroup builds a theoretical node group based on the node definition provided. The node group is not automatically
-// created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
+// created on the cloud provider side. The node group is not returned by NodeGroups() until it is created. An error is
+// returned if the instance configuration of the machine type does not yield the requested labels, taints and resources.
 // Implementation optional.
 func (ocp *OciCloudProvider) NewNodeGroup(machineType string,
 	labels map[string]string,
@@ -92,7 +97,18 @@ func (ocp *OciCloudProvider) NewNodeGroup(machineType string,
 	taints []apiv1.Taint,
 	extraResources map[string]resource.Quantity,
 ) (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ip, err := ocp.poolManager.NewAutoprovisionedInstancePool(machineType)
+	if err != nil {
+		return nil, err
+	}
+	node, err := ocp.poolManager.GetInstancePoolTemplateNode(*ip)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAutoprovisioningRequirements(node, labels, systemLabels, taints, extraResources); err != nil {
+		return nil, err
+	}
+	return ip, nil
 }
 
 // GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
@@ -102,13 +118,13 @@ func (ocp *OciCloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimite
 
 // GPULabel returns the label added to nodes with GPU resource.
 func (ocp *OciCloudProvider) GPULabel() string {
-	// No labels, only taint: nvidia.com/gpu:NoSchedule
-	return ""
+	// GPU nodes are also tainted nvidia.com/gpu:NoSchedule.
+	return consts.GPUProductLabel
 }
 
 // GetAvailableGPUTypes return all available GPU types cloud provider supports.
 func (ocp *OciCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
-	return map[string]struct{}{}
+	return ocicommon.AvailableGPUTypes(ocp.NodeGroups())
 }
 
 // GetNodeGpuConfig returns the label, type and resource name for the GPU added to node. If node doesn't have
@@ -130,6 +146,9 @@ func (ocp *OciCloudProvider) Refresh() error {
 
 // BuildOCI constructs the OciCloudProvider object that implements the could provider interface (InstancePoolManager).
 func BuildOCI(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+	// Register the metrics of OCI node groups.
+	ocicommon.RegisterMetrics()
+
 	ocidType, err := ocicommon.GetAllPoolTypes(opts.NodeGroups)
 	if err != nil {
 		klog.Fatalf("Failed to get pool type: %v", err)
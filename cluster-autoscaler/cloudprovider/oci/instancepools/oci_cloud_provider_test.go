@@ -28,6 +28,12 @@ func Test_getPoolType(t *testing.T) {
 			want:    "instancepool",
 			wantErr: false,
 		},
+		{
+			name:    "node pools and virtual node pools",
+			groups:  []string{"ocid1.nodepool.oc1.ap-melbourne-1.xxx", "ocid1.virtualnodepool.oc1.ap-melbourne-1.yyy"},
+			want:    "nodepool",
+			wantErr: false,
+		},
 		{
 			name:    "empty should pass through",
 			groups:  []string{},
@@ -0,0 +1,632 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/workrequests"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// fakeTenancyID is the tenancy reported by the fake configuration provider.
+	fakeTenancyID = "ocid1.tenancy.oc1..fake"
+	// fakeRegion is the region the OCIDs of fake resources are created in when OCI_REGION is not set.
+	fakeRegion = "us-phoenix-1"
+	// fakeAvailabilityDomain is the availability domain of the instance pools seeded into the fake clients.
+	fakeAvailabilityDomain = "fake:PHX-AD-1"
+	// fakeShape is the shape launched by the instance configuration of every fake instance pool, unless it is one of
+	// gpuInstanceConfigurations.
+	fakeShape = "VM.Standard2.4"
+	// fakeGPUShape is the shape launched by gpuInstanceConfigurations.
+	fakeGPUShape = "VM.GPU.A10.1"
+)
+
+// fakeClients is an in-memory implementation of the compute, compute management, virtual network, work request and
+// shape clients. Instances launched by a fake instance pool are Running immediately, so the autoscaler can be tested
+// end-to-end without an OCI tenancy.
+type fakeClients struct {
+	mu            sync.Mutex
+	region        string
+	instancePools map[string]*core.InstancePool
+	instances     map[string][]core.InstanceSummary
+	vnics         map[string]core.Vnic
+	lastInstance  int
+	lastPool      int
+	// outOfCapacity are the instance configurations that cannot launch instances.
+	outOfCapacity map[string]bool
+	// launchWorkRequests are the in progress launches of instance pools that are out of capacity.
+	launchWorkRequests map[string]workrequests.WorkRequestSummary
+	// instanceListings counts the ListInstancePoolInstances calls per instance pool.
+	instanceListings map[string]int
+	// instanceTags are the freeform tags of the fake instances.
+	instanceTags map[string]map[string]string
+	// instanceUpdates counts the UpdateInstance calls.
+	instanceUpdates int
+	// windowsImages are the images that run Windows, all other images run Oracle Linux.
+	windowsImages map[string]bool
+	// gpuInstanceConfigurations are the instance configurations that launch fakeGPUShape.
+	gpuInstanceConfigurations map[string]bool
+	// outOfCapacityAvailabilityDomains are the availability domains that cannot launch instances.
+	outOfCapacityAvailabilityDomains map[string]bool
+	// deletedSubnets are the subnets that do not exist, all other subnets exist.
+	deletedSubnets map[string]bool
+	// shapelessAvailabilityDomains are the availability domains that do not offer any shape.
+	shapelessAvailabilityDomains map[string]bool
+	// secondaryVnics are the numbers of secondary VNICs instance configurations attach, they attach none by default.
+	secondaryVnics map[string]int
+}
+
+// newFakeClients returns fake clients with no instance pools in the specified region.
+func newFakeClients(region string) *fakeClients {
+	return &fakeClients{
+		region:             region,
+		instancePools:      map[string]*core.InstancePool{},
+		instances:          map[string][]core.InstanceSummary{},
+		vnics:              map[string]core.Vnic{},
+		outOfCapacity:      map[string]bool{},
+		launchWorkRequests: map[string]workrequests.WorkRequestSummary{},
+		instanceListings:   map[string]int{},
+		instanceTags:       map[string]map[string]string{},
+		windowsImages:      map[string]bool{},
+
+		gpuInstanceConfigurations:        map[string]bool{},
+		outOfCapacityAvailabilityDomains: map[string]bool{},
+		deletedSubnets:                   map[string]bool{},
+		shapelessAvailabilityDomains:     map[string]bool{},
+		secondaryVnics:                   map[string]int{},
+	}
+}
+
+// newFakeConfigurationProvider returns a configuration provider that is never used to sign a request.
+func newFakeConfigurationProvider(region string) common.ConfigurationProvider {
+	return common.NewRawConfigurationProvider(fakeTenancyID, "ocid1.user.oc1..fake", region, "fake", "fake", nil)
+}
+
+// createFakeInstancePoolManager constructs an InstancePoolManager backed by in-memory fake clients, so the autoscaler
+// can be run end-to-end without an OCI tenancy. Every instance pool given by the node group specs is seeded with its
+// min size. The fake clients must never reach a real tenancy, so no other OCI client is created.
+func createFakeInstancePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions, kubeClient kubernetes.Interface) (InstancePoolManager, error) {
+	klog.Warning("using in-memory fake OCI clients, no OCI resources will be managed")
+	region := os.Getenv(consts.OciRegionEnvVar)
+	if region == "" {
+		region = fakeRegion
+	}
+	cloudConfig, err := ocicommon.CreateCloudConfig(cloudConfigPath, newFakeConfigurationProvider(region), consts.OciInstancePoolResourceIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	fake := newFakeClients(region)
+	ipManager, err := newInstancePoolManager(cloudConfig, discoveryOpts, kubeClient, fake, fake, fake, fake, fake)
+	if err != nil {
+		return nil, err
+	}
+	for id, ip := range ipManager.staticInstancePools {
+		fake.addInstancePool(id, cloudConfig.Global.CompartmentID, ip.minSize)
+	}
+	if err := ipManager.Refresh(); err != nil {
+		return nil, err
+	}
+	return ipManager, nil
+}
+
+// addInstancePool seeds a Running instance pool with the specified number of Running instances. Instance pools are
+// not seeded implicitly, every instance pool registered with the manager must be added.
+func (f *fakeClients) addInstancePool(instancePoolID, compartmentID string, size int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.instancePools[instancePoolID] = &core.InstancePool{
+		Id:                      common.String(instancePoolID),
+		CompartmentId:           common.String(compartmentID),
+		DisplayName:             common.String(displayNameFromID(instancePoolID)),
+		InstanceConfigurationId: common.String(f.instanceConfigurationID(instancePoolID)),
+		LifecycleState:          core.InstancePoolLifecycleStateRunning,
+		PlacementConfigurations: []core.InstancePoolPlacementConfiguration{{
+			AvailabilityDomain: common.String(fakeAvailabilityDomain),
+		}},
+		Size: common.Int(0),
+	}
+	f.resize(instancePoolID, size)
+}
+
+// GetInstancePool returns the fake instance pool with the specified OCID.
+func (f *fakeClients) GetInstancePool(_ context.Context, req core.GetInstancePoolRequest) (core.GetInstancePoolResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	instancePool, ok := f.instancePools[*req.InstancePoolId]
+	if !ok {
+		return core.GetInstancePoolResponse{}, fakeNotFoundError("instance pool", *req.InstancePoolId)
+	}
+	return core.GetInstancePoolResponse{InstancePool: *instancePool}, nil
+}
+
+// UpdateInstancePool resizes the fake instance pool, launching or terminating instances immediately.
+func (f *fakeClients) UpdateInstancePool(_ context.Context, req core.UpdateInstancePoolRequest) (core.UpdateInstancePoolResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	instancePool, ok := f.instancePools[*req.InstancePoolId]
+	if !ok {
+		return core.UpdateInstancePoolResponse{}, fakeNotFoundError("instance pool", *req.InstancePoolId)
+	}
+	if req.InstanceConfigurationId != nil {
+		instancePool.InstanceConfigurationId = req.InstanceConfigurationId
+	}
+	if req.PlacementConfigurations != nil {
+		instancePool.PlacementConfigurations = nil
+		for _, placement := range req.PlacementConfigurations {
+			instancePool.PlacementConfigurations = append(instancePool.PlacementConfigurations, core.InstancePoolPlacementConfiguration{
+				AvailabilityDomain: placement.AvailabilityDomain,
+				PrimarySubnetId:    placement.PrimarySubnetId,
+			})
+		}
+	}
+	if req.Size != nil {
+		f.resize(*req.InstancePoolId, *req.Size)
+	}
+	return core.UpdateInstancePoolResponse{InstancePool: *instancePool}, nil
+}
+
+// GetInstancePoolInstance returns the instance with the specified OCID if it belongs to the fake instance pool.
+func (f *fakeClients) GetInstancePoolInstance(_ context.Context, req core.GetInstancePoolInstanceRequest) (core.GetInstancePoolInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, instance := range f.instances[*req.InstancePoolId] {
+		if *instance.Id == *req.InstanceId {
+			return core.GetInstancePoolInstanceResponse{
+				InstancePoolInstance: core.InstancePoolInstance{
+					Id:                      instance.Id,
+					InstancePoolId:          req.InstancePoolId,
+					AvailabilityDomain:      instance.AvailabilityDomain,
+					CompartmentId:           instance.CompartmentId,
+					InstanceConfigurationId: instance.InstanceConfigurationId,
+					DisplayName:             instance.DisplayName,
+					Shape:                   instance.Shape,
+					FaultDomain:             instance.FaultDomain,
+					LifecycleState:          core.InstancePoolInstanceLifecycleStateActive,
+				},
+			}, nil
+		}
+	}
+	return core.GetInstancePoolInstanceResponse{}, fakeNotFoundError("instance", *req.InstanceId)
+}
+
+// ListInstancePoolInstances lists the instances of the fake instance pool in a single page.
+func (f *fakeClients) ListInstancePoolInstances(_ context.Context, req core.ListInstancePoolInstancesRequest) (core.ListInstancePoolInstancesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.instanceListings[*req.InstancePoolId]++
+	if _, ok := f.instancePools[*req.InstancePoolId]; !ok {
+		return core.ListInstancePoolInstancesResponse{}, fakeNotFoundError("instance pool", *req.InstancePoolId)
+	}
+	items := make([]core.InstanceSummary, len(f.instances[*req.InstancePoolId]))
+	copy(items, f.instances[*req.InstancePoolId])
+	return core.ListInstancePoolInstancesResponse{Items: items}, nil
+}
+
+// DetachInstancePoolInstance removes the instance from the fake instance pool, optionally decrementing its size.
+func (f *fakeClients) DetachInstancePoolInstance(_ context.Context, req core.DetachInstancePoolInstanceRequest) (core.DetachInstancePoolInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	instancePool, ok := f.instancePools[*req.InstancePoolId]
+	if !ok {
+		return core.DetachInstancePoolInstanceResponse{}, fakeNotFoundError("instance pool", *req.InstancePoolId)
+	}
+	instances := f.instances[*req.InstancePoolId]
+	for i, instance := range instances {
+		if *instance.Id != *req.InstanceId {
+			continue
+		}
+		f.instances[*req.InstancePoolId] = append(instances[:i:i], instances[i+1:]...)
+		delete(f.vnics, *instance.Id)
+		if req.IsDecrementSize == nil || *req.IsDecrementSize {
+			instancePool.Size = common.Int(*instancePool.Size - 1)
+		} else {
+			// The instance pool replaces detached instances when its size is not decremented.
+			f.resize(*req.InstancePoolId, *instancePool.Size)
+		}
+		return core.DetachInstancePoolInstanceResponse{}, nil
+	}
+	return core.DetachInstancePoolInstanceResponse{}, fakeNotFoundError("instance", *req.InstanceId)
+}
+
+// ListInstancePools lists the fake instance pools in the requested compartment in a single page.
+func (f *fakeClients) ListInstancePools(_ context.Context, req core.ListInstancePoolsRequest) (core.ListInstancePoolsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []core.InstancePoolSummary
+	for _, instancePool := range f.instancePools {
+		if req.CompartmentId != nil && *instancePool.CompartmentId != *req.CompartmentId {
+			continue
+		}
+		items = append(items, core.InstancePoolSummary{
+			Id:                      instancePool.Id,
+			CompartmentId:           instancePool.CompartmentId,
+			InstanceConfigurationId: instancePool.InstanceConfigurationId,
+			DisplayName:             instancePool.DisplayName,
+			LifecycleState:          core.InstancePoolSummaryLifecycleStateEnum(instancePool.LifecycleState),
+			Size:                    instancePool.Size,
+			FreeformTags:            instancePool.FreeformTags,
+		})
+	}
+	return core.ListInstancePoolsResponse{Items: items}, nil
+}
+
+// CreateInstancePool creates a Running fake instance pool.
+func (f *fakeClients) CreateInstancePool(_ context.Context, req core.CreateInstancePoolRequest) (core.CreateInstancePoolResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lastPool++
+	id := fmt.Sprintf("ocid1.instancepool.oc1.%s.fake%d", f.region, f.lastPool)
+	instancePool := &core.InstancePool{
+		Id:                      common.String(id),
+		CompartmentId:           req.CompartmentId,
+		DisplayName:             common.String(displayNameFromID(id)),
+		InstanceConfigurationId: req.InstanceConfigurationId,
+		LifecycleState:          core.InstancePoolLifecycleStateRunning,
+		FreeformTags:            req.FreeformTags,
+		Size:                    common.Int(0),
+	}
+	for _, placement := range req.PlacementConfigurations {
+		instancePool.PlacementConfigurations = append(instancePool.PlacementConfigurations, core.InstancePoolPlacementConfiguration{
+			AvailabilityDomain: placement.AvailabilityDomain,
+			PrimarySubnetId:    placement.PrimarySubnetId,
+		})
+	}
+	f.instancePools[id] = instancePool
+	if req.Size != nil {
+		f.resize(id, *req.Size)
+	}
+	return core.CreateInstancePoolResponse{InstancePool: *instancePool}, nil
+}
+
+// TerminateInstancePool terminates the fake instance pool and its instances.
+func (f *fakeClients) TerminateInstancePool(_ context.Context, req core.TerminateInstancePoolRequest) (core.TerminateInstancePoolResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.instancePools[*req.InstancePoolId]; !ok {
+		return core.TerminateInstancePoolResponse{}, fakeNotFoundError("instance pool", *req.InstancePoolId)
+	}
+	for _, instance := range f.instances[*req.InstancePoolId] {
+		delete(f.vnics, *instance.Id)
+	}
+	delete(f.instancePools, *req.InstancePoolId)
+	delete(f.instances, *req.InstancePoolId)
+	return core.TerminateInstancePoolResponse{}, nil
+}
+
+// GetInstance returns the freeform tags of the requested fake instance.
+func (f *fakeClients) GetInstance(_ context.Context, req core.GetInstanceRequest) (core.GetInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.vnics[*req.InstanceId]; !ok {
+		return core.GetInstanceResponse{}, fakeNotFoundError("instance", *req.InstanceId)
+	}
+	return core.GetInstanceResponse{
+		Instance: core.Instance{
+			Id:           req.InstanceId,
+			FreeformTags: f.instanceTags[*req.InstanceId],
+		},
+	}, nil
+}
+
+// UpdateInstance replaces the freeform tags of the requested fake instance.
+func (f *fakeClients) UpdateInstance(_ context.Context, req core.UpdateInstanceRequest) (core.UpdateInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.vnics[*req.InstanceId]; !ok {
+		return core.UpdateInstanceResponse{}, fakeNotFoundError("instance", *req.InstanceId)
+	}
+	f.instanceUpdates++
+	f.instanceTags[*req.InstanceId] = req.FreeformTags
+	return core.UpdateInstanceResponse{Instance: core.Instance{Id: req.InstanceId, FreeformTags: req.FreeformTags}}, nil
+}
+
+// ListInstances lists the instances of the fake instance pools of the requested compartment in a single page.
+func (f *fakeClients) ListInstances(_ context.Context, req core.ListInstancesRequest) (core.ListInstancesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []core.Instance
+	for _, instances := range f.instances {
+		for _, instance := range instances {
+			if instance.CompartmentId == nil || *instance.CompartmentId != *req.CompartmentId {
+				continue
+			}
+			items = append(items, core.Instance{
+				Id:                 instance.Id,
+				AvailabilityDomain: instance.AvailabilityDomain,
+				CompartmentId:      instance.CompartmentId,
+				DisplayName:        instance.DisplayName,
+				Shape:              instance.Shape,
+				LifecycleState:     core.InstanceLifecycleStateEnum(strings.ToUpper(*instance.State)),
+				TimeCreated:        instance.TimeCreated,
+			})
+		}
+	}
+	return core.ListInstancesResponse{Items: items}, nil
+}
+
+// ListVnicAttachments lists the attachment of the primary VNIC of the requested fake instance.
+func (f *fakeClients) ListVnicAttachments(_ context.Context, req core.ListVnicAttachmentsRequest) (core.ListVnicAttachmentsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.InstanceId == nil {
+		return core.ListVnicAttachmentsResponse{}, nil
+	}
+	vnic, ok := f.vnics[*req.InstanceId]
+	if !ok {
+		return core.ListVnicAttachmentsResponse{}, nil
+	}
+	return core.ListVnicAttachmentsResponse{
+		Items: []core.VnicAttachment{{
+			Id:             common.String(strings.Replace(*vnic.Id, ".vnic.", ".vnicattachment.", 1)),
+			InstanceId:     req.InstanceId,
+			VnicId:         vnic.Id,
+			LifecycleState: core.VnicAttachmentLifecycleStateAttached,
+		}},
+	}, nil
+}
+
+// GetVnic returns the fake VNIC with the specified OCID.
+func (f *fakeClients) GetVnic(_ context.Context, req core.GetVnicRequest) (core.GetVnicResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, vnic := range f.vnics {
+		if *vnic.Id == *req.VnicId {
+			return core.GetVnicResponse{Vnic: vnic}, nil
+		}
+	}
+	return core.GetVnicResponse{}, fakeNotFoundError("vnic", *req.VnicId)
+}
+
+// GetSubnet returns a fake subnet unless the subnet is one of deletedSubnets.
+func (f *fakeClients) GetSubnet(_ context.Context, req core.GetSubnetRequest) (core.GetSubnetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.deletedSubnets[*req.SubnetId] {
+		return core.GetSubnetResponse{}, fakeNotFoundError("subnet", *req.SubnetId)
+	}
+	return core.GetSubnetResponse{Subnet: core.Subnet{Id: req.SubnetId}}, nil
+}
+
+// GetWorkRequest always fails since fake instance pools complete every operation synchronously.
+func (f *fakeClients) GetWorkRequest(_ context.Context, req workrequests.GetWorkRequestRequest) (workrequests.GetWorkRequestResponse, error) {
+	return workrequests.GetWorkRequestResponse{}, fakeNotFoundError("work request", *req.WorkRequestId)
+}
+
+// ListWorkRequests returns the launch work request of the specified instance pool if it is out of capacity.
+func (f *fakeClients) ListWorkRequests(_ context.Context, req workrequests.ListWorkRequestsRequest) (workrequests.ListWorkRequestsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if workRequest, ok := f.launchWorkRequests[*req.ResourceId]; ok {
+		return workrequests.ListWorkRequestsResponse{Items: []workrequests.WorkRequestSummary{workRequest}}, nil
+	}
+	return workrequests.ListWorkRequestsResponse{}, nil
+}
+
+// ListWorkRequestErrors returns an out of capacity error for the launch work requests of instance pools.
+func (f *fakeClients) ListWorkRequestErrors(_ context.Context, req workrequests.ListWorkRequestErrorsRequest) (workrequests.ListWorkRequestErrorsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, workRequest := range f.launchWorkRequests {
+		if *workRequest.Id == *req.WorkRequestId {
+			return workrequests.ListWorkRequestErrorsResponse{Items: []workrequests.WorkRequestError{{
+				Code:    common.String("OutOfCapacity"),
+				Message: common.String("OutOfCapacity: Out of host capacity."),
+			}}}, nil
+		}
+	}
+	return workrequests.ListWorkRequestErrorsResponse{}, nil
+}
+
+// GetInstanceConfiguration returns an instance configuration that launches fakeShape, or fakeGPUShape if it is one of
+// gpuInstanceConfigurations, from the image of the instance configuration.
+func (f *fakeClients) GetInstanceConfiguration(_ context.Context, req core.GetInstanceConfigurationRequest) (core.GetInstanceConfigurationResponse, error) {
+	f.mu.Lock()
+	shape := fakeShape
+	if f.gpuInstanceConfigurations[*req.InstanceConfigurationId] {
+		shape = fakeGPUShape
+	}
+	secondaryVnics := make([]core.InstanceConfigurationAttachVnicDetails, f.secondaryVnics[*req.InstanceConfigurationId])
+	f.mu.Unlock()
+
+	return core.GetInstanceConfigurationResponse{
+		InstanceConfiguration: core.InstanceConfiguration{
+			Id: req.InstanceConfigurationId,
+			InstanceDetails: core.ComputeInstanceDetails{
+				LaunchDetails: &core.InstanceConfigurationLaunchInstanceDetails{
+					Shape: common.String(shape),
+					SourceDetails: core.InstanceConfigurationInstanceSourceViaImageDetails{
+						ImageId: common.String(f.imageID(*req.InstanceConfigurationId)),
+					},
+				},
+				SecondaryVnics: secondaryVnics,
+			},
+		},
+	}, nil
+}
+
+// GetImage returns a Windows image if the image is one of windowsImages, and an Oracle Linux image otherwise.
+func (f *fakeClients) GetImage(_ context.Context, req core.GetImageRequest) (core.GetImageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	operatingSystem := "Oracle Linux"
+	if f.windowsImages[*req.ImageId] {
+		operatingSystem = "Windows"
+	}
+	return core.GetImageResponse{
+		Image: core.Image{
+			Id:              req.ImageId,
+			OperatingSystem: common.String(operatingSystem),
+		},
+	}, nil
+}
+
+// ListShapes returns fakeShape and fakeGPUShape, or no shapes if the availability domain does not offer any.
+func (f *fakeClients) ListShapes(_ context.Context, req core.ListShapesRequest) (core.ListShapesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.AvailabilityDomain != nil && f.shapelessAvailabilityDomains[*req.AvailabilityDomain] {
+		return core.ListShapesResponse{}, nil
+	}
+	return core.ListShapesResponse{
+		Items: []core.Shape{
+			{
+				Shape:       common.String(fakeShape),
+				Ocpus:       common.Float32(4),
+				MemoryInGBs: common.Float32(60),
+			},
+			{
+				Shape:       common.String(fakeGPUShape),
+				Ocpus:       common.Float32(15),
+				MemoryInGBs: common.Float32(240),
+				Gpus:        common.Int(1),
+			},
+		},
+	}, nil
+}
+
+// resize launches or terminates instances of the specified instance pool until it has size instances. Instances are
+// spread evenly across the placement configurations of the instance pool. Instances are not launched if the instance
+// configuration of the instance pool or their availability domain is out of capacity, instead the launch work request
+// stays in progress with an out of capacity error. The caller must hold the lock.
+func (f *fakeClients) resize(instancePoolID string, size int) {
+	instancePool := f.instancePools[instancePoolID]
+	instances := f.instances[instancePoolID]
+	delete(f.launchWorkRequests, instancePoolID)
+	outOfCapacity := func() {
+		f.launchWorkRequests[instancePoolID] = workrequests.WorkRequestSummary{
+			Id:            common.String("ocid1.workrequest.oc1." + f.region + "." + displayNameFromID(instancePoolID)),
+			OperationType: common.String(consts.OciInstancePoolLaunchOp),
+			Status:        workrequests.WorkRequestSummaryStatusInProgress,
+			TimeStarted:   &common.SDKTime{Time: time.Now()},
+		}
+	}
+	if len(instances) < size && f.outOfCapacity[*instancePool.InstanceConfigurationId] {
+		outOfCapacity()
+		instancePool.Size = common.Int(size)
+		return
+	}
+
+	placed := map[string]int{}
+	for _, instance := range instances {
+		placed[*instance.AvailabilityDomain]++
+	}
+	for launching := len(instances); launching < size; launching++ {
+		availabilityDomain := fakeAvailabilityDomain
+		for i, placement := range instancePool.PlacementConfigurations {
+			if i == 0 || placed[*placement.AvailabilityDomain] < placed[availabilityDomain] {
+				availabilityDomain = *placement.AvailabilityDomain
+			}
+		}
+		placed[availabilityDomain]++
+		if f.outOfCapacityAvailabilityDomains[availabilityDomain] {
+			outOfCapacity()
+			continue
+		}
+
+		f.lastInstance++
+		instanceID := fmt.Sprintf("ocid1.instance.oc1.%s.fake%d", f.region, f.lastInstance)
+		f.vnics[instanceID] = core.Vnic{
+			Id:        common.String(fmt.Sprintf("ocid1.vnic.oc1.%s.fake%d", f.region, f.lastInstance)),
+			PrivateIp: common.String(fmt.Sprintf("10.0.%d.%d", f.lastInstance/256, f.lastInstance%256)),
+		}
+		instances = append(instances, core.InstanceSummary{
+			Id:                      common.String(instanceID),
+			AvailabilityDomain:      common.String(availabilityDomain),
+			CompartmentId:           instancePool.CompartmentId,
+			InstanceConfigurationId: instancePool.InstanceConfigurationId,
+			DisplayName:             common.String(fmt.Sprintf("%s-%d", *instancePool.DisplayName, f.lastInstance)),
+			Shape:                   common.String(fakeShape),
+			State:                   common.String(string(core.InstanceLifecycleStateRunning)),
+			TimeCreated:             &common.SDKTime{Time: time.Now()},
+		})
+	}
+	for len(instances) > size {
+		delete(f.vnics, *instances[len(instances)-1].Id)
+		instances = instances[:len(instances)-1]
+	}
+	f.instances[instancePoolID] = instances
+	instancePool.Size = common.Int(size)
+}
+
+// instanceConfigurationID returns the OCID of the fake instance configuration of the specified instance pool.
+func (f *fakeClients) instanceConfigurationID(instancePoolID string) string {
+	return fmt.Sprintf("ocid1.instanceconfiguration.oc1.%s.%s", f.region, displayNameFromID(instancePoolID))
+}
+
+// imageID returns the OCID of the image the specified instance configuration launches instances from.
+func (f *fakeClients) imageID(instanceConfigurationID string) string {
+	return fmt.Sprintf("ocid1.image.oc1.%s.%s", f.region, displayNameFromID(instanceConfigurationID))
+}
+
+// displayNameFromID returns the unique part of the specified OCID.
+func displayNameFromID(id string) string {
+	return id[strings.LastIndex(id, ".")+1:]
+}
+
+// fakeNotFoundError returns the error returned by the fake clients for a resource that does not exist.
+func fakeNotFoundError(kind, id string) error {
+	return fakeServiceError(fmt.Sprintf("%s %s not found", kind, id))
+}
+
+// fakeServiceError is a common.ServiceError returned by the fake clients.
+type fakeServiceError string
+
+func (e fakeServiceError) Error() string {
+	return string(e)
+}
+
+// GetHTTPStatusCode implements common.ServiceError.
+func (e fakeServiceError) GetHTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+// GetMessage implements common.ServiceError.
+func (e fakeServiceError) GetMessage() string {
+	return string(e)
+}
+
+// GetCode implements common.ServiceError.
+func (e fakeServiceError) GetCode() string {
+	return "NotAuthorizedOrNotFound"
+}
+
+// GetOpcRequestID implements common.ServiceError.
+func (e fakeServiceError) GetOpcRequestID() string {
+	return ""
+}
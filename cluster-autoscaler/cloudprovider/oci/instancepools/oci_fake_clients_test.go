@@ -0,0 +1,980 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFakeClientsScaleUpAndDown(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	// Seed the instance pool at its min size, as it would be in a tenancy before the autoscaler starts.
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	instancePools := manager.GetInstancePools()
+	if len(instancePools) != 1 {
+		t.Fatalf("got %d instance-pools ; wanted 1", len(instancePools))
+	}
+	ip := instancePools[0]
+
+	if err := ip.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	instances, err := ip.Nodes()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(instances) != 3 {
+		t.Fatalf("got %d instances ; wanted 3", len(instances))
+	}
+	for _, instance := range instances {
+		if instance.Status == nil || instance.Status.State != cloudprovider.InstanceRunning {
+			t.Errorf("got status %+v for instance %s ; wanted running", instance.Status, instance.Id)
+		}
+	}
+
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       apiv1.NodeSpec{ProviderID: instances[2].Id},
+	}
+	if err := ip.DeleteNodes([]*apiv1.Node{node}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 2 {
+		t.Errorf("got target size %d (%v) ; wanted 2", size, err)
+	}
+}
+
+func TestCreateInstancePoolManagerFakeClients(t *testing.T) {
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	t.Setenv(consts.OciUseFakeClientsEnvVar, "true")
+	t.Setenv(consts.OciCompartmentEnvVar, "ocid1.compartment.oc1..aaaaaaaa1")
+
+	manager, err := CreateInstancePoolManager("", cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"2:5:" + instancePoolID},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	instancePools := manager.GetInstancePools()
+	if len(instancePools) != 1 {
+		t.Fatalf("got %d instance-pools ; wanted 1", len(instancePools))
+	}
+	if size, err := instancePools[0].TargetSize(); err != nil || size != 2 {
+		t.Errorf("got target size %d (%v) ; wanted 2", size, err)
+	}
+}
+
+func TestFakeClientsInstanceConfigurationFallback(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const (
+		instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		preferred      = "ocid1.instanceconfiguration.oc1.phx.e5"
+		fallback       = "ocid1.instanceconfiguration.oc1.phx.e4"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.instancePools[instancePoolID].InstanceConfigurationId = common.String(preferred)
+	fake.outOfCapacity[preferred] = true
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID + ":" + preferred + "," + fallback},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	ip := manager.GetInstancePools()[0]
+	if err := ip.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := *fake.instancePools[instancePoolID].InstanceConfigurationId; got != fallback {
+		t.Errorf("got instance configuration %s ; wanted %s", got, fallback)
+	}
+	instances, err := ip.Nodes()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(instances) != 3 {
+		t.Errorf("got %d instances ; wanted 3", len(instances))
+	}
+
+	// Without another instance configuration to fall back to, the out of capacity error is returned.
+	fake.outOfCapacity[fallback] = true
+	if err := ip.IncreaseSize(1); err == nil || !ocicommon.IsOutOfCapacity(err.Error()) {
+		t.Errorf("got error %v ; wanted an out of capacity error", err)
+	}
+}
+
+func TestFakeClientsPreemptibleFallback(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const (
+		preemptibleID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		onDemandID    = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	// Instances are listed on every refresh, so preempted instances are seen right away.
+	cloudConfig.Global.DrainTerminatingNodes = true
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(preemptibleID, cloudConfig.Global.CompartmentID, 1)
+	fake.addInstancePool(onDemandID, cloudConfig.Global.CompartmentID, 0)
+	fake.instancePools[preemptibleID].FreeformTags = map[string]string{consts.FallbackInstancePoolTag: onDemandID}
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"0:5:" + preemptibleID, "0:5:" + onDemandID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	preemptible := manager.getStaticInstancePool(preemptibleID)
+	onDemand := manager.getStaticInstancePool(onDemandID)
+
+	// Once the preemptible instance pool runs out of capacity, it fails scale-ups without launching anything.
+	fake.outOfCapacity[fake.instanceConfigurationID(preemptibleID)] = true
+	if err := preemptible.IncreaseSize(1); err == nil || !ocicommon.IsOutOfCapacity(err.Error()) {
+		t.Errorf("got error %v ; wanted an out of capacity error", err)
+	}
+	var unavailableErr *ocicommon.PreemptibleUnavailableError
+	if err := preemptible.IncreaseSize(1); !errors.As(err, &unavailableErr) || unavailableErr.FallbackNodeGroupID != onDemandID {
+		t.Errorf("got error %v ; wanted a PreemptibleUnavailableError falling back to %s", err, onDemandID)
+	}
+	if err := onDemand.IncreaseSize(1); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// It is available again once it recovered.
+	fake.outOfCapacity[fake.instanceConfigurationID(preemptibleID)] = false
+	delete(manager.preemptibleFallback.unavailable, preemptibleID)
+	if err := preemptible.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// A preemption storm makes it unavailable as well.
+	fake.mu.Lock()
+	for i := range fake.instances[preemptibleID] {
+		fake.instances[preemptibleID][i].State = common.String(string(core.InstanceLifecycleStateTerminating))
+	}
+	fake.mu.Unlock()
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.CheckInstancePoolAvailable(*preemptible); !errors.As(err, &unavailableErr) {
+		t.Errorf("got error %v ; wanted a PreemptibleUnavailableError", err)
+	}
+
+	// A fallback instance pool the autoscaler does not manage is a misconfiguration.
+	fake.instancePools[preemptibleID].FreeformTags[consts.FallbackInstancePoolTag] = "ocid1.instancepool.oc1.phx.aaaaaaaa3"
+	problem, err := manager.validateInstancePool(preemptibleID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if problem == nil || !strings.Contains(problem.Error(), consts.FallbackInstancePoolTag) {
+		t.Errorf("got problem %v ; wanted an invalid %s freeform tag", problem, consts.FallbackInstancePoolTag)
+	}
+}
+
+func TestFakeClientsAvailabilityDomainReselection(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	availabilityDomains := []string{"fake:PHX-AD-1", "fake:PHX-AD-2", "fake:PHX-AD-3"}
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 0)
+	fake.instancePools[instancePoolID].PlacementConfigurations = nil
+	for _, availabilityDomain := range availabilityDomains {
+		fake.instancePools[instancePoolID].PlacementConfigurations = append(fake.instancePools[instancePoolID].PlacementConfigurations,
+			core.InstancePoolPlacementConfiguration{
+				AvailabilityDomain: common.String(availabilityDomain),
+				PrimarySubnetId:    common.String("ocid1.subnet.oc1.phx." + displayNameFromID(availabilityDomain)),
+			})
+	}
+	fake.outOfCapacityAvailabilityDomains["fake:PHX-AD-2"] = true
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"0:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	placedInstances := func() map[string]int {
+		placed := map[string]int{}
+		for _, instance := range fake.instances[instancePoolID] {
+			placed[*instance.AvailabilityDomain]++
+		}
+		return placed
+	}
+
+	// The instance that could not be launched in AD-2 is launched in one of the others.
+	ip := manager.GetInstancePools()[0]
+	if err := ip.IncreaseSize(3); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected := map[string]int{"fake:PHX-AD-1": 2, "fake:PHX-AD-3": 1}
+	if got := placedInstances(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got instances per availability domain %v ; wanted %v", got, expected)
+	}
+	if got := len(fake.instancePools[instancePoolID].PlacementConfigurations); got != 2 {
+		t.Errorf("got %d placement configurations ; wanted 2", got)
+	}
+
+	// Once the backoff of AD-2 expires, scale-ups use it again.
+	fake.outOfCapacityAvailabilityDomains["fake:PHX-AD-2"] = false
+	instanceConfigurationID := fake.instanceConfigurationID(instancePoolID)
+	if !manager.availabilityDomainBackoff.isBackedOff(instanceConfigurationID, "fake:PHX-AD-2", time.Now()) {
+		t.Errorf("wanted fake:PHX-AD-2 to be backed off")
+	}
+	manager.availabilityDomainBackoff.backoffInfo[availabilityDomainBackoffKey(instanceConfigurationID, "fake:PHX-AD-2")] =
+		availabilityDomainBackoffInfo{duration: availabilityDomainInitialBackoff, backoffUntil: time.Now().Add(-time.Second)}
+	if err := ip.IncreaseSize(1); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected["fake:PHX-AD-2"] = 1
+	if got := placedInstances(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got instances per availability domain %v ; wanted %v", got, expected)
+	}
+	if got := len(fake.instancePools[instancePoolID].PlacementConfigurations); got != 3 {
+		t.Errorf("got %d placement configurations ; wanted 3", got)
+	}
+}
+
+func TestFakeClientsPackedPlacementPolicy(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	availabilityDomains := []string{"fake:PHX-AD-1", "fake:PHX-AD-2", "fake:PHX-AD-3"}
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 0)
+	fake.instancePools[instancePoolID].FreeformTags = map[string]string{consts.PlacementPolicyTag: consts.PlacementPolicyPacked}
+	fake.instancePools[instancePoolID].PlacementConfigurations = nil
+	for _, availabilityDomain := range availabilityDomains {
+		fake.instancePools[instancePoolID].PlacementConfigurations = append(fake.instancePools[instancePoolID].PlacementConfigurations,
+			core.InstancePoolPlacementConfiguration{
+				AvailabilityDomain: common.String(availabilityDomain),
+				PrimarySubnetId:    common.String("ocid1.subnet.oc1.phx." + displayNameFromID(availabilityDomain)),
+			})
+	}
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"0:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	placedInstances := func() map[string]int {
+		placed := map[string]int{}
+		for _, instance := range fake.instances[instancePoolID] {
+			placed[*instance.AvailabilityDomain]++
+		}
+		return placed
+	}
+
+	// A packed instance pool fills its first availability domain.
+	ip := manager.GetInstancePools()[0]
+	if err := ip.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected := map[string]int{"fake:PHX-AD-1": 2}
+	if got := placedInstances(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got instances per availability domain %v ; wanted %v", got, expected)
+	}
+
+	// Once it runs out of capacity, it fills the next one.
+	fake.outOfCapacityAvailabilityDomains["fake:PHX-AD-1"] = true
+	if err := ip.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected["fake:PHX-AD-2"] = 2
+	if got := placedInstances(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got instances per availability domain %v ; wanted %v", got, expected)
+	}
+	if got := len(fake.instancePools[instancePoolID].PlacementConfigurations); got != 1 {
+		t.Errorf("got %d placement configurations ; wanted 1", got)
+	}
+
+	// Instance pools with an unknown placement policy are misconfigured.
+	fake.instancePools[instancePoolID].FreeformTags[consts.PlacementPolicyTag] = "random"
+	problem, err := manager.validateInstancePool(instancePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if problem == nil || !strings.Contains(problem.Error(), consts.PlacementPolicyTag) {
+		t.Errorf("got problem %v ; wanted an invalid %s freeform tag", problem, consts.PlacementPolicyTag)
+	}
+}
+
+func TestFakeClientsMaxNodeProvisionTime(t *testing.T) {
+	const (
+		taggedInstancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		instancePoolID       = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	cloudConfig.Global.MaxNodeProvisionTime = fakeShape + "=30m"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(taggedInstancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.instancePools[taggedInstancePoolID].FreeformTags = map[string]string{ocicommon.MaxNodeProvisionTimeTag: "1h"}
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + taggedInstancePoolID, "1:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	defaults := config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}
+	expected := map[string]time.Duration{
+		taggedInstancePoolID: time.Hour,
+		instancePoolID:       30 * time.Minute,
+	}
+	for _, ip := range manager.GetInstancePools() {
+		options, err := ip.GetOptions(defaults)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if options == nil || options.MaxNodeProvisionTime != expected[ip.Id()] {
+			t.Errorf("got options %+v for instance-pool %s ; wanted max node provision time %v", options, ip.Id(), expected[ip.Id()])
+		}
+	}
+}
+
+func TestFakeClientsIncrementalRefresh(t *testing.T) {
+	const (
+		instancePoolID        = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		changedInstancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	cloudConfig.Global.FullRefreshInterval = time.Hour
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.addInstancePool(changedInstancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID, "1:5:" + changedInstancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// The instance pool is resized outside the autoscaler, e.g. from the console.
+	fake.mu.Lock()
+	fake.resize(changedInstancePoolID, 2)
+	fake.mu.Unlock()
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if got := fake.instanceListings[instancePoolID]; got != 1 {
+		t.Errorf("got %d listings of the instances of the unchanged instance-pool ; wanted 1", got)
+	}
+	if got := fake.instanceListings[changedInstancePoolID]; got != 2 {
+		t.Errorf("got %d listings of the instances of the changed instance-pool ; wanted 2", got)
+	}
+	instanceSummaries, err := manager.instancePoolCache.getInstanceSummaries(changedInstancePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*instanceSummaries) != 2 {
+		t.Errorf("got %d cached instances ; wanted 2", len(*instanceSummaries))
+	}
+
+	// Invalidated instance pools are listed whether they changed or not.
+	if err := manager.forceRefreshInstancePool(instancePoolID); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := fake.instanceListings[instancePoolID]; got != 2 {
+		t.Errorf("got %d listings of the instances of the invalidated instance-pool ; wanted 2", got)
+	}
+}
+
+func TestFakeClientsIncrementalRefreshReplacedInstance(t *testing.T) {
+	const (
+		instancePoolID         = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		replacedInstancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	cloudConfig.Global.FullRefreshInterval = time.Hour
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.addInstancePool(replacedInstancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID, "1:5:" + replacedInstancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// OCI terminates an instance and the instance pool replaces it, without changing the instance pool itself.
+	fake.mu.Lock()
+	terminatedInstanceID := *fake.instances[replacedInstancePoolID][0].Id
+	fake.resize(replacedInstancePoolID, 0)
+	fake.resize(replacedInstancePoolID, 1)
+	fake.mu.Unlock()
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if got := fake.instanceListings[instancePoolID]; got != 1 {
+		t.Errorf("got %d listings of the instances of the unchanged instance-pool ; wanted 1", got)
+	}
+	if got := fake.instanceListings[replacedInstancePoolID]; got != 2 {
+		t.Errorf("got %d listings of the instances of the instance-pool with a replaced instance ; wanted 2", got)
+	}
+	instanceSummaries, err := manager.instancePoolCache.getInstanceSummaries(replacedInstancePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*instanceSummaries) != 1 || *(*instanceSummaries)[0].Id == terminatedInstanceID {
+		t.Errorf("got cached instances %+v ; wanted the replacement of %s", *instanceSummaries, terminatedInstanceID)
+	}
+}
+
+func TestFakeClientsExternalResize(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 2)
+	kubeClient := kubefake.NewSimpleClientset()
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:10:" + instancePoolID},
+	}, kubeClient, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	ip := manager.GetInstancePools()[0]
+
+	// The instance pool is resized in the console after the last refresh, the scale-up is applied to its new size
+	// rather than reverting the resize, and does not fail.
+	fake.mu.Lock()
+	fake.resize(instancePoolID, 4)
+	fake.mu.Unlock()
+	if err := ip.IncreaseSize(1); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 5 {
+		t.Errorf("got target size %d (%v) ; wanted 5", size, err)
+	}
+
+	// Resizes are also adopted on refresh.
+	fake.mu.Lock()
+	fake.resize(instancePoolID, 3)
+	fake.mu.Unlock()
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 3 {
+		t.Errorf("got target size %d (%v) ; wanted 3", size, err)
+	}
+
+	// Resizes by the autoscaler are not mistaken for external resizes.
+	if err := ip.IncreaseSize(1); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	events, err := kubeClient.CoreV1().Events(metav1.NamespaceSystem).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(events.Items) != 2 {
+		t.Fatalf("got %d events ; wanted 2", len(events.Items))
+	}
+	for _, event := range events.Items {
+		if event.Reason != ocicommon.ResizedExternallyReason {
+			t.Errorf("got event reason %s ; wanted %s", event.Reason, ocicommon.ResizedExternallyReason)
+		}
+	}
+}
+
+func TestFakeClientsTagLaunchedInstances(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	cloudConfig.Global.TagLaunchedInstances = true
+	cloudConfig.Global.ClusterName = "cluster-1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.SetInstancePoolSize(*manager.GetInstancePools()[0], 2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := map[string]string{
+		ocicommon.ManagedByTag:   ocicommon.ManagedByTagValue,
+		ocicommon.ClusterNameTag: "cluster-1",
+		ocicommon.NodeGroupTag:   instancePoolID,
+	}
+	for _, instance := range fake.instances[instancePoolID] {
+		tags := fake.instanceTags[*instance.Id]
+		for key, value := range expected {
+			if tags[key] != value {
+				t.Errorf("got tag %s=%q on instance %s ; wanted %q", key, tags[key], *instance.Id, value)
+			}
+		}
+	}
+	// Every instance is tagged once.
+	if fake.instanceUpdates != 2 {
+		t.Errorf("got %d instance updates ; wanted 2", fake.instanceUpdates)
+	}
+}
+
+func TestFakeClientsReportVCPUs(t *testing.T) {
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+
+	testCases := map[string]struct {
+		reportVCPUs bool
+		expected    string
+	}{
+		"OCPUs": {
+			expected: "4",
+		},
+		"vCPUs": {
+			reportVCPUs: true,
+			expected:    "8",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cloudConfig := &ocicommon.CloudConfig{}
+			cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+			cloudConfig.Global.ReportVCPUs = tc.reportVCPUs
+
+			fake := newFakeClients(fakeRegion)
+			fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+
+			manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+				NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+			}, nil, fake, fake, fake, fake, fake)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if err := manager.Refresh(); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			node, err := manager.GetInstancePoolTemplateNode(*manager.GetInstancePools()[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if got := node.Status.Capacity[apiv1.ResourceCPU]; got.String() != tc.expected {
+				t.Errorf("got cpu capacity %s ; wanted %s", got.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestFakeClientsSecondaryVnicResource(t *testing.T) {
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	const resourceName = apiv1.ResourceName("oci.oraclecloud.com/sriov-vnic")
+
+	testCases := map[string]struct {
+		resourceName   string
+		secondaryVnics int
+		expected       string
+	}{
+		"secondary vnics": {
+			resourceName:   string(resourceName),
+			secondaryVnics: 2,
+			expected:       "2",
+		},
+		"no secondary vnics": {
+			resourceName: string(resourceName),
+		},
+		"resource not configured": {
+			secondaryVnics: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cloudConfig := &ocicommon.CloudConfig{}
+			cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+			cloudConfig.Global.SecondaryVnicResourceName = tc.resourceName
+
+			fake := newFakeClients(fakeRegion)
+			fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+			fake.secondaryVnics[fake.instanceConfigurationID(instancePoolID)] = tc.secondaryVnics
+
+			manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+				NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+			}, nil, fake, fake, fake, fake, fake)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if err := manager.Refresh(); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			node, err := manager.GetInstancePoolTemplateNode(*manager.GetInstancePools()[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			got, ok := node.Status.Allocatable[resourceName]
+			if tc.expected == "" {
+				if ok {
+					t.Errorf("got %s %s ; wanted none", got.String(), resourceName)
+				}
+				return
+			}
+			if got.String() != tc.expected {
+				t.Errorf("got %s %s ; wanted %s", got.String(), resourceName, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFakeClientsWindowsTemplate(t *testing.T) {
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+
+	testCases := map[string]struct {
+		windows      bool
+		expectedOS   string
+		expectedPods int
+	}{
+		"linux image": {
+			expectedOS:   "linux",
+			expectedPods: 2,
+		},
+		"windows image": {
+			windows:      true,
+			expectedOS:   ocicommon.WindowsOS,
+			expectedPods: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cloudConfig := &ocicommon.CloudConfig{}
+			cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+			fake := newFakeClients(fakeRegion)
+			fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+			fake.windowsImages[fake.imageID(fake.instanceConfigurationID(instancePoolID))] = tc.windows
+
+			manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+				NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+			}, nil, fake, fake, fake, fake, fake)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if err := manager.Refresh(); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			nodeInfo, err := manager.GetInstancePools()[0].TemplateNodeInfo()
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			node := nodeInfo.Node()
+			if got := node.Labels[apiv1.LabelOSStable]; got != tc.expectedOS {
+				t.Errorf("got os label %s ; wanted %s", got, tc.expectedOS)
+			}
+			hasTaint := false
+			for _, taint := range node.Spec.Taints {
+				if taint.Key == ocicommon.WindowsTaintKey && taint.Effect == apiv1.TaintEffectNoSchedule {
+					hasTaint = true
+				}
+			}
+			if hasTaint != tc.windows {
+				t.Errorf("got windows taint %v ; wanted %v", hasTaint, tc.windows)
+			}
+			if got := len(nodeInfo.Pods); got != tc.expectedPods {
+				t.Errorf("got %d pods ; wanted %d", got, tc.expectedPods)
+			}
+		})
+	}
+}
+
+func TestFakeClientsAvailableGPUTypes(t *testing.T) {
+	const (
+		instancePoolID    = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		gpuInstancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.addInstancePool(gpuInstancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.gpuInstanceConfigurations[fake.instanceConfigurationID(gpuInstancePoolID)] = true
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID, "1:5:" + gpuInstancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	provider := &OciCloudProvider{poolManager: manager}
+
+	expected := map[string]struct{}{"NVIDIA-A10": {}}
+	if got := provider.GetAvailableGPUTypes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got GPU types %v ; wanted %v", got, expected)
+	}
+
+	var gpuInstancePool *InstancePoolNodeGroup
+	for _, ip := range manager.GetInstancePools() {
+		if ip.Id() == gpuInstancePoolID {
+			gpuInstancePool = ip
+		}
+	}
+	nodeInfo, err := gpuInstancePool.TemplateNodeInfo()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	gpuConfig := provider.GetNodeGpuConfig(nodeInfo.Node())
+	if gpuConfig == nil || gpuConfig.Type != "NVIDIA-A10" {
+		t.Errorf("got GPU config %+v ; wanted type NVIDIA-A10", gpuConfig)
+	}
+}
+
+type failingFunctionsInvokeClient struct {
+	invocations []ocicommon.InvokeFunctionRequest
+}
+
+func (c *failingFunctionsInvokeClient) InvokeFunction(_ context.Context, req ocicommon.InvokeFunctionRequest) (ocicommon.InvokeFunctionResponse, error) {
+	c.invocations = append(c.invocations, req)
+	return ocicommon.InvokeFunctionResponse{}, fmt.Errorf("function returned 502")
+}
+
+func TestFakeClientsPreTerminationHook(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 3)
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := manager.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	ip := manager.GetInstancePools()[0]
+	instances, err := ip.Nodes()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       apiv1.NodeSpec{ProviderID: instances[2].Id},
+	}
+
+	// A failed hook keeps the instance if the failure policy is abort.
+	client := &failingFunctionsInvokeClient{}
+	manager.preTerminationHook = ocicommon.NewPreTerminationHook(client, "ocid1.fnfunc.oc1.phx.aaaaaaaa1", time.Second,
+		ocicommon.PreTerminationFailurePolicyAbort)
+	if err := ip.DeleteNodes([]*apiv1.Node{node}); err == nil {
+		t.Fatalf("got no error ; wanted the pre-termination hook to abort the deletion")
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 3 {
+		t.Errorf("got target size %d (%v) ; wanted 3", size, err)
+	}
+	if len(client.invocations) != 1 || len(client.invocations[0].Instances) != 1 ||
+		client.invocations[0].Instances[0].InstanceID != instances[2].Id {
+		t.Errorf("got invocations %+v ; wanted one for instance %s", client.invocations, instances[2].Id)
+	}
+
+	// A failed hook does not keep the instance if the failure policy is continue.
+	manager.preTerminationHook = ocicommon.NewPreTerminationHook(client, "ocid1.fnfunc.oc1.phx.aaaaaaaa1", time.Second,
+		ocicommon.PreTerminationFailurePolicyContinue)
+	if err := ip.DeleteNodes([]*apiv1.Node{node}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 2 {
+		t.Errorf("got target size %d (%v) ; wanted 2", size, err)
+	}
+}
+
+func TestFakeClientsValidateInstancePools(t *testing.T) {
+	const (
+		instancePoolID        = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+		missingInstancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa2"
+		subnetID              = "ocid1.subnet.oc1.phx.aaaaaaaa1"
+	)
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+
+	fake := newFakeClients(fakeRegion)
+	fake.addInstancePool(instancePoolID, cloudConfig.Global.CompartmentID, 1)
+	fake.instancePools[instancePoolID].PlacementConfigurations[0].PrimarySubnetId = common.String(subnetID)
+	fake.deletedSubnets[subnetID] = true
+
+	manager, err := newInstancePoolManager(cloudConfig, cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupSpecs: []string{"1:5:" + instancePoolID, "1:5:" + missingInstancePoolID},
+	}, nil, fake, fake, fake, fake, fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// Misconfigured instance pools do not fail the refresh, they are left out of it.
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	for _, id := range []string{instancePoolID, missingInstancePoolID} {
+		if manager.validator.IsValid(id) {
+			t.Errorf("got instance pool %s valid ; wanted misconfigured", id)
+		}
+		if _, ok := manager.instancePoolCache.InstancePools()[id]; ok {
+			t.Errorf("got instance pool %s cached ; wanted it left out", id)
+		}
+	}
+
+	// Instance pools whose shape is not offered in their availability domain are left out as well.
+	fake.mu.Lock()
+	delete(fake.deletedSubnets, subnetID)
+	fake.shapelessAvailabilityDomains[fakeAvailabilityDomain] = true
+	fake.mu.Unlock()
+	problem, err := manager.validateInstancePool(instancePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if problem == nil || !strings.Contains(problem.Error(), fakeShape) {
+		t.Errorf("got problem %v ; wanted shape %s not offered", problem, fakeShape)
+	}
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if manager.validator.IsValid(instancePoolID) {
+		t.Errorf("got instance pool %s valid ; wanted misconfigured", instancePoolID)
+	}
+
+	// Fixed instance pools are refreshed again.
+	fake.mu.Lock()
+	delete(fake.shapelessAvailabilityDomains, fakeAvailabilityDomain)
+	fake.mu.Unlock()
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !manager.validator.IsValid(instancePoolID) {
+		t.Errorf("got instance pool %s misconfigured ; wanted valid", instancePoolID)
+	}
+	if _, ok := manager.instancePoolCache.InstancePools()[instancePoolID]; !ok {
+		t.Errorf("got instance pool %s not cached ; wanted it refreshed", instancePoolID)
+	}
+}
@@ -24,6 +24,15 @@ type InstancePoolNodeGroup struct {
 	id         string
 	minSize    int
 	maxSize    int
+	// autoprovisioned is set for instance-pools created by the autoscaler.
+	autoprovisioned bool
+	// theoretical is set for autoprovisioned instance-pools that have not been created yet.
+	theoretical bool
+	// instanceConfigurationID is the instance configuration an autoprovisioned instance-pool is created from.
+	instanceConfigurationID string
+	// instanceConfigurationIDs are the instance configurations the instance-pool launches instances from in order of
+	// preference. The instance-pool falls back to the next one when the current one is out of capacity.
+	instanceConfigurationIDs []string
 }
 
 // MaxSize returns maximum size of the instance-pool based node group.
@@ -41,6 +50,9 @@ func (ip *InstancePoolNodeGroup) MinSize() int {
 // to Size() once everything stabilizes (new nodes finish startup and registration or
 // removed nodes are deleted completely). Implementation required.
 func (ip *InstancePoolNodeGroup) TargetSize() (int, error) {
+	if ip.theoretical {
+		return 0, nil
+	}
 	return ip.manager.GetInstancePoolSize(*ip)
 }
 
@@ -52,6 +64,11 @@ func (ip *InstancePoolNodeGroup) IncreaseSize(delta int) error {
 		return fmt.Errorf("size increase must be positive")
 	}
 
+	return retryResizedExternally(func() error { return ip.increaseSize(delta) })
+}
+
+func (ip *InstancePoolNodeGroup) increaseSize(delta int) error {
+
 	size, err := ip.manager.GetInstancePoolSize(*ip)
 	if err != nil {
 		return err
@@ -61,7 +78,40 @@ func (ip *InstancePoolNodeGroup) IncreaseSize(delta int) error {
 		return fmt.Errorf("size increase too large - desired:%d max:%d", size+delta, ip.MaxSize())
 	}
 
-	return ip.manager.SetInstancePoolSize(*ip, size+delta)
+	if err := ip.manager.CheckInstancePoolAvailable(*ip); err != nil {
+		// Fail without launching anything, so that the core autoscaler backs off and scales up the fallback
+		// instance-pool instead.
+		return err
+	}
+
+	hasCapacity, err := ip.manager.HasInstancePoolHostCapacity(*ip)
+	if err != nil {
+		klog.Warningf("unable to check host capacity of instance-pool %s, continuing without: %v", ip.Id(), err)
+	} else if !hasCapacity {
+		// Fail without launching anything, so that the core autoscaler backs off and chooses another node group rather
+		// than waiting for a launch that cannot succeed.
+		return &ocicommon.HostCapacityError{NodeGroupID: ip.Id()}
+	}
+
+	available, err := ip.manager.GetInstancePoolAvailableInstanceCount(*ip)
+	if err != nil {
+		klog.Warningf("unable to check service limits of instance-pool %s, continuing without: %v", ip.Id(), err)
+		return ip.manager.SetInstancePoolSize(*ip, size+delta)
+	}
+	if delta <= available {
+		return ip.manager.SetInstancePoolSize(*ip, size+delta)
+	}
+
+	// Launch what the service limits allow and fail the remainder of the scale-up, so that the core autoscaler backs off
+	// rather than waiting for instances that cannot be launched.
+	limitErr := &ocicommon.ServiceLimitError{NodeGroupID: ip.Id(), Requested: delta, Available: available}
+	if available > 0 {
+		klog.Warningf("increasing size of instance-pool %s by %d rather than %d due to service limits", ip.Id(), available, delta)
+		if err := ip.manager.SetInstancePoolSize(*ip, size+available); err != nil {
+			return err
+		}
+	}
+	return limitErr
 }
 
 // AtomicIncreaseSize is not implemented.
@@ -69,6 +119,11 @@ func (ip *InstancePoolNodeGroup) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity is not implemented.
+func (ip *InstancePoolNodeGroup) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
 // DeleteNodes deletes nodes from this instance-pool. Error is returned either on
 // failure or if the given node doesn't belong to this instance-pool. This function
 // should wait until instance-pool size is updated. Implementation required.
@@ -83,7 +138,7 @@ func (ip *InstancePoolNodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
 		return err
 	}
 
-	if size <= ip.MinSize() {
+	if size-len(nodes) < ip.MinSize() {
 		return fmt.Errorf("min size reached, nodes will not be deleted")
 	}
 
@@ -117,6 +172,11 @@ func (ip *InstancePoolNodeGroup) DecreaseTargetSize(delta int) error {
 		return fmt.Errorf("size decrease must be negative")
 	}
 
+	return retryResizedExternally(func() error { return ip.decreaseTargetSize(delta) })
+}
+
+func (ip *InstancePoolNodeGroup) decreaseTargetSize(delta int) error {
+
 	size, err := ip.manager.GetInstancePoolSize(*ip)
 	if err != nil {
 		return err
@@ -135,6 +195,19 @@ func (ip *InstancePoolNodeGroup) DecreaseTargetSize(delta int) error {
 	return ip.manager.SetInstancePoolSize(*ip, size+delta)
 }
 
+// retryResizedExternally calls resize once more if it failed because the instance-pool was resized outside the
+// autoscaler. Its new size is adopted by then, so the target size is recomputed from it rather than reverting the
+// external resize, and the resize does not fail so that the core autoscaler does not back off the instance-pool.
+func retryResizedExternally(resize func() error) error {
+	err := resize()
+	var resizedErr *ocicommon.ResizedExternallyError
+	if errors.As(err, &resizedErr) {
+		klog.Infof("%v, resizing it from its new size", err)
+		return resize()
+	}
+	return err
+}
+
 // Belongs returns true if the given node belongs to the InstancePoolNodeGroup.
 func (ip *InstancePoolNodeGroup) Belongs(node *apiv1.Node) (bool, error) {
 	ref, err := ocicommon.NodeToOciRef(node)
@@ -169,6 +242,9 @@ func (ip *InstancePoolNodeGroup) Debug() string {
 // Other fields are optional.
 // This list should include also instances that might have not become a kubernetes node yet.
 func (ip *InstancePoolNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	if ip.theoretical {
+		return []cloudprovider.Instance{}, nil
+	}
 	return ip.manager.GetInstancePoolNodes(*ip)
 }
 
@@ -184,6 +260,13 @@ func (ip *InstancePoolNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInf
 		return nil, errors.Wrap(err, "unable to build node info template")
 	}
 
+	// kube-proxy and the CSI node driver pods are Linux only.
+	if ocicommon.IsWindowsNode(node) {
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		return nodeInfo, nil
+	}
+
 	nodeInfo := schedulerframework.NewNodeInfo(
 		cloudprovider.BuildKubeProxy(ip.id),
 		ocicommon.BuildCSINodePod(),
@@ -195,29 +278,44 @@ func (ip *InstancePoolNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInf
 // Exist checks if the instance-pool based node group really exists on the cloud provider side. Allows to tell the
 // theoretical instance-pool from the real one. Implementation required.
 func (ip *InstancePoolNodeGroup) Exist() bool {
-	return true
+	return !ip.theoretical
 }
 
 // Create creates the instance-pool based node group on the cloud provider side. Implementation optional.
 func (ip *InstancePoolNodeGroup) Create() (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if !ip.theoretical {
+		return nil, cloudprovider.ErrAlreadyExist
+	}
+	created, err := ip.manager.CreateInstancePool(*ip)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 // Delete deletes the instance-pool based node group on the cloud provider side.
 // This will be executed only for autoprovisioned instance-pools, once their size drops to 0.
 // Implementation optional.
 func (ip *InstancePoolNodeGroup) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return ip.manager.DeleteInstancePool(*ip)
 }
 
 // GetOptions returns NodeGroupAutoscalingOptions that should be used for this particular
 // InstancePoolNodeGroup. Returning a nil will result in using default options.
+// Only MaxNodeProvisionTime can be overridden, see GetInstancePoolMaxNodeProvisionTime.
 func (ip *InstancePoolNodeGroup) GetOptions(defaults config.NodeGroupAutoscalingOptions) (*config.NodeGroupAutoscalingOptions, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if !ip.Exist() {
+		return nil, nil
+	}
+	maxNodeProvisionTime, err := ip.manager.GetInstancePoolMaxNodeProvisionTime(*ip)
+	if err != nil {
+		return nil, err
+	}
+	return ocicommon.NodeGroupOptions(defaults, maxNodeProvisionTime), nil
 }
 
 // Autoprovisioned returns true if the instance-pool based node group is autoprovisioned. An autoprovisioned group
 // was created by CA and can be deleted when scaled to 0.
 func (ip *InstancePoolNodeGroup) Autoprovisioned() bool {
-	return false
+	return ip.autoprovisioned
 }
@@ -0,0 +1,255 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/klog/v2"
+)
+
+// GetAutoprovisioningShapes returns the shapes new instance-pools can be autoprovisioned with.
+func (m *InstancePoolManagerImpl) GetAutoprovisioningShapes() ([]string, error) {
+	templates, err := m.getAutoprovisioningTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	shapes := make([]string, 0, len(templates))
+	for shape := range templates {
+		shapes = append(shapes, shape)
+	}
+	sort.Strings(shapes)
+	return shapes, nil
+}
+
+// NewAutoprovisionedInstancePool returns a theoretical instance-pool with the specified shape. The instance-pool is
+// not created until Create is called on it.
+func (m *InstancePoolManagerImpl) NewAutoprovisionedInstancePool(shape string) (*InstancePoolNodeGroup, error) {
+	templates, err := m.getAutoprovisioningTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceConfigurationID, ok := templates[shape]
+	if !ok {
+		return nil, fmt.Errorf("no autoprovisioning instance configuration found for shape %s", shape)
+	}
+
+	return &InstancePoolNodeGroup{
+		manager:                 m,
+		kubeClient:              m.kubeClient,
+		id:                      theoreticalInstancePoolID(instanceConfigurationID),
+		minSize:                 0,
+		maxSize:                 consts.AutoprovisionedInstancePoolMaxSize,
+		autoprovisioned:         true,
+		theoretical:             true,
+		instanceConfigurationID: instanceConfigurationID,
+	}, nil
+}
+
+// checkAutoprovisioningRequirements returns an error unless the template node of an autoprovisioned instance-pool
+// already has the labels, taints and extra resources requested by node auto-provisioning. Instance-pools are created
+// from the configured instance configurations as is, so they cannot be given anything their template does not have.
+func checkAutoprovisioningRequirements(node *apiv1.Node, labels, systemLabels map[string]string, taints []apiv1.Taint,
+	extraResources map[string]resource.Quantity) error {
+	for _, requested := range []map[string]string{labels, systemLabels} {
+		for key, value := range requested {
+			if actual, ok := node.Labels[key]; !ok || actual != value {
+				return fmt.Errorf("autoprovisioned instance-pools of shape %s do not have label %s=%s",
+					node.Labels[apiv1.LabelInstanceTypeStable], key, value)
+			}
+		}
+	}
+	for i := range taints {
+		found := false
+		for j := range node.Spec.Taints {
+			if taints[i].MatchTaint(&node.Spec.Taints[j]) && taints[i].Value == node.Spec.Taints[j].Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("autoprovisioned instance-pools of shape %s do not have taint %s",
+				node.Labels[apiv1.LabelInstanceTypeStable], taints[i].ToString())
+		}
+	}
+	for name, quantity := range extraResources {
+		if capacity, ok := node.Status.Capacity[apiv1.ResourceName(name)]; !ok || capacity.Cmp(quantity) < 0 {
+			return fmt.Errorf("autoprovisioned instance-pools of shape %s do not have %s of resource %s",
+				node.Labels[apiv1.LabelInstanceTypeStable], quantity.String(), name)
+		}
+	}
+	return nil
+}
+
+// CreateInstancePool creates the specified theoretical instance-pool with a size of 0.
+func (m *InstancePoolManagerImpl) CreateInstancePool(ip InstancePoolNodeGroup) (*InstancePoolNodeGroup, error) {
+	if !ip.theoretical {
+		return nil, fmt.Errorf("instance-pool %s already exists", ip.Id())
+	}
+	klog.Infof("CreateInstancePool called for instance configuration %s", ip.instanceConfigurationID)
+
+	instancePool, err := m.instancePoolCache.createInstancePool(core.CreateInstancePoolDetails{
+		CompartmentId:           common.String(m.cfg.Global.CompartmentID),
+		InstanceConfigurationId: common.String(ip.instanceConfigurationID),
+		PlacementConfigurations: []core.CreateInstancePoolPlacementConfigurationDetails{{
+			AvailabilityDomain: common.String(m.cfg.Global.AutoprovisioningAvailabilityDomain),
+			PrimarySubnetId:    common.String(m.cfg.Global.AutoprovisioningSubnetID),
+		}},
+		Size:         common.Int(0),
+		FreeformTags: map[string]string{consts.AutoprovisionedFromTag: ip.instanceConfigurationID},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create instance-pool from instance configuration %s", ip.instanceConfigurationID)
+	}
+
+	created := m.newAutoprovisionedInstancePool(*instancePool.Id, ip.instanceConfigurationID)
+	created.minSize = ip.minSize
+	created.maxSize = ip.maxSize
+	m.instancePoolsMu.Lock()
+	m.staticInstancePools[created.Id()] = created
+	m.instancePoolsMu.Unlock()
+	return created, nil
+}
+
+// DeleteInstancePool terminates the specified autoprovisioned instance-pool.
+func (m *InstancePoolManagerImpl) DeleteInstancePool(ip InstancePoolNodeGroup) error {
+	if !ip.autoprovisioned || ip.theoretical {
+		return fmt.Errorf("instance-pool %s was not autoprovisioned and will not be deleted", ip.Id())
+	}
+	klog.Infof("DeleteInstancePool called on instance pool %s", ip.Id())
+
+	if err := m.instancePoolCache.terminateInstancePool(ip.Id()); err != nil {
+		return errors.Wrapf(err, "unable to terminate instance-pool %s", ip.Id())
+	}
+	m.instancePoolsMu.Lock()
+	delete(m.staticInstancePools, ip.Id())
+	m.instancePoolsMu.Unlock()
+	return nil
+}
+
+// discoverAutoprovisionedInstancePools registers the instance-pools previously autoprovisioned from one of the configured
+// instance configurations, and forgets the ones that no longer exist.
+func (m *InstancePoolManagerImpl) discoverAutoprovisionedInstancePools() error {
+	if len(m.cfg.Global.AutoprovisioningInstanceConfigurationID) == 0 {
+		return nil
+	}
+
+	instancePools, err := m.instancePoolCache.listInstancePools(m.cfg.Global.CompartmentID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list autoprovisioned instance-pools")
+	}
+
+	m.instancePoolsMu.Lock()
+	defer m.instancePoolsMu.Unlock()
+
+	discovered := map[string]bool{}
+	for _, instancePool := range instancePools {
+		instanceConfigurationID, ok := instancePool.FreeformTags[consts.AutoprovisionedFromTag]
+		if !ok || !m.isAutoprovisioningTemplate(instanceConfigurationID) {
+			continue
+		}
+		if instancePool.LifecycleState == core.InstancePoolSummaryLifecycleStateTerminating ||
+			instancePool.LifecycleState == core.InstancePoolSummaryLifecycleStateTerminated {
+			continue
+		}
+
+		discovered[*instancePool.Id] = true
+		if _, found := m.staticInstancePools[*instancePool.Id]; !found {
+			klog.Infof("discovered autoprovisioned instance-pool %s", *instancePool.Id)
+			m.staticInstancePools[*instancePool.Id] = m.newAutoprovisionedInstancePool(*instancePool.Id, instanceConfigurationID)
+		}
+	}
+
+	for id, ip := range m.staticInstancePools {
+		if ip.autoprovisioned && !discovered[id] {
+			klog.Infof("autoprovisioned instance-pool %s no longer exists", id)
+			delete(m.staticInstancePools, id)
+		}
+	}
+	return nil
+}
+
+// getAutoprovisioningTemplates returns the configured autoprovisioning instance configurations keyed by the shape they launch.
+func (m *InstancePoolManagerImpl) getAutoprovisioningTemplates() (map[string]string, error) {
+	if len(m.cfg.Global.AutoprovisioningInstanceConfigurationID) == 0 {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	if m.autoprovisioningTemplates != nil {
+		return m.autoprovisioningTemplates, nil
+	}
+
+	templates := map[string]string{}
+	for _, instanceConfigurationID := range m.cfg.Global.AutoprovisioningInstanceConfigurationID {
+		shape, err := m.ShapeGetter.GetInstancePoolShape(m.theoreticalInstancePool(instanceConfigurationID))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get shape of autoprovisioning instance configuration %s", instanceConfigurationID)
+		}
+		if existing, found := templates[shape.Name]; found {
+			klog.Warningf("instance configurations %s and %s both launch shape %s, using %s", existing, instanceConfigurationID, shape.Name, existing)
+			continue
+		}
+		templates[shape.Name] = instanceConfigurationID
+	}
+	m.autoprovisioningTemplates = templates
+	return templates, nil
+}
+
+// isAutoprovisioningTemplate returns whether the specified instance configuration is configured for autoprovisioning.
+func (m *InstancePoolManagerImpl) isAutoprovisioningTemplate(instanceConfigurationID string) bool {
+	for _, id := range m.cfg.Global.AutoprovisioningInstanceConfigurationID {
+		if id == instanceConfigurationID {
+			return true
+		}
+	}
+	return false
+}
+
+// newAutoprovisionedInstancePool wraps an existing autoprovisioned instance pool.
+func (m *InstancePoolManagerImpl) newAutoprovisionedInstancePool(id, instanceConfigurationID string) *InstancePoolNodeGroup {
+	return &InstancePoolNodeGroup{
+		manager:                 m,
+		kubeClient:              m.kubeClient,
+		id:                      id,
+		minSize:                 0,
+		maxSize:                 consts.AutoprovisionedInstancePoolMaxSize,
+		autoprovisioned:         true,
+		instanceConfigurationID: instanceConfigurationID,
+	}
+}
+
+// theoreticalInstancePool returns the instance pool that would be created from the specified instance configuration.
+func (m *InstancePoolManagerImpl) theoreticalInstancePool(instanceConfigurationID string) *core.InstancePool {
+	return &core.InstancePool{
+		Id:                      common.String(theoreticalInstancePoolID(instanceConfigurationID)),
+		CompartmentId:           common.String(m.cfg.Global.CompartmentID),
+		InstanceConfigurationId: common.String(instanceConfigurationID),
+		PlacementConfigurations: []core.InstancePoolPlacementConfiguration{{
+			AvailabilityDomain: common.String(m.cfg.Global.AutoprovisioningAvailabilityDomain),
+			PrimarySubnetId:    common.String(m.cfg.Global.AutoprovisioningSubnetID),
+		}},
+		Size: common.Int(0),
+	}
+}
+
+// theoreticalInstancePoolID returns a placeholder OCID for the instance pool that would be created from the specified
+// instance configuration. The placeholder is in the same realm and region as the instance configuration.
+func theoreticalInstancePoolID(instanceConfigurationID string) string {
+	parts := strings.Split(instanceConfigurationID, ".")
+	if len(parts) < 5 {
+		return fmt.Sprintf("ocid1.instancepool.oc1..%s-%s", consts.AutoprovisionedInstancePoolIdent, instanceConfigurationID)
+	}
+	return fmt.Sprintf("ocid1.instancepool.%s.%s.%s-%s", parts[2], parts[3], consts.AutoprovisionedInstancePoolIdent, parts[len(parts)-1])
+}
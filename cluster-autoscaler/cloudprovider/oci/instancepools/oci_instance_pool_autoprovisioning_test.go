@@ -0,0 +1,187 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func newAutoprovisioningTestManager(computeManagementClient *mockComputeManagementClient) *InstancePoolManagerImpl {
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaa1"
+	cloudConfig.Global.AutoprovisioningInstanceConfigurationID = []string{"ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"}
+	cloudConfig.Global.AutoprovisioningAvailabilityDomain = "hash:PHX-AD-1"
+	cloudConfig.Global.AutoprovisioningSubnetID = "ocid1.subnet.oc1.phx.aaaaaaaa1"
+
+	return &InstancePoolManagerImpl{
+		cfg:                 cloudConfig,
+		staticInstancePools: map[string]*InstancePoolNodeGroup{},
+		ShapeGetter:         ocicommon.CreateShapeGetter(shapeClient, nil),
+		instancePoolCache:   newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient),
+	}
+}
+
+func TestAutoprovisionInstancePool(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	instancePool := core.InstancePool{
+		Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa2"),
+		CompartmentId:           common.String("ocid1.compartment.oc1..aaaaaaaa1"),
+		InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+		LifecycleState:          core.InstancePoolLifecycleStateProvisioning,
+		Size:                    common.Int(0),
+	}
+	running := instancePool
+	running.LifecycleState = core.InstancePoolLifecycleStateRunning
+	computeManagementClient := &mockComputeManagementClient{
+		createInstancePoolResponse: core.CreateInstancePoolResponse{InstancePool: instancePool},
+		// The new instance-pool is still provisioning when first polled.
+		getInstancePoolResponses: []core.GetInstancePoolResponse{{InstancePool: instancePool}},
+		getInstancePoolResponse:  core.GetInstancePoolResponse{InstancePool: running},
+	}
+	manager := newAutoprovisioningTestManager(computeManagementClient)
+
+	shapes, err := manager.GetAutoprovisioningShapes()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !reflect.DeepEqual(shapes, []string{"VM.Standard.E3.Flex"}) {
+		t.Fatalf("got shapes %v ; wanted [VM.Standard.E3.Flex]", shapes)
+	}
+
+	if _, err := manager.NewAutoprovisionedInstancePool("VM.Standard2.8"); err == nil {
+		t.Fatalf("expected error for a shape without autoprovisioning instance configuration")
+	}
+
+	ip, err := manager.NewAutoprovisionedInstancePool("VM.Standard.E3.Flex")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if ip.Exist() || !ip.Autoprovisioned() {
+		t.Fatalf("expected a theoretical autoprovisioned instance-pool")
+	}
+	if size, err := ip.TargetSize(); err != nil || size != 0 {
+		t.Fatalf("got target size %d (%v) ; wanted 0", size, err)
+	}
+
+	node, err := manager.GetInstancePoolTemplateNode(*ip)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if node.Labels[apiv1.LabelInstanceTypeStable] != "VM.Standard.E3.Flex" {
+		t.Errorf("got instance type %q ; wanted VM.Standard.E3.Flex", node.Labels[apiv1.LabelInstanceTypeStable])
+	}
+
+	created, err := ip.Create()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if created.Id() != "ocid1.instancepool.oc1.phx.aaaaaaaa2" || !created.Exist() || !created.Autoprovisioned() {
+		t.Fatalf("got %+v ; wanted an existing autoprovisioned instance-pool", created)
+	}
+	if _, found := manager.staticInstancePools[created.Id()]; !found {
+		t.Fatalf("created instance-pool was not registered")
+	}
+	if len(computeManagementClient.getInstancePoolResponses) != 0 {
+		t.Fatalf("expected Create to wait for the instance-pool to leave the PROVISIONING state")
+	}
+	if cached, err := manager.instancePoolCache.getInstancePool(created.Id()); err != nil || cached.LifecycleState != core.InstancePoolLifecycleStateRunning {
+		t.Fatalf("got cached instance-pool %+v (%v) ; wanted a RUNNING instance-pool", cached, err)
+	}
+
+	if err := created.Delete(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if _, found := manager.staticInstancePools[created.Id()]; found {
+		t.Fatalf("deleted instance-pool is still registered")
+	}
+}
+
+func TestDiscoverAutoprovisionedInstancePools(t *testing.T) {
+	computeManagementClient := &mockComputeManagementClient{
+		listInstancePoolsResponse: core.ListInstancePoolsResponse{
+			Items: []core.InstancePoolSummary{{
+				Id:             common.String("ocid1.instancepool.oc1.phx.aaaaaaaa2"),
+				LifecycleState: core.InstancePoolSummaryLifecycleStateRunning,
+				FreeformTags:   map[string]string{consts.AutoprovisionedFromTag: "ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"},
+			}, {
+				Id:             common.String("ocid1.instancepool.oc1.phx.aaaaaaaa3"),
+				LifecycleState: core.InstancePoolSummaryLifecycleStateRunning,
+				FreeformTags:   map[string]string{consts.AutoprovisionedFromTag: "ocid1.instanceconfiguration.oc1.phx.other"},
+			}, {
+				Id:             common.String("ocid1.instancepool.oc1.phx.aaaaaaaa4"),
+				LifecycleState: core.InstancePoolSummaryLifecycleStateTerminated,
+				FreeformTags:   map[string]string{consts.AutoprovisionedFromTag: "ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"},
+			}},
+		},
+	}
+	manager := newAutoprovisioningTestManager(computeManagementClient)
+	manager.staticInstancePools["ocid1.instancepool.oc1.phx.gone"] = manager.newAutoprovisionedInstancePool("ocid1.instancepool.oc1.phx.gone", "ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1")
+	manager.staticInstancePools["ocid1.instancepool.oc1.phx.static"] = &InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.static"}
+
+	if err := manager.discoverAutoprovisionedInstancePools(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	var ids []string
+	for id := range manager.staticInstancePools {
+		ids = append(ids, id)
+	}
+	if len(ids) != 2 || manager.staticInstancePools["ocid1.instancepool.oc1.phx.aaaaaaaa2"] == nil || manager.staticInstancePools["ocid1.instancepool.oc1.phx.static"] == nil {
+		t.Errorf("got instance-pools %v ; wanted the discovered and the static instance-pool", ids)
+	}
+}
+
+func TestNewNodeGroupAutoprovisioningRequirements(t *testing.T) {
+	manager := newAutoprovisioningTestManager(&mockComputeManagementClient{})
+	provider := &OciCloudProvider{poolManager: manager}
+
+	testCases := map[string]struct {
+		labels         map[string]string
+		taints         []apiv1.Taint
+		extraResources map[string]resource.Quantity
+		expectError    bool
+	}{
+		"no requirements": {},
+		"label of the template": {
+			labels: map[string]string{apiv1.LabelInstanceTypeStable: "VM.Standard.E3.Flex"},
+		},
+		"label the template does not have": {
+			labels:      map[string]string{"workload": "batch"},
+			expectError: true,
+		},
+		"taint the template does not have": {
+			taints:      []apiv1.Taint{{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}},
+			expectError: true,
+		},
+		"resource the template does not have": {
+			extraResources: map[string]resource.Quantity{"example.com/fpga": resource.MustParse("1")},
+			expectError:    true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := provider.NewNodeGroup("VM.Standard.E3.Flex", tc.labels, nil, tc.taints, tc.extraResources)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+		})
+	}
+}
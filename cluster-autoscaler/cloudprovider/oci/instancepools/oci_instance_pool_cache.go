@@ -13,12 +13,16 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/workrequests"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
 
@@ -29,16 +33,23 @@ type ComputeMgmtClient interface {
 	GetInstancePoolInstance(context.Context, core.GetInstancePoolInstanceRequest) (core.GetInstancePoolInstanceResponse, error)
 	ListInstancePoolInstances(context.Context, core.ListInstancePoolInstancesRequest) (core.ListInstancePoolInstancesResponse, error)
 	DetachInstancePoolInstance(context.Context, core.DetachInstancePoolInstanceRequest) (core.DetachInstancePoolInstanceResponse, error)
+	ListInstancePools(context.Context, core.ListInstancePoolsRequest) (core.ListInstancePoolsResponse, error)
+	CreateInstancePool(context.Context, core.CreateInstancePoolRequest) (core.CreateInstancePoolResponse, error)
+	TerminateInstancePool(context.Context, core.TerminateInstancePoolRequest) (core.TerminateInstancePoolResponse, error)
 }
 
 // ComputeClient wraps core.ComputeClient exposing the functions we actually require.
 type ComputeClient interface {
 	ListVnicAttachments(ctx context.Context, request core.ListVnicAttachmentsRequest) (core.ListVnicAttachmentsResponse, error)
+	GetInstance(ctx context.Context, request core.GetInstanceRequest) (core.GetInstanceResponse, error)
+	UpdateInstance(ctx context.Context, request core.UpdateInstanceRequest) (core.UpdateInstanceResponse, error)
+	ListInstances(ctx context.Context, request core.ListInstancesRequest) (core.ListInstancesResponse, error)
 }
 
 // VirtualNetworkClient wraps core.VirtualNetworkClient exposing the functions we actually require.
 type VirtualNetworkClient interface {
 	GetVnic(context.Context, core.GetVnicRequest) (core.GetVnicResponse, error)
+	GetSubnet(context.Context, core.GetSubnetRequest) (core.GetSubnetResponse, error)
 }
 
 // WorkRequestClient wraps workrequests.WorkRequestClient exposing the functions we actually require.
@@ -53,6 +64,19 @@ type instancePoolCache struct {
 	poolCache            map[string]*core.InstancePool
 	instanceSummaryCache map[string]*[]core.InstanceSummary
 	unownedInstances     map[ocicommon.OciRef]bool
+	// unfulfilledErrors holds the unrecoverable work request error that left instances of an instance pool unfulfilled.
+	unfulfilledErrors map[string]string
+	// lastFullRefresh holds when the instances of an instance pool were last listed.
+	lastFullRefresh map[string]time.Time
+	// fullRefreshInterval is how often the instances of an instance pool that did not change are listed. The instances
+	// of every instance pool are listed on every rebuild if it is 0.
+	fullRefreshInterval time.Duration
+	// monitoring publishes failed launches to OCI Monitoring, it is nil if they are not published.
+	monitoring *ocicommon.MonitoringPublisher
+	// kubeClient records events about instance pools resized outside the autoscaler, they are only logged if it is nil.
+	kubeClient kubernetes.Interface
+	// mutationLimiter limits the updates and detaches in flight, they are not limited if it is nil.
+	mutationLimiter *ocicommon.MutationLimiter
 
 	computeManagementClient ComputeMgmtClient
 	computeClient           ComputeClient
@@ -65,6 +89,8 @@ func newInstancePoolCache(computeManagementClient ComputeMgmtClient, computeClie
 		poolCache:               map[string]*core.InstancePool{},
 		instanceSummaryCache:    map[string]*[]core.InstanceSummary{},
 		unownedInstances:        map[ocicommon.OciRef]bool{},
+		unfulfilledErrors:       map[string]string{},
+		lastFullRefresh:         map[string]time.Time{},
 		computeManagementClient: computeManagementClient,
 		computeClient:           computeClient,
 		virtualNetworkClient:    virtualNetworkClient,
@@ -80,71 +106,399 @@ func (c *instancePoolCache) InstancePools() map[string]*core.InstancePool {
 	return result
 }
 
+// rebuild refreshes the specified instance pools and their instances. Instance pools that did not change since their
+// instances were last listed are only refreshed every fullRefreshInterval, see changedInstancePools.
 func (c *instancePoolCache) rebuild(staticInstancePools map[string]*InstancePoolNodeGroup, cfg ocicommon.CloudConfig) error {
 	// Since we only support static instance-pools we don't need to worry about pruning.
 
+	changed := c.changedInstancePools(staticInstancePools, cfg, time.Now())
 	for id := range staticInstancePools {
-		getInstancePoolResp, err := c.computeManagementClient.GetInstancePool(context.Background(), core.GetInstancePoolRequest{
-			InstancePoolId: common.String(id),
-		})
-		if err != nil {
-			klog.Errorf("get instance pool %s failed: %v", id, err)
+		if !changed[id] {
+			klog.V(5).Infof("Instance pool %s did not change, skipping the listing of its instances", id)
+			continue
+		}
+		if err := c.refreshInstancePool(id, cfg); err != nil {
 			return err
 		}
-		klog.V(6).Infof("GetInstancePool() response %v", getInstancePoolResp.InstancePool)
+	}
 
-		c.setInstancePool(&getInstancePoolResp.InstancePool)
+	// Reset unowned instances cache.
+	c.unownedInstances = make(map[ocicommon.OciRef]bool)
 
-		var instanceSummaries []core.InstanceSummary
-		var page *string
-		for {
-			// OCI instance-pools do not contain individual instance objects so they must be fetched separately.
-			listInstancePoolInstances, err := c.computeManagementClient.ListInstancePoolInstances(context.Background(), core.ListInstancePoolInstancesRequest{
-				InstancePoolId: common.String(id),
-				CompartmentId:  common.String(cfg.Global.CompartmentID),
-				Page:           page,
-			})
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-			instanceSummaries = append(instanceSummaries, listInstancePoolInstances.Items...)
+// changedInstancePools returns the instance pools whose instances must be listed again. Listing the instance pools and
+// the instances of a compartment are a single paginated call each, while listing the instances of an instance pool is
+// one call per instance pool, so on large fleets the instances are only listed if:
+//   - the instance pool is not cached or its instances were last listed more than fullRefreshInterval ago,
+//   - the lifecycle state, size or instance configuration of the instance pool changed,
+//   - the instance pool is not RUNNING or any of its instances is not RUNNING, i.e. it is still changing,
+//   - any of its cached instances is no longer RUNNING in the compartment, e.g. it was terminated and OCI is
+//     replacing it without changing the instance pool,
+//   - it has fewer cached instances than its size and an instance that is not cached was created in the compartment
+//     since its instances were last listed.
+func (c *instancePoolCache) changedInstancePools(staticInstancePools map[string]*InstancePoolNodeGroup, cfg ocicommon.CloudConfig, now time.Time) map[string]bool {
+	changed := make(map[string]bool, len(staticInstancePools))
+	if c.fullRefreshInterval <= 0 {
+		for id := range staticInstancePools {
+			changed[id] = true
+		}
+		return changed
+	}
 
-			if page = listInstancePoolInstances.OpcNextPage; listInstancePoolInstances.OpcNextPage == nil {
-				break
+	c.mu.Lock()
+	// candidates maps the instance pools that may not have changed to their compartment.
+	candidates := map[string]string{}
+	for id := range staticInstancePools {
+		instancePool, ok := c.poolCache[id]
+		if !ok || now.Sub(c.lastFullRefresh[id]) >= c.fullRefreshInterval || !c.isSteady(id) {
+			changed[id] = true
+			continue
+		}
+		compartmentID := cfg.Global.CompartmentID
+		if instancePool.CompartmentId != nil {
+			compartmentID = *instancePool.CompartmentId
+		}
+		candidates[id] = compartmentID
+	}
+	c.mu.Unlock()
+
+	summaries := map[string]core.InstancePoolSummary{}
+	instances := map[string]map[string]core.Instance{}
+	for _, compartmentID := range candidates {
+		if _, ok := instances[compartmentID]; ok {
+			continue
+		}
+		instances[compartmentID] = nil
+		instancePools, err := c.listInstancePools(compartmentID)
+		if err != nil {
+			klog.Warningf("unable to list the instance pools of compartment %s, listing the instances of all its instance pools: %v", compartmentID, err)
+			continue
+		}
+		for _, instancePool := range instancePools {
+			if instancePool.Id != nil {
+				summaries[*instancePool.Id] = instancePool
 			}
 		}
-		c.setInstanceSummaries(id, &instanceSummaries)
-		// Compare instance pool's size with the latest number of InstanceSummaries. If found, look for unrecoverable
-		// errors such as quota or capacity issues in scaling pool.
-		if len(*c.instanceSummaryCache[id]) < *c.poolCache[id].Size {
-			klog.V(4).Infof("Instance pool %s has only %d instances created while requested count is %d. ",
-				*getInstancePoolResp.InstancePool.DisplayName, len(*c.instanceSummaryCache[id]), *c.poolCache[id].Size)
-
-			if getInstancePoolResp.LifecycleState != core.InstancePoolLifecycleStateRunning {
-				lastWorkRequest, err := c.lastStartedWorkRequest(*getInstancePoolResp.CompartmentId, id)
-
-				// The last started work request may be many minutes old depending on sync interval
-				// and exponential backoff time of OCI retried OCI operations.
-				if err == nil && *lastWorkRequest.OperationType == consts.OciInstancePoolLaunchOp &&
-					lastWorkRequest.Status == workrequests.WorkRequestSummaryStatusFailed {
-					unrecoverableErrorMsg := c.firstUnrecoverableErrorForWorkRequest(*lastWorkRequest.Id)
-					if unrecoverableErrorMsg != "" {
-						klog.V(4).Infof("Creating placeholder instances for %s.", *getInstancePoolResp.InstancePool.DisplayName)
-						for i := len(*c.instanceSummaryCache[id]); i < *c.poolCache[id].Size; i++ {
-							c.addUnfulfilledInstanceToCache(id, fmt.Sprintf("%s%s-%d", consts.InstanceIDUnfulfilled,
-								*getInstancePoolResp.InstancePool.Id, i), *getInstancePoolResp.InstancePool.CompartmentId,
-								fmt.Sprintf("%s-%d", *getInstancePoolResp.InstancePool.DisplayName, i))
-						}
+		compartmentInstances, err := c.listInstances(compartmentID)
+		if err != nil {
+			klog.Warningf("unable to list the instances of compartment %s, listing the instances of all its instance pools: %v", compartmentID, err)
+			continue
+		}
+		instances[compartmentID] = make(map[string]core.Instance, len(compartmentInstances))
+		for _, instance := range compartmentInstances {
+			if instance.Id != nil {
+				instances[compartmentID][*instance.Id] = instance
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cachedInstances := map[string]bool{}
+	for _, instanceSummaries := range c.instanceSummaryCache {
+		for _, instanceSummary := range *instanceSummaries {
+			if instanceSummary.Id != nil {
+				cachedInstances[*instanceSummary.Id] = true
+			}
+		}
+	}
+	for id, compartmentID := range candidates {
+		summary, ok := summaries[id]
+		instancePool := c.poolCache[id]
+		changed[id] = !ok ||
+			!strings.EqualFold(string(summary.LifecycleState), string(instancePool.LifecycleState)) ||
+			summary.Size == nil || instancePool.Size == nil || *summary.Size != *instancePool.Size ||
+			summary.InstanceConfigurationId == nil || instancePool.InstanceConfigurationId == nil ||
+			*summary.InstanceConfigurationId != *instancePool.InstanceConfigurationId ||
+			c.instancesChanged(id, instances[compartmentID], cachedInstances)
+	}
+	return changed
+}
+
+// instancesChanged returns true if the cached instances of the instance pool may not match its instances in the listed
+// instances of its compartment, or if the compartment could not be listed. The caller must hold mu.
+func (c *instancePoolCache) instancesChanged(instancePoolID string, instances map[string]core.Instance, cachedInstances map[string]bool) bool {
+	if instances == nil {
+		return true
+	}
+	instanceSummaries := *c.instanceSummaryCache[instancePoolID]
+	for _, instanceSummary := range instanceSummaries {
+		if instanceSummary.Id == nil {
+			return true
+		}
+		instance, ok := instances[*instanceSummary.Id]
+		if !ok || !strings.EqualFold(string(instance.LifecycleState), string(core.InstanceLifecycleStateRunning)) {
+			klog.V(4).Infof("Instance %s of instance pool %s is no longer running", *instanceSummary.Id, instancePoolID)
+			return true
+		}
+	}
+	if c.poolCache[instancePoolID].Size == nil || len(instanceSummaries) >= *c.poolCache[instancePoolID].Size {
+		return false
+	}
+	for id, instance := range instances {
+		if cachedInstances[id] || instance.TimeCreated == nil || !instance.TimeCreated.After(c.lastFullRefresh[instancePoolID]) {
+			continue
+		}
+		switch instance.LifecycleState {
+		case core.InstanceLifecycleStateTerminating, core.InstanceLifecycleStateTerminated:
+			continue
+		}
+		klog.V(4).Infof("Instance %s was created in the compartment of instance pool %s since its instances were listed", id, instancePoolID)
+		return true
+	}
+	return false
+}
+
+// isSteady returns true if the cached instance pool and all its cached instances are RUNNING. The caller must hold mu.
+func (c *instancePoolCache) isSteady(instancePoolID string) bool {
+	if !strings.EqualFold(string(c.poolCache[instancePoolID].LifecycleState), string(core.InstancePoolLifecycleStateRunning)) {
+		return false
+	}
+	instanceSummaries, ok := c.instanceSummaryCache[instancePoolID]
+	if !ok {
+		return false
+	}
+	for _, instanceSummary := range *instanceSummaries {
+		if instanceSummary.State == nil || instanceState(*instanceSummary.State) != cloudprovider.InstanceRunning {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidate makes the next rebuild list the instances of the instance pool, whether it changed or not.
+func (c *instancePoolCache) invalidate(instancePoolID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.lastFullRefresh, instancePoolID)
+}
+
+// refreshInstancePool refreshes the instance pool and lists all its instances.
+func (c *instancePoolCache) refreshInstancePool(id string, cfg ocicommon.CloudConfig) error {
+	getInstancePoolResp, err := c.computeManagementClient.GetInstancePool(context.Background(), core.GetInstancePoolRequest{
+		InstancePoolId: common.String(id),
+	})
+	if err != nil {
+		klog.Errorf("get instance pool %s failed: %v", id, err)
+		return err
+	}
+	klog.V(6).Infof("GetInstancePool() response %v", getInstancePoolResp.InstancePool)
+
+	c.detectExternalResize(&getInstancePoolResp.InstancePool)
+	c.setInstancePool(&getInstancePoolResp.InstancePool)
+
+	var instanceSummaries []core.InstanceSummary
+	var page *string
+	for {
+		// OCI instance-pools do not contain individual instance objects so they must be fetched separately.
+		listInstancePoolInstances, err := c.computeManagementClient.ListInstancePoolInstances(context.Background(), core.ListInstancePoolInstancesRequest{
+			InstancePoolId: common.String(id),
+			CompartmentId:  common.String(cfg.Global.CompartmentID),
+			Page:           page,
+		})
+		if err != nil {
+			return err
+		}
+
+		instanceSummaries = append(instanceSummaries, listInstancePoolInstances.Items...)
+
+		if page = listInstancePoolInstances.OpcNextPage; listInstancePoolInstances.OpcNextPage == nil {
+			break
+		}
+	}
+	c.setInstanceSummaries(id, &instanceSummaries)
+	previousUnfulfilledError := c.getUnfulfilledError(id)
+	c.setUnfulfilledError(id, "")
+	// Compare instance pool's size with the latest number of instances that are being launched or are running. If
+	// found, look for unrecoverable errors such as quota or capacity issues in scaling pool.
+	numInstances := countLaunchedInstances(instanceSummaries)
+	if numInstances < *c.poolCache[id].Size {
+		klog.V(4).Infof("Instance pool %s has only %d instances created while requested count is %d. ",
+			*getInstancePoolResp.InstancePool.DisplayName, numInstances, *c.poolCache[id].Size)
+
+		if getInstancePoolResp.LifecycleState != core.InstancePoolLifecycleStateRunning {
+			lastWorkRequest, err := c.lastStartedWorkRequest(*getInstancePoolResp.CompartmentId, id)
+
+			// The last started work request may be many minutes old depending on sync interval
+			// and exponential backoff time of OCI retried OCI operations.
+			if err == nil && *lastWorkRequest.OperationType == consts.OciInstancePoolLaunchOp &&
+				lastWorkRequest.Status == workrequests.WorkRequestSummaryStatusFailed {
+				unrecoverableErrorMsg := c.firstUnrecoverableErrorForWorkRequest(*lastWorkRequest.Id)
+				if unrecoverableErrorMsg != "" {
+					klog.V(4).Infof("Creating placeholder instances for %s.", *getInstancePoolResp.InstancePool.DisplayName)
+					c.setUnfulfilledError(id, unrecoverableErrorMsg)
+					if previousUnfulfilledError == "" {
+						c.monitoring.RecordFailedLaunches(id, *c.poolCache[id].Size-numInstances)
+					}
+					for i := numInstances; i < *c.poolCache[id].Size; i++ {
+						c.addUnfulfilledInstanceToCache(id, fmt.Sprintf("%s%s-%d", consts.InstanceIDUnfulfilled,
+							*getInstancePoolResp.InstancePool.Id, i), *getInstancePoolResp.InstancePool.CompartmentId,
+							fmt.Sprintf("%s-%d", *getInstancePoolResp.InstancePool.DisplayName, i))
 					}
 				}
 			}
 		}
 	}
 
-	// Reset unowned instances cache.
-	c.unownedInstances = make(map[ocicommon.OciRef]bool)
+	c.mu.Lock()
+	c.lastFullRefresh[id] = time.Now()
+	c.mu.Unlock()
+	return nil
+}
 
+// tagInstance adds the tags identifying instances launched by the autoscaler to the freeform tags of the instance,
+// unless it already has them.
+func (c *instancePoolCache) tagInstance(instanceID, clusterName, instancePoolID string) error {
+	getInstanceResp, err := c.computeClient.GetInstance(context.Background(), core.GetInstanceRequest{
+		InstanceId: common.String(instanceID),
+	})
+	if err != nil {
+		return err
+	}
+	freeformTags, changed := ocicommon.WithLaunchedInstanceTags(getInstanceResp.FreeformTags, clusterName, instancePoolID)
+	if !changed {
+		return nil
+	}
+
+	// Freeform tags are replaced as a whole, the If-Match header prevents overwriting concurrent changes.
+	resp, err := c.computeClient.UpdateInstance(context.Background(), core.UpdateInstanceRequest{
+		InstanceId: common.String(instanceID),
+		IfMatch:    getInstanceResp.Etag,
+		UpdateInstanceDetails: core.UpdateInstanceDetails{
+			FreeformTags: freeformTags,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(4).InfoS("Tagged instance", "instance", instanceID, "instancePool", instancePoolID,
+		"opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+	return nil
+}
+
+// launchedInstanceIDs returns the IDs of the cached instances of the instance pool that are being launched or are
+// running.
+func (c *instancePoolCache) launchedInstanceIDs(instancePoolID string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	instanceSummaries, ok := c.instanceSummaryCache[instancePoolID]
+	if !ok {
+		return nil
+	}
+	var instanceIDs []string
+	for _, instanceSummary := range *instanceSummaries {
+		if instanceSummary.Id == nil || instanceSummary.State == nil {
+			continue
+		}
+		if state := instanceState(*instanceSummary.State); state == cloudprovider.InstanceRunning || state == cloudprovider.InstanceCreating {
+			instanceIDs = append(instanceIDs, *instanceSummary.Id)
+		}
+	}
+	return instanceIDs
+}
+
+// countLaunchedInstances returns the number of instances that are being launched or are running. Instances that are
+// terminating, stopped or terminated do not count towards the size of an instance pool.
+func countLaunchedInstances(instanceSummaries []core.InstanceSummary) int {
+	count := 0
+	for _, instanceSummary := range instanceSummaries {
+		if instanceSummary.State == nil {
+			continue
+		}
+		if state := instanceState(*instanceSummary.State); state == cloudprovider.InstanceRunning || state == cloudprovider.InstanceCreating {
+			count++
+		}
+	}
+	return count
+}
+
+// listInstancePools lists all instance pools in the specified compartment.
+func (c *instancePoolCache) listInstancePools(compartmentID string) ([]core.InstancePoolSummary, error) {
+	var instancePools []core.InstancePoolSummary
+	var page *string
+	for {
+		listInstancePools, err := c.computeManagementClient.ListInstancePools(context.Background(), core.ListInstancePoolsRequest{
+			CompartmentId: common.String(compartmentID),
+			Page:          page,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		instancePools = append(instancePools, listInstancePools.Items...)
+
+		if page = listInstancePools.OpcNextPage; listInstancePools.OpcNextPage == nil {
+			break
+		}
+	}
+	return instancePools, nil
+}
+
+// listInstances lists all instances in the specified compartment.
+func (c *instancePoolCache) listInstances(compartmentID string) ([]core.Instance, error) {
+	var instances []core.Instance
+	var page *string
+	for {
+		listInstances, err := c.computeClient.ListInstances(context.Background(), core.ListInstancesRequest{
+			CompartmentId: common.String(compartmentID),
+			Page:          page,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, listInstances.Items...)
+
+		if page = listInstances.OpcNextPage; listInstances.OpcNextPage == nil {
+			break
+		}
+	}
+	return instances, nil
+}
+
+// createInstancePool creates a new instance pool, waits for it to become RUNNING and adds it to the cache.
+func (c *instancePoolCache) createInstancePool(details core.CreateInstancePoolDetails) (*core.InstancePool, error) {
+	resp, err := c.computeManagementClient.CreateInstancePool(context.Background(), core.CreateInstancePoolRequest{
+		CreateInstancePoolDetails: details,
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.V(2).InfoS("Created instance pool", "instancePool", *resp.InstancePool.Id, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+
+	// OCI rejects updates of an instance pool that is still PROVISIONING, so it cannot be scaled up before it is RUNNING.
+	ctx, cancel := context.WithTimeout(context.Background(), instancePoolProvisioningTimeout)
+	defer cancel()
+	if err := c.waitForState(ctx, *resp.InstancePool.Id, core.InstancePoolLifecycleStateRunning); err != nil {
+		return nil, errors.Wrapf(err, "instance-pool %s did not become %s (opc-request-id: %s)", *resp.InstancePool.Id,
+			core.InstancePoolLifecycleStateRunning, ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+	}
+	resp.InstancePool.LifecycleState = core.InstancePoolLifecycleStateRunning
+
+	c.setInstancePool(&resp.InstancePool)
+	c.setInstanceSummaries(*resp.InstancePool.Id, &[]core.InstanceSummary{})
+	return &resp.InstancePool, nil
+}
+
+// terminateInstancePool terminates the instance pool and removes it from the cache.
+func (c *instancePoolCache) terminateInstancePool(instancePoolID string) error {
+	resp, err := c.computeManagementClient.TerminateInstancePool(context.Background(), core.TerminateInstancePoolRequest{
+		InstancePoolId: common.String(instancePoolID),
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Terminated instance pool", "instancePool", instancePoolID, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.poolCache, instancePoolID)
+	delete(c.instanceSummaryCache, instancePoolID)
+	delete(c.lastFullRefresh, instancePoolID)
 	return nil
 }
 
@@ -157,22 +511,59 @@ func (c *instancePoolCache) addUnfulfilledInstanceToCache(instancePoolID, instan
 	})
 }
 
-// removeInstance tries to remove the instance from the specified instance pool. If the instance isn't in the array,
-// then it won't do anything removeInstance returns true if it actually removed the instance and reduced the size of
-// the instance pool.
-func (c *instancePoolCache) removeInstance(instancePool InstancePoolNodeGroup, instanceID string) bool {
+// removeInstances removes the specified instances from the instance pool and reduces the size of the instance pool
+// accordingly. Placeholders of unfulfilled instances are removed with a single reduction of the instance pool's target
+// size, while all other instances are detached and terminated one after another, since OCI rejects updates of an
+// instance pool while another one is in progress. The detaches hold a single slot of the mutation limiter. An error is
+// returned for every instance that could not be removed.
+func (c *instancePoolCache) removeInstances(instancePool InstancePoolNodeGroup, instanceIDs []string) error {
+	var unfulfilledIDs, instanceIDsToDetach []string
+	for _, instanceID := range instanceIDs {
+		if instanceID == "" {
+			return errors.New("instanceID is not set")
+		}
+		if strings.Contains(instanceID, consts.InstanceIDUnfulfilled) {
+			unfulfilledIDs = append(unfulfilledIDs, instanceID)
+		} else {
+			instanceIDsToDetach = append(instanceIDsToDetach, instanceID)
+		}
+	}
 
-	if instanceID == "" {
-		klog.Warning("instanceID is not set - skipping removal.")
-		return false
+	var errs []error
+	if len(unfulfilledIDs) > 0 {
+		// For unfulfilled instances, reduce the target size of the instance pool once and remove the placeholder instances from cache.
+		err := retryResizedExternally(func() error {
+			size, err := c.getSize(instancePool.Id())
+			if err != nil {
+				return err
+			}
+			// setSize updates the size of the instance pool in cache.
+			return c.setSize(instancePool.Id(), size-len(unfulfilledIDs))
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "unable to remove %d unfulfilled instance(s)", len(unfulfilledIDs)))
+		} else {
+			c.mu.Lock()
+			// Since we're removing the instances from cache, we don't need to expire the pool cache
+			for _, instanceID := range unfulfilledIDs {
+				c.removeInstanceSummaryFromCache(instancePool.Id(), instanceID)
+			}
+			c.mu.Unlock()
+		}
+	}
+
+	if len(instanceIDsToDetach) == 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	release, err := c.mutationLimiter.Acquire(context.Background(), instancePool.Id())
+	if err != nil {
+		errs = append(errs, errors.Wrapf(err, "unable to detach %d instance(s)", len(instanceIDsToDetach)))
+		return utilerrors.NewAggregate(errs)
 	}
+	defer release()
 
-	var err error
-	if strings.Contains(instanceID, consts.InstanceIDUnfulfilled) {
-		// For an unfulfilled instance, reduce the target size of the instance pool and remove the placeholder instance from cache.
-		err = c.setSize(instancePool.Id(), *c.poolCache[instancePool.Id()].Size-1)
-	} else {
-		_, err = c.computeManagementClient.DetachInstancePoolInstance(context.Background(), core.DetachInstancePoolInstanceRequest{
+	for _, instanceID := range instanceIDsToDetach {
+		resp, err := c.computeManagementClient.DetachInstancePoolInstance(context.Background(), core.DetachInstancePoolInstanceRequest{
 			InstancePoolId: common.String(instancePool.Id()),
 			DetachInstancePoolInstanceDetails: core.DetachInstancePoolInstanceDetails{
 				InstanceId:      common.String(instanceID),
@@ -180,20 +571,97 @@ func (c *instancePoolCache) removeInstance(instancePool InstancePoolNodeGroup, i
 				IsAutoTerminate: common.Bool(true),
 			},
 		})
-	}
+		if err != nil {
+			klog.ErrorS(err, "Error detaching instance from pool", "instance", instanceID, "instancePool", instancePool.Id(),
+				"opcRequestID", ocicommon.OpcRequestID(err))
+			errs = append(errs, errors.Wrapf(err, "unable to detach instance %s", instanceID))
+			continue
+		}
+		klog.V(2).InfoS("Detached instance from pool", "instance", instanceID, "instancePool", instancePool.Id(),
+			"opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
 
-	if err == nil {
 		c.mu.Lock()
-		// Decrease pool size in cache
+		// Decrease pool size in cache right away, so a refresh in between detaches does not mistake the detaches for
+		// an external resize.
 		c.poolCache[instancePool.Id()].Size = common.Int(*c.poolCache[instancePool.Id()].Size - 1)
 		// Since we're removing the instance from cache, we don't need to expire the pool cache
 		c.removeInstanceSummaryFromCache(instancePool.Id(), instanceID)
 		c.mu.Unlock()
-		return true
 	}
 
-	klog.Errorf("error detaching instance %s from pool: %v", instanceID, err)
-	return false
+	return utilerrors.NewAggregate(errs)
+}
+
+// terminatingInstanceIDs returns the IDs of the cached instances that are terminating.
+func (c *instancePoolCache) terminatingInstanceIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var instanceIDs []string
+	for _, instanceSummaries := range c.instanceSummaryCache {
+		instanceIDs = append(instanceIDs, filterTerminatingInstanceIDs(*instanceSummaries)...)
+	}
+	return instanceIDs
+}
+
+// instancePoolTerminatingInstanceIDs returns the IDs of the cached instances of the instance pool that are
+// terminating.
+func (c *instancePoolCache) instancePoolTerminatingInstanceIDs(instancePoolID string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if instanceSummaries, ok := c.instanceSummaryCache[instancePoolID]; ok {
+		return filterTerminatingInstanceIDs(*instanceSummaries)
+	}
+	return nil
+}
+
+func filterTerminatingInstanceIDs(instanceSummaries []core.InstanceSummary) []string {
+	var instanceIDs []string
+	for _, instanceSummary := range instanceSummaries {
+		if instanceSummary.Id != nil && instanceSummary.State != nil &&
+			strings.EqualFold(*instanceSummary.State, string(core.InstanceLifecycleStateTerminating)) {
+			instanceIDs = append(instanceIDs, *instanceSummary.Id)
+		}
+	}
+	return instanceIDs
+}
+
+// findInstanceInCache returns the details of the instance with the instance ID of the specified OciRef if it is one of
+// the cached instances of an instance pool, or nil otherwise. Unlike findInstanceByDetails, it does not call OCI.
+func (c *instancePoolCache) findInstanceInCache(ociInstance ocicommon.OciRef) *ocicommon.OciRef {
+	if ociInstance.InstanceID == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for instancePoolID, instanceSummaries := range c.instanceSummaryCache {
+		for _, instanceSummary := range *instanceSummaries {
+			if instanceSummary.Id == nil || *instanceSummary.Id != ociInstance.InstanceID {
+				continue
+			}
+			klog.V(4).Infof("%s is a cached member of %s", ociInstance.InstanceID, instancePoolID)
+			ociInstance.InstancePoolID = instancePoolID
+			if ociInstance.Name == "" && instanceSummary.DisplayName != nil {
+				ociInstance.Name = *instanceSummary.DisplayName
+			}
+			if instanceSummary.CompartmentId != nil {
+				ociInstance.CompartmentID = *instanceSummary.CompartmentId
+			}
+			if instanceSummary.AvailabilityDomain != nil {
+				// Availability domains are listed with their tenancy specific prefix, e.g. Uocm:PHX-AD-1.
+				adParts := strings.Split(*instanceSummary.AvailabilityDomain, ":")
+				ociInstance.AvailabilityDomain = adParts[len(adParts)-1]
+			}
+			if instanceSummary.Shape != nil {
+				ociInstance.Shape = *instanceSummary.Shape
+			}
+			return &ociInstance
+		}
+	}
+	return nil
 }
 
 // findInstanceByDetails attempts to find the given instance by details by searching
@@ -327,6 +795,24 @@ func (c *instancePoolCache) getInstancePoolWithoutLock(id string) (*core.Instanc
 	return instancePool, nil
 }
 
+// detectExternalResize returns the cached size of the instance pool and true if the instance pool was resized outside
+// the autoscaler, e.g. in the OCI console, i.e. its size differs from the size the autoscaler last saw or set. Such
+// resizes are reported as events, so the autoscaler adopts them instead of fighting them.
+func (c *instancePoolCache) detectExternalResize(instancePool *core.InstancePool) (int, bool) {
+	c.mu.Lock()
+	cached, ok := c.poolCache[*instancePool.Id]
+	c.mu.Unlock()
+	if !ok || cached.Size == nil || instancePool.Size == nil || *cached.Size == *instancePool.Size {
+		return 0, false
+	}
+
+	message := fmt.Sprintf("Instance pool %s was resized from %d to %d outside the autoscaler, adopting its new size",
+		*instancePool.Id, *cached.Size, *instancePool.Size)
+	klog.Warning(message)
+	ocicommon.RecordNodeGroupEvent(c.kubeClient, apiv1.EventTypeWarning, ocicommon.ResizedExternallyReason, message)
+	return *cached.Size, true
+}
+
 func (c *instancePoolCache) setInstancePool(np *core.InstancePool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -358,6 +844,26 @@ func (c *instancePoolCache) setInstanceSummaries(instancePoolID string, is *[]co
 	c.instanceSummaryCache[instancePoolID] = is
 }
 
+// setUnfulfilledError records the error that left instances of the specified instance pool unfulfilled.
+func (c *instancePoolCache) setUnfulfilledError(instancePoolID, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if message == "" {
+		delete(c.unfulfilledErrors, instancePoolID)
+		return
+	}
+	c.unfulfilledErrors[instancePoolID] = message
+}
+
+// getUnfulfilledError returns the error that left instances of the specified instance pool unfulfilled, if known.
+func (c *instancePoolCache) getUnfulfilledError(instancePoolID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.unfulfilledErrors[instancePoolID]
+}
+
 func (c *instancePoolCache) setSize(instancePoolID string, size int) error {
 
 	if instancePoolID == "" {
@@ -370,6 +876,17 @@ func (c *instancePoolCache) setSize(instancePoolID string, size int) error {
 	if err != nil {
 		return err
 	}
+	if cachedSize, resized := c.detectExternalResize(&getInstancePoolResp.InstancePool); resized {
+		c.mu.Lock()
+		c.poolCache[instancePoolID].Size = common.Int(*getInstancePoolResp.Size)
+		c.mu.Unlock()
+		return &ocicommon.ResizedExternallyError{
+			NodeGroupID:   instancePoolID,
+			CachedSize:    cachedSize,
+			ActualSize:    *getInstancePoolResp.Size,
+			RequestedSize: size,
+		}
+	}
 
 	isScaleUp := size > *getInstancePoolResp.Size
 	scaleDelta := int(math.Abs(float64(*getInstancePoolResp.Size - size)))
@@ -379,13 +896,15 @@ func (c *instancePoolCache) setSize(instancePoolID string, size int) error {
 		InstanceConfigurationId: getInstancePoolResp.InstanceConfigurationId,
 	}
 
-	_, err = c.computeManagementClient.UpdateInstancePool(context.Background(), core.UpdateInstancePoolRequest{
+	updateInstancePoolResp, err := c.updateInstancePool(core.UpdateInstancePoolRequest{
 		InstancePoolId:            common.String(instancePoolID),
 		UpdateInstancePoolDetails: updateDetails,
 	})
 	if err != nil {
 		return err
 	}
+	opcRequestID := ocicommon.ResponseOpcRequestID(updateInstancePoolResp.OpcRequestId)
+	klog.V(2).InfoS("Updated instance pool size", "instancePool", instancePoolID, "size", size, "opcRequestID", opcRequestID)
 
 	c.mu.Lock()
 	c.poolCache[instancePoolID].Size = common.Int(size)
@@ -404,15 +923,84 @@ func (c *instancePoolCache) setSize(instancePoolID string, size int) error {
 	// Wait for the number of Running instances in this pool to reach size
 	err = c.waitForRunningInstanceCount(ctx, size, instancePoolID, *getInstancePoolResp.CompartmentId)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "instance-pool %s did not reach size %d (opc-request-id: %s)", instancePoolID, size, opcRequestID)
 	}
 	// Allow an additional time for the pool State to reach Running
 	ctx, _ = context.WithTimeout(ctx, 10*time.Minute)
 	err = c.waitForState(ctx, instancePoolID, core.InstancePoolLifecycleStateRunning)
+	if err != nil {
+		return errors.Wrapf(err, "instance-pool %s did not become %s (opc-request-id: %s)", instancePoolID, core.InstancePoolLifecycleStateRunning, opcRequestID)
+	}
+
+	return nil
+}
+
+// updateInstancePool updates the instance pool while holding a slot of the mutation limiter.
+func (c *instancePoolCache) updateInstancePool(request core.UpdateInstancePoolRequest) (core.UpdateInstancePoolResponse, error) {
+	release, err := c.mutationLimiter.Acquire(context.Background(), *request.InstancePoolId)
+	if err != nil {
+		return core.UpdateInstancePoolResponse{}, err
+	}
+	defer release()
+	return c.computeManagementClient.UpdateInstancePool(context.Background(), request)
+}
+
+// setPlacementConfigurations replaces the placement configurations of the instance pool and sets its size without
+// waiting for instances to be launched.
+func (c *instancePoolCache) setPlacementConfigurations(instancePoolID string, placements []core.InstancePoolPlacementConfiguration, size int) error {
+	placementDetails := make([]core.UpdateInstancePoolPlacementConfigurationDetails, 0, len(placements))
+	for _, placement := range placements {
+		placementDetails = append(placementDetails, core.UpdateInstancePoolPlacementConfigurationDetails{
+			AvailabilityDomain:   placement.AvailabilityDomain,
+			PrimarySubnetId:      placement.PrimarySubnetId,
+			FaultDomains:         placement.FaultDomains,
+			SecondaryVnicSubnets: placement.SecondaryVnicSubnets,
+		})
+	}
+	resp, err := c.updateInstancePool(core.UpdateInstancePoolRequest{
+		InstancePoolId: common.String(instancePoolID),
+		UpdateInstancePoolDetails: core.UpdateInstancePoolDetails{
+			PlacementConfigurations: placementDetails,
+			Size:                    common.Int(size),
+		},
+	})
 	if err != nil {
 		return err
 	}
+	klog.V(2).InfoS("Updated instance pool placement configurations", "instancePool", instancePoolID,
+		"placementConfigurations", len(placements), "size", size, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if instancePool, ok := c.poolCache[instancePoolID]; ok {
+		instancePool.PlacementConfigurations = placements
+		instancePool.Size = common.Int(size)
+	}
+	return nil
+}
+
+// setInstanceConfiguration switches the instance pool to the specified instance configuration and sets its size without
+// waiting for instances to be launched.
+func (c *instancePoolCache) setInstanceConfiguration(instancePoolID, instanceConfigurationID string, size int) error {
+	resp, err := c.updateInstancePool(core.UpdateInstancePoolRequest{
+		InstancePoolId: common.String(instancePoolID),
+		UpdateInstancePoolDetails: core.UpdateInstancePoolDetails{
+			InstanceConfigurationId: common.String(instanceConfigurationID),
+			Size:                    common.Int(size),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Updated instance pool instance configuration", "instancePool", instancePoolID,
+		"instanceConfiguration", instanceConfigurationID, "size", size, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if instancePool, ok := c.poolCache[instancePoolID]; ok {
+		instancePool.InstanceConfigurationId = common.String(instanceConfigurationID)
+		instancePool.Size = common.Int(size)
+	}
 	return nil
 }
 
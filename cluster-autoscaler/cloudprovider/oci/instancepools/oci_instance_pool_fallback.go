@@ -0,0 +1,168 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// preemptibleUnavailableDuration is how long a preemptible instance pool with a fallback is reported unavailable
+	// after it ran out of capacity or its instances were preempted in a storm. It is also the window preemptions are
+	// counted in.
+	preemptibleUnavailableDuration = 10 * time.Minute
+	// preemptionStormThreshold is the number of instances of an instance pool preempted within
+	// preemptibleUnavailableDuration that make a preemption storm.
+	preemptionStormThreshold = 3
+)
+
+type preemptibleUnavailability struct {
+	reason string
+	until  time.Time
+}
+
+// preemptibleFallback remembers the preemptible instance pools with an on-demand fallback that ran out of capacity or
+// had their instances preempted in a storm, and the instances that were preempted recently.
+type preemptibleFallback struct {
+	mu sync.Mutex
+	// unavailable holds why and until when instance pools are unavailable.
+	unavailable map[string]preemptibleUnavailability
+	// preemptions holds when the preempted instances of each instance pool were first seen terminating.
+	preemptions map[string]map[string]time.Time
+}
+
+func newPreemptibleFallback() *preemptibleFallback {
+	return &preemptibleFallback{
+		unavailable: map[string]preemptibleUnavailability{},
+		preemptions: map[string]map[string]time.Time{},
+	}
+}
+
+// markUnavailable makes the instance pool unavailable for preemptibleUnavailableDuration.
+func (f *preemptibleFallback) markUnavailable(instancePoolID, reason string, now time.Time) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	until := now.Add(preemptibleUnavailableDuration)
+	f.unavailable[instancePoolID] = preemptibleUnavailability{reason: reason, until: until}
+	return until
+}
+
+// unavailability returns why and until when the instance pool is unavailable, if it is.
+func (f *preemptibleFallback) unavailability(instancePoolID string, now time.Time) (preemptibleUnavailability, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	unavailability, ok := f.unavailable[instancePoolID]
+	if !ok || !unavailability.until.After(now) {
+		delete(f.unavailable, instancePoolID)
+		return preemptibleUnavailability{}, false
+	}
+	return unavailability, true
+}
+
+// recordPreemptions records the preempted instances of the instance pool that were not seen before, and returns how
+// many of its instances were preempted within preemptibleUnavailableDuration.
+func (f *preemptibleFallback) recordPreemptions(instancePoolID string, instanceIDs []string, now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	preemptions, ok := f.preemptions[instancePoolID]
+	if !ok {
+		preemptions = map[string]time.Time{}
+		f.preemptions[instancePoolID] = preemptions
+	}
+	for _, instanceID := range instanceIDs {
+		if _, seen := preemptions[instanceID]; !seen {
+			preemptions[instanceID] = now
+		}
+	}
+	for instanceID, preemptedAt := range preemptions {
+		if !preemptedAt.Add(preemptibleUnavailableDuration).After(now) {
+			delete(preemptions, instanceID)
+		}
+	}
+	return len(preemptions)
+}
+
+// fallbackInstancePoolID returns the ID of the on-demand instance pool the instance pool falls back to, or the empty
+// string if it has none.
+func fallbackInstancePoolID(instancePool *core.InstancePool) string {
+	return instancePool.FreeformTags[consts.FallbackInstancePoolTag]
+}
+
+// CheckInstancePoolAvailable returns a PreemptibleUnavailableError if the instance-pool is a preemptible instance-pool
+// with a fallback that recently ran out of capacity or had its instances preempted in a storm.
+func (m *InstancePoolManagerImpl) CheckInstancePoolAvailable(ip InstancePoolNodeGroup) error {
+	if m.preemptibleFallback == nil || ip.theoretical {
+		return nil
+	}
+	instancePool, err := m.instancePoolCache.getInstancePool(ip.Id())
+	if err != nil {
+		return err
+	}
+	fallbackID := fallbackInstancePoolID(instancePool)
+	if fallbackID == "" {
+		return nil
+	}
+	unavailability, ok := m.preemptibleFallback.unavailability(ip.Id(), time.Now())
+	if !ok {
+		return nil
+	}
+	return &ocicommon.PreemptibleUnavailableError{
+		NodeGroupID:         ip.Id(),
+		FallbackNodeGroupID: fallbackID,
+		Reason:              unavailability.reason,
+		Until:               unavailability.until,
+	}
+}
+
+// markPreemptibleUnavailable makes the instance-pool unavailable if it has a fallback and the error of its scale-up
+// reports that it ran out of capacity.
+func (m *InstancePoolManagerImpl) markPreemptibleUnavailable(instancePoolID string, scaleUpErr error) {
+	if m.preemptibleFallback == nil || !ocicommon.IsOutOfCapacity(scaleUpErr.Error()) {
+		return
+	}
+	instancePool, err := m.instancePoolCache.getInstancePool(instancePoolID)
+	if err != nil || fallbackInstancePoolID(instancePool) == "" {
+		return
+	}
+	until := m.preemptibleFallback.markUnavailable(instancePoolID, "it ran out of capacity", time.Now())
+	klog.Warningf("preemptible instance pool %s ran out of capacity, falling back to instance pool %s until %s",
+		instancePoolID, fallbackInstancePoolID(instancePool), until.Format(time.RFC3339))
+}
+
+// recordPreemptions makes the preemptible instance-pools with a fallback unavailable whose instances are preempted in
+// a storm. Preempted instances are only seen while the instances of the instance-pool are listed, so storms are
+// detected sooner the more often they are, see full-refresh-interval.
+func (m *InstancePoolManagerImpl) recordPreemptions() {
+	if m.preemptibleFallback == nil {
+		return
+	}
+	now := time.Now()
+	for id := range m.instancePoolsSnapshot() {
+		instancePool, err := m.instancePoolCache.getInstancePool(id)
+		if err != nil || fallbackInstancePoolID(instancePool) == "" {
+			continue
+		}
+		preempted := m.preemptibleFallback.recordPreemptions(id, m.instancePoolCache.instancePoolTerminatingInstanceIDs(id), now)
+		if preempted < preemptionStormThreshold {
+			continue
+		}
+		if _, ok := m.preemptibleFallback.unavailability(id, now); ok {
+			continue
+		}
+		until := m.preemptibleFallback.markUnavailable(id, fmt.Sprintf("%d of its instances were preempted", preempted), now)
+		klog.Warningf("%d instances of preemptible instance pool %s were preempted, falling back to instance pool %s until %s",
+			preempted, id, fallbackInstancePoolID(instancePool), until.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreemptibleFallback(t *testing.T) {
+	const instancePoolID = "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	f := newPreemptibleFallback()
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		at          time.Time
+		instanceIDs []string
+		expected    int
+	}{
+		{name: "first preemptions", at: now, instanceIDs: []string{"a", "b"}, expected: 2},
+		{name: "preemptions seen again", at: now.Add(time.Minute), instanceIDs: []string{"a", "b", "c"}, expected: 3},
+		{name: "first preemptions expired", at: now.Add(preemptibleUnavailableDuration), instanceIDs: []string{"c"}, expected: 1},
+		{name: "all preemptions expired", at: now.Add(2 * preemptibleUnavailableDuration), expected: 0},
+	}
+	for _, tc := range testCases {
+		if got := f.recordPreemptions(instancePoolID, tc.instanceIDs, tc.at); got != tc.expected {
+			t.Errorf("%s: got %d preemptions ; wanted %d", tc.name, got, tc.expected)
+		}
+	}
+
+	until := f.markUnavailable(instancePoolID, "it ran out of capacity", now)
+	if _, ok := f.unavailability(instancePoolID, now.Add(time.Minute)); !ok {
+		t.Errorf("got available ; wanted unavailable until %v", until)
+	}
+	if _, ok := f.unavailability(instancePoolID, until); ok {
+		t.Errorf("got unavailable at %v ; wanted available", until)
+	}
+}
@@ -6,9 +6,11 @@ package instancepools
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
@@ -31,7 +33,9 @@ import (
 )
 
 var (
-	internalPollInterval            = 15 * time.Second
+	internalPollInterval = 15 * time.Second
+	// instancePoolProvisioningTimeout is how long a newly created instance-pool may take to become RUNNING.
+	instancePoolProvisioningTimeout = 10 * time.Minute
 	errInstanceInstancePoolNotFound = errors.New("instance-pool not found for instance")
 )
 
@@ -56,25 +60,85 @@ type InstancePoolManager interface {
 	SetInstancePoolSize(ip InstancePoolNodeGroup, size int) error
 	// DeleteInstances deletes the given instances. All instances must be controlled by the same InstancePool.
 	DeleteInstances(ip InstancePoolNodeGroup, instances []ocicommon.OciRef) error
+	// GetInstancePoolAvailableInstanceCount returns the number of instances the service limits still allow the InstancePool to launch.
+	GetInstancePoolAvailableInstanceCount(ip InstancePoolNodeGroup) (int, error)
+	// HasInstancePoolHostCapacity returns false if capacity reports show that the InstancePool cannot launch any instances.
+	HasInstancePoolHostCapacity(ip InstancePoolNodeGroup) (bool, error)
+	// CheckInstancePoolAvailable returns an error if the InstancePool is a preemptible InstancePool that falls back to
+	// another one while it is unavailable.
+	CheckInstancePoolAvailable(ip InstancePoolNodeGroup) error
+	// GetInstancePoolMaxNodeProvisionTime returns the max node provision time of the instance-pool, or 0 if it has
+	// no override.
+	GetInstancePoolMaxNodeProvisionTime(ip InstancePoolNodeGroup) (time.Duration, error)
+	// GetAutoprovisioningShapes returns the shapes new InstancePools can be autoprovisioned with.
+	GetAutoprovisioningShapes() ([]string, error)
+	// NewAutoprovisionedInstancePool returns a theoretical InstancePool with the specified shape.
+	NewAutoprovisionedInstancePool(shape string) (*InstancePoolNodeGroup, error)
+	// CreateInstancePool creates the theoretical InstancePool.
+	CreateInstancePool(ip InstancePoolNodeGroup) (*InstancePoolNodeGroup, error)
+	// DeleteInstancePool deletes the autoprovisioned InstancePool.
+	DeleteInstancePool(ip InstancePoolNodeGroup) error
+	// GetPricingModel returns the pricing model of the shape snapshot, or nil if there is none.
+	GetPricingModel() cloudprovider.PricingModel
 }
 
 // InstancePoolManagerImpl is the implementation of an instance-pool based autoscaler on OCI.
 type InstancePoolManagerImpl struct {
-	cfg                 *ocicommon.CloudConfig
-	ShapeGetter         ocicommon.ShapeGetter
+	cfg         *ocicommon.CloudConfig
+	ShapeGetter ocicommon.ShapeGetter
+	// instancePoolsMu guards staticInstancePools, which autoprovisioning modifies at runtime.
+	instancePoolsMu     sync.RWMutex
 	staticInstancePools map[string]*InstancePoolNodeGroup
 	lastRefresh         time.Time
 	// caches the instance pool and instance summary objects received from OCI.
 	// All interactions with OCI's API should go through the poolCache.
 	instancePoolCache *instancePoolCache
 	kubeClient        kubernetes.Interface
+	// kubeletReservation is deducted from the capacity of template nodes.
+	kubeletReservation *ocicommon.KubeletReservation
+	// limitsClient is only set if scale-up requests should be checked against service limits.
+	limitsClient ocicommon.LimitsClient
+	// capacityReportClient is only set if scale-up requests should be checked against compute capacity reports.
+	capacityReportClient ocicommon.CapacityReportClient
+	// autoprovisioningTemplates holds the autoprovisioning instance configurations keyed by the shape they launch.
+	autoprovisioningTemplates map[string]string
+	// maxNodeProvisionTimeByShape overrides the max node provision time of instance pools by shape.
+	maxNodeProvisionTimeByShape map[string]time.Duration
+	// monitoring publishes scale events to OCI Monitoring, it is nil if they are not published.
+	monitoring *ocicommon.MonitoringPublisher
+	// preTerminationHook is invoked before instances are terminated, it is nil if there is none.
+	preTerminationHook *ocicommon.PreTerminationHook
+	// preemptibleFallback holds the preemptible instance pools that fall back to another instance pool while they are
+	// unavailable, they never fall back if it is nil.
+	preemptibleFallback *preemptibleFallback
+	// taggedInstances are the instances that already have the tags of instances launched by the autoscaler.
+	taggedInstances map[string]bool
+	// availabilityDomainBackoff holds the availability domains instance pools ran out of capacity in, availability
+	// domains are not reselected if it is nil.
+	availabilityDomainBackoff *availabilityDomainBackoff
+	// validator reports misconfigured instance pools, instance pools are not validated if it is nil.
+	validator *ocicommon.NodeGroupValidator
+	// shapeClient lists the shape catalog the shapes of instance pools are validated against, they are not validated
+	// against it if it is nil.
+	shapeClient ocicommon.ShapeClient
+	// pricingModel prices nodes and pods by the shape snapshot, it is nil if there is no snapshot.
+	pricingModel cloudprovider.PricingModel
+	// credentials reloads rotated API keys, it is nil unless the clients authenticate with an API key.
+	credentials *ocicommon.ReloadingConfigurationProvider
+	// terminationNotices drains the nodes tainted with a termination notice, it is nil if they are not watched.
+	terminationNotices *ocicommon.TerminationNoticeWatcher
 }
 
 // CreateInstancePoolManager constructs the InstancePoolManager object.
 func CreateInstancePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions, kubeClient kubernetes.Interface) (InstancePoolManager, error) {
 
+	if os.Getenv(consts.OciUseFakeClientsEnvVar) == "true" {
+		return createFakeInstancePoolManager(cloudConfigPath, discoveryOpts, kubeClient)
+	}
+
 	var err error
 	var configProvider common.ConfigurationProvider
+	useAPIKey := false
 
 	clientConfig := common.CustomClientConfiguration{
 		RetryPolicy: ocicommon.NewRetryPolicy(),
@@ -98,6 +162,7 @@ func CreateInstancePoolManager(cloudConfigPath string, discoveryOpts cloudprovid
 	} else {
 		klog.Info("using default configuration provider")
 		configProvider = common.DefaultConfigProvider()
+		useAPIKey = true
 	}
 	providerRegion, _ := configProvider.Region()
 	klog.Infof("OCI provider region: %s ", providerRegion)
@@ -107,36 +172,128 @@ func CreateInstancePoolManager(cloudConfigPath string, discoveryOpts cloudprovid
 		return nil, err
 	}
 
-	computeMgmtClient, err := core.NewComputeManagementClientWithConfigurationProvider(configProvider)
+	var credentials *ocicommon.ReloadingConfigurationProvider
+	if useAPIKey {
+		credentials, err = ocicommon.NewReloadingConfigurationProvider(configProvider, cloudConfig.Global.CredentialsReloadInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load OCI credentials")
+		}
+		configProvider = credentials
+	}
+
+	rateLimiter := cloudConfig.RateLimiter()
+	circuitBreaker := cloudConfig.CircuitBreaker()
+	computeMgmtClient, computeClient, networkClient, workRequestClient, err := newClients(configProvider, clientConfig, rateLimiter, circuitBreaker)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create compute management client")
+		return nil, err
 	}
-	computeMgmtClient.SetCustomClientConfiguration(clientConfig)
 
-	computeClient, err := core.NewComputeClientWithConfigurationProvider(configProvider)
+	ipManager, err := newInstancePoolManager(cloudConfig, discoveryOpts, kubeClient,
+		&computeMgmtClient, &computeClient, &networkClient, &workRequestClient,
+		ocicommon.ShapeClientImpl{ComputeMgmtClient: computeMgmtClient, ComputeClient: computeClient})
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create compute client")
+		return nil, err
 	}
-	computeClient.SetCustomClientConfiguration(clientConfig)
 
-	networkClient, err := core.NewVirtualNetworkClientWithConfigurationProvider(configProvider)
+	if cloudConfig.Global.CheckServiceLimits {
+		limitsClient, err := ocicommon.NewLimitsClient(configProvider, clientConfig, rateLimiter, circuitBreaker)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create limits client")
+		}
+		ipManager.limitsClient = limitsClient
+	}
+
+	if cloudConfig.Global.CheckHostCapacity {
+		capacityReportClient, err := ocicommon.NewCapacityReportClient(configProvider, clientConfig, rateLimiter, circuitBreaker)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create capacity report client")
+		}
+		ipManager.capacityReportClient = capacityReportClient
+	}
+
+	monitoring, err := cloudConfig.MonitoringPublisher(configProvider, clientConfig, rateLimiter)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create virtual network client")
+		return nil, err
 	}
-	networkClient.SetCustomClientConfiguration(clientConfig)
+	ipManager.monitoring = monitoring
+	ipManager.instancePoolCache.monitoring = monitoring
 
-	workRequestClient, err := workrequests.NewWorkRequestClientWithConfigurationProvider(configProvider)
+	preTerminationHook, err := cloudConfig.PreTerminationHook(configProvider, clientConfig, rateLimiter)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create work request client")
+		return nil, err
+	}
+	ipManager.preTerminationHook = preTerminationHook
+	ipManager.credentials = credentials
+
+	// wait until we have an initial full poolCache.
+	err = wait.PollImmediateInfinite(
+		10*time.Second,
+		func() (bool, error) {
+			err := ipManager.Refresh()
+			if err != nil {
+				klog.Errorf("unable to fill cache on startup. Retrying: %+v", err)
+				return false, nil
+			}
+
+			return true, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return ipManager, nil
+}
+
+// newInstancePoolManager constructs an InstancePoolManagerImpl that uses the specified clients and registers the
+// instance pools given by the node group specs.
+func newInstancePoolManager(cloudConfig *ocicommon.CloudConfig, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions, kubeClient kubernetes.Interface,
+	computeMgmtClient ComputeMgmtClient, computeClient ComputeClient, networkClient VirtualNetworkClient, workRequestClient WorkRequestClient,
+	shapeClient ocicommon.ShapeClient) (*InstancePoolManagerImpl, error) {
+
+	kubeletReservation, err := cloudConfig.KubeletReservation()
+	if err != nil {
+		return nil, err
 	}
-	workRequestClient.SetCustomClientConfiguration(clientConfig)
+
+	flexShapeMemoryPerOcpu, err := cloudConfig.FlexShapeMemoryPerOcpuInGBs()
+	if err != nil {
+		return nil, err
+	}
+
+	maxNodeProvisionTimeByShape, err := cloudConfig.MaxNodeProvisionTimeByShape()
+	if err != nil {
+		return nil, err
+	}
+
+	shapeSnapshot, err := cloudConfig.ShapeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	shapeClient = shapeSnapshot.WrapShapeClient(shapeClient, cloudConfig.Global.PreferShapeSnapshot)
+
+	instancePoolCache := newInstancePoolCache(computeMgmtClient, computeClient, networkClient, workRequestClient)
+	instancePoolCache.fullRefreshInterval = cloudConfig.Global.FullRefreshInterval
+	if instancePoolCache.fullRefreshInterval == 0 && !cloudConfig.Global.DrainTerminatingNodes {
+		instancePoolCache.fullRefreshInterval = consts.DefaultFullRefreshInterval
+	}
+	instancePoolCache.kubeClient = kubeClient
+	instancePoolCache.mutationLimiter = cloudConfig.MutationLimiter()
 
 	ipManager := &InstancePoolManagerImpl{
 		cfg:                 cloudConfig,
 		staticInstancePools: map[string]*InstancePoolNodeGroup{},
-		ShapeGetter:         ocicommon.CreateShapeGetter(ocicommon.ShapeClientImpl{ComputeMgmtClient: computeMgmtClient, ComputeClient: computeClient}),
-		instancePoolCache:   newInstancePoolCache(&computeMgmtClient, &computeClient, &networkClient, &workRequestClient),
+		ShapeGetter:         ocicommon.CreateShapeGetter(shapeClient, flexShapeMemoryPerOcpu),
+		instancePoolCache:   instancePoolCache,
 		kubeClient:          kubeClient,
+		kubeletReservation:  kubeletReservation,
+
+		maxNodeProvisionTimeByShape: maxNodeProvisionTimeByShape,
+		taggedInstances:             map[string]bool{},
+		availabilityDomainBackoff:   newAvailabilityDomainBackoff(),
+		validator:                   ocicommon.NewNodeGroupValidator(kubeClient),
+		preemptibleFallback:         newPreemptibleFallback(),
+		shapeClient:                 shapeClient,
+		pricingModel:                shapeSnapshot.PricingModel(cloudConfig.Global.ReportVCPUs),
 	}
 
 	// Contains all the specs from the args that give us the pools.
@@ -152,34 +309,66 @@ func CreateInstancePoolManager(cloudConfigPath string, discoveryOpts cloudprovid
 		ipManager.staticInstancePools[ip.Id()] = ip
 	}
 
-	// wait until we have an initial full poolCache.
-	err = wait.PollImmediateInfinite(
-		10*time.Second,
-		func() (bool, error) {
-			err := ipManager.Refresh()
-			if err != nil {
-				klog.Errorf("unable to fill cache on startup. Retrying: %+v", err)
-				return false, nil
-			}
+	if cloudConfig.Global.DrainTerminatingNodes && cloudConfig.Global.TerminationNoticeTaint != "" && kubeClient != nil {
+		ipManager.terminationNotices = ocicommon.NewTerminationNoticeWatcher(kubeClient, cloudConfig.Global.TerminationNoticeTaint)
+	}
 
-			return true, nil
-		})
+	return ipManager, nil
+}
+
+// newClients creates the OCI clients used by the instance pool manager. All clients share the specified rate limiter
+// and get circuit breakers of their own.
+func newClients(configProvider common.ConfigurationProvider, clientConfig common.CustomClientConfiguration, rateLimiter *ocicommon.RateLimiter, circuitBreaker *ocicommon.CircuitBreaker) (core.ComputeManagementClient, core.ComputeClient, core.VirtualNetworkClient, workrequests.WorkRequestClient, error) {
+	computeMgmtClient, err := core.NewComputeManagementClientWithConfigurationProvider(configProvider)
 	if err != nil {
-		return nil, err
+		return core.ComputeManagementClient{}, core.ComputeClient{}, core.VirtualNetworkClient{}, workrequests.WorkRequestClient{}, errors.Wrap(err, "unable to create compute management client")
 	}
+	computeMgmtClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&computeMgmtClient.BaseClient)
+	rateLimiter.Apply(&computeMgmtClient.BaseClient)
+	ocicommon.LogRequestIDs(&computeMgmtClient.BaseClient)
 
-	return ipManager, nil
+	computeClient, err := core.NewComputeClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return core.ComputeManagementClient{}, core.ComputeClient{}, core.VirtualNetworkClient{}, workrequests.WorkRequestClient{}, errors.Wrap(err, "unable to create compute client")
+	}
+	computeClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&computeClient.BaseClient)
+	rateLimiter.Apply(&computeClient.BaseClient)
+	ocicommon.LogRequestIDs(&computeClient.BaseClient)
+
+	networkClient, err := core.NewVirtualNetworkClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return core.ComputeManagementClient{}, core.ComputeClient{}, core.VirtualNetworkClient{}, workrequests.WorkRequestClient{}, errors.Wrap(err, "unable to create virtual network client")
+	}
+	networkClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&networkClient.BaseClient)
+	rateLimiter.Apply(&networkClient.BaseClient)
+	ocicommon.LogRequestIDs(&networkClient.BaseClient)
+
+	workRequestClient, err := workrequests.NewWorkRequestClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return core.ComputeManagementClient{}, core.ComputeClient{}, core.VirtualNetworkClient{}, workrequests.WorkRequestClient{}, errors.Wrap(err, "unable to create work request client")
+	}
+	workRequestClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&workRequestClient.BaseClient)
+	rateLimiter.Apply(&workRequestClient.BaseClient)
+	ocicommon.LogRequestIDs(&workRequestClient.BaseClient)
+
+	return computeMgmtClient, computeClient, networkClient, workRequestClient, nil
 }
 
-// instancePoolFromArg parses a instancepool spec represented in the form of `<minSize>:<maxSize>:<ocid>` and produces an instance pool wrapper spec object
+// instancePoolFromArg parses a instancepool spec represented in the form of `<minSize>:<maxSize>:<ocid>` and produces an instance pool wrapper spec object.
+// The spec may be followed by `:<instance-configuration-ocid>[,<instance-configuration-ocid>...]` to list the instance
+// configurations the instance pool falls back to in order when it is out of capacity.
 func instancePoolFromArg(value string) (*InstancePoolNodeGroup, error) {
 
 	if !strings.Contains(value, consts.OciInstancePoolResourceIdent) {
 		return nil, fmt.Errorf("instance pool manager does not work with resources of type: %s", value)
 	}
 
-	tokens := strings.SplitN(value, ":", 3)
-	if len(tokens) != 3 || !strings.HasPrefix(tokens[2], "ocid") {
+	tokens := strings.SplitN(value, ":", 4)
+	if len(tokens) < 3 || !strings.HasPrefix(tokens[2], "ocid") {
 		return nil, fmt.Errorf("incorrect instance configuration: %s", value)
 	}
 
@@ -198,6 +387,17 @@ func instancePoolFromArg(value string) (*InstancePoolNodeGroup, error) {
 
 	spec.id = tokens[2]
 
+	// An optional comma separated list of instance configurations the instance pool falls back to in order.
+	if len(tokens) == 4 {
+		for _, instanceConfigurationID := range strings.Split(tokens[3], ",") {
+			if !strings.HasPrefix(instanceConfigurationID, "ocid") ||
+				!strings.Contains(instanceConfigurationID, consts.OciInstanceConfigurationResourceIdent) {
+				return nil, fmt.Errorf("incorrect instance configuration %q in: %s", instanceConfigurationID, value)
+			}
+			spec.instanceConfigurationIDs = append(spec.instanceConfigurationIDs, instanceConfigurationID)
+		}
+	}
+
 	klog.Infof("static instance pool wrapper spec constructed: %+v", spec)
 
 	return spec, nil
@@ -205,6 +405,10 @@ func instancePoolFromArg(value string) (*InstancePoolNodeGroup, error) {
 
 // Refresh triggers refresh of cached resources.
 func (m *InstancePoolManagerImpl) Refresh() error {
+	if err := m.monitoring.Flush(); err != nil {
+		klog.Warningf("unable to publish scale events: %v", err)
+	}
+
 	if m.lastRefresh.Add(m.cfg.Global.RefreshInterval).After(time.Now()) {
 		return nil
 	}
@@ -217,23 +421,80 @@ func (m *InstancePoolManagerImpl) forceRefresh() error {
 		return errors.New("instance pool manager does have a required config")
 	}
 	m.ShapeGetter.Refresh()
-	err := m.instancePoolCache.rebuild(m.staticInstancePools, *m.cfg)
+	if err := m.discoverAutoprovisionedInstancePools(); err != nil {
+		return err
+	}
+	err := m.instancePoolCache.rebuild(m.validInstancePools(m.instancePoolsSnapshot()), *m.cfg)
 	if err != nil {
 		return err
 	}
 
+	// Instances that are terminating while still in their instance pool were not detached by the autoscaler, e.g. they
+	// were preempted. Their pods are moved right away while the instance pool launches replacements to keep its size.
+	if m.cfg.Global.DrainTerminatingNodes && m.kubeClient != nil {
+		if err := ocicommon.DrainTerminatingNodes(m.kubeClient, m.instancePoolCache.terminatingInstanceIDs()); err != nil {
+			klog.Errorf("unable to drain the nodes of terminating instances: %v", err)
+		}
+	}
+
+	m.recordPreemptions()
+	m.reportInstancePoolHealth()
+
+	if m.cfg.Global.TagLaunchedInstances {
+		m.tagLaunchedInstances()
+	}
+
 	m.lastRefresh = time.Now()
 	klog.Infof("Refreshed instance-pool list, next refresh after %v", m.lastRefresh.Add(m.cfg.Global.RefreshInterval))
 	return nil
 }
 
+// reportInstancePoolHealth reports the target size and the instances of the cached instance-pools as metrics.
+func (m *InstancePoolManagerImpl) reportInstancePoolHealth() {
+	instancePools := m.instancePoolsSnapshot()
+	healthByInstancePool := make(map[string]ocicommon.NodeGroupHealth, len(instancePools))
+	for id, ip := range instancePools {
+		// Misconfigured and theoretical instance-pools are not cached.
+		size, err := m.GetInstancePoolSize(*ip)
+		if err != nil {
+			continue
+		}
+		instances, err := m.GetInstancePoolNodes(*ip)
+		if err != nil {
+			continue
+		}
+		healthByInstancePool[id] = ocicommon.NewNodeGroupHealth(size, instances)
+	}
+	ocicommon.ReportNodeGroupHealth(healthByInstancePool)
+}
+
+// tagLaunchedInstances adds the tags identifying instances launched by the autoscaler to the instances of all
+// instance-pools. Instances that could not be tagged are tried again on the next refresh.
+func (m *InstancePoolManagerImpl) tagLaunchedInstances() {
+	// Only the instances that are still in their instance pool are remembered.
+	taggedInstances := map[string]bool{}
+	for id := range m.instancePoolsSnapshot() {
+		for _, instanceID := range m.instancePoolCache.launchedInstanceIDs(id) {
+			if !m.taggedInstances[instanceID] {
+				if err := m.instancePoolCache.tagInstance(instanceID, m.cfg.Global.ClusterName, id); err != nil {
+					klog.Warningf("unable to tag instance %s of instance pool %s: %v", instanceID, id, err)
+					continue
+				}
+			}
+			taggedInstances[instanceID] = true
+		}
+	}
+	m.taggedInstances = taggedInstances
+}
+
 func (m *InstancePoolManagerImpl) forceRefreshInstancePool(instancePoolID string) error {
 
 	if m.cfg == nil {
 		return errors.New("instance pool manager does have a required config")
 	}
 
-	if instancePoolCache, found := m.staticInstancePools[instancePoolID]; found {
+	if instancePoolCache := m.getStaticInstancePool(instancePoolID); instancePoolCache != nil {
+		m.instancePoolCache.invalidate(instancePoolID)
 		return m.instancePoolCache.rebuild(map[string]*InstancePoolNodeGroup{instancePoolID: instancePoolCache}, *m.cfg)
 	}
 	return errors.New("instance pool not found")
@@ -241,11 +502,16 @@ func (m *InstancePoolManagerImpl) forceRefreshInstancePool(instancePoolID string
 
 // Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
 func (m *InstancePoolManagerImpl) Cleanup() error {
+	m.credentials.Stop()
+	m.terminationNotices.Stop()
 	return nil
 }
 
 // GetInstancePools returns list of registered InstancePools.
 func (m *InstancePoolManagerImpl) GetInstancePools() []*InstancePoolNodeGroup {
+	m.instancePoolsMu.RLock()
+	defer m.instancePoolsMu.RUnlock()
+
 	var instancePools []*InstancePoolNodeGroup
 	for _, np := range m.staticInstancePools {
 		instancePools = append(instancePools, np)
@@ -253,6 +519,26 @@ func (m *InstancePoolManagerImpl) GetInstancePools() []*InstancePoolNodeGroup {
 	return instancePools
 }
 
+// getStaticInstancePool returns the registered InstancePool with the specified ID, or nil if there is none.
+func (m *InstancePoolManagerImpl) getStaticInstancePool(id string) *InstancePoolNodeGroup {
+	m.instancePoolsMu.RLock()
+	defer m.instancePoolsMu.RUnlock()
+
+	return m.staticInstancePools[id]
+}
+
+// instancePoolsSnapshot returns a copy of the registered InstancePools that is safe to iterate over.
+func (m *InstancePoolManagerImpl) instancePoolsSnapshot() map[string]*InstancePoolNodeGroup {
+	m.instancePoolsMu.RLock()
+	defer m.instancePoolsMu.RUnlock()
+
+	snapshot := make(map[string]*InstancePoolNodeGroup, len(m.staticInstancePools))
+	for id, ip := range m.staticInstancePools {
+		snapshot[id] = ip
+	}
+	return snapshot
+}
+
 // GetInstancePoolNodes returns InstancePool nodes that are not in a terminal state.
 func (m *InstancePoolManagerImpl) GetInstancePoolNodes(ip InstancePoolNodeGroup) ([]cloudprovider.Instance, error) {
 
@@ -266,30 +552,19 @@ func (m *InstancePoolManagerImpl) GetInstancePoolNodes(ip InstancePoolNodeGroup)
 	var providerInstances []cloudprovider.Instance
 	for _, instance := range *instanceSummaries {
 		status := &cloudprovider.InstanceStatus{}
-		switch *instance.State {
-		case string(core.InstanceLifecycleStateStopped), string(core.InstanceLifecycleStateTerminated):
-			klog.V(4).Infof("skipping instance is in stopped/terminated state: %q", *instance.Id)
-		case string(core.InstanceLifecycleStateRunning):
-			status.State = cloudprovider.InstanceRunning
-		case string(core.InstanceLifecycleStateCreatingImage):
-			status.State = cloudprovider.InstanceCreating
-		case string(core.InstanceLifecycleStateStarting):
-			status.State = cloudprovider.InstanceCreating
-		case string(core.InstanceLifecycleStateMoving):
-			status.State = cloudprovider.InstanceCreating
-		case string(core.InstanceLifecycleStateProvisioning):
-			status.State = cloudprovider.InstanceCreating
-		case string(core.InstanceLifecycleStateTerminating):
-			status.State = cloudprovider.InstanceDeleting
-		case string(core.InstanceLifecycleStateStopping):
-			status.State = cloudprovider.InstanceDeleting
-		case consts.InstanceStateUnfulfilled:
+		if *instance.State == consts.InstanceStateUnfulfilled {
 			status.State = cloudprovider.InstanceCreating
 			status.ErrorInfo = &cloudprovider.InstanceErrorInfo{
 				ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
 				ErrorCode:    consts.InstanceStateUnfulfilled,
 				ErrorMessage: "OCI cannot provision additional instances for this instance pool. Review quota and/or capacity.",
 			}
+			// Placeholders are only created for unrecoverable errors, prefer reporting the specific one.
+			if message := m.instancePoolCache.getUnfulfilledError(ip.Id()); message != "" {
+				status.ErrorInfo.ErrorMessage = message
+			}
+		} else if status.State = instanceState(*instance.State); status.State == 0 {
+			klog.V(4).Infof("skipping instance in state %q: %q", *instance.State, *instance.Id)
 		}
 
 		// Instance not in a terminal or unknown state, ok to add.
@@ -304,6 +579,24 @@ func (m *InstancePoolManagerImpl) GetInstancePoolNodes(ip InstancePoolNodeGroup)
 	return providerInstances, nil
 }
 
+// instanceState returns the cloudprovider.InstanceState of an instance pool instance in the specified lifecycle state,
+// or 0 for stopped, terminated and unknown instances which should not be reported. Instances are only Running once
+// their lifecycle state is RUNNING, so that provisioning timeouts apply to instances that are still being launched.
+// Instance pools report lifecycle states in title case (e.g. "Running"), so they are compared case-insensitively.
+func instanceState(lifecycleState string) cloudprovider.InstanceState {
+	switch core.InstanceLifecycleStateEnum(strings.ToUpper(lifecycleState)) {
+	case core.InstanceLifecycleStateRunning:
+		return cloudprovider.InstanceRunning
+	case core.InstanceLifecycleStateProvisioning, core.InstanceLifecycleStateStarting,
+		core.InstanceLifecycleStateCreatingImage, core.InstanceLifecycleStateMoving:
+		return cloudprovider.InstanceCreating
+	case core.InstanceLifecycleStateTerminating, core.InstanceLifecycleStateStopping:
+		return cloudprovider.InstanceDeleting
+	default:
+		return 0
+	}
+}
+
 // GetInstancePoolForInstance returns InstancePool to which the given instance belongs. If
 // PoolID is not set on the specified OciRef, we will look for a match.
 func (m *InstancePoolManagerImpl) GetInstancePoolForInstance(instanceDetails ocicommon.OciRef) (*InstancePoolNodeGroup, error) {
@@ -319,17 +612,23 @@ func (m *InstancePoolManagerImpl) GetInstancePoolForInstance(instanceDetails oci
 		instanceDetails.CompartmentID = m.cfg.Global.CompartmentID
 	}
 
-	if ip, ok := m.staticInstancePools[instanceDetails.InstancePoolID]; ok {
+	if ip := m.getStaticInstancePool(instanceDetails.InstancePoolID); ip != nil {
 		return ip, nil
 	}
-	// This instance is not in the cache.
-	// Try to resolve the pool ID and other details, though it may not be a member of an instance-pool we manage.
-	foundInstanceDetails, err := m.instancePoolCache.findInstanceByDetails(instanceDetails)
-	if err != nil || foundInstanceDetails == nil || foundInstanceDetails.InstancePoolID == "" {
-		if m.cfg.Global.UseNonMemberAnnotation && err == errInstanceInstancePoolNotFound {
-			_ = ocicommon.AnnotateNode(m.kubeClient, instanceDetails.Name, consts.OciInstancePoolIDAnnotation, consts.OciInstancePoolIDNonPoolMember)
+	// Nodes joined by custom bootstrap images may lack the instance pool labels and annotations, but their provider ID
+	// still identifies them among the cached instances of the instance pools.
+	foundInstanceDetails := m.instancePoolCache.findInstanceInCache(instanceDetails)
+	if foundInstanceDetails == nil {
+		// This instance is not in the cache.
+		// Try to resolve the pool ID and other details, though it may not be a member of an instance-pool we manage.
+		var err error
+		foundInstanceDetails, err = m.instancePoolCache.findInstanceByDetails(instanceDetails)
+		if err != nil || foundInstanceDetails == nil || foundInstanceDetails.InstancePoolID == "" {
+			if m.cfg.Global.UseNonMemberAnnotation && err == errInstanceInstancePoolNotFound {
+				_ = ocicommon.AnnotateNode(m.kubeClient, instanceDetails.Name, consts.OciInstancePoolIDAnnotation, consts.OciInstancePoolIDNonPoolMember)
+			}
+			return nil, err
 		}
-		return nil, err
 	}
 
 	// Optionally annotate & label the node so that it does not need to be searched for in subsequent iterations.
@@ -342,12 +641,16 @@ func (m *InstancePoolManagerImpl) GetInstancePoolForInstance(instanceDetails oci
 	_ = ocicommon.LabelNode(m.kubeClient, foundInstanceDetails.Name, apiv1.LabelInstanceTypeStable, foundInstanceDetails.Shape)
 	_ = ocicommon.SetNodeProviderID(m.kubeClient, foundInstanceDetails.Name, foundInstanceDetails.InstanceID)
 
-	return m.staticInstancePools[foundInstanceDetails.InstancePoolID], nil
+	return m.getStaticInstancePool(foundInstanceDetails.InstancePoolID), nil
 }
 
 // GetInstancePoolTemplateNode returns a template node for the InstancePool.
 func (m *InstancePoolManagerImpl) GetInstancePoolTemplateNode(ip InstancePoolNodeGroup) (*apiv1.Node, error) {
 
+	if ip.theoretical {
+		return m.buildNodeFromTemplate(m.theoreticalInstancePool(ip.instanceConfigurationID))
+	}
+
 	instancePool, err := m.instancePoolCache.getInstancePool(ip.Id())
 	if err != nil {
 		return nil, err
@@ -369,14 +672,22 @@ func (m *InstancePoolManagerImpl) GetInstancePoolSize(ip InstancePoolNodeGroup)
 // SetInstancePoolSize sets instance-pool size.
 func (m *InstancePoolManagerImpl) SetInstancePoolSize(np InstancePoolNodeGroup, size int) error {
 	klog.Infof("SetInstancePoolSize (%d) called on instance pool %s", size, np.Id())
-
-	setSizeErr := m.instancePoolCache.setSize(np.Id(), size)
+	previousSize, _ := m.instancePoolCache.getSize(np.Id())
+	setSizeErr := m.setInstancePoolSizeWithFallback(np, size)
 	klog.V(5).Infof("SetInstancePoolSize was called: refreshing instance pool cache")
 	// refresh instance pool cache after update (regardless if there was an error or not)
 	_ = m.forceRefreshInstancePool(np.Id())
 	if setSizeErr != nil {
+		if size > previousSize {
+			m.markPreemptibleUnavailable(np.Id(), setSizeErr)
+		}
 		return setSizeErr
 	}
+	if size > previousSize {
+		m.monitoring.RecordScaleUp(np.Id(), size-previousSize)
+	} else {
+		m.monitoring.RecordScaleDown(np.Id(), previousSize-size)
+	}
 
 	// Interface says this function should wait until node group size is updated.
 
@@ -387,21 +698,292 @@ func (m *InstancePoolManagerImpl) SetInstancePoolSize(np InstancePoolNodeGroup,
 	return nil
 }
 
+// setInstancePoolSizeWithFallback sets the size of the instance-pool. When the instance-pool runs out of capacity
+// while scaling up, it is switched to the next of its instance configurations and scaled up again, until one of them
+// can launch the instances or none are left. An empty instance-pool is switched back to its preferred instance
+// configuration before it is scaled up.
+func (m *InstancePoolManagerImpl) setInstancePoolSizeWithFallback(np InstancePoolNodeGroup, size int) error {
+	if len(np.instanceConfigurationIDs) == 0 {
+		return m.setInstancePoolSizeInAvailabilityDomains(np.Id(), size)
+	}
+
+	instancePool, err := m.instancePoolCache.getInstancePool(np.Id())
+	if err != nil {
+		return err
+	}
+	if *instancePool.Size == 0 && size > 0 && *instancePool.InstanceConfigurationId != np.instanceConfigurationIDs[0] {
+		klog.Infof("switching empty instance pool %s back to instance configuration %s", np.Id(), np.instanceConfigurationIDs[0])
+		if err := m.instancePoolCache.setInstanceConfiguration(np.Id(), np.instanceConfigurationIDs[0], 0); err != nil {
+			return err
+		}
+	}
+
+	err = m.setInstancePoolSizeInAvailabilityDomains(np.Id(), size)
+	for err != nil && ocicommon.IsOutOfCapacity(err.Error()) {
+		if refreshErr := m.forceRefreshInstancePool(np.Id()); refreshErr != nil {
+			return err
+		}
+		instancePool, getErr := m.instancePoolCache.getInstancePool(np.Id())
+		if getErr != nil {
+			return err
+		}
+		next := nextInstanceConfiguration(np.instanceConfigurationIDs, *instancePool.InstanceConfigurationId)
+		if next == "" {
+			return err
+		}
+		instanceSummaries, getErr := m.instancePoolCache.getInstanceSummaries(np.Id())
+		if getErr != nil {
+			return err
+		}
+
+		// Shrink the instance pool to the instances it launched so the next instance configuration launches the rest.
+		launched := countLaunchedInstances(*instanceSummaries)
+		klog.Warningf("instance pool %s is out of capacity for instance configuration %s, falling back to %s: %v",
+			np.Id(), *instancePool.InstanceConfigurationId, next, err)
+		if switchErr := m.instancePoolCache.setInstanceConfiguration(np.Id(), next, launched); switchErr != nil {
+			return errors.Wrapf(switchErr, "unable to fall back to instance configuration %s after: %v", next, err)
+		}
+		err = m.setInstancePoolSizeInAvailabilityDomains(np.Id(), size)
+	}
+	return err
+}
+
+// setInstancePoolSizeInAvailabilityDomains sets the size of the instance-pool. When an instance-pool with several
+// placement configurations runs out of capacity while scaling up, the availability domains that ran out are backed
+// off and the instance-pool is restricted to the remaining ones and scaled up again, until one of them can launch the
+// instances or none are left. Scale-ups place instances in all availability domains that are not backed off, or in the
+// first of them if the placement policy of the instance-pool is packed.
+func (m *InstancePoolManagerImpl) setInstancePoolSizeInAvailabilityDomains(instancePoolID string, size int) error {
+	if m.availabilityDomainBackoff == nil {
+		return m.instancePoolCache.setSize(instancePoolID, size)
+	}
+
+	instancePool, err := m.instancePoolCache.getInstancePool(instancePoolID)
+	if err != nil {
+		return err
+	}
+	if size > *instancePool.Size {
+		if err := m.placeInAvailableAvailabilityDomains(instancePool); err != nil {
+			return err
+		}
+	}
+
+	err = m.instancePoolCache.setSize(instancePoolID, size)
+	for err != nil && ocicommon.IsOutOfCapacity(err.Error()) {
+		if refreshErr := m.forceRefreshInstancePool(instancePoolID); refreshErr != nil {
+			return err
+		}
+		instancePool, getErr := m.instancePoolCache.getInstancePool(instancePoolID)
+		if getErr != nil {
+			return err
+		}
+		if len(m.availabilityDomainBackoff.originalPlacements(instancePool)) <= 1 {
+			return err
+		}
+		instanceSummaries, getErr := m.instancePoolCache.getInstanceSummaries(instancePoolID)
+		if getErr != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, availabilityDomain := range shortAvailabilityDomains(instancePool.PlacementConfigurations, *instanceSummaries) {
+			backoffUntil := m.availabilityDomainBackoff.backoff(*instancePool.InstanceConfigurationId, availabilityDomain, now)
+			klog.Warningf("instance pool %s is out of capacity in %s, not using it until %s: %v",
+				instancePoolID, availabilityDomain, backoffUntil.Format(time.RFC3339), err)
+		}
+		available := placementsForPolicy(instancePool, m.availabilityDomainBackoff.availablePlacements(instancePool, now))
+		if len(available) == 0 {
+			return err
+		}
+
+		// Shrink the instance pool to the instances it launched so the remaining availability domains launch the rest.
+		m.availabilityDomainBackoff.restrict(instancePool)
+		if placeErr := m.instancePoolCache.setPlacementConfigurations(instancePoolID, available, countLaunchedInstances(*instanceSummaries)); placeErr != nil {
+			return errors.Wrapf(placeErr, "unable to restrict instance pool to %d availability domain(s) after: %v", len(available), err)
+		}
+		err = m.instancePoolCache.setSize(instancePoolID, size)
+	}
+	return err
+}
+
+// placeInAvailableAvailabilityDomains updates the placement configurations of the instance-pool to the availability
+// domains that are not backed off, restoring availability domains whose backoff expired, or to the first of them if
+// the instance-pool is packed. The placement configurations are left as they are if every availability domain is
+// backed off.
+func (m *InstancePoolManagerImpl) placeInAvailableAvailabilityDomains(instancePool *core.InstancePool) error {
+	original := m.availabilityDomainBackoff.originalPlacements(instancePool)
+	available := placementsForPolicy(instancePool, m.availabilityDomainBackoff.availablePlacements(instancePool, time.Now()))
+	if len(available) == 0 || samePlacements(available, instancePool.PlacementConfigurations) {
+		return nil
+	}
+
+	m.availabilityDomainBackoff.restrict(instancePool)
+	if err := m.instancePoolCache.setPlacementConfigurations(*instancePool.Id, available, *instancePool.Size); err != nil {
+		return err
+	}
+	if len(available) == len(original) {
+		m.availabilityDomainBackoff.unrestrict(*instancePool.Id)
+	}
+	return nil
+}
+
+// samePlacements returns true if both placement configurations are in the same availability domains.
+func samePlacements(a, b []core.InstancePoolPlacementConfiguration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].AvailabilityDomain == nil || b[i].AvailabilityDomain == nil || *a[i].AvailabilityDomain != *b[i].AvailabilityDomain {
+			return false
+		}
+	}
+	return true
+}
+
+// nextInstanceConfiguration returns the instance configuration that follows current in instanceConfigurationIDs, or
+// the empty string if current is the last one. The first instance configuration follows an unknown one.
+func nextInstanceConfiguration(instanceConfigurationIDs []string, current string) string {
+	for i, instanceConfigurationID := range instanceConfigurationIDs {
+		if instanceConfigurationID == current {
+			if i+1 < len(instanceConfigurationIDs) {
+				return instanceConfigurationIDs[i+1]
+			}
+			return ""
+		}
+	}
+	return instanceConfigurationIDs[0]
+}
+
 // DeleteInstances deletes the given instances. All instances must be controlled by the same instance-pool.
 func (m *InstancePoolManagerImpl) DeleteInstances(instancePool InstancePoolNodeGroup, instances []ocicommon.OciRef) error {
 	klog.Infof("DeleteInstances called on instance pool %s", instancePool.Id())
 
+	instanceIDs := make([]string, 0, len(instances))
+	launchedInstances := make([]ocicommon.OciRef, 0, len(instances))
 	for _, instance := range instances {
-		// removeInstance auto decrements instance pool size.
-		detached := m.instancePoolCache.removeInstance(instancePool, instance.InstanceID)
-		if !detached {
-			return fmt.Errorf("could not delete instance %s from instance pool %s", instance.InstanceID, instancePool.Id())
+		instanceIDs = append(instanceIDs, instance.InstanceID)
+		// Placeholders of instances that were never launched have nothing to clean up.
+		if !strings.Contains(instance.InstanceID, consts.InstanceIDUnfulfilled) {
+			launchedInstances = append(launchedInstances, instance)
 		}
 	}
 
+	if err := m.preTerminationHook.Run(instancePool.Id(), launchedInstances); err != nil {
+		return errors.Wrapf(err, "could not delete instances from instance pool %s", instancePool.Id())
+	}
+
+	// removeInstances auto decrements instance pool size.
+	if err := m.instancePoolCache.removeInstances(instancePool, instanceIDs); err != nil {
+		return errors.Wrapf(err, "could not delete instances from instance pool %s", instancePool.Id())
+	}
+	m.monitoring.RecordScaleDown(instancePool.Id(), len(instanceIDs))
 	return nil
 }
 
+// GetInstancePoolAvailableInstanceCount returns the number of instances the service limits still allow the instance-pool
+// to launch, or math.MaxInt32 if service limits are not checked.
+func (m *InstancePoolManagerImpl) GetInstancePoolAvailableInstanceCount(ip InstancePoolNodeGroup) (int, error) {
+	if m.limitsClient == nil {
+		return math.MaxInt32, nil
+	}
+
+	instancePool, err := m.instancePoolCache.getInstancePool(ip.Id())
+	if err != nil {
+		return 0, err
+	}
+	shape, err := m.ShapeGetter.GetInstancePoolShape(instancePool)
+	if err != nil {
+		return 0, err
+	}
+
+	limitName := ocicommon.ShapeServiceLimit(shape)
+	if name, ok := instancePool.FreeformTags[consts.ServiceLimitNameTag]; ok && name != "" {
+		limitName = name
+	}
+	if limitName == "" {
+		return 0, fmt.Errorf("no known service limit for shape %s, set the %s freeform tag on instance-pool %s",
+			shape.Name, consts.ServiceLimitNameTag, ip.Id())
+	}
+	unitsPerInstance := ocicommon.ServiceLimitUnitsPerInstance(limitName, shape)
+
+	var availabilityDomains []string
+	for _, placementConfig := range instancePool.PlacementConfigurations {
+		if placementConfig.AvailabilityDomain != nil {
+			availabilityDomains = append(availabilityDomains, *placementConfig.AvailabilityDomain)
+		}
+	}
+
+	available, err := ocicommon.AvailableInstanceCount(m.limitsClient, limitName, unitsPerInstance, *instancePool.CompartmentId, availabilityDomains)
+	if err != nil {
+		return 0, err
+	}
+	klog.V(4).Infof("service limit %s allows %d more instances in instance pool %s", limitName, available, ip.Id())
+	return available, nil
+}
+
+// HasInstancePoolHostCapacity returns false if compute capacity reports show that no availability domain of the
+// instance-pool has host capacity for its shape. Returns true if capacity is not checked or the instance-pool can fall
+// back to other instance configurations, which may launch other shapes.
+func (m *InstancePoolManagerImpl) HasInstancePoolHostCapacity(ip InstancePoolNodeGroup) (bool, error) {
+	if m.capacityReportClient == nil || len(ip.instanceConfigurationIDs) > 1 {
+		return true, nil
+	}
+
+	instancePool, err := m.instancePoolCache.getInstancePool(ip.Id())
+	if err != nil {
+		return false, err
+	}
+	shape, err := m.ShapeGetter.GetInstancePoolShape(instancePool)
+	if err != nil {
+		return false, err
+	}
+
+	var availabilityDomains []string
+	for _, placementConfig := range instancePool.PlacementConfigurations {
+		if placementConfig.AvailabilityDomain != nil {
+			availabilityDomains = append(availabilityDomains, *placementConfig.AvailabilityDomain)
+		}
+	}
+
+	hasCapacity, err := ocicommon.HasHostCapacity(m.capacityReportClient, shape, *instancePool.CompartmentId, availabilityDomains)
+	if err != nil {
+		return false, err
+	}
+	klog.V(4).Infof("capacity reports show host capacity %v for shape %s of instance pool %s", hasCapacity, shape.Name, ip.Id())
+	return hasCapacity, nil
+}
+
+// GetInstancePoolMaxNodeProvisionTime returns the max node provision time of the instance-pool from its
+// cluster-autoscaler/max-node-provision-time freeform tag or, failing that, from the max-node-provision-time of
+// its shape. Returns 0 if neither is set.
+func (m *InstancePoolManagerImpl) GetInstancePoolMaxNodeProvisionTime(ip InstancePoolNodeGroup) (time.Duration, error) {
+	instancePool, err := m.instancePoolCache.getInstancePool(ip.Id())
+	if err != nil {
+		return 0, err
+	}
+
+	var shapeName string
+	if len(m.maxNodeProvisionTimeByShape) > 0 {
+		// Only resolve the shape when it matters, this may call the OCI API.
+		shape, err := m.ShapeGetter.GetInstancePoolShape(instancePool)
+		if err != nil {
+			return 0, err
+		}
+		shapeName = shape.Name
+	}
+
+	provisionTime, err := ocicommon.MaxNodeProvisionTime(instancePool.FreeformTags, shapeName, m.maxNodeProvisionTimeByShape)
+	if err != nil {
+		return 0, errors.Wrapf(err, "instance-pool %s", ip.Id())
+	}
+	return provisionTime, nil
+}
+
+// GetPricingModel returns the pricing model of the shape snapshot, or nil if there is none.
+func (m *InstancePoolManagerImpl) GetPricingModel() cloudprovider.PricingModel {
+	return m.pricingModel
+}
+
 func (m *InstancePoolManagerImpl) buildNodeFromTemplate(instancePool *core.InstancePool) (*apiv1.Node, error) {
 
 	node := apiv1.Node{}
@@ -439,11 +1021,21 @@ func (m *InstancePoolManagerImpl) buildNodeFromTemplate(instancePool *core.Insta
 	}
 
 	node.Status.Capacity[apiv1.ResourcePods] = *resource.NewQuantity(110, resource.DecimalSI)
-	node.Status.Capacity[apiv1.ResourceCPU] = *resource.NewQuantity(int64(shape.CPU), resource.DecimalSI)
+	cpu := shape.CPU
+	if m.cfg.Global.ReportVCPUs && shape.ThreadsPerCore > 0 {
+		cpu *= float32(shape.ThreadsPerCore)
+	}
+	node.Status.Capacity[apiv1.ResourceCPU] = *resource.NewQuantity(int64(cpu), resource.DecimalSI)
 	node.Status.Capacity[apiv1.ResourceMemory] = *resource.NewQuantity(int64(shape.MemoryInBytes), resource.DecimalSI)
 	node.Status.Capacity[consts.ResourceGPU] = *resource.NewQuantity(int64(shape.GPU), resource.DecimalSI)
+	if shape.EphemeralStorageInBytes > 0 {
+		node.Status.Capacity[apiv1.ResourceEphemeralStorage] = *resource.NewQuantity(int64(shape.EphemeralStorageInBytes), resource.DecimalSI)
+	}
+	if resourceName := m.cfg.Global.SecondaryVnicResourceName; resourceName != "" && shape.SecondaryVnics > 0 {
+		node.Status.Capacity[apiv1.ResourceName(resourceName)] = *resource.NewQuantity(int64(shape.SecondaryVnics), resource.DecimalSI)
+	}
 
-	node.Status.Allocatable = node.Status.Capacity
+	node.Status.Allocatable = m.kubeletReservation.Allocatable(node.Status.Capacity)
 
 	availabilityDomain, err := getInstancePoolAvailabilityDomain(instancePool)
 	if err != nil {
@@ -451,6 +1043,18 @@ func (m *InstancePoolManagerImpl) buildNodeFromTemplate(instancePool *core.Insta
 	}
 
 	node.Labels = cloudprovider.JoinStringMaps(node.Labels, ocicommon.BuildGenericLabels(*instancePool.Id, nodeName, shape.Name, availabilityDomain))
+	if gpuProduct := ocicommon.GPUProductForShape(shape.Name); shape.GPU > 0 && gpuProduct != "" {
+		node.Labels[consts.GPUProductLabel] = gpuProduct
+	}
+	if shape.LaunchConfigHash != "" {
+		node.Labels[ocicommon.LaunchConfigHashLabel] = shape.LaunchConfigHash
+	}
+	if shape.NumaNodesPerSocket != "" {
+		node.Labels[ocicommon.NumaNodesPerSocketLabel] = shape.NumaNodesPerSocket
+	}
+	if shape.IsWindows() {
+		ocicommon.SetWindowsOS(&node)
+	}
 
 	node.Status.Conditions = cloudprovider.BuildReadyConditions()
 	return &node, nil
@@ -6,13 +6,20 @@ package instancepools
 
 import (
 	"context"
+	"fmt"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/workrequests"
+	"k8s.io/client-go/kubernetes/fake"
 	kubeletapis "k8s.io/kubelet/pkg/apis"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
@@ -25,6 +32,7 @@ type mockShapeClient struct {
 	err                   error
 	listShapeResp         core.ListShapesResponse
 	getInstanceConfigResp core.GetInstanceConfigurationResponse
+	getImageResp          core.GetImageResponse
 }
 
 func (m *mockShapeClient) ListShapes(_ context.Context, _ core.ListShapesRequest) (core.ListShapesResponse, error) {
@@ -35,6 +43,10 @@ func (m *mockShapeClient) GetInstanceConfiguration(context.Context, core.GetInst
 	return m.getInstanceConfigResp, m.err
 }
 
+func (m *mockShapeClient) GetImage(context.Context, core.GetImageRequest) (core.GetImageResponse, error) {
+	return m.getImageResp, m.err
+}
+
 var launchDetails = core.InstanceConfigurationLaunchInstanceDetails{
 	CompartmentId:     nil,
 	DisplayName:       nil,
@@ -87,6 +99,20 @@ type mockComputeManagementClient struct {
 	listInstancePoolInstancesResponse  core.ListInstancePoolInstancesResponse
 	updateInstancePoolResponse         core.UpdateInstancePoolResponse
 	detachInstancePoolInstanceResponse core.DetachInstancePoolInstanceResponse
+	detachedInstanceIDsMu              sync.Mutex
+	detachedInstanceIDs                []string
+	listInstancePoolsResponse          core.ListInstancePoolsResponse
+	createInstancePoolResponse         core.CreateInstancePoolResponse
+	terminateInstancePoolResponse      core.TerminateInstancePoolResponse
+	// getInstancePoolResponses, if set, are returned by successive GetInstancePool calls before getInstancePoolResponse.
+	getInstancePoolResponses []core.GetInstancePoolResponse
+	// detachErrs are returned by the detaches of the instances with the given IDs instead of err.
+	detachErrs map[string]error
+	// detachesInFlight and maxDetachesInFlight count the detaches in progress at the same time.
+	detachesInFlight    int
+	maxDetachesInFlight int
+	// onListInstancePoolInstances, if set, is called by ListInstancePoolInstances.
+	onListInstancePoolInstances func()
 }
 
 type mockVirtualNetworkClient struct {
@@ -115,6 +141,18 @@ func (m *mockWorkRequestClient) ListWorkRequestErrors(ctx context.Context, reque
 	return workrequests.ListWorkRequestErrorsResponse{}, m.err
 }
 
+func (m *mockComputeClient) GetInstance(context.Context, core.GetInstanceRequest) (core.GetInstanceResponse, error) {
+	return core.GetInstanceResponse{}, m.err
+}
+
+func (m *mockComputeClient) UpdateInstance(context.Context, core.UpdateInstanceRequest) (core.UpdateInstanceResponse, error) {
+	return core.UpdateInstanceResponse{}, m.err
+}
+
+func (m *mockComputeClient) ListInstances(context.Context, core.ListInstancesRequest) (core.ListInstancesResponse, error) {
+	return core.ListInstancesResponse{}, m.err
+}
+
 func (m *mockComputeClient) ListVnicAttachments(ctx context.Context, request core.ListVnicAttachmentsRequest) (core.ListVnicAttachmentsResponse, error) {
 	return m.listVnicAttachmentsResponse, m.err
 }
@@ -123,11 +161,23 @@ func (m *mockVirtualNetworkClient) GetVnic(context.Context, core.GetVnicRequest)
 	return m.getVnicResponse, m.err
 }
 
+func (m *mockVirtualNetworkClient) GetSubnet(context.Context, core.GetSubnetRequest) (core.GetSubnetResponse, error) {
+	return core.GetSubnetResponse{}, m.err
+}
+
 func (m *mockComputeManagementClient) ListInstancePoolInstances(_ context.Context, _ core.ListInstancePoolInstancesRequest) (core.ListInstancePoolInstancesResponse, error) {
+	if m.onListInstancePoolInstances != nil {
+		m.onListInstancePoolInstances()
+	}
 	return m.listInstancePoolInstancesResponse, m.err
 }
 
 func (m *mockComputeManagementClient) GetInstancePool(context.Context, core.GetInstancePoolRequest) (core.GetInstancePoolResponse, error) {
+	if len(m.getInstancePoolResponses) > 0 {
+		resp := m.getInstancePoolResponses[0]
+		m.getInstancePoolResponses = m.getInstancePoolResponses[1:]
+		return resp, m.err
+	}
 	return m.getInstancePoolResponse, m.err
 }
 
@@ -139,10 +189,36 @@ func (m *mockComputeManagementClient) GetInstancePoolInstance(context.Context, c
 	return m.getInstancePoolInstanceResponse, m.err
 }
 
-func (m *mockComputeManagementClient) DetachInstancePoolInstance(context.Context, core.DetachInstancePoolInstanceRequest) (core.DetachInstancePoolInstanceResponse, error) {
+func (m *mockComputeManagementClient) DetachInstancePoolInstance(_ context.Context, req core.DetachInstancePoolInstanceRequest) (core.DetachInstancePoolInstanceResponse, error) {
+	m.detachedInstanceIDsMu.Lock()
+	m.detachesInFlight++
+	m.maxDetachesInFlight = max(m.maxDetachesInFlight, m.detachesInFlight)
+	m.detachedInstanceIDsMu.Unlock()
+	// Give overlapping detaches a chance to show up.
+	time.Sleep(10 * time.Millisecond)
+
+	m.detachedInstanceIDsMu.Lock()
+	defer m.detachedInstanceIDsMu.Unlock()
+	m.detachesInFlight--
+	m.detachedInstanceIDs = append(m.detachedInstanceIDs, *req.InstanceId)
+	if err, ok := m.detachErrs[*req.InstanceId]; ok {
+		return core.DetachInstancePoolInstanceResponse{}, err
+	}
 	return m.detachInstancePoolInstanceResponse, m.err
 }
 
+func (m *mockComputeManagementClient) ListInstancePools(context.Context, core.ListInstancePoolsRequest) (core.ListInstancePoolsResponse, error) {
+	return m.listInstancePoolsResponse, m.err
+}
+
+func (m *mockComputeManagementClient) CreateInstancePool(context.Context, core.CreateInstancePoolRequest) (core.CreateInstancePoolResponse, error) {
+	return m.createInstancePoolResponse, m.err
+}
+
+func (m *mockComputeManagementClient) TerminateInstancePool(context.Context, core.TerminateInstancePoolRequest) (core.TerminateInstancePoolResponse, error) {
+	return m.terminateInstancePoolResponse, m.err
+}
+
 var computeClient = &mockComputeClient{
 	err: nil,
 	listVnicAttachmentsResponse: core.ListVnicAttachmentsResponse{
@@ -221,6 +297,22 @@ func TestInstancePoolFromArgs(t *testing.T) {
 		t.Errorf("got ocid %q ; wanted id \"ocid1.instancepool.oc1.phx.aaaaaaaah\"", instanceNodePool.id)
 	}
 
+	value = `1:5:ocid1.instancepool.oc1.phx.aaaaaaaah:ocid1.instanceconfiguration.oc1.phx.aaaa1,ocid1.instanceconfiguration.oc1.phx.aaaa2`
+	instanceNodePool, err = instancePoolFromArg(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expectedInstanceConfigurationIDs := []string{"ocid1.instanceconfiguration.oc1.phx.aaaa1", "ocid1.instanceconfiguration.oc1.phx.aaaa2"}
+	if !reflect.DeepEqual(instanceNodePool.instanceConfigurationIDs, expectedInstanceConfigurationIDs) {
+		t.Errorf("got instance configurations %v ; wanted %v", instanceNodePool.instanceConfigurationIDs, expectedInstanceConfigurationIDs)
+	}
+
+	value = `1:5:ocid1.instancepool.oc1.phx.aaaaaaaah:ocid1.image.oc1.phx.aaaa1`
+	_, err = instancePoolFromArg(value)
+	if err == nil {
+		t.Fatal("expected error of an invalid instance configuration")
+	}
+
 	value = `1:5:ocid1.nodepool.oc1.phx.aaaaaaaah`
 	_, err = instancePoolFromArg(value)
 	if err == nil {
@@ -355,6 +447,130 @@ func TestGetInstancePoolForInstance(t *testing.T) {
 
 }
 
+func TestGetInstancePoolForInstanceFromCache(t *testing.T) {
+	instancePoolCache := newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient)
+	instancePoolCache.poolCache["ocid1.instancepool.oc1.phx.aaaaaaaa2"] = &core.InstancePool{
+		Id:   common.String("ocid1.instancepool.oc1.phx.aaaaaaaa2"),
+		Size: common.Int(1),
+	}
+	instancePoolCache.instanceSummaryCache["ocid1.instancepool.oc1.phx.aaaaaaaa2"] = &[]core.InstanceSummary{{
+		Id:                 common.String("ocid1.instance.oc1.phx.aaacustom"),
+		AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+		CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaa1"),
+		DisplayName:        common.String("inst-custom"),
+		Shape:              common.String("VM.Standard2.8"),
+		State:              common.String("Running"),
+	}}
+
+	// The node was joined by a custom bootstrap image, so it only has a provider ID.
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-node"},
+		Spec:       apiv1.NodeSpec{ProviderID: "ocid1.instance.oc1.phx.aaacustom"},
+	}
+	kubeClient := fake.NewSimpleClientset(node)
+
+	manager := &InstancePoolManagerImpl{
+		cfg: &ocicommon.CloudConfig{},
+		staticInstancePools: map[string]*InstancePoolNodeGroup{
+			"ocid1.instancepool.oc1.phx.aaaaaaaa2": {id: "ocid1.instancepool.oc1.phx.aaaaaaaa2"},
+		},
+		instancePoolCache: instancePoolCache,
+		kubeClient:        kubeClient,
+	}
+
+	ociRef, err := ocicommon.NodeToOciRef(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	ip, err := manager.GetInstancePoolForInstance(ociRef)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if ip == nil || ip.Id() != "ocid1.instancepool.oc1.phx.aaaaaaaa2" {
+		t.Fatalf("got instance pool %v ; wanted ocid1.instancepool.oc1.phx.aaaaaaaa2", ip)
+	}
+
+	updatedNode, err := kubeClient.CoreV1().Nodes().Get(context.Background(), "custom-node", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := updatedNode.Annotations[consts.OciInstancePoolIDAnnotation]; got != "ocid1.instancepool.oc1.phx.aaaaaaaa2" {
+		t.Errorf("got instance pool annotation %q ; wanted ocid1.instancepool.oc1.phx.aaaaaaaa2", got)
+	}
+	if got := updatedNode.Labels[apiv1.LabelTopologyZone]; got != "PHX-AD-1" {
+		t.Errorf("got zone label %q ; wanted PHX-AD-1", got)
+	}
+}
+
+func TestNextInstanceConfiguration(t *testing.T) {
+	instanceConfigurationIDs := []string{"ocid1.instanceconfiguration.oc1.phx.e5", "ocid1.instanceconfiguration.oc1.phx.e4"}
+	testCases := map[string]struct {
+		current  string
+		expected string
+	}{
+		"preferred falls back to the next": {current: "ocid1.instanceconfiguration.oc1.phx.e5", expected: "ocid1.instanceconfiguration.oc1.phx.e4"},
+		"last has no fallback":             {current: "ocid1.instanceconfiguration.oc1.phx.e4", expected: ""},
+		"unknown falls back to preferred":  {current: "ocid1.instanceconfiguration.oc1.phx.other", expected: "ocid1.instanceconfiguration.oc1.phx.e5"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := nextInstanceConfiguration(instanceConfigurationIDs, tc.current); got != tc.expected {
+				t.Errorf("got %q ; wanted %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTerminatingInstanceIDs(t *testing.T) {
+	instancePoolCache := newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient)
+	instancePoolCache.instanceSummaryCache["ocid1.instancepool.oc1.phx.aaaaaaaa1"] = &[]core.InstanceSummary{
+		{Id: common.String("ocid1.instance.oc1.phx.aaa1"), State: common.String("Running")},
+		{Id: common.String("ocid1.instance.oc1.phx.aaa2"), State: common.String("Terminating")},
+		{Id: common.String("ocid1.instance.oc1.phx.aaa3"), State: common.String("Terminated")},
+	}
+
+	expected := []string{"ocid1.instance.oc1.phx.aaa2"}
+	if got := instancePoolCache.terminatingInstanceIDs(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v ; wanted %v", got, expected)
+	}
+}
+
+func TestInstanceState(t *testing.T) {
+	testCases := map[string]struct {
+		lifecycleState string
+		expected       cloudprovider.InstanceState
+	}{
+		"running":                 {lifecycleState: "Running", expected: cloudprovider.InstanceRunning},
+		"provisioning":            {lifecycleState: "Provisioning", expected: cloudprovider.InstanceCreating},
+		"starting":                {lifecycleState: "Starting", expected: cloudprovider.InstanceCreating},
+		"terminating":             {lifecycleState: "Terminating", expected: cloudprovider.InstanceDeleting},
+		"upper case provisioning": {lifecycleState: "PROVISIONING", expected: cloudprovider.InstanceCreating},
+		"terminated":              {lifecycleState: "Terminated", expected: 0},
+		"stopped":                 {lifecycleState: "Stopped", expected: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := instanceState(tc.lifecycleState); got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCountLaunchedInstances(t *testing.T) {
+	instanceSummaries := []core.InstanceSummary{
+		{State: common.String("Running")},
+		{State: common.String("Provisioning")},
+		{State: common.String("Terminating")},
+		{State: common.String("Terminated")},
+	}
+	if got := countLaunchedInstances(instanceSummaries); got != 2 {
+		t.Errorf("got %d launched instances ; wanted 2", got)
+	}
+}
+
 func TestGetInstancePoolNodes(t *testing.T) {
 
 	nodePoolCache := newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient)
@@ -371,8 +587,12 @@ func TestGetInstancePoolNodes(t *testing.T) {
 		Id:                 common.String("ocid1.instance.oc1.phx.aaa2"),
 		AvailabilityDomain: common.String("PHX-AD-1"),
 		State:              common.String(string(core.InstanceLifecycleStateTerminating)),
+	}, {
+		Id:    common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1-unfulfilled-2"),
+		State: common.String(consts.InstanceStateUnfulfilled),
 	},
 	}
+	nodePoolCache.setUnfulfilledError("ocid1.instancepool.oc1.phx.aaaaaaaa1", "LimitExceeded: standard-e4-core-count")
 
 	expected := []cloudprovider.Instance{
 		{
@@ -387,10 +607,21 @@ func TestGetInstancePoolNodes(t *testing.T) {
 				State: cloudprovider.InstanceDeleting,
 			},
 		},
+		{
+			Id: "ocid1.instancepool.oc1.phx.aaaaaaaa1-unfulfilled-2",
+			Status: &cloudprovider.InstanceStatus{
+				State: cloudprovider.InstanceCreating,
+				ErrorInfo: &cloudprovider.InstanceErrorInfo{
+					ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+					ErrorCode:    consts.InstanceStateUnfulfilled,
+					ErrorMessage: "LimitExceeded: standard-e4-core-count",
+				},
+			},
+		},
 	}
 
 	manager := &InstancePoolManagerImpl{instancePoolCache: nodePoolCache, cfg: &ocicommon.CloudConfig{}}
-	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient)
+	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient, nil)
 	instances, err := manager.GetInstancePoolNodes(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"})
 	if err != nil {
 		t.Fatalf("received unexpected error; %+v", err)
@@ -466,6 +697,8 @@ func TestGetInstancePoolsAndInstances(t *testing.T) {
 				Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
 				CompartmentId:           common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
 				InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+				DisplayName:             common.String("ociinstancepool"),
+				LifecycleState:          core.InstancePoolLifecycleStateRunning,
 				PlacementConfigurations: nil,
 				Size:                    common.Int(2),
 			},
@@ -500,7 +733,7 @@ func TestGetInstancePoolsAndInstances(t *testing.T) {
 	}
 
 	// Populate cache(s) (twice to increase code coverage).
-	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient)
+	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient, nil)
 	_ = manager.Refresh()
 	err := manager.Refresh()
 	if err != nil {
@@ -553,13 +786,18 @@ func TestGetInstancePoolTemplateNode(t *testing.T) {
 
 	cloudConfig := &ocicommon.CloudConfig{}
 	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	kubeletReservation, err := ocicommon.NewKubeletReservation("cpu=100m", "memory=1Gi", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
 	var manager = &InstancePoolManagerImpl{
 		cfg:         cloudConfig,
-		ShapeGetter: ocicommon.CreateShapeGetter(shapeClient),
+		ShapeGetter: ocicommon.CreateShapeGetter(shapeClient, nil),
 		staticInstancePools: map[string]*InstancePoolNodeGroup{
 			"ocid1.instancepool.oc1.phx.aaaaaaaa1": {id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
 		},
-		instancePoolCache: instancePoolCache,
+		instancePoolCache:  instancePoolCache,
+		kubeletReservation: kubeletReservation,
 	}
 
 	instancePoolNodeGroups := manager.GetInstancePools()
@@ -585,6 +823,19 @@ func TestGetInstancePoolTemplateNode(t *testing.T) {
 		t.Fatalf("expected AD zone label %s to be set to US-ASHBURN-1: %v", apiv1.LabelTopologyZone, nodeTemplate.Labels)
 	}
 
+	// The kubelet reservation is deducted from allocatable, not capacity.
+	capacityCPU := nodeTemplate.Status.Capacity[apiv1.ResourceCPU]
+	wantedCPU := capacityCPU.DeepCopy()
+	wantedCPU.Sub(resource.MustParse("100m"))
+	if got := nodeTemplate.Status.Allocatable[apiv1.ResourceCPU]; got.Cmp(wantedCPU) != 0 {
+		t.Errorf("got allocatable cpu %s ; wanted %s", got.String(), wantedCPU.String())
+	}
+	capacityMemory := nodeTemplate.Status.Capacity[apiv1.ResourceMemory]
+	wantedMemory := capacityMemory.DeepCopy()
+	wantedMemory.Sub(resource.MustParse("1Gi"))
+	if got := nodeTemplate.Status.Allocatable[apiv1.ResourceMemory]; got.Cmp(wantedMemory) != 0 {
+		t.Errorf("got allocatable memory %s ; wanted %s", got.String(), wantedMemory.String())
+	}
 }
 
 func TestDeleteInstances(t *testing.T) {
@@ -627,7 +878,7 @@ func TestDeleteInstances(t *testing.T) {
 		},
 		instancePoolCache: newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient),
 	}
-	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient)
+	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient, nil)
 	// Populate cache(s).
 	manager.Refresh()
 
@@ -667,6 +918,225 @@ func TestDeleteInstances(t *testing.T) {
 	}
 }
 
+func TestDeleteInstancesBatch(t *testing.T) {
+
+	var computeManagementClient = &mockComputeManagementClient{
+		getInstancePoolResponse: core.GetInstancePoolResponse{
+			InstancePool: core.InstancePool{
+				Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
+				CompartmentId:           common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+				LifecycleState:          core.InstancePoolLifecycleStateRunning,
+				Size:                    common.Int(3),
+			},
+		},
+		listInstancePoolInstancesResponse: core.ListInstancePoolInstancesResponse{
+			Items: []core.InstanceSummary{{
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa1"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-1ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}, {
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa2"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-2ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}, {
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa3"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-3ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}},
+		},
+	}
+
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	manager := &InstancePoolManagerImpl{
+		cfg: cloudConfig,
+		staticInstancePools: map[string]*InstancePoolNodeGroup{
+			"ocid1.instancepool.oc1.phx.aaaaaaaa1": {id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+		},
+		instancePoolCache: newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient),
+	}
+	manager.instancePoolCache.mutationLimiter = ocicommon.NewMutationLimiter(0, 1, 0)
+	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient, nil)
+	// Populate cache(s).
+	manager.Refresh()
+
+	instancesToDelete := []ocicommon.OciRef{
+		{InstanceID: "ocid1.instance.oc1.phx.aaa2", InstancePoolID: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+		{InstanceID: "ocid1.instance.oc1.phx.aaa3", InstancePoolID: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+	}
+	err := manager.DeleteInstances(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"}, instancesToDelete)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	size, err := manager.GetInstancePoolSize(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size != 1 {
+		t.Errorf("got size %d ; wanted size 1 *after* delete", size)
+	}
+	instances, err := manager.GetInstancePoolNodes(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := len(instances); got != 1 {
+		t.Errorf("got %d instances ; wanted 1 *after* delete", got)
+	}
+	// The instances are detached one after another, in order.
+	expectedDetached := []string{"ocid1.instance.oc1.phx.aaa2", "ocid1.instance.oc1.phx.aaa3"}
+	if !reflect.DeepEqual(computeManagementClient.detachedInstanceIDs, expectedDetached) {
+		t.Errorf("got detached instances %v ; wanted %v", computeManagementClient.detachedInstanceIDs, expectedDetached)
+	}
+	if got := computeManagementClient.maxDetachesInFlight; got != 1 {
+		t.Errorf("got %d detaches in flight at the same time ; wanted 1", got)
+	}
+}
+
+func TestDeleteInstancesBatchDetachFails(t *testing.T) {
+
+	var computeManagementClient = &mockComputeManagementClient{
+		getInstancePoolResponse: core.GetInstancePoolResponse{
+			InstancePool: core.InstancePool{
+				Id:                      common.String("ocid1.instancepool.oc1.phx.aaaaaaaa1"),
+				CompartmentId:           common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				InstanceConfigurationId: common.String("ocid1.instanceconfiguration.oc1.phx.aaaaaaaa1"),
+				LifecycleState:          core.InstancePoolLifecycleStateRunning,
+				Size:                    common.Int(3),
+			},
+		},
+		listInstancePoolInstancesResponse: core.ListInstancePoolInstancesResponse{
+			Items: []core.InstanceSummary{{
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa1"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-1ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}, {
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa2"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-2ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}, {
+				Id:                 common.String("ocid1.instance.oc1.phx.aaa3"),
+				AvailabilityDomain: common.String("Uocm:PHX-AD-1"),
+				CompartmentId:      common.String("ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				DisplayName:        common.String("inst-3ncvn-ociinstancepool"),
+				Shape:              common.String("VM.Standard2.16"),
+				State:              common.String(string(core.InstanceLifecycleStateRunning)),
+			}},
+		},
+		detachErrs: map[string]error{"ocid1.instance.oc1.phx.aaa2": fmt.Errorf("instance pool is being updated")},
+	}
+
+	cloudConfig := &ocicommon.CloudConfig{}
+	cloudConfig.Global.CompartmentID = "ocid1.compartment.oc1..aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	manager := &InstancePoolManagerImpl{
+		cfg: cloudConfig,
+		staticInstancePools: map[string]*InstancePoolNodeGroup{
+			"ocid1.instancepool.oc1.phx.aaaaaaaa1": {id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+		},
+		instancePoolCache: newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient),
+	}
+	manager.instancePoolCache.mutationLimiter = ocicommon.NewMutationLimiter(0, 1, 0)
+	manager.ShapeGetter = ocicommon.CreateShapeGetter(shapeClient, nil)
+	// Populate cache(s).
+	manager.Refresh()
+
+	instancesToDelete := []ocicommon.OciRef{
+		{InstanceID: "ocid1.instance.oc1.phx.aaa2", InstancePoolID: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+		{InstanceID: "ocid1.instance.oc1.phx.aaa3", InstancePoolID: "ocid1.instancepool.oc1.phx.aaaaaaaa1"},
+	}
+	if err := manager.DeleteInstances(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"}, instancesToDelete); err == nil {
+		t.Fatal("expected error")
+	}
+	// The instance following the one that failed is still detached.
+	expectedDetached := []string{"ocid1.instance.oc1.phx.aaa2", "ocid1.instance.oc1.phx.aaa3"}
+	if !reflect.DeepEqual(computeManagementClient.detachedInstanceIDs, expectedDetached) {
+		t.Errorf("got detached instances %v ; wanted %v", computeManagementClient.detachedInstanceIDs, expectedDetached)
+	}
+	size, err := manager.GetInstancePoolSize(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size != 2 {
+		t.Errorf("got size %d ; wanted size 2 *after* delete", size)
+	}
+	instances, err := manager.GetInstancePoolNodes(InstancePoolNodeGroup{id: "ocid1.instancepool.oc1.phx.aaaaaaaa1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := len(instances); got != 2 {
+		t.Errorf("got %d instances ; wanted 2 *after* delete", got)
+	}
+}
+
+func TestSetSizeMutationLimiter(t *testing.T) {
+	pollInterval := internalPollInterval
+	internalPollInterval = 10 * time.Millisecond
+	defer func() { internalPollInterval = pollInterval }()
+
+	instancePoolID := "ocid1.instancepool.oc1.phx.aaaaaaaa1"
+	computeManagementClient := &mockComputeManagementClient{
+		getInstancePoolResponse: core.GetInstancePoolResponse{
+			InstancePool: core.InstancePool{
+				Id:             common.String(instancePoolID),
+				CompartmentId:  common.String("ocid1.compartment.oc1..aaaaaaaa1"),
+				LifecycleState: core.InstancePoolLifecycleStateRunning,
+				Size:           common.Int(1),
+			},
+		},
+		listInstancePoolInstancesResponse: core.ListInstancePoolInstancesResponse{
+			Items: []core.InstanceSummary{
+				{Id: common.String("ocid1.instance.oc1.phx.aaa1"), State: common.String(string(core.InstanceLifecycleStateRunning))},
+				{Id: common.String("ocid1.instance.oc1.phx.aaa2"), State: common.String(string(core.InstanceLifecycleStateRunning))},
+			},
+		},
+	}
+	instancePoolCache := newInstancePoolCache(computeManagementClient, computeClient, virtualNetworkClient, workRequestsClient)
+	instancePoolCache.poolCache[instancePoolID] = &core.InstancePool{Id: common.String(instancePoolID), Size: common.Int(1)}
+	instancePoolCache.mutationLimiter = ocicommon.NewMutationLimiter(0, 1, 50*time.Millisecond)
+
+	// The resize fails once waiting for the mutation in flight takes too long.
+	release, err := instancePoolCache.mutationLimiter.Acquire(context.Background(), instancePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := instancePoolCache.setSize(instancePoolID, 2); err == nil {
+		t.Errorf("resized the instance pool while another mutation was in flight ; wanted an error")
+	}
+	release()
+
+	// The slot is released once the instance pool is updated, while waiting for the instances to run.
+	var waitErr error
+	computeManagementClient.onListInstancePoolInstances = func() {
+		release, err := instancePoolCache.mutationLimiter.Acquire(context.Background(), instancePoolID)
+		if err != nil {
+			waitErr = err
+			return
+		}
+		release()
+	}
+	if err := instancePoolCache.setSize(instancePoolID, 2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if waitErr != nil {
+		t.Errorf("got the mutation slot held while waiting for the instances to run: %v", waitErr)
+	}
+}
+
 func TestBuildGenericLabels(t *testing.T) {
 
 	shapeName := "VM.Standard2.8"
@@ -715,7 +1185,7 @@ func TestBuildGenericLabels(t *testing.T) {
 			},
 		},
 	}
-	shapeGetter := ocicommon.CreateShapeGetter(mockShapeClient)
+	shapeGetter := ocicommon.CreateShapeGetter(mockShapeClient, nil)
 
 	manager := InstancePoolManagerImpl{
 		ShapeGetter: shapeGetter,
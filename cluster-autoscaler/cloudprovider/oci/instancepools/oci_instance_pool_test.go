@@ -0,0 +1,160 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"errors"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+)
+
+// mockInstancePoolManager implements the InstancePoolManager calls IncreaseSize makes, every other call panics.
+type mockInstancePoolManager struct {
+	InstancePoolManager
+
+	size         int
+	available    int
+	availableErr error
+	// noHostCapacity makes capacity reports show no host capacity.
+	noHostCapacity  bool
+	hostCapacityErr error
+	// unavailableErr is returned by CheckInstancePoolAvailable.
+	unavailableErr error
+}
+
+func (m *mockInstancePoolManager) GetInstancePoolSize(_ InstancePoolNodeGroup) (int, error) {
+	return m.size, nil
+}
+
+func (m *mockInstancePoolManager) SetInstancePoolSize(_ InstancePoolNodeGroup, size int) error {
+	m.size = size
+	return nil
+}
+
+func (m *mockInstancePoolManager) GetInstancePoolAvailableInstanceCount(_ InstancePoolNodeGroup) (int, error) {
+	return m.available, m.availableErr
+}
+
+func (m *mockInstancePoolManager) HasInstancePoolHostCapacity(_ InstancePoolNodeGroup) (bool, error) {
+	return !m.noHostCapacity, m.hostCapacityErr
+}
+
+func (m *mockInstancePoolManager) CheckInstancePoolAvailable(_ InstancePoolNodeGroup) error {
+	return m.unavailableErr
+}
+
+func TestIncreaseSizeHostCapacity(t *testing.T) {
+	testCases := map[string]struct {
+		noHostCapacity  bool
+		hostCapacityErr error
+		expectedSize    int
+		expectedError   bool
+	}{
+		"host capacity": {
+			expectedSize: 5,
+		},
+		"no host capacity": {
+			noHostCapacity: true,
+			expectedSize:   2,
+			expectedError:  true,
+		},
+		"capacity unknown": {
+			hostCapacityErr: errors.New("unable to create capacity report"),
+			expectedSize:    5,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			manager := &mockInstancePoolManager{size: 2, available: 10, noHostCapacity: tc.noHostCapacity, hostCapacityErr: tc.hostCapacityErr}
+			ip := &InstancePoolNodeGroup{manager: manager, id: "ocid1.instancepool.oc1.phx.aaaaaaaa1", minSize: 0, maxSize: 10}
+
+			err := ip.IncreaseSize(3)
+			if tc.expectedError {
+				var capacityErr *ocicommon.HostCapacityError
+				if !errors.As(err, &capacityErr) {
+					t.Fatalf("got error %v ; wanted a HostCapacityError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if manager.size != tc.expectedSize {
+				t.Errorf("got size %d ; wanted %d", manager.size, tc.expectedSize)
+			}
+		})
+	}
+}
+
+func TestIncreaseSizeServiceLimits(t *testing.T) {
+	testCases := map[string]struct {
+		available     int
+		availableErr  error
+		delta         int
+		expectedSize  int
+		expectedError bool
+	}{
+		"within limits": {
+			available:    5,
+			delta:        3,
+			expectedSize: 5,
+		},
+		"partially covered": {
+			available:     2,
+			delta:         3,
+			expectedSize:  4,
+			expectedError: true,
+		},
+		"exhausted": {
+			available:     0,
+			delta:         3,
+			expectedSize:  2,
+			expectedError: true,
+		},
+		"limits unknown": {
+			availableErr: errors.New("no known service limit"),
+			delta:        3,
+			expectedSize: 5,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			manager := &mockInstancePoolManager{size: 2, available: tc.available, availableErr: tc.availableErr}
+			ip := &InstancePoolNodeGroup{manager: manager, id: "ocid1.instancepool.oc1.phx.aaaaaaaa1", minSize: 0, maxSize: 10}
+
+			err := ip.IncreaseSize(tc.delta)
+			if tc.expectedError {
+				var limitErr *ocicommon.ServiceLimitError
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("got error %v ; wanted a ServiceLimitError", err)
+				}
+				if limitErr.Requested != tc.delta || limitErr.Available != tc.available {
+					t.Errorf("got requested %d and available %d ; wanted %d and %d", limitErr.Requested, limitErr.Available, tc.delta, tc.available)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if manager.size != tc.expectedSize {
+				t.Errorf("got size %d ; wanted %d", manager.size, tc.expectedSize)
+			}
+		})
+	}
+}
+
+func TestDeleteNodesPastMinSize(t *testing.T) {
+	manager := &mockInstancePoolManager{size: 3}
+	ip := &InstancePoolNodeGroup{manager: manager, id: "ocid1.instancepool.oc1.phx.aaaaaaaa1", minSize: 2, maxSize: 10}
+
+	nodes := []*apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodeA"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodeB"}},
+	}
+	if err := ip.DeleteNodes(nodes); err == nil {
+		t.Fatalf("expected to have an error because deleting the nodes would drop the instance pool below its min size")
+	}
+}
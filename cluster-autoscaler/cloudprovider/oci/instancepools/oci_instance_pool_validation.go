@@ -0,0 +1,107 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package instancepools
+
+import (
+	"context"
+	"fmt"
+
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/klog/v2"
+)
+
+// validInstancePools returns the instance pools that are not known to be misconfigured. Instance pools that were not
+// validated yet are validated first, and misconfigured ones are reported and left out, so they do not fail the refresh
+// of the others. They are validated again on every refresh until they are fixed.
+func (m *InstancePoolManagerImpl) validInstancePools(instancePools map[string]*InstancePoolNodeGroup) map[string]*InstancePoolNodeGroup {
+	valid := make(map[string]*InstancePoolNodeGroup, len(instancePools))
+	for id, instancePool := range instancePools {
+		if !m.validator.IsValid(id) {
+			problem, err := m.validateInstancePool(id)
+			if err != nil {
+				// The instance pool is kept, so whatever failed fails the refresh as it would without validation.
+				klog.Warningf("unable to validate instance pool %s: %v", id, err)
+			} else {
+				m.validator.Report(id, problem)
+				if problem != nil {
+					continue
+				}
+			}
+		}
+		valid[id] = instancePool
+	}
+	return valid
+}
+
+// validateInstancePool checks that the instance pool exists, that its placement policy and fallback instance pool are
+// valid, that its instance configuration and shape can be resolved, that the shape is offered in the availability
+// domains of the instance pool and that the subnets it places instances in exist. It returns the problem with the
+// configuration of the instance pool, or an error if it could not be validated.
+func (m *InstancePoolManagerImpl) validateInstancePool(id string) (problem error, err error) {
+	resp, err := m.instancePoolCache.computeManagementClient.GetInstancePool(context.Background(), core.GetInstancePoolRequest{
+		InstancePoolId: common.String(id),
+	})
+	if err != nil {
+		if ocicommon.IsNotFound(err) {
+			return fmt.Errorf("instance pool does not exist or is not accessible"), nil
+		}
+		return nil, err
+	}
+
+	if _, err := placementPolicy(&resp.InstancePool); err != nil {
+		return err, nil
+	}
+	if fallbackID := fallbackInstancePoolID(&resp.InstancePool); fallbackID != "" && (fallbackID == id || m.getStaticInstancePool(fallbackID) == nil) {
+		return fmt.Errorf("the fallback instance pool %s of the %s freeform tag is not another instance pool of the autoscaler",
+			fallbackID, consts.FallbackInstancePoolTag), nil
+	}
+
+	shape, err := m.ShapeGetter.GetInstancePoolShape(&resp.InstancePool)
+	if err != nil {
+		if ocicommon.IsMisconfiguration(err) {
+			return fmt.Errorf("the shape of instance configuration %s cannot be resolved: %v",
+				*resp.InstanceConfigurationId, err), nil
+		}
+		return nil, err
+	}
+
+	if m.shapeClient != nil {
+		var availabilityDomains []string
+		for _, placement := range resp.PlacementConfigurations {
+			if placement.AvailabilityDomain != nil {
+				availabilityDomains = append(availabilityDomains, *placement.AvailabilityDomain)
+			}
+		}
+		problem, err := ocicommon.CheckShapeAvailability(context.Background(), m.shapeClient, resp.CompartmentId, shape.Name, availabilityDomains)
+		if problem != nil || err != nil {
+			return problem, err
+		}
+	}
+
+	for _, placement := range resp.PlacementConfigurations {
+		subnetIDs := []*string{placement.PrimarySubnetId}
+		for _, secondary := range placement.SecondaryVnicSubnets {
+			subnetIDs = append(subnetIDs, secondary.SubnetId)
+		}
+		for _, subnetID := range subnetIDs {
+			if subnetID == nil {
+				continue
+			}
+			_, err := m.instancePoolCache.virtualNetworkClient.GetSubnet(context.Background(), core.GetSubnetRequest{
+				SubnetId: subnetID,
+			})
+			if err != nil {
+				if ocicommon.IsNotFound(err) {
+					return fmt.Errorf("subnet %s does not exist or is not accessible", *subnetID), nil
+				}
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
@@ -8,19 +8,24 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
 	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
 )
 
 func newNodePoolCache(okeClient *oke.ContainerEngineClient) *nodePoolCache {
 	return &nodePoolCache{
-		cache:      map[string]*oke.NodePool{},
-		targetSize: map[string]int{},
-		okeClient:  okeClient,
+		cache:           map[string]*oke.NodePool{},
+		targetSize:      map[string]int{},
+		cycling:         map[string]string{},
+		ownWorkRequests: map[string]time.Time{},
+		okeClient:       okeClient,
 	}
 }
 
@@ -28,8 +33,18 @@ type nodePoolCache struct {
 	mu         sync.Mutex
 	cache      map[string]*oke.NodePool
 	targetSize map[string]int
+	// cycling holds the ID of the work request cycling the nodes of each node pool OKE is cycling the nodes of.
+	cycling map[string]string
+	// ownWorkRequests holds when the work requests of the resizes and node deletions of the autoscaler were started,
+	// so they are not mistaken for node cycling.
+	ownWorkRequests map[string]time.Time
 
 	okeClient okeClient
+
+	// tagLaunchedInstances adds the tags identifying instances launched by the autoscaler to the node configuration of
+	// node pools when they are resized, so OKE tags the nodes it launches with them.
+	tagLaunchedInstances bool
+	clusterName          string
 }
 
 func (c *nodePoolCache) nodePools() map[string]*oke.NodePool {
@@ -65,15 +80,47 @@ func (c *nodePoolCache) rebuild(staticNodePools map[string]NodePool, maxGetNodep
 			return statusCode, err
 		}
 		c.set(&resp.NodePool)
+		c.refreshCycling(&resp.NodePool)
 	}
 	return statusCode, nil
 }
 
-// removeInstance tries to remove the instance from the node pool.
-func (c *nodePoolCache) removeInstance(nodePoolID, instanceID string) error {
+// removeInstances tries to remove the instances from the node pool in one pass: OKE has no API to delete several
+// nodes at once, so a node is deleted after another while the cache is locked, and the instances that were removed
+// are dropped from the cached node pool at once. A failure to delete a node does not prevent deleting the others.
+// Returns the number of instances removed and an error for every instance that could not be removed.
+func (c *nodePoolCache) removeInstances(nodePoolID string, instanceIDs []string) (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	var errs []error
+	removed := map[string]bool{}
+	for _, instanceID := range instanceIDs {
+		if err := c.deleteNodeWithoutLock(nodePoolID, instanceID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed[instanceID] = true
+	}
+
+	nodePool := c.cache[nodePoolID]
+	// theoretical max number of nodes inside a cluster is 1000
+	// so at most we'll be copying 1000 nodes
+	newNodeSlice := make([]oke.Node, 0, len(nodePool.Nodes))
+	for _, node := range nodePool.Nodes {
+		if !removed[*node.Id] {
+			newNodeSlice = append(newNodeSlice, node)
+		} else {
+			klog.Infof("Deleting instance %q from cache", *node.Id)
+		}
+	}
+	nodePool.Nodes = newNodeSlice
+
+	return len(removed), utilerrors.NewAggregate(errs)
+}
+
+// deleteNodeWithoutLock deletes the instance from the node pool, decrementing its size. c.mu must be held.
+func (c *nodePoolCache) deleteNodeWithoutLock(nodePoolID, instanceID string) error {
 	klog.Infof("Deleting instance %q from node pool %q", instanceID, nodePoolID)
 	// always try to remove the instance. This call is idempotent
 	scaleDown := true
@@ -97,7 +144,8 @@ func (c *nodePoolCache) removeInstance(nodePoolID, instanceID string) error {
 			statusCode == http.StatusNotFound
 		if !success {
 			status := httpResp.Status
-			klog.Infof("Received error status %s while deleting node %q", status, instanceID)
+			opcRequestID := httpResp.Header.Get(ocicommon.OpcRequestIDHeader)
+			klog.InfoS("Received error status while deleting node", "status", status, "node", instanceID, "opcRequestID", opcRequestID)
 
 			// statuses that we might expect but are still errors:
 			// 400s (if cluster still uses TA or is v1 based)
@@ -105,30 +153,17 @@ func (c *nodePoolCache) removeInstance(nodePoolID, instanceID string) error {
 			// 412 etag mismatch
 			// 429 too many requests
 			// 500 internal server errors
-			return errors.Errorf("received error status %s while deleting node %q", status, instanceID)
+			return errors.Errorf("received error status %s while deleting node %q (opc-request-id: %s)", status, instanceID, opcRequestID)
 		} else if statusSuccess {
 			// since delete node endpoint scales down by 1, we need to update the cache's target size by -1 too
 			c.targetSize[nodePoolID]--
+			c.recordOwnWorkRequestWithoutLock(resp.OpcWorkRequestId)
 		}
 	}
 
 	if !success && err != nil {
 		return err
 	}
-
-	nodePool := c.cache[nodePoolID]
-	// theoretical max number of nodes inside a cluster is 1000
-	// so at most we'll be copying 1000 nodes
-	newNodeSlice := make([]oke.Node, 0, len(nodePool.Nodes))
-	for _, node := range nodePool.Nodes {
-		if *node.Id != instanceID {
-			newNodeSlice = append(newNodeSlice, node)
-		} else {
-			klog.Infof("Deleting instance %q from cache", instanceID)
-		}
-	}
-	nodePool.Nodes = newNodeSlice
-
 	return nil
 }
 
@@ -173,25 +208,49 @@ func (c *nodePoolCache) set(np *oke.NodePool) {
 
 func (c *nodePoolCache) setSize(id string, size int) error {
 
-	_, err := c.okeClient.UpdateNodePool(context.Background(), oke.UpdateNodePoolRequest{
+	nodeConfigDetails := &oke.UpdateNodePoolNodeConfigDetails{
+		Size: common.Int(size),
+	}
+	if c.tagLaunchedInstances {
+		nodeConfigDetails.FreeformTags = c.launchedInstanceTags(id)
+	}
+
+	resp, err := c.okeClient.UpdateNodePool(context.Background(), oke.UpdateNodePoolRequest{
 		NodePoolId: common.String(id),
 		UpdateNodePoolDetails: oke.UpdateNodePoolDetails{
-			NodeConfigDetails: &oke.UpdateNodePoolNodeConfigDetails{
-				Size: common.Int(size),
-			},
+			NodeConfigDetails: nodeConfigDetails,
 		},
 	})
 	if err != nil {
 		return err
 	}
+	klog.V(2).InfoS("Updated node pool size", "nodePool", id, "size", size, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.targetSize[id] = size
+	c.recordOwnWorkRequestWithoutLock(resp.OpcWorkRequestId)
 	return nil
 }
 
+// launchedInstanceTags returns the node freeform tags of the node pool with the tags identifying instances launched by
+// the autoscaler added, or nil if the node pool already has them.
+func (c *nodePoolCache) launchedInstanceTags(id string) map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var freeformTags map[string]string
+	if nodePool, ok := c.cache[id]; ok && nodePool.NodeConfigDetails != nil {
+		freeformTags = nodePool.NodeConfigDetails.FreeformTags
+	}
+	launchedInstanceTags, changed := ocicommon.WithLaunchedInstanceTags(freeformTags, c.clusterName, id)
+	if !changed {
+		return nil
+	}
+	return launchedInstanceTags
+}
+
 func (c *nodePoolCache) getSize(id string) (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
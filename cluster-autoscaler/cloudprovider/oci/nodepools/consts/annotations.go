@@ -21,6 +21,17 @@ const (
 
 	// OciNodePoolResourceIdent is the string identifier in the ocid that indicates the resource is a node pool
 	OciNodePoolResourceIdent = "nodepool"
+	// OciVirtualNodePoolResourceIdent is the string identifier in the ocid that indicates the resource is a virtual
+	// node pool
+	OciVirtualNodePoolResourceIdent = "virtualnodepool"
+
+	// VirtualNodeMaxPods is the number of pods the template of a virtual node has capacity for
+	VirtualNodeMaxPods = 110
+	// VirtualNodeCPUCapacity is the CPU the template of a virtual node has capacity for. Virtual nodes have no CPU of
+	// their own, every pod gets the CPU it requests from the pod shape
+	VirtualNodeCPUCapacity = "1000"
+	// VirtualNodeMemoryCapacity is the memory the template of a virtual node has capacity for, see VirtualNodeCPUCapacity
+	VirtualNodeMemoryCapacity = "4Ti"
 
 	// ToBeDeletedByClusterAutoscaler is the taint used to ensure that after a node has been called to be deleted
 	// no more pods will schedule onto it
@@ -0,0 +1,73 @@
+/*
+Copyright 2020-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// kubeletExtraArgsMetadataKey is the node metadata key holding the extra arguments passed to kubelet.
+	kubeletExtraArgsMetadataKey = "kubelet-extra-args"
+	// userDataMetadataKey is the node metadata key holding the base64 encoded cloud-init script of the nodes.
+	userDataMetadataKey = "user_data"
+)
+
+var (
+	// userDataKubeletExtraArgsRegexp matches the kubelet extra args passed to the OKE init script in a custom cloud-init script.
+	userDataKubeletExtraArgsRegexp = regexp.MustCompile(`--kubelet-extra-args[= ](?:"([^"]*)"|'([^']*)')`)
+	nodeLabelsArgRegexp            = regexp.MustCompile(`-?-node-labels[= ](\S*)`)
+)
+
+// getKubeletExtraArgs returns the extra arguments kubelet is started with on the nodes of the node pool, i.e. the
+// kubelet-extra-args node metadata as well as any arguments passed to the OKE init script in a custom cloud-init script.
+func getKubeletExtraArgs(np *oke.NodePool) string {
+	args := []string{np.NodeMetadata[kubeletExtraArgsMetadataKey]}
+
+	if userData, ok := np.NodeMetadata[userDataMetadataKey]; ok && len(userData) > 0 {
+		decoded, err := base64.StdEncoding.DecodeString(userData)
+		if err != nil {
+			klog.Warningf("could not decode the user_data of nodepool %s: %v", getString(np.Id), err)
+		} else {
+			for _, submatches := range userDataKubeletExtraArgsRegexp.FindAllStringSubmatch(string(decoded), -1) {
+				args = append(args, submatches[1]+submatches[2])
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(args, " "))
+}
+
+// getRegisteredNodeLabels returns the labels kubelet registers the nodes of the node pool with through its
+// --node-labels flag.
+func getRegisteredNodeLabels(np *oke.NodePool) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, submatches := range nodeLabelsArgRegexp.FindAllStringSubmatch(getKubeletExtraArgs(np), -1) {
+		for _, pair := range strings.Split(submatches[1], ",") {
+			if pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid node label %q, expected <key>=<value>", pair)
+			}
+			labels[kv[0]] = kv[1]
+		}
+	}
+	return labels, nil
+}
+
+// getString is a helper to get a string pointer value or default to an empty string.
+func getString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+)
+
+func TestGetKubeletExtraArgs(t *testing.T) {
+	userData := base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\n" +
+		"curl --fail -H \"Authorization: Bearer Oracle\" -L0 http://169.254.169.254/opc/v2/instance/metadata/oke_init_script | base64 --decode >/var/run/oke-init.sh\n" +
+		"bash /var/run/oke-init.sh --kubelet-extra-args \"--register-with-taints=dedicated=gpu:NoSchedule --node-labels=team=ml\"\n"))
+
+	testCases := map[string]struct {
+		np       *oke.NodePool
+		expected string
+	}{
+		"no metadata": {
+			np:       &oke.NodePool{},
+			expected: "",
+		},
+		"kubelet-extra-args only": {
+			np:       &oke.NodePool{NodeMetadata: map[string]string{"kubelet-extra-args": "--max-pods=31"}},
+			expected: "--max-pods=31",
+		},
+		"user_data only": {
+			np:       &oke.NodePool{NodeMetadata: map[string]string{"user_data": userData}},
+			expected: "--register-with-taints=dedicated=gpu:NoSchedule --node-labels=team=ml",
+		},
+		"kubelet-extra-args and user_data": {
+			np:       &oke.NodePool{NodeMetadata: map[string]string{"kubelet-extra-args": "--max-pods=31", "user_data": userData}},
+			expected: "--max-pods=31 --register-with-taints=dedicated=gpu:NoSchedule --node-labels=team=ml",
+		},
+		"invalid user_data": {
+			np:       &oke.NodePool{NodeMetadata: map[string]string{"user_data": "not base64!"}},
+			expected: "",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := getKubeletExtraArgs(tc.np); got != tc.expected {
+				t.Errorf("got %q ; wanted %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetRegisteredNodeLabels(t *testing.T) {
+	testCases := map[string]struct {
+		kubeletArgs string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		"no node labels": {
+			kubeletArgs: "--max-pods=31",
+			expected:    map[string]string{},
+		},
+		"node labels": {
+			kubeletArgs: "--node-labels=team=ml,tier=gpu --max-pods=31",
+			expected:    map[string]string{"team": "ml", "tier": "gpu"},
+		},
+		"node labels using space instead of =": {
+			kubeletArgs: "--node-labels team=ml",
+			expected:    map[string]string{"team": "ml"},
+		},
+		"invalid node labels": {
+			kubeletArgs: "--node-labels=team",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			labels, err := getRegisteredNodeLabels(&oke.NodePool{NodeMetadata: map[string]string{"kubelet-extra-args": tc.kubeletArgs}})
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected err but not nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(labels, tc.expected) {
+				t.Errorf("got %v ; wanted %v", labels, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+)
+
+// ownWorkRequestRetention is how long the work requests of the resizes and node deletions of the autoscaler are
+// remembered. Work requests cycling nodes can take a lot longer, but resizes do not.
+const ownWorkRequestRetention = time.Hour
+
+// cyclingOperationTypes are the operation types of the work requests OKE cycles the nodes of node pools in, e.g. after
+// their image or Kubernetes version was upgraded.
+var cyclingOperationTypes = map[oke.WorkRequestOperationTypeEnum]bool{
+	oke.WorkRequestOperationTypeNodepoolUpdate:    true,
+	oke.WorkRequestOperationTypeNodepoolReconcile: true,
+}
+
+// refreshCycling records whether OKE is cycling the nodes of the node pool. The node pool is assumed not to be cycling
+// if its work requests cannot be listed.
+func (c *nodePoolCache) refreshCycling(nodePool *oke.NodePool) {
+	id := getString(nodePool.Id)
+	workRequestID, err := c.cyclingWorkRequest(nodePool)
+	if err != nil {
+		klog.Warningf("unable to list the work requests of node pool %s, assuming its nodes are not cycling: %v", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previousWorkRequestID := c.cycling[id]
+	if workRequestID == "" {
+		delete(c.cycling, id)
+		if previousWorkRequestID != "" {
+			klog.Infof("node pool %s is no longer cycling nodes, it is autoscaled again", id)
+		}
+		return
+	}
+	c.cycling[id] = workRequestID
+	if previousWorkRequestID != workRequestID {
+		klog.Infof("node pool %s is cycling nodes in work request %s, it is neither scaled up nor down until it is done", id, workRequestID)
+	}
+}
+
+// cyclingWorkRequest returns the ID of an accepted or in-progress work request cycling the nodes of the node pool, or
+// the empty string if there is none.
+func (c *nodePoolCache) cyclingWorkRequest(nodePool *oke.NodePool) (string, error) {
+	req := oke.ListWorkRequestsRequest{
+		CompartmentId: nodePool.CompartmentId,
+		ClusterId:     nodePool.ClusterId,
+		ResourceId:    nodePool.Id,
+		ResourceType:  oke.ListWorkRequestsResourceTypeNodepool,
+		Status:        []string{string(oke.WorkRequestStatusAccepted), string(oke.WorkRequestStatusInProgress)},
+	}
+	for {
+		resp, err := c.okeClient.ListWorkRequests(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		for _, workRequest := range resp.Items {
+			if cyclingOperationTypes[workRequest.OperationType] && !c.isOwnWorkRequest(getString(workRequest.Id)) {
+				return getString(workRequest.Id), nil
+			}
+		}
+		if resp.OpcNextPage == nil {
+			return "", nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// recordOwnWorkRequestWithoutLock remembers a work request of a resize or node deletion of the autoscaler, and forgets
+// the ones older than ownWorkRequestRetention.
+func (c *nodePoolCache) recordOwnWorkRequestWithoutLock(workRequestID *string) {
+	now := time.Now()
+	for id, startedAt := range c.ownWorkRequests {
+		if now.Sub(startedAt) > ownWorkRequestRetention {
+			delete(c.ownWorkRequests, id)
+		}
+	}
+	if workRequestID != nil {
+		c.ownWorkRequests[*workRequestID] = now
+	}
+}
+
+func (c *nodePoolCache) isOwnWorkRequest(workRequestID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.ownWorkRequests[workRequestID]
+	return ok
+}
+
+// cyclingWorkRequestID returns the ID of the work request cycling the nodes of the node pool, or the empty string if
+// its nodes are not cycling.
+func (c *nodePoolCache) cyclingWorkRequestID(id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cycling[id]
+}
+
+// CheckNodePoolCycling returns a NodeCyclingError if OKE is cycling the nodes of the NodePool, so the autoscaler
+// neither adds nodes to it nor removes nodes from it while the cycling replaces them.
+func (m *ociManagerImpl) CheckNodePoolCycling(np NodePool) error {
+	if isVirtualNodePool(np.Id()) {
+		return nil
+	}
+	if workRequestID := m.nodePoolCache.cyclingWorkRequestID(np.Id()); workRequestID != "" {
+		return &ocicommon.NodeCyclingError{NodeGroupID: np.Id(), WorkRequestID: workRequestID}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	ipconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/instancepools/consts"
 	caerrors "k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	klog "k8s.io/klog/v2"
@@ -78,6 +79,9 @@ func (ocp *OciCloudProvider) HasInstance(n *apiv1.Node) (bool, error) {
 // Implementation optional.
 func (ocp *OciCloudProvider) Pricing() (cloudprovider.PricingModel, caerrors.AutoscalerError) {
 	klog.Info("Pricing called")
+	if pricingModel := ocp.manager.GetPricingModel(); pricingModel != nil {
+		return pricingModel, nil
+	}
 	return nil, cloudprovider.ErrNotImplemented
 }
 
@@ -107,12 +111,13 @@ func (ocp *OciCloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimite
 
 // GPULabel returns the label added to nodes with GPU resource.
 func (ocp *OciCloudProvider) GPULabel() string {
-	return ""
+	// GPU nodes are also tainted nvidia.com/gpu:NoSchedule.
+	return ipconsts.GPUProductLabel
 }
 
 // GetAvailableGPUTypes return all available GPU types cloud provider supports.
 func (ocp *OciCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
-	return map[string]struct{}{}
+	return ocicommon.AvailableGPUTypes(ocp.NodeGroups())
 }
 
 // Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
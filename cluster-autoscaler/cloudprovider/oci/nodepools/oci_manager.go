@@ -61,6 +61,8 @@ type NodePoolManager interface {
 	GetNodePoolTemplateNode(np NodePool) (*apiv1.Node, error)
 	// GetNodePoolSize gets NodePool size.
 	GetNodePoolSize(np NodePool) (int, error)
+	// GetNodePoolMaxNodeProvisionTime returns the max node provision time of the NodePool, or 0 if it has no override.
+	GetNodePoolMaxNodeProvisionTime(np NodePool) (time.Duration, error)
 	// SetNodePoolSize sets NodePool size.
 	SetNodePoolSize(np NodePool, size int) error
 	// DeleteInstances deletes the given instances. All instances must be controlled by the same NodePool.
@@ -69,12 +71,17 @@ type NodePoolManager interface {
 	InvalidateAndRefreshCache() error
 	// Taint with ToBeDeletedByClusterAutoscaler to avoid unexpected CA restarts scheduling pods on a node intended to be deleted before restart
 	TaintToPreventFurtherSchedulingOnRestart(nodes []*apiv1.Node, client kubernetes.Interface) error
+	// GetPricingModel returns the pricing model of the shape snapshot, or nil if there is none.
+	GetPricingModel() cloudprovider.PricingModel
+	// CheckNodePoolCycling returns a NodeCyclingError if OKE is cycling the nodes of the NodePool.
+	CheckNodePoolCycling(np NodePool) error
 }
 
 type okeClient interface {
 	GetNodePool(context.Context, oke.GetNodePoolRequest) (oke.GetNodePoolResponse, error)
 	UpdateNodePool(context.Context, oke.UpdateNodePoolRequest) (oke.UpdateNodePoolResponse, error)
 	DeleteNode(context.Context, oke.DeleteNodeRequest) (oke.DeleteNodeResponse, error)
+	ListWorkRequests(context.Context, oke.ListWorkRequestsRequest) (oke.ListWorkRequestsResponse, error)
 }
 
 // CreateNodePoolManager creates an NodePoolManager that can manage autoscaling node pools
@@ -82,6 +89,7 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 
 	var err error
 	var configProvider common.ConfigurationProvider
+	useAPIKey := false
 
 	if os.Getenv(ipconsts.OciUseWorkloadIdentityEnvVar) == "true" {
 		klog.Info("using workload identity provider")
@@ -98,6 +106,7 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 	} else {
 		klog.Info("using default configuration provider")
 		configProvider = common.DefaultConfigProvider()
+		useAPIKey = true
 	}
 
 	cloudConfig, err := ocicommon.CreateCloudConfig(cloudConfigPath, configProvider, npconsts.OciNodePoolResourceIdent)
@@ -105,9 +114,22 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 		return nil, err
 	}
 
+	var credentials *ocicommon.ReloadingConfigurationProvider
+	if useAPIKey {
+		credentials, err = ocicommon.NewReloadingConfigurationProvider(configProvider, cloudConfig.Global.CredentialsReloadInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load OCI credentials")
+		}
+		configProvider = credentials
+	}
+
 	clientConfig := common.CustomClientConfiguration{
 		RetryPolicy: ocicommon.NewRetryPolicy(),
 	}
+	// All clients share the same rate limiter so the limits apply to the total rate of calls.
+	rateLimiter := cloudConfig.RateLimiter()
+	// Every client gets a circuit breaker of its own so a failing service does not fail the calls to the others.
+	circuitBreaker := cloudConfig.CircuitBreaker()
 
 	okeClient, err := oke.NewContainerEngineClientWithConfigurationProvider(configProvider)
 	if err != nil {
@@ -115,6 +137,9 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 	}
 
 	okeClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&okeClient.BaseClient)
+	rateLimiter.Apply(&okeClient.BaseClient)
+	ocicommon.LogRequestIDs(&okeClient.BaseClient)
 
 	// undocumented endpoint for testing in dev
 	if os.Getenv(npconsts.OkeHostOverrideEnvVar) != "" {
@@ -127,28 +152,92 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 		return nil, errors.Wrap(err, "unable to create compute management client")
 	}
 	computeMgmtClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&computeMgmtClient.BaseClient)
+	rateLimiter.Apply(&computeMgmtClient.BaseClient)
+	ocicommon.LogRequestIDs(&computeMgmtClient.BaseClient)
 
 	computeClient, err := core.NewComputeClientWithConfigurationProvider(configProvider)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create compute client")
 	}
 	computeClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&computeClient.BaseClient)
+	rateLimiter.Apply(&computeClient.BaseClient)
+	ocicommon.LogRequestIDs(&computeClient.BaseClient)
+
+	networkClient, err := core.NewVirtualNetworkClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create virtual network client")
+	}
+	networkClient.SetCustomClientConfiguration(clientConfig)
+	circuitBreaker.Apply(&networkClient.BaseClient)
+	rateLimiter.Apply(&networkClient.BaseClient)
+	ocicommon.LogRequestIDs(&networkClient.BaseClient)
+
+	flexShapeMemoryPerOcpu, err := cloudConfig.FlexShapeMemoryPerOcpuInGBs()
+	if err != nil {
+		return nil, err
+	}
+
+	shapeSnapshot, err := cloudConfig.ShapeSnapshot()
+	if err != nil {
+		return nil, err
+	}
 
 	//ociShapeGetter := ocicommon.CreateShapeGetter(computeClient)
-	ociShapeGetter := ocicommon.CreateShapeGetter(ocicommon.ShapeClientImpl{ComputeMgmtClient: computeMgmtClient, ComputeClient: computeClient})
+	shapeClient := shapeSnapshot.WrapShapeClient(ocicommon.ShapeClientImpl{ComputeMgmtClient: computeMgmtClient, ComputeClient: computeClient}, cloudConfig.Global.PreferShapeSnapshot)
+	ociShapeGetter := ocicommon.CreateShapeGetter(shapeClient, flexShapeMemoryPerOcpu)
 	ociTagsGetter := ocicommon.CreateTagsGetter()
 
 	registeredTaintsGetter := CreateRegisteredTaintsGetter()
 
+	kubeletReservation, err := cloudConfig.KubeletReservation()
+	if err != nil {
+		return nil, err
+	}
+
+	maxNodeProvisionTimeByShape, err := cloudConfig.MaxNodeProvisionTimeByShape()
+	if err != nil {
+		return nil, err
+	}
+
+	monitoring, err := cloudConfig.MonitoringPublisher(configProvider, clientConfig, rateLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	preTerminationHook, err := cloudConfig.PreTerminationHook(configProvider, clientConfig, rateLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	nodePoolCache := newNodePoolCache(&okeClient)
+	virtualNodePoolCache := newVirtualNodePoolCache(&okeClient)
+	nodePoolCache.tagLaunchedInstances = cloudConfig.Global.TagLaunchedInstances
+	nodePoolCache.clusterName = cloudConfig.Global.ClusterName
+
 	manager := &ociManagerImpl{
 		cfg:                    cloudConfig,
 		okeClient:              &okeClient,
 		computeClient:          &computeClient,
+		virtualNetworkClient:   &networkClient,
 		staticNodePools:        map[string]NodePool{},
 		ociShapeGetter:         ociShapeGetter,
 		ociTagsGetter:          ociTagsGetter,
 		registeredTaintsGetter: registeredTaintsGetter,
-		nodePoolCache:          newNodePoolCache(&okeClient),
+		nodePoolCache:          nodePoolCache,
+		virtualNodePoolCache:   virtualNodePoolCache,
+		kubeletReservation:     kubeletReservation,
+
+		maxNodeProvisionTimeByShape: maxNodeProvisionTimeByShape,
+		monitoring:                  monitoring,
+		preTerminationHook:          preTerminationHook,
+		validator:                   ocicommon.NewNodeGroupValidator(kubeClient),
+		mutationLimiter:             cloudConfig.MutationLimiter(),
+		shapeClient:                 shapeClient,
+		// Template nodes of node pools always report vCPUs.
+		pricingModel: shapeSnapshot.PricingModel(true),
+		credentials:  credentials,
 	}
 
 	// Contains all the specs from the args that give us the pools.
@@ -161,7 +250,11 @@ func CreateNodePoolManager(cloudConfigPath string, discoveryOpts cloudprovider.N
 		np.manager = manager
 		np.kubeClient = kubeClient
 
-		manager.staticNodePools[np.Id()] = np
+		if isVirtualNodePool(np.Id()) {
+			manager.staticNodePools[np.Id()] = &virtualNodePool{nodePool: np}
+		} else {
+			manager.staticNodePools[np.Id()] = np
+		}
 	}
 
 	// wait until we have an initial full cache.
@@ -211,20 +304,47 @@ type ociManagerImpl struct {
 	cfg                    *ocicommon.CloudConfig
 	okeClient              okeClient
 	computeClient          *core.ComputeClient
+	virtualNetworkClient   virtualNetworkClient
 	ociShapeGetter         ocicommon.ShapeGetter
 	ociTagsGetter          ocicommon.TagsGetter
 	registeredTaintsGetter RegisteredTaintsGetter
 	staticNodePools        map[string]NodePool
+	// kubeletReservation is deducted from the capacity of template nodes unless overridden by the
+	// kubelet-extra-args of the node pool.
+	kubeletReservation *ocicommon.KubeletReservation
+	// maxNodeProvisionTimeByShape overrides the max node provision time of node pools by shape.
+	maxNodeProvisionTimeByShape map[string]time.Duration
+	// monitoring publishes scale events to OCI Monitoring, it is nil if they are not published.
+	monitoring *ocicommon.MonitoringPublisher
+	// preTerminationHook is invoked before instances are terminated, it is nil if there is none.
+	preTerminationHook *ocicommon.PreTerminationHook
+	// mutationLimiter limits the resizes and terminations in flight, they are not limited if it is nil.
+	mutationLimiter *ocicommon.MutationLimiter
+	// validator reports misconfigured node pools, node pools are not validated if it is nil.
+	validator *ocicommon.NodeGroupValidator
+	// shapeClient lists the shape catalog the shapes of node pools are validated against, they are not validated
+	// against it if it is nil.
+	shapeClient ocicommon.ShapeClient
+	// pricingModel prices nodes and pods by the shape snapshot, it is nil if there is no snapshot.
+	pricingModel cloudprovider.PricingModel
+	// credentials reloads rotated API keys, it is nil unless the clients authenticate with an API key.
+	credentials *ocicommon.ReloadingConfigurationProvider
 
 	lastRefresh time.Time
 
 	// caches the node pool objects received from OKE.
 	// All interactions with OKE's API should go through the cache.
 	nodePoolCache *nodePoolCache
+	// virtualNodePoolCache caches the virtual node pools and their virtual nodes.
+	virtualNodePoolCache *virtualNodePoolCache
 }
 
 // Refresh triggers refresh of cached resources.
 func (m *ociManagerImpl) Refresh() error {
+	if err := m.monitoring.Flush(); err != nil {
+		klog.Warningf("unable to publish scale events: %v", err)
+	}
+
 	if m.lastRefresh.Add(m.cfg.Global.RefreshInterval).After(time.Now()) {
 		return nil
 	}
@@ -239,6 +359,11 @@ func (m *ociManagerImpl) InvalidateAndRefreshCache() error {
 	return m.Refresh()
 }
 
+// GetPricingModel returns the pricing model of the shape snapshot, or nil if there is none.
+func (m *ociManagerImpl) GetPricingModel() cloudprovider.PricingModel {
+	return m.pricingModel
+}
+
 // TaintToPreventFurtherSchedulingOnRestart adds a taint to prevent new pods from scheduling onto the node
 // this fixes a race condition where a node can be deleted, and if it's not deleted in time, the delete will retry
 // and if this second delet fails, it can make the node usable again. This taint prevents this from happening
@@ -253,7 +378,16 @@ func (m *ociManagerImpl) TaintToPreventFurtherSchedulingOnRestart(nodes []*apiv1
 }
 
 func (m *ociManagerImpl) forceRefresh() error {
-	httpStatusCode, err := m.nodePoolCache.rebuild(m.staticNodePools, maxGetNodepoolRetries)
+	nodePools := m.validNodePools()
+	var virtualNodePoolIDs []string
+	for id := range nodePools {
+		if isVirtualNodePool(id) {
+			virtualNodePoolIDs = append(virtualNodePoolIDs, id)
+			delete(nodePools, id)
+		}
+	}
+
+	httpStatusCode, err := m.nodePoolCache.rebuild(nodePools, maxGetNodepoolRetries)
 	if err != nil {
 		if httpStatusCode == 404 {
 			m.lastRefresh = time.Now()
@@ -262,6 +396,10 @@ func (m *ociManagerImpl) forceRefresh() error {
 		}
 		return err
 	}
+	if err := m.virtualNodePoolCache.rebuild(virtualNodePoolIDs); err != nil {
+		return err
+	}
+	m.reportNodePoolHealth()
 	m.lastRefresh = time.Now()
 	klog.Infof("Refreshed NodePool list, next refresh after %v", m.lastRefresh.Add(m.cfg.Global.RefreshInterval))
 	return nil
@@ -269,10 +407,29 @@ func (m *ociManagerImpl) forceRefresh() error {
 
 // Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
 func (m *ociManagerImpl) Cleanup() error {
+	m.credentials.Stop()
 	return nil
 }
 
 // GetNodePools returns list of registered NodePools.
+// reportNodePoolHealth reports the target size and the instances of the cached node pools as metrics.
+func (m *ociManagerImpl) reportNodePoolHealth() {
+	healthByNodePool := make(map[string]ocicommon.NodeGroupHealth, len(m.staticNodePools))
+	for id, np := range m.staticNodePools {
+		// Misconfigured node pools are not cached.
+		size, err := m.GetNodePoolSize(np)
+		if err != nil {
+			continue
+		}
+		instances, err := m.GetNodePoolNodes(np)
+		if err != nil {
+			continue
+		}
+		healthByNodePool[id] = ocicommon.NewNodeGroupHealth(size, instances)
+	}
+	ocicommon.ReportNodeGroupHealth(healthByNodePool)
+}
+
 func (m *ociManagerImpl) GetNodePools() []NodePool {
 	var nodePools []NodePool
 	for _, np := range m.staticNodePools {
@@ -285,6 +442,11 @@ func (m *ociManagerImpl) GetNodePools() []NodePool {
 // We do this to avoid any dependency on the internal caching that happens, so that we have the latest node pool state always
 func (m *ociManagerImpl) GetExistingNodePoolSizeViaCompute(np NodePool) (int, error) {
 	klog.V(4).Infof("getting nodes for node pool: %q", np.Id())
+	if isVirtualNodePool(np.Id()) {
+		// Virtual nodes are not compute instances.
+		nodes, err := m.getVirtualNodePoolNodes(np.Id())
+		return len(nodes), err
+	}
 	nodePoolDetails, err := m.nodePoolCache.get(np.Id())
 	if err != nil {
 		klog.V(4).Error(err, "error fetching detailed nodepool from cache")
@@ -369,6 +531,9 @@ func getDisplayNamePrefix(clusterId string, nodePoolId string) string {
 // GetNodePoolNodes returns NodePool nodes that are not in a terminal state.
 func (m *ociManagerImpl) GetNodePoolNodes(np NodePool) ([]cloudprovider.Instance, error) {
 	klog.V(4).Infof("getting nodes for node pool: %q", np.Id())
+	if isVirtualNodePool(np.Id()) {
+		return m.getVirtualNodePoolNodes(np.Id())
+	}
 
 	nodePool, err := m.nodePoolCache.get(np.Id())
 	if err != nil {
@@ -380,18 +545,11 @@ func (m *ociManagerImpl) GetNodePoolNodes(np NodePool) ([]cloudprovider.Instance
 
 		if node.NodeError != nil {
 
-			errorClass := cloudprovider.OtherErrorClass
-			if *node.NodeError.Code == "LimitExceeded" ||
-				(*node.NodeError.Code == "InternalServerError" &&
-					strings.Contains(*node.NodeError.Message, "quota")) {
-				errorClass = cloudprovider.OutOfResourcesErrorClass
-			}
-
 			instances = append(instances, cloudprovider.Instance{
 				Id: *node.Id,
 				Status: &cloudprovider.InstanceStatus{
 					ErrorInfo: &cloudprovider.InstanceErrorInfo{
-						ErrorClass:   errorClass,
+						ErrorClass:   ocicommon.ErrorClass(*node.NodeError.Code, *node.NodeError.Message),
 						ErrorCode:    *node.NodeError.Code,
 						ErrorMessage: *node.NodeError.Message,
 					},
@@ -441,6 +599,9 @@ func (m *ociManagerImpl) GetNodePoolForInstance(instance ocicommon.OciRef) (Node
 		// we're looking up an unregistered node, so we can't use node pool id.
 		nodePool, err := m.nodePoolCache.getByInstance(instance.InstanceID)
 		if err != nil {
+			if id, ok := m.virtualNodePoolCache.getByVirtualNode(instance.InstanceID); ok {
+				return m.staticNodePools[id], nil
+			}
 			return nil, err
 		}
 
@@ -458,6 +619,13 @@ func (m *ociManagerImpl) GetNodePoolForInstance(instance ocicommon.OciRef) (Node
 
 // GetNodePoolTemplateNode returns a template node for NodePool.
 func (m *ociManagerImpl) GetNodePoolTemplateNode(np NodePool) (*apiv1.Node, error) {
+	if isVirtualNodePool(np.Id()) {
+		virtualNodePool, _, err := m.virtualNodePoolCache.get(np.Id())
+		if err != nil {
+			return nil, err
+		}
+		return m.buildVirtualNodeFromTemplate(virtualNodePool)
+	}
 
 	nodePool, err := m.nodePoolCache.get(np.Id())
 	if err != nil {
@@ -472,18 +640,72 @@ func (m *ociManagerImpl) GetNodePoolTemplateNode(np NodePool) (*apiv1.Node, erro
 	return node, nil
 }
 
+// GetNodePoolMaxNodeProvisionTime returns the max node provision time of the NodePool from its
+// cluster-autoscaler/max-node-provision-time freeform tag or, failing that, from the max-node-provision-time of
+// its shape. Returns 0 if neither is set.
+func (m *ociManagerImpl) GetNodePoolMaxNodeProvisionTime(np NodePool) (time.Duration, error) {
+	if isVirtualNodePool(np.Id()) {
+		virtualNodePool, _, err := m.virtualNodePoolCache.get(np.Id())
+		if err != nil {
+			return 0, err
+		}
+		provisionTime, err := ocicommon.MaxNodeProvisionTime(virtualNodePool.FreeformTags, "", nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "virtual node pool %s", np.Id())
+		}
+		return provisionTime, nil
+	}
+
+	nodePool, err := m.nodePoolCache.get(np.Id())
+	if err != nil {
+		return 0, err
+	}
+
+	freeformTags, err := m.ociTagsGetter.GetNodePoolFreeformTags(nodePool)
+	if err != nil {
+		return 0, err
+	}
+	var shapeName string
+	if nodePool.NodeShape != nil {
+		shapeName = *nodePool.NodeShape
+	}
+
+	provisionTime, err := ocicommon.MaxNodeProvisionTime(freeformTags, shapeName, m.maxNodeProvisionTimeByShape)
+	if err != nil {
+		return 0, errors.Wrapf(err, "node pool %s", np.Id())
+	}
+	return provisionTime, nil
+}
+
 // GetNodePoolSize gets NodePool size.
 func (m *ociManagerImpl) GetNodePoolSize(np NodePool) (int, error) {
+	if isVirtualNodePool(np.Id()) {
+		return m.virtualNodePoolCache.getSize(np.Id())
+	}
 	return m.nodePoolCache.getSize(np.Id())
 }
 
 // SetNodePoolSize sets NodePool size.
 func (m *ociManagerImpl) SetNodePoolSize(np NodePool, size int) error {
-
-	err := m.nodePoolCache.setSize(np.Id(), size)
+	previousSize, _ := m.GetNodePoolSize(np)
+	release, err := m.mutationLimiter.Acquire(context.Background(), np.Id())
 	if err != nil {
 		return err
 	}
+	if isVirtualNodePool(np.Id()) {
+		err = m.virtualNodePoolCache.setSize(np.Id(), size)
+	} else {
+		err = m.nodePoolCache.setSize(np.Id(), size)
+	}
+	release()
+	if err != nil {
+		return err
+	}
+	if size > previousSize {
+		m.monitoring.RecordScaleUp(np.Id(), size-previousSize)
+	} else {
+		m.monitoring.RecordScaleDown(np.Id(), previousSize-size)
+	}
 
 	// We do not wait for the work request to finish or nodes become active on purpose. This allows
 	// the autoscaler to make decisions quicker especially since the autoscaler is aware of
@@ -495,13 +717,23 @@ func (m *ociManagerImpl) SetNodePoolSize(np NodePool, size int) error {
 // DeleteInstances deletes the given instances. All instances must be controlled by the same NodePool.
 func (m *ociManagerImpl) DeleteInstances(np NodePool, instances []ocicommon.OciRef) error {
 	klog.Infof("DeleteInstances called")
+	if err := m.preTerminationHook.Run(np.Id(), instances); err != nil {
+		return err
+	}
+	instanceIDs := make([]string, 0, len(instances))
 	for _, instance := range instances {
-		err := m.nodePoolCache.removeInstance(np.Id(), instance.InstanceID)
-		if err != nil {
-			return err
-		}
+		instanceIDs = append(instanceIDs, instance.InstanceID)
 	}
-	return nil
+	release, err := m.mutationLimiter.Acquire(context.Background(), np.Id())
+	if err != nil {
+		return err
+	}
+	removed, err := m.nodePoolCache.removeInstances(np.Id(), instanceIDs)
+	release()
+	if removed > 0 {
+		m.monitoring.RecordScaleDown(np.Id(), removed)
+	}
+	return err
 }
 
 func (m *ociManagerImpl) buildNodeFromTemplate(nodePool *oke.NodePool) (*apiv1.Node, error) {
@@ -514,6 +746,15 @@ func (m *ociManagerImpl) buildNodeFromTemplate(nodePool *oke.NodePool) (*apiv1.N
 		Labels: map[string]string{},
 	}
 
+	// Add the labels kubelet is configured to register the nodes with.
+	registeredLabels, err := getRegisteredNodeLabels(nodePool)
+	if err != nil {
+		klog.Warningf("could not extract node labels from the nodepool: %s. Continuing on with the initial node labels only", err)
+	}
+	for key, value := range registeredLabels {
+		node.ObjectMeta.Labels[key] = value
+	}
+
 	// Add all the initial node labels from the NodePool configuration to the
 	// templated node.
 	for _, kv := range nodePool.InitialNodeLabels {
@@ -564,7 +805,12 @@ func (m *ociManagerImpl) buildNodeFromTemplate(nodePool *oke.NodePool) (*apiv1.N
 		node.Status.Capacity[apiv1.ResourceEphemeralStorage] = *resource.NewQuantity(ephemeralStorage, resource.DecimalSI)
 	}
 
-	node.Status.Allocatable = node.Status.Capacity
+	kubeletReservation, err := ocicommon.KubeletReservationFromArgs(getKubeletExtraArgs(nodePool), m.kubeletReservation)
+	if err != nil {
+		klog.Warningf("could not extract kubelet reservations from the nodepool: %s. Continuing on with the configured reservations", err)
+		kubeletReservation = m.kubeletReservation
+	}
+	node.Status.Allocatable = kubeletReservation.Allocatable(node.Status.Capacity)
 
 	availabilityDomain, err := getNodePoolAvailabilityDomain(nodePool)
 	if err != nil {
@@ -572,11 +818,25 @@ func (m *ociManagerImpl) buildNodeFromTemplate(nodePool *oke.NodePool) (*apiv1.N
 	}
 
 	node.Labels = cloudprovider.JoinStringMaps(node.Labels, ocicommon.BuildGenericLabels(*nodePool.Id, nodeName, shape.Name, availabilityDomain))
+	if gpuProduct := ocicommon.GPUProductForShape(shape.Name); shape.GPU > 0 && gpuProduct != "" {
+		node.Labels[ipconsts.GPUProductLabel] = gpuProduct
+	}
+	if launchConfigHash := ocicommon.LaunchConfigHash(getNodePoolImageID(nodePool), nodePool.NodeMetadata); launchConfigHash != "" {
+		node.Labels[ocicommon.LaunchConfigHashLabel] = launchConfigHash
+	}
 
 	node.Status.Conditions = cloudprovider.BuildReadyConditions()
 	return &node, nil
 }
 
+// getNodePoolImageID returns the image the nodes of the node pool are launched from, or an empty string if unknown.
+func getNodePoolImageID(nodePool *oke.NodePool) string {
+	if sourceDetails, ok := nodePool.NodeSourceDetails.(oke.NodeSourceViaImageDetails); ok && sourceDetails.ImageId != nil {
+		return *sourceDetails.ImageId
+	}
+	return ""
+}
+
 // getNodePoolAvailabilityDomain determines the availability of the node pool.
 // This breaks down if the customer specifies more than one placement configuration,
 // so best practices should be a node pool per AD if customers care about it during scheduling.
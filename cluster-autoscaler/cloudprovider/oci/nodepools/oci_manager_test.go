@@ -6,12 +6,15 @@ package nodepools
 
 import (
 	"context"
+	"errors"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools/consts"
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
 	kubeletapis "k8s.io/kubelet/pkg/apis"
@@ -316,7 +319,10 @@ func TestBuildGenericLabels(t *testing.T) {
 
 }
 
-type mockOKEClient struct{}
+type mockOKEClient struct {
+	// failedNodeIDs are the nodes whose deletion fails.
+	failedNodeIDs map[string]bool
+}
 
 func (c mockOKEClient) GetNodePool(context.Context, oke.GetNodePoolRequest) (oke.GetNodePoolResponse, error) {
 	return oke.GetNodePoolResponse{}, nil
@@ -324,7 +330,15 @@ func (c mockOKEClient) GetNodePool(context.Context, oke.GetNodePoolRequest) (oke
 func (c mockOKEClient) UpdateNodePool(context.Context, oke.UpdateNodePoolRequest) (oke.UpdateNodePoolResponse, error) {
 	return oke.UpdateNodePoolResponse{}, nil
 }
-func (c mockOKEClient) DeleteNode(context.Context, oke.DeleteNodeRequest) (oke.DeleteNodeResponse, error) {
+func (c mockOKEClient) DeleteNode(_ context.Context, req oke.DeleteNodeRequest) (oke.DeleteNodeResponse, error) {
+	if c.failedNodeIDs[*req.NodeId] {
+		return oke.DeleteNodeResponse{
+			RawResponse: &http.Response{
+				Status:     "429 Too Many Requests",
+				StatusCode: http.StatusTooManyRequests,
+			},
+		}, errors.New("too many requests")
+	}
 	return oke.DeleteNodeResponse{
 		RawResponse: &http.Response{
 			Status:     "200 OK",
@@ -332,6 +346,9 @@ func (c mockOKEClient) DeleteNode(context.Context, oke.DeleteNodeRequest) (oke.D
 		},
 	}, nil
 }
+func (c mockOKEClient) ListWorkRequests(context.Context, oke.ListWorkRequestsRequest) (oke.ListWorkRequestsResponse, error) {
+	return oke.ListWorkRequestsResponse{}, nil
+}
 
 func TestRemoveInstance(t *testing.T) {
 	instanceId1 := "instance1"
@@ -345,7 +362,8 @@ func TestRemoveInstance(t *testing.T) {
 	expectedInstances := map[string]int{instanceId4: 1, instanceId5: 1, instanceId6: 1}
 
 	nodePoolCache := newNodePoolCache(nil)
-	nodePoolCache.okeClient = mockOKEClient{}
+	nodePoolCache.okeClient = mockOKEClient{failedNodeIDs: map[string]bool{instanceId4: true}}
+	nodePoolCache.targetSize[nodePoolId] = 6
 	nodePoolCache.cache[nodePoolId] = &oke.NodePool{
 		Nodes: []oke.Node{
 			{Id: common.String(instanceId1), LifecycleState: oke.NodeLifecycleStateDeleting},
@@ -357,16 +375,16 @@ func TestRemoveInstance(t *testing.T) {
 		},
 	}
 
-	if err := nodePoolCache.removeInstance(nodePoolId, instanceId1); err != nil {
-		t.Errorf("Remove instance #{instanceId1} incorrectly")
+	// The deletion of instance4 fails, the instances before and after it are still removed.
+	removed, err := nodePoolCache.removeInstances(nodePoolId, []string{instanceId1, instanceId2, instanceId4, instanceId3})
+	if err == nil {
+		t.Errorf("Removed instance %q that should have failed", instanceId4)
 	}
-
-	if err := nodePoolCache.removeInstance(nodePoolId, instanceId2); err != nil {
-		t.Errorf("Remove instance #{instanceId2} incorrectly")
+	if removed != 3 {
+		t.Errorf("Removed %d instances; expected 3", removed)
 	}
-
-	if err := nodePoolCache.removeInstance(nodePoolId, instanceId3); err != nil {
-		t.Errorf("Fail to remove instance #{instanceId3}")
+	if nodePoolCache.targetSize[nodePoolId] != 3 {
+		t.Errorf("Get incorrect target size %d; expected size is 3", nodePoolCache.targetSize[nodePoolId])
 	}
 
 	if len(nodePoolCache.cache[nodePoolId].Nodes) != 3 {
@@ -381,3 +399,220 @@ func TestRemoveInstance(t *testing.T) {
 		}
 	}
 }
+
+func TestGetNodePoolTemplateNodeKubeletReservation(t *testing.T) {
+	nodePoolCache := newNodePoolCache(nil)
+	nodePoolCache.cache["ocid1.nodepool.oc1.phx.aaaaaaaa1"] = &oke.NodePool{
+		Id:            common.String("ocid1.nodepool.oc1.phx.aaaaaaaa1"),
+		CompartmentId: common.String("ocid1.compartment.oc1..aaaaaaaa1"),
+		NodeShape:     common.String("VM.Standard.E4.Flex"),
+		NodeShapeConfig: &oke.NodeShapeConfig{
+			Ocpus:       common.Float32(2),
+			MemoryInGBs: common.Float32(16),
+		},
+		NodeMetadata: map[string]string{
+			"kubelet-extra-args": `--kube-reserved="cpu=500m,memory=1Gi"`,
+		},
+		NodeConfigDetails: &oke.NodePoolNodeConfigDetails{
+			PlacementConfigs: []oke.NodePoolPlacementConfigDetails{{
+				AvailabilityDomain: common.String("hash:PHX-AD-1"),
+			}},
+		},
+	}
+
+	defaults, err := ocicommon.NewKubeletReservation("cpu=100m", "memory=512Mi", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	manager := &ociManagerImpl{
+		nodePoolCache:          nodePoolCache,
+		ociShapeGetter:         ocicommon.CreateShapeGetter(nil, nil),
+		ociTagsGetter:          ocicommon.CreateTagsGetter(),
+		registeredTaintsGetter: CreateRegisteredTaintsGetter(),
+		kubeletReservation:     defaults,
+	}
+
+	node, err := manager.GetNodePoolTemplateNode(&nodePool{id: "ocid1.nodepool.oc1.phx.aaaaaaaa1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// kube-reserved comes from the node pool, system-reserved from the defaults.
+	if got := node.Status.Allocatable[apiv1.ResourceCPU]; got.Cmp(resource.MustParse("3500m")) != 0 {
+		t.Errorf("got allocatable cpu %s ; wanted 3500m", got.String())
+	}
+	if got := node.Status.Allocatable[apiv1.ResourceMemory]; got.Cmp(resource.MustParse("14848Mi")) != 0 {
+		t.Errorf("got allocatable memory %s ; wanted 14848Mi", got.String())
+	}
+	if got := node.Status.Capacity[apiv1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("got capacity cpu %s ; wanted 4", got.String())
+	}
+}
+
+func TestGetNodePoolMaxNodeProvisionTime(t *testing.T) {
+	nodePoolCache := newNodePoolCache(nil)
+	nodePoolCache.cache["ocid1.nodepool.oc1.phx.aaaaaaaa1"] = &oke.NodePool{
+		Id:           common.String("ocid1.nodepool.oc1.phx.aaaaaaaa1"),
+		NodeShape:    common.String("BM.GPU4.8"),
+		FreeformTags: map[string]string{ocicommon.MaxNodeProvisionTimeTag: "1h"},
+	}
+	nodePoolCache.cache["ocid1.nodepool.oc1.phx.aaaaaaaa2"] = &oke.NodePool{
+		Id:        common.String("ocid1.nodepool.oc1.phx.aaaaaaaa2"),
+		NodeShape: common.String("BM.GPU4.8"),
+	}
+	nodePoolCache.cache["ocid1.nodepool.oc1.phx.aaaaaaaa3"] = &oke.NodePool{
+		Id:        common.String("ocid1.nodepool.oc1.phx.aaaaaaaa3"),
+		NodeShape: common.String("VM.Standard.E4.Flex"),
+	}
+
+	manager := &ociManagerImpl{
+		nodePoolCache:               nodePoolCache,
+		ociTagsGetter:               ocicommon.CreateTagsGetter(),
+		maxNodeProvisionTimeByShape: map[string]time.Duration{"BM.GPU4.8": 30 * time.Minute},
+	}
+
+	testCases := map[string]struct {
+		id       string
+		expected time.Duration
+	}{
+		"tag": {
+			id:       "ocid1.nodepool.oc1.phx.aaaaaaaa1",
+			expected: time.Hour,
+		},
+		"shape": {
+			id:       "ocid1.nodepool.oc1.phx.aaaaaaaa2",
+			expected: 30 * time.Minute,
+		},
+		"no override": {
+			id:       "ocid1.nodepool.oc1.phx.aaaaaaaa3",
+			expected: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := manager.GetNodePoolMaxNodeProvisionTime(&nodePool{id: tc.id})
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %v ; wanted %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+// recordingOKEClient records the UpdateNodePool requests it receives.
+type recordingOKEClient struct {
+	mockOKEClient
+	updates []oke.UpdateNodePoolRequest
+}
+
+func (c *recordingOKEClient) UpdateNodePool(_ context.Context, req oke.UpdateNodePoolRequest) (oke.UpdateNodePoolResponse, error) {
+	c.updates = append(c.updates, req)
+	return oke.UpdateNodePoolResponse{}, nil
+}
+
+func TestSetSizeTagsLaunchedInstances(t *testing.T) {
+	const nodePoolID = "ocid1.nodepool.oc1.phx.aaaaaaaa1"
+	client := &recordingOKEClient{}
+	nodePoolCache := newNodePoolCache(nil)
+	nodePoolCache.okeClient = client
+	nodePoolCache.tagLaunchedInstances = true
+	nodePoolCache.clusterName = "cluster-1"
+	nodePoolCache.cache[nodePoolID] = &oke.NodePool{
+		Id: common.String(nodePoolID),
+		NodeConfigDetails: &oke.NodePoolNodeConfigDetails{
+			FreeformTags: map[string]string{"team": "ml"},
+		},
+	}
+
+	if err := nodePoolCache.setSize(nodePoolID, 3); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected := map[string]string{
+		"team":                   "ml",
+		ocicommon.ManagedByTag:   ocicommon.ManagedByTagValue,
+		ocicommon.ClusterNameTag: "cluster-1",
+		ocicommon.NodeGroupTag:   nodePoolID,
+	}
+	if got := client.updates[0].NodeConfigDetails.FreeformTags; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v ; wanted %v", got, expected)
+	}
+
+	// Node pools that are already tagged only have their size updated.
+	nodePoolCache.cache[nodePoolID].NodeConfigDetails.FreeformTags = expected
+	if err := nodePoolCache.setSize(nodePoolID, 4); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := client.updates[1].NodeConfigDetails.FreeformTags; got != nil {
+		t.Errorf("got %v ; wanted no tags", got)
+	}
+}
+
+// workRequestsOKEClient lists the same work requests for every node pool, and starts a work request for every resize.
+type workRequestsOKEClient struct {
+	mockOKEClient
+	workRequests []oke.WorkRequestSummary
+}
+
+func (c *workRequestsOKEClient) UpdateNodePool(context.Context, oke.UpdateNodePoolRequest) (oke.UpdateNodePoolResponse, error) {
+	return oke.UpdateNodePoolResponse{OpcWorkRequestId: common.String("ocid1.clustersworkrequest.oc1.phx.resize")}, nil
+}
+
+func (c *workRequestsOKEClient) ListWorkRequests(context.Context, oke.ListWorkRequestsRequest) (oke.ListWorkRequestsResponse, error) {
+	return oke.ListWorkRequestsResponse{Items: c.workRequests}, nil
+}
+
+func TestCheckNodePoolCycling(t *testing.T) {
+	const nodePoolID = "ocid1.nodepool.oc1.phx.aaaaaaaa1"
+	workRequest := func(id string, operationType oke.WorkRequestOperationTypeEnum) oke.WorkRequestSummary {
+		return oke.WorkRequestSummary{Id: common.String(id), OperationType: operationType, Status: oke.WorkRequestStatusInProgress}
+	}
+
+	testCases := map[string]struct {
+		workRequests []oke.WorkRequestSummary
+		expected     string
+	}{
+		"no work requests": {},
+		"cycling": {
+			workRequests: []oke.WorkRequestSummary{
+				workRequest("ocid1.clustersworkrequest.oc1.phx.delete", oke.WorkRequestOperationTypeNodepoolDelete),
+				workRequest("ocid1.clustersworkrequest.oc1.phx.cycle", oke.WorkRequestOperationTypeNodepoolUpdate),
+			},
+			expected: "ocid1.clustersworkrequest.oc1.phx.cycle",
+		},
+		"resized by the autoscaler": {
+			workRequests: []oke.WorkRequestSummary{
+				workRequest("ocid1.clustersworkrequest.oc1.phx.resize", oke.WorkRequestOperationTypeNodepoolUpdate),
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			nodePoolCache := newNodePoolCache(nil)
+			nodePoolCache.okeClient = &workRequestsOKEClient{workRequests: tc.workRequests}
+			nodePoolCache.cache[nodePoolID] = &oke.NodePool{Id: common.String(nodePoolID)}
+			manager := &ociManagerImpl{nodePoolCache: nodePoolCache}
+			np := &nodePool{id: nodePoolID}
+
+			if err := nodePoolCache.setSize(nodePoolID, 3); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			nodePoolCache.refreshCycling(nodePoolCache.cache[nodePoolID])
+
+			err := manager.CheckNodePoolCycling(np)
+			var cyclingErr *ocicommon.NodeCyclingError
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("got error %v ; wanted none", err)
+				}
+				return
+			}
+			if !errors.As(err, &cyclingErr) || cyclingErr.WorkRequestID != tc.expected {
+				t.Errorf("got error %v ; wanted a NodeCyclingError for work request %s", err, tc.expected)
+			}
+		})
+	}
+}
@@ -96,6 +96,10 @@ func (np *nodePool) IncreaseSize(delta int) error {
 	nodePoolDeleteMutex.Lock()
 	defer nodePoolDeleteMutex.Unlock()
 
+	if err := np.manager.CheckNodePoolCycling(np); err != nil {
+		return err
+	}
+
 	size, err := np.manager.GetNodePoolSize(np)
 	if err != nil {
 		return err
@@ -113,6 +117,11 @@ func (np *nodePool) AtomicIncreaseSize(delta int) error {
 	return cloudprovider.ErrNotImplemented
 }
 
+// WarmCapacity is not implemented.
+func (np *nodePool) WarmCapacity() (int, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
 // DeleteNodes deletes nodes from this node group. Error is returned either on
 // failure or if the given node doesn't belong to this node group. This function
 // should wait until node group size is updated. Implementation required.
@@ -127,6 +136,11 @@ func (np *nodePool) DeleteNodes(nodes []*apiv1.Node) (err error) {
 
 	klog.Infof("DeleteNodes called with %d nodes", len(nodes))
 
+	// The nodes of node pools OKE is cycling are replaced by the cycling, removing them would fight it.
+	if err := np.manager.CheckNodePoolCycling(np); err != nil {
+		return err
+	}
+
 	size, err := np.manager.GetNodePoolSize(np)
 	if err != nil {
 		return err
@@ -309,7 +323,11 @@ func (np *nodePool) Autoprovisioned() bool {
 
 // GetOptions returns NodeGroupAutoscalingOptions that should be used for this particular
 // NodeGroup. Returning a nil will result in using default options.
-// Implementation optional.
+// Only MaxNodeProvisionTime can be overridden, see GetNodePoolMaxNodeProvisionTime.
 func (np *nodePool) GetOptions(defaults config.NodeGroupAutoscalingOptions) (*config.NodeGroupAutoscalingOptions, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	maxNodeProvisionTime, err := np.manager.GetNodePoolMaxNodeProvisionTime(np)
+	if err != nil {
+		return nil, err
+	}
+	return ocicommon.NodeGroupOptions(defaults, maxNodeProvisionTime), nil
 }
@@ -6,6 +6,7 @@ package nodepools
 
 import (
 	"context"
+	"errors"
 	apiv1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,6 +53,29 @@ func TestDeletePastMinSize(t *testing.T) {
 	}
 }
 
+func TestNodePoolCycling(t *testing.T) {
+	manager := &mockManager{
+		cyclingErr: &ocicommon.NodeCyclingError{NodeGroupID: "abc", WorkRequestID: "ocid1.clustersworkrequest.oc1.phx.aaaaaaaa1"},
+	}
+	np := &nodePool{
+		kubeClient: fake.NewSimpleClientset(),
+		manager:    manager,
+		minSize:    1,
+		maxSize:    10,
+		id:         "abc",
+	}
+	manager.nodePool = np
+
+	var cyclingErr *ocicommon.NodeCyclingError
+	if err := np.IncreaseSize(1); !errors.As(err, &cyclingErr) {
+		t.Errorf("got error %v on scale up ; wanted a NodeCyclingError", err)
+	}
+	node := &apiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "nodeA"}}
+	if err := np.DeleteNodes([]*apiv1.Node{node}); !errors.As(err, &cyclingErr) {
+		t.Errorf("got error %v on scale down ; wanted a NodeCyclingError", err)
+	}
+}
+
 type mockManager struct {
 	called    []string
 	nodePools []NodePool
@@ -62,6 +86,8 @@ type mockManager struct {
 	NodePoolManager
 	err        error
 	timeOutErr error
+	// cyclingErr is returned by CheckNodePoolCycling.
+	cyclingErr error
 }
 
 func (m mockManager) Refresh() error {
@@ -113,3 +139,8 @@ func (m mockManager) DeleteInstances(np NodePool, instances []ocicommon.OciRef)
 	m.called = append(m.called, "delete-instances")
 	return m.timeOutErr
 }
+
+func (m mockManager) CheckNodePoolCycling(np NodePool) error {
+	m.called = append(m.called, "check-node-pool-cycling")
+	return m.cyclingErr
+}
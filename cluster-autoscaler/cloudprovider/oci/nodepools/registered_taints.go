@@ -27,11 +27,10 @@ func CreateRegisteredTaintsGetter() RegisteredTaintsGetter {
 type registeredTaintsGetterImpl struct{}
 
 func (otg *registeredTaintsGetterImpl) Get(np *oke.NodePool) ([]apiv1.Taint, error) {
-	metadata := np.NodeMetadata
-	kubeletArgs, ok := metadata["kubelet-extra-args"]
+	kubeletArgs := getKubeletExtraArgs(np)
 
 	// if user didn't specify any extra args on kubelet, then we know there can't be any initial taints
-	if !ok || len(kubeletArgs) == 0 {
+	if len(kubeletArgs) == 0 {
 		return []apiv1.Taint{}, nil
 	}
 
@@ -0,0 +1,102 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"context"
+	"fmt"
+
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/klog/v2"
+)
+
+type virtualNetworkClient interface {
+	GetSubnet(context.Context, core.GetSubnetRequest) (core.GetSubnetResponse, error)
+}
+
+// validNodePools returns the node pools that are not known to be misconfigured. Node pools that were not validated yet
+// are validated first, and misconfigured ones are reported and left out, so they do not fail the refresh of the
+// others. They are validated again on every refresh until they are fixed.
+func (m *ociManagerImpl) validNodePools() map[string]NodePool {
+	valid := make(map[string]NodePool, len(m.staticNodePools))
+	for id, np := range m.staticNodePools {
+		if !m.validator.IsValid(id) {
+			problem, err := m.validateNodePool(id)
+			if err != nil {
+				// The node pool is kept, so whatever failed fails the refresh as it would without validation.
+				klog.Warningf("unable to validate node pool %s: %v", id, err)
+			} else {
+				m.validator.Report(id, problem)
+				if problem != nil {
+					continue
+				}
+			}
+		}
+		valid[id] = np
+	}
+	return valid
+}
+
+// validateNodePool checks that the node pool exists, that its shape can be resolved and is offered in the availability
+// domains of the node pool, and that the subnets it places nodes in exist. It returns the problem with the
+// configuration of the node pool, or an error if it could not be validated.
+func (m *ociManagerImpl) validateNodePool(id string) (problem error, err error) {
+	if isVirtualNodePool(id) {
+		return m.validateVirtualNodePool(id)
+	}
+	resp, err := m.okeClient.GetNodePool(context.Background(), oke.GetNodePoolRequest{
+		NodePoolId: common.String(id),
+	})
+	if err != nil {
+		if ocicommon.IsNotFound(err) {
+			return fmt.Errorf("node pool does not exist or is not accessible"), nil
+		}
+		return nil, err
+	}
+
+	if _, err := m.ociShapeGetter.GetNodePoolShape(&resp.NodePool, 0); err != nil {
+		if ocicommon.IsMisconfiguration(err) {
+			return fmt.Errorf("shape %s cannot be resolved: %v", getString(resp.NodeShape), err), nil
+		}
+		return nil, err
+	}
+
+	if m.shapeClient != nil {
+		var availabilityDomains []string
+		if resp.NodeConfigDetails != nil {
+			for _, placement := range resp.NodeConfigDetails.PlacementConfigs {
+				if placement.AvailabilityDomain != nil {
+					availabilityDomains = append(availabilityDomains, *placement.AvailabilityDomain)
+				}
+			}
+		}
+		problem, err := ocicommon.CheckShapeAvailability(context.Background(), m.shapeClient, resp.CompartmentId, getString(resp.NodeShape), availabilityDomains)
+		if problem != nil || err != nil {
+			return problem, err
+		}
+	}
+
+	if resp.NodeConfigDetails == nil || m.virtualNetworkClient == nil {
+		return nil, nil
+	}
+	for _, placement := range resp.NodeConfigDetails.PlacementConfigs {
+		if placement.SubnetId == nil {
+			continue
+		}
+		_, err := m.virtualNetworkClient.GetSubnet(context.Background(), core.GetSubnetRequest{
+			SubnetId: placement.SubnetId,
+		})
+		if err != nil {
+			if ocicommon.IsNotFound(err) {
+				return fmt.Errorf("subnet %s does not exist or is not accessible", *placement.SubnetId), nil
+			}
+			return nil, err
+		}
+	}
+	return nil, nil
+}
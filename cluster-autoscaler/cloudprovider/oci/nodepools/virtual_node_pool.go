@@ -0,0 +1,303 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	npconsts "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/nodepools/consts"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/core"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// isVirtualNodePool returns true if the OCID is the OCID of a virtual node pool.
+func isVirtualNodePool(id string) bool {
+	return strings.HasPrefix(id, "ocid1."+npconsts.OciVirtualNodePoolResourceIdent+".")
+}
+
+// virtualNodePool is a node group of OKE virtual nodes, which run every pod on capacity of the pod shape and are
+// billed by the resources of their pods rather than by the node. They serve as burst capacity: only pods that tolerate
+// the taints of the virtual node pool land on them, and the expander can pick them while the node pools of VMs are
+// backed off, e.g. by giving them a lower priority in the priority expander.
+type virtualNodePool struct {
+	*nodePool
+}
+
+// DeleteNodes is not supported, idle virtual nodes cost nothing and OKE does not delete specific virtual nodes.
+func (np *virtualNodePool) DeleteNodes(nodes []*apiv1.Node) error {
+	return fmt.Errorf("virtual nodes of virtual node pool %s are not scaled down", np.Id())
+}
+
+// TemplateNodeInfo returns the template of a virtual node, which runs no daemon set pods.
+func (np *virtualNodePool) TemplateNodeInfo() (*schedulerframework.NodeInfo, error) {
+	node, err := np.manager.GetNodePoolTemplateNode(np)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build virtual node pool template")
+	}
+
+	nodeInfo := schedulerframework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	return nodeInfo, nil
+}
+
+// GetOptions returns options that keep virtual nodes from being considered for scale down, see DeleteNodes.
+func (np *virtualNodePool) GetOptions(defaults config.NodeGroupAutoscalingOptions) (*config.NodeGroupAutoscalingOptions, error) {
+	options, err := np.nodePool.GetOptions(defaults)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &defaults
+	}
+	// Nodes are only unneeded if their utilization is below the threshold, which no utilization is below 0.
+	options.ScaleDownUtilizationThreshold = 0
+	options.ScaleDownGpuUtilizationThreshold = 0
+	return options, nil
+}
+
+type virtualNodePoolClient interface {
+	GetVirtualNodePool(context.Context, oke.GetVirtualNodePoolRequest) (oke.GetVirtualNodePoolResponse, error)
+	ListVirtualNodes(context.Context, oke.ListVirtualNodesRequest) (oke.ListVirtualNodesResponse, error)
+	UpdateVirtualNodePool(context.Context, oke.UpdateVirtualNodePoolRequest) (oke.UpdateVirtualNodePoolResponse, error)
+}
+
+func newVirtualNodePoolCache(client virtualNodePoolClient) *virtualNodePoolCache {
+	return &virtualNodePoolCache{
+		client:     client,
+		cache:      map[string]*oke.VirtualNodePool{},
+		nodes:      map[string][]oke.VirtualNodeSummary{},
+		targetSize: map[string]int{},
+	}
+}
+
+// virtualNodePoolCache caches the virtual node pools and their virtual nodes received from OKE.
+type virtualNodePoolCache struct {
+	mu         sync.Mutex
+	client     virtualNodePoolClient
+	cache      map[string]*oke.VirtualNodePool
+	nodes      map[string][]oke.VirtualNodeSummary
+	targetSize map[string]int
+}
+
+func (c *virtualNodePoolCache) rebuild(ids []string) error {
+	for _, id := range ids {
+		resp, err := c.client.GetVirtualNodePool(context.Background(), oke.GetVirtualNodePoolRequest{
+			VirtualNodePoolId: common.String(id),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "unable to get virtual node pool %s", id)
+		}
+
+		var nodes []oke.VirtualNodeSummary
+		req := oke.ListVirtualNodesRequest{VirtualNodePoolId: common.String(id)}
+		for {
+			listResp, err := c.client.ListVirtualNodes(context.Background(), req)
+			if err != nil {
+				return errors.Wrapf(err, "unable to list virtual nodes of virtual node pool %s", id)
+			}
+			nodes = append(nodes, listResp.Items...)
+			if listResp.OpcNextPage == nil {
+				break
+			}
+			req.Page = listResp.OpcNextPage
+		}
+
+		c.mu.Lock()
+		c.cache[id] = &resp.VirtualNodePool
+		c.nodes[id] = nodes
+		if resp.Size != nil {
+			c.targetSize[id] = *resp.Size
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *virtualNodePoolCache) get(id string) (*oke.VirtualNodePool, []oke.VirtualNodeSummary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	virtualNodePool := c.cache[id]
+	if virtualNodePool == nil {
+		return nil, nil, errors.New("virtual node pool was not found in cache")
+	}
+	return virtualNodePool, c.nodes[id], nil
+}
+
+// getByVirtualNode returns the OCID of the virtual node pool of the virtual node, or false if it is not cached.
+func (c *virtualNodePoolCache) getByVirtualNode(virtualNodeID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, nodes := range c.nodes {
+		for _, node := range nodes {
+			if node.Id != nil && *node.Id == virtualNodeID {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (c *virtualNodePoolCache) getSize(id string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size, ok := c.targetSize[id]
+	if !ok {
+		return -1, errors.New("target size not found")
+	}
+	return size, nil
+}
+
+func (c *virtualNodePoolCache) setSize(id string, size int) error {
+	resp, err := c.client.UpdateVirtualNodePool(context.Background(), oke.UpdateVirtualNodePoolRequest{
+		VirtualNodePoolId: common.String(id),
+		UpdateVirtualNodePoolDetails: oke.UpdateVirtualNodePoolDetails{
+			Size: common.Int(size),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Updated virtual node pool size", "virtualNodePool", id, "size", size, "opcRequestID", ocicommon.ResponseOpcRequestID(resp.OpcRequestId))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.targetSize[id] = size
+	return nil
+}
+
+// getVirtualNodePoolNodes returns the virtual nodes of the virtual node pool that are not deleted.
+func (m *ociManagerImpl) getVirtualNodePoolNodes(id string) ([]cloudprovider.Instance, error) {
+	_, nodes, err := m.virtualNodePoolCache.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []cloudprovider.Instance
+	for _, node := range nodes {
+		instance := cloudprovider.Instance{Id: *node.Id, Status: &cloudprovider.InstanceStatus{}}
+		switch node.LifecycleState {
+		case oke.VirtualNodeLifecycleStateDeleted:
+			continue
+		case oke.VirtualNodeLifecycleStateDeleting:
+			instance.Status.State = cloudprovider.InstanceDeleting
+		case oke.VirtualNodeLifecycleStateCreating, oke.VirtualNodeLifecycleStateUpdating:
+			instance.Status.State = cloudprovider.InstanceCreating
+		case oke.VirtualNodeLifecycleStateFailed:
+			instance.Status.ErrorInfo = &cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.OtherErrorClass,
+				ErrorCode:    string(node.LifecycleState),
+				ErrorMessage: getString(node.VirtualNodeError),
+			}
+		default:
+			instance.Status.State = cloudprovider.InstanceRunning
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// buildVirtualNodeFromTemplate returns a template node with the labels and taints of the virtual node pool, whose
+// instance type is the pod shape so that pods can be priced by it.
+func (m *ociManagerImpl) buildVirtualNodeFromTemplate(virtualNodePool *oke.VirtualNodePool) (*apiv1.Node, error) {
+	if len(virtualNodePool.PlacementConfigurations) == 0 || virtualNodePool.PlacementConfigurations[0].AvailabilityDomain == nil {
+		return nil, fmt.Errorf("virtual node pool %q has no placement configurations", *virtualNodePool.Id)
+	}
+	availabilityDomain := *virtualNodePool.PlacementConfigurations[0].AvailabilityDomain
+	if parts := strings.SplitN(availabilityDomain, ":", 2); len(parts) == 2 {
+		availabilityDomain = parts[1]
+	}
+	var podShape string
+	if virtualNodePool.PodConfiguration != nil && virtualNodePool.PodConfiguration.Shape != nil {
+		podShape = *virtualNodePool.PodConfiguration.Shape
+	}
+
+	nodeName := fmt.Sprintf("%s-%d", "vk", 555555)
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   nodeName,
+			Labels: ocicommon.BuildGenericLabels(*virtualNodePool.Id, nodeName, podShape, availabilityDomain),
+		},
+	}
+	for _, label := range virtualNodePool.InitialVirtualNodeLabels {
+		if label.Key != nil {
+			node.Labels[*label.Key] = getString(label.Value)
+		}
+	}
+	for _, taint := range virtualNodePool.Taints {
+		if taint.Key == nil || taint.Effect == nil {
+			continue
+		}
+		node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
+			Key:    *taint.Key,
+			Value:  getString(taint.Value),
+			Effect: apiv1.TaintEffect(*taint.Effect),
+		})
+	}
+
+	node.Status.Capacity = apiv1.ResourceList{
+		apiv1.ResourcePods:   *resource.NewQuantity(npconsts.VirtualNodeMaxPods, resource.DecimalSI),
+		apiv1.ResourceCPU:    resource.MustParse(npconsts.VirtualNodeCPUCapacity),
+		apiv1.ResourceMemory: resource.MustParse(npconsts.VirtualNodeMemoryCapacity),
+	}
+	node.Status.Allocatable = node.Status.Capacity.DeepCopy()
+	node.Status.Conditions = cloudprovider.BuildReadyConditions()
+	return node, nil
+}
+
+// validateVirtualNodePool checks that the virtual node pool exists and that the subnets it places virtual nodes and
+// pods in exist. It returns the problem with the configuration of the virtual node pool, or an error if it could not
+// be validated.
+func (m *ociManagerImpl) validateVirtualNodePool(id string) (problem error, err error) {
+	resp, err := m.virtualNodePoolCache.client.GetVirtualNodePool(context.Background(), oke.GetVirtualNodePoolRequest{
+		VirtualNodePoolId: common.String(id),
+	})
+	if err != nil {
+		if ocicommon.IsNotFound(err) {
+			return fmt.Errorf("virtual node pool does not exist or is not accessible"), nil
+		}
+		return nil, err
+	}
+
+	if m.virtualNetworkClient == nil {
+		return nil, nil
+	}
+	var subnetIDs []*string
+	for _, placement := range resp.PlacementConfigurations {
+		subnetIDs = append(subnetIDs, placement.SubnetId)
+	}
+	if resp.PodConfiguration != nil {
+		subnetIDs = append(subnetIDs, resp.PodConfiguration.SubnetId)
+	}
+	for _, subnetID := range subnetIDs {
+		if subnetID == nil {
+			continue
+		}
+		_, err := m.virtualNetworkClient.GetSubnet(context.Background(), core.GetSubnetRequest{SubnetId: subnetID})
+		if err != nil {
+			if ocicommon.IsNotFound(err) {
+				return fmt.Errorf("subnet %s does not exist or is not accessible", *subnetID), nil
+			}
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021-2024 Oracle and/or its affiliates.
+*/
+
+package nodepools
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	ocicommon "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/common"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/common"
+	oke "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/oci/vendor-internal/github.com/oracle/oci-go-sdk/v65/containerengine"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+const testVirtualNodePoolID = "ocid1.virtualnodepool.oc1.phx.aaaaaaaa1"
+
+type fakeVirtualNodePoolClient struct {
+	virtualNodePool oke.VirtualNodePool
+	nodes           []oke.VirtualNodeSummary
+	sizes           []int
+}
+
+func (c *fakeVirtualNodePoolClient) GetVirtualNodePool(context.Context, oke.GetVirtualNodePoolRequest) (oke.GetVirtualNodePoolResponse, error) {
+	return oke.GetVirtualNodePoolResponse{VirtualNodePool: c.virtualNodePool}, nil
+}
+
+func (c *fakeVirtualNodePoolClient) ListVirtualNodes(_ context.Context, req oke.ListVirtualNodesRequest) (oke.ListVirtualNodesResponse, error) {
+	// Return one virtual node per page to exercise pagination.
+	page := 0
+	if req.Page != nil {
+		page, _ = strconv.Atoi(*req.Page)
+	}
+	if page >= len(c.nodes) {
+		return oke.ListVirtualNodesResponse{}, nil
+	}
+	resp := oke.ListVirtualNodesResponse{Items: c.nodes[page : page+1]}
+	if page+1 < len(c.nodes) {
+		resp.OpcNextPage = common.String(strconv.Itoa(page + 1))
+	}
+	return resp, nil
+}
+
+func (c *fakeVirtualNodePoolClient) UpdateVirtualNodePool(_ context.Context, req oke.UpdateVirtualNodePoolRequest) (oke.UpdateVirtualNodePoolResponse, error) {
+	c.sizes = append(c.sizes, *req.Size)
+	return oke.UpdateVirtualNodePoolResponse{}, nil
+}
+
+func newTestVirtualNodePoolManager(t *testing.T, client *fakeVirtualNodePoolClient) (*ociManagerImpl, NodePool) {
+	np, err := nodePoolFromArg("0:10:" + testVirtualNodePoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	manager := &ociManagerImpl{
+		cfg:                  &ocicommon.CloudConfig{},
+		staticNodePools:      map[string]NodePool{},
+		ociTagsGetter:        ocicommon.CreateTagsGetter(),
+		nodePoolCache:        newNodePoolCache(nil),
+		virtualNodePoolCache: newVirtualNodePoolCache(client),
+	}
+	np.manager = manager
+	vnp := &virtualNodePool{nodePool: np}
+	manager.staticNodePools[vnp.Id()] = vnp
+
+	if err := manager.forceRefresh(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	return manager, vnp
+}
+
+func TestVirtualNodePool(t *testing.T) {
+	client := &fakeVirtualNodePoolClient{
+		virtualNodePool: oke.VirtualNodePool{
+			Id:   common.String(testVirtualNodePoolID),
+			Size: common.Int(3),
+			PlacementConfigurations: []oke.PlacementConfiguration{{
+				AvailabilityDomain: common.String("hash:PHX-AD-1"),
+			}},
+			PodConfiguration: &oke.PodConfiguration{Shape: common.String("Pod.Standard.E4.Flex")},
+			InitialVirtualNodeLabels: []oke.InitialVirtualNodeLabel{{
+				Key:   common.String("burst"),
+				Value: common.String("true"),
+			}},
+			Taints: []oke.Taint{{
+				Key:    common.String("burst"),
+				Value:  common.String("true"),
+				Effect: common.String(string(apiv1.TaintEffectNoSchedule)),
+			}},
+		},
+		nodes: []oke.VirtualNodeSummary{
+			{Id: common.String("ocid1.virtualnode.oc1.phx.aaaaaaaa1"), LifecycleState: oke.VirtualNodeLifecycleStateActive},
+			{Id: common.String("ocid1.virtualnode.oc1.phx.aaaaaaaa2"), LifecycleState: oke.VirtualNodeLifecycleStateCreating},
+			{Id: common.String("ocid1.virtualnode.oc1.phx.aaaaaaaa3"), LifecycleState: oke.VirtualNodeLifecycleStateDeleted},
+		},
+	}
+	manager, np := newTestVirtualNodePoolManager(t, client)
+
+	nodes, err := np.Nodes()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(nodes) != 2 || nodes[1].Status.State != cloudprovider.InstanceCreating {
+		t.Errorf("got %+v ; wanted an active and a creating virtual node", nodes)
+	}
+
+	found, err := manager.GetNodePoolForInstance(ocicommon.OciRef{InstanceID: "ocid1.virtualnode.oc1.phx.aaaaaaaa2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if found != np {
+		t.Errorf("got %v ; wanted %v", found, np)
+	}
+
+	if err := np.IncreaseSize(2); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if size, _ := np.TargetSize(); size != 5 || len(client.sizes) != 1 || client.sizes[0] != 5 {
+		t.Errorf("got size %d and updates %v ; wanted size 5", size, client.sizes)
+	}
+
+	nodeInfo, err := np.TemplateNodeInfo()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	node := nodeInfo.Node()
+	if got := node.Labels[apiv1.LabelInstanceTypeStable]; got != "Pod.Standard.E4.Flex" {
+		t.Errorf("got instance type %v ; wanted Pod.Standard.E4.Flex", got)
+	}
+	if got := node.Labels["burst"]; got != "true" {
+		t.Errorf("got burst label %q ; wanted true", got)
+	}
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != "burst" {
+		t.Errorf("got taints %v ; wanted the burst taint", node.Spec.Taints)
+	}
+	if len(nodeInfo.Pods) != 0 {
+		t.Errorf("got %d template pods ; wanted none", len(nodeInfo.Pods))
+	}
+
+	options, err := np.GetOptions(config.NodeGroupAutoscalingOptions{ScaleDownUtilizationThreshold: 0.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if options.ScaleDownUtilizationThreshold != 0 {
+		t.Errorf("got scale down utilization threshold %v ; wanted 0", options.ScaleDownUtilizationThreshold)
+	}
+	if err := np.DeleteNodes([]*apiv1.Node{node}); err == nil {
+		t.Errorf("got no error deleting a virtual node ; wanted an error")
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledscaling
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	klog "k8s.io/klog/v2"
+)
+
+// scheduledScalingCloudProvider overrides the min and max sizes of the node groups of the wrapped
+// cloud provider with the schedules read from a ConfigMap. The schedules are read and evaluated
+// in Refresh, so that the sizes don't change in the middle of a loop.
+type scheduledScalingCloudProvider struct {
+	cloudprovider.CloudProvider
+	configMapLister v1lister.ConfigMapNamespaceLister
+	configMapName   string
+	now             func() time.Time
+
+	lock sync.Mutex
+	// lastValue is the last value of the schedules that was processed.
+	lastValue string
+	schedules []*Schedule
+	// active are the schedules active at the last refresh.
+	active []*Schedule
+	// nodeGroups are the node groups with an active schedule by id. They are reused until
+	// the next refresh, so that the same node group is returned in a loop, e.g. to be used
+	// as a map key.
+	nodeGroups map[string]*scheduledNodeGroup
+}
+
+// NewCloudProvider returns a cloud provider overriding the min and max sizes of the node groups
+// of the given cloud provider in the time windows of the schedules in the ConfigMap.
+func NewCloudProvider(cloudProvider cloudprovider.CloudProvider, configMapLister v1lister.ConfigMapNamespaceLister, configMapName string) cloudprovider.CloudProvider {
+	return &scheduledScalingCloudProvider{
+		CloudProvider:   cloudProvider,
+		configMapLister: configMapLister,
+		configMapName:   configMapName,
+		now:             time.Now,
+		nodeGroups:      make(map[string]*scheduledNodeGroup),
+	}
+}
+
+// NodeGroups returns all node groups configured for this cloud provider, with the sizes of the active schedules.
+func (p *scheduledScalingCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	nodeGroups := p.CloudProvider.NodeGroups()
+	result := make([]cloudprovider.NodeGroup, 0, len(nodeGroups))
+	for _, nodeGroup := range nodeGroups {
+		result = append(result, p.withSchedule(nodeGroup))
+	}
+	return result
+}
+
+// NodeGroupForNode returns the node group for the given node, with the sizes of the active schedules.
+func (p *scheduledScalingCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	nodeGroup, err := p.CloudProvider.NodeGroupForNode(node)
+	if err != nil {
+		return nodeGroup, err
+	}
+	return p.withSchedule(nodeGroup), nil
+}
+
+// Refresh reloads the schedules and evaluates them, then refreshes the wrapped cloud provider.
+func (p *scheduledScalingCloudProvider) Refresh() error {
+	p.lock.Lock()
+	p.reloadSchedules()
+	p.activateSchedules(p.now())
+	p.lock.Unlock()
+	return p.CloudProvider.Refresh()
+}
+
+// reloadSchedules parses the schedules if they changed since the last call. Invalid schedules
+// are ignored and the ones parsed before are kept.
+func (p *scheduledScalingCloudProvider) reloadSchedules() {
+	value := ""
+	cm, err := p.configMapLister.Get(p.configMapName)
+	if err != nil && !kube_errors.IsNotFound(err) {
+		klog.Warningf("Failed to get scheduled scaling configmap %s: %v", p.configMapName, err)
+		return
+	}
+	if err == nil {
+		value = cm.Data[SchedulesConfigMapKey]
+	}
+	if value == p.lastValue {
+		return
+	}
+	p.lastValue = value
+
+	schedules, err := ParseSchedules(value)
+	if err != nil {
+		klog.Warningf("Wrong schedules in scheduled scaling configmap %s: %v. Ignoring update.", p.configMapName, err)
+		return
+	}
+	p.schedules = schedules
+	klog.V(1).Infof("Loaded %d schedules from scheduled scaling configmap %s", len(schedules), p.configMapName)
+}
+
+func (p *scheduledScalingCloudProvider) activateSchedules(now time.Time) {
+	wasActive := make(map[string]bool)
+	for _, s := range p.active {
+		wasActive[s.Name] = true
+	}
+	p.active = nil
+	for _, s := range p.schedules {
+		if !s.Active(now) {
+			continue
+		}
+		p.active = append(p.active, s)
+		if !wasActive[s.Name] {
+			klog.V(1).Infof("Schedule %s is active", s.Name)
+		}
+		delete(wasActive, s.Name)
+	}
+	for name := range wasActive {
+		klog.V(1).Infof("Schedule %s is no longer active", name)
+	}
+	p.nodeGroups = make(map[string]*scheduledNodeGroup)
+}
+
+// withSchedule returns the node group with the sizes of the first active schedule matching it,
+// or the node group itself if no active schedule matches it.
+func (p *scheduledScalingCloudProvider) withSchedule(nodeGroup cloudprovider.NodeGroup) cloudprovider.NodeGroup {
+	if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+		return nodeGroup
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, s := range p.active {
+		if !s.Matches(nodeGroup.Id()) {
+			continue
+		}
+		scheduled, found := p.nodeGroups[nodeGroup.Id()]
+		if !found || scheduled.NodeGroup != nodeGroup {
+			scheduled = &scheduledNodeGroup{NodeGroup: nodeGroup, schedule: s}
+			p.nodeGroups[nodeGroup.Id()] = scheduled
+		}
+		return scheduled
+	}
+	return nodeGroup
+}
+
+// scheduledNodeGroup is a node group with the sizes of an active schedule.
+type scheduledNodeGroup struct {
+	cloudprovider.NodeGroup
+	schedule *Schedule
+}
+
+// MaxSize returns the max size of the schedule, if set, or the one of the node group.
+func (ng *scheduledNodeGroup) MaxSize() int {
+	if ng.schedule.MaxSize != nil {
+		return *ng.schedule.MaxSize
+	}
+	return ng.NodeGroup.MaxSize()
+}
+
+// MinSize returns the min size of the schedule, if set, or the one of the node group,
+// capped at the max size.
+func (ng *scheduledNodeGroup) MinSize() int {
+	minSize := ng.NodeGroup.MinSize()
+	if ng.schedule.MinSize != nil {
+		minSize = *ng.schedule.MinSize
+	}
+	return min(minSize, ng.MaxSize())
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledscaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestScheduledScalingCloudProvider(t *testing.T) {
+	const schedules = `
+- name: business-hours
+  nodeGroups: ["^workers-"]
+  schedule: "0 8 * * *"
+  duration: 10h
+  minSize: 5
+  maxSize: 20
+- name: workers-a-only
+  nodeGroups: ["^workers-a$"]
+  schedule: "0 8 * * *"
+  duration: 10h
+  minSize: 1
+- name: nights
+  nodeGroups: ["^batch$"]
+  schedule: "0 22 * * *"
+  duration: 8h
+  maxSize: 1
+`
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("workers-a", 0, 10, 1)
+	provider.AddNodeGroup("batch", 2, 10, 2)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	provider.AddNode("workers-a", n1)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	provider.AddNode("batch", n2)
+
+	cm := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "schedules", Namespace: "kube-system"},
+		Data:       map[string]string{SchedulesConfigMapKey: schedules},
+	}
+	lister, err := kube_util.NewTestConfigMapLister([]*apiv1.ConfigMap{cm})
+	assert.NoError(t, err)
+	scheduled := NewCloudProvider(provider, lister.ConfigMaps("kube-system"), "schedules").(*scheduledScalingCloudProvider)
+
+	sizes := func() map[string][2]int {
+		result := map[string][2]int{}
+		for _, ng := range scheduled.NodeGroups() {
+			result[ng.Id()] = [2]int{ng.MinSize(), ng.MaxSize()}
+		}
+		return result
+	}
+
+	scheduled.now = func() time.Time { return time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC) }
+	assert.NoError(t, scheduled.Refresh())
+	// The first active schedule matching the node group is used.
+	assert.Equal(t, map[string][2]int{"workers-a": {5, 20}, "batch": {2, 10}}, sizes())
+	ng, err := scheduled.NodeGroupForNode(n1)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, ng.MinSize())
+	// The same node group is returned until the next refresh.
+	assert.True(t, ng == scheduled.NodeGroups()[0] || ng == scheduled.NodeGroups()[1])
+
+	// The min size is capped at the max size.
+	scheduled.now = func() time.Time { return time.Date(2024, 6, 3, 23, 0, 0, 0, time.UTC) }
+	assert.NoError(t, scheduled.Refresh())
+	assert.Equal(t, map[string][2]int{"workers-a": {0, 10}, "batch": {1, 1}}, sizes())
+	ng, err = scheduled.NodeGroupForNode(n2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ng.MaxSize())
+
+	// Invalid schedules are ignored.
+	cm.Data[SchedulesConfigMapKey] = "- name: broken"
+	assert.NoError(t, scheduled.Refresh())
+	assert.Equal(t, map[string][2]int{"workers-a": {0, 10}, "batch": {1, 1}}, sizes())
+
+	// Without the ConfigMap, the sizes of the node groups are used.
+	emptyLister, err := kube_util.NewTestConfigMapLister(nil)
+	assert.NoError(t, err)
+	scheduled.configMapLister = emptyLister.ConfigMaps("kube-system")
+	assert.NoError(t, scheduled.Refresh())
+	assert.Equal(t, map[string][2]int{"workers-a": {0, 10}, "batch": {2, 10}}, sizes())
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledscaling
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// SchedulesConfigMapKey is the key of the schedules in their ConfigMap.
+const SchedulesConfigMapKey = "schedules"
+
+// Schedule overrides the min and max sizes of node groups in recurring time windows,
+// e.g. to add nodes before a predictable increase of traffic instead of waiting for pods to
+// become unschedulable.
+type Schedule struct {
+	// Name identifies the schedule in logs and events.
+	Name string `yaml:"name"`
+	// NodeGroups are regular expressions matched against the ids of the node groups
+	// the schedule applies to.
+	NodeGroups []string `yaml:"nodeGroups"`
+	// Schedule is a cron expression with five fields, e.g. "0 8 * * 1-5", of the start of the windows.
+	Schedule string `yaml:"schedule"`
+	// Duration is the length of a window.
+	Duration time.Duration `yaml:"duration"`
+	// TimeZone is the IANA time zone of the cron expression. UTC if empty.
+	TimeZone string `yaml:"timeZone"`
+	// MinSize, if set, is the min size of the node groups in the windows.
+	MinSize *int `yaml:"minSize"`
+	// MaxSize, if set, is the max size of the node groups in the windows.
+	MaxSize *int `yaml:"maxSize"`
+
+	nodeGroupRegexps []*regexp.Regexp
+	cronSchedule     cron.Schedule
+}
+
+// ParseSchedules parses a list of schedules represented in YAML.
+func ParseSchedules(value string) ([]*Schedule, error) {
+	var schedules []*Schedule
+	if err := yaml.UnmarshalStrict([]byte(value), &schedules); err != nil {
+		return nil, fmt.Errorf("can't parse schedules: %v", err)
+	}
+	for i, s := range schedules {
+		if err := s.init(); err != nil {
+			return nil, fmt.Errorf("invalid schedule %d (%s): %v", i, s.Name, err)
+		}
+	}
+	return schedules, nil
+}
+
+func (s *Schedule) init() error {
+	if s.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if len(s.NodeGroups) == 0 {
+		return fmt.Errorf("nodeGroups must not be empty")
+	}
+	for _, nodeGroup := range s.NodeGroups {
+		re, err := regexp.Compile(nodeGroup)
+		if err != nil {
+			return fmt.Errorf("invalid node group regexp %q: %v", nodeGroup, err)
+		}
+		s.nodeGroupRegexps = append(s.nodeGroupRegexps, re)
+	}
+	spec := s.Schedule
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return fmt.Errorf("invalid time zone %q: %v", s.TimeZone, err)
+		}
+		spec = fmt.Sprintf("CRON_TZ=%s %s", s.TimeZone, s.Schedule)
+	}
+	cronSchedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %v", s.Schedule, err)
+	}
+	s.cronSchedule = cronSchedule
+	if s.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if s.MinSize == nil && s.MaxSize == nil {
+		return fmt.Errorf("at least one of minSize and maxSize must be set")
+	}
+	if s.MinSize != nil && *s.MinSize < 0 {
+		return fmt.Errorf("minSize must not be negative")
+	}
+	if s.MaxSize != nil && *s.MaxSize < 0 {
+		return fmt.Errorf("maxSize must not be negative")
+	}
+	if s.MinSize != nil && s.MaxSize != nil && *s.MinSize > *s.MaxSize {
+		return fmt.Errorf("minSize %d is greater than maxSize %d", *s.MinSize, *s.MaxSize)
+	}
+	return nil
+}
+
+// Active checks whether one of the windows of the schedule contains the given time.
+func (s *Schedule) Active(now time.Time) bool {
+	// Next returns the first start after the given time, so a window started after
+	// now minus its duration and not after now contains now.
+	return !s.cronSchedule.Next(now.Add(-s.Duration)).After(now)
+}
+
+// Matches checks whether the schedule applies to the node group.
+func (s *Schedule) Matches(nodeGroupId string) bool {
+	for _, re := range s.nodeGroupRegexps {
+		if re.MatchString(nodeGroupId) {
+			return true
+		}
+	}
+	return false
+}
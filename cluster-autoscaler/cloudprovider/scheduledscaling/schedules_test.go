@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledscaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedules(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			value: `
+- name: business-hours
+  nodeGroups: ["^workers-"]
+  schedule: "0 8 * * 1-5"
+  duration: 10h
+  timeZone: Europe/Berlin
+  minSize: 5
+  maxSize: 20
+- name: nights
+  nodeGroups: ["batch"]
+  schedule: "0 22 * * *"
+  duration: 8h
+  maxSize: 0
+`,
+		},
+		{
+			name:  "empty",
+			value: "",
+		},
+		{
+			name:    "unknown field",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", duration: 1h, minSize: 1, foo: bar}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			value:   `[{nodeGroups: [ng], schedule: "0 8 * * *", duration: 1h, minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing node groups",
+			value:   `[{name: s, schedule: "0 8 * * *", duration: 1h, minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid node group regexp",
+			value:   `[{name: s, nodeGroups: ["("], schedule: "0 8 * * *", duration: 1h, minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid cron expression",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 25 * * *", duration: 1h, minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid time zone",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", timeZone: Mars/Olympus, duration: 1h, minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing duration",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", minSize: 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing sizes",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", duration: 1h}]`,
+			wantErr: true,
+		},
+		{
+			name:    "negative min size",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", duration: 1h, minSize: -1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "min size greater than max size",
+			value:   `[{name: s, nodeGroups: [ng], schedule: "0 8 * * *", duration: 1h, minSize: 5, maxSize: 3}]`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseSchedules(tc.value)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduleActive(t *testing.T) {
+	schedules, err := ParseSchedules(`
+- name: business-hours
+  nodeGroups: ["^workers-"]
+  schedule: "0 8 * * 1-5"
+  duration: 10h
+  timeZone: America/New_York
+  minSize: 5
+`)
+	assert.NoError(t, err)
+	s := schedules[0]
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// 2024-06-03 is a Monday.
+	assert.False(t, s.Active(time.Date(2024, 6, 3, 7, 59, 0, 0, newYork)))
+	assert.True(t, s.Active(time.Date(2024, 6, 3, 8, 0, 0, 0, newYork)))
+	assert.True(t, s.Active(time.Date(2024, 6, 3, 17, 59, 0, 0, newYork)))
+	assert.False(t, s.Active(time.Date(2024, 6, 3, 18, 0, 0, 0, newYork)))
+	// The time zone of the schedule is used regardless of the one of the time.
+	assert.True(t, s.Active(time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)))
+	assert.False(t, s.Active(time.Date(2024, 6, 3, 11, 30, 0, 0, time.UTC)))
+	// Saturday.
+	assert.False(t, s.Active(time.Date(2024, 6, 8, 9, 0, 0, 0, newYork)))
+
+	assert.True(t, s.Matches("workers-a"))
+	assert.False(t, s.Matches("batch-workers-a"))
+}
@@ -333,6 +333,7 @@ type TestNodeGroup struct {
 	labels          map[string]string
 	taints          []apiv1.Taint
 	opts            *config.NodeGroupAutoscalingOptions
+	warmCapacity    *int
 }
 
 // NewTestNodeGroup creates a TestNodeGroup without setting up the realted TestCloudProvider.
@@ -406,6 +407,24 @@ func (tng *TestNodeGroup) AtomicIncreaseSize(delta int) error {
 	return tng.cloudProvider.onScaleUp(tng.id, delta)
 }
 
+// WarmCapacity returns the warm capacity set with SetWarmCapacity, it is not implemented if it was not set.
+func (tng *TestNodeGroup) WarmCapacity() (int, error) {
+	tng.Lock()
+	defer tng.Unlock()
+
+	if tng.warmCapacity == nil {
+		return 0, cloudprovider.ErrNotImplemented
+	}
+	return *tng.warmCapacity, nil
+}
+
+// SetWarmCapacity sets the warm capacity of the group. Function is used only in tests.
+func (tng *TestNodeGroup) SetWarmCapacity(warmCapacity int) {
+	tng.Lock()
+	defer tng.Unlock()
+	tng.warmCapacity = &warmCapacity
+}
+
 // Exist checks if the node group really exists on the cloud provider side. Allows to tell the
 // theoretical node group from the real one.
 func (tng *TestNodeGroup) Exist() bool {
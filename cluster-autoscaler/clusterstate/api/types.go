@@ -161,6 +161,17 @@ type ScaleDownCondition struct {
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
 }
 
+// ScaleDownDryRun contains the nodes that would have been removed in the last scale-down dry run.
+type ScaleDownDryRun struct {
+	// Nodes are the names of the nodes that would have been removed.
+	Nodes []string `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	// EstimatedMonthlySavings is the estimated monthly cost of the nodes, in the currency of the
+	// cloud provider pricing. Nil if the cloud provider doesn't provide pricing.
+	EstimatedMonthlySavings *float64 `json:"estimatedMonthlySavings,omitempty" yaml:"estimatedMonthlySavings,omitempty"`
+	// LastProbeTime is the time of the dry run.
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty" yaml:"lastProbeTime,omitempty"`
+}
+
 // ClusterWideStatus contains status that apply to the whole cluster.
 type ClusterWideStatus struct {
 	// Health contains information about health condition of the cluster.
@@ -169,6 +180,16 @@ type ClusterWideStatus struct {
 	ScaleUp ClusterScaleUpCondition `json:"scaleUp,omitempty" yaml:"scaleUp,omitempty"`
 	// ScaleDown contains information about scale down condition of the node group.
 	ScaleDown ScaleDownCondition `json:"scaleDown,omitempty" yaml:"scaleDown,omitempty"`
+	// ScaleDownDryRun contains the result of the last scale-down dry run. Nil if scale-down isn't run dry.
+	ScaleDownDryRun *ScaleDownDryRun `json:"scaleDownDryRun,omitempty" yaml:"scaleDownDryRun,omitempty"`
+}
+
+// ScaleEvent contains information about the last scale-up or scale-down of a node group.
+type ScaleEvent struct {
+	// Time of the scale event.
+	Time metav1.Time `json:"time,omitempty" yaml:"time,omitempty"`
+	// NodeCount is the number of nodes added or removed by the scale event.
+	NodeCount int `json:"nodeCount" yaml:"nodeCount"`
 }
 
 // NodeGroupStatus contains status of an individual node group on which CA works..
@@ -181,6 +202,10 @@ type NodeGroupStatus struct {
 	ScaleUp NodeGroupScaleUpCondition `json:"scaleUp,omitempty" yaml:"scaleUp,omitempty"`
 	// ScaleDown contains information about scale down condition of the node group.
 	ScaleDown ScaleDownCondition `json:"scaleDown,omitempty" yaml:"scaleDown,omitempty"`
+	// LastScaleUp contains information about the last scale-up of the node group.
+	LastScaleUp *ScaleEvent `json:"lastScaleUp,omitempty" yaml:"lastScaleUp,omitempty"`
+	// LastScaleDown contains information about the last scale-down of the node group.
+	LastScaleDown *ScaleEvent `json:"lastScaleDown,omitempty" yaml:"lastScaleDown,omitempty"`
 }
 
 // ClusterAutoscalerStatus contains ClusterAutoscaler status.
@@ -67,6 +67,13 @@ type ScaleUpRequest struct {
 	Increase int
 }
 
+// ScaleUpRequestCheckpoint is the state of a scale-up request, which can be saved and restored.
+type ScaleUpRequestCheckpoint struct {
+	Time            time.Time `json:"time"`
+	ExpectedAddTime time.Time `json:"expectedAddTime"`
+	Increase        int       `json:"increase"`
+}
+
 // ScaleDownRequest contains information about the requested node deletion.
 type ScaleDownRequest struct {
 	// NodeName is the name of the node to be deleted.
@@ -132,6 +139,8 @@ type ClusterStateRegistry struct {
 	unregisteredNodes                  map[string]UnregisteredNode
 	deletedNodes                       map[string]struct{}
 	candidatesForScaleDown             map[string][]string
+	lastScaleUps                       map[string]*api.ScaleEvent
+	lastScaleDowns                     map[string]*api.ScaleEvent
 	backoff                            backoff.Backoff
 	lastStatus                         *api.ClusterAutoscalerStatus
 	lastScaleDownUpdateTime            time.Time
@@ -145,6 +154,10 @@ type ClusterStateRegistry struct {
 	// scaleUpFailures contains information about scale-up failures for each node group. It should be
 	// cleared periodically to avoid unnecessary accumulation.
 	scaleUpFailures map[string][]ScaleUpFailure
+
+	// restoredUnregisteredSince are the times nodes were first spotted unregistered restored from a checkpoint,
+	// by node name. They're applied to the unregistered nodes the next time the nodes are updated.
+	restoredUnregisteredSince map[string]time.Time
 }
 
 // NodeGroupScalingSafety contains information about the safety of the node group to scale up/down.
@@ -168,6 +181,8 @@ func NewClusterStateRegistry(cloudProvider cloudprovider.CloudProvider, config C
 		unregisteredNodes:               make(map[string]UnregisteredNode),
 		deletedNodes:                    make(map[string]struct{}),
 		candidatesForScaleDown:          make(map[string][]string),
+		lastScaleUps:                    make(map[string]*api.ScaleEvent),
+		lastScaleDowns:                  make(map[string]*api.ScaleEvent),
 		backoff:                         backoff,
 		lastStatus:                      utils.EmptyClusterAutoscalerStatus(),
 		logRecorder:                     logRecorder,
@@ -193,6 +208,10 @@ func (csr *ClusterStateRegistry) RegisterScaleUp(nodeGroup cloudprovider.NodeGro
 	csr.Lock()
 	defer csr.Unlock()
 	csr.registerOrUpdateScaleUpNoLock(nodeGroup, delta, currentTime)
+	if delta > 0 {
+		csr.lastScaleUps[nodeGroup.Id()] = &api.ScaleEvent{Time: metav1.Time{Time: currentTime}, NodeCount: delta}
+		metrics.UpdateNodeGroupLastScaleTime(nodeGroup.Id(), metrics.DirectionScaleUp, currentTime)
+	}
 }
 
 // MaxNodeProvisionTime returns MaxNodeProvisionTime value that should be used for the given NodeGroup.
@@ -252,6 +271,9 @@ func (csr *ClusterStateRegistry) RegisterScaleDown(nodeGroup cloudprovider.NodeG
 	csr.Lock()
 	defer csr.Unlock()
 	csr.scaleDownRequests = append(csr.scaleDownRequests, request)
+	// Scale-downs are registered node by node.
+	csr.lastScaleDowns[nodeGroup.Id()] = &api.ScaleEvent{Time: metav1.Time{Time: currentTime}, NodeCount: 1}
+	metrics.UpdateNodeGroupLastScaleTime(nodeGroup.Id(), metrics.DirectionScaleDown, currentTime)
 }
 
 // To be executed under a lock.
@@ -328,6 +350,7 @@ func (csr *ClusterStateRegistry) RegisterFailedScaleDown(_ cloudprovider.NodeGro
 func (csr *ClusterStateRegistry) registerFailedScaleUpNoLock(nodeGroup cloudprovider.NodeGroup, reason metrics.FailedScaleUpReason, errorInfo cloudprovider.InstanceErrorInfo, gpuResourceName, gpuType string, currentTime time.Time) {
 	csr.scaleUpFailures[nodeGroup.Id()] = append(csr.scaleUpFailures[nodeGroup.Id()], ScaleUpFailure{NodeGroup: nodeGroup, Reason: reason, Time: currentTime})
 	metrics.RegisterFailedScaleUp(reason, gpuResourceName, gpuType)
+	metrics.RegisterNodeGroupFailedScaleUp(nodeGroup.Id(), reason)
 	csr.backoffNodeGroup(nodeGroup, errorInfo, currentTime)
 }
 
@@ -719,10 +742,14 @@ func (csr *ClusterStateRegistry) updateUnregisteredNodes(unregisteredNodes []Unr
 		if prev, found := csr.unregisteredNodes[unregistered.Node.Name]; found {
 			result[unregistered.Node.Name] = prev
 		} else {
+			if since, found := csr.restoredUnregisteredSince[unregistered.Node.Name]; found && since.Before(unregistered.UnregisteredSince) {
+				unregistered.UnregisteredSince = since
+			}
 			result[unregistered.Node.Name] = unregistered
 		}
 	}
 	csr.unregisteredNodes = result
+	csr.restoredUnregisteredSince = nil
 }
 
 // GetUnregisteredNodes returns a list of all unregistered nodes.
@@ -796,6 +823,9 @@ func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscaler
 		nodeGroupStatus.ScaleDown = buildScaleDownStatusNodeGroup(
 			csr.candidatesForScaleDown[nodeGroup.Id()], csr.lastScaleDownUpdateTime, nodeGroupLastStatus.ScaleDown)
 
+		// Last scale events.
+		nodeGroupStatus.LastScaleUp, nodeGroupStatus.LastScaleDown = csr.lastScaleEvents(nodeGroup.Id())
+
 		result.NodeGroups = append(result.NodeGroups, nodeGroupStatus)
 	}
 	result.ClusterWide.Health =
@@ -809,6 +839,19 @@ func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscaler
 	return result
 }
 
+// lastScaleEvents returns the last scale-up and scale-down of the node group. Scale-downs are registered
+// while nodes are deleted in the background, so the events are read under the lock.
+func (csr *ClusterStateRegistry) lastScaleEvents(nodeGroupId string) (*api.ScaleEvent, *api.ScaleEvent) {
+	csr.Lock()
+	defer csr.Unlock()
+	return csr.lastScaleUps[nodeGroupId], csr.lastScaleDowns[nodeGroupId]
+}
+
+// GetNodeGroupReadiness returns current readiness stats of the node group
+func (csr *ClusterStateRegistry) GetNodeGroupReadiness(nodeGroupName string) Readiness {
+	return csr.perNodeGroupReadiness[nodeGroupName]
+}
+
 // GetClusterReadiness returns current readiness stats of cluster
 func (csr *ClusterStateRegistry) GetClusterReadiness() Readiness {
 	return csr.totalReadiness
@@ -1266,6 +1309,80 @@ func (csr *ClusterStateRegistry) GetScaleUpFailures() map[string][]ScaleUpFailur
 	return result
 }
 
+// ScaleUpRequestsCheckpoint returns the state of the scale-up requests in progress by node group id.
+func (csr *ClusterStateRegistry) ScaleUpRequestsCheckpoint() map[string]ScaleUpRequestCheckpoint {
+	csr.Lock()
+	defer csr.Unlock()
+	checkpoints := make(map[string]ScaleUpRequestCheckpoint, len(csr.scaleUpRequests))
+	for nodeGroupId, request := range csr.scaleUpRequests {
+		checkpoints[nodeGroupId] = ScaleUpRequestCheckpoint{
+			Time:            request.Time,
+			ExpectedAddTime: request.ExpectedAddTime,
+			Increase:        request.Increase,
+		}
+	}
+	return checkpoints
+}
+
+// RestoreScaleUpRequests restores the scale-up requests returned by ScaleUpRequestsCheckpoint, so that
+// node groups whose nodes don't come up in time are backed off even if the scale-up was requested by
+// another CA instance. Requests of node groups which no longer exist or already have a request are skipped.
+func (csr *ClusterStateRegistry) RestoreScaleUpRequests(checkpoints map[string]ScaleUpRequestCheckpoint) {
+	if len(checkpoints) == 0 {
+		return
+	}
+	nodeGroups := csr.cloudProvider.NodeGroups()
+	csr.Lock()
+	defer csr.Unlock()
+	for _, nodeGroup := range nodeGroups {
+		checkpoint, found := checkpoints[nodeGroup.Id()]
+		if !found {
+			continue
+		}
+		if _, found := csr.scaleUpRequests[nodeGroup.Id()]; found {
+			continue
+		}
+		csr.scaleUpRequests[nodeGroup.Id()] = &ScaleUpRequest{
+			NodeGroup:       nodeGroup,
+			Increase:        checkpoint.Increase,
+			Time:            checkpoint.Time,
+			ExpectedAddTime: checkpoint.ExpectedAddTime,
+		}
+	}
+}
+
+// UnregisteredSinceCheckpoint returns the times the unregistered nodes were first spotted, by node name.
+func (csr *ClusterStateRegistry) UnregisteredSinceCheckpoint() map[string]time.Time {
+	csr.Lock()
+	defer csr.Unlock()
+	// Restored times that weren't applied yet are carried over as they are.
+	if csr.restoredUnregisteredSince != nil {
+		checkpoint := make(map[string]time.Time, len(csr.restoredUnregisteredSince))
+		for name, since := range csr.restoredUnregisteredSince {
+			checkpoint[name] = since
+		}
+		return checkpoint
+	}
+	checkpoint := make(map[string]time.Time, len(csr.unregisteredNodes))
+	for name, unregistered := range csr.unregisteredNodes {
+		checkpoint[name] = unregistered.UnregisteredSince
+	}
+	return checkpoint
+}
+
+// RestoreUnregisteredSince restores the times returned by UnregisteredSinceCheckpoint, so that nodes which
+// didn't register for longer than the max node provision time keep counting as long unregistered, and their
+// node groups as unhealthy, even if they were first spotted by another CA instance. The times are applied to
+// the nodes still unregistered the next time the nodes are updated.
+func (csr *ClusterStateRegistry) RestoreUnregisteredSince(since map[string]time.Time) {
+	if len(since) == 0 {
+		return
+	}
+	csr.Lock()
+	defer csr.Unlock()
+	csr.restoredUnregisteredSince = since
+}
+
 func truncateIfExceedMaxLength(s string, maxLength int) string {
 	if len(s) <= maxLength {
 		return s
@@ -418,6 +418,9 @@ func TestNotStarted(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(clusterstate.GetClusterReadiness().NotStarted))
 	assert.Equal(t, 1, len(clusterstate.GetClusterReadiness().Ready))
+	assert.Equal(t, []string{"ng1-1"}, clusterstate.GetNodeGroupReadiness("ng1").Ready)
+	assert.Equal(t, []string{"ng2-1"}, clusterstate.GetNodeGroupReadiness("ng2").NotStarted)
+	assert.Empty(t, clusterstate.GetNodeGroupReadiness("ng3").Registered)
 
 	// node ng2_1 moves condition to ready
 	SetNodeReadyState(ng2_1, true, now.Add(-4*time.Minute))
@@ -463,6 +466,46 @@ func TestExpiredScaleUp(t *testing.T) {
 	})
 }
 
+func TestRestoreScaleUpRequests(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 5)
+	provider.AddNode("ng1", ng1_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	newClusterState := func() *ClusterStateRegistry {
+		return NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+			MaxTotalUnreadyPercentage: 10,
+			OkTotalUnreadyCount:       1,
+		}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 2 * time.Minute}))
+	}
+	oldClusterState := newClusterState()
+	oldClusterState.RegisterScaleUp(provider.GetNodeGroup("ng1"), 4, now.Add(-3*time.Minute))
+	checkpoints := oldClusterState.ScaleUpRequestsCheckpoint()
+	assert.Equal(t, map[string]ScaleUpRequestCheckpoint{
+		"ng1": {Time: now.Add(-3 * time.Minute), ExpectedAddTime: now.Add(-time.Minute), Increase: 4},
+	}, checkpoints)
+
+	// The restored scale-up times out and backs off the node group.
+	checkpoints["missing"] = ScaleUpRequestCheckpoint{Time: now, ExpectedAddTime: now, Increase: 1}
+	clusterstate := newClusterState()
+	clusterstate.RestoreScaleUpRequests(checkpoints)
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]ScaleUpFailure{
+		"ng1": {
+			{NodeGroup: provider.GetNodeGroup("ng1"), Time: now, Reason: metrics.Timeout},
+		},
+	}, clusterstate.GetScaleUpFailures())
+	assert.False(t, clusterstate.NodeGroupScaleUpSafety(provider.GetNodeGroup("ng1"), now).SafeToScale)
+	assert.Empty(t, clusterstate.ScaleUpRequestsCheckpoint())
+}
+
 func TestRegisterScaleDown(t *testing.T) {
 	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
 	provider := testprovider.NewTestCloudProvider(nil, nil)
@@ -666,6 +709,57 @@ func TestUnregisteredNodes(t *testing.T) {
 	assert.Equal(t, 0, len(clusterstate.GetUnregisteredNodes()))
 }
 
+func TestRestoreUnregisteredSince(t *testing.T) {
+	now := time.Now()
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	ng1_1.Spec.ProviderID = "ng1-1"
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	ng1_2.Spec.ProviderID = "ng1-2"
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	newClusterState := func() *ClusterStateRegistry {
+		return NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+			MaxTotalUnreadyPercentage: 10,
+			OkTotalUnreadyCount:       1,
+		}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	}
+	oldClusterState := newClusterState()
+	err := oldClusterState.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	checkpoint := oldClusterState.UnregisteredSinceCheckpoint()
+	assert.Equal(t, map[string]time.Time{"ng1-2": now.Add(-time.Hour)}, checkpoint)
+
+	// Without the checkpoint, the node is spotted unregistered from scratch and still counted as upcoming.
+	clusterstate := newClusterState()
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	upcomingNodes, _ := clusterstate.GetUpcomingNodes()
+	assert.Equal(t, 1, upcomingNodes["ng1"])
+
+	// The restored time is carried over until it's applied, and the node is long unregistered right away.
+	clusterstate = newClusterState()
+	clusterstate.RestoreUnregisteredSince(checkpoint)
+	assert.Equal(t, checkpoint, clusterstate.UnregisteredSinceCheckpoint())
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(clusterstate.GetUnregisteredNodes()))
+	assert.Equal(t, now.Add(-time.Hour), clusterstate.GetUnregisteredNodes()[0].UnregisteredSince)
+	upcomingNodes, _ = clusterstate.GetUpcomingNodes()
+	assert.Equal(t, 0, len(upcomingNodes))
+
+	// Restored times of nodes that registered meanwhile are dropped.
+	clusterstate = newClusterState()
+	clusterstate.RestoreUnregisteredSince(checkpoint)
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+	assert.NoError(t, err)
+	assert.Empty(t, clusterstate.UnregisteredSinceCheckpoint())
+}
+
 func TestCloudProviderDeletedNodes(t *testing.T) {
 	now := time.Now()
 	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
@@ -1380,3 +1474,44 @@ func TestTruncateIfExceedMaxSize(t *testing.T) {
 		})
 	}
 }
+
+func TestLastScaleEvents(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	ng2_1 := BuildTestNode("ng2-1", 1000, 1000)
+	SetNodeReadyState(ng2_1, true, now.Add(-time.Minute))
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng2", ng2_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: time.Minute}))
+	clusterstate.RegisterScaleUp(provider.GetNodeGroup("ng1"), 3, now.Add(-2*time.Minute))
+	clusterstate.RegisterScaleUp(provider.GetNodeGroup("ng1"), -3, now.Add(-time.Minute))
+	clusterstate.RegisterScaleDown(provider.GetNodeGroup("ng2"), "ng2-2", now.Add(-time.Minute), now.Add(time.Minute))
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng2_1}, nil, now)
+	assert.NoError(t, err)
+
+	status := clusterstate.GetStatus(now)
+	assert.Equal(t, 2, len(status.NodeGroups))
+	for _, nodeGroupStatus := range status.NodeGroups {
+		switch nodeGroupStatus.Name {
+		case "ng1":
+			// Cancelled scale-ups don't replace the last scale-up.
+			assert.Equal(t, &api.ScaleEvent{Time: metav1.Time{Time: now.Add(-2 * time.Minute)}, NodeCount: 3}, nodeGroupStatus.LastScaleUp)
+			assert.Nil(t, nodeGroupStatus.LastScaleDown)
+		case "ng2":
+			assert.Nil(t, nodeGroupStatus.LastScaleUp)
+			assert.Equal(t, &api.ScaleEvent{Time: metav1.Time{Time: now.Add(-time.Minute)}, NodeCount: 1}, nodeGroupStatus.LastScaleDown)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	"k8s.io/client-go/dynamic"
+
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// StatusResourceKind is the kind of the status custom resource.
+	StatusResourceKind = "ClusterAutoscalerStatus"
+)
+
+// StatusResourceGVR identifies the ClusterAutoscalerStatus custom resource the status is published in,
+// as an alternative to the status ConfigMap.
+var StatusResourceGVR = schema.GroupVersionResource{
+	Group:    "autoscaling.x-k8s.io",
+	Version:  "v1alpha1",
+	Resource: "clusterautoscalerstatuses",
+}
+
+// WriteStatusResource updates the status custom resource with a given status or creates a new
+// one if it doesn't exist. Unlike the status ConfigMap, the status is kept structured in the
+// status field of the resource, so it can be consumed without parsing YAML.
+func WriteStatusResource(client dynamic.Interface, namespace string, status api.ClusterAutoscalerStatus, statusResourceName string, currentTime time.Time) (*unstructured.Unstructured, error) {
+	statusUpdateTime := currentTime.Format(ConfigMapLastUpdateFormat)
+	status.Time = statusUpdateTime
+	statusObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert status to unstructured: %v", err)
+	}
+	resources := client.Resource(StatusResourceGVR).Namespace(namespace)
+	obj, err := resources.Get(context.TODO(), statusResourceName, metav1.GetOptions{})
+	if err == nil {
+		obj.Object["status"] = statusObj
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ConfigMapLastUpdatedKey] = statusUpdateTime
+		obj.SetAnnotations(annotations)
+		obj, err = resources.Update(context.TODO(), obj, metav1.UpdateOptions{})
+	} else if kube_errors.IsNotFound(err) {
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{"status": statusObj}}
+		obj.SetAPIVersion(StatusResourceGVR.GroupVersion().String())
+		obj.SetKind(StatusResourceKind)
+		obj.SetNamespace(namespace)
+		obj.SetName(statusResourceName)
+		obj.SetAnnotations(map[string]string{ConfigMapLastUpdatedKey: statusUpdateTime})
+		obj, err = resources.Create(context.TODO(), obj, metav1.CreateOptions{})
+	} else {
+		klog.Errorf("Failed to retrieve status resource for update: %v", err)
+		return nil, err
+	}
+	if err != nil {
+		klog.Errorf("Failed to write status resource: %v", err)
+		return nil, err
+	}
+	klog.V(8).Infof("Successfully wrote status resource %s/%s", namespace, statusResourceName)
+	return obj, nil
+}
+
+// DeleteStatusResource deletes the status custom resource.
+func DeleteStatusResource(client dynamic.Interface, namespace string, statusResourceName string) error {
+	err := client.Resource(StatusResourceGVR).Namespace(namespace).Delete(context.TODO(), statusResourceName, metav1.DeleteOptions{})
+	if err != nil {
+		klog.Error("Failed to delete status resource")
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteStatusResource(t *testing.T) {
+	currentTime := time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	// The resource is created on the first write.
+	_, err := WriteStatusResource(client, "kube-system", api.ClusterAutoscalerStatus{Message: "TEST_MSG"}, "my-cool-status", currentTime)
+	assert.NoError(t, err)
+	obj, err := client.Resource(StatusResourceGVR).Namespace("kube-system").Get(context.TODO(), "my-cool-status", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusResourceKind, obj.GetKind())
+	assert.Equal(t, "autoscaling.x-k8s.io/v1alpha1", obj.GetAPIVersion())
+	assert.Equal(t, "2023-12-21 00:00:00 +0000 UTC", obj.GetAnnotations()[ConfigMapLastUpdatedKey])
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+	assert.Equal(t, "TEST_MSG", message)
+
+	// Later writes update the structured status.
+	_, err = WriteStatusResource(client, "kube-system", status, "my-cool-status", currentTime.Add(time.Minute))
+	assert.NoError(t, err)
+	obj, err = client.Resource(StatusResourceGVR).Namespace("kube-system").Get(context.TODO(), "my-cool-status", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-12-21 00:01:00 +0000 UTC", obj.GetAnnotations()[ConfigMapLastUpdatedKey])
+	nodeGroups, _, _ := unstructured.NestedSlice(obj.Object, "status", "nodeGroups")
+	assert.Equal(t, len(status.NodeGroups), len(nodeGroups))
+	var got api.ClusterAutoscalerStatus
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object["status"].(map[string]interface{}), &got))
+	// Times are published with the precision of metav1.Time.
+	assert.Equal(t, "2023-12-21 00:01:00 +0000 UTC", got.Time)
+	assert.Equal(t, status.AutoscalerStatus, got.AutoscalerStatus)
+	assert.Equal(t, status.ClusterWide.Health.NodeCounts, got.ClusterWide.Health.NodeCounts)
+	assert.Equal(t, status.ClusterWide.Health.LastProbeTime.Unix(), got.ClusterWide.Health.LastProbeTime.Unix())
+	for i := range status.NodeGroups {
+		assert.Equal(t, status.NodeGroups[i].Name, got.NodeGroups[i].Name)
+		assert.Equal(t, status.NodeGroups[i].ScaleUp.BackoffInfo, got.NodeGroups[i].ScaleUp.BackoffInfo)
+	}
+
+	assert.NoError(t, DeleteStatusResource(client, "kube-system", "my-cool-status"))
+	_, err = client.Resource(StatusResourceGVR).Namespace("kube-system").Get(context.TODO(), "my-cool-status", metav1.GetOptions{})
+	assert.Error(t, err)
+}
@@ -19,6 +19,8 @@ package config
 import (
 	"time"
 
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	gce_localssdsize "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/gce/localssdsize"
 	kubelet_config "k8s.io/kubernetes/pkg/kubelet/apis/config"
 	scheduler_config "k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -34,6 +36,32 @@ type GpuLimits struct {
 	Max int64
 }
 
+// ScopedResourceLimit defines an upper bound on a resource of the nodes matching a label selector
+type ScopedResourceLimit struct {
+	// Resource is the limited resource, cloudprovider.ResourceNameCores or cloudprovider.ResourceNameMemory
+	Resource string
+	// Max is the upper bound on the resource of the matching nodes, in cores or bytes
+	Max int64
+	// Selector selects the nodes the limit applies to by their labels
+	Selector labels.Selector
+}
+
+// NodeGroupHeadroom defines the spare capacity kept in a node group, as a number of units of the same size
+type NodeGroupHeadroom struct {
+	// Units is the number of units kept free
+	Units int
+	// Unit is the resources of a single unit
+	Unit apiv1.ResourceList
+}
+
+// NodeGroupBackoffPolicy defines the backoff of node groups after scale-up failures with an error code or class
+type NodeGroupBackoffPolicy struct {
+	// InitialBackoffDuration is the duration of the first backoff
+	InitialBackoffDuration time.Duration
+	// MaxBackoffDuration is the maximum duration of the backoff
+	MaxBackoffDuration time.Duration
+}
+
 // NodeGroupAutoscalingOptions contain various options to customize how autoscaling of
 // a given NodeGroup works. Different options can be used for each NodeGroup.
 type NodeGroupAutoscalingOptions struct {
@@ -54,6 +82,11 @@ type NodeGroupAutoscalingOptions struct {
 	ZeroOrMaxNodeScaling bool
 	// IgnoreDaemonSetsUtilization sets if daemonsets utilization should be considered during node scale-down
 	IgnoreDaemonSetsUtilization bool
+	// ScaleDownDelayAfterAdd sets how long after a scale up of the NodeGroup its nodes are not considered for scale down.
+	// It is only used per NodeGroup if ScaleDownDelayTypeLocal is enabled.
+	ScaleDownDelayAfterAdd time.Duration
+	// MaxNewNodesPerMinute is the maximum number of nodes added to the NodeGroup per minute. 0 means no limit.
+	MaxNewNodesPerMinute int
 }
 
 // GCEOptions contain autoscaling options specific to GCE cloud provider.
@@ -115,6 +148,8 @@ type AutoscalingOptions struct {
 	MinMemoryTotal int64
 	// GpuTotal is a list of strings with configuration of min/max limits for different GPUs.
 	GpuTotal []GpuLimits
+	// ScopedResourceLimits is a list of max limits for resources of the nodes matching label selectors.
+	ScopedResourceLimits []ScopedResourceLimit
 	// NodeGroupAutoDiscovery represents one or more definition(s) of node group auto-discovery
 	NodeGroupAutoDiscovery []string
 	// EstimatorName is the estimator used to estimate the number of needed nodes in scale up.
@@ -125,6 +160,19 @@ type AutoscalingOptions struct {
 	GRPCExpanderCert string
 	// GRPCExpanderURL is the url of the gRPC server when using the gRPC expander
 	GRPCExpanderURL string
+	// GRPCExpanderCacheTTL is how long the best options returned by the gRPC server are reused for the same expansion options
+	GRPCExpanderCacheTTL time.Duration
+	// GRPCExpanderHealthCheckInterval is how often the gRPC server is health checked
+	GRPCExpanderHealthCheckInterval time.Duration
+	// GRPCExpanderFallback is the expander used when the gRPC server is unhealthy or fails
+	GRPCExpanderFallback string
+	// PriceExpanderFile is the JSON file, e.g. mounted from a ConfigMap, with the prices used by the price expander
+	// instead of the ones of the cloud provider
+	PriceExpanderFile string
+	// CarbonIntensityRegions is the static table of carbon intensities by region or zone used by the leastco2 expander
+	CarbonIntensityRegions string
+	// CarbonIntensityURL is the url of the HTTP carbon intensity provider used by the leastco2 expander
+	CarbonIntensityURL string
 	// IgnoreMirrorPodsUtilization is whether CA will ignore Mirror pods when calculating resource utilization for scaling down
 	IgnoreMirrorPodsUtilization bool
 	// MaxGracefulTerminationSec is maximum number of seconds scale down waits for pods to terminate before
@@ -151,10 +199,18 @@ type AutoscalingOptions struct {
 	NodeGroups []string
 	// EnforceNodeGroupMinSize is used to allow CA to scale up the node group to the configured min size if needed.
 	EnforceNodeGroupMinSize bool
+	// NodeGroupHeadroom is the spare capacity kept in node groups, by node group id. It is reserved
+	// with virtual placeholder pods, and node groups are scaled up when they don't fit.
+	NodeGroupHeadroom map[string]NodeGroupHeadroom
+	// NodeGroupGangs are sets of node groups, by node group id, that are scaled up together for pods that need nodes
+	// of several of them, e.g. a CPU and a GPU node group. Either all node groups of a gang are scaled up or none is.
+	NodeGroupGangs [][]string
 	// ScaleDownEnabled is used to allow CA to scale down the cluster
 	ScaleDownEnabled bool
 	// ScaleDownUnreadyEnabled is used to allow CA to scale down unready nodes of the cluster
 	ScaleDownUnreadyEnabled bool
+	// ScaleDownDryRun makes CA report the nodes it would remove, with their estimated monthly cost, instead of removing them.
+	ScaleDownDryRun bool
 	// ScaleDownDelayAfterAdd sets the duration from the last scale up to the time when CA starts to check scale down options
 	ScaleDownDelayAfterAdd time.Duration
 	// ScaleDownDelayAfterDelete sets the duration between scale down attempts if scale down removes one or more nodes
@@ -164,6 +220,9 @@ type AutoscalingOptions struct {
 	// ScaleDownDelayTypeLocal sets if the --scale-down-delay-after-* flags should be applied locally per nodegroup
 	// or globally across all nodegroups
 	ScaleDownDelayTypeLocal bool
+	// ScaleDownOrder is the order scale down candidates are considered in: cost, utilization or age. If empty, the
+	// order of the candidates is kept.
+	ScaleDownOrder string
 	// ScaleDownNonEmptyCandidatesCount is the maximum number of non empty nodes
 	// considered at once as candidates for scale down.
 	ScaleDownNonEmptyCandidatesCount int
@@ -180,6 +239,10 @@ type AutoscalingOptions struct {
 	// ScaleDownSimulationTimeout defines the maximum time that can be
 	// spent on scale down simulation.
 	ScaleDownSimulationTimeout time.Duration
+	// ScaleDownSimulationTargetDuration, if positive, makes the number of scale down candidates simulated in a loop
+	// adapt to how long the recent simulations took, so that the simulation takes about this long. The budget of
+	// candidates doesn't go below ScaleDownCandidatesPoolMinCount and replaces the fixed additional candidates pool size.
+	ScaleDownSimulationTargetDuration time.Duration
 	// SchedulerConfig allows changing configuration of in-tree
 	// scheduler plugins acting on PreFilter and Filter extension points
 	SchedulerConfig *scheduler_config.KubeSchedulerConfiguration
@@ -189,6 +252,36 @@ type AutoscalingOptions struct {
 	WriteStatusConfigMap bool
 	// StaticConfigMapName
 	StatusConfigMapName string
+	// WriteStatusResource tells if the status information should be written to a ClusterAutoscalerStatus custom resource
+	WriteStatusResource bool
+	// StatusResourceName is the name of the ClusterAutoscalerStatus custom resource
+	StatusResourceName string
+	// RuntimeConfigMapName is the name of the configmap the tunables that can be changed without restarting
+	// the autoscaler are read from in each loop. Empty if the tunables can't be changed at runtime.
+	RuntimeConfigMapName string
+	// StateCheckpointConfigMapName is the name of the configmap the unneeded times of nodes, the backoff of node
+	// groups, the scale-ups in progress and the times nodes have been unregistered for are saved to after every
+	// loop, so that a new leader or a restarted CA carries them over. Disabled if empty.
+	StateCheckpointConfigMapName string
+	// StateCheckpointMaxAge is how old a state checkpoint can be to be restored.
+	StateCheckpointMaxAge time.Duration
+	// TemplateInjectionConfigMapName is the name of the configmap with the extended resources and labels
+	// injected into the templates of node groups. Empty if nothing is injected.
+	TemplateInjectionConfigMapName string
+	// ScheduledScalingConfigMapName is the name of the configmap with the schedules overriding the min and max
+	// sizes of node groups. Empty if the sizes aren't scheduled.
+	ScheduledScalingConfigMapName string
+	// ActuationWebhookURL is the URL of the webhook called before and after scale-ups and scale-downs.
+	// Empty if no webhook is called.
+	ActuationWebhookURL string
+	// ActuationWebhookTimeout is the timeout of the actuation webhook calls.
+	ActuationWebhookTimeout time.Duration
+	// ActuationWebhookFailurePolicy defines whether scale-ups and scale-downs are allowed (Ignore) or
+	// vetoed (Fail) when the actuation webhook can't be called.
+	ActuationWebhookFailurePolicy string
+	// DynamicResourceAllocationEnabled makes the scheduling simulation account for the devices requested by
+	// the resource claims of pods and published in the resource slices of nodes.
+	DynamicResourceAllocationEnabled bool
 	// BalanceSimilarNodeGroups enables logic that identifies node groups with similar machines and tries to balance node count between them.
 	BalanceSimilarNodeGroups bool
 	// ConfigNamespace is the namespace cluster-autoscaler is running in and all related configmaps live in
@@ -204,6 +297,8 @@ type AutoscalingOptions struct {
 	// Pods with priority below cutoff are expendable. They can be killed without any consideration during scale down and they don't cause scale-up.
 	// Pods with null priority (PodPriority disabled) are non-expendable.
 	ExpendablePodsPriorityCutoff int
+	// ExpendablePodsNamespaceCutoffs override ExpendablePodsPriorityCutoff for the pods of the given namespaces.
+	ExpendablePodsNamespaceCutoffs map[string]int
 	// Regional tells whether the cluster is regional.
 	Regional bool
 	// Pods newer than this will not be considered as unschedulable for scale-up.
@@ -215,6 +310,14 @@ type AutoscalingOptions struct {
 	MaxBulkSoftTaintTime time.Duration
 	// MaxPodEvictionTime sets the maximum time CA tries to evict a pod before giving up.
 	MaxPodEvictionTime time.Duration
+	// MaxPodDrainTimeout caps the graceful termination time pods may ask for with the drain timeout annotation.
+	// Value of 0 ignores the annotation.
+	MaxPodDrainTimeout time.Duration
+	// MaxConcurrentEvictionsPerNode is the maximum number of pods evicted concurrently from a single drained node.
+	// Value of 0 doesn't limit concurrent evictions.
+	MaxConcurrentEvictionsPerNode int
+	// PodEvictionInterval is the minimum time between the starts of consecutive pod evictions from a single drained node.
+	PodEvictionInterval time.Duration
 	// StartupTaints is a list of taints CA considers to reflect transient node
 	// status that should be removed when creating a node template for scheduling.
 	// startup taints are expected to appear during node startup.
@@ -223,12 +326,22 @@ type AutoscalingOptions struct {
 	// status that should be removed when creating a node template for scheduling.
 	// The status taints are expected to appear during node lifetime, after startup.
 	StatusTaints []string
+	// IgnoredTemplateTaintPrefixes is a list of taint key prefixes, e.g. of ephemeral vendor taints, that should
+	// be removed when creating a node template for scheduling, in addition to the built-in ones. Unlike
+	// StartupTaints, they don't make the nodes unready.
+	IgnoredTemplateTaintPrefixes []string
+	// IgnoredTemplateLabelPrefixes is a list of label key prefixes that should be removed when creating a
+	// node template for scheduling.
+	IgnoredTemplateLabelPrefixes []string
 	// BalancingExtraIgnoredLabels is a list of labels to additionally ignore when comparing if two node groups are similar.
 	// Labels in BasicIgnoredLabels and the cloud provider-specific ignored labels are always ignored.
 	BalancingExtraIgnoredLabels []string
 	// BalancingLabels is a list of labels to use when comparing if two node groups are similar.
 	// If this is set, only labels are used to compare node groups. It is mutually exclusive with BalancingExtraIgnoredLabels.
 	BalancingLabels []string
+	// NodeInfoComparator is the name of the registered NodeInfoComparator used to compare if two node groups are similar.
+	// If empty, the one registered under the cloud provider name is used, falling back to the generic one.
+	NodeInfoComparator string
 	// AWSUseStaticInstanceList tells if AWS cloud provider use static instance type list or dynamically fetch from remote APIs.
 	AWSUseStaticInstanceList bool
 	// GCEOptions contain autoscaling options specific to GCE cloud provider.
@@ -244,6 +357,10 @@ type AutoscalingOptions struct {
 	DaemonSetEvictionForEmptyNodes bool
 	// DaemonSetEvictionForOccupiedNodes is whether CA will gracefully terminate DaemonSet pods from non-empty nodes.
 	DaemonSetEvictionForOccupiedNodes bool
+	// DaemonSetPdbTimeout is how long CA retries evictions of DaemonSet pods blocked by their PodDisruptionBudgets
+	// before proceeding with the scale-down. DaemonSet pods covered by PDBs are always evicted if it's positive.
+	// PDBs covering DaemonSet pods are ignored if it's 0.
+	DaemonSetPdbTimeout time.Duration
 	// User agent to use for HTTP calls.
 	UserAgent string
 	// InitialNodeGroupBackoffDuration is the duration of first backoff after a new node failed to start
@@ -252,24 +369,42 @@ type AutoscalingOptions struct {
 	MaxNodeGroupBackoffDuration time.Duration
 	// NodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.
 	NodeGroupBackoffResetTimeout time.Duration
+	// NodeGroupBackoffPolicies override the initial and maximum backoff duration after scale-up failures,
+	// by error code or, if no policy is set for the error code, error class.
+	NodeGroupBackoffPolicies map[string]NodeGroupBackoffPolicy
 	// MaxScaleDownParallelism is the maximum number of nodes (both empty and needing drain) that can be deleted in parallel.
 	MaxScaleDownParallelism int
 	// MaxDrainParallelism is the maximum number of nodes needing drain, that can be drained and deleted in parallel.
 	MaxDrainParallelism int
 	// RecordDuplicatedEvents controls whether events should be duplicated within a 5 minute window.
 	RecordDuplicatedEvents bool
+	// AggregateScaleUpEvents makes CA emit NotTriggerScaleUp and TriggeredScaleUp events once per owning workload
+	// of the pods instead of once per pod.
+	AggregateScaleUpEvents bool
 	// MaxNodesPerScaleUp controls how many nodes can be added in a single scale-up.
 	// Note that this is strictly a performance optimization aimed at limiting binpacking time, not a tool to rate-limit
 	// scale-up. There is nothing stopping CA from adding MaxNodesPerScaleUp every loop.
 	MaxNodesPerScaleUp int
+	// MaxNewNodesPerMinute is the maximum number of nodes added to the whole cluster per minute. Unlike
+	// MaxNodesPerScaleUp it rate-limits scale-ups, smoothing provisioning bursts. 0 means no limit.
+	MaxNewNodesPerMinute int
+	// MaxHourlyCost is the maximum hourly cost of the cluster, estimated with the pricing model of the cloud provider.
+	// Scale-ups that would exceed it are rejected. 0 means no limit.
+	MaxHourlyCost float64
 	// MaxNodeGroupBinpackingDuration is a maximum time that can be spent binpacking a single NodeGroup. If the threshold
 	// is exceeded binpacking will be cut short and a partial scale-up will be performed.
 	MaxNodeGroupBinpackingDuration time.Duration
 	// MaxBinpackingTime is the maximum time spend on binpacking for a single scale-up.
 	// If binpacking is limited by this, scale-up will continue with the already calculated scale-up options.
 	MaxBinpackingTime time.Duration
+	// ParallelEstimationWorkers is the number of workers estimating the expansion options of node groups in parallel
+	// during scale-up. With 1 worker, node groups are estimated one by one.
+	ParallelEstimationWorkers int
 	// NodeDeletionBatcherInterval is a time for how long CA ScaleDown gather nodes to delete them in batch.
 	NodeDeletionBatcherInterval time.Duration
+	// MaxConcurrentDeletionsPerZone is the maximum number of nodes of a zone drained and deleted concurrently.
+	// 0 means no limit.
+	MaxConcurrentDeletionsPerZone int
 	// SkipNodesWithSystemPods tells if nodes with pods from kube-system should be deleted (except for DaemonSet or mirror pods)
 	SkipNodesWithSystemPods bool
 	// SkipNodesWithLocalStorage tells if nodes with pods with local storage, e.g. EmptyDir or HostPath, should be deleted
@@ -279,6 +414,15 @@ type AutoscalingOptions struct {
 	// MinReplicaCount controls the minimum number of replicas that a replica set or replication controller should have
 	// to allow their pods deletion in scale down
 	MinReplicaCount int
+	// DrainabilityWebhookURL is the URL of a webhook consulted on whether pods of scale down candidates can be drained.
+	// The webhook isn't used if it is empty.
+	DrainabilityWebhookURL string
+	// DrainabilityWebhookTimeout is the timeout of a single drainability webhook call.
+	DrainabilityWebhookTimeout time.Duration
+	// DrainabilityWebhookCacheTTL is how long drainability webhook responses are cached per pod.
+	DrainabilityWebhookCacheTTL time.Duration
+	// DrainabilityWebhookFailClosed tells if pods should block scale down of their node when the drainability webhook fails.
+	DrainabilityWebhookFailClosed bool
 	// NodeDeleteDelayAfterTaint is the duration to wait before deleting a node after tainting it
 	NodeDeleteDelayAfterTaint time.Duration
 	// ParallelDrain is whether CA can drain nodes in parallel.
@@ -290,8 +434,24 @@ type AutoscalingOptions struct {
 	DynamicNodeDeleteDelayAfterTaintEnabled bool
 	// BypassedSchedulers are used to specify which schedulers to bypass their processing
 	BypassedSchedulers map[string]bool
+	// AdditionalSchedulers maps the names of the schedulers, other than the default one, whose pods trigger scale-up
+	// to the scheduler name of the profile of SchedulerConfig their pods are simulated with, empty for the default
+	// profile. If it's empty, pods of all schedulers trigger scale-up.
+	AdditionalSchedulers map[string]string
 	// ProvisioningRequestEnabled tells if CA processes ProvisioningRequest.
 	ProvisioningRequestEnabled bool
+	// ProvisioningRequestExpirationTime is the time since creation after which ProvisioningRequests that weren't provisioned fail.
+	ProvisioningRequestExpirationTime time.Duration
+	// ProvisioningRequestRetentionTime is the time after which failed or booking expired ProvisioningRequests are deleted.
+	// They are never deleted if it is 0.
+	ProvisioningRequestRetentionTime time.Duration
+	// CapacityBufferEnabled tells if CA keeps the spare capacity of CapacityBuffers.
+	CapacityBufferEnabled bool
+	// PredictiveScaleUpAggressiveness is the number of pods predicted to arrive per pod created in the last
+	// PredictiveScaleUpWindow that was pending. The predictive scale-up is disabled if it is 0.
+	PredictiveScaleUpAggressiveness float64
+	// PredictiveScaleUpWindow is how long after their creation pending pods are counted by the predictive scale-up.
+	PredictiveScaleUpWindow time.Duration
 }
 
 // KubeClientOptions specify options for kube client
@@ -40,6 +40,10 @@ const (
 	DefaultMaxNodeProvisionTimeKey = "maxnodeprovisiontime"
 	// DefaultIgnoreDaemonSetsUtilizationKey identifies IgnoreDaemonSetsUtilization autoscaling option
 	DefaultIgnoreDaemonSetsUtilizationKey = "ignoredaemonsetsutilization"
+	// DefaultScaleDownDelayAfterAddKey identifies ScaleDownDelayAfterAdd autoscaling option
+	DefaultScaleDownDelayAfterAddKey = "scaledowndelayafteradd"
+	// DefaultMaxNewNodesPerMinuteKey identifies MaxNewNodesPerMinute autoscaling option
+	DefaultMaxNewNodesPerMinuteKey = "maxnewnodesperminute"
 
 	// DefaultScaleDownUnneededTime is the default time duration for which CA waits before deleting an unneeded node
 	DefaultScaleDownUnneededTime = 10 * time.Minute
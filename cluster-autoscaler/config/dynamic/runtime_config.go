@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamic
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+// RuntimeConfigMapKey is the key of the runtime configuration in its ConfigMap.
+const RuntimeConfigMapKey = "config"
+
+// RuntimeConfig holds the tunables that can be changed without restarting the autoscaler.
+// The keys are named after the corresponding flags. Tunables that are not set keep
+// the value passed on the command line.
+type RuntimeConfig struct {
+	ScaleDownEnabled                 *bool          `yaml:"scale-down-enabled"`
+	ScaleDownUtilizationThreshold    *float64       `yaml:"scale-down-utilization-threshold"`
+	ScaleDownGpuUtilizationThreshold *float64       `yaml:"scale-down-gpu-utilization-threshold"`
+	ScaleDownUnneededTime            *time.Duration `yaml:"scale-down-unneeded-time"`
+	ScaleDownUnreadyTime             *time.Duration `yaml:"scale-down-unready-time"`
+	ScaleDownDelayAfterAdd           *time.Duration `yaml:"scale-down-delay-after-add"`
+	ScaleDownDelayAfterDelete        *time.Duration `yaml:"scale-down-delay-after-delete"`
+	ScaleDownDelayAfterFailure       *time.Duration `yaml:"scale-down-delay-after-failure"`
+	MaxNodeProvisionTime             *time.Duration `yaml:"max-node-provision-time"`
+	MaxNodesTotal                    *int           `yaml:"max-nodes-total"`
+	Expander                         *string        `yaml:"expander"`
+}
+
+// ParseRuntimeConfig parses a runtime configuration represented in YAML.
+func ParseRuntimeConfig(value string) (*RuntimeConfig, error) {
+	var c RuntimeConfig
+	if err := yaml.UnmarshalStrict([]byte(value), &c); err != nil {
+		return nil, fmt.Errorf("can't parse runtime configuration: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid runtime configuration: %v", err)
+	}
+	return &c, nil
+}
+
+// Validate produces an error if there's an invalid field in the runtime configuration
+func (c *RuntimeConfig) Validate() error {
+	for name, threshold := range map[string]*float64{
+		"scale-down-utilization-threshold":     c.ScaleDownUtilizationThreshold,
+		"scale-down-gpu-utilization-threshold": c.ScaleDownGpuUtilizationThreshold,
+	} {
+		if threshold != nil && (*threshold < 0 || *threshold > 1) {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	for name, duration := range map[string]*time.Duration{
+		"scale-down-unneeded-time":       c.ScaleDownUnneededTime,
+		"scale-down-unready-time":        c.ScaleDownUnreadyTime,
+		"scale-down-delay-after-add":     c.ScaleDownDelayAfterAdd,
+		"scale-down-delay-after-delete":  c.ScaleDownDelayAfterDelete,
+		"scale-down-delay-after-failure": c.ScaleDownDelayAfterFailure,
+		"max-node-provision-time":        c.MaxNodeProvisionTime,
+	} {
+		if duration != nil && *duration < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if c.MaxNodesTotal != nil && *c.MaxNodesTotal < 0 {
+		return fmt.Errorf("max-nodes-total must not be negative")
+	}
+	if c.Expander != nil && *c.Expander == "" {
+		return fmt.Errorf("expander must not be blank")
+	}
+	return nil
+}
+
+// Apply returns the given options with the tunables set in the runtime configuration overridden.
+func (c *RuntimeConfig) Apply(options config.AutoscalingOptions) config.AutoscalingOptions {
+	if c.ScaleDownEnabled != nil {
+		options.ScaleDownEnabled = *c.ScaleDownEnabled
+	}
+	if c.ScaleDownUtilizationThreshold != nil {
+		options.NodeGroupDefaults.ScaleDownUtilizationThreshold = *c.ScaleDownUtilizationThreshold
+	}
+	if c.ScaleDownGpuUtilizationThreshold != nil {
+		options.NodeGroupDefaults.ScaleDownGpuUtilizationThreshold = *c.ScaleDownGpuUtilizationThreshold
+	}
+	if c.ScaleDownUnneededTime != nil {
+		options.NodeGroupDefaults.ScaleDownUnneededTime = *c.ScaleDownUnneededTime
+	}
+	if c.ScaleDownUnreadyTime != nil {
+		options.NodeGroupDefaults.ScaleDownUnreadyTime = *c.ScaleDownUnreadyTime
+	}
+	if c.ScaleDownDelayAfterAdd != nil {
+		options.ScaleDownDelayAfterAdd = *c.ScaleDownDelayAfterAdd
+		options.NodeGroupDefaults.ScaleDownDelayAfterAdd = *c.ScaleDownDelayAfterAdd
+	}
+	if c.ScaleDownDelayAfterDelete != nil {
+		options.ScaleDownDelayAfterDelete = *c.ScaleDownDelayAfterDelete
+	}
+	if c.ScaleDownDelayAfterFailure != nil {
+		options.ScaleDownDelayAfterFailure = *c.ScaleDownDelayAfterFailure
+	}
+	if c.MaxNodeProvisionTime != nil {
+		options.NodeGroupDefaults.MaxNodeProvisionTime = *c.MaxNodeProvisionTime
+	}
+	if c.MaxNodesTotal != nil {
+		options.MaxNodesTotal = *c.MaxNodesTotal
+	}
+	if c.Expander != nil {
+		options.ExpanderNames = *c.Expander
+	}
+	return options
+}
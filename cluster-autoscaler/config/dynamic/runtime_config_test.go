@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+func TestParseRuntimeConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			value: "",
+		},
+		{
+			name: "all tunables",
+			value: `
+scale-down-enabled: false
+scale-down-utilization-threshold: 0.6
+scale-down-gpu-utilization-threshold: 0.7
+scale-down-unneeded-time: 5m
+scale-down-unready-time: 30m
+scale-down-delay-after-add: 15m
+scale-down-delay-after-delete: 1m
+scale-down-delay-after-failure: 2m
+max-node-provision-time: 20m
+max-nodes-total: 100
+expander: priority,least-waste
+`,
+		},
+		{
+			name:    "unknown tunable",
+			value:   "scan-interval: 10s",
+			wantErr: true,
+		},
+		{
+			name:    "malformed duration",
+			value:   "scale-down-unneeded-time: soon",
+			wantErr: true,
+		},
+		{
+			name:    "threshold out of range",
+			value:   "scale-down-utilization-threshold: 1.5",
+			wantErr: true,
+		},
+		{
+			name:    "negative duration",
+			value:   "scale-down-delay-after-add: -1m",
+			wantErr: true,
+		},
+		{
+			name:    "negative max nodes",
+			value:   "max-nodes-total: -1",
+			wantErr: true,
+		},
+		{
+			name:    "blank expander",
+			value:   `expander: ""`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRuntimeConfig(tc.value)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRuntimeConfigApply(t *testing.T) {
+	base := config.AutoscalingOptions{
+		NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
+			ScaleDownUtilizationThreshold: 0.5,
+			ScaleDownUnneededTime:         10 * time.Minute,
+			ScaleDownDelayAfterAdd:        10 * time.Minute,
+		},
+		ScaleDownEnabled:       true,
+		ScaleDownDelayAfterAdd: 10 * time.Minute,
+		MaxNodesTotal:          50,
+		ExpanderNames:          "random",
+	}
+	c, err := ParseRuntimeConfig(`
+scale-down-utilization-threshold: 0.6
+scale-down-delay-after-add: 15m
+max-nodes-total: 100
+`)
+	assert.NoError(t, err)
+
+	want := base
+	want.NodeGroupDefaults.ScaleDownUtilizationThreshold = 0.6
+	want.NodeGroupDefaults.ScaleDownDelayAfterAdd = 15 * time.Minute
+	want.ScaleDownDelayAfterAdd = 15 * time.Minute
+	want.MaxNodesTotal = 100
+	assert.Equal(t, want, c.Apply(base))
+	assert.Equal(t, 0.5, base.NodeGroupDefaults.ScaleDownUtilizationThreshold)
+}
@@ -47,6 +47,34 @@ profiles:
         weight: 1
   schedulerName: custom-scheduler`
 
+	// SchedulerConfigTwoProfiles is scheduler config with the default
+	// profile and a profile with `NodeResourcesFit` plugin disabled
+	SchedulerConfigTwoProfiles = `
+apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- schedulerName: default-scheduler
+- pluginConfig:
+  plugins:
+    multiPoint:
+      disabled:
+      - name: NodeResourcesFit
+        weight: 1
+  schedulerName: custom-scheduler`
+
+	// SchedulerConfigCustomPluginEnabled is scheduler config
+	// with the out-of-tree `TestCustomPlugin` plugin enabled
+	SchedulerConfigCustomPluginEnabled = `
+apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- pluginConfig:
+  plugins:
+    multiPoint:
+      enabled:
+      - name: TestCustomPlugin
+  schedulerName: custom-scheduler`
+
 	// SchedulerConfigMinimalCorrect is the minimal
 	// correct scheduler config
 	SchedulerConfigMinimalCorrect = `
@@ -29,6 +29,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	kube_client "k8s.io/client-go/kubernetes"
 	kube_record "k8s.io/client-go/tools/record"
@@ -74,6 +75,9 @@ type AutoscalingKubeClients struct {
 	Recorder kube_record.EventRecorder
 	// LogRecorder can be used to collect log messages to expose via Events on some central object.
 	LogRecorder *utils.LogEventRecorder
+	// StatusResourceClient is used to write the status custom resource. It is nil if the status
+	// is not written to a custom resource.
+	StatusResourceClient dynamic.Interface
 }
 
 // NewResourceLimiterFromAutoscalingOptions creates new instance of cloudprovider.ResourceLimiter
@@ -34,10 +34,13 @@ import (
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/dynamicresources"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	kube_client "k8s.io/client-go/kubernetes"
 )
@@ -46,6 +49,7 @@ import (
 type AutoscalerOptions struct {
 	config.AutoscalingOptions
 	KubeClient             kube_client.Interface
+	StatusResourceClient   dynamic.Interface
 	InformerFactory        informers.SharedInformerFactory
 	AutoscalingKubeClients *context.AutoscalingKubeClients
 	CloudProvider          cloudprovider.CloudProvider
@@ -61,6 +65,8 @@ type AutoscalerOptions struct {
 	ScaleUpOrchestrator    scaleup.Orchestrator
 	DeleteOptions          options.NodeDeleteOptions
 	DrainabilityRules      rules.Rules
+	// expanderFactory is set if ExpanderStrategy was built from ExpanderNames.
+	expanderFactory *factory.Factory
 }
 
 // Autoscaler is the main component of CA which scales up/down node groups according to its configuration
@@ -84,7 +90,7 @@ func NewAutoscaler(opts AutoscalerOptions, informerFactory informers.SharedInfor
 	if err != nil {
 		return nil, errors.ToAutoscalerError(errors.InternalError, err)
 	}
-	return NewStaticAutoscaler(
+	autoscaler := NewStaticAutoscaler(
 		opts.AutoscalingOptions,
 		opts.PredicateChecker,
 		opts.ClusterSnapshot,
@@ -100,7 +106,28 @@ func NewAutoscaler(opts AutoscalerOptions, informerFactory informers.SharedInfor
 		opts.ScaleUpOrchestrator,
 		opts.DeleteOptions,
 		opts.DrainabilityRules,
-	), nil
+	)
+	if opts.RuntimeConfigMapName != "" {
+		// Listers never receive the termination msg on the ch, as in the priority expander.
+		stopChannel := make(chan struct{})
+		lister := kube_util.NewConfigMapListerForNamespace(opts.KubeClient, stopChannel, opts.ConfigNamespace)
+		var buildExpander func(names string) (expander.Strategy, errors.AutoscalerError)
+		if opts.expanderFactory != nil {
+			buildExpander = func(names string) (expander.Strategy, errors.AutoscalerError) {
+				return opts.expanderFactory.Build(strings.Split(names, ","))
+			}
+		}
+		autoscaler.runtimeConfig = newRuntimeConfig(lister.ConfigMaps(opts.ConfigNamespace), opts.RuntimeConfigMapName,
+			opts.AutoscalingKubeClients.Recorder, opts.AutoscalingOptions, buildExpander)
+	}
+	if opts.StateCheckpointConfigMapName != "" {
+		autoscaler.stateCheckpoint = newStateCheckpoint(opts.KubeClient.CoreV1().ConfigMaps(opts.ConfigNamespace),
+			opts.StateCheckpointConfigMapName, opts.StateCheckpointMaxAge, autoscaler.scaleDownPlanner, opts.Backoff, autoscaler.clusterStateRegistry)
+	}
+	if opts.DynamicResourceAllocationEnabled {
+		autoscaler.dynamicResources = dynamicresources.NewProviderFromInformers(informerFactory)
+	}
+	return autoscaler, nil
 }
 
 // Initialize default options if not provided.
@@ -113,6 +140,7 @@ func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers
 	}
 	if opts.AutoscalingKubeClients == nil {
 		opts.AutoscalingKubeClients = context.NewAutoscalingKubeClients(opts.AutoscalingOptions, opts.KubeClient, opts.InformerFactory)
+		opts.AutoscalingKubeClients.StatusResourceClient = opts.StatusResourceClient
 	}
 	if opts.ClusterSnapshot == nil {
 		opts.ClusterSnapshot = clustersnapshot.NewBasicClusterSnapshot()
@@ -125,7 +153,8 @@ func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers
 	}
 	if opts.ExpanderStrategy == nil {
 		expanderFactory := factory.NewFactory()
-		expanderFactory.RegisterDefaultExpanders(opts.CloudProvider, opts.AutoscalingKubeClients, opts.KubeClient, opts.ConfigNamespace, opts.GRPCExpanderCert, opts.GRPCExpanderURL)
+		opts.expanderFactory = expanderFactory
+		expanderFactory.RegisterDefaultExpanders(opts.CloudProvider, opts.AutoscalingKubeClients, opts.KubeClient, opts.ConfigNamespace, opts.GRPCExpanderCert, opts.GRPCExpanderURL, opts.GRPCExpanderCacheTTL, opts.GRPCExpanderHealthCheckInterval, opts.GRPCExpanderFallback, opts.PriceExpanderFile, opts.CarbonIntensityRegions, opts.CarbonIntensityURL)
 		expanderStrategy, err := expanderFactory.Build(strings.Split(opts.ExpanderNames, ","))
 		if err != nil {
 			return err
@@ -151,7 +180,7 @@ func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers
 	}
 	if opts.Backoff == nil {
 		opts.Backoff =
-			backoff.NewIdBasedExponentialBackoff(opts.InitialNodeGroupBackoffDuration, opts.MaxNodeGroupBackoffDuration, opts.NodeGroupBackoffResetTimeout)
+			backoff.NewIdBasedExponentialBackoffWithPolicies(opts.InitialNodeGroupBackoffDuration, opts.MaxNodeGroupBackoffDuration, opts.NodeGroupBackoffResetTimeout, opts.NodeGroupBackoffPolicies)
 	}
 	if opts.DrainabilityRules == nil {
 		opts.DrainabilityRules = rules.Default(opts.DeleteOptions)
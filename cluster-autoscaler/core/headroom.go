@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// headroomPlaceholders returns the virtual pods reserving the headroom of the node group, one per unit.
+func headroomPlaceholders(nodeGroupId string, headroom config.NodeGroupHeadroom) []*apiv1.Pod {
+	pods := make([]*apiv1.Pod, 0, headroom.Units)
+	for i := 0; i < headroom.Units; i++ {
+		name := fmt.Sprintf("headroom-placeholder-%s-%d", nodeGroupId, i)
+		pods = append(pods, &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   metav1.NamespaceDefault,
+				UID:         types.UID(name),
+				Annotations: map[string]string{pod_util.HeadroomPlaceholderAnnotationKey: nodeGroupId},
+			},
+			Spec: apiv1.PodSpec{
+				Containers: []apiv1.Container{{
+					Name:      "placeholder",
+					Resources: apiv1.ResourceRequirements{Requests: headroom.Unit.DeepCopy()},
+				}},
+			},
+		})
+	}
+	return pods
+}
+
+// placeHeadroom adds the virtual pods reserving the headroom of node groups to the nodes of their node
+// group in the cluster snapshot, so that scale-down keeps room for them, and returns the ones that don't
+// fit, by node group id. upcomingNodeGroups are the node groups of the upcoming nodes in the snapshot,
+// by node name.
+func placeHeadroom(ctx *context.AutoscalingContext, headroom map[string]config.NodeGroupHeadroom, upcomingNodeGroups map[string]string) (map[string][]*apiv1.Pod, error) {
+	nodeInfos, err := ctx.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	nodeGroupOf := make(map[string]string)
+	nodeNames := make(map[string][]string)
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		id, found := upcomingNodeGroups[node.Name]
+		if !found {
+			nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+			if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+				continue
+			}
+			id = nodeGroup.Id()
+		}
+		if _, found := headroom[id]; found {
+			nodeGroupOf[node.Name] = id
+			nodeNames[id] = append(nodeNames[id], node.Name)
+		}
+	}
+
+	ids := make([]string, 0, len(headroom))
+	for id := range headroom {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	unplaced := make(map[string][]*apiv1.Pod)
+	for _, id := range ids {
+		placeholders := headroomPlaceholders(id, headroom[id])
+		for i, pod := range placeholders {
+			nodeName, err := ctx.PredicateChecker.FitsAnyNodeMatching(ctx.ClusterSnapshot, pod, func(nodeInfo *schedulerframework.NodeInfo) bool {
+				return nodeGroupOf[nodeInfo.Node().Name] == id
+			})
+			if err != nil {
+				// The placeholders are identical, so none of the remaining ones fit either.
+				unplaced[id] = placeholders[i:]
+				break
+			}
+			// Scale-down may only move the placeholder to another node of the node group.
+			pod.Spec.NodeName = nodeName
+			pod.Spec.Affinity = &apiv1.Affinity{NodeAffinity: &apiv1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+					NodeSelectorTerms: []apiv1.NodeSelectorTerm{{
+						MatchFields: []apiv1.NodeSelectorRequirement{{
+							Key:      metav1.ObjectNameField,
+							Operator: apiv1.NodeSelectorOpIn,
+							Values:   nodeNames[id],
+						}},
+					}},
+				},
+			}}
+			if err := ctx.ClusterSnapshot.AddPod(pod, nodeName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return unplaced, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPlaceHeadroom(t *testing.T) {
+	headroom := map[string]config.NodeGroupHeadroom{
+		"ng1": {Units: 3, Unit: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("1")}},
+	}
+	testCases := []struct {
+		name         string
+		withUpcoming bool
+		wantUnplaced int
+		wantPlaced   int
+	}{
+		{
+			name:         "units that don't fit are returned",
+			wantUnplaced: 1,
+			wantPlaced:   2,
+		},
+		{
+			name:         "units fit on upcoming nodes",
+			withUpcoming: true,
+			wantPlaced:   3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 0, 10, 2)
+			provider.AddNodeGroup("ng2", 0, 10, 1)
+			n1 := BuildTestNode("n1", 2000, 1000)
+			n2 := BuildTestNode("n2", 2000, 1000)
+			n3 := BuildTestNode("n3", 2000, 1000)
+			provider.AddNode("ng1", n1)
+			provider.AddNode("ng1", n2)
+			provider.AddNode("ng2", n3)
+			p1 := BuildTestPod("p1", 1500, 0)
+			p1.Spec.NodeName = "n1"
+
+			ctx, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, &fake.Clientset{}, kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, nil), provider, nil, nil)
+			assert.NoError(t, err)
+			assert.NoError(t, ctx.ClusterSnapshot.AddNodeWithPods(n1, []*apiv1.Pod{p1}))
+			assert.NoError(t, ctx.ClusterSnapshot.AddNode(n2))
+			assert.NoError(t, ctx.ClusterSnapshot.AddNode(n3))
+			upcomingNodeGroups := map[string]string{}
+			if tc.withUpcoming {
+				assert.NoError(t, ctx.ClusterSnapshot.AddNode(BuildTestNode("u1", 2000, 1000)))
+				upcomingNodeGroups["u1"] = "ng1"
+			}
+
+			unplaced, err := placeHeadroom(&ctx, headroom, upcomingNodeGroups)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantUnplaced, len(unplaced["ng1"]))
+
+			placed := 0
+			nodeInfos, err := ctx.ClusterSnapshot.NodeInfos().List()
+			assert.NoError(t, err)
+			for _, nodeInfo := range nodeInfos {
+				for _, podInfo := range nodeInfo.Pods {
+					if pod_util.IsHeadroomPlaceholderPod(podInfo.Pod) {
+						placed++
+						// n1 is full and n3 is in another node group.
+						assert.Contains(t, []string{"n2", "u1"}, nodeInfo.Node().Name)
+						assert.Equal(t, nodeInfo.Node().Name, podInfo.Pod.Spec.NodeName)
+						assert.NotNil(t, podInfo.Pod.Spec.Affinity)
+					}
+				}
+			}
+			assert.Equal(t, tc.wantPlaced, placed)
+		})
+	}
+}
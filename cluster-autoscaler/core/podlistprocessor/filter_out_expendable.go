@@ -41,8 +41,9 @@ func (p *filterOutExpendable) Process(context *context.AutoscalingContext, pods
 		return nil, fmt.Errorf("Failed to list all nodes while filtering expendable pods: %v", err)
 	}
 	expendablePodsPriorityCutoff := context.AutoscalingOptions.ExpendablePodsPriorityCutoff
+	namespaceCutoffs := context.AutoscalingOptions.ExpendablePodsNamespaceCutoffs
 
-	unschedulablePods, waitingForLowerPriorityPreemption := core_utils.FilterOutExpendableAndSplit(pods, nodes, expendablePodsPriorityCutoff)
+	unschedulablePods, waitingForLowerPriorityPreemption := core_utils.FilterOutExpendableAndSplit(pods, nodes, expendablePodsPriorityCutoff, namespaceCutoffs)
 	if err = p.addPreemptingPodsToSnapshot(waitingForLowerPriorityPreemption, context); err != nil {
 		klog.Warningf("Failed to add preempting pods to snapshot: %v", err)
 		return nil, err
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/config/dynamic"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/record"
+	klog "k8s.io/klog/v2"
+)
+
+// runtimeConfig overrides the options the autoscaler was started with by the tunables read from
+// the runtime configuration ConfigMap, so that they can be changed without restarting the autoscaler
+// and losing its state.
+type runtimeConfig struct {
+	configMapLister v1lister.ConfigMapNamespaceLister
+	configMapName   string
+	recorder        record.EventRecorder
+	// baseOptions are the options the autoscaler was started with. Tunables removed
+	// from the ConfigMap go back to their values.
+	baseOptions config.AutoscalingOptions
+	// buildExpander builds the expander strategy of the given expander names. If nil,
+	// the expander can't be changed at runtime.
+	buildExpander func(names string) (expander.Strategy, errors.AutoscalerError)
+	// lastValue is the last runtime configuration that was processed.
+	lastValue string
+}
+
+func newRuntimeConfig(configMapLister v1lister.ConfigMapNamespaceLister, configMapName string, recorder record.EventRecorder,
+	baseOptions config.AutoscalingOptions, buildExpander func(names string) (expander.Strategy, errors.AutoscalerError)) *runtimeConfig {
+	return &runtimeConfig{
+		configMapLister: configMapLister,
+		configMapName:   configMapName,
+		recorder:        recorder,
+		baseOptions:     baseOptions,
+		buildExpander:   buildExpander,
+	}
+}
+
+// reload applies the runtime configuration to the autoscaling context if it changed since the last call.
+// An invalid runtime configuration is ignored and the autoscaler keeps running with the current options.
+func (r *runtimeConfig) reload(autoscalingContext *context.AutoscalingContext, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) {
+	value := ""
+	cm, err := r.configMapLister.Get(r.configMapName)
+	if err != nil && !kube_errors.IsNotFound(err) {
+		klog.Warningf("Failed to get runtime configuration configmap %s: %v", r.configMapName, err)
+		return
+	}
+	if err == nil {
+		var found bool
+		value, found = cm.Data[dynamic.RuntimeConfigMapKey]
+		if !found && r.lastValue != "" {
+			r.logConfigWarning(cm, fmt.Sprintf("Runtime configuration configmap %s doesn't contain %s key, reverting to the command line configuration", r.configMapName, dynamic.RuntimeConfigMapKey))
+		}
+	}
+	if value == r.lastValue {
+		return
+	}
+	r.lastValue = value
+
+	runtimeConfig, err := dynamic.ParseRuntimeConfig(value)
+	if err != nil {
+		r.logConfigWarning(cm, fmt.Sprintf("Wrong runtime configuration in configmap %s: %v. Ignoring update.", r.configMapName, err))
+		return
+	}
+	options := runtimeConfig.Apply(r.baseOptions)
+	if options.ExpanderNames != autoscalingContext.ExpanderNames {
+		if r.buildExpander == nil {
+			r.logConfigWarning(cm, fmt.Sprintf("Expander can't be changed at runtime, keeping %s", autoscalingContext.ExpanderNames))
+			options.ExpanderNames = autoscalingContext.ExpanderNames
+		} else {
+			expanderStrategy, err := r.buildExpander(options.ExpanderNames)
+			if err != nil {
+				r.logConfigWarning(cm, fmt.Sprintf("Wrong expander %s in runtime configuration: %v. Ignoring update.", options.ExpanderNames, err))
+				return
+			}
+			autoscalingContext.ExpanderStrategy = expanderStrategy
+		}
+	}
+	autoscalingContext.AutoscalingOptions = options
+	if updater, ok := nodeGroupConfigProcessor.(nodegroupconfig.NodeGroupDefaultsUpdater); ok {
+		updater.SetNodeGroupDefaults(options.NodeGroupDefaults)
+	}
+	klog.V(1).Infof("Applied runtime configuration from configmap %s", r.configMapName)
+}
+
+func (r *runtimeConfig) logConfigWarning(cm *apiv1.ConfigMap, msg string) {
+	if cm != nil {
+		r.recorder.Event(cm, apiv1.EventTypeWarning, "RuntimeConfigInvalid", msg)
+	}
+	klog.Warning(msg)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+func TestRuntimeConfigReload(t *testing.T) {
+	const (
+		namespace     = "kube-system"
+		configMapName = "cluster-autoscaler-runtime-config"
+	)
+	baseOptions := config.AutoscalingOptions{
+		NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
+			ScaleDownUnneededTime: 10 * time.Minute,
+		},
+		MaxNodesTotal: 50,
+		ExpanderNames: expander.RandomExpanderName,
+	}
+	builtExpanders := []string{}
+	buildExpander := func(names string) (expander.Strategy, errors.AutoscalerError) {
+		if names == "unknown" {
+			return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s not supported", names)
+		}
+		builtExpanders = append(builtExpanders, names)
+		return random.NewStrategy(), nil
+	}
+	configMap := func(data map[string]string) *apiv1.ConfigMap {
+		return &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: namespace}, Data: data}
+	}
+
+	testCases := []struct {
+		name              string
+		configMap         *apiv1.ConfigMap
+		wantMaxNodesTotal int
+		wantUnneededTime  time.Duration
+		wantExpander      string
+	}{
+		{
+			name:              "no configmap",
+			wantMaxNodesTotal: 50,
+			wantUnneededTime:  10 * time.Minute,
+			wantExpander:      expander.RandomExpanderName,
+		},
+		{
+			name:              "tunables overridden",
+			configMap:         configMap(map[string]string{"config": "max-nodes-total: 100\nscale-down-unneeded-time: 2m\nexpander: least-waste"}),
+			wantMaxNodesTotal: 100,
+			wantUnneededTime:  2 * time.Minute,
+			wantExpander:      expander.LeastWasteExpanderName,
+		},
+		{
+			name:              "invalid configuration is ignored",
+			configMap:         configMap(map[string]string{"config": "max-nodes-total: -1"}),
+			wantMaxNodesTotal: 100,
+			wantUnneededTime:  2 * time.Minute,
+			wantExpander:      expander.LeastWasteExpanderName,
+		},
+		{
+			name:              "unknown expander is ignored",
+			configMap:         configMap(map[string]string{"config": "max-nodes-total: 10\nexpander: unknown"}),
+			wantMaxNodesTotal: 100,
+			wantUnneededTime:  2 * time.Minute,
+			wantExpander:      expander.LeastWasteExpanderName,
+		},
+		{
+			name:              "tunable removed",
+			configMap:         configMap(map[string]string{"config": "expander: least-waste"}),
+			wantMaxNodesTotal: 50,
+			wantUnneededTime:  10 * time.Minute,
+			wantExpander:      expander.LeastWasteExpanderName,
+		},
+		{
+			name:              "configmap removed",
+			wantMaxNodesTotal: 50,
+			wantUnneededTime:  10 * time.Minute,
+			wantExpander:      expander.RandomExpanderName,
+		},
+	}
+
+	autoscalingContext := &context.AutoscalingContext{AutoscalingOptions: baseOptions}
+	nodeGroupConfigProcessor := nodegroupconfig.NewDefaultNodeGroupConfigProcessor(baseOptions.NodeGroupDefaults)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	ng1 := provider.GetNodeGroup("ng1")
+
+	r := newRuntimeConfig(nil, configMapName, kube_record.NewFakeRecorder(10), baseOptions, buildExpander)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cms []*apiv1.ConfigMap
+			if tc.configMap != nil {
+				cms = append(cms, tc.configMap)
+			}
+			lister, err := kube_util.NewTestConfigMapLister(cms)
+			assert.NoError(t, err)
+			r.configMapLister = lister.ConfigMaps(namespace)
+
+			r.reload(autoscalingContext, nodeGroupConfigProcessor)
+
+			assert.Equal(t, tc.wantMaxNodesTotal, autoscalingContext.MaxNodesTotal)
+			assert.Equal(t, tc.wantUnneededTime, autoscalingContext.NodeGroupDefaults.ScaleDownUnneededTime)
+			assert.Equal(t, tc.wantExpander, autoscalingContext.ExpanderNames)
+			unneededTime, err := nodeGroupConfigProcessor.GetScaleDownUnneededTime(ng1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantUnneededTime, unneededTime)
+		})
+	}
+	assert.Equal(t, []string{expander.LeastWasteExpanderName, expander.RandomExpanderName}, builtExpanders)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	klog "k8s.io/klog/v2"
+)
+
+// averageMonth is the period the savings of a scale-down dry run are estimated for.
+const averageMonth = 365 * 24 * time.Hour / 12
+
+// reportScaleDownDryRun reports the nodes the scale-down would remove, with their estimated monthly
+// cost, in events, metrics and the returned status instead of removing them.
+func reportScaleDownDryRun(autoscalingContext *context.AutoscalingContext, empty, needDrain []*apiv1.Node, now time.Time) *api.ScaleDownDryRun {
+	pricing, err := autoscalingContext.CloudProvider.Pricing()
+	if err != nil {
+		klog.V(4).Infof("Scale-down dry run savings not estimated, pricing not available: %v", err)
+	}
+	result := &api.ScaleDownDryRun{LastProbeTime: metav1.NewTime(now)}
+	savings := 0.0
+	priced := pricing != nil
+	report := func(node *apiv1.Node, how string) {
+		result.Nodes = append(result.Nodes, node.Name)
+		if pricing == nil {
+			autoscalingContext.Recorder.Eventf(node, apiv1.EventTypeNormal, "ScaleDownDryRun", "node would be removed %s", how)
+			return
+		}
+		price, err := pricing.NodePrice(node, now, now.Add(averageMonth))
+		if err != nil {
+			klog.Warningf("Failed to get price of node %s: %v", node.Name, err)
+			priced = false
+			autoscalingContext.Recorder.Eventf(node, apiv1.EventTypeNormal, "ScaleDownDryRun", "node would be removed %s", how)
+			return
+		}
+		savings += price
+		autoscalingContext.Recorder.Eventf(node, apiv1.EventTypeNormal, "ScaleDownDryRun", "node would be removed %s, estimated monthly savings %.2f", how, price)
+	}
+	for _, node := range empty {
+		report(node, "as empty")
+	}
+	for _, node := range needDrain {
+		report(node, "after evicting its pods")
+	}
+	if !priced {
+		metrics.UpdateScaleDownDryRun(len(result.Nodes), 0)
+		if len(result.Nodes) > 0 {
+			klog.V(1).Infof("Scale-down dry run: would remove %d nodes %v", len(result.Nodes), result.Nodes)
+			autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDownDryRun", "Scale-down dry run: would remove %d nodes", len(result.Nodes))
+		}
+		return result
+	}
+	result.EstimatedMonthlySavings = &savings
+	metrics.UpdateScaleDownDryRun(len(result.Nodes), savings)
+	if len(result.Nodes) > 0 {
+		klog.V(1).Infof("Scale-down dry run: would remove %d nodes %v, estimated monthly savings %.2f", len(result.Nodes), result.Nodes, savings)
+		autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDownDryRun",
+			"Scale-down dry run: would remove %d nodes, estimated monthly savings %.2f", len(result.Nodes), savings)
+	}
+	return result
+}
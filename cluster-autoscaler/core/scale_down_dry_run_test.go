@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+type dryRunPricingModel struct {
+	nodePrices map[string]float64
+}
+
+func (m *dryRunPricingModel) NodePrice(node *apiv1.Node, _, _ time.Time) (float64, error) {
+	if price, found := m.nodePrices[node.Name]; found {
+		return price, nil
+	}
+	return 0, fmt.Errorf("unknown node %s", node.Name)
+}
+
+func (m *dryRunPricingModel) PodPrice(*apiv1.Pod, time.Time, time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestReportScaleDownDryRun(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n3 := BuildTestNode("n3", 1000, 1000)
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		pricing     *dryRunPricingModel
+		empty       []*apiv1.Node
+		needDrain   []*apiv1.Node
+		wantNodes   []string
+		wantSavings *float64
+		wantEvents  int
+	}{
+		{
+			name:        "priced nodes",
+			pricing:     &dryRunPricingModel{nodePrices: map[string]float64{"n1": 100, "n2": 50.5}},
+			empty:       []*apiv1.Node{n1},
+			needDrain:   []*apiv1.Node{n2},
+			wantNodes:   []string{"n1", "n2"},
+			wantSavings: floatPtr(150.5),
+			wantEvents:  2,
+		},
+		{
+			name:       "node without price",
+			pricing:    &dryRunPricingModel{nodePrices: map[string]float64{"n1": 100}},
+			empty:      []*apiv1.Node{n1, n3},
+			wantNodes:  []string{"n1", "n3"},
+			wantEvents: 2,
+		},
+		{
+			name:       "no pricing",
+			needDrain:  []*apiv1.Node{n1},
+			wantNodes:  []string{"n1"},
+			wantEvents: 1,
+		},
+		{
+			name:        "no nodes",
+			pricing:     &dryRunPricingModel{},
+			wantSavings: floatPtr(0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			if tc.pricing != nil {
+				provider.SetPricingModel(tc.pricing)
+			}
+			recorder := kube_record.NewFakeRecorder(10)
+			logRecorder, err := utils.NewStatusMapRecorder(fake.NewSimpleClientset(), "kube-system", recorder, false, "my-cool-configmap")
+			assert.NoError(t, err)
+			autoscalingContext := &context.AutoscalingContext{
+				CloudProvider:          provider,
+				AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: recorder, LogRecorder: logRecorder},
+			}
+
+			result := reportScaleDownDryRun(autoscalingContext, tc.empty, tc.needDrain, now)
+
+			assert.Equal(t, tc.wantNodes, result.Nodes)
+			assert.Equal(t, tc.wantSavings, result.EstimatedMonthlySavings)
+			assert.Equal(t, now.Unix(), result.LastProbeTime.Unix())
+			assert.Equal(t, tc.wantEvents, len(recorder.Events))
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -19,6 +19,7 @@ package actuation
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -31,8 +32,10 @@ import (
 	kubelet_config "k8s.io/kubernetes/pkg/kubelet/apis/config"
 
 	acontext "k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/daemonset"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -78,7 +81,7 @@ func NewEvictor(evictionRegister evictionRegister, shutdownGracePeriodByPodPrior
 // If priority evictor is not enable, eviction of daemonSet pods is the best effort.
 func (e Evictor) DrainNode(ctx *acontext.AutoscalingContext, nodeInfo *framework.NodeInfo) (map[string]status.PodEvictionResult, error) {
 	node := nodeInfo.Node()
-	dsPods, pods := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForOccupiedNodes)
+	dsPods, pods := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForOccupiedNodes, daemonSetPdbTracker(ctx))
 	if e.fullDsEviction {
 		return e.drainNodeWithPodsBasedOnPodPriority(ctx, node, append(pods, dsPods...), nil)
 	}
@@ -89,7 +92,7 @@ func (e Evictor) DrainNode(ctx *acontext.AutoscalingContext, nodeInfo *framework
 // If priority evictor is not enable, eviction of daemonSet pods is the best effort.
 func (e Evictor) EvictDaemonSetPods(ctx *acontext.AutoscalingContext, nodeInfo *framework.NodeInfo) (map[string]status.PodEvictionResult, error) {
 	node := nodeInfo.Node()
-	dsPods, _ := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForEmptyNodes)
+	dsPods, _ := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForEmptyNodes, daemonSetPdbTracker(ctx))
 	if e.fullDsEviction {
 		return e.drainNodeWithPodsBasedOnPodPriority(ctx, node, dsPods, nil)
 	}
@@ -135,7 +138,12 @@ func (e Evictor) drainNodeWithPodsBasedOnPodPriority(ctx *acontext.AutoscalingCo
 func (e Evictor) waitPodsToDisappear(ctx *acontext.AutoscalingContext, node *apiv1.Node, pods []*apiv1.Pod, evictionResults map[string]status.PodEvictionResult,
 	maxTermination int64) (map[string]status.PodEvictionResult, error) {
 	var allGone bool
-	for start := time.Now(); time.Now().Sub(start) < time.Duration(maxTermination)*time.Second+e.PodEvictionHeadroom; time.Sleep(5 * time.Second) {
+	waitTime := maxTermination
+	for _, pod := range pods {
+		waitTime = max(waitTime, podTerminationSeconds(pod, maxTermination, ctx.MaxPodDrainTimeout))
+	}
+	timeout := secondsToDuration(waitTime, e.PodEvictionHeadroom)
+	for start := time.Now(); time.Now().Sub(start) < timeout; time.Sleep(5 * time.Second) {
 		allGone = true
 		for _, pod := range pods {
 			podReturned, err := ctx.ClientSet.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
@@ -172,21 +180,21 @@ func (e Evictor) waitPodsToDisappear(ctx *acontext.AutoscalingContext, node *api
 func (e Evictor) initiateEviction(ctx *acontext.AutoscalingContext, node *apiv1.Node, fullEvictionPods, bestEffortEvictionPods []*apiv1.Pod, evictionResults map[string]status.PodEvictionResult,
 	maxTermination int64) (map[string]status.PodEvictionResult, error) {
 
-	retryUntil := time.Now().Add(ctx.MaxPodEvictionTime)
 	fullEvictionConfirmations := make(chan status.PodEvictionResult, len(fullEvictionPods))
 	bestEffortEvictionConfirmations := make(chan status.PodEvictionResult, len(bestEffortEvictionPods))
 
+	pacer := newEvictionPacer(ctx.MaxConcurrentEvictionsPerNode, ctx.PodEvictionInterval)
 	for _, pod := range fullEvictionPods {
 		evictionResults[pod.Name] = status.PodEvictionResult{Pod: pod, TimedOut: true, Err: nil}
-		go func(pod *apiv1.Pod) {
-			fullEvictionConfirmations <- e.evictPod(ctx, pod, retryUntil, maxTermination, true)
-		}(pod)
+		pacer.start(func() {
+			fullEvictionConfirmations <- e.evictPod(ctx, pod, evictionRetryUntil(ctx, pod), maxTermination, true)
+		})
 	}
 
 	for _, pod := range bestEffortEvictionPods {
-		go func(pod *apiv1.Pod) {
-			bestEffortEvictionConfirmations <- e.evictPod(ctx, pod, retryUntil, maxTermination, false)
-		}(pod)
+		pacer.start(func() {
+			bestEffortEvictionConfirmations <- e.evictPod(ctx, pod, evictionRetryUntil(ctx, pod), maxTermination, false)
+		})
 	}
 
 	for i := 0; i < len(fullEvictionPods)+len(bestEffortEvictionPods); i++ {
@@ -218,13 +226,7 @@ func (e Evictor) initiateEviction(ctx *acontext.AutoscalingContext, node *apiv1.
 func (e Evictor) evictPod(ctx *acontext.AutoscalingContext, podToEvict *apiv1.Pod, retryUntil time.Time, maxTermination int64, fullEvictionPod bool) status.PodEvictionResult {
 	ctx.Recorder.Eventf(podToEvict, apiv1.EventTypeNormal, "ScaleDown", "deleting pod for node scale down")
 
-	termination := int64(apiv1.DefaultTerminationGracePeriodSeconds)
-	if podToEvict.Spec.TerminationGracePeriodSeconds != nil {
-		termination = *podToEvict.Spec.TerminationGracePeriodSeconds
-	}
-	if maxTermination > 0 && termination > maxTermination {
-		termination = maxTermination
-	}
+	termination := podTerminationSeconds(podToEvict, maxTermination, ctx.MaxPodDrainTimeout)
 
 	var lastError error
 	for first := true; first || time.Now().Before(retryUntil); time.Sleep(e.EvictionRetryTime) {
@@ -253,17 +255,115 @@ func (e Evictor) evictPod(ctx *acontext.AutoscalingContext, podToEvict *apiv1.Po
 	return status.PodEvictionResult{Pod: podToEvict, TimedOut: true, Err: fmt.Errorf("failed to evict pod %s/%s within allowed timeout (last error: %v)", podToEvict.Namespace, podToEvict.Name, lastError)}
 }
 
-func podsToEvict(nodeInfo *framework.NodeInfo, evictDsByDefault bool) (dsPods, nonDsPods []*apiv1.Pod) {
+// evictionPacer limits how many evictions of a node are in flight at once and
+// how often they are started.
+type evictionPacer struct {
+	inFlight chan struct{}
+	interval time.Duration
+	started  bool
+}
+
+// newEvictionPacer returns an evictionPacer running at most maxConcurrent
+// evictions at once and starting them at least interval apart. A
+// non-positive maxConcurrent doesn't limit the evictions in flight.
+func newEvictionPacer(maxConcurrent int, interval time.Duration) *evictionPacer {
+	p := &evictionPacer{interval: interval}
+	if maxConcurrent > 0 {
+		p.inFlight = make(chan struct{}, maxConcurrent)
+	}
+	return p
+}
+
+// start runs evict in a new goroutine, blocking until the eviction is allowed to start.
+func (p *evictionPacer) start(evict func()) {
+	if p.started && p.interval > 0 {
+		time.Sleep(p.interval)
+	}
+	p.started = true
+	if p.inFlight != nil {
+		p.inFlight <- struct{}{}
+	}
+	go func() {
+		evict()
+		if p.inFlight != nil {
+			<-p.inFlight
+		}
+	}()
+}
+
+// podTerminationSeconds returns the grace period the pod is evicted with: its termination grace period,
+// capped by its drain timeout annotation if set, by maxTermination otherwise. 0 maxTermination means no cap.
+// The drain timeout annotation is capped by maxDrainTimeout, and ignored if maxDrainTimeout is 0.
+func podTerminationSeconds(pod *apiv1.Pod, maxTermination int64, maxDrainTimeout time.Duration) int64 {
+	termination := int64(apiv1.DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		termination = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	if timeout, found := drain.PodDrainTimeout(pod); found && maxDrainTimeout > 0 {
+		maxTermination = int64(min(timeout, maxDrainTimeout).Seconds())
+	}
+	if maxTermination > 0 && termination > maxTermination {
+		termination = maxTermination
+	}
+	return termination
+}
+
+// secondsToDuration returns the given number of seconds plus headroom as a duration, saturating
+// instead of overflowing for grace periods too long to be represented.
+func secondsToDuration(seconds int64, headroom time.Duration) time.Duration {
+	if maxSeconds := int64((math.MaxInt64 - headroom) / time.Second); seconds > maxSeconds {
+		return math.MaxInt64
+	}
+	return time.Duration(seconds)*time.Second + headroom
+}
+
+// evictionRetryUntil returns the time until which the eviction of the pod is retried. When PodDisruptionBudgets
+// covering DaemonSet pods are respected, their evictions are retried until the DaemonSet PDB timeout instead.
+func evictionRetryUntil(ctx *acontext.AutoscalingContext, pod *apiv1.Pod) time.Time {
+	if ctx.DaemonSetPdbTimeout > 0 && pod_util.IsDaemonSetPod(pod) {
+		return time.Now().Add(ctx.DaemonSetPdbTimeout)
+	}
+	return time.Now().Add(ctx.MaxPodEvictionTime)
+}
+
+// daemonSetPdbTracker returns a tracker of the PodDisruptionBudgets in the cluster if the ones
+// covering DaemonSet pods should be respected, nil otherwise.
+func daemonSetPdbTracker(ctx *acontext.AutoscalingContext) pdb.RemainingPdbTracker {
+	if ctx.DaemonSetPdbTimeout <= 0 {
+		return nil
+	}
+	pdbs, err := ctx.PodDisruptionBudgetLister().List()
+	if err != nil {
+		klog.Warningf("Failed to list PodDisruptionBudgets, ignoring them for DaemonSet pods: %v", err)
+		return nil
+	}
+	tracker := pdb.NewBasicRemainingPdbTracker()
+	if err := tracker.SetPdbs(pdbs); err != nil {
+		klog.Warningf("Failed to parse PodDisruptionBudgets, ignoring them for DaemonSet pods: %v", err)
+		return nil
+	}
+	return tracker
+}
+
+// podsToEvict returns the DaemonSet and the other pods of the node that should be evicted. DaemonSet pods
+// covered by a PodDisruptionBudget of the dsPdbTracker are evicted unless they opt out with an annotation.
+func podsToEvict(nodeInfo *framework.NodeInfo, evictDsByDefault bool, dsPdbTracker pdb.RemainingPdbTracker) (dsPods, nonDsPods []*apiv1.Pod) {
+	var dsPodsWithPdbs []*apiv1.Pod
 	for _, podInfo := range nodeInfo.Pods {
-		if pod_util.IsMirrorPod(podInfo.Pod) {
+		if pod_util.IsMirrorPod(podInfo.Pod) || pod_util.IsHeadroomPlaceholderPod(podInfo.Pod) {
 			continue
 		} else if pod_util.IsDaemonSetPod(podInfo.Pod) {
-			dsPods = append(dsPods, podInfo.Pod)
+			if dsPdbTracker != nil && len(dsPdbTracker.MatchingPdbs(podInfo.Pod)) > 0 {
+				dsPodsWithPdbs = append(dsPodsWithPdbs, podInfo.Pod)
+			} else {
+				dsPods = append(dsPods, podInfo.Pod)
+			}
 		} else {
 			nonDsPods = append(nonDsPods, podInfo.Pod)
 		}
 	}
 	dsPodsToEvict := daemonset.PodsToEvict(dsPods, evictDsByDefault)
+	dsPodsToEvict = append(dsPodsToEvict, daemonset.PodsToEvict(dsPodsWithPdbs, true)...)
 	return dsPodsToEvict, nonDsPods
 }
 
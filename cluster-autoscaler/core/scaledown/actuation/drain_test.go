@@ -18,6 +18,7 @@ package actuation
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"testing"
@@ -27,6 +28,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,10 +36,12 @@ import (
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	acontext "k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
 	"k8s.io/autoscaler/cluster-autoscaler/core/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/daemonset"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/client-go/kubernetes/fake"
@@ -298,6 +302,96 @@ func TestDrainNodeWithPods(t *testing.T) {
 	assert.Equal(t, p2.Name, deleted[2])
 }
 
+// inFlightEvictionRegister tracks the maximum number of evictions registering at once.
+type inFlightEvictionRegister struct {
+	sync.Mutex
+	inFlight    int
+	maxInFlight int
+	starts      []time.Time
+}
+
+func (r *inFlightEvictionRegister) RegisterEviction(_ *apiv1.Pod) {
+	r.Lock()
+	r.inFlight++
+	r.maxInFlight = max(r.maxInFlight, r.inFlight)
+	r.starts = append(r.starts, time.Now())
+	r.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	r.Lock()
+	r.inFlight--
+	r.Unlock()
+}
+
+func TestDrainNodeWithPodsPaced(t *testing.T) {
+	testCases := map[string]struct {
+		maxConcurrentEvictions int
+		podEvictionInterval    time.Duration
+	}{
+		"unlimited": {},
+		"limited concurrency": {
+			maxConcurrentEvictions: 2,
+		},
+		"paced": {
+			podEvictionInterval: 30 * time.Millisecond,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			fakeClient := &fake.Clientset{}
+			n1 := BuildTestNode("n1", 1000, 1000)
+			SetNodeReadyState(n1, true, time.Time{})
+			var pods []*apiv1.Pod
+			for i := 0; i < 6; i++ {
+				pods = append(pods, BuildTestPod(fmt.Sprintf("p%d", i), 100, 0, WithNodeName(n1.Name)))
+			}
+
+			fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.NewNotFound(apiv1.Resource("pod"), "whatever")
+			})
+			fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+
+			options := config.AutoscalingOptions{
+				MaxGracefulTerminationSec:     20,
+				MaxPodEvictionTime:            5 * time.Second,
+				MaxConcurrentEvictionsPerNode: tc.maxConcurrentEvictions,
+				PodEvictionInterval:           tc.podEvictionInterval,
+			}
+			ctx, err := NewScaleTestAutoscalingContext(options, fakeClient, nil, nil, nil, nil)
+			assert.NoError(t, err)
+
+			register := &inFlightEvictionRegister{}
+			evictor := Evictor{
+				EvictionRetryTime:                0,
+				PodEvictionHeadroom:              DefaultPodEvictionHeadroom,
+				evictionRegister:                 register,
+				shutdownGracePeriodByPodPriority: SingleRuleDrainConfig(ctx.MaxGracefulTerminationSec),
+			}
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, ctx.ClusterSnapshot, []*apiv1.Node{n1}, pods)
+			nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(n1.Name)
+			assert.NoError(t, err)
+			_, err = evictor.DrainNode(&ctx, nodeInfo)
+			assert.NoError(t, err)
+
+			assert.Len(t, register.starts, len(pods))
+			if tc.maxConcurrentEvictions > 0 {
+				assert.LessOrEqual(t, register.maxInFlight, tc.maxConcurrentEvictions)
+			} else if tc.podEvictionInterval == 0 {
+				assert.Greater(t, register.maxInFlight, 1)
+			}
+			if tc.podEvictionInterval > 0 {
+				sort.Slice(register.starts, func(i, j int) bool { return register.starts[i].Before(register.starts[j]) })
+				for i := 1; i < len(register.starts); i++ {
+					assert.GreaterOrEqual(t, register.starts[i].Sub(register.starts[i-1]), tc.podEvictionInterval/2)
+				}
+			}
+		})
+	}
+}
+
 func TestDrainNodeWithPodsWithRescheduled(t *testing.T) {
 	deletedPods := make(chan string, 10)
 	fakeClient := &fake.Clientset{}
@@ -634,6 +728,7 @@ func TestPodsToEvict(t *testing.T) {
 		pods               []*apiv1.Pod
 		nodeNameOverwrite  string
 		dsEvictionDisabled bool
+		dsPdbs             []*policyv1.PodDisruptionBudget
 		wantDsPods         []*apiv1.Pod
 		wantNonDsPods      []*apiv1.Pod
 	}{
@@ -669,6 +764,13 @@ func TestPodsToEvict(t *testing.T) {
 			wantDsPods:         []*apiv1.Pod{dsPod("pod-1", true), dsPod("pod-3", true)},
 			wantNonDsPods:      []*apiv1.Pod{},
 		},
+		"DS pods covered by PDBs are returned when DS eviction is disabled, unless the pods are marked as not evictable": {
+			dsEvictionDisabled: true,
+			dsPdbs:             []*policyv1.PodDisruptionBudget{agentPdb()},
+			pods:               []*apiv1.Pod{agentDsPod("pod-1", nil), dsPod("pod-2", false), agentDsPod("pod-3", map[string]string{daemonset.EnableDsEvictionKey: "false"})},
+			wantDsPods:         []*apiv1.Pod{agentDsPod("pod-1", nil)},
+			wantNonDsPods:      []*apiv1.Pod{},
+		},
 		"all pod kinds are correctly handled together": {
 			pods: []*apiv1.Pod{
 				dsPod("ds-pod-1", false), dsPod("ds-pod-2", false),
@@ -700,7 +802,12 @@ func TestPodsToEvict(t *testing.T) {
 			if err != nil {
 				t.Fatalf("NodeInfos().Get() unexpected error: %v", err)
 			}
-			gotDsPods, gotNonDsPods := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForOccupiedNodes)
+			var dsPdbTracker pdb.RemainingPdbTracker
+			if tc.dsPdbs != nil {
+				dsPdbTracker = pdb.NewBasicRemainingPdbTracker()
+				assert.NoError(t, dsPdbTracker.SetPdbs(tc.dsPdbs))
+			}
+			gotDsPods, gotNonDsPods := podsToEvict(nodeInfo, ctx.DaemonSetEvictionForOccupiedNodes, dsPdbTracker)
 			if diff := cmp.Diff(tc.wantDsPods, gotDsPods, cmpopts.EquateEmpty()); diff != "" {
 				t.Errorf("podsToEvict dsPods diff (-want +got):\n%s", diff)
 			}
@@ -711,6 +818,82 @@ func TestPodsToEvict(t *testing.T) {
 	}
 }
 
+func TestPodTerminationSeconds(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		gracePeriod     int64
+		drainTimeout    string
+		maxTermination  int64
+		maxDrainTimeout time.Duration
+		want            int64
+	}{
+		"default grace period": {
+			maxTermination: 600,
+			want:           apiv1.DefaultTerminationGracePeriodSeconds,
+		},
+		"grace period capped by max termination": {
+			gracePeriod:    3600,
+			maxTermination: 600,
+			want:           600,
+		},
+		"grace period not capped without max termination": {
+			gracePeriod: 3600,
+			want:        3600,
+		},
+		"grace period capped by drain timeout instead of max termination": {
+			gracePeriod:     3600,
+			drainTimeout:    "30m",
+			maxTermination:  600,
+			maxDrainTimeout: time.Hour,
+			want:            1800,
+		},
+		"drain timeout longer than grace period": {
+			gracePeriod:     900,
+			drainTimeout:    "1h",
+			maxTermination:  600,
+			maxDrainTimeout: time.Hour,
+			want:            900,
+		},
+		"invalid drain timeout": {
+			gracePeriod:     3600,
+			drainTimeout:    "forever",
+			maxTermination:  600,
+			maxDrainTimeout: time.Hour,
+			want:            600,
+		},
+		"huge drain timeout capped by max drain timeout": {
+			gracePeriod:     math.MaxInt64,
+			drainTimeout:    "2562047h",
+			maxTermination:  600,
+			maxDrainTimeout: 2 * time.Hour,
+			want:            7200,
+		},
+		"drain timeout ignored without max drain timeout": {
+			gracePeriod:    3600,
+			drainTimeout:   "30m",
+			maxTermination: 600,
+			want:           600,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			pod := regularPod("pod")
+			if tc.gracePeriod > 0 {
+				pod.Spec.TerminationGracePeriodSeconds = &tc.gracePeriod
+			}
+			if tc.drainTimeout != "" {
+				pod.Annotations = map[string]string{drain.PodDrainTimeoutKey: tc.drainTimeout}
+			}
+			assert.Equal(t, tc.want, podTerminationSeconds(pod, tc.maxTermination, tc.maxDrainTimeout))
+		})
+	}
+}
+
+func TestSecondsToDuration(t *testing.T) {
+	assert.Equal(t, 630*time.Second, secondsToDuration(600, 30*time.Second))
+	// Huge grace periods, e.g. ones that aren't capped, don't overflow into a negative timeout.
+	assert.Equal(t, time.Duration(math.MaxInt64), secondsToDuration(math.MaxInt64, 30*time.Second))
+	assert.Equal(t, time.Duration(math.MaxInt64), secondsToDuration(int64(math.MaxInt64/time.Second), 30*time.Second))
+}
+
 func regularPod(name string) *apiv1.Pod {
 	return &apiv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -743,6 +926,21 @@ func dsPod(name string, evictable bool) *apiv1.Pod {
 	return pod
 }
 
+func agentDsPod(name string, annotations map[string]string) *apiv1.Pod {
+	pod := dsPod(name, false)
+	pod.Labels = map[string]string{"app": "agent"}
+	pod.Annotations = annotations
+	return pod
+}
+
+func agentPdb() *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+		},
+	}
+}
+
 type evRegister struct {
 	sync.Mutex
 	pods []*apiv1.Pod
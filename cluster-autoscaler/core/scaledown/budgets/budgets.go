@@ -39,12 +39,16 @@ type NodeGroupView struct {
 // ScaleDownBudgetProcessor is responsible for keeping the number of nodes deleted in parallel within defined limits.
 type ScaleDownBudgetProcessor struct {
 	ctx *context.AutoscalingContext
+	// deletionZones are the zones of the nodes whose deletion was allowed, by node name, used to count
+	// the deletions in progress in each zone.
+	deletionZones map[string]string
 }
 
 // NewScaleDownBudgetProcessor creates a ScaleDownBudgetProcessor instance.
 func NewScaleDownBudgetProcessor(ctx *context.AutoscalingContext) *ScaleDownBudgetProcessor {
 	return &ScaleDownBudgetProcessor{
-		ctx: ctx,
+		ctx:           ctx,
+		deletionZones: make(map[string]string),
 	}
 }
 
@@ -144,9 +148,69 @@ func (bp *ScaleDownBudgetProcessor) CropNodes(as scaledown.ActuationStatus, empt
 
 	drainToDelete, _ = cropIndividualNodes(drainToDelete, drainIndividual, drainBudget)
 
+	if bp.ctx.MaxConcurrentDeletionsPerZone > 0 {
+		atomic := map[string]bool{}
+		for id := range emptyAtomicMap {
+			atomic[id] = true
+		}
+		for id := range drainAtomicMap {
+			atomic[id] = true
+		}
+		emptyToDelete, drainToDelete = bp.cropToZoneBudgets(as, atomic, emptyToDelete, drainToDelete)
+	}
+
 	return emptyToDelete, drainToDelete
 }
 
+// cropToZoneBudgets crops the nodes so that at most MaxConcurrentDeletionsPerZone nodes of each zone, including the
+// deletions in progress, are drained and deleted concurrently. Node groups using atomic scaling can't be partially
+// scaled down, so they aren't cropped, but their nodes count towards the limit. Nodes without a zone aren't limited.
+func (bp *ScaleDownBudgetProcessor) cropToZoneBudgets(as scaledown.ActuationStatus, atomic map[string]bool, empty, drain []*NodeGroupView) (emptyToDelete, drainToDelete []*NodeGroupView) {
+	emptyInProgress, drainInProgress := as.DeletionsInProgress()
+	inProgress := map[string]int{}
+	deletionZones := make(map[string]string)
+	for _, name := range append(emptyInProgress, drainInProgress...) {
+		if zone, found := bp.deletionZones[name]; found {
+			deletionZones[name] = zone
+			inProgress[zone]++
+		}
+	}
+	bp.deletionZones = deletionZones
+
+	crop := func(views []*NodeGroupView) []*NodeGroupView {
+		cropped := []*NodeGroupView{}
+		for _, view := range views {
+			var nodes []*apiv1.Node
+			for _, node := range view.Nodes {
+				zone := nodeZone(node)
+				if zone != "" && !atomic[view.Group.Id()] && inProgress[zone] >= bp.ctx.MaxConcurrentDeletionsPerZone {
+					klog.V(4).Infof("Skipping deletion of %s, %d nodes of zone %s are already being deleted", node.Name, inProgress[zone], zone)
+					continue
+				}
+				if zone != "" {
+					inProgress[zone]++
+					bp.deletionZones[node.Name] = zone
+				}
+				nodes = append(nodes, node)
+			}
+			if len(nodes) > 0 {
+				view.Nodes = nodes
+				cropped = append(cropped, view)
+			}
+		}
+		return cropped
+	}
+	return crop(empty), crop(drain)
+}
+
+// nodeZone returns the zone of the node, as set by the zone label, or an empty string if it has none.
+func nodeZone(node *apiv1.Node) string {
+	if zone, found := node.Labels[apiv1.LabelTopologyZone]; found {
+		return zone
+	}
+	return node.Labels[apiv1.LabelFailureDomainBetaZone]
+}
+
 func groupBuckets(buckets []*NodeGroupView) map[string]*NodeGroupView {
 	grouped := map[string]*NodeGroupView{}
 	for _, bucket := range buckets {
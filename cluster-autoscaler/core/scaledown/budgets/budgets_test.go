@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/deletiontracker"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
 )
 
 func TestCropNodesToBudgets(t *testing.T) {
@@ -518,3 +520,73 @@ func generateNode(name string) *apiv1.Node {
 		},
 	}
 }
+
+func TestCropNodesToZoneBudgets(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng", 0, 100, 10)
+	atomic := sizedNodeGroup("atomic", 2, true)
+	atomic.(*testprovider.TestNodeGroup).SetCloudProvider(provider)
+	provider.InsertNodeGroup(atomic)
+	ng := provider.GetNodeGroup("ng")
+	zonedNode := func(name, zone string, nodeGroup cloudprovider.NodeGroup) *apiv1.Node {
+		node := generateNode(name)
+		if zone != "" {
+			node.Labels = map[string]string{apiv1.LabelTopologyZone: zone}
+		}
+		provider.AddNode(nodeGroup.Id(), node)
+		return node
+	}
+	a0, a1, a2, a3 := zonedNode("a-0", "zone-a", ng), zonedNode("a-1", "zone-a", ng), zonedNode("a-2", "zone-a", ng), zonedNode("a-3", "zone-a", ng)
+	b0, b1 := zonedNode("b-0", "zone-b", ng), zonedNode("b-1", "zone-b", ng)
+	noZone := zonedNode("no-zone", "", ng)
+	atomic0, atomic1 := zonedNode("atomic-0", "zone-a", atomic), zonedNode("atomic-1", "zone-a", atomic)
+
+	ctx := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			MaxScaleDownParallelism:       10,
+			MaxDrainParallelism:           5,
+			MaxConcurrentDeletionsPerZone: 2,
+		},
+		CloudProvider: provider,
+	}
+	ndt := deletiontracker.NewNodeDeletionTracker(1 * time.Hour)
+	budgeter := NewScaleDownBudgetProcessor(ctx)
+	cropNodes := func(empty, drain []*apiv1.Node) (gotEmpty, gotDrain []*apiv1.Node) {
+		emptyToDelete, drainToDelete := budgeter.CropNodes(ndt, empty, drain)
+		for _, view := range emptyToDelete {
+			for _, node := range view.Nodes {
+				ndt.StartDeletion(view.Group.Id(), node.Name)
+				gotEmpty = append(gotEmpty, node)
+			}
+		}
+		for _, view := range drainToDelete {
+			for _, node := range view.Nodes {
+				ndt.StartDeletionWithDrain(view.Group.Id(), node.Name)
+				gotDrain = append(gotDrain, node)
+			}
+		}
+		return gotEmpty, gotDrain
+	}
+
+	gotEmpty, gotDrain := cropNodes([]*apiv1.Node{a0, a1, a2}, []*apiv1.Node{b0, noZone})
+	assert.Equal(t, []*apiv1.Node{a0, a1}, gotEmpty)
+	assert.Equal(t, []*apiv1.Node{b0, noZone}, gotDrain)
+
+	// The deletions in progress count towards the limit, whether the nodes are drained or not.
+	gotEmpty, gotDrain = cropNodes([]*apiv1.Node{a2}, []*apiv1.Node{a3, b1})
+	assert.Empty(t, gotEmpty)
+	assert.Equal(t, []*apiv1.Node{b1}, gotDrain)
+
+	// Nodes of node groups using atomic scaling aren't cropped.
+	gotEmpty, gotDrain = cropNodes([]*apiv1.Node{atomic0, atomic1}, nil)
+	assert.Equal(t, []*apiv1.Node{atomic0, atomic1}, gotEmpty)
+	assert.Empty(t, gotDrain)
+
+	// Nodes of the zone are deleted again once the deletions in progress finish.
+	ndt.EndDeletion(ng.Id(), a0.Name, status.NodeDeleteResult{ResultType: status.NodeDeleteOk})
+	ndt.EndDeletion(ng.Id(), a1.Name, status.NodeDeleteResult{ResultType: status.NodeDeleteOk})
+	ndt.EndDeletion(atomic.Id(), atomic0.Name, status.NodeDeleteResult{ResultType: status.NodeDeleteOk})
+	gotEmpty, gotDrain = cropNodes([]*apiv1.Node{a2}, []*apiv1.Node{a3})
+	assert.Equal(t, []*apiv1.Node{a2}, gotEmpty)
+	assert.Empty(t, gotDrain)
+}
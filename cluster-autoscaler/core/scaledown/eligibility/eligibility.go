@@ -37,6 +37,9 @@ import (
 const (
 	// ScaleDownDisabledKey is the name of annotation marking node as not eligible for scale down.
 	ScaleDownDisabledKey = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+	// ScaleDownDeferKey is the name of annotation holding the minimum time an empty node has to be unneeded
+	// before it is scaled down, e.g. to keep nodes with warm local caches around for longer.
+	ScaleDownDeferKey = "cluster-autoscaler.kubernetes.io/scale-down-defer"
 )
 
 // Checker is responsible for deciding which nodes pass the criteria for scale down.
@@ -194,3 +197,18 @@ func (c *Checker) isNodeBelowUtilizationThreshold(context *context.AutoscalingCo
 func HasNoScaleDownAnnotation(node *apiv1.Node) bool {
 	return node.Annotations[ScaleDownDisabledKey] == "true"
 }
+
+// ScaleDownDeferTime returns the time the node asks to be kept after becoming empty and unneeded,
+// set with the scale-down defer annotation. Invalid values are ignored.
+func ScaleDownDeferTime(node *apiv1.Node) (time.Duration, bool) {
+	value, found := node.Annotations[ScaleDownDeferKey]
+	if !found {
+		return 0, false
+	}
+	deferTime, err := time.ParseDuration(value)
+	if err != nil || deferTime < 0 {
+		klog.Warningf("Ignoring invalid %s annotation %q on node %s", ScaleDownDeferKey, value, node.Name)
+		return 0, false
+	}
+	return deferTime, true
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/deletiontracker"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/eligibility"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/simulationbudget"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unneeded"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unremovable"
@@ -54,6 +55,7 @@ type ScaleDown struct {
 	removalSimulator     *simulator.RemovalSimulator
 	eligibilityChecker   *eligibility.Checker
 	resourceLimitsFinder *resource.LimitsFinder
+	simulationBudget     *simulationbudget.Controller
 }
 
 // NewScaleDown builds new ScaleDown object.
@@ -73,6 +75,7 @@ func NewScaleDown(context *context.AutoscalingContext, processors *processors.Au
 		removalSimulator:     removalSimulator,
 		eligibilityChecker:   eligibility.NewChecker(processors.NodeGroupConfigProcessor),
 		resourceLimitsFinder: resourceLimitsFinder,
+		simulationBudget:     simulationbudget.NewController(context.ScaleDownSimulationTargetDuration, context.ScaleDownCandidatesPoolMinCount),
 	}
 }
 
@@ -145,6 +148,7 @@ func (sd *ScaleDown) UpdateUnneededNodes(
 	}
 
 	// Look for nodes to remove in the current candidates
+	simulationStart := time.Now()
 	nodesToRemove, unremovable := sd.removalSimulator.FindNodesToRemove(
 		currentCandidates,
 		destinations,
@@ -160,10 +164,19 @@ func (sd *ScaleDown) UpdateUnneededNodes(
 	if additionalCandidatesPoolSize < sd.context.ScaleDownCandidatesPoolMinCount {
 		additionalCandidatesPoolSize = sd.context.ScaleDownCandidatesPoolMinCount
 	}
+	if sd.simulationBudget.Enabled() {
+		// The budget covers the current candidates too, they're always simulated.
+		additionalCandidatesPoolSize = sd.simulationBudget.Budget() - len(currentCandidates)
+		if additionalCandidatesPoolSize < 0 {
+			additionalCandidatesPoolSize = 0
+		}
+	}
 	if additionalCandidatesPoolSize > len(currentNonCandidates) {
 		additionalCandidatesPoolSize = len(currentNonCandidates)
 	}
+	simulatedCount := len(currentCandidates)
 	if additionalCandidatesCount > 0 {
+		simulatedCount += additionalCandidatesPoolSize
 		// Look for additional nodes to remove among the rest of nodes.
 		klog.V(3).Infof("Finding additional %v candidates for scale down.", additionalCandidatesCount)
 		additionalNodesToRemove, additionalUnremovable :=
@@ -178,6 +191,7 @@ func (sd *ScaleDown) UpdateUnneededNodes(
 		nodesToRemove = append(nodesToRemove, additionalNodesToRemove...)
 		unremovable = append(unremovable, additionalUnremovable...)
 	}
+	sd.simulationBudget.Update(simulatedCount, time.Since(simulationStart))
 
 	for _, empty := range emptyNodesToRemove {
 		nodesToRemove = append(nodesToRemove, simulator.NodeToBeRemoved{Node: empty.Node, PodsToReschedule: []*apiv1.Pod{}})
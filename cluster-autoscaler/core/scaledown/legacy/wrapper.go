@@ -62,6 +62,16 @@ func (p *ScaleDownWrapper) UnneededNodes() []*apiv1.Node {
 	return p.sd.UnneededNodes()
 }
 
+// UnneededSince returns the times since which nodes are unneeded.
+func (p *ScaleDownWrapper) UnneededSince() map[string]time.Time {
+	return p.sd.unneededNodes.UnneededSince()
+}
+
+// RestoreUnneededSince restores the times since which nodes were unneeded.
+func (p *ScaleDownWrapper) RestoreUnneededSince(since map[string]time.Time) {
+	p.sd.unneededNodes.RestoreUnneededSince(since)
+}
+
 // UnremovableNodes returns a list of nodes that cannot be removed.
 func (p *ScaleDownWrapper) UnremovableNodes() []*simulator.UnremovableNode {
 	return p.sd.UnremovableNodes()
@@ -79,6 +79,9 @@ func (t *basicRemainingPdbTracker) CanRemovePods(pods []*apiv1.Pod) (canRemove,
 		count := int32(0)
 		for _, pod := range pods {
 			if pod.Namespace == pdbInfo.pdb.Namespace && pdbInfo.selector.Matches(labels.Set(pod.Labels)) {
+				if drain.IsUnhealthyPodEvictionAllowed(pod, pdbInfo.pdb) {
+					continue
+				}
 				count += 1
 				if pdbInfo.pdb.Status.DisruptionsAllowed < 1 {
 					return false, false, &drain.BlockingPod{Pod: pod, Reason: drain.NotEnoughPdb}
@@ -96,7 +99,8 @@ func (t *basicRemainingPdbTracker) CanRemovePods(pods []*apiv1.Pod) (canRemove,
 func (t *basicRemainingPdbTracker) RemovePods(pods []*apiv1.Pod) {
 	for _, pdbInfo := range t.pdbInfos {
 		for _, pod := range pods {
-			if pod.Namespace == pdbInfo.pdb.Namespace && pdbInfo.selector.Matches(labels.Set(pod.Labels)) {
+			if pod.Namespace == pdbInfo.pdb.Namespace && pdbInfo.selector.Matches(labels.Set(pod.Labels)) &&
+				!drain.IsUnhealthyPodEvictionAllowed(pod, pdbInfo.pdb) {
 				pdbInfo.pdb.Status.DisruptionsAllowed -= 1
 			}
 		}
@@ -239,6 +239,30 @@ func TestBasicRemovePods(t *testing.T) {
 	}
 }
 
+func TestBasicUnhealthyPodEvictionPolicy(t *testing.T) {
+	alwaysAllow := policyv1.AlwaysAllow
+	pdb := pdb1.DeepCopy()
+	pdb.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
+	pdb.Status.DisruptionsAllowed = 0
+	tracker := NewBasicRemainingPdbTracker()
+	assert.NoError(t, tracker.SetPdbs([]*policyv1.PodDisruptionBudget{pdb}))
+
+	pods := makePodsWithLabel(label1, 2)
+	for _, pod := range pods {
+		pod.Status.Conditions = []apiv1.PodCondition{{Type: apiv1.PodReady, Status: apiv1.ConditionFalse}}
+	}
+	canRemove, inParallel, _ := tracker.CanRemovePods(pods)
+	assert.True(t, canRemove)
+	assert.True(t, inParallel)
+	// Evicting unhealthy pods doesn't use the disruptions allowed by the pdb.
+	tracker.RemovePods(pods)
+	assert.Equal(t, int32(0), tracker.GetPdbs()[0].Status.DisruptionsAllowed)
+
+	pods[0].Status.Conditions[0].Status = apiv1.ConditionTrue
+	canRemove, _, _ = tracker.CanRemovePods(pods)
+	assert.False(t, canRemove)
+}
+
 func makePodsWithLabel(label string, amount int) []*apiv1.Pod {
 	pods := []*apiv1.Pod{}
 	for i := 0; i < amount; i++ {
@@ -29,6 +29,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/eligibility"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/simulationbudget"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unneeded"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unremovable"
 	"k8s.io/autoscaler/cluster-autoscaler/processors"
@@ -77,6 +78,7 @@ type Planner struct {
 	resourceLimitsFinder  *resource.LimitsFinder
 	cc                    controllerReplicasCalculator
 	scaleDownSetProcessor nodes.ScaleDownSetProcessor
+	simulationBudget      *simulationbudget.Controller
 }
 
 // New creates a new Planner object.
@@ -98,6 +100,7 @@ func New(context *context.AutoscalingContext, processors *processors.Autoscaling
 		cc:                    newControllerReplicasCalculator(context.ListerRegistry),
 		scaleDownSetProcessor: processors.ScaleDownSetProcessor,
 		minUpdateInterval:     minUpdateInterval,
+		simulationBudget:      simulationbudget.NewController(context.ScaleDownSimulationTargetDuration, context.ScaleDownCandidatesPoolMinCount),
 	}
 }
 
@@ -192,6 +195,16 @@ func (p *Planner) UnneededNodes() []*apiv1.Node {
 	return p.unneededNodes.AsList()
 }
 
+// UnneededSince returns the times since which nodes are unneeded.
+func (p *Planner) UnneededSince() map[string]time.Time {
+	return p.unneededNodes.UnneededSince()
+}
+
+// RestoreUnneededSince restores the times since which nodes were unneeded.
+func (p *Planner) RestoreUnneededSince(since map[string]time.Time) {
+	p.unneededNodes.RestoreUnneededSince(since)
+}
+
 // UnremovableNodes returns a list of nodes currently considered as unremovable.
 func (p *Planner) UnremovableNodes() []*simulator.UnremovableNode {
 	return p.unremovableNodes.AsList()
@@ -268,8 +281,14 @@ func (p *Planner) categorizeNodes(podDestinations map[string]bool, scaleDownCand
 	for _, n := range ineligible {
 		p.unremovableNodes.Add(n)
 	}
+	// After a restart, nodes that were unneeded before are simulated first, so that they don't
+	// lose the time they've been unneeded for if not all nodes can be simulated.
+	currentlyUnneededNodeNames = p.unneededNodes.RestoredFirst(currentlyUnneededNodeNames)
 	p.nodeUtilizationMap = utilizationMap
 	timer := time.NewTimer(p.context.ScaleDownSimulationTimeout)
+	simulationStart := time.Now()
+	simulationBudget := p.simulationBudget.Budget()
+	simulatedCount := 0
 
 	for i, node := range currentlyUnneededNodeNames {
 		if timedOut(timer) {
@@ -280,6 +299,11 @@ func (p *Planner) categorizeNodes(podDestinations map[string]bool, scaleDownCand
 			klog.V(4).Infof("%d out of %d nodes skipped in scale down simulation: there are already %d unneeded nodes so no point in looking for more. Total atomic scale down nodes: %d", len(currentlyUnneededNodeNames)-i, len(currentlyUnneededNodeNames), len(removableList), atomicScaleDownNodesCount)
 			break
 		}
+		if simulatedCount >= simulationBudget {
+			klog.V(4).Infof("%d out of %d nodes skipped in scale down simulation: the simulation budget of %d nodes is used up.", len(currentlyUnneededNodeNames)-i, len(currentlyUnneededNodeNames), simulationBudget)
+			break
+		}
+		simulatedCount++
 		removable, unremovable := p.rs.SimulateNodeRemoval(node, podDestinations, p.latestUpdate, p.context.RemainingPdbTracker)
 		if removable != nil {
 			_, inParallel, _ := p.context.RemainingPdbTracker.CanRemovePods(removable.PodsToReschedule)
@@ -299,6 +323,7 @@ func (p *Planner) categorizeNodes(podDestinations map[string]bool, scaleDownCand
 			p.unremovableNodes.AddTimeout(unremovable, unremovableTimeout)
 		}
 	}
+	p.simulationBudget.Update(simulatedCount, time.Since(simulationStart))
 	p.unneededNodes.Update(removableList, p.latestUpdate)
 	if unremovableCount > 0 {
 		klog.V(1).Infof("%v nodes found to be unremovable in simulation, will re-check them at %v", unremovableCount, unremovableTimeout)
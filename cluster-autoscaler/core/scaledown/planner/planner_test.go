@@ -704,6 +704,44 @@ func TestUpdateClusterStatUnneededNodesLimit(t *testing.T) {
 	}
 }
 
+func TestUpdateClusterStateRestoredUnneededSince(t *testing.T) {
+	now := time.Now()
+	restoredSince := now.Add(-5 * time.Minute)
+	nodes := make([]*apiv1.Node, 100)
+	for i := range nodes {
+		nodes[i] = BuildTestNode(fmt.Sprintf("n%d", i), 1000, 10)
+	}
+	// The nodes that were unneeded before a restart are the last scale-down candidates.
+	restored := map[string]time.Time{}
+	for _, node := range nodes[90:] {
+		restored[node.Name] = restoredSince
+	}
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 0, 0)
+	for _, node := range nodes {
+		provider.AddNode("ng1", node)
+	}
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{
+		NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
+			ScaleDownUnneededTime: time.Minute,
+		},
+		ScaleDownSimulationTimeout: 1 * time.Hour,
+		MaxScaleDownParallelism:    5,
+	}, &fake.Clientset{}, nil, provider, nil, nil)
+	assert.NoError(t, err)
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, context.ClusterSnapshot, nodes, nil)
+	p := New(&context, NewTestProcessors(&context), options.NodeDeleteOptions{}, nil)
+	p.eligibilityChecker = &fakeEligibilityChecker{eligible: asMap(nodeNames(nodes))}
+	p.minUpdateInterval = 10 * time.Second
+	p.RestoreUnneededSince(restored)
+	assert.NoError(t, p.UpdateClusterState(nodes, nodes, &fakeActuationStatus{}, now))
+
+	// Only 10 nodes are simulated, the ones with restored times keep them.
+	unneededSince := p.UnneededSince()
+	assert.Len(t, unneededSince, 10)
+	assert.Equal(t, restored, unneededSince)
+}
+
 func TestNodesToDelete(t *testing.T) {
 	testCases := []struct {
 		name      string
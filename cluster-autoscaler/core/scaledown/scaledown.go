@@ -49,6 +49,16 @@ type Planner interface {
 	NodeUtilizationMap() map[string]utilization.Info
 }
 
+// UnneededSinceCheckpointer is implemented by Planners whose unneeded-since times of
+// nodes can be saved and restored, e.g. by a new leader.
+type UnneededSinceCheckpointer interface {
+	// UnneededSince returns the times since which nodes are unneeded.
+	UnneededSince() map[string]time.Time
+	// RestoreUnneededSince restores the times returned by UnneededSince, for
+	// the nodes that are found unneeded in the next cluster state update.
+	RestoreUnneededSince(since map[string]time.Time)
+}
+
 // Actuator is responsible for making changes in the cluster: draining and
 // deleting nodes.
 type Actuator interface {
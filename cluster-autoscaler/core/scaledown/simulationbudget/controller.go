@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulationbudget
+
+import (
+	"math"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+// Controller adapts the number of scale down candidates simulated in a loop, so
+// that the scale down simulation takes about the target duration. The budget
+// shrinks right away when a simulation takes longer than the target and grows,
+// at most twice per loop, when the whole budget was used up within the target.
+type Controller struct {
+	targetDuration time.Duration
+	minBudget      int
+	budget         int
+}
+
+// NewController returns a new Controller. The budget starts at, and never goes
+// below, minBudget. The Controller is disabled if targetDuration is not positive.
+func NewController(targetDuration time.Duration, minBudget int) *Controller {
+	if minBudget < 1 {
+		minBudget = 1
+	}
+	return &Controller{
+		targetDuration: targetDuration,
+		minBudget:      minBudget,
+		budget:         minBudget,
+	}
+}
+
+// Enabled returns whether the number of simulated candidates is limited by the Controller.
+func (c *Controller) Enabled() bool {
+	return c.targetDuration > 0
+}
+
+// Budget returns the number of candidates that can be simulated in the current loop.
+func (c *Controller) Budget() int {
+	if !c.Enabled() {
+		return math.MaxInt
+	}
+	return c.budget
+}
+
+// Update adjusts the budget after simulating the given number of candidates took the given time.
+func (c *Controller) Update(simulated int, duration time.Duration) {
+	if !c.Enabled() || simulated <= 0 {
+		return
+	}
+	perCandidate := float64(duration) / float64(simulated)
+	fitting := math.MaxInt
+	if perCandidate > 0 {
+		fitting = int(math.Min(float64(c.targetDuration)/perCandidate, math.MaxInt32))
+	}
+	oldBudget := c.budget
+	if duration > c.targetDuration {
+		c.budget = fitting
+	} else if simulated >= c.budget {
+		c.budget = int(math.Min(float64(fitting), 2*float64(c.budget)))
+	}
+	if c.budget < c.minBudget {
+		c.budget = c.minBudget
+	}
+	if c.budget != oldBudget {
+		klog.V(4).Infof("Scale down simulation of %d candidates took %v, changing the budget from %d to %d candidates", simulated, duration, oldBudget, c.budget)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulationbudget
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestController(t *testing.T) {
+	type update struct {
+		simulated  int
+		duration   time.Duration
+		wantBudget int
+	}
+	testCases := []struct {
+		name           string
+		targetDuration time.Duration
+		minBudget      int
+		updates        []update
+	}{
+		{
+			name:           "disabled",
+			targetDuration: 0,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 100, duration: time.Minute, wantBudget: math.MaxInt},
+			},
+		},
+		{
+			name:           "grows at most twice per loop when used up",
+			targetDuration: 10 * time.Second,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 10, duration: time.Second, wantBudget: 20},
+				{simulated: 20, duration: 2 * time.Second, wantBudget: 40},
+				{simulated: 40, duration: 8 * time.Second, wantBudget: 50},
+			},
+		},
+		{
+			name:           "doesn't grow when not used up",
+			targetDuration: 10 * time.Second,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 5, duration: time.Second, wantBudget: 10},
+			},
+		},
+		{
+			name:           "shrinks to fit the target",
+			targetDuration: 10 * time.Second,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 10, duration: time.Second, wantBudget: 20},
+				{simulated: 20, duration: 2 * time.Second, wantBudget: 40},
+				{simulated: 40, duration: 20 * time.Second, wantBudget: 20},
+			},
+		},
+		{
+			name:           "doesn't shrink below the minimum",
+			targetDuration: 10 * time.Second,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 10, duration: time.Minute, wantBudget: 10},
+			},
+		},
+		{
+			name:           "nothing simulated",
+			targetDuration: 10 * time.Second,
+			minBudget:      10,
+			updates: []update{
+				{simulated: 0, duration: time.Minute, wantBudget: 10},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewController(tc.targetDuration, tc.minBudget)
+			for _, u := range tc.updates {
+				c.Update(u.simulated, u.duration)
+				assert.Equal(t, u.wantBudget, c.Budget())
+			}
+		})
+	}
+}
@@ -40,6 +40,9 @@ type Nodes struct {
 	limitsFinder *resource.LimitsFinder
 	cachedList   []*apiv1.Node
 	byName       map[string]*node
+	// restoredSince are the times since which nodes were unneeded according to a previous leader,
+	// used for the nodes that are unneeded in the next update.
+	restoredSince map[string]time.Time
 }
 
 type node struct {
@@ -73,11 +76,14 @@ func (n *Nodes) Update(nodes []simulator.NodeToBeRemoved, ts time.Time) {
 		}
 		if val, found := n.byName[name]; found {
 			updated[name].since = val.since
+		} else if since, found := n.restoredSince[name]; found && since.Before(ts) {
+			updated[name].since = since
 		} else {
 			updated[name].since = ts
 		}
 	}
 	n.byName = updated
+	n.restoredSince = nil
 	n.cachedList = nil
 	if klog.V(4).Enabled() {
 		for k, v := range n.byName {
@@ -86,6 +92,45 @@ func (n *Nodes) Update(nodes []simulator.NodeToBeRemoved, ts time.Time) {
 	}
 }
 
+// UnneededSince returns the times since which the nodes are unneeded. Until the next update,
+// it includes the restored times too, so that they aren't lost if they're checkpointed again.
+func (n *Nodes) UnneededSince() map[string]time.Time {
+	since := make(map[string]time.Time, len(n.byName)+len(n.restoredSince))
+	for name, t := range n.restoredSince {
+		since[name] = t
+	}
+	for name, v := range n.byName {
+		since[name] = v.since
+	}
+	return since
+}
+
+// RestoreUnneededSince restores the times since which nodes were unneeded, e.g. according to
+// a previous leader. They're used for the nodes that are unneeded in the next update and
+// aren't tracked yet.
+func (n *Nodes) RestoreUnneededSince(since map[string]time.Time) {
+	n.restoredSince = since
+}
+
+// RestoredFirst returns the node names with the nodes that have restored unneeded times first,
+// keeping their order otherwise. Restored times are only used in the next update, so the nodes
+// they're restored for need to be simulated before the simulation budget runs out.
+func (n *Nodes) RestoredFirst(names []string) []string {
+	if len(n.restoredSince) == 0 {
+		return names
+	}
+	restored := make([]string, 0, len(names))
+	var others []string
+	for _, name := range names {
+		if _, found := n.restoredSince[name]; found {
+			restored = append(restored, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	return append(restored, others...)
+}
+
 // Clear resets the internal state, dropping information about all tracked nodes.
 func (n *Nodes) Clear() {
 	n.Update(nil, time.Time{})
@@ -170,6 +215,11 @@ func (n *Nodes) unremovableReason(context *context.AutoscalingContext, v *node,
 		if !v.since.Add(unneededTime).Before(ts) {
 			return simulator.NotUnneededLongEnough
 		}
+		// Empty nodes holding warm local state may ask to be kept for longer.
+		if deferTime, found := eligibility.ScaleDownDeferTime(node); found && len(v.ntbr.PodsToReschedule) == 0 && !v.since.Add(deferTime).Before(ts) {
+			klog.V(4).Infof("Skipping %s - scale down deferred for %s by annotation", node.Name, deferTime)
+			return simulator.ScaleDownDeferred
+		}
 	} else {
 		// Unready nodes may be deleted after a different time than underutilized nodes.
 		unreadyTime, err := n.sdtg.GetScaleDownUnreadyTime(nodeGroup)
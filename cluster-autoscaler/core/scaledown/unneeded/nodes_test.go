@@ -25,6 +25,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/eligibility"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
 	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
@@ -114,6 +115,57 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestRestoreUnneededSince(t *testing.T) {
+	restoredTimestamp := time.Now()
+	initialTimestamp := restoredTimestamp.Add(time.Minute)
+	finalTimestamp := initialTimestamp.Add(time.Minute)
+
+	nodes := NewNodes(nil, nil)
+	nodes.Update([]simulator.NodeToBeRemoved{makeNode("n1", "v1")}, restoredTimestamp.Add(-time.Minute))
+	nodes.RestoreUnneededSince(map[string]time.Time{
+		"n1": restoredTimestamp,
+		"n2": restoredTimestamp,
+		"n3": initialTimestamp.Add(time.Hour),
+		"n4": restoredTimestamp,
+	})
+	// The restored times are checkpointed again until they're used.
+	assert.Equal(t, map[string]time.Time{
+		"n1": restoredTimestamp.Add(-time.Minute),
+		"n2": restoredTimestamp,
+		"n3": initialTimestamp.Add(time.Hour),
+		"n4": restoredTimestamp,
+	}, nodes.UnneededSince())
+	nodes.Update([]simulator.NodeToBeRemoved{makeNode("n1", "v1"), makeNode("n2", "v1"), makeNode("n3", "v1")}, initialTimestamp)
+	// The restored times are only used in the first update.
+	nodes.Update([]simulator.NodeToBeRemoved{makeNode("n1", "v1"), makeNode("n2", "v1"), makeNode("n3", "v1"), makeNode("n4", "v1")}, finalTimestamp)
+
+	assert.Equal(t, map[string]time.Time{
+		// Nodes that are already tracked keep their times.
+		"n1": restoredTimestamp.Add(-time.Minute),
+		"n2": restoredTimestamp,
+		// Times in the future are ignored.
+		"n3": initialTimestamp,
+		"n4": finalTimestamp,
+	}, nodes.UnneededSince())
+}
+
+func TestRestoredFirst(t *testing.T) {
+	nodes := NewNodes(nil, nil)
+	names := []string{"n1", "n2", "n3", "n4"}
+	assert.Equal(t, names, nodes.RestoredFirst(names))
+
+	nodes.RestoreUnneededSince(map[string]time.Time{
+		"n2": time.Now(),
+		"n4": time.Now(),
+		"n5": time.Now(),
+	})
+	assert.Equal(t, []string{"n2", "n4", "n1", "n3"}, nodes.RestoredFirst(names))
+
+	// The restored times are only used in the next update.
+	nodes.Update(nil, time.Now())
+	assert.Equal(t, names, nodes.RestoredFirst(names))
+}
+
 const testVersion = "testVersion"
 
 func makeNode(name, version string) simulator.NodeToBeRemoved {
@@ -135,6 +187,7 @@ func TestRemovableAt(t *testing.T) {
 		minSize             int
 		targetSize          int
 		numOngoingDeletions int
+		scaleDownDefer      string
 		numEmptyToRemove    int
 		numDrainToRemove    int
 	}{
@@ -168,6 +221,26 @@ func TestRemovableAt(t *testing.T) {
 			numEmptyToRemove:    2,
 			numDrainToRemove:    0,
 		},
+		{
+			name:             "Scale down of empty nodes is deferred",
+			numEmpty:         3,
+			numDrain:         2,
+			minSize:          1,
+			targetSize:       10,
+			scaleDownDefer:   "1h",
+			numEmptyToRemove: 0,
+			numDrainToRemove: 2,
+		},
+		{
+			name:             "Invalid scale down defer annotation is ignored",
+			numEmpty:         3,
+			numDrain:         2,
+			minSize:          1,
+			targetSize:       10,
+			scaleDownDefer:   "forever",
+			numEmptyToRemove: 3,
+			numDrainToRemove: 2,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -185,6 +258,12 @@ func TestRemovableAt(t *testing.T) {
 					PodsToReschedule: []*apiv1.Pod{BuildTestPod(fmt.Sprintf("pod-%d", i), 1, 1)},
 				})
 			}
+			if tc.scaleDownDefer != "" {
+				for _, n := range append(empty, drain...) {
+					SetNodeReadyState(n.Node, true, time.Now())
+					n.Node.Annotations = map[string]string{eligibility.ScaleDownDeferKey: tc.scaleDownDefer}
+				}
+			}
 
 			nodes := append(empty, drain...)
 			provider := testprovider.NewTestCloudProvider(nil, nil)
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package costlimit
+
+import (
+	"math"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Limiter keeps the hourly cost of the cluster, as estimated by the cloud provider pricing model,
+// under a ceiling by rejecting scale-ups that would exceed it. A ceiling of 0 means no limit.
+type Limiter struct {
+	maxHourlyCost float64
+}
+
+// NewLimiter returns a Limiter with the given max hourly cost of the cluster.
+func NewLimiter(maxHourlyCost float64) *Limiter {
+	return &Limiter{maxHourlyCost: maxHourlyCost}
+}
+
+// BudgetLeft returns the hourly cost that can still be added to the cluster, given its nodes
+// and the nodes that are being provisioned. It's +Inf if there is no limit.
+func (l *Limiter) BudgetLeft(ctx *context.AutoscalingContext, nodes []*apiv1.Node, upcomingNodes []*schedulerframework.NodeInfo, now time.Time) (float64, errors.AutoscalerError) {
+	if l.maxHourlyCost <= 0 {
+		return math.Inf(1), nil
+	}
+	pricing, aErr := ctx.CloudProvider.Pricing()
+	if aErr != nil {
+		return 0, aErr.AddPrefix("max hourly cost is set, but pricing is not available: ")
+	}
+	cost := 0.0
+	add := func(node *apiv1.Node) errors.AutoscalerError {
+		price, err := pricing.NodePrice(node, now, now.Add(time.Hour))
+		if err != nil {
+			return errors.NewAutoscalerError(errors.CloudProviderError, "failed to get price of node %s: %v", node.Name, err)
+		}
+		cost += price
+		return nil
+	}
+	for _, node := range nodes {
+		if aErr := add(node); aErr != nil {
+			return 0, aErr
+		}
+	}
+	for _, nodeInfo := range upcomingNodes {
+		if aErr := add(nodeInfo.Node()); aErr != nil {
+			return 0, aErr
+		}
+	}
+	budgetLeft := math.Max(l.maxHourlyCost-cost, 0)
+	metrics.UpdateHourlyCostBudgetLeft(budgetLeft)
+	return budgetLeft, nil
+}
+
+// MaxNewNodes returns the number of nodes of the node group the budget left can pay for.
+// It's math.MaxInt if there is no limit.
+func (l *Limiter) MaxNewNodes(ctx *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup, budgetLeft float64, nodeInfo *schedulerframework.NodeInfo, now time.Time) (int, errors.AutoscalerError) {
+	if math.IsInf(budgetLeft, 1) {
+		return math.MaxInt, nil
+	}
+	pricing, aErr := ctx.CloudProvider.Pricing()
+	if aErr != nil {
+		return 0, aErr.AddPrefix("max hourly cost is set, but pricing is not available: ")
+	}
+	price, err := pricing.NodePrice(nodeInfo.Node(), now, now.Add(time.Hour))
+	if err != nil {
+		return 0, errors.NewAutoscalerError(errors.CloudProviderError, "failed to get price of node group %s: %v", nodeGroup.Id(), err)
+	}
+	if price <= 0 {
+		return math.MaxInt, nil
+	}
+	return int(math.Floor(budgetLeft / price)), nil
+}
+
+// ApplyLimit caps the number of nodes added to the node group to the ones the budget left can pay for.
+// It emits an event if the scale-up had to be limited.
+func (l *Limiter) ApplyLimit(ctx *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup, newCount int, budgetLeft float64, nodeInfo *schedulerframework.NodeInfo, now time.Time) (int, errors.AutoscalerError) {
+	maxNewNodes, aErr := l.MaxNewNodes(ctx, nodeGroup, budgetLeft, nodeInfo, now)
+	if aErr != nil {
+		return 0, aErr
+	}
+	if newCount <= maxNewNodes {
+		return newCount, nil
+	}
+	klog.V(1).Infof("Scale-up of %s limited to %d new nodes by the max hourly cost, budget left %.2f", nodeGroup.Id(), maxNewNodes, budgetLeft)
+	ctx.LogRecorder.Eventf(apiv1.EventTypeWarning, "MaxHourlyCostReached",
+		"Scale-up of %s limited from %d to %d nodes: max hourly cost %.2f would be exceeded, budget left %.2f",
+		nodeGroup.Id(), newCount, maxNewNodes, l.maxHourlyCost, budgetLeft)
+	return maxNewNodes, nil
+}
+
+// Cost returns the hourly cost of adding newCount nodes to the node group, to be subtracted from
+// the budget left when several node groups are scaled up at once. It's 0 if there is no limit.
+func (l *Limiter) Cost(ctx *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup, newCount int, nodeInfo *schedulerframework.NodeInfo, now time.Time) (float64, errors.AutoscalerError) {
+	if l.maxHourlyCost <= 0 || newCount <= 0 {
+		return 0, nil
+	}
+	pricing, aErr := ctx.CloudProvider.Pricing()
+	if aErr != nil {
+		return 0, aErr.AddPrefix("max hourly cost is set, but pricing is not available: ")
+	}
+	price, err := pricing.NodePrice(nodeInfo.Node(), now, now.Add(time.Hour))
+	if err != nil {
+		return 0, errors.NewAutoscalerError(errors.CloudProviderError, "failed to get price of node group %s: %v", nodeGroup.Id(), err)
+	}
+	return price * float64(newCount), nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package costlimit
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type testPricingModel struct {
+	nodePrices map[string]float64
+}
+
+func (m *testPricingModel) NodePrice(node *apiv1.Node, start, end time.Time) (float64, error) {
+	if price, found := m.nodePrices[node.Name]; found {
+		return price * end.Sub(start).Hours(), nil
+	}
+	return 0, fmt.Errorf("unknown node %s", node.Name)
+}
+
+func (m *testPricingModel) PodPrice(*apiv1.Pod, time.Time, time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestLimiter(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	template := schedulerframework.NewNodeInfo()
+	template.SetNode(BuildTestNode("template", 1000, 1000))
+	now := time.Now()
+
+	testCases := []struct {
+		name           string
+		maxHourlyCost  float64
+		pricing        *testPricingModel
+		upcomingNodes  []*schedulerframework.NodeInfo
+		newCount       int
+		wantBudgetLeft float64
+		wantBudgetErr  bool
+		wantCount      int
+		wantCost       float64
+		wantEvents     int
+	}{
+		{
+			name:           "no limit",
+			newCount:       10,
+			wantBudgetLeft: math.Inf(1),
+			wantCount:      10,
+		},
+		{
+			name:           "within budget",
+			maxHourlyCost:  20,
+			pricing:        &testPricingModel{nodePrices: map[string]float64{"n1": 3, "n2": 2, "template": 1.5}},
+			upcomingNodes:  []*schedulerframework.NodeInfo{template},
+			newCount:       5,
+			wantBudgetLeft: 13.5,
+			wantCount:      5,
+			wantCost:       7.5,
+		},
+		{
+			name:           "limited",
+			maxHourlyCost:  20,
+			pricing:        &testPricingModel{nodePrices: map[string]float64{"n1": 3, "n2": 2, "template": 4}},
+			newCount:       5,
+			wantBudgetLeft: 15,
+			wantCount:      3,
+			wantCost:       12,
+			wantEvents:     1,
+		},
+		{
+			name:           "budget exceeded",
+			maxHourlyCost:  4,
+			pricing:        &testPricingModel{nodePrices: map[string]float64{"n1": 3, "n2": 2, "template": 1}},
+			newCount:       5,
+			wantBudgetLeft: 0,
+			wantCount:      0,
+			wantEvents:     1,
+		},
+		{
+			name:          "no pricing",
+			maxHourlyCost: 20,
+			wantBudgetErr: true,
+		},
+		{
+			name:          "node without price",
+			maxHourlyCost: 20,
+			pricing:       &testPricingModel{nodePrices: map[string]float64{"n1": 3}},
+			wantBudgetErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 0, 100, 0)
+			if tc.pricing != nil {
+				provider.SetPricingModel(tc.pricing)
+			}
+			recorder := kube_record.NewFakeRecorder(10)
+			logRecorder, err := utils.NewStatusMapRecorder(fake.NewSimpleClientset(), "kube-system", recorder, true, "my-cool-configmap")
+			assert.NoError(t, err)
+			autoscalingContext := &context.AutoscalingContext{
+				CloudProvider:          provider,
+				AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: recorder, LogRecorder: logRecorder},
+			}
+			limiter := NewLimiter(tc.maxHourlyCost)
+
+			budgetLeft, aErr := limiter.BudgetLeft(autoscalingContext, []*apiv1.Node{n1, n2}, tc.upcomingNodes, now)
+			if tc.wantBudgetErr {
+				assert.Error(t, aErr)
+				return
+			}
+			assert.NoError(t, aErr)
+			assert.Equal(t, tc.wantBudgetLeft, budgetLeft)
+
+			count, aErr := limiter.ApplyLimit(autoscalingContext, provider.GetNodeGroup("ng1"), tc.newCount, budgetLeft, template, now)
+			assert.NoError(t, aErr)
+			assert.Equal(t, tc.wantCount, count)
+			assert.Equal(t, tc.wantEvents, len(recorder.Events))
+
+			cost, aErr := limiter.Cost(autoscalingContext, provider.GetNodeGroup("ng1"), count, template, now)
+			assert.NoError(t, aErr)
+			assert.Equal(t, tc.wantCost, cost)
+		})
+	}
+}
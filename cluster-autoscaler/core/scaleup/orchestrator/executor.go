@@ -32,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	"k8s.io/autoscaler/cluster-autoscaler/observers/nodegroupchange"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/tracing"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 )
@@ -57,12 +58,16 @@ func newScaleUpExecutor(
 // May scale up groups concurrently when autoscler option is enabled.
 // In case of issues returns an error and a scale up info which failed to execute.
 // If there were multiple concurrent errors one combined error is returned.
+// Atomic scale-ups of several node groups are executed as a transaction, see executeScaleUpTransaction.
 func (e *scaleUpExecutor) ExecuteScaleUps(
 	scaleUpInfos []nodegroupset.ScaleUpInfo,
 	nodeInfos map[string]*schedulerframework.NodeInfo,
 	now time.Time,
 	atomic bool,
 ) (errors.AutoscalerError, []cloudprovider.NodeGroup) {
+	if atomic && len(scaleUpInfos) > 1 {
+		return e.executeScaleUpTransaction(scaleUpInfos, nodeInfos, now)
+	}
 	options := e.autoscalingContext.AutoscalingOptions
 	if options.ParallelScaleUp {
 		return e.executeScaleUpsParallel(scaleUpInfos, nodeInfos, now, atomic)
@@ -139,7 +144,55 @@ func (e *scaleUpExecutor) executeScaleUpsParallel(
 	return nil, nil
 }
 
-func (e *scaleUpExecutor) increaseSize(nodeGroup cloudprovider.NodeGroup, increase int, atomic bool) error {
+// executeScaleUpTransaction executes the scale-ups of an atomic scale-up of several node groups one
+// by one, e.g. of a gang of node groups or of similar node groups it is balanced between, so that
+// either all node groups are scaled up or none is. If a scale-up fails, the node groups already
+// scaled up are scaled back down, so the pods don't get only part of the capacity they need.
+func (e *scaleUpExecutor) executeScaleUpTransaction(
+	scaleUpInfos []nodegroupset.ScaleUpInfo,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	now time.Time,
+) (errors.AutoscalerError, []cloudprovider.NodeGroup) {
+	if err := checkUniqueNodeGroups(scaleUpInfos); err != nil {
+		return err, extractNodeGroups(scaleUpInfos)
+	}
+	availableGPUTypes := e.autoscalingContext.CloudProvider.GetAvailableGPUTypes()
+	for i, scaleUpInfo := range scaleUpInfos {
+		var aErr errors.AutoscalerError
+		if nodeInfo, ok := nodeInfos[scaleUpInfo.Group.Id()]; ok {
+			aErr = e.executeScaleUp(scaleUpInfo, nodeInfo, availableGPUTypes, now, true)
+		} else {
+			aErr = errors.NewAutoscalerError(errors.InternalError, "failed to get node info for node group %s", scaleUpInfo.Group.Id())
+		}
+		if aErr != nil {
+			e.rollBackScaleUps(scaleUpInfos[:i], scaleUpInfo.Group, now)
+			return aErr.AddPrefix("atomic scale-up rolled back: "), []cloudprovider.NodeGroup{scaleUpInfo.Group}
+		}
+	}
+	return nil, nil
+}
+
+// rollBackScaleUps decreases the target size of node groups scaled up in a transaction which failed
+// on another node group. The added nodes haven't registered yet, so they are simply not created.
+func (e *scaleUpExecutor) rollBackScaleUps(scaleUpInfos []nodegroupset.ScaleUpInfo, failedGroup cloudprovider.NodeGroup, now time.Time) {
+	for _, info := range scaleUpInfos {
+		increase := info.NewSize - info.CurrentSize
+		klog.V(0).Infof("Scale-up: rolling back group %s size to %d, scale-up of group %s failed", info.Group.Id(), info.CurrentSize, failedGroup.Id())
+		if err := info.Group.DecreaseTargetSize(-increase); err != nil {
+			klog.Errorf("Failed to roll back scale-up of group %s: %v", info.Group.Id(), err)
+			e.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeWarning, "FailedToRollBackScaleUpGroup",
+				"Rolling back scale-up failed for group %s: %v", info.Group.Id(), err)
+			continue
+		}
+		e.scaleStateNotifier.RegisterScaleUp(info.Group, -increase, now)
+		e.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "RolledBackScaleUpGroup",
+			"Scale-up: group %s size set back to %d, scale-up of group %s failed", info.Group.Id(), info.CurrentSize, failedGroup.Id())
+	}
+}
+
+func (e *scaleUpExecutor) increaseSize(nodeGroup cloudprovider.NodeGroup, increase int, atomic bool) (err error) {
+	span := tracing.Start("NodeGroup.IncreaseSize", tracing.NodeGroup(nodeGroup.Id()))
+	defer func() { span.End(err) }()
 	if atomic {
 		if err := nodeGroup.AtomicIncreaseSize(increase); err != cloudprovider.ErrNotImplemented {
 			return err
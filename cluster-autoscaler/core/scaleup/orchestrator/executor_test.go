@@ -17,9 +17,20 @@ limitations under the License.
 package orchestrator
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -125,3 +136,86 @@ func TestCombinedConcurrentScaleUpErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteScaleUpTransaction(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		failingGroup       string
+		wantTargetSizes    map[string]int
+		wantFailedGroups   []string
+		wantScaleUpRequest map[string]int
+	}{
+		{
+			desc:               "all node groups scaled up",
+			wantTargetSizes:    map[string]int{"cpu": 3, "gpu": 4, "storage": 2},
+			wantScaleUpRequest: map[string]int{"cpu": 2, "gpu": 2, "storage": 1},
+		},
+		{
+			desc:               "scale-ups rolled back",
+			failingGroup:       "storage",
+			wantTargetSizes:    map[string]int{"cpu": 1, "gpu": 2},
+			wantFailedGroups:   []string{"storage"},
+			wantScaleUpRequest: map[string]int{},
+		},
+		{
+			desc:               "first scale-up failed",
+			failingGroup:       "cpu",
+			wantTargetSizes:    map[string]int{"gpu": 2, "storage": 1},
+			wantFailedGroups:   []string{"cpu"},
+			wantScaleUpRequest: map[string]int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Now()
+			provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+				if nodeGroup == tc.failingGroup && increase > 0 {
+					return fmt.Errorf("out of capacity")
+				}
+				return nil
+			}, nil)
+			provider.AddNodeGroup("cpu", 0, 10, 1)
+			provider.AddNodeGroup("gpu", 0, 10, 2)
+			provider.AddNodeGroup("storage", 0, 10, 1)
+			nodeInfos := map[string]*schedulerframework.NodeInfo{}
+			for _, id := range []string{"cpu", "gpu", "storage"} {
+				nodeInfo := schedulerframework.NewNodeInfo()
+				nodeInfo.SetNode(BuildTestNode(id+"-template", 1000, 1000))
+				nodeInfos[id] = nodeInfo
+			}
+
+			context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, &fake.Clientset{}, nil, provider, nil, nil)
+			assert.NoError(t, err)
+			clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(),
+				nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+			executor := newScaleUpExecutor(&context, clusterState)
+
+			scaleUpInfos := []nodegroupset.ScaleUpInfo{
+				{Group: provider.GetNodeGroup("cpu"), CurrentSize: 1, NewSize: 3, MaxSize: 10},
+				{Group: provider.GetNodeGroup("gpu"), CurrentSize: 2, NewSize: 4, MaxSize: 10},
+				{Group: provider.GetNodeGroup("storage"), CurrentSize: 1, NewSize: 2, MaxSize: 10},
+			}
+			aErr, failedGroups := executor.ExecuteScaleUps(scaleUpInfos, nodeInfos, now, true)
+
+			var failedGroupIds []string
+			for _, ng := range failedGroups {
+				failedGroupIds = append(failedGroupIds, ng.Id())
+			}
+			assert.Equal(t, tc.wantFailedGroups, failedGroupIds)
+			assert.Equal(t, tc.failingGroup != "", aErr != nil)
+			for id, wantTargetSize := range tc.wantTargetSizes {
+				targetSize, err := provider.GetNodeGroup(id).TargetSize()
+				assert.NoError(t, err)
+				assert.Equal(t, wantTargetSize, targetSize, id)
+			}
+			scaleUpRequests := map[string]int{}
+			for id, request := range clusterState.ScaleUpRequestsCheckpoint() {
+				scaleUpRequests[id] = request.Increase
+			}
+			assert.Equal(t, tc.wantScaleUpRequest, scaleUpRequests)
+			if tc.failingGroup != "" {
+				assert.True(t, clusterState.BackoffStatusForNodeGroup(provider.GetNodeGroup(tc.failingGroup), now).IsBackedOff)
+			}
+		})
+	}
+}
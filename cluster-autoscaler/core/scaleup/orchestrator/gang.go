@@ -0,0 +1,235 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orchestrator
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// computeGangOptions computes an expansion option for each gang of node groups whose node groups are all valid for
+// scale-up, if the pods need nodes of at least two of them. Pods are estimated on the node groups of a gang in the
+// order they're listed, each node group getting the pods that didn't fit on the node groups before it.
+func (o *ScaleUpOrchestrator) computeGangOptions(
+	validNodeGroups []cloudprovider.NodeGroup,
+	schedulablePodGroups map[string][]estimator.PodEquivalenceGroup,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	currentNodeCount int,
+	allOrNothing bool,
+) []expander.Option {
+	validByID := make(map[string]cloudprovider.NodeGroup, len(validNodeGroups))
+	for _, nodeGroup := range validNodeGroups {
+		validByID[nodeGroup.Id()] = nodeGroup
+	}
+
+	var options []expander.Option
+	for _, gang := range o.autoscalingContext.NodeGroupGangs {
+		if option, ok := o.computeGangOption(gang, validByID, schedulablePodGroups, nodeInfos, currentNodeCount, allOrNothing); ok {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+func (o *ScaleUpOrchestrator) computeGangOption(
+	gang []string,
+	validByID map[string]cloudprovider.NodeGroup,
+	schedulablePodGroups map[string][]estimator.PodEquivalenceGroup,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	currentNodeCount int,
+	allOrNothing bool,
+) (expander.Option, bool) {
+	estimated := map[*apiv1.Pod]bool{}
+	var members []expander.Option
+	for _, id := range gang {
+		nodeGroup, found := validByID[id]
+		if !found || !nodeGroup.Exist() {
+			klog.V(4).Infof("Skipping gang %v - node group %s can't be scaled up", gang, id)
+			return expander.Option{}, false
+		}
+
+		var podGroups []estimator.PodEquivalenceGroup
+		for _, podGroup := range schedulablePodGroups[id] {
+			var pods []*apiv1.Pod
+			for _, pod := range podGroup.Pods {
+				if !estimated[pod] {
+					pods = append(pods, pod)
+				}
+			}
+			if len(pods) > 0 {
+				podGroups = append(podGroups, estimator.PodEquivalenceGroup{Pods: pods})
+			}
+		}
+		if len(podGroups) == 0 {
+			continue
+		}
+
+		member := expander.Option{NodeGroup: nodeGroup}
+		o.estimateExpansionOption(&member, o.autoscalingContext.ClusterSnapshot, podGroups, nodeInfos[id], currentNodeCount)
+		o.applyZeroOrMaxNodeScaling(&member, allOrNothing)
+		if len(member.Pods) == 0 || member.NodeCount == 0 {
+			continue
+		}
+		for _, pod := range member.Pods {
+			estimated[pod] = true
+		}
+		currentNodeCount += member.NodeCount
+		members = append(members, member)
+	}
+
+	// Pods that only need one node group of the gang are covered by the option of that node group.
+	if len(members) < 2 {
+		return expander.Option{}, false
+	}
+	option := members[0]
+	option.Pods = append([]*apiv1.Pod{}, option.Pods...)
+	option.GangOptions = members[1:]
+	for _, member := range option.GangOptions {
+		option.Pods = append(option.Pods, member.Pods...)
+	}
+	return option, true
+}
+
+// scaleUpGang scales up the node groups of a gang option as a transaction, either all of them or none. Each node
+// group must be able to add all of its nodes within the limits of the cluster and of the node group.
+func (o *ScaleUpOrchestrator) scaleUpGang(
+	bestOption *expander.Option,
+	nodeGroups []cloudprovider.NodeGroup,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	podEquivalenceGroups []*equivalence.PodGroup,
+	skippedNodeGroups map[string]status.Reasons,
+	resourcesLeft resource.Limits,
+	costLeft float64,
+	currentNodeCount int,
+	now time.Time,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	notAccommodated := func() *status.ScaleUpStatus {
+		klog.V(1).Info("Not attempting scale-up of gang: not all pods would be accommodated")
+		markedEquivalenceGroups := markAllGroupsAsUnschedulable(podEquivalenceGroups, AllOrNothingReason)
+		return buildNoOptionsAvailableStatus(markedEquivalenceGroups, skippedNodeGroups, nodeGroups)
+	}
+
+	members := append([]expander.Option{*bestOption}, bestOption.GangOptions...)
+	gangNodeCount := 0
+	for _, member := range members {
+		gangNodeCount += member.NodeCount
+	}
+	if newNodes, aErr := o.GetCappedNewNodeCount(gangNodeCount, currentNodeCount); aErr != nil || newNodes < gangNodeCount {
+		klog.V(1).Infof("Only %d of the %d nodes of the gang can be added due to the max size of the cluster", max(newNodes, 0), gangNodeCount)
+		return notAccommodated(), nil
+	}
+
+	scaleUpInfos := make([]nodegroupset.ScaleUpInfo, 0, len(members))
+	for _, member := range members {
+		nodeGroup := member.NodeGroup
+		nodeInfo, found := nodeInfos[nodeGroup.Id()]
+		if !found {
+			klog.Errorf("No node info for: %s", nodeGroup.Id())
+			return status.UpdateScaleUpError(
+				&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
+				errors.NewAutoscalerError(errors.CloudProviderError, "No node info for gang expansion option!"))
+		}
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			return status.UpdateScaleUpError(
+				&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
+				errors.ToAutoscalerError(errors.CloudProviderError, err).AddPrefix("failed to get node group size: "))
+		}
+
+		newNodes, aErr := o.resourceManager.ApplyLimits(o.autoscalingContext, member.NodeCount, resourcesLeft, nodeInfo, nodeGroup)
+		if aErr != nil {
+			return status.UpdateScaleUpError(&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods}, aErr)
+		}
+		newNodes, aErr = o.costLimiter.ApplyLimit(o.autoscalingContext, nodeGroup, newNodes, costLeft, nodeInfo, now)
+		if aErr != nil {
+			return status.UpdateScaleUpError(&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods}, aErr)
+		}
+		if newNodes < member.NodeCount || targetSize+newNodes > nodeGroup.MaxSize() {
+			klog.V(1).Infof("Only %d of %d nodes can be added to %s of the gang", min(newNodes, nodeGroup.MaxSize()-targetSize), member.NodeCount, nodeGroup.Id())
+			return notAccommodated(), nil
+		}
+
+		scaleUpInfos = append(scaleUpInfos, nodegroupset.ScaleUpInfo{
+			Group:       nodeGroup,
+			CurrentSize: targetSize,
+			NewSize:     targetSize + newNodes,
+			MaxSize:     nodeGroup.MaxSize(),
+		})
+		// The following node groups of the gang are limited by what's left after this scale-up.
+		costLeft = o.subtractScaleUp(resourcesLeft, costLeft, nodeGroup, nodeInfo, newNodes, now)
+	}
+
+	// Smooth bursts of scale-ups by limiting the number of nodes added per minute.
+	limitedScaleUpInfos, aErr := o.rateLimiter.ApplyLimits(scaleUpInfos, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods}, aErr)
+	}
+	limited := len(limitedScaleUpInfos) < len(scaleUpInfos)
+	for i := 0; !limited && i < len(limitedScaleUpInfos); i++ {
+		limited = limitedScaleUpInfos[i].NewSize < scaleUpInfos[i].NewSize
+	}
+	if limited {
+		klog.V(1).Info("Not attempting scale-up of gang, max new nodes per minute reached")
+		return &status.ScaleUpStatus{
+			Result:                  status.ScaleUpInCooldown,
+			PodsRemainUnschedulable: GetRemainingPods(podEquivalenceGroups, skippedNodeGroups),
+			ConsideredNodeGroups:    nodeGroups,
+		}, nil
+	}
+
+	klog.V(1).Infof("Final gang scale-up plan: %v", scaleUpInfos)
+	if err := o.processors.ActuationHooks.BeforeScaleUp(o.autoscalingContext, scaleUpInfos); err != nil {
+		o.logScaleUpVetoed(err)
+		return &status.ScaleUpStatus{
+			Result:                  status.ScaleUpNotTried,
+			PodsRemainUnschedulable: GetRemainingPods(podEquivalenceGroups, skippedNodeGroups),
+			ConsideredNodeGroups:    nodeGroups,
+		}, nil
+	}
+	aErr, failedNodeGroups := o.scaleUpExecutor.ExecuteScaleUps(scaleUpInfos, nodeInfos, now, true)
+	o.processors.ActuationHooks.AfterScaleUp(o.autoscalingContext, scaleUpInfos, failedNodeGroups, aErr)
+	if aErr != nil {
+		return status.UpdateScaleUpError(
+			&status.ScaleUpStatus{
+				FailedResizeNodeGroups: failedNodeGroups,
+				PodsTriggeredScaleUp:   bestOption.Pods,
+			},
+			aErr,
+		)
+	}
+
+	o.clusterStateRegistry.Recalculate()
+	return &status.ScaleUpStatus{
+		Result:                  status.ScaleUpSuccessful,
+		ScaleUpInfos:            scaleUpInfos,
+		PodsRemainUnschedulable: GetRemainingPods(podEquivalenceGroups, skippedNodeGroups),
+		ConsideredNodeGroups:    nodeGroups,
+		PodsTriggeredScaleUp:    bestOption.Pods,
+	}, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfosprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+)
+
+func TestScaleUpGang(t *testing.T) {
+	testCases := []struct {
+		name            string
+		gangs           [][]string
+		allOrNothing    bool
+		maxNodesTotal   int
+		failedGroup     string
+		wantResult      status.ScaleUpResult
+		wantError       bool
+		wantTargetSizes map[string]int
+	}{
+		{
+			name:            "gang scaled up",
+			gangs:           [][]string{{"cpu-ng", "gpu-ng"}},
+			allOrNothing:    true,
+			wantResult:      status.ScaleUpSuccessful,
+			wantTargetSizes: map[string]int{"cpu-ng": 3, "gpu-ng": 2},
+		},
+		{
+			name:            "no node group fits all pods without a gang",
+			allOrNothing:    true,
+			wantResult:      status.ScaleUpNoOptionsAvailable,
+			wantTargetSizes: map[string]int{"cpu-ng": 1, "gpu-ng": 1},
+		},
+		{
+			name:            "gang not scaled up if one of its node groups is limited",
+			gangs:           [][]string{{"cpu-ng", "gpu-ng"}},
+			allOrNothing:    true,
+			maxNodesTotal:   4,
+			wantResult:      status.ScaleUpNoOptionsAvailable,
+			wantTargetSizes: map[string]int{"cpu-ng": 1, "gpu-ng": 1},
+		},
+		{
+			name:            "scale-up rolled back if one of the node groups fails",
+			gangs:           [][]string{{"cpu-ng", "gpu-ng"}},
+			allOrNothing:    true,
+			failedGroup:     "gpu-ng",
+			wantResult:      status.ScaleUpError,
+			wantError:       true,
+			wantTargetSizes: map[string]int{"cpu-ng": 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+				if nodeGroup == tc.failedGroup && increase > 0 {
+					return fmt.Errorf("out of capacity")
+				}
+				return nil
+			}, nil)
+
+			// Nodes of the CPU node group have a lot of memory, nodes of the GPU node group have a lot of cores.
+			cpuNode := BuildTestNode("cpu-n1", 2000, 8*units.GiB)
+			SetNodeReadyState(cpuNode, true, time.Now())
+			gpuNode := BuildTestNode("gpu-n1", 8000, units.GiB)
+			SetNodeReadyState(gpuNode, true, time.Now())
+			provider.AddNodeGroup("cpu-ng", 1, 10, 1)
+			provider.AddNode("cpu-ng", cpuNode)
+			provider.AddNodeGroup("gpu-ng", 1, 10, 1)
+			provider.AddNode("gpu-ng", gpuNode)
+
+			options := config.AutoscalingOptions{
+				EstimatorName:  estimator.BinpackingEstimatorName,
+				MaxCoresTotal:  config.DefaultMaxClusterCores,
+				MaxMemoryTotal: config.DefaultMaxClusterMemory * units.GiB,
+				MaxNodesTotal:  tc.maxNodesTotal,
+				NodeGroupGangs: tc.gangs,
+			}
+			podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+			listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+			context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+			assert.NoError(t, err)
+
+			nodes := []*apiv1.Node{cpuNode, gpuNode}
+			nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+			processors := NewTestProcessors(&context)
+			clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+			clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+			// The memory-bound pods only fit on 2 new nodes of the CPU node group, the cpu-bound pod only fits
+			// on a new node of the GPU node group.
+			pods := []*apiv1.Pod{
+				BuildTestPod("memory-0", 1000, 6*units.GiB),
+				BuildTestPod("memory-1", 1000, 6*units.GiB),
+				BuildTestPod("cpu-0", 6000, 100*units.MiB),
+			}
+
+			suOrchestrator := New()
+			suOrchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+			scaleUpStatus, aErr := suOrchestrator.ScaleUp(pods, nodes, []*appsv1.DaemonSet{}, nodeInfos, tc.allOrNothing)
+			if tc.wantError {
+				assert.Error(t, aErr)
+			} else {
+				assert.NoError(t, aErr)
+			}
+			assert.Equal(t, tc.wantResult, scaleUpStatus.Result)
+			for id, wantTargetSize := range tc.wantTargetSizes {
+				targetSize, err := provider.GetNodeGroup(id).TargetSize()
+				assert.NoError(t, err)
+				assert.Equal(t, wantTargetSize, targetSize, "target size of %s", id)
+			}
+		})
+	}
+}
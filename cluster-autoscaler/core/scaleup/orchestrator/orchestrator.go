@@ -17,7 +17,9 @@ limitations under the License.
 package orchestrator
 
 import (
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -29,7 +31,9 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/costlimit"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/ratelimit"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/core/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
@@ -38,6 +42,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/klogx"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
@@ -50,6 +55,8 @@ type ScaleUpOrchestrator struct {
 	resourceManager      *resource.Manager
 	clusterStateRegistry *clusterstate.ClusterStateRegistry
 	scaleUpExecutor      *scaleUpExecutor
+	rateLimiter          *ratelimit.Limiter
+	costLimiter          *costlimit.Limiter
 	estimatorBuilder     estimator.EstimatorBuilder
 	taintConfig          taints.TaintConfig
 	initialized          bool
@@ -77,6 +84,11 @@ func (o *ScaleUpOrchestrator) Initialize(
 	o.taintConfig = taintConfig
 	o.resourceManager = resource.NewManager(processors.CustomResourcesProcessor)
 	o.scaleUpExecutor = newScaleUpExecutor(autoscalingContext, processors.ScaleStateNotifier)
+	o.rateLimiter = ratelimit.NewLimiter(autoscalingContext.MaxNewNodesPerMinute, processors.NodeGroupConfigProcessor)
+	if processors.ScaleStateNotifier != nil {
+		processors.ScaleStateNotifier.Register(o.rateLimiter)
+	}
+	o.costLimiter = costlimit.NewLimiter(autoscalingContext.MaxHourlyCost)
 	o.initialized = true
 }
 
@@ -129,8 +141,13 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 
 	now := time.Now()
 
+	costLeft, aErr := o.costLimiter.BudgetLeft(o.autoscalingContext, nodes, upcomingNodes, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not compute hourly cost budget: "))
+	}
+
 	// Filter out invalid node groups
-	validNodeGroups, skippedNodeGroups := o.filterValidScaleUpNodeGroups(nodeGroups, nodeInfos, resourcesLeft, len(nodes)+len(upcomingNodes), now)
+	validNodeGroups, skippedNodeGroups := o.filterValidScaleUpNodeGroups(nodeGroups, nodeInfos, resourcesLeft, costLeft, len(nodes)+len(upcomingNodes), now)
 
 	// Mark skipped node groups as processed.
 	for nodegroupID := range skippedNodeGroups {
@@ -145,23 +162,48 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 		schedulablePodGroups[nodeGroup.Id()] = o.SchedulablePodGroups(podEquivalenceGroups, nodeGroup, nodeInfos[nodeGroup.Id()])
 	}
 
-	for _, nodeGroup := range validNodeGroups {
-		option := o.ComputeExpansionOption(nodeGroup, schedulablePodGroups, nodeInfos, len(nodes)+len(upcomingNodes), now, allOrNothing)
-		o.processors.BinpackingLimiter.MarkProcessed(o.autoscalingContext, nodeGroup.Id())
-
-		if len(option.Pods) == 0 || option.NodeCount == 0 {
-			klog.V(4).Infof("No pod can fit to %s", nodeGroup.Id())
-		} else if allOrNothing && len(option.Pods) < len(unschedulablePods) {
-			klog.V(4).Infof("Some pods can't fit to %s, giving up due to all-or-nothing scale-up strategy", nodeGroup.Id())
-		} else {
-			options = append(options, option)
+	// With parallel estimation, the options of node groups are estimated in batches of ParallelEstimationWorkers node
+	// groups. The binpacking limiter is applied to the options of a batch one by one, in the same order, and no
+	// further batch is estimated once it stops binpacking.
+	batchSize := len(validNodeGroups)
+	if o.autoscalingContext.ParallelEstimationWorkers > 1 {
+		batchSize = o.autoscalingContext.ParallelEstimationWorkers
+	}
+	stopBinpacking := false
+	for start := 0; start < len(validNodeGroups) && !stopBinpacking; start += batchSize {
+		batch := validNodeGroups[start:min(start+batchSize, len(validNodeGroups))]
+		var estimatedOptions map[string]expander.Option
+		if o.autoscalingContext.ParallelEstimationWorkers > 1 {
+			estimatedOptions = o.computeExpansionOptionsInParallel(batch, schedulablePodGroups, nodeInfos, len(nodes)+len(upcomingNodes), now, allOrNothing)
 		}
 
-		if o.processors.BinpackingLimiter.StopBinpacking(o.autoscalingContext, options) {
-			break
+		for _, nodeGroup := range batch {
+			option, found := estimatedOptions[nodeGroup.Id()]
+			if !found {
+				option = o.ComputeExpansionOption(nodeGroup, schedulablePodGroups, nodeInfos, len(nodes)+len(upcomingNodes), now, allOrNothing)
+			}
+			o.processors.BinpackingLimiter.MarkProcessed(o.autoscalingContext, nodeGroup.Id())
+
+			if len(option.Pods) == 0 || option.NodeCount == 0 {
+				klog.V(4).Infof("No pod can fit to %s", nodeGroup.Id())
+			} else if allOrNothing && len(option.Pods) < len(unschedulablePods) {
+				klog.V(4).Infof("Some pods can't fit to %s, giving up due to all-or-nothing scale-up strategy", nodeGroup.Id())
+			} else {
+				options = append(options, option)
+			}
+
+			if o.processors.BinpackingLimiter.StopBinpacking(o.autoscalingContext, options) {
+				stopBinpacking = true
+				break
+			}
 		}
 	}
 
+	// Gangs of node groups are scaled up together for pods that need nodes of several of them.
+	if len(o.autoscalingContext.NodeGroupGangs) > 0 {
+		options = append(options, o.computeGangOptions(validNodeGroups, schedulablePodGroups, nodeInfos, len(nodes)+len(upcomingNodes), allOrNothing)...)
+	}
+
 	// Finalize binpacking limiter.
 	o.processors.BinpackingLimiter.FinalizeBinpacking(o.autoscalingContext, options)
 
@@ -176,6 +218,7 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 
 	// Pick some expansion option.
 	bestOption := o.autoscalingContext.ExpanderStrategy.BestOption(options, nodeInfos)
+	o.autoscalingContext.DebuggingSnapshotter.SetExpansionOptions(options, bestOption)
 	if bestOption == nil || bestOption.NodeCount <= 0 {
 		return &status.ScaleUpStatus{
 			Result:                  status.ScaleUpNoOptionsAvailable,
@@ -188,6 +231,12 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 		klog.V(1).Info(bestOption.Debug)
 	}
 	klog.V(1).Infof("Estimated %d nodes needed in %s", bestOption.NodeCount, bestOption.NodeGroup.Id())
+	if len(bestOption.GangOptions) > 0 {
+		for _, gangOption := range bestOption.GangOptions {
+			klog.V(1).Infof("Estimated %d nodes needed in %s of the same gang", gangOption.NodeCount, gangOption.NodeGroup.Id())
+		}
+		return o.scaleUpGang(bestOption, nodeGroups, nodeInfos, podEquivalenceGroups, skippedNodeGroups, resourcesLeft, costLeft, len(nodes)+len(upcomingNodes), now)
+	}
 
 	// Cap new nodes to supported number of nodes in the cluster.
 	newNodes, aErr := o.GetCappedNewNodeCount(bestOption.NodeCount, len(nodes)+len(upcomingNodes))
@@ -212,6 +261,12 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 			&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
 			aErr)
 	}
+	newNodes, aErr = o.costLimiter.ApplyLimit(o.autoscalingContext, bestOption.NodeGroup, newNodes, costLeft, nodeInfo, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(
+			&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
+			aErr)
+	}
 
 	if newNodes < bestOption.NodeCount {
 		klog.V(1).Infof("Only %d nodes can be added to %s due to cluster-wide limits", newNodes, bestOption.NodeGroup.Id())
@@ -275,6 +330,23 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 			aErr)
 	}
 
+	// Smooth bursts of scale-ups by limiting the number of nodes added per minute.
+	scaleUpInfos, aErr = o.rateLimiter.ApplyLimits(scaleUpInfos, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(
+			&status.ScaleUpStatus{CreateNodeGroupResults: createNodeGroupResults, PodsTriggeredScaleUp: bestOption.Pods},
+			aErr)
+	}
+	if len(scaleUpInfos) == 0 {
+		klog.V(1).Info("Not attempting scale-up, max new nodes per minute reached")
+		return &status.ScaleUpStatus{
+			Result:                  status.ScaleUpInCooldown,
+			PodsRemainUnschedulable: GetRemainingPods(podEquivalenceGroups, skippedNodeGroups),
+			ConsideredNodeGroups:    nodeGroups,
+			CreateNodeGroupResults:  createNodeGroupResults,
+		}, nil
+	}
+
 	// Last check before scale-up. Node group capacity (both due to max size limits & current size) is only checked when balancing.
 	totalCapacity := 0
 	for _, sui := range scaleUpInfos {
@@ -292,7 +364,17 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 
 	// Execute scale up.
 	klog.V(1).Infof("Final scale-up plan: %v", scaleUpInfos)
+	if err := o.processors.ActuationHooks.BeforeScaleUp(o.autoscalingContext, scaleUpInfos); err != nil {
+		o.logScaleUpVetoed(err)
+		return &status.ScaleUpStatus{
+			Result:                  status.ScaleUpNotTried,
+			PodsRemainUnschedulable: GetRemainingPods(podEquivalenceGroups, skippedNodeGroups),
+			ConsideredNodeGroups:    nodeGroups,
+			CreateNodeGroupResults:  createNodeGroupResults,
+		}, nil
+	}
 	aErr, failedNodeGroups := o.scaleUpExecutor.ExecuteScaleUps(scaleUpInfos, nodeInfos, now, allOrNothing)
+	o.processors.ActuationHooks.AfterScaleUp(o.autoscalingContext, scaleUpInfos, failedNodeGroups, aErr)
 	if aErr != nil {
 		return status.UpdateScaleUpError(
 			&status.ScaleUpStatus{
@@ -328,77 +410,172 @@ func (o *ScaleUpOrchestrator) ScaleUpToNodeGroupMinSize(
 		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, errors.NewAutoscalerError(errors.InternalError, "ScaleUpOrchestrator is not initialized"))
 	}
 
-	now := time.Now()
 	nodeGroups := o.autoscalingContext.CloudProvider.NodeGroups()
+	return o.scaleUpNodeGroups("ScaleUpToNodeGroupMinSize", nodeGroups, nodes, nodeInfos, func(ng cloudprovider.NodeGroup, targetSize, _ int) int {
+		klog.V(4).Infof("ScaleUpToNodeGroupMinSize: NodeGroup %s, TargetSize %d, MinSize %d, MaxSize %d", ng.Id(), targetSize, ng.MinSize(), ng.MaxSize())
+		return ng.MinSize() - targetSize
+	})
+}
+
+// ScaleUpForHeadroom scales up node groups by the number of nodes needed to fit the placeholder
+// pods reserving their headroom that don't fit in the cluster, by node group id. Returns
+// appropriate status or error if an unexpected error occurred.
+func (o *ScaleUpOrchestrator) ScaleUpForHeadroom(
+	placeholders map[string][]*apiv1.Pod,
+	nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	if !o.initialized {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, errors.NewAutoscalerError(errors.InternalError, "ScaleUpOrchestrator is not initialized"))
+	}
+
+	var nodeGroups []cloudprovider.NodeGroup
+	for _, ng := range o.autoscalingContext.CloudProvider.NodeGroups() {
+		if len(placeholders[ng.Id()]) > 0 {
+			nodeGroups = append(nodeGroups, ng)
+		}
+	}
+	return o.scaleUpNodeGroups("ScaleUpForHeadroom", nodeGroups, nodes, nodeInfos, func(ng cloudprovider.NodeGroup, _, currentNodeCount int) int {
+		nodeInfo, found := nodeInfos[ng.Id()]
+		if !found {
+			return 0
+		}
+		podGroups := o.SchedulablePodGroups(equivalence.BuildPodGroups(placeholders[ng.Id()]), ng, nodeInfo)
+		option := expander.Option{NodeGroup: ng}
+		if len(podGroups) > 0 {
+			o.estimateExpansionOption(&option, o.autoscalingContext.ClusterSnapshot, podGroups, nodeInfo, currentNodeCount)
+		}
+		if option.NodeCount == 0 {
+			klog.Warningf("ScaleUpForHeadroom: headroom units of node group %s don't fit on its nodes", ng.Id())
+		}
+		return option.NodeCount
+	})
+}
+
+// scaleUpNodeGroups scales up each of the node groups by the number of nodes newNodeCount returns
+// for it, given its target size and the number of nodes in the cluster. Like the scale-ups for unschedulable pods, they're capped by the
+// resource limits, the max hourly cost, the max size of the cluster and the max new nodes per
+// minute. Logs are prefixed with the caller.
+func (o *ScaleUpOrchestrator) scaleUpNodeGroups(
+	caller string,
+	nodeGroups []cloudprovider.NodeGroup,
+	nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	newNodeCount func(ng cloudprovider.NodeGroup, targetSize, currentNodeCount int) int,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	now := time.Now()
 	scaleUpInfos := make([]nodegroupset.ScaleUpInfo, 0)
 
+	upcomingNodes, aErr := o.UpcomingNodes(nodeInfos)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not get upcoming nodes: "))
+	}
+	currentNodeCount := len(nodes) + len(upcomingNodes)
+
 	resourcesLeft, aErr := o.resourceManager.ResourcesLeft(o.autoscalingContext, nodeInfos, nodes)
 	if aErr != nil {
 		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not compute total resources: "))
 	}
 
+	costLeft, aErr := o.costLimiter.BudgetLeft(o.autoscalingContext, nodes, upcomingNodes, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not compute hourly cost budget: "))
+	}
+
 	for _, ng := range nodeGroups {
 		if !ng.Exist() {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: NodeGroup %s does not exist", ng.Id())
+			klog.Warningf("%s: NodeGroup %s does not exist", caller, ng.Id())
 			continue
 		}
 
 		targetSize, err := ng.TargetSize()
 		if err != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to get target size of node group %s", ng.Id())
+			klog.Warningf("%s: failed to get target size of node group %s", caller, ng.Id())
+			continue
+		}
+		if targetSize >= ng.MaxSize() {
+			klog.V(4).Infof("%s: node group %s is at max size", caller, ng.Id())
 			continue
 		}
 
-		klog.V(4).Infof("ScaleUpToNodeGroupMinSize: NodeGroup %s, TargetSize %d, MinSize %d, MaxSize %d", ng.Id(), targetSize, ng.MinSize(), ng.MaxSize())
-		if targetSize >= ng.MinSize() {
+		count := newNodeCount(ng, targetSize, currentNodeCount)
+		if count <= 0 {
 			continue
 		}
 
 		if skipReason := o.IsNodeGroupReadyToScaleUp(ng, now); skipReason != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: node group is ready to scale up: %v", skipReason)
+			klog.Warningf("%s: node group %s is not ready to scale up: %v", caller, ng.Id(), skipReason)
 			continue
 		}
 
 		nodeInfo, found := nodeInfos[ng.Id()]
 		if !found {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: no node info for %s", ng.Id())
+			klog.Warningf("%s: no node info for %s", caller, ng.Id())
 			continue
 		}
 
 		if skipReason := o.IsNodeGroupResourceExceeded(resourcesLeft, ng, nodeInfo, 1); skipReason != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: node group resource excceded: %v", skipReason)
+			klog.Warningf("%s: node group resource exceeded: %v", caller, skipReason)
+			continue
+		}
+
+		count, err = o.resourceManager.ApplyLimits(o.autoscalingContext, count, resourcesLeft, nodeInfo, ng)
+		if err != nil {
+			klog.Warningf("%s: failed to apply resource limits: %v", caller, err)
 			continue
 		}
 
-		newNodeCount := ng.MinSize() - targetSize
-		newNodeCount, err = o.resourceManager.ApplyLimits(o.autoscalingContext, newNodeCount, resourcesLeft, nodeInfo, ng)
+		count, err = o.costLimiter.ApplyLimit(o.autoscalingContext, ng, count, costLeft, nodeInfo, now)
 		if err != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to apply resource limits: %v", err)
+			klog.Warningf("%s: failed to apply max hourly cost: %v", caller, err)
+			continue
+		}
+		if count <= 0 {
 			continue
 		}
 
-		newNodeCount, err = o.GetCappedNewNodeCount(newNodeCount, targetSize)
+		count, err = o.GetCappedNewNodeCount(count, currentNodeCount)
 		if err != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to get capped node count: %v", err)
+			klog.Warningf("%s: failed to get capped node count: %v", caller, err)
 			continue
 		}
 
 		info := nodegroupset.ScaleUpInfo{
 			Group:       ng,
 			CurrentSize: targetSize,
-			NewSize:     targetSize + newNodeCount,
+			NewSize:     min(targetSize+count, ng.MaxSize()),
 			MaxSize:     ng.MaxSize(),
 		}
 		scaleUpInfos = append(scaleUpInfos, info)
+
+		// The following node groups are limited by what's left after this scale-up.
+		count = info.NewSize - info.CurrentSize
+		currentNodeCount += count
+		costLeft = o.subtractScaleUp(resourcesLeft, costLeft, ng, nodeInfo, count, now)
 	}
 
 	if len(scaleUpInfos) == 0 {
-		klog.V(1).Info("ScaleUpToNodeGroupMinSize: scale up not needed")
+		klog.V(1).Infof("%s: scale up not needed", caller)
 		return &status.ScaleUpStatus{Result: status.ScaleUpNotNeeded}, nil
 	}
 
-	klog.V(1).Infof("ScaleUpToNodeGroupMinSize: final scale-up plan: %v", scaleUpInfos)
+	// Smooth bursts of scale-ups by limiting the number of nodes added per minute.
+	scaleUpInfos, aErr = o.rateLimiter.ApplyLimits(scaleUpInfos, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr)
+	}
+	if len(scaleUpInfos) == 0 {
+		klog.V(1).Infof("%s: not attempting scale-up, max new nodes per minute reached", caller)
+		return &status.ScaleUpStatus{Result: status.ScaleUpInCooldown, ConsideredNodeGroups: nodeGroups}, nil
+	}
+
+	klog.V(1).Infof("%s: final scale-up plan: %v", caller, scaleUpInfos)
+	if err := o.processors.ActuationHooks.BeforeScaleUp(o.autoscalingContext, scaleUpInfos); err != nil {
+		o.logScaleUpVetoed(err)
+		return &status.ScaleUpStatus{Result: status.ScaleUpNotTried, ConsideredNodeGroups: nodeGroups}, nil
+	}
 	aErr, failedNodeGroups := o.scaleUpExecutor.ExecuteScaleUps(scaleUpInfos, nodeInfos, now, false /* allOrNothing disabled */)
+	o.processors.ActuationHooks.AfterScaleUp(o.autoscalingContext, scaleUpInfos, failedNodeGroups, aErr)
 	if aErr != nil {
 		return status.UpdateScaleUpError(
 			&status.ScaleUpStatus{
@@ -416,11 +593,40 @@ func (o *ScaleUpOrchestrator) ScaleUpToNodeGroupMinSize(
 	}, nil
 }
 
+// subtractScaleUp subtracts the resources of count new nodes of the node group from resourcesLeft, and returns the
+// hourly cost budget left after adding them.
+func (o *ScaleUpOrchestrator) subtractScaleUp(
+	resourcesLeft resource.Limits,
+	costLeft float64,
+	ng cloudprovider.NodeGroup,
+	nodeInfo *schedulerframework.NodeInfo,
+	count int,
+	now time.Time,
+) float64 {
+	if delta, err := o.resourceManager.DeltaForNode(o.autoscalingContext, nodeInfo, ng); err == nil {
+		for r, resourceDelta := range delta {
+			if limit, found := resourcesLeft[r]; found && limit != resource.LimitUnknown {
+				resourcesLeft[r] = max(limit-int64(count)*resourceDelta, 0)
+			}
+		}
+	}
+	if cost, err := o.costLimiter.Cost(o.autoscalingContext, ng, count, nodeInfo, now); err == nil {
+		costLeft = math.Max(costLeft-cost, 0)
+	}
+	return costLeft
+}
+
+func (o *ScaleUpOrchestrator) logScaleUpVetoed(err error) {
+	klog.Warningf("Scale-up not executed: %v", err)
+	o.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeWarning, "ScaleUpVetoed", "Scale-up not executed: %v", err)
+}
+
 // filterValidScaleUpNodeGroups filters the node groups that are valid for scale-up
 func (o *ScaleUpOrchestrator) filterValidScaleUpNodeGroups(
 	nodeGroups []cloudprovider.NodeGroup,
 	nodeInfos map[string]*schedulerframework.NodeInfo,
 	resourcesLeft resource.Limits,
+	costLeft float64,
 	currentNodeCount int,
 	now time.Time,
 ) ([]cloudprovider.NodeGroup, map[string]status.Reasons) {
@@ -468,6 +674,15 @@ func (o *ScaleUpOrchestrator) filterValidScaleUpNodeGroups(
 			skippedNodeGroups[nodeGroup.Id()] = skipReason
 			continue
 		}
+		if maxNewNodes, err := o.costLimiter.MaxNewNodes(o.autoscalingContext, nodeGroup, costLeft, nodeInfo, now); err != nil {
+			klog.Errorf("Couldn't apply max hourly cost to node group %s: %v", nodeGroup.Id(), err)
+			skippedNodeGroups[nodeGroup.Id()] = NotReadyReason
+			continue
+		} else if maxNewNodes < numNodes {
+			klog.V(4).Infof("Skipping node group %s - max hourly cost reached", nodeGroup.Id())
+			skippedNodeGroups[nodeGroup.Id()] = MaxHourlyCostReachedReason
+			continue
+		}
 
 		validNodeGroups = append(validNodeGroups, nodeGroup)
 	}
@@ -492,16 +707,83 @@ func (o *ScaleUpOrchestrator) ComputeExpansionOption(
 	}
 
 	option.SimilarNodeGroups = o.ComputeSimilarNodeGroups(nodeGroup, nodeInfos, schedulablePodGroups, now)
+	o.estimateExpansionOption(&option, o.autoscalingContext.ClusterSnapshot, podGroups, nodeInfo, currentNodeCount)
+	o.applyZeroOrMaxNodeScaling(&option, allOrNothing)
+
+	return option
+}
+
+// computeExpansionOptionsInParallel computes the expansion options of the node groups, by node group id. The options
+// are estimated by ParallelEstimationWorkers workers, each in a concurrent fork of the cluster snapshot.
+func (o *ScaleUpOrchestrator) computeExpansionOptionsInParallel(
+	nodeGroups []cloudprovider.NodeGroup,
+	schedulablePodGroups map[string][]estimator.PodEquivalenceGroup,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	currentNodeCount int,
+	now time.Time,
+	allOrNothing bool,
+) map[string]expander.Option {
+	options := make([]expander.Option, len(nodeGroups))
+	var toEstimate []int
+	for i, nodeGroup := range nodeGroups {
+		options[i] = expander.Option{NodeGroup: nodeGroup}
+		if len(schedulablePodGroups[nodeGroup.Id()]) > 0 {
+			options[i].SimilarNodeGroups = o.ComputeSimilarNodeGroups(nodeGroup, nodeInfos, schedulablePodGroups, now)
+			toEstimate = append(toEstimate, i)
+		}
+	}
+
+	indexes := make(chan int, len(toEstimate))
+	for _, i := range toEstimate {
+		indexes <- i
+	}
+	close(indexes)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(o.autoscalingContext.ParallelEstimationWorkers, len(toEstimate)); worker++ {
+		clusterSnapshot := o.autoscalingContext.ClusterSnapshot.ConcurrentFork()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				id := nodeGroups[i].Id()
+				o.estimateExpansionOption(&options[i], clusterSnapshot, schedulablePodGroups[id], nodeInfos[id], currentNodeCount)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, i := range toEstimate {
+		o.applyZeroOrMaxNodeScaling(&options[i], allOrNothing)
+	}
+	optionsByID := make(map[string]expander.Option, len(options))
+	for _, option := range options {
+		optionsByID[option.NodeGroup.Id()] = option
+	}
+	return optionsByID
+}
 
+// estimateExpansionOption estimates the number of nodes the option needs to schedule the pods in the cluster snapshot.
+func (o *ScaleUpOrchestrator) estimateExpansionOption(
+	option *expander.Option,
+	clusterSnapshot clustersnapshot.ClusterSnapshot,
+	podGroups []estimator.PodEquivalenceGroup,
+	nodeInfo *schedulerframework.NodeInfo,
+	currentNodeCount int,
+) {
 	estimateStart := time.Now()
 	expansionEstimator := o.estimatorBuilder(
 		o.autoscalingContext.PredicateChecker,
-		o.autoscalingContext.ClusterSnapshot,
+		clusterSnapshot,
 		estimator.NewEstimationContext(o.autoscalingContext.MaxNodesTotal, option.SimilarNodeGroups, currentNodeCount),
 	)
-	option.NodeCount, option.Pods = expansionEstimator.Estimate(podGroups, nodeInfo, nodeGroup)
+	option.NodeCount, option.Pods = expansionEstimator.Estimate(podGroups, nodeInfo, option.NodeGroup)
 	metrics.UpdateDurationFromStart(metrics.Estimate, estimateStart)
+}
 
+// applyZeroOrMaxNodeScaling caps or increases the node count of options of node groups that only scale from zero to
+// max to the max size of the node group.
+func (o *ScaleUpOrchestrator) applyZeroOrMaxNodeScaling(option *expander.Option, allOrNothing bool) {
+	nodeGroup := option.NodeGroup
 	autoscalingOptions, err := nodeGroup.GetOptions(o.autoscalingContext.NodeGroupDefaults)
 	if err != nil && err != cloudprovider.ErrNotImplemented {
 		klog.Errorf("Failed to get autoscaling options for node group %s: %v", nodeGroup.Id(), err)
@@ -521,8 +803,6 @@ func (o *ScaleUpOrchestrator) ComputeExpansionOption(
 			option.NodeCount = nodeGroup.MaxSize()
 		}
 	}
-
-	return option
 }
 
 // CreateNodeGroup will try to create a new node group based on the initialOption.
@@ -44,6 +44,8 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfosprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
@@ -1226,6 +1228,77 @@ func TestBinpackingLimiter(t *testing.T) {
 	assert.True(t, len(expansionOptions) == 1)
 }
 
+func TestParallelEstimation(t *testing.T) {
+	now := time.Now()
+	var nodes []*apiv1.Node
+	// Node groups of increasingly large nodes, needing fewer and fewer nodes for the pods.
+	for i := 1; i <= 8; i++ {
+		node := BuildTestNode(fmt.Sprintf("n%d", i), int64(i*1000), 100000)
+		SetNodeReadyState(node, true, now.Add(-2*time.Minute))
+		nodes = append(nodes, node)
+	}
+	var extraPods []*apiv1.Pod
+	for i := 0; i < 20; i++ {
+		extraPods = append(extraPods, BuildTestPod(fmt.Sprintf("p-new-%d", i), 900, 0))
+	}
+
+	scaleUpOptions := func(workers int, binpackingLimiter bool) ([]GroupSizeChange, int) {
+		provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+			return nil
+		}, nil)
+		for i, node := range nodes {
+			provider.AddNodeGroup(fmt.Sprintf("ng%d", i+1), 1, 100, 1)
+			provider.AddNode(fmt.Sprintf("ng%d", i+1), node)
+		}
+		podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+		listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+		options := defaultOptions
+		options.ParallelEstimationWorkers = workers
+		context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+		assert.NoError(t, err)
+
+		nodeInfos, err := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).
+			Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, now)
+		assert.NoError(t, err)
+
+		clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+		clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+		processors := NewTestProcessors(&context)
+		if binpackingLimiter {
+			processors.BinpackingLimiter = &MockBinpackingLimiter{}
+		}
+		var estimations atomic.Int32
+		estimatorBuilder := newEstimatorBuilder()
+		countingEstimatorBuilder := func(predicateChecker predicatechecker.PredicateChecker, clusterSnapshot clustersnapshot.ClusterSnapshot, context estimator.EstimationContext) estimator.Estimator {
+			estimations.Add(1)
+			return estimatorBuilder(predicateChecker, clusterSnapshot, context)
+		}
+		suOrchestrator := New()
+		suOrchestrator.Initialize(&context, processors, clusterState, countingEstimatorBuilder, taints.TaintConfig{})
+		expander := NewMockRepotingStrategy(t, nil)
+		context.ExpanderStrategy = expander
+
+		scaleUpStatus, err := suOrchestrator.ScaleUp(extraPods, nodes, []*appsv1.DaemonSet{}, nodeInfos, false)
+		assert.NoError(t, err)
+		assert.True(t, scaleUpStatus.WasSuccessful())
+		return expander.LastInputOptions(), int(estimations.Load())
+	}
+
+	serialOptions, _ := scaleUpOptions(1, false)
+	assert.Len(t, serialOptions, 8)
+	// Options are estimated the same way.
+	parallelOptions, _ := scaleUpOptions(4, false)
+	assert.ElementsMatch(t, serialOptions, parallelOptions)
+	parallelOptions, _ = scaleUpOptions(16, false)
+	assert.ElementsMatch(t, serialOptions, parallelOptions)
+	// The binpacking limiter still stops after the first option, and no node group past the first batch is estimated.
+	limitedOptions, estimations := scaleUpOptions(4, true)
+	assert.Len(t, limitedOptions, 1)
+	assert.Subset(t, serialOptions, limitedOptions)
+	assert.Equal(t, 4, estimations)
+}
+
 func TestScaleUpNoHelp(t *testing.T) {
 	n1 := BuildTestNode("n1", 100, 1000)
 	now := time.Now()
@@ -1664,6 +1737,150 @@ func TestScaleUpToMeetNodeGroupMinSize(t *testing.T) {
 	assert.Equal(t, "ng1", scaleUpStatus.ScaleUpInfos[0].Group.Id())
 }
 
+func TestScaleUpForHeadroom(t *testing.T) {
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+		assert.Equal(t, "ng1", nodeGroup)
+		assert.Equal(t, 2, increase)
+		return nil
+	}, nil)
+
+	// ng1: 3 placeholders of 1 core don't fit, nodes have 2 cores => scale up with 2 new nodes.
+	// ng2: no placeholders => no scale up.
+	n1 := BuildTestNode("n1", 2000, 1000)
+	SetNodeReadyState(n1, true, time.Now())
+	n2 := BuildTestNode("n2", 2000, 1000)
+	SetNodeReadyState(n2, true, time.Now())
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNode("ng2", n2)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:  estimator.BinpackingEstimatorName,
+		MaxCoresTotal:  config.DefaultMaxClusterCores,
+		MaxMemoryTotal: config.DefaultMaxClusterMemory,
+	}
+	context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+	assert.NoError(t, err)
+
+	nodes := []*apiv1.Node{n1, n2}
+	nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+	processors := NewTestProcessors(&context)
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	placeholders := map[string][]*apiv1.Pod{"ng1": {
+		BuildTestPod("placeholder-0", 1000, 0),
+		BuildTestPod("placeholder-1", 1000, 0),
+		BuildTestPod("placeholder-2", 1000, 0),
+	}}
+
+	suOrchestrator := New()
+	suOrchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+	scaleUpStatus, err := suOrchestrator.ScaleUpForHeadroom(placeholders, nodes, nodeInfos)
+	assert.NoError(t, err)
+	assert.True(t, scaleUpStatus.WasSuccessful())
+	assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+	assert.Equal(t, 3, scaleUpStatus.ScaleUpInfos[0].NewSize)
+	assert.Equal(t, "ng1", scaleUpStatus.ScaleUpInfos[0].Group.Id())
+}
+
+type flatPricingModel struct {
+	nodePrice float64
+}
+
+func (m *flatPricingModel) NodePrice(_ *apiv1.Node, start, end time.Time) (float64, error) {
+	return m.nodePrice * end.Sub(start).Hours(), nil
+}
+
+func (m *flatPricingModel) PodPrice(*apiv1.Pod, time.Time, time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestScaleUpForHeadroomLimits(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		maxNewNodesPerMinute int
+		maxHourlyCost        float64
+		wantResult           status.ScaleUpResult
+		wantNewSize          int
+	}{
+		{
+			name:                 "max new nodes per minute",
+			maxNewNodesPerMinute: 1,
+			wantResult:           status.ScaleUpSuccessful,
+			wantNewSize:          2,
+		},
+		{
+			name:          "max hourly cost",
+			maxHourlyCost: 3,
+			wantResult:    status.ScaleUpSuccessful,
+			wantNewSize:   2,
+		},
+		{
+			name:          "max hourly cost reached",
+			maxHourlyCost: 2,
+			wantResult:    status.ScaleUpNotNeeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+			listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+			provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+				assert.Equal(t, "ng1", nodeGroup)
+				assert.Equal(t, tc.wantNewSize-1, increase)
+				return nil
+			}, nil)
+			provider.SetPricingModel(&flatPricingModel{nodePrice: 1})
+
+			// 3 placeholders of 1 core need 2 new nodes of 2 cores, the limits only allow 1 or none.
+			n1 := BuildTestNode("n1", 2000, 1000)
+			SetNodeReadyState(n1, true, time.Now())
+			n2 := BuildTestNode("n2", 2000, 1000)
+			SetNodeReadyState(n2, true, time.Now())
+			provider.AddNodeGroup("ng1", 1, 10, 1)
+			provider.AddNode("ng1", n1)
+			provider.AddNodeGroup("ng2", 1, 10, 1)
+			provider.AddNode("ng2", n2)
+
+			options := config.AutoscalingOptions{
+				EstimatorName:        estimator.BinpackingEstimatorName,
+				MaxCoresTotal:        config.DefaultMaxClusterCores,
+				MaxMemoryTotal:       config.DefaultMaxClusterMemory,
+				MaxNewNodesPerMinute: tc.maxNewNodesPerMinute,
+				MaxHourlyCost:        tc.maxHourlyCost,
+			}
+			context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+			assert.NoError(t, err)
+
+			nodes := []*apiv1.Node{n1, n2}
+			nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+			processors := NewTestProcessors(&context)
+			clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+			clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+			placeholders := map[string][]*apiv1.Pod{"ng1": {
+				BuildTestPod("placeholder-0", 1000, 0),
+				BuildTestPod("placeholder-1", 1000, 0),
+				BuildTestPod("placeholder-2", 1000, 0),
+			}}
+
+			suOrchestrator := New()
+			suOrchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+			scaleUpStatus, err := suOrchestrator.ScaleUpForHeadroom(placeholders, nodes, nodeInfos)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantResult, scaleUpStatus.Result)
+			if tc.wantNewSize > 0 {
+				assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+				assert.Equal(t, tc.wantNewSize, scaleUpStatus.ScaleUpInfos[0].NewSize)
+			}
+		})
+	}
+}
+
 func TestCheckDeltaWithinLimits(t *testing.T) {
 	type testcase struct {
 		limits            resource.Limits
@@ -43,6 +43,8 @@ var (
 	MaxLimitReachedReason = NewSkippedReasons("max node group size reached")
 	// NotReadyReason node group is not ready.
 	NotReadyReason = NewSkippedReasons("not ready for scale-up")
+	// MaxHourlyCostReachedReason adding a node to the node group would exceed the max hourly cost of the cluster.
+	MaxHourlyCostReachedReason = NewSkippedReasons("max cluster hourly cost reached")
 )
 
 // MaxResourceLimitReached contains information why given node group was skipped.
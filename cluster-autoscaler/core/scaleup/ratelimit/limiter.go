@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	klog "k8s.io/klog/v2"
+)
+
+// window is the period the new node limits apply to.
+const window = time.Minute
+
+type scaleUp struct {
+	nodeGroupId string
+	delta       int
+	time        time.Time
+}
+
+// Limiter limits the number of nodes added to the cluster and to each node group per minute,
+// smoothing bursts of scale-ups. It observes scale-ups to know how many nodes were added recently.
+// A limit of 0 means no limit.
+type Limiter struct {
+	maxNewNodesPerMinute     int
+	nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor
+	scaleUps                 []scaleUp
+	mutex                    sync.Mutex
+}
+
+// NewLimiter returns a Limiter with the given cluster-wide limit. Limits of node groups
+// come from the NodeGroupConfigProcessor.
+func NewLimiter(maxNewNodesPerMinute int, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) *Limiter {
+	return &Limiter{
+		maxNewNodesPerMinute:     maxNewNodesPerMinute,
+		nodeGroupConfigProcessor: nodeGroupConfigProcessor,
+	}
+}
+
+// ApplyLimits caps the scale-ups to the number of nodes that can still be added in the current
+// minute, first to each node group and then to the cluster. Scale-ups that can't add any node
+// are dropped, and so are scale-ups of node groups that scale all at once if they can't add all
+// their nodes.
+func (l *Limiter) ApplyLimits(scaleUpInfos []nodegroupset.ScaleUpInfo, now time.Time) ([]nodegroupset.ScaleUpInfo, errors.AutoscalerError) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.prune(now)
+
+	clusterLeft := -1
+	if l.maxNewNodesPerMinute > 0 {
+		clusterLeft = max(l.maxNewNodesPerMinute-l.added(""), 0)
+	}
+	var result []nodegroupset.ScaleUpInfo
+	for _, info := range scaleUpInfos {
+		increase := info.NewSize - info.CurrentSize
+		maxNewNodes, err := l.nodeGroupConfigProcessor.GetMaxNewNodesPerMinute(info.Group)
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		if maxNewNodes > 0 {
+			increase = min(increase, max(maxNewNodes-l.added(info.Group.Id()), 0))
+		}
+		if clusterLeft >= 0 {
+			increase = min(increase, clusterLeft)
+		}
+		if increase < info.NewSize-info.CurrentSize {
+			zeroOrMaxNodeScaling, err := l.nodeGroupConfigProcessor.GetZeroOrMaxNodeScaling(info.Group)
+			if err != nil {
+				return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+			}
+			if zeroOrMaxNodeScaling {
+				klog.V(1).Infof("Scale-up of %s dropped, its %d new nodes are more than the max new nodes per minute", info.Group.Id(), info.NewSize-info.CurrentSize)
+				continue
+			}
+			klog.V(1).Infof("Scale-up of %s limited to %d new nodes by the max new nodes per minute", info.Group.Id(), increase)
+		}
+		if increase <= 0 {
+			continue
+		}
+		if clusterLeft >= 0 {
+			clusterLeft -= increase
+		}
+		info.NewSize = info.CurrentSize + increase
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// RegisterScaleUp records the scale-up of a node group.
+func (l *Limiter) RegisterScaleUp(nodeGroup cloudprovider.NodeGroup, delta int, currentTime time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.scaleUps = append(l.scaleUps, scaleUp{nodeGroupId: nodeGroup.Id(), delta: delta, time: currentTime})
+}
+
+// RegisterScaleDown is a no-op.
+func (l *Limiter) RegisterScaleDown(_ cloudprovider.NodeGroup, _ string, _ time.Time, _ time.Time) {
+}
+
+// RegisterFailedScaleUp is a no-op, the nodes of failed scale-ups still count towards the limits.
+func (l *Limiter) RegisterFailedScaleUp(_ cloudprovider.NodeGroup, _ string, _ string, _, _ string, _ time.Time) {
+}
+
+// RegisterFailedScaleDown is a no-op.
+func (l *Limiter) RegisterFailedScaleDown(_ cloudprovider.NodeGroup, _ string, _ time.Time) {
+}
+
+// added returns the number of nodes added to the node group in the current window,
+// or to the whole cluster if nodeGroupId is empty.
+func (l *Limiter) added(nodeGroupId string) int {
+	added := 0
+	for _, su := range l.scaleUps {
+		if nodeGroupId == "" || su.nodeGroupId == nodeGroupId {
+			added += su.delta
+		}
+	}
+	return added
+}
+
+func (l *Limiter) prune(now time.Time) {
+	var scaleUps []scaleUp
+	for _, su := range l.scaleUps {
+		if now.Sub(su.time) < window {
+			scaleUps = append(scaleUps, su)
+		}
+	}
+	l.scaleUps = scaleUps
+}
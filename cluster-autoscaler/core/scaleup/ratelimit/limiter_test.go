@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+)
+
+func TestApplyLimits(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 100, 10)
+	provider.AddNodeGroupWithCustomOptions("ng2", 0, 100, 10, &config.NodeGroupAutoscalingOptions{MaxNewNodesPerMinute: 3})
+	ng1 := provider.GetNodeGroup("ng1")
+	provider.AddNodeGroupWithCustomOptions("atomic", 0, 100, 0, &config.NodeGroupAutoscalingOptions{ZeroOrMaxNodeScaling: true})
+	ng2 := provider.GetNodeGroup("ng2")
+	atomic := provider.GetNodeGroup("atomic")
+	now := time.Now()
+
+	testCases := []struct {
+		name                 string
+		maxNewNodesPerMinute int
+		defaults             config.NodeGroupAutoscalingOptions
+		scaleUps             []scaleUp
+		scaleUpInfos         []nodegroupset.ScaleUpInfo
+		want                 []nodegroupset.ScaleUpInfo
+	}{
+		{
+			name:         "no limits",
+			scaleUps:     []scaleUp{{nodeGroupId: "ng1", delta: 50, time: now}},
+			scaleUpInfos: []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 60, MaxSize: 100}},
+			want:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 60, MaxSize: 100}},
+		},
+		{
+			name:         "node group limit",
+			defaults:     config.NodeGroupAutoscalingOptions{MaxNewNodesPerMinute: 5},
+			scaleUps:     []scaleUp{{nodeGroupId: "ng1", delta: 2, time: now.Add(-30 * time.Second)}},
+			scaleUpInfos: []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 20, MaxSize: 100}},
+			want:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 13, MaxSize: 100}},
+		},
+		{
+			name:         "node group limit overridden",
+			defaults:     config.NodeGroupAutoscalingOptions{MaxNewNodesPerMinute: 5},
+			scaleUps:     []scaleUp{{nodeGroupId: "ng1", delta: 2, time: now}},
+			scaleUpInfos: []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 20, MaxSize: 100}, {Group: ng2, CurrentSize: 10, NewSize: 20, MaxSize: 100}},
+			want:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 13, MaxSize: 100}, {Group: ng2, CurrentSize: 10, NewSize: 13, MaxSize: 100}},
+		},
+		{
+			name:                 "cluster limit",
+			maxNewNodesPerMinute: 10,
+			scaleUps:             []scaleUp{{nodeGroupId: "ng2", delta: 2, time: now}},
+			scaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 17, MaxSize: 100}, {Group: ng2, CurrentSize: 10, NewSize: 20, MaxSize: 100}},
+			want:                 []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 17, MaxSize: 100}, {Group: ng2, CurrentSize: 10, NewSize: 11, MaxSize: 100}},
+		},
+		{
+			name:                 "atomic scale-up over the limit is dropped",
+			maxNewNodesPerMinute: 10,
+			scaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: atomic, CurrentSize: 0, NewSize: 100, MaxSize: 100}, {Group: ng1, CurrentSize: 10, NewSize: 15, MaxSize: 100}},
+			want:                 []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 15, MaxSize: 100}},
+		},
+		{
+			name:                 "atomic scale-up within the limit",
+			maxNewNodesPerMinute: 100,
+			scaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: atomic, CurrentSize: 0, NewSize: 100, MaxSize: 100}},
+			want:                 []nodegroupset.ScaleUpInfo{{Group: atomic, CurrentSize: 0, NewSize: 100, MaxSize: 100}},
+		},
+		{
+			name:                 "limits reached",
+			maxNewNodesPerMinute: 10,
+			scaleUps:             []scaleUp{{nodeGroupId: "ng1", delta: 10, time: now.Add(-59 * time.Second)}},
+			scaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 10, NewSize: 20, MaxSize: 100}},
+		},
+		{
+			name:                 "old scale-ups don't count",
+			maxNewNodesPerMinute: 10,
+			scaleUps:             []scaleUp{{nodeGroupId: "ng1", delta: 10, time: now.Add(-time.Minute)}},
+			scaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 20, NewSize: 30, MaxSize: 100}},
+			want:                 []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 20, NewSize: 30, MaxSize: 100}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := NewLimiter(tc.maxNewNodesPerMinute, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(tc.defaults))
+			for _, su := range tc.scaleUps {
+				limiter.RegisterScaleUp(provider.GetNodeGroup(su.nodeGroupId), su.delta, su.time)
+			}
+			got, err := limiter.ApplyLimits(tc.scaleUpInfos, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"math"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/core/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/customresources"
@@ -64,6 +66,11 @@ func (m *Manager) DeltaForNode(ctx *context.AutoscalingContext, nodeInfo *schedu
 	nodeCPU, nodeMemory := utils.GetNodeCoresAndMemory(nodeInfo.Node())
 	resultScaleUpDelta[cloudprovider.ResourceNameCores] = nodeCPU
 	resultScaleUpDelta[cloudprovider.ResourceNameMemory] = nodeMemory
+	for _, limit := range ctx.ScopedResourceLimits {
+		if limit.Selector.Matches(labels.Set(nodeInfo.Node().Labels)) {
+			resultScaleUpDelta[ScopedResourceName(limit)] = scopedResource(limit, nodeCPU, nodeMemory)
+		}
+	}
 
 	resourceLimiter, err := ctx.CloudProvider.GetResourceLimiter()
 	if err != nil {
@@ -139,9 +146,30 @@ func (m *Manager) ResourcesLeft(ctx *context.AutoscalingContext, nodeInfos map[s
 		}
 	}
 
+	if len(ctx.ScopedResourceLimits) > 0 {
+		scopedTotals, err := m.scopedResourcesTotal(ctx, nodeInfos, nodesFromNotAutoscaledGroups)
+		if err != nil {
+			return Limits{}, err
+		}
+		for _, limit := range ctx.ScopedResourceLimits {
+			name := ScopedResourceName(limit)
+			left := computeBelowMax(scopedTotals[name], limit.Max)
+			if previous, found := resultScaleUpLimits[name]; found {
+				left = min(left, previous)
+			}
+			resultScaleUpLimits[name] = left
+		}
+	}
+
 	return resultScaleUpLimits, nil
 }
 
+// ScopedResourceName returns the name the scoped resource limit is tracked under in Limits and Delta,
+// e.g. "cpu{nvidia.com/gpu.present=true}".
+func ScopedResourceName(limit config.ScopedResourceLimit) string {
+	return fmt.Sprintf("%s{%s}", limit.Resource, limit.Selector.String())
+}
+
 // ApplyLimits calculates the new node count by applying the left resource limits of the cluster.
 func (m *Manager) ApplyLimits(ctx *context.AutoscalingContext, newCount int, resourceLeft Limits, nodeInfo *schedulerframework.NodeInfo, nodeGroup cloudprovider.NodeGroup) (int, errors.AutoscalerError) {
 	delta, err := m.DeltaForNode(ctx, nodeInfo, nodeGroup)
@@ -278,6 +306,46 @@ func (m *Manager) customResourcesTotal(ctx *context.AutoscalingContext, nodeInfo
 	return result, nil
 }
 
+func (m *Manager) scopedResourcesTotal(ctx *context.AutoscalingContext, nodeInfos map[string]*schedulerframework.NodeInfo, nodesFromNotAutoscaledGroups []*corev1.Node) (map[string]int64, errors.AutoscalerError) {
+	result := make(map[string]int64)
+	add := func(node *corev1.Node, count int64) {
+		nodeCPU, nodeMemory := utils.GetNodeCoresAndMemory(node)
+		for _, limit := range ctx.ScopedResourceLimits {
+			if limit.Selector.Matches(labels.Set(node.Labels)) {
+				result[ScopedResourceName(limit)] += count * scopedResource(limit, nodeCPU, nodeMemory)
+			}
+		}
+	}
+	for _, nodeGroup := range ctx.CloudProvider.NodeGroups() {
+		currentSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err).AddPrefix("failed to get node group size of %v: ", nodeGroup.Id())
+		}
+
+		nodeInfo, found := nodeInfos[nodeGroup.Id()]
+		if !found {
+			return nil, errors.NewAutoscalerError(errors.CloudProviderError, "No node info for: %s", nodeGroup.Id())
+		}
+
+		if currentSize > 0 {
+			add(nodeInfo.Node(), int64(currentSize))
+		}
+	}
+
+	for _, node := range nodesFromNotAutoscaledGroups {
+		add(node, 1)
+	}
+
+	return result, nil
+}
+
+func scopedResource(limit config.ScopedResourceLimit, nodeCPU, nodeMemory int64) int64 {
+	if limit.Resource == cloudprovider.ResourceNameMemory {
+		return nodeMemory
+	}
+	return nodeCPU
+}
+
 func computeBelowMax(total int64, max int64) int64 {
 	if total < max {
 		return max - total
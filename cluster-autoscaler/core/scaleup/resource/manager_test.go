@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
@@ -247,6 +248,45 @@ func TestResourceManagerWithGpuResource(t *testing.T) {
 	assert.Equal(t, 3, newNodeCount) // gpu left / grpu per node: 12 / 4 = 3
 }
 
+func TestResourceManagerWithScopedLimits(t *testing.T) {
+	provider := newCloudProvider(t, 1000, 1000)
+	context := newContext(t, provider)
+	context.ScopedResourceLimits = []config.ScopedResourceLimit{
+		{Resource: cloudprovider.ResourceNameCores, Max: 40, Selector: labels.SelectorFromSet(labels.Set{"accelerator": "gpu"})},
+		{Resource: cloudprovider.ResourceNameMemory, Max: 100, Selector: labels.Everything()},
+	}
+	processors := test.NewTestProcessors(&context)
+
+	gpuGroup, gpuNodes := newNodeGroup(t, provider, "gpu", 0, 10, 3, 8, 16)
+	for _, node := range gpuNodes {
+		node.Labels["accelerator"] = "gpu"
+	}
+	generalGroup, generalNodes := newNodeGroup(t, provider, "general", 0, 10, 2, 4, 8)
+	nodes := append(gpuNodes, generalNodes...)
+	nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+
+	rm := NewManager(processors.CustomResourcesProcessor)
+
+	delta, err := rm.DeltaForNode(&context, nodeInfos["gpu"], gpuGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, Delta{"cpu": 8, "memory": 16, "cpu{accelerator=gpu}": 8, "memory{}": 16}, delta)
+	delta, err = rm.DeltaForNode(&context, nodeInfos["general"], generalGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, Delta{"cpu": 4, "memory": 8, "memory{}": 8}, delta)
+
+	left, err := rm.ResourcesLeft(&context, nodeInfos, nodes)
+	assert.NoError(t, err)
+	// cpu{accelerator=gpu}: 40-8*3=16; memory{}: 100-16*3-8*2=36
+	assert.Equal(t, Limits{"cpu": 968, "memory": 936, "cpu{accelerator=gpu}": 16, "memory{}": 36}, left)
+
+	newNodeCount, err := rm.ApplyLimits(&context, 10, left, nodeInfos["gpu"], gpuGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, newNodeCount) // cpu{accelerator=gpu} left / cpu per node: 16 / 8 = 2
+	newNodeCount, err = rm.ApplyLimits(&context, 10, left, nodeInfos["general"], generalGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, newNodeCount) // memory{} left / memory per node: 36 / 8 = 4
+}
+
 func newCloudProvider(t *testing.T, cpu, mem int64) *testprovider.TestCloudProvider {
 	provider := testprovider.NewTestCloudProvider(nil, nil)
 	assert.NotNil(t, provider)
@@ -58,4 +58,13 @@ type Orchestrator interface {
 		nodes []*apiv1.Node,
 		nodeInfos map[string]*schedulerframework.NodeInfo,
 	) (*status.ScaleUpStatus, errors.AutoscalerError)
+	// ScaleUpForHeadroom tries to scale up node groups by the number of nodes
+	// needed to fit the placeholder pods reserving their headroom that don't
+	// fit in the cluster, by node group id. Returns appropriate status or error
+	// if an unexpected error occurred.
+	ScaleUpForHeadroom(
+		placeholders map[string][]*apiv1.Pod,
+		nodes []*apiv1.Node,
+		nodeInfos map[string]*schedulerframework.NodeInfo,
+	) (*status.ScaleUpStatus, errors.AutoscalerError)
 }
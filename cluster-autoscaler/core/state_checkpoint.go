@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	klog "k8s.io/klog/v2"
+)
+
+// stateCheckpointConfigMapKey is the key of the checkpointed state in the ConfigMap.
+const stateCheckpointConfigMapKey = "state"
+
+// checkpointedState is the state of the autoscaler that a new leader or a restarted CA carries over, so
+// that it doesn't start counting the unneeded time of nodes from zero, scale up backed off or unhealthy
+// node groups or lose track of scale-ups that may never finish.
+type checkpointedState struct {
+	Time              time.Time                                        `json:"time"`
+	UnneededSince     map[string]time.Time                             `json:"unneededSince,omitempty"`
+	Backoff           map[string]backoff.Checkpoint                    `json:"backoff,omitempty"`
+	ScaleUpRequests   map[string]clusterstate.ScaleUpRequestCheckpoint `json:"scaleUpRequests,omitempty"`
+	UnregisteredSince map[string]time.Time                             `json:"unregisteredSince,omitempty"`
+}
+
+// stateCheckpoint saves the state of the autoscaler to a ConfigMap after every loop and
+// restores it in the first loop, e.g. after a leader failover or a restart.
+type stateCheckpoint struct {
+	configMaps    corev1client.ConfigMapInterface
+	configMapName string
+	// maxAge is how old a checkpoint can be to be restored. An older checkpoint may hold scale-ups
+	// that finished or nodes that registered since, so nothing is restored from it.
+	maxAge time.Duration
+	// planner, backoff and clusterState are nil if their state can't be checkpointed.
+	planner      scaledown.UnneededSinceCheckpointer
+	backoff      backoff.Checkpointer
+	clusterState *clusterstate.ClusterStateRegistry
+	restored     bool
+	// lastValue and lastSaved are the last state saved and when, to skip saving the same state every loop.
+	lastValue string
+	lastSaved time.Time
+}
+
+func newStateCheckpoint(configMaps corev1client.ConfigMapInterface, configMapName string, maxAge time.Duration, planner scaledown.Planner, b backoff.Backoff, clusterState *clusterstate.ClusterStateRegistry) *stateCheckpoint {
+	s := &stateCheckpoint{
+		configMaps:    configMaps,
+		configMapName: configMapName,
+		maxAge:        maxAge,
+		clusterState:  clusterState,
+	}
+	if checkpointer, ok := planner.(scaledown.UnneededSinceCheckpointer); ok {
+		s.planner = checkpointer
+	} else {
+		klog.Warningf("Scale down planner doesn't support checkpointing, unneeded times of nodes won't be carried over")
+	}
+	if checkpointer, ok := b.(backoff.Checkpointer); ok {
+		s.backoff = checkpointer
+	} else {
+		klog.Warningf("Node group backoff doesn't support checkpointing, it won't be carried over")
+	}
+	return s
+}
+
+// restore restores the checkpointed state the first time it's called, if the checkpoint is recent enough.
+func (s *stateCheckpoint) restore(now time.Time) {
+	if s.restored {
+		return
+	}
+	s.restored = true
+	configMap, err := s.configMaps.Get(context.TODO(), s.configMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		klog.V(1).Infof("State checkpoint configmap %s not found, starting with an empty state", s.configMapName)
+		return
+	}
+	if err != nil {
+		klog.Errorf("Failed to get state checkpoint configmap %s, starting with an empty state: %v", s.configMapName, err)
+		return
+	}
+	var state checkpointedState
+	if err := json.Unmarshal([]byte(configMap.Data[stateCheckpointConfigMapKey]), &state); err != nil {
+		klog.Errorf("Failed to parse state checkpoint configmap %s, starting with an empty state: %v", s.configMapName, err)
+		return
+	}
+	if age := now.Sub(state.Time); age > s.maxAge {
+		klog.V(1).Infof("State checkpoint in configmap %s is %v old, more than %v, starting with an empty state", s.configMapName, age, s.maxAge)
+		return
+	}
+	if s.planner != nil && state.UnneededSince != nil {
+		s.planner.RestoreUnneededSince(state.UnneededSince)
+	}
+	if s.backoff != nil {
+		s.backoff.Restore(state.Backoff)
+	}
+	if s.clusterState != nil {
+		s.clusterState.RestoreScaleUpRequests(state.ScaleUpRequests)
+		s.clusterState.RestoreUnregisteredSince(state.UnregisteredSince)
+	}
+	klog.V(1).Infof("Restored state checkpoint from configmap %s: %d unneeded nodes, %d node group backoffs, %d scale-ups in progress, %d unregistered nodes",
+		s.configMapName, len(state.UnneededSince), len(state.Backoff), len(state.ScaleUpRequests), len(state.UnregisteredSince))
+}
+
+// save saves the current state if it changed since it was last saved, or if the saved state is getting old.
+func (s *stateCheckpoint) save(now time.Time) {
+	var state checkpointedState
+	if s.planner != nil {
+		state.UnneededSince = s.planner.UnneededSince()
+	}
+	if s.backoff != nil {
+		state.Backoff = s.backoff.Checkpoint()
+	}
+	if s.clusterState != nil {
+		state.ScaleUpRequests = s.clusterState.ScaleUpRequestsCheckpoint()
+		state.UnregisteredSince = s.clusterState.UnregisteredSinceCheckpoint()
+	}
+	value, err := json.Marshal(state)
+	if err != nil {
+		klog.Errorf("Failed to marshal state checkpoint: %v", err)
+		return
+	}
+	if string(value) == s.lastValue && now.Sub(s.lastSaved) < s.maxAge/2 {
+		return
+	}
+	state.Time = now
+	data, err := json.Marshal(state)
+	if err != nil {
+		klog.Errorf("Failed to marshal state checkpoint: %v", err)
+		return
+	}
+	if err := s.writeConfigMap(string(data)); err != nil {
+		klog.Errorf("Failed to write state checkpoint configmap %s: %v", s.configMapName, err)
+		return
+	}
+	s.lastValue = string(value)
+	s.lastSaved = now
+}
+
+func (s *stateCheckpoint) writeConfigMap(data string) error {
+	configMap, err := s.configMaps.Get(context.TODO(), s.configMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		configMap = &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: s.configMapName},
+			Data:       map[string]string{stateCheckpointConfigMapKey: data},
+		}
+		_, err = s.configMaps.Create(context.TODO(), configMap, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[stateCheckpointConfigMapKey] = data
+	_, err = s.configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	return err
+}
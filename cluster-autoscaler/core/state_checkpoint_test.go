@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unneeded"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type checkpointedPlannerMock struct {
+	scaledown.Planner
+	since         map[string]time.Time
+	restoredSince map[string]time.Time
+}
+
+func (p *checkpointedPlannerMock) UnneededSince() map[string]time.Time {
+	return p.since
+}
+
+func (p *checkpointedPlannerMock) RestoreUnneededSince(since map[string]time.Time) {
+	p.restoredSince = since
+}
+
+// unneededNodesPlannerMock checkpoints the unneeded times of nodes tracked by unneeded.Nodes.
+type unneededNodesPlannerMock struct {
+	scaledown.Planner
+	*unneeded.Nodes
+}
+
+func TestStateCheckpoint(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	ng1 := provider.GetNodeGroup("ng1")
+	errorInfo := cloudprovider.InstanceErrorInfo{ErrorClass: cloudprovider.OutOfResourcesErrorClass, ErrorCode: "QUOTA_EXCEEDED"}
+	// Times read back from JSON are in UTC and have no monotonic clock reading.
+	now := time.Now().UTC().Round(0)
+	configMaps := fake.NewSimpleClientset().CoreV1().ConfigMaps("kube-system")
+	newClusterState := func(b backoff.Backoff) *clusterstate.ClusterStateRegistry {
+		return clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, nil, b,
+			nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	}
+
+	oldPlanner := &checkpointedPlannerMock{since: map[string]time.Time{"n1": now.Add(-5 * time.Minute)}}
+	oldBackoff := backoff.NewIdBasedExponentialBackoff(5*time.Minute, time.Hour, 3*time.Hour)
+	oldBackoff.Backoff(ng1, nil, errorInfo, now)
+	oldClusterState := newClusterState(oldBackoff)
+	oldClusterState.RegisterScaleUp(ng1, 2, now)
+	oldClusterState.RestoreUnregisteredSince(map[string]time.Time{"ng1-2": now.Add(-20 * time.Minute)})
+	oldCheckpoint := newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, oldPlanner, oldBackoff, oldClusterState)
+	oldCheckpoint.save(now)
+
+	configMap, err := configMaps.Get(context.TODO(), "ca-state", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, configMap.Data[stateCheckpointConfigMapKey], `"n1"`)
+
+	// The same state isn't saved again.
+	configMap.Data[stateCheckpointConfigMapKey] = "overwritten"
+	_, err = configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	oldCheckpoint.save(now.Add(time.Minute))
+	configMap, err = configMaps.Get(context.TODO(), "ca-state", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "overwritten", configMap.Data[stateCheckpointConfigMapKey])
+
+	// A changed state is saved.
+	oldPlanner.since["n2"] = now
+	oldCheckpoint.save(now.Add(time.Minute))
+
+	// A new leader restores the state.
+	newPlanner := &checkpointedPlannerMock{}
+	newBackoff := backoff.NewIdBasedExponentialBackoff(5*time.Minute, time.Hour, 3*time.Hour)
+	restoredClusterState := newClusterState(newBackoff)
+	newCheckpoint := newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, newPlanner, newBackoff, restoredClusterState)
+	newCheckpoint.restore(now.Add(2 * time.Minute))
+	assert.Equal(t, map[string]time.Time{"n1": now.Add(-5 * time.Minute), "n2": now}, newPlanner.restoredSince)
+	assert.True(t, newBackoff.BackoffStatus(ng1, nil, now.Add(3*time.Minute)).IsBackedOff)
+	assert.Equal(t, oldClusterState.ScaleUpRequestsCheckpoint(), restoredClusterState.ScaleUpRequestsCheckpoint())
+	assert.Equal(t, oldClusterState.UnregisteredSinceCheckpoint(), restoredClusterState.UnregisteredSinceCheckpoint())
+
+	// The state is only restored once.
+	newPlanner.restoredSince = nil
+	newCheckpoint.restore(now.Add(3 * time.Minute))
+	assert.Nil(t, newPlanner.restoredSince)
+
+	// Nothing is restored from an old checkpoint.
+	stalePlanner := &checkpointedPlannerMock{}
+	staleBackoff := backoff.NewIdBasedExponentialBackoff(5*time.Minute, time.Hour, 3*time.Hour)
+	staleClusterState := newClusterState(staleBackoff)
+	staleCheckpoint := newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, stalePlanner, staleBackoff, staleClusterState)
+	staleCheckpoint.restore(now.Add(10 * time.Minute))
+	assert.Nil(t, stalePlanner.restoredSince)
+	assert.Empty(t, staleBackoff.(backoff.Checkpointer).Checkpoint())
+	assert.Empty(t, staleClusterState.ScaleUpRequestsCheckpoint())
+	assert.Empty(t, staleClusterState.UnregisteredSinceCheckpoint())
+
+	// A missing configmap isn't an error.
+	missingPlanner := &checkpointedPlannerMock{}
+	missingCheckpoint := newStateCheckpoint(configMaps, "missing", 5*time.Minute, missingPlanner, nil, nil)
+	missingCheckpoint.restore(now)
+	assert.Nil(t, missingPlanner.restoredSince)
+}
+
+func TestStateCheckpointRestartedTwice(t *testing.T) {
+	now := time.Now().UTC().Round(0)
+	unneededSince := now.Add(-5 * time.Minute)
+	configMaps := fake.NewSimpleClientset().CoreV1().ConfigMaps("kube-system")
+	n1 := simulator.NodeToBeRemoved{Node: BuildTestNode("n1", 1000, 10)}
+
+	firstPlanner := &unneededNodesPlannerMock{Nodes: unneeded.NewNodes(nil, nil)}
+	firstPlanner.Update([]simulator.NodeToBeRemoved{n1}, unneededSince)
+	newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, firstPlanner, nil, nil).save(now)
+
+	// The first loops after a restart save the state before the unneeded nodes are updated.
+	secondPlanner := &unneededNodesPlannerMock{Nodes: unneeded.NewNodes(nil, nil)}
+	secondCheckpoint := newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, secondPlanner, nil, nil)
+	secondCheckpoint.restore(now.Add(time.Minute))
+	secondCheckpoint.save(now.Add(time.Minute))
+	secondCheckpoint.save(now.Add(2 * time.Minute))
+
+	thirdPlanner := &unneededNodesPlannerMock{Nodes: unneeded.NewNodes(nil, nil)}
+	newStateCheckpoint(configMaps, "ca-state", 5*time.Minute, thirdPlanner, nil, nil).restore(now.Add(3 * time.Minute))
+	thirdPlanner.Update([]simulator.NodeToBeRemoved{n1}, now.Add(3*time.Minute))
+	assert.Equal(t, map[string]time.Time{"n1": unneededSince}, thirdPlanner.UnneededSince())
+}
@@ -36,6 +36,7 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
@@ -53,9 +54,12 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/dynamicresources"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	"k8s.io/autoscaler/cluster-autoscaler/tracing"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
 	caerrors "k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	scheduler_utils "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
 	"k8s.io/utils/integer"
@@ -95,6 +99,14 @@ type StaticAutoscaler struct {
 	processorCallbacks      *staticAutoscalerProcessorCallbacks
 	initialized             bool
 	taintConfig             taints.TaintConfig
+	// runtimeConfig is nil if options can't be changed at runtime.
+	runtimeConfig *runtimeConfig
+	// dynamicResources is nil if dynamic resource allocation isn't accounted for in the simulation.
+	dynamicResources *dynamicresources.Provider
+	// lastScaleDownDryRun is the result of the last scale-down dry run, nil if scale-down isn't run dry.
+	lastScaleDownDryRun *api.ScaleDownDryRun
+	// stateCheckpoint is nil if the state isn't saved for a new leader.
+	stateCheckpoint *stateCheckpoint
 }
 
 type staticAutoscalerProcessorCallbacks struct {
@@ -170,6 +182,7 @@ func NewStaticAutoscaler(
 	taintConfig := taints.NewTaintConfig(opts)
 	processors.ScaleDownCandidatesNotifier.Register(clusterStateRegistry)
 	processors.ScaleStateNotifier.Register(clusterStateRegistry)
+	processors.ScaleStateNotifier.Register(debuggingsnapshot.NewFailedScaleUpObserver(debuggingSnapshotter))
 
 	// TODO: Populate the ScaleDownActuator/Planner fields in AutoscalingContext
 	// during the struct creation rather than here.
@@ -293,12 +306,24 @@ func (a *StaticAutoscaler) initializeRemainingPdbTracker() caerrors.AutoscalerEr
 }
 
 // RunOnce iterates over node groups and scales them up/down if necessary
-func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerError {
+func (a *StaticAutoscaler) RunOnce(currentTime time.Time) (runErr caerrors.AutoscalerError) {
 	a.cleanUpIfRequired()
 	a.processorCallbacks.reset()
+	if a.runtimeConfig != nil {
+		a.runtimeConfig.reload(a.AutoscalingContext, a.processors.NodeGroupConfigProcessor)
+	}
+	if a.stateCheckpoint != nil {
+		a.stateCheckpoint.restore(currentTime)
+		defer a.stateCheckpoint.save(currentTime)
+	}
 	a.clusterStateRegistry.PeriodicCleanup()
 	a.DebuggingSnapshotter.StartDataCollection()
-	defer a.DebuggingSnapshotter.Flush()
+	defer func() {
+		if runErr != nil {
+			a.DebuggingSnapshotter.ReportFailure(fmt.Sprintf("loop failed: %v", runErr))
+		}
+		a.DebuggingSnapshotter.Flush()
+	}()
 
 	podLister := a.AllPodLister()
 	autoscalingContext := a.AutoscalingContext
@@ -324,7 +349,19 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		klog.Errorf("Failed to list pods: %v", err)
 		return caerrors.ToAutoscalerError(caerrors.ApiCallError, err)
 	}
+	if a.dynamicResources != nil {
+		draSnapshot, err := a.dynamicResources.Snapshot()
+		if err != nil {
+			klog.Errorf("Failed to list dynamic resources: %v", err)
+			return caerrors.ToAutoscalerError(caerrors.ApiCallError, err)
+		}
+		allNodes, readyNodes = draSnapshot.Nodes(allNodes), draSnapshot.Nodes(readyNodes)
+		pods = draSnapshot.Pods(pods)
+	}
 	originalScheduledPods, unschedulablePods := kube_util.ScheduledPods(pods), kube_util.UnschedulablePods(pods)
+	if len(a.AdditionalSchedulers) > 0 {
+		unschedulablePods = kube_util.PodsOfSchedulers(unschedulablePods, a.AdditionalSchedulers)
+	}
 	schedulerUnprocessed := make([]*apiv1.Pod, 0, 0)
 	isSchedulerProcessingIgnored := len(a.BypassedSchedulers) > 0
 	if isSchedulerProcessingIgnored {
@@ -345,7 +382,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	scaleDownActuationStatus := a.scaleDownActuator.CheckStatus()
 	// Call CloudProvider.Refresh before any other calls to cloud provider.
 	refreshStart := time.Now()
+	refreshSpan := tracing.Start("CloudProvider.Refresh")
 	err = a.AutoscalingContext.CloudProvider.Refresh()
+	refreshSpan.End(err)
 	metrics.UpdateDurationFromStart(metrics.CloudProviderRefresh, refreshStart)
 	if err != nil {
 		klog.Errorf("Failed to refresh cloud provider config: %v", err)
@@ -365,7 +404,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	} else {
 		metrics.UpdateMaxNodesCount(maxNodesCount)
 	}
-	nonExpendableScheduledPods := core_utils.FilterOutExpendablePods(originalScheduledPods, a.ExpendablePodsPriorityCutoff)
+	nonExpendableScheduledPods := core_utils.FilterOutExpendablePods(originalScheduledPods, a.ExpendablePodsPriorityCutoff, a.ExpendablePodsNamespaceCutoffs)
 	// Initialize cluster state to ClusterSnapshot
 	if typedErr := a.initializeClusterSnapshot(allNodes, nonExpendableScheduledPods); typedErr != nil {
 		return typedErr.AddPrefix("failed to initialize ClusterSnapshot: ")
@@ -395,10 +434,17 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 	defer func() {
 		// Update status information when the loop is done (regardless of reason)
-		if autoscalingContext.WriteStatusConfigMap {
+		if autoscalingContext.WriteStatusConfigMap || autoscalingContext.StatusResourceClient != nil {
 			status := a.clusterStateRegistry.GetStatus(currentTime)
-			utils.WriteStatusConfigMap(autoscalingContext.ClientSet, autoscalingContext.ConfigNamespace,
-				*status, a.AutoscalingContext.LogRecorder, a.AutoscalingContext.StatusConfigMapName, currentTime)
+			status.ClusterWide.ScaleDownDryRun = a.lastScaleDownDryRun
+			if autoscalingContext.WriteStatusConfigMap {
+				utils.WriteStatusConfigMap(autoscalingContext.ClientSet, autoscalingContext.ConfigNamespace,
+					*status, a.AutoscalingContext.LogRecorder, a.AutoscalingContext.StatusConfigMapName, currentTime)
+			}
+			if autoscalingContext.StatusResourceClient != nil {
+				utils.WriteStatusResource(autoscalingContext.StatusResourceClient, autoscalingContext.ConfigNamespace,
+					*status, a.AutoscalingContext.StatusResourceName, currentTime)
+			}
 		}
 
 		// This deferred processor execution allows the processors to handle a situation when a scale-(up|down)
@@ -412,6 +458,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 			scaleDownStatus.NodeDeleteResults = nodeDeletionResults
 			scaleDownStatus.NodeDeleteResultsAsOf = nodeDeletionResultsAsOf
 			a.scaleDownActuator.ClearResultsNotNewerThan(scaleDownStatus.NodeDeleteResultsAsOf)
+			if a.processors.ActuationHooks != nil {
+				a.processors.ActuationHooks.AfterScaleDown(a.AutoscalingContext, nodeDeletionResults)
+			}
 			scaleDownStatus.SetUnremovableNodesInfo(a.scaleDownPlanner.UnremovableNodes(), a.scaleDownPlanner.NodeUtilizationMap(), a.CloudProvider)
 
 			a.processors.ScaleDownStatusProcessor.Process(a.AutoscalingContext, scaleDownStatus)
@@ -485,15 +534,19 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	// them and not trigger another scale-up.
 	// The fake nodes are intentionally not added to the all nodes list, so that they are not considered as candidates for scale-down (which
 	// doesn't make sense as they're not real).
-	for _, upcomingNode := range getUpcomingNodeInfos(upcomingCounts, nodeInfosForGroups) {
-		var pods []*apiv1.Pod
-		for _, podInfo := range upcomingNode.Pods {
-			pods = append(pods, podInfo.Pod)
-		}
-		err = a.ClusterSnapshot.AddNodeWithPods(upcomingNode.Node(), pods)
-		if err != nil {
-			klog.Errorf("Failed to add upcoming node %s to cluster snapshot: %v", upcomingNode.Node().Name, err)
-			return caerrors.ToAutoscalerError(caerrors.InternalError, err)
+	upcomingNodeGroups := make(map[string]string)
+	for nodeGroup, upcomingNodes := range getUpcomingNodeInfos(upcomingCounts, nodeInfosForGroups) {
+		for _, upcomingNode := range upcomingNodes {
+			var pods []*apiv1.Pod
+			for _, podInfo := range upcomingNode.Pods {
+				pods = append(pods, podInfo.Pod)
+			}
+			err = a.ClusterSnapshot.AddNodeWithPods(upcomingNode.Node(), pods)
+			if err != nil {
+				klog.Errorf("Failed to add upcoming node %s to cluster snapshot: %v", upcomingNode.Node().Name, err)
+				return caerrors.ToAutoscalerError(caerrors.InternalError, err)
+			}
+			upcomingNodeGroups[upcomingNode.Node().Name] = nodeGroup
 		}
 	}
 	// Some upcoming nodes can already be registered in the cluster, but not yet ready - we still inject replacements for them above. The actual registered nodes
@@ -532,6 +585,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 	// finally, filter out pods that are too "young" to safely be considered for a scale-up (delay is configurable)
 	unschedulablePodsToHelp = a.filterOutYoungPods(unschedulablePodsToHelp, currentTime)
+	a.DebuggingSnapshotter.SetUnschedulablePods(unschedulablePodsToHelp)
 
 	preScaleUp := func() time.Time {
 		scaleUpStart := time.Now()
@@ -576,12 +630,31 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		klog.V(1).Info("Unschedulable pods are very new, waiting one iteration for more")
 	} else {
 		scaleUpStart := preScaleUp()
+		scaleUpSpan := tracing.Start("ScaleUp")
 		scaleUpStatus, typedErr = a.scaleUpOrchestrator.ScaleUp(unschedulablePodsToHelp, readyNodes, daemonsets, nodeInfosForGroups, false)
+		scaleUpSpan.End(typedErr)
 		if exit, err := postScaleUp(scaleUpStart); exit {
 			return err
 		}
 	}
 
+	if len(a.NodeGroupHeadroom) > 0 {
+		placeholders, err := placeHeadroom(autoscalingContext, a.NodeGroupHeadroom, upcomingNodeGroups)
+		if err != nil {
+			klog.Errorf("Failed to place headroom placeholders: %v", err)
+			return caerrors.ToAutoscalerError(caerrors.InternalError, err)
+		}
+		if len(placeholders) > 0 {
+			scaleUpStart := preScaleUp()
+			scaleUpSpan := tracing.Start("ScaleUpForHeadroom")
+			scaleUpStatus, typedErr = a.scaleUpOrchestrator.ScaleUpForHeadroom(placeholders, readyNodes, nodeInfosForGroups)
+			scaleUpSpan.End(typedErr)
+			if exit, err := postScaleUp(scaleUpStart); exit {
+				return err
+			}
+		}
+	}
+
 	if a.ScaleDownEnabled {
 		unneededStart := time.Now()
 
@@ -614,7 +687,10 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 			}
 		}
 
+		findUnneededSpan := tracing.Start("FindUnneeded")
 		typedErr := a.scaleDownPlanner.UpdateClusterState(podDestinations, scaleDownCandidates, scaleDownActuationStatus, currentTime)
+		findUnneededSpan.End(typedErr)
+		a.DebuggingSnapshotter.SetNodeUtilization(a.scaleDownPlanner.NodeUtilizationMap())
 		// Update clusterStateRegistry and metrics regardless of whether ScaleDown was successful or not.
 		unneededNodes := a.scaleDownPlanner.UnneededNodes()
 		a.processors.ScaleDownCandidatesNotifier.Update(unneededNodes, currentTime)
@@ -637,7 +713,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		// in progress.
 		_, drained := scaleDownActuationStatus.DeletionsInProgress()
 		var removedNodeGroups []cloudprovider.NodeGroup
-		if len(drained) == 0 {
+		if len(drained) == 0 && !a.ScaleDownDryRun {
 			var err error
 			removedNodeGroups, err = a.processors.NodeGroupManager.RemoveUnneededNodeGroups(autoscalingContext)
 			if err != nil {
@@ -648,17 +724,33 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 		if scaleDownInCooldown {
 			scaleDownStatus.Result = scaledownstatus.ScaleDownInCooldown
+		} else if a.ScaleDownDryRun {
+			empty, needDrain := a.scaleDownPlanner.NodesToDelete(currentTime)
+			a.lastScaleDownDryRun = reportScaleDownDryRun(autoscalingContext, empty, needDrain, currentTime)
+			scaleDownStatus.Result = scaledownstatus.ScaleDownNoNodeDeleted
+			metrics.UpdateUnremovableNodesCount(countsByReason(a.scaleDownPlanner.UnremovableNodes()))
+			metrics.UpdateUnremovableNodesBlockedByPodCount(countsByBlockingPodReason(a.scaleDownPlanner.UnremovableNodes()))
 		} else {
 			klog.V(4).Infof("Starting scale down")
 
 			scaleDownStart := time.Now()
 			metrics.UpdateLastTime(metrics.ScaleDown, scaleDownStart)
+			scaleDownSpan := tracing.Start("ScaleDown")
 			empty, needDrain := a.scaleDownPlanner.NodesToDelete(currentTime)
+			if len(empty) > 0 || len(needDrain) > 0 {
+				if err := a.processors.ActuationHooks.BeforeScaleDown(autoscalingContext, empty, needDrain); err != nil {
+					klog.Warningf("Scale-down not executed: %v", err)
+					autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeWarning, "ScaleDownVetoed", "Scale-down not executed: %v", err)
+					empty, needDrain = nil, nil
+				}
+			}
 			scaleDownResult, scaledDownNodes, typedErr := a.scaleDownActuator.StartDeletion(empty, needDrain)
+			scaleDownSpan.End(typedErr)
 			scaleDownStatus.Result = scaleDownResult
 			scaleDownStatus.ScaledDownNodes = scaledDownNodes
 			metrics.UpdateDurationFromStart(metrics.ScaleDown, scaleDownStart)
 			metrics.UpdateUnremovableNodesCount(countsByReason(a.scaleDownPlanner.UnremovableNodes()))
+			metrics.UpdateUnremovableNodesBlockedByPodCount(countsByBlockingPodReason(a.scaleDownPlanner.UnremovableNodes()))
 
 			scaleDownStatus.RemovedNodeGroups = removedNodeGroups
 
@@ -692,7 +784,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 	if a.EnforceNodeGroupMinSize {
 		scaleUpStart := preScaleUp()
+		scaleUpSpan := tracing.Start("ScaleUpToNodeGroupMinSize")
 		scaleUpStatus, typedErr = a.scaleUpOrchestrator.ScaleUpToNodeGroupMinSize(readyNodes, nodeInfosForGroups)
+		scaleUpSpan.End(typedErr)
 		if exit, err := postScaleUp(scaleUpStart); exit {
 			return err
 		}
@@ -956,6 +1050,9 @@ func (a *StaticAutoscaler) ExitCleanUp() {
 	a.processors.CleanUp()
 	a.DebuggingSnapshotter.Cleanup()
 
+	if a.AutoscalingContext.StatusResourceClient != nil {
+		utils.DeleteStatusResource(a.AutoscalingContext.StatusResourceClient, a.AutoscalingContext.ConfigNamespace, a.AutoscalingContext.StatusResourceName)
+	}
 	if !a.AutoscalingContext.WriteStatusConfigMap {
 		return
 	}
@@ -1028,8 +1125,8 @@ func allPodsAreNew(pods []*apiv1.Pod, currentTime time.Time) bool {
 	return found && oldest.Add(unschedulablePodWithGpuTimeBuffer).After(currentTime)
 }
 
-func getUpcomingNodeInfos(upcomingCounts map[string]int, nodeInfos map[string]*schedulerframework.NodeInfo) []*schedulerframework.NodeInfo {
-	upcomingNodes := make([]*schedulerframework.NodeInfo, 0)
+func getUpcomingNodeInfos(upcomingCounts map[string]int, nodeInfos map[string]*schedulerframework.NodeInfo) map[string][]*schedulerframework.NodeInfo {
+	upcomingNodes := make(map[string][]*schedulerframework.NodeInfo)
 	for nodeGroup, numberOfNodes := range upcomingCounts {
 		nodeTemplate, found := nodeInfos[nodeGroup]
 		if !found {
@@ -1046,7 +1143,7 @@ func getUpcomingNodeInfos(upcomingCounts map[string]int, nodeInfos map[string]*s
 			// Ensure new nodes have different names because nodeName
 			// will be used as a map key. Also deep copy pods (daemonsets &
 			// any pods added by cloud provider on template).
-			upcomingNodes = append(upcomingNodes, scheduler_utils.DeepCopyTemplateNode(nodeTemplate, fmt.Sprintf("upcoming-%d", i)))
+			upcomingNodes[nodeGroup] = append(upcomingNodes[nodeGroup], scheduler_utils.DeepCopyTemplateNode(nodeTemplate, fmt.Sprintf("upcoming-%d", i)))
 		}
 	}
 	return upcomingNodes
@@ -1080,6 +1177,18 @@ func countsByReason(nodes []*simulator.UnremovableNode) map[simulator.Unremovabl
 	return counts
 }
 
+func countsByBlockingPodReason(nodes []*simulator.UnremovableNode) map[drain.BlockingPodReason]int {
+	counts := make(map[drain.BlockingPodReason]int)
+
+	for _, node := range nodes {
+		if node.BlockingPod != nil {
+			counts[node.BlockingPod.Reason]++
+		}
+	}
+
+	return counts
+}
+
 func subtractNodesByName(nodes []*apiv1.Node, namesToRemove []string) []*apiv1.Node {
 	var c []*apiv1.Node
 	removeSet := make(map[string]bool)
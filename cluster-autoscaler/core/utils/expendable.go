@@ -24,7 +24,7 @@ import (
 // FilterOutExpendableAndSplit filters out expendable pods and splits into:
 //   - waiting for lower priority pods preemption
 //   - other pods.
-func FilterOutExpendableAndSplit(unschedulableCandidates []*apiv1.Pod, nodes []*apiv1.Node, expendablePodsPriorityCutoff int) ([]*apiv1.Pod, []*apiv1.Pod) {
+func FilterOutExpendableAndSplit(unschedulableCandidates []*apiv1.Pod, nodes []*apiv1.Node, expendablePodsPriorityCutoff int, namespaceCutoffs map[string]int) ([]*apiv1.Pod, []*apiv1.Pod) {
 	var unschedulableNonExpendable []*apiv1.Pod
 	var waitingForLowerPriorityPreemption []*apiv1.Pod
 
@@ -34,8 +34,9 @@ func FilterOutExpendableAndSplit(unschedulableCandidates []*apiv1.Pod, nodes []*
 	}
 
 	for _, pod := range unschedulableCandidates {
-		if IsExpendablePod(pod, expendablePodsPriorityCutoff) {
-			klog.V(4).Infof("Pod %s has priority below %d (%d) and will scheduled when enough resources is free. Ignoring in scale up.", pod.Name, expendablePodsPriorityCutoff, *pod.Spec.Priority)
+		cutoff := PodExpendablePriorityCutoff(pod, expendablePodsPriorityCutoff, namespaceCutoffs)
+		if IsExpendablePod(pod, cutoff) {
+			klog.V(4).Infof("Pod %s has priority below %d (%d) and will scheduled when enough resources is free. Ignoring in scale up.", pod.Name, cutoff, *pod.Spec.Priority)
 		} else if nominatedNodeName := pod.Status.NominatedNodeName; nominatedNodeName != "" {
 			if nodeNames[nominatedNodeName] {
 				klog.V(4).Infof("Pod %s will be scheduled after low priority pods are preempted on %s. Ignoring in scale up.", pod.Name, nominatedNodeName)
@@ -52,16 +53,25 @@ func FilterOutExpendableAndSplit(unschedulableCandidates []*apiv1.Pod, nodes []*
 }
 
 // FilterOutExpendablePods filters out expendable pods.
-func FilterOutExpendablePods(pods []*apiv1.Pod, expendablePodsPriorityCutoff int) []*apiv1.Pod {
+func FilterOutExpendablePods(pods []*apiv1.Pod, expendablePodsPriorityCutoff int, namespaceCutoffs map[string]int) []*apiv1.Pod {
 	var result []*apiv1.Pod
 	for _, pod := range pods {
-		if !IsExpendablePod(pod, expendablePodsPriorityCutoff) {
+		if !IsExpendablePod(pod, PodExpendablePriorityCutoff(pod, expendablePodsPriorityCutoff, namespaceCutoffs)) {
 			result = append(result, pod)
 		}
 	}
 	return result
 }
 
+// PodExpendablePriorityCutoff returns the priority cutoff of the pod's namespace if it's overridden
+// in namespaceCutoffs, and expendablePodsPriorityCutoff otherwise.
+func PodExpendablePriorityCutoff(pod *apiv1.Pod, expendablePodsPriorityCutoff int, namespaceCutoffs map[string]int) int {
+	if cutoff, found := namespaceCutoffs[pod.Namespace]; found {
+		return cutoff
+	}
+	return expendablePodsPriorityCutoff
+}
+
 // IsExpendablePod tests if pod is expendable for give priority cutoff
 func IsExpendablePod(pod *apiv1.Pod, expendablePodsPriorityCutoff int) bool {
 	preemptLowerPriority := pod.Spec.PreemptionPolicy == nil || *pod.Spec.PreemptionPolicy == apiv1.PreemptLowerPriority
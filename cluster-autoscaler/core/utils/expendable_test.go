@@ -43,7 +43,7 @@ func TestFilterOutExpendableAndSplit(t *testing.T) {
 	podWaitingForPreemption2.Spec.Priority = &priority100
 	podWaitingForPreemption2.Status.NominatedNodeName = "node2"
 
-	res1, res2 := FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1, n2}, 0)
+	res1, res2 := FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1, n2}, 0, nil)
 	assert.Equal(t, 2, len(res1))
 	assert.Equal(t, p1, res1[0])
 	assert.Equal(t, p2, res1[1])
@@ -51,14 +51,14 @@ func TestFilterOutExpendableAndSplit(t *testing.T) {
 	assert.Equal(t, podWaitingForPreemption1, res2[0])
 	assert.Equal(t, podWaitingForPreemption2, res2[1])
 
-	res1, res2 = FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1, n2}, 10)
+	res1, res2 = FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1, n2}, 10, nil)
 	assert.Equal(t, 1, len(res1))
 	assert.Equal(t, p2, res1[0])
 	assert.Equal(t, 1, len(res2))
 	assert.Equal(t, podWaitingForPreemption2, res2[0])
 
 	// if node2 is missing podWaitingForPreemption2 should be treated as standard pod not one waiting for preemption
-	res1, res2 = FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1}, 0)
+	res1, res2 = FilterOutExpendableAndSplit([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, []*apiv1.Node{n1}, 0, nil)
 	assert.Equal(t, 3, len(res1))
 	assert.Equal(t, p1, res1[0])
 	assert.Equal(t, p2, res1[1])
@@ -81,13 +81,32 @@ func TestFilterOutExpendablePods(t *testing.T) {
 	podWaitingForPreemption2.Spec.Priority = &priority2
 	podWaitingForPreemption2.Status.NominatedNodeName = "node1"
 
-	res := FilterOutExpendablePods([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, 0)
+	res := FilterOutExpendablePods([]*apiv1.Pod{p1, p2, podWaitingForPreemption1, podWaitingForPreemption2}, 0, nil)
 	assert.Equal(t, 3, len(res))
 	assert.Equal(t, p1, res[0])
 	assert.Equal(t, p2, res[1])
 	assert.Equal(t, podWaitingForPreemption2, res[2])
 }
 
+func TestFilterOutExpendablePodsWithNamespaceCutoffs(t *testing.T) {
+	var priority int32 = 10
+	p1 := BuildTestPod("p1", 1500, 200000)
+	p1.Namespace = "fillers"
+	p1.Spec.Priority = &priority
+	p2 := BuildTestPod("p2", 1500, 200000)
+	p2.Namespace = "default"
+	p2.Spec.Priority = &priority
+	p3 := BuildTestPod("p3", 1500, 200000)
+	p3.Namespace = "critical"
+	p3.Spec.Priority = &priority
+
+	res := FilterOutExpendablePods([]*apiv1.Pod{p1, p2, p3}, 0, map[string]int{"fillers": 100, "critical": 20})
+	assert.Equal(t, []*apiv1.Pod{p2}, res)
+
+	res = FilterOutExpendablePods([]*apiv1.Pod{p1, p2, p3}, 100, map[string]int{"critical": -10})
+	assert.Equal(t, []*apiv1.Pod{p3}, res)
+}
+
 func TestIsExpandablePod(t *testing.T) {
 	preemptLowerPriorityPolicy := apiv1.PreemptLowerPriority
 	neverPolicy := apiv1.PreemptNever
@@ -37,16 +37,20 @@ import (
 
 // GetNodeInfoFromTemplate returns NodeInfo object built base on TemplateNodeInfo returned by NodeGroup.TemplateNodeInfo().
 func GetNodeInfoFromTemplate(nodeGroup cloudprovider.NodeGroup, daemonsets []*appsv1.DaemonSet, taintConfig taints.TaintConfig) (*schedulerframework.NodeInfo, errors.AutoscalerError) {
-	id := nodeGroup.Id()
 	baseNodeInfo, err := nodeGroup.TemplateNodeInfo()
 	if err != nil {
 		return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
 	}
+	return BuildNodeInfoFromTemplate(nodeGroup.Id(), baseNodeInfo, daemonsets, taintConfig)
+}
 
+// BuildNodeInfoFromTemplate returns the NodeInfo of a new node of the node group with the given id,
+// built from the template returned by its TemplateNodeInfo and the pods of the daemonsets that would run on it.
+func BuildNodeInfoFromTemplate(id string, baseNodeInfo *schedulerframework.NodeInfo, daemonsets []*appsv1.DaemonSet, taintConfig taints.TaintConfig) (*schedulerframework.NodeInfo, errors.AutoscalerError) {
 	labels.UpdateDeprecatedLabels(baseNodeInfo.Node().ObjectMeta.Labels)
 
 	sanitizedNode, typedErr := SanitizeNode(baseNodeInfo.Node(), id, taintConfig)
-	if err != nil {
+	if typedErr != nil {
 		return nil, typedErr
 	}
 	baseNodeInfo.SetNode(sanitizedNode)
@@ -109,6 +113,9 @@ func SanitizeNode(node *apiv1.Node, nodeGroup string, taintConfig taints.TaintCo
 	nodeName := fmt.Sprintf("template-node-for-%s-%d", nodeGroup, rand.Int63())
 	newNode.Labels = make(map[string]string, len(node.Labels))
 	for k, v := range node.Labels {
+		if taintConfig.IsIgnoredTemplateLabel(k) {
+			continue
+		}
 		if k != apiv1.LabelHostname {
 			newNode.Labels[k] = v
 		} else {
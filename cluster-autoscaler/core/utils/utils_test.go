@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 
@@ -53,6 +54,13 @@ func TestSanitizeLabels(t *testing.T) {
 	assert.Equal(t, node.Labels["x"], "y")
 	assert.NotEqual(t, node.Name, oldNode.Name)
 	assert.Equal(t, node.Labels[apiv1.LabelHostname], node.Name)
+
+	oldNode.Labels["vendor.example.com/driver-version"] = "1.2.3"
+	taintConfig := taints.NewTaintConfig(config.AutoscalingOptions{IgnoredTemplateLabelPrefixes: []string{"vendor.example.com/"}})
+	node, err = SanitizeNode(oldNode, "bzium", taintConfig)
+	assert.NoError(t, err)
+	assert.NotContains(t, node.Labels, "vendor.example.com/driver-version")
+	assert.Equal(t, node.Labels["x"], "y")
 }
 
 func TestGetNodeResource(t *testing.T) {
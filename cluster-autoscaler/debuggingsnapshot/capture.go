@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debuggingsnapshot
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/klog/v2"
+)
+
+// SnapshotSink persists the debugging snapshots captured automatically on failures.
+type SnapshotSink interface {
+	// Save persists the snapshot under the given name.
+	Save(name string, data []byte) error
+}
+
+// NewSnapshotSink returns a sink for the destination, which is either a http(s) URL the snapshots
+// are PUT under, e.g. a bucket of an object storage, or a directory, e.g. a mounted PVC.
+func NewSnapshotSink(destination string) SnapshotSink {
+	if strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://") {
+		return &urlSink{url: strings.TrimSuffix(destination, "/"), client: &http.Client{Timeout: time.Minute}}
+	}
+	return &dirSink{dir: destination}
+}
+
+type dirSink struct {
+	dir string
+}
+
+// Save writes the snapshot to a file in the directory. The file is written under a temporary
+// name first, so that a partially written snapshot is never picked up.
+func (s *dirSink) Save(name string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(s.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(s.dir, name))
+}
+
+type urlSink struct {
+	url    string
+	client *http.Client
+}
+
+// Save uploads the snapshot with a PUT request.
+func (s *urlSink) Save(name string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, s.url+"/"+name, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// autoCapture is the configuration and the state of capturing snapshots on failures.
+type autoCapture struct {
+	sink SnapshotSink
+	// minInterval is the minimum time between two captured snapshots.
+	minInterval time.Duration
+	lastCapture time.Time
+	// saving is used to wait for snapshots that are being saved, in tests.
+	saving sync.WaitGroup
+}
+
+// NewAutoCapturingDebuggingSnapshotter returns an enabled DebuggingSnapshotter which, in addition to
+// serving snapshot requests, collects the data of every loop and saves it to the sink when a failure
+// is reported in the loop, at most once per minInterval.
+func NewAutoCapturingDebuggingSnapshotter(sink SnapshotSink, minInterval time.Duration) DebuggingSnapshotter {
+	d := NewDebuggingSnapshotter(true).(*DebuggingSnapshotterImpl)
+	d.autoCapture = &autoCapture{sink: sink, minInterval: minInterval}
+	klog.Infof("Debugging Snapshots are captured automatically on failures, at most once per %v", minInterval)
+	return d
+}
+
+// ReportFailure is the impl for DebuggingSnapshotter.ReportFailure
+func (d *DebuggingSnapshotterImpl) ReportFailure(reason string) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if d.autoCapture == nil || !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	d.failures = append(d.failures, reason)
+}
+
+// canCaptureNoLock checks whether enough time passed since the last captured snapshot.
+func (d *DebuggingSnapshotterImpl) canCaptureNoLock(now time.Time) bool {
+	return d.autoCapture != nil && now.Sub(d.autoCapture.lastCapture) >= d.autoCapture.minInterval
+}
+
+// captureNoLock saves the snapshot collected in the loop in the background, so that a slow
+// sink doesn't block the loop.
+func (d *DebuggingSnapshotterImpl) captureNoLock(now time.Time) {
+	failures := d.failures
+	d.failures = nil
+	if !d.canCaptureNoLock(now) {
+		klog.V(1).Infof("Not capturing a debugging snapshot of failures %v, the last one was captured less than %v ago", failures, d.autoCapture.minInterval)
+		return
+	}
+	d.autoCapture.lastCapture = now
+	d.DebuggingSnapshot.SetFailures(failures)
+	d.DebuggingSnapshot.SetEndTimestamp(now.In(time.UTC))
+	body, _ := d.DebuggingSnapshot.GetOutputBytes()
+	name := fmt.Sprintf("snapshot-%s.json", now.In(time.UTC).Format("20060102-150405"))
+	d.autoCapture.saving.Add(1)
+	go func() {
+		defer d.autoCapture.saving.Done()
+		if err := d.autoCapture.sink.Save(name, body); err != nil {
+			klog.Errorf("Failed to save the debugging snapshot %s of failures %v: %v", name, failures, err)
+			return
+		}
+		klog.Infof("Saved the debugging snapshot %s of failures %v", name, failures)
+	}()
+}
+
+// FailedScaleUpObserver reports failed scale-ups to the DebuggingSnapshotter.
+type FailedScaleUpObserver struct {
+	snapshotter DebuggingSnapshotter
+}
+
+// NewFailedScaleUpObserver returns a node group change observer that reports failed scale-ups
+// to the DebuggingSnapshotter, so that a snapshot of the loop is captured.
+func NewFailedScaleUpObserver(snapshotter DebuggingSnapshotter) *FailedScaleUpObserver {
+	return &FailedScaleUpObserver{snapshotter: snapshotter}
+}
+
+// RegisterScaleUp is a no-op.
+func (o *FailedScaleUpObserver) RegisterScaleUp(cloudprovider.NodeGroup, int, time.Time) {}
+
+// RegisterScaleDown is a no-op.
+func (o *FailedScaleUpObserver) RegisterScaleDown(cloudprovider.NodeGroup, string, time.Time, time.Time) {
+}
+
+// RegisterFailedScaleUp reports the failed scale-up.
+func (o *FailedScaleUpObserver) RegisterFailedScaleUp(nodeGroup cloudprovider.NodeGroup, reason string, errMsg string, _, _ string, _ time.Time) {
+	o.snapshotter.ReportFailure(fmt.Sprintf("scale-up of node group %s failed: %s: %s", nodeGroup.Id(), reason, errMsg))
+}
+
+// RegisterFailedScaleDown is a no-op.
+func (o *FailedScaleUpObserver) RegisterFailedScaleDown(cloudprovider.NodeGroup, string, time.Time) {}
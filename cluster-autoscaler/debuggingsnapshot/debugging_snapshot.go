@@ -21,6 +21,8 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/utilization"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -31,6 +33,15 @@ type ClusterNode struct {
 	Pods []*v1.Pod `json:"Pods"`
 }
 
+// ExpansionOption captures a single scale-up option considered by the expander.
+type ExpansionOption struct {
+	NodeGroup string   `json:"NodeGroup"`
+	NodeCount int      `json:"NodeCount"`
+	Pods      []string `json:"Pods"`
+	Debug     string   `json:"Debug,omitempty"`
+	Chosen    bool     `json:"Chosen"`
+}
+
 // DebuggingSnapshot is the interface used to define any debugging snapshot
 // implementation, incl. any custom impl. to be used by DebuggingSnapshotter
 type DebuggingSnapshot interface {
@@ -42,8 +53,18 @@ type DebuggingSnapshot interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetUnschedulablePods is a setter for all pods which are unschedulable
+	// and are considered for a scale-up
+	SetUnschedulablePods([]*v1.Pod)
+	// SetExpansionOptions is a setter for the scale-up options considered by the expander
+	// and the option it chose
+	SetExpansionOptions([]expander.Option, *expander.Option)
+	// SetNodeUtilization is a setter for the utilization of the nodes considered for scale-down
+	SetNodeUtilization(map[string]utilization.Info)
 	// SetErrorMessage sets the error message in the snapshot
 	SetErrorMessage(string)
+	// SetFailures sets the failures the snapshot was captured automatically because of
+	SetFailures([]string)
 	// SetEndTimestamp sets the timestamp in the snapshot,
 	// when all the data collection is finished
 	SetEndTimestamp(time.Time)
@@ -61,12 +82,16 @@ type DebuggingSnapshot interface {
 // Please add all new output fields in this struct. This is to make the data
 // encoding/decoding easier as the single object going into the decoder
 type DebuggingSnapshotImpl struct {
-	NodeList                      []*ClusterNode          `json:"NodeList"`
-	UnscheduledPodsCanBeScheduled []*v1.Pod               `json:"UnscheduledPodsCanBeScheduled"`
-	Error                         string                  `json:"Error,omitempty"`
-	StartTimestamp                time.Time               `json:"StartTimestamp"`
-	EndTimestamp                  time.Time               `json:"EndTimestamp"`
-	TemplateNodes                 map[string]*ClusterNode `json:"TemplateNodes"`
+	NodeList                      []*ClusterNode              `json:"NodeList"`
+	UnscheduledPodsCanBeScheduled []*v1.Pod                   `json:"UnscheduledPodsCanBeScheduled"`
+	Error                         string                      `json:"Error,omitempty"`
+	StartTimestamp                time.Time                   `json:"StartTimestamp"`
+	EndTimestamp                  time.Time                   `json:"EndTimestamp"`
+	TemplateNodes                 map[string]*ClusterNode     `json:"TemplateNodes"`
+	UnschedulablePods             []*v1.Pod                   `json:"UnschedulablePods"`
+	ExpansionOptions              []*ExpansionOption          `json:"ExpansionOptions"`
+	NodeUtilization               map[string]utilization.Info `json:"NodeUtilization"`
+	Failures                      []string                    `json:"Failures,omitempty"`
 }
 
 // SetUnscheduledPodsCanBeScheduled is the setter for UnscheduledPodsCanBeScheduled
@@ -93,6 +118,51 @@ func (s *DebuggingSnapshotImpl) SetTemplateNodes(templates map[string]*framework
 	}
 }
 
+// SetUnschedulablePods is the setter for UnschedulablePods
+func (s *DebuggingSnapshotImpl) SetUnschedulablePods(podList []*v1.Pod) {
+	if podList == nil {
+		return
+	}
+
+	s.UnschedulablePods = nil
+	for _, pod := range podList {
+		s.UnschedulablePods = append(s.UnschedulablePods, pod.DeepCopy())
+	}
+}
+
+// SetExpansionOptions is the setter for ExpansionOptions
+func (s *DebuggingSnapshotImpl) SetExpansionOptions(options []expander.Option, bestOption *expander.Option) {
+	if options == nil {
+		return
+	}
+
+	s.ExpansionOptions = nil
+	for _, option := range options {
+		expansionOption := &ExpansionOption{
+			NodeGroup: option.NodeGroup.Id(),
+			NodeCount: option.NodeCount,
+			Debug:     option.Debug,
+			Chosen:    bestOption != nil && bestOption.NodeGroup.Id() == option.NodeGroup.Id(),
+		}
+		for _, pod := range option.Pods {
+			expansionOption.Pods = append(expansionOption.Pods, pod.Namespace+"/"+pod.Name)
+		}
+		s.ExpansionOptions = append(s.ExpansionOptions, expansionOption)
+	}
+}
+
+// SetNodeUtilization is the setter for NodeUtilization
+func (s *DebuggingSnapshotImpl) SetNodeUtilization(utilizationMap map[string]utilization.Info) {
+	if utilizationMap == nil {
+		return
+	}
+
+	s.NodeUtilization = make(map[string]utilization.Info, len(utilizationMap))
+	for name, info := range utilizationMap {
+		s.NodeUtilization[name] = info
+	}
+}
+
 // GetClusterNodeCopy is an util func to copy template node and filter values
 func GetClusterNodeCopy(template *framework.NodeInfo) *ClusterNode {
 	cNode := &ClusterNode{}
@@ -164,6 +234,11 @@ func (s *DebuggingSnapshotImpl) SetErrorMessage(error string) {
 	s.Error = error
 }
 
+// SetFailures sets the failures the snapshot was captured automatically because of
+func (s *DebuggingSnapshotImpl) SetFailures(failures []string) {
+	s.Failures = failures
+}
+
 // Cleanup cleans up all the data in the snapshot without changing the
 // pointer reference
 func (s *DebuggingSnapshotImpl) Cleanup() {
@@ -24,6 +24,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/utilization"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
@@ -102,3 +105,31 @@ func TestEmptyDataNoError(t *testing.T) {
 	assert.False(t, err)
 	assert.NotNil(t, op)
 }
+
+func TestScaleUpAndUtilizationSetters(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "Pod1", Namespace: "default"}}
+	options := []expander.Option{
+		{NodeGroup: provider.GetNodeGroup("ng1"), NodeCount: 1, Pods: []*v1.Pod{pod}},
+		{NodeGroup: provider.GetNodeGroup("ng2"), NodeCount: 2, Pods: []*v1.Pod{pod}, Debug: "more expensive"},
+	}
+
+	snapshot := &DebuggingSnapshotImpl{}
+	snapshot.SetUnschedulablePods([]*v1.Pod{pod})
+	snapshot.SetExpansionOptions(options, &options[0])
+	snapshot.SetNodeUtilization(map[string]utilization.Info{"testNode": {CpuUtil: 0.5, MemUtil: 0.25, ResourceName: v1.ResourceCPU, Utilization: 0.5}})
+	op, err := snapshot.GetOutputBytes()
+	assert.False(t, err)
+
+	var parsed DebuggingSnapshotImpl
+	assert.NoError(t, json.Unmarshal(op, &parsed))
+	assert.Equal(t, 1, len(parsed.UnschedulablePods))
+	assert.Equal(t, "Pod1", parsed.UnschedulablePods[0].Name)
+	assert.Equal(t, []*ExpansionOption{
+		{NodeGroup: "ng1", NodeCount: 1, Pods: []string{"default/Pod1"}, Chosen: true},
+		{NodeGroup: "ng2", NodeCount: 2, Pods: []string{"default/Pod1"}, Debug: "more expensive"},
+	}, parsed.ExpansionOptions)
+	assert.Equal(t, map[string]utilization.Info{"testNode": {CpuUtil: 0.5, MemUtil: 0.25, ResourceName: v1.ResourceCPU, Utilization: 0.5}}, parsed.NodeUtilization)
+}
@@ -18,11 +18,15 @@ package debuggingsnapshot
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/utilization"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -66,6 +70,17 @@ type DebuggingSnapshotterImpl struct {
 	// CancelRequest is the cancel function for the snapshot request. It is used to
 	// terminate any ongoing request when CA is shutting down
 	CancelRequest context.CancelFunc
+
+	// autoCapture is nil if snapshots aren't captured automatically on failures.
+	autoCapture *autoCapture
+	// autoCollecting is set while data is collected for a loop without a snapshot request,
+	// in case a failure is reported.
+	autoCollecting bool
+	// requestPending is set when a snapshot request is received while autoCollecting,
+	// data for it is collected in the next loop.
+	requestPending bool
+	// failures are the failures reported in the current loop.
+	failures []string
 }
 
 // DebuggingSnapshotter is the interface for debugging snapshot
@@ -82,12 +97,23 @@ type DebuggingSnapshotter interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetUnschedulablePods is a setter for all pods which are unschedulable
+	// and are considered for a scale-up
+	SetUnschedulablePods([]*v1.Pod)
+	// SetExpansionOptions is a setter for the scale-up options considered by the expander
+	// and the option it chose
+	SetExpansionOptions([]expander.Option, *expander.Option)
+	// SetNodeUtilization is a setter for the utilization of the nodes considered for scale-down
+	SetNodeUtilization(map[string]utilization.Info)
 	// ResponseHandler is the http response handler to manage incoming requests
 	ResponseHandler(http.ResponseWriter, *http.Request)
 	// IsDataCollectionAllowed checks the internal State of the snapshotter
 	// to find if data can be collected. This can be used before preprocessing
 	// for the snapshot
 	IsDataCollectionAllowed() bool
+	// ReportFailure reports a failure in the current loop, so that a snapshot of the
+	// loop is captured if capturing snapshots on failures is enabled
+	ReportFailure(reason string)
 	// Flush triggers the flushing of the snapshot
 	Flush()
 	// Cleanup clears the internal data beans of the snapshot, readying for next request
@@ -114,7 +140,8 @@ func (d *DebuggingSnapshotterImpl) ResponseHandler(w http.ResponseWriter, r *htt
 
 	d.Mutex.Lock()
 	// checks if the handler is in the correct State to accept a new snapshot request
-	if *d.State != LISTENING {
+	// a request received while data is collected only for a failure is served in the next loop
+	if *d.State != LISTENING && (!d.autoCollecting || d.requestPending) {
 		defer d.Mutex.Unlock()
 		klog.Errorf("Debugging Snapshot is currently being processed. Another snapshot can't be processed")
 		w.WriteHeader(http.StatusTooManyRequests)
@@ -126,8 +153,12 @@ func (d *DebuggingSnapshotterImpl) ResponseHandler(w http.ResponseWriter, r *htt
 	d.CancelRequest = cancel
 
 	klog.Infof("Received a new snapshot, that is accepted")
-	// set the State to trigger enabled, to allow workflow to collect data
-	*d.State = TRIGGER_ENABLED
+	if d.autoCollecting {
+		d.requestPending = true
+	} else {
+		// set the State to trigger enabled, to allow workflow to collect data
+		*d.State = TRIGGER_ENABLED
+	}
 	d.Mutex.Unlock()
 
 	select {
@@ -153,9 +184,15 @@ func (d *DebuggingSnapshotterImpl) ResponseHandler(w http.ResponseWriter, r *htt
 		klog.Infof("Received terminate trigger, aborting ongoing snapshot request")
 		w.WriteHeader(http.StatusServiceUnavailable)
 
+		d.CancelRequest = nil
+		if d.requestPending {
+			// the data being collected is still needed in case of a failure
+			d.requestPending = false
+			d.Mutex.Unlock()
+			return
+		}
 		d.DebuggingSnapshot.Cleanup()
 		*d.State = LISTENING
-		d.CancelRequest = nil
 		select {
 		case <-d.Trigger:
 		default:
@@ -164,6 +201,23 @@ func (d *DebuggingSnapshotterImpl) ResponseHandler(w http.ResponseWriter, r *htt
 	}
 }
 
+// AuthenticatedHandler wraps the handler to only serve requests authenticated with
+// the bearer token. An empty token disables the authentication.
+func AuthenticatedHandler(token string, handler http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestToken, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) != 1 {
+			klog.Warningf("Rejected unauthenticated debugging snapshot request from %s", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
 // IsDataCollectionAllowed encapsulate the check to know if data collection is currently active
 // This should be used by setters and by any function that is contingent on data collection State
 // before doing extra processing.
@@ -192,6 +246,11 @@ func (d *DebuggingSnapshotterImpl) StartDataCollection() {
 		*d.State = START_DATA_COLLECTION
 		klog.Infof("Trigger Enabled for Debugging Snapshot, starting data collection")
 		d.DebuggingSnapshot.SetStartTimestamp(time.Now().In(time.UTC))
+	} else if *d.State == LISTENING && d.canCaptureNoLock(time.Now()) {
+		*d.State = START_DATA_COLLECTION
+		d.autoCollecting = true
+		klog.V(4).Infof("Starting data collection for Debugging Snapshot in case of a failure")
+		d.DebuggingSnapshot.SetStartTimestamp(time.Now().In(time.UTC))
 	}
 }
 
@@ -201,6 +260,20 @@ func (d *DebuggingSnapshotterImpl) Flush() {
 	d.Mutex.Lock()
 	defer d.Mutex.Unlock()
 
+	if len(d.failures) > 0 {
+		d.captureNoLock(time.Now())
+	}
+	if d.autoCollecting {
+		d.autoCollecting = false
+		d.DebuggingSnapshot.Cleanup()
+		*d.State = LISTENING
+		if d.requestPending {
+			d.requestPending = false
+			*d.State = TRIGGER_ENABLED
+		}
+		return
+	}
+
 	// Case where Data Collection was started but no data was collected, needs to
 	// be stated as an error and reset to pre-trigger State
 	if *d.State == START_DATA_COLLECTION {
@@ -251,6 +324,42 @@ func (d *DebuggingSnapshotterImpl) SetTemplateNodes(templates map[string]*framew
 	d.DebuggingSnapshot.SetTemplateNodes(templates)
 }
 
+// SetUnschedulablePods is the setter for UnschedulablePods
+func (d *DebuggingSnapshotterImpl) SetUnschedulablePods(podList []*v1.Pod) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	klog.V(4).Infof("UnschedulablePods is being set for the debugging snapshot")
+	d.DebuggingSnapshot.SetUnschedulablePods(podList)
+	*d.State = DATA_COLLECTED
+}
+
+// SetExpansionOptions is the setter for ExpansionOptions
+func (d *DebuggingSnapshotterImpl) SetExpansionOptions(options []expander.Option, bestOption *expander.Option) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	klog.V(4).Infof("ExpansionOptions is being set for the debugging snapshot")
+	d.DebuggingSnapshot.SetExpansionOptions(options, bestOption)
+	*d.State = DATA_COLLECTED
+}
+
+// SetNodeUtilization is the setter for NodeUtilization
+func (d *DebuggingSnapshotterImpl) SetNodeUtilization(utilizationMap map[string]utilization.Info) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	klog.V(4).Infof("NodeUtilization is being set for the debugging snapshot")
+	d.DebuggingSnapshot.SetNodeUtilization(utilizationMap)
+	*d.State = DATA_COLLECTED
+}
+
 // Cleanup clears the internal data sets of the cluster
 func (d *DebuggingSnapshotterImpl) Cleanup() {
 	if d.CancelRequest != nil {
@@ -17,10 +17,16 @@ limitations under the License.
 package debuggingsnapshot
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -158,3 +164,127 @@ func TestRejectParallelRequest(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestAuthenticatedHandler(t *testing.T) {
+	handler := AuthenticatedHandler("secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for header, code := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, code, w.Code, "Authorization: %q", header)
+	}
+}
+
+func TestAutoCaptureOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	snapshotter := NewAutoCapturingDebuggingSnapshotter(NewSnapshotSink(dir), time.Hour).(*DebuggingSnapshotterImpl)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "testNode"}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	// Without a failure nothing is captured.
+	snapshotter.StartDataCollection()
+	assert.True(t, snapshotter.IsDataCollectionAllowed())
+	snapshotter.SetClusterNodes([]*framework.NodeInfo{nodeInfo})
+	snapshotter.Flush()
+	assert.False(t, snapshotter.IsDataCollectionAllowed())
+	snapshotter.autoCapture.saving.Wait()
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	// A failure is captured.
+	snapshotter.StartDataCollection()
+	snapshotter.SetClusterNodes([]*framework.NodeInfo{nodeInfo})
+	snapshotter.ReportFailure("loop failed")
+	snapshotter.Flush()
+	snapshotter.autoCapture.saving.Wait()
+	files, err = os.ReadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		assert.NoError(t, err)
+		var snapshot DebuggingSnapshotImpl
+		assert.NoError(t, json.Unmarshal(data, &snapshot))
+		assert.Equal(t, []string{"loop failed"}, snapshot.Failures)
+		if assert.Len(t, snapshot.NodeList, 1) {
+			assert.Equal(t, "testNode", snapshot.NodeList[0].Node.Name)
+		}
+	}
+
+	// No data is collected until another snapshot can be captured.
+	snapshotter.StartDataCollection()
+	assert.False(t, snapshotter.IsDataCollectionAllowed())
+	snapshotter.ReportFailure("loop failed")
+	snapshotter.Flush()
+	snapshotter.autoCapture.saving.Wait()
+	files, err = os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestRequestDuringAutoCollection(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	snapshotter := NewAutoCapturingDebuggingSnapshotter(NewSnapshotSink(t.TempDir()), time.Hour)
+	snapshotter.StartDataCollection()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	go func() {
+		snapshotter.ResponseHandler(w, req)
+		wg.Done()
+	}()
+
+	impl := snapshotter.(*DebuggingSnapshotterImpl)
+	assert.Eventually(t, func() bool {
+		impl.Mutex.Lock()
+		defer impl.Mutex.Unlock()
+		return impl.requestPending
+	}, time.Second, time.Millisecond)
+
+	// A concurrent request is still rejected.
+	w1 := httptest.NewRecorder()
+	snapshotter.ResponseHandler(w1, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w1.Code)
+
+	// The request is served with the data of the next loop.
+	snapshotter.Flush()
+	snapshotter.StartDataCollection()
+	assert.True(t, snapshotter.IsDataCollectionAllowed())
+	snapshotter.SetClusterNodes(nil)
+	snapshotter.Flush()
+	wg.Wait()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestURLSnapshotSink(t *testing.T) {
+	var gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(body)
+		if strings.HasSuffix(r.URL.Path, "forbidden.json") {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewSnapshotSink(server.URL + "/bucket/")
+	assert.NoError(t, sink.Save("snapshot.json", []byte("{}")))
+	assert.Equal(t, "/bucket/snapshot.json", gotPath)
+	assert.Equal(t, "{}", gotBody)
+	assert.Error(t, sink.Save("forbidden.json", []byte("{}")))
+}
@@ -46,6 +46,9 @@ type estimationState struct {
 	lastNodeName     string
 	newNodeNames     map[string]bool
 	newNodesWithPods map[string]bool
+	// topologySpread tracks the domains of the topology spread constraints of the pods being binpacked, nil if they
+	// have none.
+	topologySpread *topologySpreadTracker
 }
 
 // NewBinpackingNodeEstimator builds a new BinpackingNodeEstimator.
@@ -108,6 +111,11 @@ func (e *BinpackingNodeEstimator) Estimate(
 		var err error
 		var remainingPods []*apiv1.Pod
 
+		if err = e.trackTopologySpread(estimationState, podsEquivalenceGroup.Exemplar()); err != nil {
+			klog.Errorf(err.Error())
+			return 0, nil
+		}
+
 		remainingPods, err = e.tryToScheduleOnExistingNodes(estimationState, podsEquivalenceGroup.Pods)
 		if err != nil {
 			klog.Errorf(err.Error())
@@ -137,7 +145,7 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnExistingNodes(
 
 		// Check schedulability on all nodes created during simulation
 		nodeName, err := e.predicateChecker.FitsAnyNodeMatching(e.clusterSnapshot, pod, func(nodeInfo *schedulerframework.NodeInfo) bool {
-			return estimationState.newNodeNames[nodeInfo.Node().Name]
+			return estimationState.newNodeNames[nodeInfo.Node().Name] && estimationState.topologySpread.fits(nodeInfo.Node())
 		})
 		if err != nil {
 			break
@@ -158,7 +166,7 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnNewNodes(
 	for _, pod := range pods {
 		found := false
 
-		if estimationState.lastNodeName != "" {
+		if estimationState.lastNodeName != "" && e.fitsTopologySpread(estimationState, estimationState.lastNodeName) {
 			// Check schedulability on only newly created node
 			if err := e.predicateChecker.CheckPredicates(e.clusterSnapshot, pod, estimationState.lastNodeName); err == nil {
 				found = true
@@ -195,6 +203,9 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnNewNodes(
 			// Note that this may still fail (ex. if topology spreading with zonal topologyKey is used);
 			// in this case we can't help the pending pod. We keep the node in clusterSnapshot to avoid
 			// adding and removing node to snapshot for each such pod.
+			if !e.fitsTopologySpread(estimationState, estimationState.lastNodeName) {
+				break
+			}
 			if err := e.predicateChecker.CheckPredicates(e.clusterSnapshot, pod, estimationState.lastNodeName); err != nil {
 				break
 			}
@@ -221,9 +232,32 @@ func (e *BinpackingNodeEstimator) addNewNodeToSnapshot(
 	estimationState.newNodeNameIndex++
 	estimationState.lastNodeName = newNodeInfo.Node().Name
 	estimationState.newNodeNames[estimationState.lastNodeName] = true
+	estimationState.topologySpread.addNode(newNodeInfo)
+	return nil
+}
+
+// trackTopologySpread starts tracking the domains of the topology spread constraints of the pods of an equivalence
+// group, counting the pods matching them in the snapshot, including the ones binpacked so far.
+func (e *BinpackingNodeEstimator) trackTopologySpread(estimationState *estimationState, exemplar *apiv1.Pod) error {
+	nodeInfos, err := e.clusterSnapshot.NodeInfos().List()
+	if err != nil {
+		return fmt.Errorf("Error listing nodes in ClusterSnapshot; %w", err)
+	}
+	estimationState.topologySpread = newTopologySpreadTracker(exemplar, nodeInfos)
 	return nil
 }
 
+func (e *BinpackingNodeEstimator) fitsTopologySpread(estimationState *estimationState, nodeName string) bool {
+	if estimationState.topologySpread == nil {
+		return true
+	}
+	nodeInfo, err := e.clusterSnapshot.NodeInfos().Get(nodeName)
+	if err != nil {
+		return false
+	}
+	return estimationState.topologySpread.fits(nodeInfo.Node())
+}
+
 func (e *BinpackingNodeEstimator) tryToAddNode(
 	estimationState *estimationState,
 	pod *apiv1.Pod,
@@ -234,5 +268,10 @@ func (e *BinpackingNodeEstimator) tryToAddNode(
 	}
 	estimationState.newNodesWithPods[nodeName] = true
 	estimationState.scheduledPods = append(estimationState.scheduledPods, pod)
+	if estimationState.topologySpread != nil {
+		if nodeInfo, err := e.clusterSnapshot.NodeInfos().Get(nodeName); err == nil {
+			estimationState.topologySpread.addPod(nodeInfo.Node())
+		}
+	}
 	return nil
 }
@@ -206,6 +206,35 @@ func TestBinpackingEstimate(t *testing.T) {
 			expectNodeCount: 1,
 			expectPodCount:  2,
 		},
+		{
+			name:       "hostname topology spreading counts the pods of all equivalence groups",
+			millicores: 1000,
+			memory:     5000,
+			podsEquivalenceGroup: []PodEquivalenceGroup{
+				makePodEquivalenceGroup(
+					BuildTestPod(
+						"estimatee",
+						40,
+						100,
+						WithNamespace("universe"),
+						WithLabels(map[string]string{
+							"app": "estimatee",
+						}),
+						WithMaxSkew(1, "kubernetes.io/hostname")), 3),
+				makePodEquivalenceGroup(
+					BuildTestPod(
+						"estimatee-small",
+						20,
+						100,
+						WithNamespace("universe"),
+						WithLabels(map[string]string{
+							"app": "estimatee",
+						}),
+						WithMaxSkew(1, "kubernetes.io/hostname")), 3),
+			},
+			expectNodeCount: 6,
+			expectPodCount:  6,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
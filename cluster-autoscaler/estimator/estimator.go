@@ -71,7 +71,11 @@ func NewEstimatorBuilder(name string, limiter EstimationLimiter, orderer Estimat
 			predicateChecker predicatechecker.PredicateChecker,
 			clusterSnapshot clustersnapshot.ClusterSnapshot,
 			context EstimationContext) Estimator {
-			return NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, orderer, context, estimationAnalyserFunc)
+			estimationLimiter := limiter
+			if concurrentLimiter, ok := limiter.(ConcurrentEstimationLimiter); ok {
+				estimationLimiter = concurrentLimiter.NewEstimationLimiter()
+			}
+			return NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, estimationLimiter, orderer, context, estimationAnalyserFunc)
 		}, nil
 	}
 	return nil, fmt.Errorf("unknown estimator: %s", name)
@@ -93,6 +97,14 @@ type EstimationLimiter interface {
 	PermissionToAddNode() bool
 }
 
+// ConcurrentEstimationLimiter is an EstimationLimiter that can be copied, so that each estimator gets a limiter of
+// its own and estimations can run concurrently. Limiters that do not implement it are shared by all estimators.
+type ConcurrentEstimationLimiter interface {
+	EstimationLimiter
+	// NewEstimationLimiter returns a new limiter with the same limits and no estimation state.
+	NewEstimationLimiter() EstimationLimiter
+}
+
 // EstimationPodOrderer is an interface used to determine the order of the pods
 // used while binpacking during scale up estimation
 type EstimationPodOrderer interface {
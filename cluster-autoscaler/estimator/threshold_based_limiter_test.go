@@ -187,6 +187,22 @@ func TestThresholdBasedLimiter(t *testing.T) {
 	}
 }
 
+func TestThresholdBasedLimiterNewEstimationLimiter(t *testing.T) {
+	limiter := NewThresholdBasedEstimationLimiter([]Threshold{NewStaticThreshold(2, 0)}).(ConcurrentEstimationLimiter)
+	limiter.StartEstimation([]PodEquivalenceGroup{}, nil, nil)
+	expectAllow(t, limiter)
+
+	// A new limiter has the same limits, but does not share the state of the estimation.
+	other := limiter.NewEstimationLimiter()
+	other.StartEstimation([]PodEquivalenceGroup{}, nil, nil)
+	expectAllow(t, other)
+	expectAllow(t, other)
+	expectDeny(t, other)
+
+	expectAllow(t, limiter)
+	expectDeny(t, limiter)
+}
+
 func TestMinLimit(t *testing.T) {
 	type testCase[V interface{ int | time.Duration }] struct {
 		name        string
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package estimator
+
+import (
+	"math"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// topologySpreadTracker tracks the number of pods matching the hard topology spread constraints of the pods of an
+// equivalence group in each zone and hostname domain while they are binpacked, so that no pod is placed on a node
+// whose domain would exceed the maxSkew of a constraint.
+type topologySpreadTracker struct {
+	nodeAffinity nodeaffinity.RequiredNodeAffinity
+	constraints  []*spreadConstraint
+}
+
+// spreadConstraint is a hard topology spread constraint, with the number of matching pods in each of its domains.
+type spreadConstraint struct {
+	topologyKey string
+	maxSkew     int
+	minDomains  int
+	selector    labels.Selector
+	namespace   string
+	// selfMatch is 1 if the pods of the equivalence group match the selector of the constraint themselves, 0 otherwise.
+	selfMatch int
+	counts    map[string]int
+}
+
+// trackedTopologyKeys are the topology keys the domains of are tracked while binpacking.
+var trackedTopologyKeys = map[string]bool{
+	apiv1.LabelHostname:          true,
+	apiv1.LabelTopologyZone:      true,
+	apiv1.LabelZoneFailureDomain: true,
+}
+
+// newTopologySpreadTracker returns a tracker of the domains of the hard zone and hostname topology spread constraints
+// of the pod, counting the matching pods already running on the nodes. It returns nil if the pod has no such
+// constraints.
+func newTopologySpreadTracker(pod *apiv1.Pod, nodeInfos []*schedulerframework.NodeInfo) *topologySpreadTracker {
+	if pod == nil {
+		return nil
+	}
+	tracker := &topologySpreadTracker{nodeAffinity: nodeaffinity.GetRequiredNodeAffinity(pod)}
+	for _, c := range pod.Spec.TopologySpreadConstraints {
+		if c.WhenUnsatisfiable != apiv1.DoNotSchedule || !trackedTopologyKeys[c.TopologyKey] {
+			continue
+		}
+		selector, err := spreadConstraintSelector(pod, c)
+		if err != nil {
+			klog.Warningf("Ignoring topology spread constraint of pod %s/%s with invalid label selector: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		constraint := &spreadConstraint{
+			topologyKey: c.TopologyKey,
+			maxSkew:     int(c.MaxSkew),
+			selector:    selector,
+			namespace:   pod.Namespace,
+			counts:      map[string]int{},
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			constraint.selfMatch = 1
+		}
+		if c.MinDomains != nil {
+			constraint.minDomains = int(*c.MinDomains)
+		}
+		tracker.constraints = append(tracker.constraints, constraint)
+	}
+	if len(tracker.constraints) == 0 {
+		return nil
+	}
+	for _, nodeInfo := range nodeInfos {
+		tracker.addNode(nodeInfo)
+	}
+	return tracker
+}
+
+func spreadConstraintSelector(pod *apiv1.Pod, c apiv1.TopologySpreadConstraint) (labels.Selector, error) {
+	labelSelector := c.LabelSelector
+	if len(c.MatchLabelKeys) > 0 {
+		labelSelector = labelSelector.DeepCopy()
+		if labelSelector == nil {
+			labelSelector = &metav1.LabelSelector{}
+		}
+		for _, key := range c.MatchLabelKeys {
+			if value, found := pod.Labels[key]; found {
+				metav1.AddLabelToSelector(labelSelector, key, value)
+			}
+		}
+	}
+	return metav1.LabelSelectorAsSelector(labelSelector)
+}
+
+// addNode adds the domains of a node, and the matching pods running on it, to the tracker. Nodes the pod cannot run
+// on, e.g. because of its node selector, are ignored like they are by the scheduler.
+func (t *topologySpreadTracker) addNode(nodeInfo *schedulerframework.NodeInfo) {
+	if t == nil {
+		return
+	}
+	node := nodeInfo.Node()
+	if match, _ := t.nodeAffinity.Match(node); !match {
+		return
+	}
+	for _, c := range t.constraints {
+		domain, found := node.Labels[c.topologyKey]
+		if !found {
+			continue
+		}
+		c.counts[domain] += c.matchingPods(nodeInfo)
+	}
+}
+
+func (c *spreadConstraint) matchingPods(nodeInfo *schedulerframework.NodeInfo) int {
+	count := 0
+	for _, podInfo := range nodeInfo.Pods {
+		pod := podInfo.Pod
+		if pod.Namespace == c.namespace && pod.DeletionTimestamp == nil && c.selector.Matches(labels.Set(pod.Labels)) {
+			count++
+		}
+	}
+	return count
+}
+
+// fits returns whether a pod can be placed on the node without exceeding the maxSkew of any constraint.
+func (t *topologySpreadTracker) fits(node *apiv1.Node) bool {
+	if t == nil {
+		return true
+	}
+	for _, c := range t.constraints {
+		domain, found := node.Labels[c.topologyKey]
+		if !found {
+			return false
+		}
+		if c.counts[domain]+c.selfMatch-c.minCount() > c.maxSkew {
+			return false
+		}
+	}
+	return true
+}
+
+// minCount returns the lowest number of matching pods in a domain, which is 0 if there are fewer domains than
+// minDomains.
+func (c *spreadConstraint) minCount() int {
+	if len(c.counts) < c.minDomains {
+		return 0
+	}
+	minCount := math.MaxInt
+	for _, count := range c.counts {
+		minCount = min(minCount, count)
+	}
+	if minCount == math.MaxInt {
+		return 0
+	}
+	return minCount
+}
+
+// addPod records a pod of the equivalence group placed on the node.
+func (t *topologySpreadTracker) addPod(node *apiv1.Node) {
+	if t == nil {
+		return
+	}
+	for _, c := range t.constraints {
+		if domain, found := node.Labels[c.topologyKey]; found {
+			c.counts[domain] += c.selfMatch
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package estimator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makeNodeInfo(node *apiv1.Node, pods ...*apiv1.Pod) *schedulerframework.NodeInfo {
+	nodeInfo := schedulerframework.NewNodeInfo(pods...)
+	nodeInfo.SetNode(node)
+	return nodeInfo
+}
+
+func TestTopologySpreadTracker(t *testing.T) {
+	labels := WithLabels(map[string]string{"app": "estimatee"})
+	matchingPod := BuildTestPod("matching", 10, 10, WithNamespace("universe"), labels)
+	otherNamespacePod := BuildTestPod("other-namespace", 10, 10, WithNamespace("other"), labels)
+	otherPod := BuildTestPod("other", 10, 10, WithNamespace("universe"))
+
+	mars1 := makeNode(1000, 1000, 10, "mars-1", "zone-mars")
+	mars2 := makeNode(1000, 1000, 10, "mars-2", "zone-mars")
+	jupiter := makeNode(1000, 1000, 10, "jupiter", "zone-jupiter")
+	nodeInfos := []*schedulerframework.NodeInfo{
+		makeNodeInfo(mars1, matchingPod, otherNamespacePod),
+		makeNodeInfo(jupiter, matchingPod, matchingPod, otherPod),
+	}
+
+	t.Run("no hard constraints", func(t *testing.T) {
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), labels)
+		assert.Nil(t, newTopologySpreadTracker(pod, nodeInfos))
+
+		pod.Spec.TopologySpreadConstraints = []apiv1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       apiv1.LabelHostname,
+			WhenUnsatisfiable: apiv1.ScheduleAnyway,
+		}}
+		assert.Nil(t, newTopologySpreadTracker(pod, nodeInfos))
+	})
+
+	t.Run("zone", func(t *testing.T) {
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), labels, WithMaxSkew(2, apiv1.LabelTopologyZone))
+		tracker := newTopologySpreadTracker(pod, nodeInfos)
+		assert.Equal(t, map[string]int{"zone-mars": 1, "zone-jupiter": 2}, tracker.constraints[0].counts)
+
+		// Mars can take pods until it has 2 more than jupiter.
+		assert.True(t, tracker.fits(mars2))
+		tracker.addPod(mars2)
+		assert.True(t, tracker.fits(mars2))
+		tracker.addPod(mars2)
+		assert.True(t, tracker.fits(mars1))
+		tracker.addPod(mars1)
+		assert.False(t, tracker.fits(mars2))
+		assert.True(t, tracker.fits(jupiter))
+	})
+
+	t.Run("hostname", func(t *testing.T) {
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), labels, WithMaxSkew(1, apiv1.LabelHostname))
+		tracker := newTopologySpreadTracker(pod, nodeInfos)
+
+		// A new node is an empty domain, so mars-1 cannot take another pod until mars-2 has one.
+		tracker.addNode(makeNodeInfo(mars2))
+		assert.False(t, tracker.fits(mars1))
+		assert.True(t, tracker.fits(mars2))
+		tracker.addPod(mars2)
+		assert.True(t, tracker.fits(mars1))
+
+		// Nodes without the topology key do not fit.
+		assert.False(t, tracker.fits(BuildTestNode("unlabeled", 1000, 1000)))
+	})
+
+	t.Run("min domains", func(t *testing.T) {
+		minDomains := int32(3)
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), labels, WithMaxSkew(1, apiv1.LabelTopologyZone))
+		pod.Spec.TopologySpreadConstraints[0].MinDomains = &minDomains
+		tracker := newTopologySpreadTracker(pod, nodeInfos)
+
+		// With fewer domains than minDomains, the minimum is 0, so no zone can have more than maxSkew pods.
+		assert.False(t, tracker.fits(mars2))
+		assert.False(t, tracker.fits(jupiter))
+		venus := makeNode(1000, 1000, 10, "venus", "zone-venus")
+		tracker.addNode(makeNodeInfo(venus))
+		assert.True(t, tracker.fits(venus))
+		tracker.addPod(venus)
+
+		// With minDomains domains, the minimum is the lowest count again.
+		assert.True(t, tracker.fits(mars2))
+		assert.False(t, tracker.fits(jupiter))
+	})
+
+	t.Run("pods not matching their own constraint", func(t *testing.T) {
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), WithMaxSkew(1, apiv1.LabelTopologyZone))
+		tracker := newTopologySpreadTracker(pod, nodeInfos)
+
+		// The skew only grows with matching pods.
+		assert.True(t, tracker.fits(jupiter))
+		tracker.addPod(jupiter)
+		assert.Equal(t, map[string]int{"zone-mars": 1, "zone-jupiter": 2}, tracker.constraints[0].counts)
+	})
+
+	t.Run("node affinity", func(t *testing.T) {
+		pod := BuildTestPod("estimatee", 10, 10, WithNamespace("universe"), labels, WithMaxSkew(1, apiv1.LabelTopologyZone))
+		pod.Spec.NodeSelector = map[string]string{apiv1.LabelTopologyZone: "zone-mars"}
+		tracker := newTopologySpreadTracker(pod, nodeInfos)
+
+		// Jupiter is not a domain of the pod, its pods are not counted.
+		assert.Equal(t, map[string]int{"zone-mars": 1}, tracker.constraints[0].counts)
+		assert.True(t, tracker.fits(mars2))
+	})
+}
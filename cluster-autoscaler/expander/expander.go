@@ -24,7 +24,7 @@ import (
 
 var (
 	// AvailableExpanders is a list of available expander options
-	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName, GRPCExpanderName}
+	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName, GRPCExpanderName, LeastCO2ExpanderName, WarmCapacityExpanderName}
 	// RandomExpanderName selects a node group at random
 	RandomExpanderName = "random"
 	// MostPodsExpanderName selects a node group that fits the most pods
@@ -40,6 +40,10 @@ var (
 	PriorityBasedExpanderName = "priority"
 	// GRPCExpanderName uses the gRPC client expander to call to an external gRPC server to select a node group for scale up
 	GRPCExpanderName = "grpc"
+	// LeastCO2ExpanderName selects a node group in the region or zone whose electricity has the lowest carbon intensity
+	LeastCO2ExpanderName = "leastco2"
+	// WarmCapacityExpanderName selects a node group that can add the most nodes from pre-warmed capacity
+	WarmCapacityExpanderName = "warm-capacity"
 )
 
 // Option describes an option to expand the cluster.
@@ -49,6 +53,9 @@ type Option struct {
 	NodeCount         int
 	Debug             string
 	Pods              []*apiv1.Pod
+	// GangOptions are the options of the other node groups of a gang, which are scaled up together with NodeGroup.
+	// Pods holds the pods of the whole gang.
+	GangOptions []Option
 }
 
 // Strategy describes an interface for selecting the best option when scaling up
@@ -60,3 +67,9 @@ type Strategy interface {
 type Filter interface {
 	BestOptions(options []Option, nodeInfo map[string]*schedulerframework.NodeInfo) []Option
 }
+
+// Scorer describes an interface for scoring options according to some criteria, so the scores of several expanders
+// can be blended. Higher scores are better, options that cannot be scored are scored NaN.
+type Scorer interface {
+	ScoreOptions(options []Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64
+}
@@ -17,38 +17,72 @@ limitations under the License.
 package factory
 
 import (
+	"fmt"
+	"time"
+
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/leastco2"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/leastnodes"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/mostpods"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/price"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/priority"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/warmcapacity"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/waste"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 
 	kube_client "k8s.io/client-go/kubernetes"
-	"k8s.io/klog/v2"
 )
 
 // Factory can create expander.Strategy based on provided expander names.
 type Factory struct {
-	createFunc map[string]func() expander.Filter
+	createFunc map[string]func() (expander.Filter, error)
+	// filters are the filters created so far, by name. Each filter is created once and shared by all the strategies
+	// built afterwards, so that rebuilding a strategy doesn't start its clients and informers again.
+	filters map[string]expander.Filter
 }
 
 // NewFactory returns a new Factory.
 func NewFactory() *Factory {
 	return &Factory{
-		createFunc: make(map[string]func() expander.Filter),
+		createFunc: make(map[string]func() (expander.Filter, error)),
+		filters:    make(map[string]expander.Filter),
 	}
 }
 
 // RegisterFilter registers a function that can provision a new expander.Filter under the specified name.
 func (f *Factory) RegisterFilter(name string, createFunc func() expander.Filter) {
+	f.RegisterFallibleFilter(name, func() (expander.Filter, error) {
+		return createFunc(), nil
+	})
+}
+
+// RegisterFallibleFilter registers a function that can provision a new expander.Filter under the specified name, or
+// return an error if the expander is misconfigured.
+func (f *Factory) RegisterFallibleFilter(name string, createFunc func() (expander.Filter, error)) {
 	f.createFunc[name] = createFunc
+	delete(f.filters, name)
+}
+
+// filter returns the expander.Filter registered under the specified name, creating it on first use.
+func (f *Factory) filter(name string) (expander.Filter, errors.AutoscalerError) {
+	if filter, found := f.filters[name]; found {
+		return filter, nil
+	}
+	create, known := f.createFunc[name]
+	if !known {
+		return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s not supported", name)
+	}
+	filter, err := create()
+	if err != nil {
+		return nil, errors.NewAutoscalerError(errors.ConfigurationError, "Couldn't create expander %s: %v", name, err)
+	}
+	f.filters[name] = filter
+	return filter, nil
 }
 
 // Build creates a new expander.Strategy based on a list of expander.Filter names.
@@ -65,12 +99,20 @@ func (f *Factory) Build(names []string) (expander.Strategy, errors.AutoscalerErr
 		}
 		seenExpanders[name] = struct{}{}
 
-		create, known := f.createFunc[name]
-		if known {
-			filters = append(filters, create())
-		} else {
-			return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s not supported", name)
+		if isWeighted(name) {
+			filter, err := f.buildWeighted(name)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+			continue
 		}
+
+		filter, err := f.filter(name)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
 		if _, ok := filters[len(filters)-1].(expander.Strategy); ok {
 			strategySeen = true
 		}
@@ -79,23 +121,57 @@ func (f *Factory) Build(names []string) (expander.Strategy, errors.AutoscalerErr
 }
 
 // RegisterDefaultExpanders is a convenience function, registering all known expanders in the Factory.
-func (f *Factory) RegisterDefaultExpanders(cloudProvider cloudprovider.CloudProvider, autoscalingKubeClients *context.AutoscalingKubeClients, kubeClient kube_client.Interface, configNamespace string, GRPCExpanderCert string, GRPCExpanderURL string) {
+func (f *Factory) RegisterDefaultExpanders(cloudProvider cloudprovider.CloudProvider, autoscalingKubeClients *context.AutoscalingKubeClients, kubeClient kube_client.Interface, configNamespace string, GRPCExpanderCert string, GRPCExpanderURL string, GRPCExpanderCacheTTL time.Duration, GRPCExpanderHealthCheckInterval time.Duration, GRPCExpanderFallback string, priceExpanderFile string, carbonIntensityRegions string, carbonIntensityURL string) {
 	f.RegisterFilter(expander.RandomExpanderName, random.NewFilter)
 	f.RegisterFilter(expander.MostPodsExpanderName, mostpods.NewFilter)
 	f.RegisterFilter(expander.LeastWasteExpanderName, waste.NewFilter)
 	f.RegisterFilter(expander.LeastNodesExpanderName, leastnodes.NewFilter)
-	f.RegisterFilter(expander.PriceBasedExpanderName, func() expander.Filter {
-		if _, err := cloudProvider.Pricing(); err != nil {
-			klog.Fatalf("Couldn't access cloud provider pricing for %s expander: %v", expander.PriceBasedExpanderName, err)
+	f.RegisterFilter(expander.WarmCapacityExpanderName, warmcapacity.NewFilter)
+	f.RegisterFallibleFilter(expander.PriceBasedExpanderName, func() (expander.Filter, error) {
+		var pricingModelProvider price.PricingModelProvider = cloudProvider
+		if priceExpanderFile != "" {
+			var err error
+			if pricingModelProvider, err = price.NewFilePricingModelProvider(priceExpanderFile); err != nil {
+				return nil, fmt.Errorf("couldn't load prices: %v", err)
+			}
+		}
+		if _, err := pricingModelProvider.Pricing(); err != nil {
+			return nil, fmt.Errorf("couldn't access cloud provider pricing: %v", err)
 		}
-		return price.NewFilter(cloudProvider, price.NewSimplePreferredNodeProvider(autoscalingKubeClients.AllNodeLister()), price.SimpleNodeUnfitness)
+		return price.NewFilterWithPricing(pricingModelProvider, cloudProvider.GPULabel(), price.NewSimplePreferredNodeProvider(autoscalingKubeClients.AllNodeLister()), price.SimpleNodeUnfitness), nil
 	})
 	f.RegisterFilter(expander.PriorityBasedExpanderName, func() expander.Filter {
 		// It seems other listers do the same here - they never receive the termination msg on the ch.
-		// This should be currently OK.
+		// This should be currently OK, as the filter is created only once.
 		stopChannel := make(chan struct{})
 		lister := kubernetes.NewConfigMapListerForNamespace(kubeClient, stopChannel, configNamespace)
 		return priority.NewFilter(lister.ConfigMaps(configNamespace), autoscalingKubeClients.Recorder)
 	})
-	f.RegisterFilter(expander.GRPCExpanderName, func() expander.Filter { return grpcplugin.NewFilter(GRPCExpanderCert, GRPCExpanderURL) })
+	f.RegisterFallibleFilter(expander.GRPCExpanderName, func() (expander.Filter, error) {
+		opts := grpcplugin.Options{CacheTTL: GRPCExpanderCacheTTL, HealthCheckInterval: GRPCExpanderHealthCheckInterval}
+		if GRPCExpanderFallback != "" {
+			if GRPCExpanderFallback == expander.GRPCExpanderName {
+				return nil, fmt.Errorf("fallback expander %s not supported", GRPCExpanderFallback)
+			}
+			fallback, err := f.filter(GRPCExpanderFallback)
+			if err != nil {
+				return nil, fmt.Errorf("fallback expander %s not supported: %v", GRPCExpanderFallback, err)
+			}
+			opts.Fallback = fallback
+		}
+		return grpcplugin.NewFilterWithOptionsOrError(GRPCExpanderCert, GRPCExpanderURL, opts)
+	})
+	f.RegisterFallibleFilter(expander.LeastCO2ExpanderName, func() (expander.Filter, error) {
+		if carbonIntensityRegions == "" && carbonIntensityURL == "" {
+			return nil, fmt.Errorf("either --carbon-intensity-regions or --carbon-intensity-url is required")
+		}
+		staticSource, err := leastco2.ParseStaticSource(carbonIntensityRegions)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse carbon intensities: %v", err)
+		}
+		if carbonIntensityURL == "" {
+			return leastco2.NewFilter(staticSource), nil
+		}
+		return leastco2.NewFilter(leastco2.NewFallbackSource(leastco2.NewHTTPSource(carbonIntensityURL), staticSource)), nil
+	})
 }
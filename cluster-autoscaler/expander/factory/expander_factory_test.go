@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+)
+
+func TestBuild(t *testing.T) {
+	created := map[string]int{}
+	f := NewFactory()
+	f.RegisterFilter("a", func() expander.Filter {
+		created["a"]++
+		return newSubstringTestFilterStrategy("a")
+	})
+	f.RegisterFallibleFilter("broken", func() (expander.Filter, error) {
+		created["broken"]++
+		return nil, fmt.Errorf("misconfigured")
+	})
+
+	for i := 0; i < 2; i++ {
+		strategy, err := f.Build([]string{"a"})
+		assert.NoError(t, err)
+		assert.NotNil(t, strategy)
+	}
+	_, err := f.Build([]string{"broken"})
+	assert.Error(t, err)
+	_, err = f.Build([]string{"unknown"})
+	assert.Error(t, err)
+
+	// Filters are created once and reused by later builds, failed creations are retried.
+	_, err = f.Build([]string{"broken"})
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "broken": 2}, created)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// weightedScoreTolerance is how close blended scores must be to be considered equal.
+const weightedScoreTolerance = 1e-9
+
+// isWeighted returns true if the expander name is a weighted composition of expanders, e.g. 0.7*price+0.3*least-waste.
+func isWeighted(name string) bool {
+	return strings.ContainsAny(name, "*+")
+}
+
+type weightedScorer struct {
+	name   string
+	weight float64
+	scorer expander.Scorer
+}
+
+// weightedFilter selects the options with the highest weighted sum of the scores of several expanders.
+type weightedFilter struct {
+	scorers []weightedScorer
+}
+
+// buildWeighted builds a filter from a weighted composition of expanders, e.g. 0.7*price+0.3*least-waste. Expanders
+// without a weight are weighted 1.
+func (f *Factory) buildWeighted(composition string) (expander.Filter, errors.AutoscalerError) {
+	filter := &weightedFilter{}
+	seenExpanders := map[string]struct{}{}
+	for _, term := range strings.Split(composition, "+") {
+		name, weight := strings.TrimSpace(term), 1.0
+		if weightString, weightedName, found := strings.Cut(term, "*"); found {
+			var err error
+			name = strings.TrimSpace(weightedName)
+			if weight, err = strconv.ParseFloat(strings.TrimSpace(weightString), 64); err != nil || weight <= 0 {
+				return nil, errors.NewAutoscalerError(errors.InternalError, "Weight %q of expander %s in %s must be a positive number", weightString, name, composition)
+			}
+		}
+		if _, ok := seenExpanders[name]; ok {
+			return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s was weighted multiple times in %s, each expander must not be weighted more than once", name, composition)
+		}
+		seenExpanders[name] = struct{}{}
+
+		created, err := f.filter(name)
+		if err != nil {
+			return nil, err
+		}
+		scorer, ok := created.(expander.Scorer)
+		if !ok {
+			return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s does not score options, it cannot be weighted", name)
+		}
+		filter.scorers = append(filter.scorers, weightedScorer{name: name, weight: weight, scorer: scorer})
+	}
+	return filter, nil
+}
+
+// BestOptions selects the options with the highest blended score. The scores of each expander are normalized to
+// [0, 1] across the options before they are weighted, options an expander cannot score get 0.
+func (w *weightedFilter) BestOptions(options []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	blendedScores := make([]float64, len(options))
+	for _, weighted := range w.scorers {
+		for i, score := range normalizeScores(weighted.scorer.ScoreOptions(options, nodeInfo)) {
+			blendedScores[i] += weighted.weight * score
+		}
+	}
+
+	var bestOptions []expander.Option
+	bestScore := math.Inf(-1)
+	for i, option := range options {
+		klog.V(1).Infof("Expansion option %s has a blended score of %0.3f", option.Debug, blendedScores[i])
+		switch {
+		case math.Abs(blendedScores[i]-bestScore) <= weightedScoreTolerance:
+			bestOptions = append(bestOptions, option)
+		case blendedScores[i] > bestScore:
+			bestScore = blendedScores[i]
+			bestOptions = []expander.Option{option}
+		}
+	}
+	return bestOptions
+}
+
+// normalizeScores scales the scores to [0, 1], the highest score to 1. NaN scores are scaled to 0, and all other
+// scores to 1 if they are equal.
+func normalizeScores(scores []float64) []float64 {
+	minScore, maxScore := math.Inf(1), math.Inf(-1)
+	for _, score := range scores {
+		if !math.IsNaN(score) {
+			minScore, maxScore = math.Min(minScore, score), math.Max(maxScore, score)
+		}
+	}
+	normalized := make([]float64, len(scores))
+	for i, score := range scores {
+		switch {
+		case math.IsNaN(score):
+			normalized[i] = 0
+		case maxScore == minScore:
+			normalized[i] = 1
+		default:
+			normalized[i] = (score - minScore) / (maxScore - minScore)
+		}
+	}
+	return normalized
+}
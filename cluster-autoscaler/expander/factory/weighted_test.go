@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// debugTestScorer scores options by their debug string, options that are not in its scores are scored NaN.
+type debugTestScorer struct {
+	scores map[string]float64
+}
+
+func (s *debugTestScorer) BestOptions(options []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	return options
+}
+
+func (s *debugTestScorer) ScoreOptions(options []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(options))
+	for i, option := range options {
+		score, found := s.scores[option.Debug]
+		if !found {
+			score = math.NaN()
+		}
+		scores[i] = score
+	}
+	return scores
+}
+
+func newWeightedTestFactory() *Factory {
+	f := NewFactory()
+	// cheap prefers a, then b, then c; green prefers c, then b, and cannot score a.
+	f.RegisterFilter("cheap", func() expander.Filter {
+		return &debugTestScorer{scores: map[string]float64{"a": -1, "b": -2, "c": -5}}
+	})
+	f.RegisterFilter("green", func() expander.Filter {
+		return &debugTestScorer{scores: map[string]float64{"b": -300, "c": -100}}
+	})
+	f.RegisterFilter("substring", func() expander.Filter { return newSubstringTestFilterStrategy("a") })
+	return f
+}
+
+func TestWeightedFilter(t *testing.T) {
+	options := []expander.Option{*newOption("a"), *newOption("b"), *newOption("c")}
+	for name, tc := range map[string]struct {
+		composition string
+		expected    []expander.Option
+	}{
+		"cheap outweighs green": {
+			composition: "0.7*cheap+0.3*green",
+			expected:    []expander.Option{*newOption("a")},
+		},
+		"green outweighs cheap": {
+			composition: "0.3*cheap + 0.7*green",
+			expected:    []expander.Option{*newOption("c")},
+		},
+		"unweighted expanders are weighted 1": {
+			composition: "cheap+green",
+			expected:    []expander.Option{*newOption("a"), *newOption("c")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			filter, err := newWeightedTestFactory().buildWeighted(tc.composition)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, filter.BestOptions(options, nil))
+		})
+	}
+}
+
+func TestBuildWeighted(t *testing.T) {
+	f := newWeightedTestFactory()
+	strategy, err := f.Build([]string{"0.5*cheap+0.5*green", "substring"})
+	assert.Nil(t, err)
+	assert.Equal(t, newOption("a"), strategy.BestOption([]expander.Option{*newOption("a"), *newOption("b")}, nil))
+
+	for _, composition := range []string{"-1*cheap+green", "x*cheap", "cheap+cheap", "cheap+unknown", "cheap+substring"} {
+		_, err := f.Build([]string{composition})
+		assert.NotNil(t, err, composition)
+	}
+}
+
+func TestNormalizeScores(t *testing.T) {
+	assert.Equal(t, []float64{1, 0, 0.5, 0}, normalizeScores([]float64{-1, -3, -2, math.NaN()}))
+	assert.Equal(t, []float64{1, 1, 0}, normalizeScores([]float64{2, 2, math.NaN()}))
+}
@@ -18,6 +18,7 @@ package grpcplugin
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
@@ -38,26 +40,69 @@ const (
 
 type grpcclientstrategy struct {
 	grpcClient protos.ExpanderClient
+	// fallback filters the options when the server is unhealthy or does not return valid best options. All options
+	// are returned if it is nil.
+	fallback expander.Filter
+	// cache holds the recent best options of the server, it is nil if caching is disabled.
+	cache *bestOptionsCache
+	// health tracks whether the server is healthy, it is nil if health checking is disabled.
+	health *healthChecker
+}
+
+// Options configure how the gRPC expander copes with a slow or unavailable server.
+type Options struct {
+	// CacheTTL is how long the best options returned by the server for the same expansion options are reused
+	// without calling it again. Caching is disabled if it is 0.
+	CacheTTL time.Duration
+	// HealthCheckInterval is how often the server is health checked. The server is not called while it is
+	// unhealthy. Health checking is disabled if it is 0.
+	HealthCheckInterval time.Duration
+	// Fallback filters the options when the server is unhealthy or does not return valid best options. All options
+	// are returned if it is nil.
+	Fallback expander.Filter
 }
 
 // NewFilter returns an expansion filter that creates a gRPC client, and calls out to a gRPC server
 func NewFilter(expanderCert string, expanderUrl string) expander.Filter {
-	client := createGRPCClient(expanderCert, expanderUrl)
-	if client == nil {
-		return &grpcclientstrategy{grpcClient: nil}
+	return NewFilterWithOptions(expanderCert, expanderUrl, Options{})
+}
+
+// NewFilterWithOptions returns an expansion filter that creates a gRPC client, and calls out to a gRPC server with
+// the specified caching, health checking and fallback options
+func NewFilterWithOptions(expanderCert string, expanderUrl string, opts Options) expander.Filter {
+	filter, err := NewFilterWithOptionsOrError(expanderCert, expanderUrl, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return filter
+}
+
+// NewFilterWithOptionsOrError is like NewFilterWithOptions, but returns an error instead of exiting if the gRPC
+// client can't be created
+func NewFilterWithOptionsOrError(expanderCert string, expanderUrl string, opts Options) (expander.Filter, error) {
+	conn, err := createGRPCConn(expanderCert, expanderUrl)
+	if err != nil {
+		return nil, err
+	}
+	g := &grpcclientstrategy{grpcClient: protos.NewExpanderClient(conn), fallback: opts.Fallback}
+	if opts.CacheTTL > 0 {
+		g.cache = newBestOptionsCache(opts.CacheTTL)
+	}
+	if opts.HealthCheckInterval > 0 {
+		g.health = newHealthChecker(healthpb.NewHealthClient(conn))
+		// Like the other long-lived expander components, the health checker is never stopped.
+		go g.health.run(opts.HealthCheckInterval, make(chan struct{}))
 	}
-	return &grpcclientstrategy{grpcClient: client}
+	return g, nil
 }
 
-func createGRPCClient(expanderCert string, expanderUrl string) protos.ExpanderClient {
+func createGRPCConn(expanderCert string, expanderUrl string) (*grpc.ClientConn, error) {
 	if expanderCert == "" {
-		log.Fatalf("GRPC Expander Cert not specified, insecure connections not allowed")
-		return nil
+		return nil, fmt.Errorf("GRPC Expander Cert not specified, insecure connections not allowed")
 	}
 	creds, err := credentials.NewClientTLSFromFile(expanderCert, "")
 	if err != nil {
-		log.Fatalf("Failed to create TLS credentials %v", err)
-		return nil
+		return nil, fmt.Errorf("Failed to create TLS credentials %v", err)
 	}
 	dialOpts := []grpc.DialOption{
 		grpc.WithTransportCredentials(creds),
@@ -66,20 +111,29 @@ func createGRPCClient(expanderCert string, expanderUrl string) protos.ExpanderCl
 	klog.V(2).Infof("Dialing: %s with dialopt: %v", expanderUrl, dialOpts)
 	conn, err := grpc.Dial(expanderUrl, dialOpts...)
 	if err != nil {
-		log.Fatalf("Fail to dial server: %v", err)
-		return nil
+		return nil, fmt.Errorf("Fail to dial server: %v", err)
 	}
-	return protos.NewExpanderClient(conn)
+	return conn, nil
 }
 
 func (g *grpcclientstrategy) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
 	if g.grpcClient == nil {
 		klog.Errorf("Incorrect gRPC client config, filtering no options")
-		return expansionOptions
+		return g.fallbackOptions(expansionOptions, nodeInfo)
 	}
 
 	// Transform inputs to gRPC inputs
 	grpcOptionsSlice, nodeGroupIDOptionMap := populateOptionsForGRPC(expansionOptions)
+
+	cacheKey := bestOptionsCacheKey(expansionOptions)
+	if bestNodeGroupIDs, found := g.cache.get(cacheKey); found {
+		klog.V(4).Infof("Using cached gRPC best options for %v options", len(nodeGroupIDOptionMap))
+		return nodeGroupOptions(bestNodeGroupIDs, nodeGroupIDOptionMap)
+	}
+	if !g.health.isHealthy() {
+		klog.V(2).Info("GRPC server is unhealthy, falling back")
+		return g.fallbackOptions(expansionOptions, nodeInfo)
+	}
 	grpcNodeMap := populateNodeInfoForGRPC(nodeInfo)
 
 	// call gRPC server to get BestOption
@@ -89,22 +143,32 @@ func (g *grpcclientstrategy) BestOptions(expansionOptions []expander.Option, nod
 	bestOptionsResponse, err := g.grpcClient.BestOptions(ctx, &protos.BestOptionsRequest{Options: grpcOptionsSlice, NodeMap: grpcNodeMap})
 	if err != nil {
 		klog.V(4).Infof("GRPC call failed, no options filtered: %v", err)
-		return expansionOptions
+		g.health.callFailed(err)
+		return g.fallbackOptions(expansionOptions, nodeInfo)
 	}
 
 	if bestOptionsResponse == nil || bestOptionsResponse.Options == nil {
 		klog.V(4).Info("GRPC returned nil bestOptions, no options filtered")
-		return expansionOptions
+		return g.fallbackOptions(expansionOptions, nodeInfo)
 	}
 	// Transform back options slice
 	options := transformAndSanitizeOptionsFromGRPC(bestOptionsResponse.Options, nodeGroupIDOptionMap)
 	if options == nil {
 		klog.V(4).Info("Unable to sanitize GPRC returned bestOptions, no options filtered")
-		return expansionOptions
+		return g.fallbackOptions(expansionOptions, nodeInfo)
 	}
+	g.cache.put(cacheKey, options)
 	return options
 }
 
+// fallbackOptions returns the options filtered by the fallback expander, or all options if there is none.
+func (g *grpcclientstrategy) fallbackOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	if g.fallback == nil {
+		return expansionOptions
+	}
+	return g.fallback.BestOptions(expansionOptions, nodeInfo)
+}
+
 // populateOptionsForGRPC creates a map of nodegroup ID and options, as well as a slice of Options objects for the gRPC call
 func populateOptionsForGRPC(expansionOptions []expander.Option) ([]*protos.Option, map[string]expander.Option) {
 	grpcOptionsSlice := []*protos.Option{}
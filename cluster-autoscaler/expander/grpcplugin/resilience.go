@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/klog/v2"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type cachedBestOptions struct {
+	nodeGroupIDs []string
+	expires      time.Time
+}
+
+// bestOptionsCache holds the node groups of the best options the server returned for recent expansion options. A nil
+// cache caches nothing.
+type bestOptionsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedBestOptions
+}
+
+func newBestOptionsCache(ttl time.Duration) *bestOptionsCache {
+	return &bestOptionsCache{ttl: ttl, now: time.Now, entries: map[string]cachedBestOptions{}}
+}
+
+// bestOptionsCacheKey identifies the expansion options by their node groups, node counts and pods.
+func bestOptionsCacheKey(expansionOptions []expander.Option) string {
+	var key strings.Builder
+	for _, option := range expansionOptions {
+		fmt.Fprintf(&key, "%s:%d", option.NodeGroup.Id(), option.NodeCount)
+		for _, pod := range option.Pods {
+			fmt.Fprintf(&key, ",%s/%s", pod.Namespace, pod.Name)
+		}
+		key.WriteString(";")
+	}
+	return key.String()
+}
+
+// get returns the node groups of the cached best options, if they have not expired.
+func (c *bestOptionsCache) get(key string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.nodeGroupIDs, true
+}
+
+// put caches the best options, and forgets the ones that expired.
+func (c *bestOptionsCache) put(key string, bestOptions []expander.Option) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	nodeGroupIDs := make([]string, 0, len(bestOptions))
+	for _, option := range bestOptions {
+		nodeGroupIDs = append(nodeGroupIDs, option.NodeGroup.Id())
+	}
+	c.entries[key] = cachedBestOptions{nodeGroupIDs: nodeGroupIDs, expires: now.Add(c.ttl)}
+}
+
+// nodeGroupOptions returns the options of the node groups.
+func nodeGroupOptions(nodeGroupIDs []string, nodeGroupIDOptionMap map[string]expander.Option) []expander.Option {
+	options := make([]expander.Option, 0, len(nodeGroupIDs))
+	for _, id := range nodeGroupIDs {
+		options = append(options, nodeGroupIDOptionMap[id])
+	}
+	return options
+}
+
+// healthChecker tracks whether the server is healthy with the standard gRPC health checking protocol. Servers that
+// do not implement it are healthy as long as they respond. A nil health checker is always healthy.
+type healthChecker struct {
+	client healthpb.HealthClient
+
+	mu      sync.Mutex
+	healthy bool
+}
+
+func newHealthChecker(client healthpb.HealthClient) *healthChecker {
+	return &healthChecker{client: client, healthy: true}
+}
+
+// run health checks the server every interval until stop is closed.
+func (h *healthChecker) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.check()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check health checks the server once.
+func (h *healthChecker) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), gRPCTimeout)
+	defer cancel()
+	resp, err := h.client.Check(ctx, &healthpb.HealthCheckRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		h.setHealthy(true, "it does not implement health checking but responds")
+	case err != nil:
+		h.setHealthy(false, err.Error())
+	case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+		h.setHealthy(false, fmt.Sprintf("it is %s", resp.GetStatus()))
+	default:
+		h.setHealthy(true, "it is serving")
+	}
+}
+
+// callFailed marks the server unhealthy until its next successful health check if the error shows that it is
+// unreachable or too slow, so the following scale-ups do not wait for it to time out again.
+func (h *healthChecker) callFailed(err error) {
+	if h == nil {
+		return
+	}
+	if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded {
+		h.setHealthy(false, err.Error())
+	}
+}
+
+func (h *healthChecker) isHealthy() bool {
+	if h == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.healthy
+}
+
+func (h *healthChecker) setHealthy(healthy bool, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.healthy != healthy {
+		if healthy {
+			klog.Infof("GRPC expander server is healthy again, %s", reason)
+		} else {
+			klog.Warningf("GRPC expander server is unhealthy, falling back until it is healthy again: %s", reason)
+		}
+	}
+	h.healthy = healthy
+}
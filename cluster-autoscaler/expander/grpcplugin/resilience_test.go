@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin/protos"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/mocks"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type fakeHealthClient struct {
+	resp *healthpb.HealthCheckResponse
+	err  error
+}
+
+func (c *fakeHealthClient) Check(ctx context.Context, in *healthpb.HealthCheckRequest, opts ...grpc.CallOption) (*healthpb.HealthCheckResponse, error) {
+	return c.resp, c.err
+}
+
+func (c *fakeHealthClient) Watch(ctx context.Context, in *healthpb.HealthCheckRequest, opts ...grpc.CallOption) (healthpb.Health_WatchClient, error) {
+	return nil, status.Error(codes.Unimplemented, "")
+}
+
+// firstOptionFilter is a fallback that selects the first option.
+type firstOptionFilter struct{}
+
+func (f *firstOptionFilter) BestOptions(options []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	return options[:1]
+}
+
+func TestBestOptionsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockExpanderClient(ctrl)
+	now := time.Now()
+	cache := newBestOptionsCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	g := &grpcclientstrategy{grpcClient: mockClient, cache: cache}
+
+	mockClient.EXPECT().BestOptions(gomock.Any(), gomock.Any()).
+		Return(&protos.BestOptionsResponse{Options: []*protos.Option{&grpcEoT3Large}}, nil).Times(2)
+
+	assert.Equal(t, []expander.Option{eoT3Large}, g.BestOptions(options, makeFakeNodeInfos()))
+	// The server is not called again for the same options until the cached best options expire.
+	assert.Equal(t, []expander.Option{eoT3Large}, g.BestOptions(options, makeFakeNodeInfos()))
+	now = now.Add(time.Minute)
+	assert.Equal(t, []expander.Option{eoT3Large}, g.BestOptions(options, makeFakeNodeInfos()))
+}
+
+func TestBestOptionsFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockExpanderClient(ctrl)
+	health := newHealthChecker(&fakeHealthClient{resp: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}})
+	g := &grpcclientstrategy{grpcClient: mockClient, fallback: &firstOptionFilter{}, health: health}
+
+	// The server timing out falls back, and marks it unhealthy so it is not called again.
+	mockClient.EXPECT().BestOptions(gomock.Any(), gomock.Any()).
+		Return(nil, status.Error(codes.DeadlineExceeded, "timeout")).Times(1)
+	assert.Equal(t, []expander.Option{eoT2Micro}, g.BestOptions(options, makeFakeNodeInfos()))
+	assert.False(t, health.isHealthy())
+	assert.Equal(t, []expander.Option{eoT2Micro}, g.BestOptions(options, makeFakeNodeInfos()))
+
+	// The server is called again once it is healthy.
+	health.check()
+	mockClient.EXPECT().BestOptions(gomock.Any(), gomock.Any()).
+		Return(&protos.BestOptionsResponse{Options: []*protos.Option{&grpcEoT3Large}}, nil).Times(1)
+	assert.Equal(t, []expander.Option{eoT3Large}, g.BestOptions(options, makeFakeNodeInfos()))
+}
+
+func TestHealthCheckerCheck(t *testing.T) {
+	for name, tc := range map[string]struct {
+		client  *fakeHealthClient
+		healthy bool
+	}{
+		"serving":                         {client: &fakeHealthClient{resp: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}}, healthy: true},
+		"not serving":                     {client: &fakeHealthClient{resp: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}}, healthy: false},
+		"unavailable":                     {client: &fakeHealthClient{err: status.Error(codes.Unavailable, "connection refused")}, healthy: false},
+		"health checking not implemented": {client: &fakeHealthClient{err: status.Error(codes.Unimplemented, "unknown service")}, healthy: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			health := newHealthChecker(tc.client)
+			health.healthy = !tc.healthy
+			health.check()
+			assert.Equal(t, tc.healthy, health.isHealthy())
+		})
+	}
+
+	// Calls failing for other reasons than the server being unreachable or slow do not mark it unhealthy.
+	health := newHealthChecker(&fakeHealthClient{})
+	health.callFailed(status.Error(codes.InvalidArgument, "bad request"))
+	assert.True(t, health.isHealthy())
+}
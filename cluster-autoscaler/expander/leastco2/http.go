@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leastco2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// httpSourceTimeout is how long a request to the carbon intensity provider may take.
+	httpSourceTimeout = 5 * time.Second
+	// httpSourceTTL is how long carbon intensities are cached. Grid carbon intensity data is usually updated no more
+	// often than every few minutes.
+	httpSourceTTL = 5 * time.Minute
+)
+
+// httpSourceResponse is the body an HTTP carbon intensity provider responds with.
+type httpSourceResponse struct {
+	// CarbonIntensity is in gCO2eq/kWh.
+	CarbonIntensity *float64 `json:"carbonIntensity"`
+}
+
+type cachedIntensity struct {
+	intensity float64
+	expires   time.Time
+}
+
+// HTTPSource gets carbon intensities from an HTTP provider. The provider is called with GET <url>?region=<region>&zone=<zone>
+// and responds with a JSON object like {"carbonIntensity": 350.5}. Responses are cached for a few minutes.
+type HTTPSource struct {
+	url    string
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedIntensity
+	now   func() time.Time
+}
+
+// NewHTTPSource returns a source that gets carbon intensities from the HTTP provider at the specified URL.
+func NewHTTPSource(providerURL string) *HTTPSource {
+	return &HTTPSource{
+		url:    providerURL,
+		client: &http.Client{Timeout: httpSourceTimeout},
+		cache:  map[string]cachedIntensity{},
+		now:    time.Now,
+	}
+}
+
+// CarbonIntensity returns the carbon intensity the provider reports for the region and zone.
+func (s *HTTPSource) CarbonIntensity(region, zone string) (float64, error) {
+	key := region + "/" + zone
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.intensity, nil
+	}
+
+	requestURL, err := url.Parse(s.url)
+	if err != nil {
+		return 0, err
+	}
+	query := requestURL.Query()
+	query.Set("region", region)
+	query.Set("zone", zone)
+	requestURL.RawQuery = query.Encode()
+
+	resp, err := s.client.Get(requestURL.String())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("carbon intensity provider responded with %s", resp.Status)
+	}
+	var body httpSourceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("unable to decode carbon intensity: %v", err)
+	}
+	if body.CarbonIntensity == nil {
+		return 0, fmt.Errorf("carbon intensity provider did not report the carbon intensity of region %q zone %q", region, zone)
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedIntensity{intensity: *body.CarbonIntensity, expires: s.now().Add(httpSourceTTL)}
+	s.mu.Unlock()
+	return *body.CarbonIntensity, nil
+}
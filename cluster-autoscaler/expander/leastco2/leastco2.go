@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leastco2
+
+import (
+	"math"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// CarbonIntensitySource provides the carbon intensity of the electricity in the regions and zones node groups are in.
+type CarbonIntensitySource interface {
+	// CarbonIntensity returns the carbon intensity, in gCO2eq/kWh, of the specified zone of the specified region. The
+	// zone is empty if the node group does not report one.
+	CarbonIntensity(region, zone string) (float64, error)
+}
+
+type leastco2 struct {
+	source CarbonIntensitySource
+}
+
+// NewFilter returns a filter that selects the node groups in the regions or zones with the lowest carbon intensity
+func NewFilter(source CarbonIntensitySource) expander.Filter {
+	return &leastco2{source: source}
+}
+
+// BestOptions selects the expansion options whose node groups are in the region or zone with the lowest carbon
+// intensity. Options with an unknown carbon intensity are only returned if no option has a known one.
+func (l *leastco2) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	leastIntensity := math.Inf(1)
+	var leastOptions []expander.Option
+
+	for _, option := range expansionOptions {
+		intensity, found := l.carbonIntensity(option, nodeInfo)
+		if !found {
+			continue
+		}
+
+		if intensity == leastIntensity {
+			leastOptions = append(leastOptions, option)
+			continue
+		}
+		if intensity < leastIntensity {
+			leastIntensity = intensity
+			leastOptions = []expander.Option{option}
+		}
+	}
+
+	if len(leastOptions) == 0 {
+		return expansionOptions
+	}
+	return leastOptions
+}
+
+// ScoreOptions scores the expansion options by the negated carbon intensity of their region or zone
+func (l *leastco2) ScoreOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	for i, option := range expansionOptions {
+		intensity, found := l.carbonIntensity(option, nodeInfo)
+		if !found {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = -intensity
+	}
+	return scores
+}
+
+// carbonIntensity returns the carbon intensity of the region or zone of the node group of the option, if it is known
+func (l *leastco2) carbonIntensity(option expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) (float64, bool) {
+	info, found := nodeInfo[option.NodeGroup.Id()]
+	if !found || info.Node() == nil {
+		klog.Errorf("No node info for: %s", option.NodeGroup.Id())
+		return 0, false
+	}
+	region, zone := topology(info.Node())
+	intensity, err := l.source.CarbonIntensity(region, zone)
+	if err != nil {
+		klog.V(2).Infof("Unknown carbon intensity of node group %s in region %q zone %q: %v", option.NodeGroup.Id(), region, zone, err)
+		return 0, false
+	}
+	klog.V(1).Infof("Expanding Node Group %s would use electricity with a carbon intensity of %0.2f gCO2eq/kWh", option.NodeGroup.Id(), intensity)
+	return intensity, true
+}
+
+// topology returns the region and zone of the node, preferring the stable topology labels over the deprecated ones.
+func topology(node *apiv1.Node) (region, zone string) {
+	region = node.Labels[apiv1.LabelTopologyRegion]
+	if region == "" {
+		region = node.Labels[apiv1.LabelFailureDomainBetaRegion]
+	}
+	zone = node.Labels[apiv1.LabelTopologyZone]
+	if zone == "" {
+		zone = node.Labels[apiv1.LabelFailureDomainBetaZone]
+	}
+	return region, zone
+}
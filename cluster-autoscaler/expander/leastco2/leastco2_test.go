@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leastco2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makeNodeInfo(name string, labels map[string]string) *schedulerframework.NodeInfo {
+	node := BuildTestNode(name, 1000, 1000)
+	node.Labels = labels
+	nodeInfo := schedulerframework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	return nodeInfo
+}
+
+func TestLeastCO2(t *testing.T) {
+	nodeInfos := map[string]*schedulerframework.NodeInfo{
+		"phx":     makeNodeInfo("phx", map[string]string{apiv1.LabelTopologyRegion: "us-phoenix-1", apiv1.LabelTopologyZone: "PHX-AD-1"}),
+		"fra":     makeNodeInfo("fra", map[string]string{apiv1.LabelTopologyRegion: "eu-frankfurt-1", apiv1.LabelTopologyZone: "FRA-AD-1"}),
+		"fra2":    makeNodeInfo("fra2", map[string]string{apiv1.LabelFailureDomainBetaRegion: "eu-frankfurt-1"}),
+		"sto":     makeNodeInfo("sto", map[string]string{apiv1.LabelTopologyRegion: "eu-stockholm-1", apiv1.LabelTopologyZone: "STO-AD-1"}),
+		"unknown": makeNodeInfo("unknown", map[string]string{apiv1.LabelTopologyRegion: "ap-tokyo-1"}),
+	}
+	option := func(id string) expander.Option {
+		return expander.Option{NodeGroup: testprovider.NewTestNodeGroup(id, 10, 0, 1, true, false, "", nil, nil), Debug: id}
+	}
+	source := StaticSource{"us-phoenix-1": 390, "eu-frankfurt-1": 350, "STO-AD-1": 10, "eu-stockholm-1": 500}
+
+	for _, tc := range []struct {
+		name     string
+		options  []string
+		expected []string
+	}{
+		{name: "no options"},
+		{name: "lowest region", options: []string{"phx", "fra"}, expected: []string{"fra"}},
+		{name: "zone takes precedence over region", options: []string{"phx", "sto"}, expected: []string{"sto"}},
+		{name: "deprecated labels, equal intensities", options: []string{"phx", "fra", "fra2"}, expected: []string{"fra", "fra2"}},
+		{name: "unknown intensities are skipped", options: []string{"unknown", "phx"}, expected: []string{"phx"}},
+		{name: "all unknown intensities", options: []string{"unknown", "missing"}, expected: []string{"unknown", "missing"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var options, expected []expander.Option
+			for _, id := range tc.options {
+				options = append(options, option(id))
+			}
+			for _, id := range tc.expected {
+				expected = append(expected, option(id))
+			}
+			assert.Equal(t, expected, NewFilter(source).BestOptions(options, nodeInfos))
+		})
+	}
+}
+
+func TestParseStaticSource(t *testing.T) {
+	source, err := ParseStaticSource(" us-phoenix-1=390, PHX-AD-1 = 12.5,")
+	assert.NoError(t, err)
+	assert.Equal(t, StaticSource{"us-phoenix-1": 390, "PHX-AD-1": 12.5}, source)
+
+	source, err = ParseStaticSource("")
+	assert.NoError(t, err)
+	assert.Empty(t, source)
+
+	for _, spec := range []string{"us-phoenix-1", "=390", "us-phoenix-1=high", "us-phoenix-1=-1"} {
+		_, err := ParseStaticSource(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("region") {
+		case "us-phoenix-1":
+			fmt.Fprintf(w, `{"carbonIntensity": 390}`)
+		case "eu-frankfurt-1":
+			fmt.Fprintf(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	source := NewHTTPSource(server.URL + "/intensity?key=value")
+	source.now = func() time.Time { return now }
+
+	intensity, err := source.CarbonIntensity("us-phoenix-1", "PHX-AD-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 390.0, intensity)
+
+	// Carbon intensities are cached until they expire.
+	_, _ = source.CarbonIntensity("us-phoenix-1", "PHX-AD-1")
+	assert.Equal(t, 1, requests)
+	now = now.Add(httpSourceTTL + time.Second)
+	_, _ = source.CarbonIntensity("us-phoenix-1", "PHX-AD-1")
+	assert.Equal(t, 2, requests)
+
+	_, err = source.CarbonIntensity("eu-frankfurt-1", "")
+	assert.Error(t, err)
+	_, err = source.CarbonIntensity("ap-tokyo-1", "")
+	assert.Error(t, err)
+
+	// The static table is used when the provider does not know the carbon intensity.
+	fallback := NewFallbackSource(source, StaticSource{"ap-tokyo-1": 480})
+	intensity, err = fallback.CarbonIntensity("ap-tokyo-1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 480.0, intensity)
+	_, err = fallback.CarbonIntensity("sa-saopaulo-1", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leastco2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StaticSource is a fixed table of carbon intensities, in gCO2eq/kWh, by zone or region. The carbon intensity of a
+// zone takes precedence over the one of its region.
+type StaticSource map[string]float64
+
+// ParseStaticSource parses a comma-separated list of region or zone to carbon intensity pairs, e.g.
+// "us-phoenix-1=390,eu-frankfurt-1=350".
+func ParseStaticSource(spec string) (StaticSource, error) {
+	source := StaticSource{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid carbon intensity %q, expected <region or zone>=<gCO2eq/kWh>", pair)
+		}
+		intensity, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || intensity < 0 {
+			return nil, fmt.Errorf("invalid carbon intensity %q, expected a non-negative number of gCO2eq/kWh", pair)
+		}
+		source[strings.TrimSpace(name)] = intensity
+	}
+	return source, nil
+}
+
+// CarbonIntensity returns the carbon intensity of the zone, or of the region if the zone is not in the table.
+func (s StaticSource) CarbonIntensity(region, zone string) (float64, error) {
+	if intensity, ok := s[zone]; ok && zone != "" {
+		return intensity, nil
+	}
+	if intensity, ok := s[region]; ok && region != "" {
+		return intensity, nil
+	}
+	return 0, fmt.Errorf("no carbon intensity for region %q zone %q", region, zone)
+}
+
+// fallbackSource returns the carbon intensity of the first of its sources that knows it.
+type fallbackSource []CarbonIntensitySource
+
+// NewFallbackSource returns a source that asks the sources in order, e.g. an HTTP provider falling back to a static
+// table when it is unreachable.
+func NewFallbackSource(sources ...CarbonIntensitySource) CarbonIntensitySource {
+	return fallbackSource(sources)
+}
+
+// CarbonIntensity returns the carbon intensity known by the first source, or the error of the last source.
+func (s fallbackSource) CarbonIntensity(region, zone string) (float64, error) {
+	err := fmt.Errorf("no carbon intensity source")
+	for _, source := range s {
+		var intensity float64
+		if intensity, err = source.CarbonIntensity(region, zone); err == nil {
+			return intensity, nil
+		}
+	}
+	return 0, err
+}
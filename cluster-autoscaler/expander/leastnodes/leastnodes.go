@@ -59,3 +59,16 @@ func (m *leastnodes) BestOptions(expansionOptions []expander.Option, nodeInfo ma
 
 	return leastOptions
 }
+
+// ScoreOptions scores the expansion options by the negated number of nodes they use
+func (m *leastnodes) ScoreOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	for i, option := range expansionOptions {
+		if option.NodeCount == 0 {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = -float64(option.NodeCount)
+	}
+	return scores
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package leastnodes
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -111,3 +112,14 @@ func TestLeastNodes(t *testing.T) {
 		})
 	}
 }
+
+func TestLeastNodesScoreOptions(t *testing.T) {
+	scores := NewFilter().(expander.Scorer).ScoreOptions([]expander.Option{
+		{Debug: "EO0", NodeCount: 2},
+		{Debug: "EO1", NodeCount: 0},
+		{Debug: "EO2", NodeCount: 5},
+	}, nil)
+	assert.Equal(t, -2.0, scores[0])
+	assert.True(t, math.IsNaN(scores[1]))
+	assert.Equal(t, -5.0, scores[2])
+}
@@ -52,3 +52,12 @@ func (m *mostpods) BestOptions(expansionOptions []expander.Option, nodeInfo map[
 
 	return maxOptions
 }
+
+// ScoreOptions scores the expansion options by the number of pods they schedule
+func (m *mostpods) ScoreOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	for i, option := range expansionOptions {
+		scores[i] = float64(len(option.Pods))
+	}
+	return scores
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package price
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+
+	klog "k8s.io/klog/v2"
+)
+
+// HourlyPrices are the hourly prices of a machine or of the resources requested by pods. Prices that are not set are
+// not charged.
+type HourlyPrices struct {
+	// PricePerHour is the fixed price of a machine.
+	PricePerHour float64 `json:"pricePerHour,omitempty"`
+	// PricePerCPUHour is the price of a CPU.
+	PricePerCPUHour float64 `json:"pricePerCpuHour,omitempty"`
+	// PricePerMemoryGiBHour is the price of a GiB of memory.
+	PricePerMemoryGiBHour float64 `json:"pricePerMemoryGiBHour,omitempty"`
+	// PricePerGPUHour is the price of a GPU.
+	PricePerGPUHour float64 `json:"pricePerGpuHour,omitempty"`
+}
+
+// FilePrices are the prices of a price file, e.g. mounted from a ConfigMap:
+//
+//	{
+//	  "instanceTypes": {
+//	    "n1-standard-4": {"pricePerHour": 0.19},
+//	    "VM.Standard.E4.Flex": {"pricePerCpuHour": 0.025, "pricePerMemoryGiBHour": 0.0015}
+//	  },
+//	  "pods": {"pricePerCpuHour": 0.0125, "pricePerMemoryGiBHour": 0.0015}
+//	}
+//
+// Nodes are priced by the prices of their instance type, applied to their capacity. Pods are priced by the pod prices,
+// applied to their requests.
+type FilePrices struct {
+	InstanceTypes map[string]HourlyPrices `json:"instanceTypes"`
+	Pods          HourlyPrices            `json:"pods"`
+}
+
+// LoadFilePrices reads the prices of the specified JSON price file.
+func LoadFilePrices(path string) (*FilePrices, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read price file: %v", err)
+	}
+	var prices FilePrices
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("unable to parse price file %s: %v", path, err)
+	}
+	if len(prices.InstanceTypes) == 0 {
+		return nil, fmt.Errorf("price file %s has no instance types", path)
+	}
+	return &prices, nil
+}
+
+// NodePrice implements cloudprovider.PricingModel.
+func (p *FilePrices) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	instanceType := node.Labels[apiv1.LabelInstanceTypeStable]
+	if instanceType == "" {
+		instanceType = node.Labels[apiv1.LabelInstanceType]
+	}
+	prices, found := p.InstanceTypes[instanceType]
+	if !found {
+		return 0, fmt.Errorf("instance type %q of node %s is not in the price file", instanceType, node.Name)
+	}
+	gpus := node.Status.Capacity[gpu.ResourceNvidiaGPU]
+	price := prices.PricePerHour + prices.price(node.Status.Capacity.Cpu().MilliValue(), node.Status.Capacity.Memory().Value(), gpus.Value())
+	return price * endTime.Sub(startTime).Hours(), nil
+}
+
+// PodPrice implements cloudprovider.PricingModel.
+func (p *FilePrices) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	var millicpus, memory, gpus int64
+	for _, container := range pod.Spec.Containers {
+		millicpus += container.Resources.Requests.Cpu().MilliValue()
+		memory += container.Resources.Requests.Memory().Value()
+		if request, found := container.Resources.Requests[gpu.ResourceNvidiaGPU]; found {
+			gpus += request.Value()
+		}
+	}
+	return p.Pods.price(millicpus, memory, gpus) * endTime.Sub(startTime).Hours(), nil
+}
+
+// price returns the hourly price of the resources.
+func (h HourlyPrices) price(millicpus, memory, gpus int64) float64 {
+	return float64(millicpus)/1000*h.PricePerCPUHour + float64(memory)/units.GiB*h.PricePerMemoryGiBHour +
+		float64(gpus)*h.PricePerGPUHour
+}
+
+// filePricingModelProvider provides the prices of a price file, and reloads them when the file is modified, e.g.
+// when the ConfigMap it is mounted from is updated.
+type filePricingModelProvider struct {
+	path string
+
+	mu      sync.Mutex
+	prices  *FilePrices
+	modTime time.Time
+}
+
+// NewFilePricingModelProvider returns a pricing model provider with the prices of the specified price file. Any cloud
+// provider can be priced by it, whether or not it implements pricing.
+func NewFilePricingModelProvider(path string) (PricingModelProvider, error) {
+	p := &filePricingModelProvider{path: path}
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Pricing returns the prices of the price file. The last prices that were loaded are kept when the file cannot be
+// reloaded.
+func (p *filePricingModelProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
+	if err := p.reload(); err != nil {
+		klog.Warningf("Failed to reload price file, using the last prices loaded: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.prices, nil
+}
+
+// reload loads the price file if it was modified since it was last loaded.
+func (p *filePricingModelProvider) reload() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("unable to read price file: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prices != nil && info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+	prices, err := LoadFilePrices(p.path)
+	if err != nil {
+		return err
+	}
+	p.prices, p.modTime = prices, info.ModTime()
+	return nil
+}
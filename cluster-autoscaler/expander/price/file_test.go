@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package price
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+)
+
+const testPrices = `{
+  "instanceTypes": {
+    "fixed": {"pricePerHour": 0.5},
+    "flex": {"pricePerCpuHour": 0.25, "pricePerMemoryGiBHour": 0.125, "pricePerGpuHour": 2}
+  },
+  "pods": {"pricePerCpuHour": 0.1, "pricePerMemoryGiBHour": 0.01}
+}`
+
+func writePriceFile(t *testing.T, path, prices string) {
+	if err := os.WriteFile(path, []byte(prices), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFilePrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	writePriceFile(t, path, testPrices)
+	prices, err := LoadFilePrices(path)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	then := now.Add(2 * time.Hour)
+
+	fixed := BuildTestNode("fixed", 4000, 16*units.GiB)
+	fixed.Labels = map[string]string{apiv1.LabelInstanceTypeStable: "fixed"}
+	price, err := prices.NodePrice(fixed, now, then)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0, price, 1e-9)
+
+	flex := BuildTestNode("flex", 2000, 8*units.GiB)
+	flex.Labels = map[string]string{apiv1.LabelInstanceType: "flex"}
+	flex.Status.Capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	price, err = prices.NodePrice(flex, now, then)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*(2*0.25+8*0.125+2), price, 1e-9)
+
+	unknown := BuildTestNode("unknown", 2000, 8*units.GiB)
+	_, err = prices.NodePrice(unknown, now, then)
+	assert.Error(t, err)
+
+	pod := BuildTestPod("pod", 500, units.GiB)
+	price, err = prices.PodPrice(pod, now, then)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*(0.5*0.1+0.01), price, 1e-9)
+
+	for _, invalid := range []string{"", "{", `{"pods": {"pricePerCpuHour": 0.1}}`} {
+		writePriceFile(t, path, invalid)
+		_, err := LoadFilePrices(path)
+		assert.Error(t, err, invalid)
+	}
+	_, err = LoadFilePrices(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestFilePricingModelProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	_, err := NewFilePricingModelProvider(path)
+	assert.Error(t, err)
+
+	writePriceFile(t, path, testPrices)
+	provider, err := NewFilePricingModelProvider(path)
+	assert.NoError(t, err)
+	node := BuildTestNode("n1", 1000, units.GiB)
+	node.Labels = map[string]string{apiv1.LabelInstanceTypeStable: "fixed"}
+	now := time.Now()
+
+	// The prices are reloaded when the file is modified.
+	writePriceFile(t, path, `{"instanceTypes": {"fixed": {"pricePerHour": 3}}}`)
+	assert.NoError(t, os.Chtimes(path, now, now.Add(time.Minute)))
+	pricingModel, err := provider.Pricing()
+	assert.Nil(t, err)
+	price, _ := pricingModel.NodePrice(node, now, now.Add(time.Hour))
+	assert.InDelta(t, 3.0, price, 1e-9)
+
+	// The last prices loaded are kept when the file is invalid.
+	writePriceFile(t, path, "{")
+	assert.NoError(t, os.Chtimes(path, now, now.Add(2*time.Minute)))
+	pricingModel, err = provider.Pricing()
+	assert.Nil(t, err)
+	price, _ = pricingModel.NodePrice(node, now, now.Add(time.Hour))
+	assert.InDelta(t, 3.0, price, 1e-9)
+}
@@ -40,7 +40,8 @@ import (
 // **********
 
 type priceBased struct {
-	cloudProvider         cloudprovider.CloudProvider
+	pricingModelProvider  PricingModelProvider
+	gpuLabel              string
 	preferredNodeProvider PreferredNodeProvider
 	nodeUnfitness         NodeUnfitness
 }
@@ -78,9 +79,20 @@ var (
 func NewFilter(cloudProvider cloudprovider.CloudProvider,
 	preferredNodeProvider PreferredNodeProvider,
 	nodeUnfitness NodeUnfitness,
+) expander.Filter {
+	return NewFilterWithPricing(cloudProvider, cloudProvider.GPULabel(), preferredNodeProvider, nodeUnfitness)
+}
+
+// NewFilterWithPricing returns an expansion filter that picks nodes based on the prices of the pricing model
+// provider and preferred node type. gpuLabel is the label of the nodes with GPUs.
+func NewFilterWithPricing(pricingModelProvider PricingModelProvider,
+	gpuLabel string,
+	preferredNodeProvider PreferredNodeProvider,
+	nodeUnfitness NodeUnfitness,
 ) expander.Filter {
 	return &priceBased{
-		cloudProvider:         cloudProvider,
+		pricingModelProvider:  pricingModelProvider,
+		gpuLabel:              gpuLabel,
 		preferredNodeProvider: preferredNodeProvider,
 		nodeUnfitness:         nodeUnfitness,
 	}
@@ -90,6 +102,53 @@ func NewFilter(cloudProvider cloudprovider.CloudProvider,
 func (p *priceBased) BestOptions(expansionOptions []expander.Option, nodeInfos map[string]*schedulerframework.NodeInfo) []expander.Option {
 	var bestOptions []expander.Option
 	bestOptionScore := 0.0
+
+	optionScores, debugs, ok := p.optionScores(expansionOptions, nodeInfos)
+	if !ok {
+		return expansionOptions
+	}
+	for i, option := range expansionOptions {
+		optionScore := optionScores[i]
+		if math.IsNaN(optionScore) {
+			continue
+		}
+		maybeBestOption := expander.Option{
+			NodeGroup: option.NodeGroup,
+			NodeCount: option.NodeCount,
+			Debug:     fmt.Sprintf("%s | price-expander: %s", option.Debug, debugs[i]),
+			Pods:      option.Pods,
+		}
+		if len(bestOptions) == 0 || bestOptionScore == optionScore {
+			bestOptions = append(bestOptions, maybeBestOption)
+			bestOptionScore = optionScore
+		} else if bestOptionScore > optionScore {
+			bestOptions = []expander.Option{maybeBestOption}
+			bestOptionScore = optionScore
+		}
+	}
+	return bestOptions
+}
+
+// ScoreOptions scores the expansion options by their negated cost and preferred node type score.
+func (p *priceBased) ScoreOptions(expansionOptions []expander.Option, nodeInfos map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	optionScores, _, ok := p.optionScores(expansionOptions, nodeInfos)
+	for i := range expansionOptions {
+		if !ok {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = -optionScores[i]
+	}
+	return scores
+}
+
+// optionScores returns the scores of the expansion options based on cost and preferred node type, lower is better,
+// and their debug strings. Options that cannot be priced are scored NaN. It returns false if there is no pricing
+// model.
+func (p *priceBased) optionScores(expansionOptions []expander.Option, nodeInfos map[string]*schedulerframework.NodeInfo) ([]float64, []string, bool) {
+	optionScores := make([]float64, len(expansionOptions))
+	debugs := make([]string, len(expansionOptions))
 	now := time.Now()
 	then := now.Add(time.Hour)
 
@@ -99,9 +158,10 @@ func (p *priceBased) BestOptions(expansionOptions []expander.Option, nodeInfos m
 		preferredNode = defaultPreferredNode
 	}
 
-	pricingModel, err := p.cloudProvider.Pricing()
+	pricingModel, err := p.pricingModelProvider.Pricing()
 	if err != nil {
-		klog.Errorf("Failed to get pricing model from cloud provider: %v", err)
+		klog.Errorf("Failed to get pricing model, skipping price expander: %v", err)
+		return nil, nil, false
 	}
 
 	stabilizationPrice, err := pricingModel.PodPrice(priceStabilizationPod, now, then)
@@ -111,7 +171,8 @@ func (p *priceBased) BestOptions(expansionOptions []expander.Option, nodeInfos m
 	}
 
 nextoption:
-	for _, option := range expansionOptions {
+	for i, option := range expansionOptions {
+		optionScores[i] = math.NaN()
 		nodeInfo, found := nodeInfos[option.NodeGroup.Id()]
 		if !found {
 			klog.Warningf("No node info for %s", option.NodeGroup.Id())
@@ -147,7 +208,7 @@ nextoption:
 
 		// Set constant, very high unfitness to make them unattractive for pods that doesn't need GPU and
 		// avoid optimizing them for CPU utilization.
-		if gpu.NodeHasGpu(p.cloudProvider.GPULabel(), nodeInfo.Node()) {
+		if gpu.NodeHasGpu(p.gpuLabel, nodeInfo.Node()) {
 			klog.V(4).Infof("Price expander overriding unfitness for node group with GPU %s", option.NodeGroup.Id())
 			supressedUnfitness = gpuUnfitnessOverride
 		}
@@ -169,21 +230,10 @@ nextoption:
 
 		klog.V(5).Infof("Price expander for %s: %s", option.NodeGroup.Id(), debug)
 
-		maybeBestOption := expander.Option{
-			NodeGroup: option.NodeGroup,
-			NodeCount: option.NodeCount,
-			Debug:     fmt.Sprintf("%s | price-expander: %s", option.Debug, debug),
-			Pods:      option.Pods,
-		}
-		if len(bestOptions) == 0 || bestOptionScore == optionScore {
-			bestOptions = append(bestOptions, maybeBestOption)
-			bestOptionScore = optionScore
-		} else if bestOptionScore > optionScore {
-			bestOptions = []expander.Option{maybeBestOption}
-			bestOptionScore = optionScore
-		}
+		optionScores[i] = optionScore
+		debugs[i] = debug
 	}
-	return bestOptions
+	return optionScores, debugs, true
 }
 
 // buildPod creates a pod with specified resources.
@@ -336,3 +336,21 @@ func TestPriceExpander(t *testing.T) {
 		SimpleNodeUnfitness,
 	).BestOptions(options3, nodeInfosForGroups)), []string{"ng3"})
 }
+
+func TestPriceExpanderWithoutPricing(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	provider.AddNode("ng1", n1)
+	ng1, _ := provider.NodeGroupForNode(n1)
+	ni1 := schedulerframework.NewNodeInfo()
+	ni1.SetNode(n1)
+	options := []expander.Option{{NodeGroup: ng1, NodeCount: 1, Debug: "ng1"}}
+
+	// The options are not filtered if the cloud provider does not implement pricing.
+	assert.Equal(t, options, NewFilter(
+		provider,
+		&testPreferredNodeProvider{preferred: BuildTestNode("nn", 1000, 1000)},
+		SimpleNodeUnfitness,
+	).BestOptions(options, map[string]*schedulerframework.NodeInfo{"ng1": ni1}))
+}
@@ -18,8 +18,18 @@ package price
 
 import (
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 )
 
+// PricingModelProvider provides the pricing model node groups and pods are priced with. Every cloud provider
+// implements it, cloud providers without pricing by returning an error, and so do price sources that are not tied
+// to a cloud provider, like a price file.
+type PricingModelProvider interface {
+	// Pricing returns the pricing model.
+	Pricing() (cloudprovider.PricingModel, errors.AutoscalerError)
+}
+
 // PreferredNodeProvider provides a node that would be, in-longer run, the most suited to the cluster
 // needs.
 type PreferredNodeProvider interface {
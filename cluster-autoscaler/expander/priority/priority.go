@@ -26,6 +26,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	v1lister "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 	klog "k8s.io/klog/v2"
@@ -39,7 +40,45 @@ const (
 	ConfigMapKey = "priorities"
 )
 
-type priorities map[int][]*regexp.Regexp
+// nodeGroupMatcher matches node groups either by their id or by the labels of their template node.
+type nodeGroupMatcher struct {
+	nameRegexp    *regexp.Regexp
+	labelSelector labels.Selector
+}
+
+func (m nodeGroupMatcher) matches(id string, nodeInfo *schedulerframework.NodeInfo) bool {
+	if m.nameRegexp != nil {
+		return m.nameRegexp.FindStringIndex(id) != nil
+	}
+	return nodeInfo != nil && nodeInfo.Node() != nil && m.labelSelector.Matches(labels.Set(nodeInfo.Node().Labels))
+}
+
+// priorityRule is an entry of the priority list, either a node group name regexp or a label selector
+// of the template node, e.g. `labelSelector: gpu=true,zone=phx-ad-1`.
+type priorityRule struct {
+	nameRegexp    string
+	labelSelector string
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *priorityRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&r.nameRegexp); err == nil {
+		return nil
+	}
+	var selector struct {
+		LabelSelector string `yaml:"labelSelector"`
+	}
+	if err := unmarshal(&selector); err != nil {
+		return err
+	}
+	if selector.LabelSelector == "" {
+		return errors.New("rule must be either a regexp or a non-empty labelSelector")
+	}
+	r.labelSelector = selector.LabelSelector
+	return nil
+}
+
+type priorities map[int][]nodeGroupMatcher
 
 type priority struct {
 	logRecorder      record.EventRecorder
@@ -93,19 +132,27 @@ func (p *priority) parsePrioritiesYAMLString(prioritiesYAML string) (priorities,
 		return nil, fmt.Errorf("priority configuration in %s configmap is empty; please provide valid configuration",
 			PriorityConfigMapName)
 	}
-	var config map[int][]string
+	var config map[int][]priorityRule
 	if err := yaml.Unmarshal([]byte(prioritiesYAML), &config); err != nil {
 		return nil, fmt.Errorf("Can't parse YAML with priorities in the configmap: %v", err)
 	}
 
-	newPriorities := make(map[int][]*regexp.Regexp)
-	for prio, reList := range config {
-		for _, re := range reList {
-			regexp, err := regexp.Compile(re)
+	newPriorities := make(priorities)
+	for prio, ruleList := range config {
+		for _, rule := range ruleList {
+			if rule.labelSelector != "" {
+				selector, err := labels.Parse(rule.labelSelector)
+				if err != nil {
+					return nil, fmt.Errorf("Can't parse label selector rule for priority %d and rule %s: %v", prio, rule.labelSelector, err)
+				}
+				newPriorities[prio] = append(newPriorities[prio], nodeGroupMatcher{labelSelector: selector})
+				continue
+			}
+			regexp, err := regexp.Compile(rule.nameRegexp)
 			if err != nil {
-				return nil, fmt.Errorf("Can't compile regexp rule for priority %d and rule %s: %v", prio, re, err)
+				return nil, fmt.Errorf("Can't compile regexp rule for priority %d and rule %s: %v", prio, rule.nameRegexp, err)
 			}
-			newPriorities[prio] = append(newPriorities[prio], regexp)
+			newPriorities[prio] = append(newPriorities[prio], nodeGroupMatcher{nameRegexp: regexp})
 		}
 	}
 
@@ -131,8 +178,8 @@ func (p *priority) BestOptions(expansionOptions []expander.Option, nodeInfo map[
 	for _, option := range expansionOptions {
 		id := option.NodeGroup.Id()
 		found := false
-		for prio, nameRegexpList := range priorities {
-			if !p.groupIDMatchesList(id, nameRegexpList) {
+		for prio, matcherList := range priorities {
+			if !p.groupMatchesList(id, nodeInfo[id], matcherList) {
 				continue
 			}
 			found = true
@@ -165,9 +212,9 @@ func (p *priority) BestOptions(expansionOptions []expander.Option, nodeInfo map[
 	return best
 }
 
-func (p *priority) groupIDMatchesList(id string, nameRegexpList []*regexp.Regexp) bool {
-	for _, re := range nameRegexpList {
-		if re.FindStringIndex(id) != nil {
+func (p *priority) groupMatchesList(id string, nodeInfo *schedulerframework.NodeInfo, matcherList []nodeGroupMatcher) bool {
+	for _, matcher := range matcherList {
+		if matcher.matches(id, nodeInfo) {
 			return true
 		}
 	}
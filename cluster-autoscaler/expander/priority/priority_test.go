@@ -29,6 +29,8 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
 const (
@@ -69,6 +71,19 @@ var (
   - ".t2\\.large.*"
 `
 
+	labelSelectorConfig = `
+5:
+  - ".*"
+10:
+  - labelSelector: gpu=true
+50:
+  - labelSelector: gpu=true,zone=phx-ad-1
+`
+	invalidLabelSelectorConfig = `
+10:
+  - labelSelector: gpu==true==false
+`
+
 	eoT2Micro = expander.Option{
 		Debug:     "t2.micro",
 		NodeGroup: test.NewTestNodeGroup("my-asg.t2.micro", 10, 1, 1, true, false, "t2.micro", nil, nil),
@@ -167,3 +182,38 @@ func TestPriorityExpanderCorrecltySkipsBadChangeConfig(t *testing.T) {
 	assert.EqualValues(t, configWarnConfigMapEmpty, event)
 	assert.Equal(t, ret, []expander.Option{eoT2Large, eoT3Large, eoM44XLarge})
 }
+
+func TestPriorityExpanderMatchesTemplateLabels(t *testing.T) {
+	nodeInfos := map[string]*schedulerframework.NodeInfo{}
+	for id, nodeLabels := range map[string]map[string]string{
+		eoT2Micro.NodeGroup.Id():   {},
+		eoT2Large.NodeGroup.Id():   {"gpu": "true", "zone": "phx-ad-2"},
+		eoM44XLarge.NodeGroup.Id(): {"gpu": "true", "zone": "phx-ad-1"},
+	} {
+		node := BuildTestNode(id, 1000, 1000)
+		node.Labels = nodeLabels
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos[id] = nodeInfo
+	}
+
+	s, _, _ := getFilterInstance(t, labelSelectorConfig)
+	ret := s.BestOptions([]expander.Option{eoT2Micro, eoT2Large, eoM44XLarge}, nodeInfos)
+	assert.Equal(t, []expander.Option{eoM44XLarge}, ret)
+	ret = s.BestOptions([]expander.Option{eoT2Micro, eoT2Large}, nodeInfos)
+	assert.Equal(t, []expander.Option{eoT2Large}, ret)
+	// Groups without a template node are only matched by name.
+	ret = s.BestOptions([]expander.Option{eoT3Large, eoM44XLarge}, nil)
+	assert.Equal(t, []expander.Option{eoT3Large, eoM44XLarge}, ret)
+}
+
+func TestPriorityExpanderSkipsInvalidLabelSelector(t *testing.T) {
+	s, r, _ := getFilterInstance(t, invalidLabelSelectorConfig)
+	ret := s.BestOptions([]expander.Option{eoT2Large, eoT3Large}, nil)
+
+	priority := s.(*priority)
+	assert.Equal(t, 1, priority.badConfigUpdates)
+	event := <-r.Events
+	assert.Contains(t, event, "Can't parse label selector rule for priority 10")
+	assert.Equal(t, []expander.Option{eoT2Large, eoT3Large}, ret)
+}
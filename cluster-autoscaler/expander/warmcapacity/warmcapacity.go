@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmcapacity
+
+import (
+	"math"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type warmcapacity struct {
+}
+
+// NewFilter returns a scale up filter that picks the node groups that can add the most of the nodes of the scale up
+// from pre-warmed capacity
+func NewFilter() expander.Filter {
+	return &warmcapacity{}
+}
+
+// BestOptions selects the expansion options that add the most nodes from warm capacity. Node groups that do not
+// report warm capacity have none. All options are returned if none of them has warm capacity.
+func (w *warmcapacity) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	var maxWarmNodes int
+	var maxOptions []expander.Option
+
+	for _, option := range expansionOptions {
+		warmNodes := warmNodes(option)
+		if warmNodes == maxWarmNodes {
+			maxOptions = append(maxOptions, option)
+			continue
+		}
+
+		if warmNodes > maxWarmNodes {
+			maxWarmNodes = warmNodes
+			maxOptions = []expander.Option{option}
+		}
+	}
+
+	if len(maxOptions) == 0 {
+		return expansionOptions
+	}
+
+	return maxOptions
+}
+
+// ScoreOptions scores the expansion options by the fraction of their nodes added from warm capacity
+func (w *warmcapacity) ScoreOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	for i, option := range expansionOptions {
+		if option.NodeCount == 0 {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = float64(warmNodes(option)) / float64(option.NodeCount)
+	}
+	return scores
+}
+
+// warmNodes returns how many of the nodes of the option are added from the warm capacity of its node group
+func warmNodes(option expander.Option) int {
+	warmCapacity, err := option.NodeGroup.WarmCapacity()
+	if err != nil {
+		if err != cloudprovider.ErrNotImplemented {
+			klog.Warningf("Failed to get warm capacity of node group %s: %v", option.NodeGroup.Id(), err)
+		}
+		return 0
+	}
+	warmNodes := min(warmCapacity, option.NodeCount)
+	klog.V(1).Infof("Expanding Node Group %s would add %d of %d nodes from warm capacity", option.NodeGroup.Id(), warmNodes, option.NodeCount)
+	return warmNodes
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmcapacity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+)
+
+func TestWarmCapacity(t *testing.T) {
+	nodeGroup := func(id string, warmCapacity int) *testprovider.TestNodeGroup {
+		ng := testprovider.NewTestNodeGroup(id, 10, 0, 1, true, false, "", nil, nil)
+		if warmCapacity >= 0 {
+			ng.SetWarmCapacity(warmCapacity)
+		}
+		return ng
+	}
+	cold := expander.Option{Debug: "cold", NodeCount: 2, NodeGroup: nodeGroup("cold", 0)}
+	unreported := expander.Option{Debug: "unreported", NodeCount: 2, NodeGroup: nodeGroup("unreported", -1)}
+	partlyWarm := expander.Option{Debug: "partly-warm", NodeCount: 3, NodeGroup: nodeGroup("partly-warm", 1)}
+	warm := expander.Option{Debug: "warm", NodeCount: 2, NodeGroup: nodeGroup("warm", 5)}
+	alsoWarm := expander.Option{Debug: "also-warm", NodeCount: 4, NodeGroup: nodeGroup("also-warm", 2)}
+
+	for _, tc := range []struct {
+		name                     string
+		expansionOptions         []expander.Option
+		expectedExpansionOptions []expander.Option
+	}{
+		{
+			name:                     "no options",
+			expansionOptions:         nil,
+			expectedExpansionOptions: nil,
+		},
+		{
+			name:                     "no warm capacity",
+			expansionOptions:         []expander.Option{cold, unreported},
+			expectedExpansionOptions: []expander.Option{cold, unreported},
+		},
+		{
+			name:                     "most warm nodes",
+			expansionOptions:         []expander.Option{cold, partlyWarm, warm, unreported},
+			expectedExpansionOptions: []expander.Option{warm},
+		},
+		{
+			name:                     "equal warm nodes",
+			expansionOptions:         []expander.Option{partlyWarm, warm, alsoWarm},
+			expectedExpansionOptions: []expander.Option{warm, alsoWarm},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedExpansionOptions, NewFilter().BestOptions(tc.expansionOptions, nil))
+		})
+	}
+
+	scores := NewFilter().(expander.Scorer).ScoreOptions([]expander.Option{unreported, partlyWarm, warm, {NodeGroup: nodeGroup("empty", 1)}}, nil)
+	assert.Equal(t, []float64{0, 1.0 / 3, 1}, scores[:3])
+	assert.True(t, math.IsNaN(scores[3]))
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package waste
 
 import (
+	"math"
+
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
@@ -38,22 +40,11 @@ func (l *leastwaste) BestOptions(expansionOptions []expander.Option, nodeInfo ma
 	var leastWastedOptions []expander.Option
 
 	for _, option := range expansionOptions {
-		requestedCPU, requestedMemory := resourcesForPods(option.Pods)
-		node, found := nodeInfo[option.NodeGroup.Id()]
+		wastedScore, found := l.wastedScore(option, nodeInfo)
 		if !found {
-			klog.Errorf("No node info for: %s", option.NodeGroup.Id())
 			continue
 		}
 
-		nodeCPU, nodeMemory := resourcesForNode(node.Node())
-		availCPU := nodeCPU.MilliValue() * int64(option.NodeCount)
-		availMemory := nodeMemory.Value() * int64(option.NodeCount)
-		wastedCPU := float64(availCPU-requestedCPU.MilliValue()) / float64(availCPU)
-		wastedMemory := float64(availMemory-requestedMemory.Value()) / float64(availMemory)
-		wastedScore := wastedCPU + wastedMemory
-
-		klog.V(1).Infof("Expanding Node Group %s would waste %0.2f%% CPU, %0.2f%% Memory, %0.2f%% Blended\n", option.NodeGroup.Id(), wastedCPU*100.0, wastedMemory*100.0, wastedScore*50.0)
-
 		if wastedScore == leastWastedScore {
 			leastWastedOptions = append(leastWastedOptions, option)
 		}
@@ -71,6 +62,40 @@ func (l *leastwaste) BestOptions(expansionOptions []expander.Option, nodeInfo ma
 	return leastWastedOptions
 }
 
+// ScoreOptions scores the expansion options by the negated fraction of CPU and Memory they waste
+func (l *leastwaste) ScoreOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []float64 {
+	scores := make([]float64, len(expansionOptions))
+	for i, option := range expansionOptions {
+		wastedScore, found := l.wastedScore(option, nodeInfo)
+		if !found {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = -wastedScore
+	}
+	return scores
+}
+
+// wastedScore returns the sum of the fractions of CPU and Memory the option wastes
+func (l *leastwaste) wastedScore(option expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) (float64, bool) {
+	requestedCPU, requestedMemory := resourcesForPods(option.Pods)
+	node, found := nodeInfo[option.NodeGroup.Id()]
+	if !found {
+		klog.Errorf("No node info for: %s", option.NodeGroup.Id())
+		return 0, false
+	}
+
+	nodeCPU, nodeMemory := resourcesForNode(node.Node())
+	availCPU := nodeCPU.MilliValue() * int64(option.NodeCount)
+	availMemory := nodeMemory.Value() * int64(option.NodeCount)
+	wastedCPU := float64(availCPU-requestedCPU.MilliValue()) / float64(availCPU)
+	wastedMemory := float64(availMemory-requestedMemory.Value()) / float64(availMemory)
+	wastedScore := wastedCPU + wastedMemory
+
+	klog.V(1).Infof("Expanding Node Group %s would waste %0.2f%% CPU, %0.2f%% Memory, %0.2f%% Blended\n", option.NodeGroup.Id(), wastedCPU*100.0, wastedMemory*100.0, wastedScore*50.0)
+	return wastedScore, true
+}
+
 func resourcesForPods(pods []*apiv1.Pod) (cpu resource.Quantity, memory resource.Quantity) {
 	for _, pod := range pods {
 		for _, container := range pod.Spec.Containers {
@@ -40,6 +40,7 @@ func (f *FakeNodeGroup) MinSize() int                       { return 1 }
 func (f *FakeNodeGroup) TargetSize() (int, error)           { return 2, nil }
 func (f *FakeNodeGroup) IncreaseSize(delta int) error       { return nil }
 func (f *FakeNodeGroup) AtomicIncreaseSize(delta int) error { return cloudprovider.ErrNotImplemented }
+func (f *FakeNodeGroup) WarmCapacity() (int, error)         { return 0, cloudprovider.ErrNotImplemented }
 func (f *FakeNodeGroup) DecreaseTargetSize(delta int) error { return nil }
 func (f *FakeNodeGroup) DeleteNodes([]*apiv1.Node) error    { return nil }
 func (f *FakeNodeGroup) Id() string                         { return f.id }
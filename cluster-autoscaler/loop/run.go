@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/tracing"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 )
 
@@ -33,12 +34,19 @@ func RunAutoscalerOnce(autoscaler autoscaler, healthCheck *metrics.HealthCheck,
 	metrics.UpdateLastTime(metrics.Main, loopStart)
 	healthCheck.UpdateLastActivity(loopStart)
 
+	span := tracing.StartLoop(loopStart)
 	err := autoscaler.RunOnce(loopStart)
+	span.EndLoop(err)
 	if err != nil && err.Type() != errors.TransientError {
 		metrics.RegisterError(err)
 	} else {
 		healthCheck.UpdateLastSuccessfulRun(time.Now())
 	}
+	if err == nil {
+		healthCheck.UpdateCloudProviderSuccess(time.Now())
+	} else if err.Type() == errors.CloudProviderError {
+		healthCheck.UpdateCloudProviderFailure(time.Now(), err)
+	}
 
 	metrics.UpdateDurationFromStart(metrics.Main, loopStart)
 }
@@ -40,14 +40,19 @@ import (
 
 	"github.com/spf13/pflag"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apiserver/pkg/server/mux"
 	"k8s.io/apiserver/pkg/server/routes"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	cloudBuilder "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/builder"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/gce/localssdsize"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/scheduledscaling"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/core"
 	"k8s.io/autoscaler/cluster-autoscaler/core/podlistprocessor"
@@ -56,20 +61,27 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	"k8s.io/autoscaler/cluster-autoscaler/observers/loopstart"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/actuationhooks"
+	capacitybufferprocessor "k8s.io/autoscaler/cluster-autoscaler/processors/capacitybuffer"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfosprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/predictive"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/provreq"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/emptycandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/previouscandidates"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 	provreqorchestrator "k8s.io/autoscaler/cluster-autoscaler/provisioningrequest/orchestrator"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
+	"k8s.io/autoscaler/cluster-autoscaler/tracing"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
 	"k8s.io/autoscaler/cluster-autoscaler/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection"
@@ -118,10 +130,13 @@ var (
 	enforceNodeGroupMinSize = flag.Bool("enforce-node-group-min-size", false, "Should CA scale up the node group to the configured min size if needed.")
 	scaleDownEnabled        = flag.Bool("scale-down-enabled", true, "Should CA scale down the cluster")
 	scaleDownUnreadyEnabled = flag.Bool("scale-down-unready-enabled", true, "Should CA scale down unready nodes of the cluster")
+	scaleDownDryRun         = flag.Bool("scale-down-dry-run", false, "Should CA only report the nodes it would scale down, with their estimated monthly cost, instead of removing them")
 	scaleDownDelayAfterAdd  = flag.Duration("scale-down-delay-after-add", 10*time.Minute,
-		"How long after scale up that scale down evaluation resumes")
+		"How long after scale up that scale down evaluation resumes. With --scale-down-delay-type-local, node groups can override it in their autoscaling options")
 	scaleDownDelayTypeLocal = flag.Bool("scale-down-delay-type-local", false,
 		"Should --scale-down-delay-after-* flags be applied locally per nodegroup or globally across all nodegroups")
+	scaleDownOrder = flag.String("scale-down-order", "",
+		"Order scale down candidates are considered in: cost (most expensive first, using the pricing model of the cloud provider), utilization (least utilized first) or age (oldest first). If empty, the order of the candidates is kept.")
 	scaleDownDelayAfterDelete = flag.Duration("scale-down-delay-after-delete", 0,
 		"How long after node deletion that scale down evaluation resumes, defaults to scanInterval")
 	scaleDownDelayAfterFailure = flag.Duration("scale-down-delay-after-failure", config.DefaultScaleDownDelayAfterFailure,
@@ -159,6 +174,9 @@ var (
 	coresTotal                  = flag.String("cores-total", minMaxFlagString(0, config.DefaultMaxClusterCores), "Minimum and maximum number of cores in cluster, in the format <min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers.")
 	memoryTotal                 = flag.String("memory-total", minMaxFlagString(0, config.DefaultMaxClusterMemory), "Minimum and maximum number of gigabytes of memory in cluster, in the format <min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers.")
 	gpuTotal                    = multiStringFlag("gpu-total", "Minimum and maximum number of different GPUs in cluster, in the format <gpu_type>:<min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers. Can be passed multiple times. CURRENTLY THIS FLAG ONLY WORKS ON GKE.")
+	scopedResourceLimits        = multiStringFlag("scoped-resource-limit", "Maximum number of cores or gigabytes of memory of the nodes matching a label selector, in the format <cores|memory>:<max>:<label selector>, e.g. cores:256:nvidia.com/gpu.present=true. Cluster autoscaler will not scale the matching nodes beyond this number, during both scale-up and auto-provisioning. Can be passed multiple times.")
+	nodeGroupGangs              = multiStringFlag("node-group-gang", "Node groups scaled up together for pods that need nodes of several of them, as a comma-separated list of node group ids, e.g. cpu-pool,gpu-pool. Either all node groups of the gang that the pods need are scaled up or none is. Can be passed multiple times.")
+	nodeGroupHeadroom           = multiStringFlag("node-group-headroom", "Spare capacity kept in a node group, in the format <units>:<unit resources>:<node group>, e.g. 3:cpu=2,memory=4Gi:ng1. It is reserved with virtual placeholder pods requesting the unit resources, and the node group is scaled up when they don't fit on its nodes. Can be passed multiple times.")
 	cloudProviderFlag           = flag.String("cloud-provider", cloudBuilder.DefaultCloudProvider,
 		"Cloud provider type. Available values: ["+strings.Join(cloudBuilder.AvailableCloudProviders, ",")+"]")
 	maxBulkSoftTaintCount      = flag.Int("max-bulk-soft-taint-count", 10, "Maximum number of nodes that can be tainted/untainted PreferNoSchedule at the same time. Set to 0 to turn off such tainting.")
@@ -172,6 +190,9 @@ var (
 	parallelScaleUp           = flag.Bool("parallel-scale-up", false, "Whether to allow parallel node groups scale up. Experimental: may not work on some cloud providers, enable at your own risk.")
 	maxNodeProvisionTime      = flag.Duration("max-node-provision-time", 15*time.Minute, "The default maximum time CA waits for node to be provisioned - the value can be overridden per node group")
 	maxPodEvictionTime        = flag.Duration("max-pod-eviction-time", 2*time.Minute, "Maximum time CA tries to evict a pod before giving up")
+	maxPodDrainTimeout        = flag.Duration("max-pod-drain-timeout", time.Hour, "Maximum graceful termination time pods may ask for with the cluster-autoscaler.kubernetes.io/drain-timeout annotation. 0 ignores the annotation")
+	maxConcurrentEvictions    = flag.Int("max-concurrent-evictions-per-node", 0, "Maximum number of pods evicted concurrently from a single drained node. 0 means no limit")
+	podEvictionInterval       = flag.Duration("pod-eviction-interval", 0, "Minimum time between the starts of consecutive pod evictions from a single drained node")
 	nodeGroupsFlag            = multiStringFlag(
 		"nodes",
 		"sets min,max size and other configuration data for a node group in a format accepted by cloud provider. Can be used multiple times. Format: <min>:<max>:<other...>")
@@ -186,11 +207,20 @@ var (
 	estimatorFlag = flag.String("estimator", estimator.BinpackingEstimatorName,
 		"Type of resource estimator to be used in scale up. Available values: ["+strings.Join(estimator.AvailableEstimators, ",")+"]")
 
-	expanderFlag = flag.String("expander", expander.RandomExpanderName, "Type of node group expander to be used in scale up. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]. Specifying multiple values separated by commas will call the expanders in succession until there is only one option remaining. Ties still existing after this process are broken randomly.")
+	expanderFlag = flag.String("expander", expander.RandomExpanderName, "Type of node group expander to be used in scale up. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]. Specifying multiple values separated by commas will call the expanders in succession until there is only one option remaining. Ties still existing after this process are broken randomly. Expanders can also be blended with weights, e.g. 0.7*price+0.3*least-waste, which selects the options with the highest weighted sum of the normalized scores of the expanders.")
 
 	grpcExpanderCert = flag.String("grpc-expander-cert", "", "Path to cert used by gRPC server over TLS")
 	grpcExpanderURL  = flag.String("grpc-expander-url", "", "URL to reach gRPC expander server.")
 
+	grpcExpanderCacheTTL            = flag.Duration("grpc-expander-cache-ttl", 0, "How long the best options returned by the gRPC expander server are reused for the same expansion options without calling it again. 0 disables caching.")
+	grpcExpanderHealthCheckInterval = flag.Duration("grpc-expander-health-check-interval", 0, "How often the gRPC expander server is health checked. The server is not called while it is unhealthy. 0 disables health checking.")
+	grpcExpanderFallback            = flag.String("grpc-expander-fallback", "", "Expander used when the gRPC expander server is unhealthy or fails, e.g. least-waste. All options are passed on to the next expander if it is not set.")
+
+	priceExpanderFile = flag.String("price-expander-file", "", "Path to a JSON file, e.g. mounted from a ConfigMap, with the prices of instance types and pod resources used by the price expander instead of the ones of the cloud provider. The file is reloaded when it is modified.")
+
+	carbonIntensityRegions = flag.String("carbon-intensity-regions", "", "Carbon intensities in gCO2eq/kWh by region or zone used by the leastco2 expander, e.g. us-phoenix-1=390,eu-frankfurt-1=350. Zones take precedence over their region.")
+	carbonIntensityURL     = flag.String("carbon-intensity-url", "", "URL of the HTTP provider of carbon intensities used by the leastco2 expander. It is called with the region and zone query parameters and must respond with {\"carbonIntensity\": <gCO2eq/kWh>}. Falls back to --carbon-intensity-regions.")
+
 	ignoreDaemonSetsUtilization = flag.Bool("ignore-daemonsets-utilization", false,
 		"Should CA ignore DaemonSet pods when calculating resource utilization for scaling down")
 	ignoreMirrorPodsUtilization = flag.Bool("ignore-mirror-pods-utilization", false,
@@ -198,23 +228,40 @@ var (
 
 	writeStatusConfigMapFlag         = flag.Bool("write-status-configmap", true, "Should CA write status information to a configmap")
 	statusConfigMapName              = flag.String("status-config-map-name", "cluster-autoscaler-status", "Status configmap name")
+	writeStatusResourceFlag          = flag.Bool("write-status-resource", false, "Should CA write status information to a ClusterAutoscalerStatus custom resource. The ClusterAutoscalerStatus CRD must be installed.")
+	statusResourceName               = flag.String("status-resource-name", "cluster-autoscaler-status", "Status ClusterAutoscalerStatus custom resource name")
+	runtimeConfigMapName             = flag.String("runtime-config-map-name", "", "Name of the configmap in the CA namespace that overrides tunables like scale-down thresholds and delays, expander and max-nodes-total without restarting CA. Disabled if empty.")
+	stateCheckpointConfigMapName     = flag.String("state-checkpoint-config-map-name", "", "Name of the configmap in the CA namespace the unneeded times of nodes, the backoff of node groups, the scale-ups in progress and the times nodes have been unregistered for are saved to after every loop, so that a new leader or a restarted CA carries them over instead of starting from scratch. Disabled if empty.")
+	stateCheckpointMaxAge            = flag.Duration("state-checkpoint-max-age", 5*time.Minute, "How old a state checkpoint can be to be restored. Nothing is restored from older checkpoints, since the scale-ups they hold may have finished and the nodes may have registered since.")
+	templateInjectionConfigMapName   = flag.String("node-template-injection-config-map-name", "", "Name of the configmap in the CA namespace with extended resources and labels injected into the templates of node groups, e.g. hugepages or devices of device plugins unknown to the cloud provider. Disabled if empty.")
+	scheduledScalingConfigMapName    = flag.String("scheduled-scaling-config-map-name", "", "Name of the configmap in the CA namespace with schedules overriding the min and max sizes of node groups in recurring time windows, e.g. to add nodes before predictable load. Disabled if empty.")
+	actuationWebhookURL              = flag.String("actuation-webhook-url", "", "URL of the webhook called before executing scale-ups and scale-downs, which can veto or delay them, and after they complete. Disabled if empty.")
+	actuationWebhookTimeout          = flag.Duration("actuation-webhook-timeout", 10*time.Second, "Timeout of the actuation webhook calls.")
+	actuationWebhookFailurePolicy    = flag.String("actuation-webhook-failure-policy", string(actuationhooks.FailurePolicyIgnore), "Whether scale-ups and scale-downs are allowed (Ignore) or vetoed (Fail) when the actuation webhook can't be called.")
+	enableDynamicResourceAllocation  = flag.Bool("enable-dynamic-resource-allocation", false, "Whether the scheduling simulation should account for devices requested by resource claims of pods and published in resource slices of nodes. Requires the resource.k8s.io/v1alpha2 API.")
 	maxInactivityTimeFlag            = flag.Duration("max-inactivity", 10*time.Minute, "Maximum time from last recorded autoscaler activity before automatic restart")
 	maxBinpackingTimeFlag            = flag.Duration("max-binpacking-time", 5*time.Minute, "Maximum time spend on binpacking for a single scale-up. If binpacking is limited by this, scale-up will continue with the already calculated scale-up options.")
 	maxFailingTimeFlag               = flag.Duration("max-failing-time", 15*time.Minute, "Maximum time from last recorded successful autoscaler run before automatic restart")
+	readinessMaxFailingTimeFlag      = flag.Duration("readiness-max-failing-time", 15*time.Minute, "Maximum time from last recorded successful autoscaler run before /readyz reports the autoscaler as not ready")
+	readinessMaxCloudFailingTimeFlag = flag.Duration("readiness-max-cloud-provider-failing-time", 15*time.Minute, "Maximum time the cloud provider may keep failing since it last succeeded before /readyz reports the autoscaler as not ready")
 	balanceSimilarNodeGroupsFlag     = flag.Bool("balance-similar-node-groups", false, "Detect similar node groups and balance the number of nodes between them")
 	nodeAutoprovisioningEnabled      = flag.Bool("node-autoprovisioning-enabled", false, "Should CA autoprovision node groups when needed.This flag is deprecated and will be removed in future releases.")
 	maxAutoprovisionedNodeGroupCount = flag.Int("max-autoprovisioned-node-group-count", 15, "The maximum number of autoprovisioned groups in the cluster.This flag is deprecated and will be removed in future releases.")
 
 	unremovableNodeRecheckTimeout = flag.Duration("unremovable-node-recheck-timeout", 5*time.Minute, "The timeout before we check again a node that couldn't be removed before")
 	expendablePodsPriorityCutoff  = flag.Int("expendable-pods-priority-cutoff", -10, "Pods with priority below cutoff will be expendable. They can be killed without any consideration during scale down and they don't cause scale up. Pods with null priority (PodPriority disabled) are non expendable.")
+	namespaceExpendableCutoffs    = multiStringFlag("namespace-expendable-pods-priority-cutoff", "Overrides expendable-pods-priority-cutoff for the pods of a namespace, in the format <namespace>:<cutoff>. Can be passed multiple times.")
 	regional                      = flag.Bool("regional", false, "Cluster is regional.")
 	newPodScaleUpDelay            = flag.Duration("new-pod-scale-up-delay", 0*time.Second, "Pods less than this old will not be considered for scale-up. Can be increased for individual pods through annotation 'cluster-autoscaler.kubernetes.io/pod-scale-up-delay'.")
 
 	ignoreTaintsFlag          = multiStringFlag("ignore-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Deprecated, use startup-taints instead)")
 	startupTaintsFlag         = multiStringFlag("startup-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Equivalent to ignore-taint)")
 	statusTaintsFlag          = multiStringFlag("status-taint", "Specifies a taint to ignore in node templates when considering to scale a node group but nodes will not be treated as unready")
+	ignoredTemplateTaintsFlag = multiStringFlag("ignored-template-taint-prefix", "Specifies a prefix of taint keys, e.g. of ephemeral vendor taints, to remove from node templates, in addition to the built-in ones. Nodes with these taints are not treated as unready. Can be passed multiple times.")
+	ignoredTemplateLabelsFlag = multiStringFlag("ignored-template-label-prefix", "Specifies a prefix of label keys to remove from node templates. Can be passed multiple times.")
 	balancingIgnoreLabelsFlag = multiStringFlag("balancing-ignore-label", "Specifies a label to ignore in addition to the basic and cloud-provider set of labels when comparing if two node groups are similar")
 	balancingLabelsFlag       = multiStringFlag("balancing-label", "Specifies a label to use for comparing if two node groups are similar, rather than the built in heuristics. Setting this flag disables all other comparison logic, and cannot be combined with --balancing-ignore-label.")
+	nodeInfoComparator        = flag.String("node-info-comparator", "", "Name of the registered comparator used to compare if two node groups are similar, e.g. generic. If empty, the comparator of the cloud provider is used if it registers one, the generic one otherwise. Ignored if --balancing-label is set.")
 	awsUseStaticInstanceList  = flag.Bool("aws-use-static-instance-list", false, "Should CA fetch instance types in runtime or use a static list. AWS only")
 
 	// GCE specific flags
@@ -227,9 +274,15 @@ var (
 	cordonNodeBeforeTerminate          = flag.Bool("cordon-node-before-terminating", false, "Should CA cordon nodes before terminating during downscale process")
 	daemonSetEvictionForEmptyNodes     = flag.Bool("daemonset-eviction-for-empty-nodes", false, "DaemonSet pods will be gracefully terminated from empty nodes")
 	daemonSetEvictionForOccupiedNodes  = flag.Bool("daemonset-eviction-for-occupied-nodes", true, "DaemonSet pods will be gracefully terminated from non-empty nodes")
+	daemonSetPdbTimeout                = flag.Duration("daemonset-pdb-timeout", 0, "If positive, DaemonSet pods covered by PodDisruptionBudgets are always evicted, respecting the PDBs for up to this long before proceeding with the scale-down. PDBs covering DaemonSet pods are ignored if 0.")
 	userAgent                          = flag.String("user-agent", "cluster-autoscaler", "User agent used for HTTP calls.")
 	emitPerNodeGroupMetrics            = flag.Bool("emit-per-nodegroup-metrics", false, "If true, emit per node group metrics.")
 	debuggingSnapshotEnabled           = flag.Bool("debugging-snapshot-enabled", false, "Whether the debugging snapshot of cluster autoscaler feature is enabled")
+	debuggingSnapshotTokenFile         = flag.String("debugging-snapshot-token-file", "", "Path to a file with the bearer token requests to the debugging snapshot endpoint have to be authenticated with. The endpoint is not authenticated if empty.")
+	debuggingSnapshotCaptureDest       = flag.String("debugging-snapshot-capture-destination", "", "Directory, e.g. a mounted PVC, or http(s) URL snapshots are PUT under, where a debugging snapshot is saved when a loop fails or a scale-up fails. Requires --debugging-snapshot-enabled. Snapshots aren't captured automatically if empty.")
+	debuggingSnapshotCaptureInterval   = flag.Duration("debugging-snapshot-capture-min-interval", 10*time.Minute, "Minimum time between two debugging snapshots captured automatically on failures.")
+	tracingEndpoint                    = flag.String("tracing-endpoint", "", "OTLP gRPC endpoint (host:port) spans of the autoscaling loop are exported to. Tracing is disabled if empty.")
+	tracingSamplingRatePerMillion      = flag.Int("tracing-sampling-rate-per-million", 0, "Number of autoscaling loop iterations out of a million that are traced. Only used if --tracing-endpoint is set.")
 	nodeInfoCacheExpireTime            = flag.Duration("node-info-cache-expire-time", 87600*time.Hour, "Node Info cache expire time for each item. Default value is 10 years.")
 
 	initialNodeGroupBackoffDuration = flag.Duration("initial-node-group-backoff-duration", 5*time.Minute,
@@ -238,17 +291,30 @@ var (
 		"maxNodeGroupBackoffDuration is the maximum backoff duration for a NodeGroup after new nodes failed to start.")
 	nodeGroupBackoffResetTimeout = flag.Duration("node-group-backoff-reset-timeout", 3*time.Hour,
 		"nodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.")
+	nodeGroupBackoffPolicies = multiStringFlag("node-group-backoff-policy",
+		"Initial and maximum backoff duration for a NodeGroup after new nodes failed to start with an error code or class, in the format <initial>:<max>:<error code or class>, e.g. 30m:3h:QUOTA_EXCEEDED or 1m:5m:Other. Error classes are OutOfResource and Other. Overrides --initial-node-group-backoff-duration and --max-node-group-backoff-duration. Can be passed multiple times.")
 	maxScaleDownParallelismFlag             = flag.Int("max-scale-down-parallelism", 10, "Maximum number of nodes (both empty and needing drain) that can be deleted in parallel.")
 	maxDrainParallelismFlag                 = flag.Int("max-drain-parallelism", 1, "Maximum number of nodes needing drain, that can be drained and deleted in parallel.")
+	maxConcurrentDeletionsPerZone           = flag.Int("max-concurrent-deletions-per-zone", 0, "Maximum number of nodes of a zone that can be drained and deleted concurrently. 0 means no limit.")
 	recordDuplicatedEvents                  = flag.Bool("record-duplicated-events", false, "enable duplication of similar events within a 5 minute window.")
+	aggregateScaleUpEvents                  = flag.Bool("aggregate-scale-up-events", false, "If true, NotTriggerScaleUp and TriggeredScaleUp events are emitted once per owning workload of the pods, e.g. Deployment or Job, instead of once per pod.")
 	maxNodesPerScaleUp                      = flag.Int("max-nodes-per-scaleup", 1000, "Max nodes added in a single scale-up. This is intended strictly for optimizing CA algorithm latency and not a tool to rate-limit scale-up throughput.")
+	maxNewNodesPerMinute                    = flag.Int("max-new-nodes-per-minute", 0, "Maximum number of nodes added to the cluster per minute, smoothing provisioning bursts. 0 means no limit.")
+	maxHourlyCost                           = flag.Float64("max-hourly-cost", 0, "Maximum hourly cost of the cluster, estimated with the pricing model of the cloud provider. Scale-ups that would exceed it are rejected. 0 means no limit.")
+	maxNodeGroupNewNodesPerMinute           = flag.Int("max-node-group-new-nodes-per-minute", 0, "Default maximum number of nodes added to a node group per minute - the value can be overridden per node group. 0 means no limit.")
 	maxNodeGroupBinpackingDuration          = flag.Duration("max-nodegroup-binpacking-duration", 10*time.Second, "Maximum time that will be spent in binpacking simulation for each NodeGroup.")
+	parallelEstimationWorkers               = flag.Int("parallel-estimation-workers", 1, "Number of workers estimating the expansion options of node groups in parallel during scale-up. With 1 worker, node groups are estimated one by one.")
 	skipNodesWithSystemPods                 = flag.Bool("skip-nodes-with-system-pods", true, "If true cluster autoscaler will never delete nodes with pods from kube-system (except for DaemonSet or mirror pods)")
 	skipNodesWithLocalStorage               = flag.Bool("skip-nodes-with-local-storage", true, "If true cluster autoscaler will never delete nodes with pods with local storage, e.g. EmptyDir or HostPath")
 	skipNodesWithCustomControllerPods       = flag.Bool("skip-nodes-with-custom-controller-pods", true, "If true cluster autoscaler will never delete nodes with pods owned by custom controllers")
 	minReplicaCount                         = flag.Int("min-replica-count", 0, "Minimum number or replicas that a replica set or replication controller should have to allow their pods deletion in scale down")
+	drainabilityWebhookURL                  = flag.String("drainability-webhook-url", "", "URL of a webhook consulted on whether pods of scale down candidates can be drained. Disabled if empty")
+	drainabilityWebhookTimeout              = flag.Duration("drainability-webhook-timeout", 2*time.Second, "Timeout of a single drainability webhook call")
+	drainabilityWebhookCacheTTL             = flag.Duration("drainability-webhook-cache-ttl", time.Minute, "How long drainability webhook responses are cached per pod")
+	drainabilityWebhookFailClosed           = flag.Bool("drainability-webhook-fail-closed", false, "If true, pods block scale down of their node when the drainability webhook fails, otherwise the webhook is ignored")
 	nodeDeleteDelayAfterTaint               = flag.Duration("node-delete-delay-after-taint", 5*time.Second, "How long to wait before deleting a node after tainting it")
 	scaleDownSimulationTimeout              = flag.Duration("scale-down-simulation-timeout", 30*time.Second, "How long should we run scale down simulation.")
+	scaleDownSimulationTargetDuration       = flag.Duration("scale-down-simulation-target-duration", 0, "If positive, the number of scale down candidates simulated in a loop adapts to the duration of recent simulations, so that the simulation takes about this long. The number never goes below scale-down-candidates-pool-min-count and replaces the pool size calculated from scale-down-candidates-pool-ratio. Disabled if 0.")
 	parallelDrain                           = flag.Bool("parallel-drain", true, "Whether to allow parallel drain of nodes. This flag is deprecated and will be removed in future releases.")
 	maxCapacityMemoryDifferenceRatio        = flag.Float64("memory-difference-ratio", config.DefaultMaxCapacityMemoryDifferenceRatio, "Maximum difference in memory capacity between two similar node groups to be considered for balancing. Value is a ratio of the smaller node group's memory capacity.")
 	maxFreeDifferenceRatio                  = flag.Float64("max-free-difference-ratio", config.DefaultMaxFreeDifferenceRatio, "Maximum difference in free resources between two similar node groups to be considered for balancing. Value is a ratio of the smaller node group's free resource.")
@@ -256,13 +322,21 @@ var (
 	forceDaemonSets                         = flag.Bool("force-ds", false, "Blocks scale-up of node groups too small for all suitable Daemon Sets pods.")
 	dynamicNodeDeleteDelayAfterTaintEnabled = flag.Bool("dynamic-node-delete-delay-after-taint-enabled", false, "Enables dynamic adjustment of NodeDeleteDelayAfterTaint based of the latency between CA and api-server")
 	bypassedSchedulers                      = pflag.StringSlice("bypassed-scheduler-names", []string{}, fmt.Sprintf("Names of schedulers to bypass. If set to non-empty value, CA will not wait for pods to reach a certain age before triggering a scale-up."))
+	additionalSchedulers                    = pflag.StringSlice("additional-scheduler-names", []string{}, "Names of schedulers, other than the default one, whose pending pods trigger scale-up. Each name can be followed by ':' and the scheduler name of the profile of the scheduler config its pods are simulated with, they're simulated with the default profile otherwise. If set to non-empty value, pending pods of other schedulers are ignored.")
 	drainPriorityConfig                     = flag.String("drain-priority-config", "",
 		"List of ',' separated pairs (priority:terminationGracePeriodSeconds) of integers separated by ':' enables priority evictor. Priority evictor groups pods into priority groups based on pod priority and evict pods in the ascending order of group priorities"+
 			"--max-graceful-termination-sec flag should not be set when this flag is set. Not setting this flag will use unordered evictor by default."+
 			"Priority evictor reuses the concepts of drain logic in kubelet(https://github.com/kubernetes/enhancements/tree/master/keps/sig-node/2712-pod-priority-based-graceful-node-shutdown#migration-from-the-node-graceful-shutdown-feature)."+
 			"Eg. flag usage:  '10000:20,1000:100,0:60'")
-	provisioningRequestsEnabled = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
-	frequentLoopsEnabled        = flag.Bool("frequent-loops-enabled", false, "Whether clusterautoscaler triggers new iterations more frequently when it's needed")
+	provisioningRequestsEnabled       = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
+	provisioningRequestExpirationTime = flag.Duration("provisioning-request-expiration-time", 7*24*time.Hour, "Time since creation after which ProvisioningRequests that weren't provisioned fail.")
+	provisioningRequestRetentionTime  = flag.Duration("provisioning-request-retention-time", 0, "Time after which failed or booking expired ProvisioningRequests are deleted. They are never deleted if it is 0.")
+	predictiveScaleUpAggressiveness   = flag.Float64("predictive-scale-up-aggressiveness", 0, "Number of pods predicted to arrive per pod created in the last --predictive-scale-up-window that was pending. Capacity for the predicted pods is requested ahead of them, e.g. during waves of job submissions. Disabled if 0.")
+	predictiveScaleUpWindow           = flag.Duration("predictive-scale-up-window", 2*time.Minute, "How long after their creation pending pods are counted by the predictive scale-up.")
+	capacityBuffersEnabled            = flag.Bool("enable-capacity-buffers", false, "Whether the clusterautoscaler will be keeping the spare capacity of the CapacityBuffer CRs.")
+	frequentLoopsEnabled              = flag.Bool("frequent-loops-enabled", false, "Whether clusterautoscaler triggers new iterations more frequently when it's needed")
+	verifyNodeTemplates               = flag.Bool("verify-node-templates", false, "If true, CA compares the template of each node group, used to scale it up from zero, with some of its nodes, reports the label and resource mismatches and exits instead of autoscaling. It exits with a non-zero code if there are mismatches.")
+	verifyNodeTemplatesSampleSize     = flag.Int("verify-node-templates-sample-size", 3, "Max number of nodes of each node group the template is compared with when --verify-node-templates is set")
 )
 
 func isFlagPassed(name string) bool {
@@ -284,6 +358,10 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 	if err != nil {
 		klog.Fatalf("Failed to parse flags: %v", err)
 	}
+	additionalSchedulersMap, err := scheduler_util.GetAdditionalSchedulersMap(*additionalSchedulers)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
 	// Convert memory limits to bytes.
 	minMemoryTotal = minMemoryTotal * units.GiB
 	maxMemoryTotal = maxMemoryTotal * units.GiB
@@ -292,9 +370,35 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 	if err != nil {
 		klog.Fatalf("Failed to parse flags: %v", err)
 	}
+	parsedScopedResourceLimits, err := parseMultipleScopedResourceLimits(*scopedResourceLimits)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
+	parsedNamespaceExpendableCutoffs, err := parseNamespaceExpendableCutoffs(*namespaceExpendableCutoffs)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
+	parsedNodeGroupHeadroom, err := parseNodeGroupHeadroom(*nodeGroupHeadroom)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
+	parsedNodeGroupBackoffPolicies, err := parseNodeGroupBackoffPolicies(*nodeGroupBackoffPolicies)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
+	parsedNodeGroupGangs, err := parseNodeGroupGangs(*nodeGroupGangs)
+	if err != nil {
+		klog.Fatalf("Failed to parse flags: %v", err)
+	}
 	if *maxDrainParallelismFlag > 1 && !*parallelDrain {
 		klog.Fatalf("Invalid configuration, could not use --max-drain-parallelism > 1 if --parallel-drain is false")
 	}
+	if *parallelEstimationWorkers < 1 {
+		klog.Fatalf("Invalid configuration, --parallel-estimation-workers must be at least 1")
+	}
+	if *predictiveScaleUpAggressiveness < 0 {
+		klog.Fatalf("Invalid configuration, --predictive-scale-up-aggressiveness must not be negative")
+	}
 
 	// in order to avoid inconsistent deletion thresholds for the legacy planner and the new actuator, the max-empty-bulk-delete,
 	// and max-scale-down-parallelism flags must be set to the same value.
@@ -343,6 +447,8 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 			ScaleDownUnreadyTime:             *scaleDownUnreadyTime,
 			IgnoreDaemonSetsUtilization:      *ignoreDaemonSetsUtilization,
 			MaxNodeProvisionTime:             *maxNodeProvisionTime,
+			ScaleDownDelayAfterAdd:           *scaleDownDelayAfterAdd,
+			MaxNewNodesPerMinute:             *maxNodeGroupNewNodesPerMinute,
 		},
 		CloudConfig:                      *cloudConfig,
 		CloudProviderName:                *cloudProviderFlag,
@@ -355,26 +461,40 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		ExpanderNames:                    *expanderFlag,
 		GRPCExpanderCert:                 *grpcExpanderCert,
 		GRPCExpanderURL:                  *grpcExpanderURL,
+		GRPCExpanderCacheTTL:             *grpcExpanderCacheTTL,
+		GRPCExpanderHealthCheckInterval:  *grpcExpanderHealthCheckInterval,
+		GRPCExpanderFallback:             *grpcExpanderFallback,
+		PriceExpanderFile:                *priceExpanderFile,
+		CarbonIntensityRegions:           *carbonIntensityRegions,
+		CarbonIntensityURL:               *carbonIntensityURL,
 		IgnoreMirrorPodsUtilization:      *ignoreMirrorPodsUtilization,
 		MaxBulkSoftTaintCount:            *maxBulkSoftTaintCount,
 		MaxBulkSoftTaintTime:             *maxBulkSoftTaintTime,
 		MaxEmptyBulkDelete:               *maxEmptyBulkDeleteFlag,
 		MaxGracefulTerminationSec:        *maxGracefulTerminationFlag,
 		MaxPodEvictionTime:               *maxPodEvictionTime,
+		MaxPodDrainTimeout:               *maxPodDrainTimeout,
+		MaxConcurrentEvictionsPerNode:    *maxConcurrentEvictions,
+		PodEvictionInterval:              *podEvictionInterval,
 		MaxNodesTotal:                    *maxNodesTotal,
 		MaxCoresTotal:                    maxCoresTotal,
 		MinCoresTotal:                    minCoresTotal,
 		MaxMemoryTotal:                   maxMemoryTotal,
 		MinMemoryTotal:                   minMemoryTotal,
 		GpuTotal:                         parsedGpuTotal,
+		ScopedResourceLimits:             parsedScopedResourceLimits,
 		NodeGroups:                       *nodeGroupsFlag,
 		EnforceNodeGroupMinSize:          *enforceNodeGroupMinSize,
+		NodeGroupHeadroom:                parsedNodeGroupHeadroom,
+		NodeGroupGangs:                   parsedNodeGroupGangs,
 		ScaleDownDelayAfterAdd:           *scaleDownDelayAfterAdd,
 		ScaleDownDelayTypeLocal:          *scaleDownDelayTypeLocal,
+		ScaleDownOrder:                   *scaleDownOrder,
 		ScaleDownDelayAfterDelete:        *scaleDownDelayAfterDelete,
 		ScaleDownDelayAfterFailure:       *scaleDownDelayAfterFailure,
 		ScaleDownEnabled:                 *scaleDownEnabled,
 		ScaleDownUnreadyEnabled:          *scaleDownUnreadyEnabled,
+		ScaleDownDryRun:                  *scaleDownDryRun,
 		ScaleDownNonEmptyCandidatesCount: *scaleDownNonEmptyCandidatesCount,
 		ScaleDownCandidatesPoolRatio:     *scaleDownCandidatesPoolRatio,
 		ScaleDownCandidatesPoolMinCount:  *scaleDownCandidatesPoolMinCount,
@@ -382,6 +502,17 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		SchedulerConfig:                  parsedSchedConfig,
 		WriteStatusConfigMap:             *writeStatusConfigMapFlag,
 		StatusConfigMapName:              *statusConfigMapName,
+		WriteStatusResource:              *writeStatusResourceFlag,
+		StatusResourceName:               *statusResourceName,
+		RuntimeConfigMapName:             *runtimeConfigMapName,
+		StateCheckpointConfigMapName:     *stateCheckpointConfigMapName,
+		StateCheckpointMaxAge:            *stateCheckpointMaxAge,
+		TemplateInjectionConfigMapName:   *templateInjectionConfigMapName,
+		ScheduledScalingConfigMapName:    *scheduledScalingConfigMapName,
+		ActuationWebhookURL:              *actuationWebhookURL,
+		ActuationWebhookTimeout:          *actuationWebhookTimeout,
+		ActuationWebhookFailurePolicy:    *actuationWebhookFailurePolicy,
+		DynamicResourceAllocationEnabled: *enableDynamicResourceAllocation,
 		BalanceSimilarNodeGroups:         *balanceSimilarNodeGroupsFlag,
 		ConfigNamespace:                  *namespace,
 		ClusterName:                      *clusterName,
@@ -389,12 +520,16 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		MaxAutoprovisionedNodeGroupCount: *maxAutoprovisionedNodeGroupCount,
 		UnremovableNodeRecheckTimeout:    *unremovableNodeRecheckTimeout,
 		ExpendablePodsPriorityCutoff:     *expendablePodsPriorityCutoff,
+		ExpendablePodsNamespaceCutoffs:   parsedNamespaceExpendableCutoffs,
 		Regional:                         *regional,
 		NewPodScaleUpDelay:               *newPodScaleUpDelay,
 		StartupTaints:                    append(*ignoreTaintsFlag, *startupTaintsFlag...),
 		StatusTaints:                     *statusTaintsFlag,
+		IgnoredTemplateTaintPrefixes:     *ignoredTemplateTaintsFlag,
+		IgnoredTemplateLabelPrefixes:     *ignoredTemplateLabelsFlag,
 		BalancingExtraIgnoredLabels:      *balancingIgnoreLabelsFlag,
 		BalancingLabels:                  *balancingLabelsFlag,
+		NodeInfoComparator:               *nodeInfoComparator,
 		KubeClientOpts: config.KubeClientOptions{
 			Master:         *kubernetes,
 			KubeConfigPath: *kubeConfigFile,
@@ -411,24 +546,36 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		CordonNodeBeforeTerminate:          *cordonNodeBeforeTerminate,
 		DaemonSetEvictionForEmptyNodes:     *daemonSetEvictionForEmptyNodes,
 		DaemonSetEvictionForOccupiedNodes:  *daemonSetEvictionForOccupiedNodes,
+		DaemonSetPdbTimeout:                *daemonSetPdbTimeout,
 		UserAgent:                          *userAgent,
 		InitialNodeGroupBackoffDuration:    *initialNodeGroupBackoffDuration,
 		MaxNodeGroupBackoffDuration:        *maxNodeGroupBackoffDuration,
 		NodeGroupBackoffResetTimeout:       *nodeGroupBackoffResetTimeout,
+		NodeGroupBackoffPolicies:           parsedNodeGroupBackoffPolicies,
 		MaxScaleDownParallelism:            *maxScaleDownParallelismFlag,
 		MaxDrainParallelism:                *maxDrainParallelismFlag,
 		RecordDuplicatedEvents:             *recordDuplicatedEvents,
+		AggregateScaleUpEvents:             *aggregateScaleUpEvents,
 		MaxNodesPerScaleUp:                 *maxNodesPerScaleUp,
+		MaxNewNodesPerMinute:               *maxNewNodesPerMinute,
+		MaxHourlyCost:                      *maxHourlyCost,
 		MaxNodeGroupBinpackingDuration:     *maxNodeGroupBinpackingDuration,
 		MaxBinpackingTime:                  *maxBinpackingTimeFlag,
+		ParallelEstimationWorkers:          *parallelEstimationWorkers,
 		NodeDeletionBatcherInterval:        *nodeDeletionBatcherInterval,
+		MaxConcurrentDeletionsPerZone:      *maxConcurrentDeletionsPerZone,
 		SkipNodesWithSystemPods:            *skipNodesWithSystemPods,
 		SkipNodesWithLocalStorage:          *skipNodesWithLocalStorage,
 		MinReplicaCount:                    *minReplicaCount,
 		NodeDeleteDelayAfterTaint:          *nodeDeleteDelayAfterTaint,
 		ScaleDownSimulationTimeout:         *scaleDownSimulationTimeout,
+		ScaleDownSimulationTargetDuration:  *scaleDownSimulationTargetDuration,
 		ParallelDrain:                      *parallelDrain,
 		SkipNodesWithCustomControllerPods:  *skipNodesWithCustomControllerPods,
+		DrainabilityWebhookURL:             *drainabilityWebhookURL,
+		DrainabilityWebhookTimeout:         *drainabilityWebhookTimeout,
+		DrainabilityWebhookCacheTTL:        *drainabilityWebhookCacheTTL,
+		DrainabilityWebhookFailClosed:      *drainabilityWebhookFailClosed,
 		NodeGroupSetRatios: config.NodeGroupDifferenceRatios{
 			MaxCapacityMemoryDifferenceRatio: *maxCapacityMemoryDifferenceRatio,
 			MaxAllocatableDifferenceRatio:    *maxAllocatableDifferenceRatio,
@@ -436,11 +583,17 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		},
 		DynamicNodeDeleteDelayAfterTaintEnabled: *dynamicNodeDeleteDelayAfterTaintEnabled,
 		BypassedSchedulers:                      scheduler_util.GetBypassedSchedulersMap(*bypassedSchedulers),
+		AdditionalSchedulers:                    additionalSchedulersMap,
 		ProvisioningRequestEnabled:              *provisioningRequestsEnabled,
+		ProvisioningRequestExpirationTime:       *provisioningRequestExpirationTime,
+		ProvisioningRequestRetentionTime:        *provisioningRequestRetentionTime,
+		CapacityBufferEnabled:                   *capacityBuffersEnabled,
+		PredictiveScaleUpAggressiveness:         *predictiveScaleUpAggressiveness,
+		PredictiveScaleUpWindow:                 *predictiveScaleUpWindow,
 	}
 }
 
-func registerSignalHandlers(autoscaler core.Autoscaler) {
+func registerSignalHandlers(autoscaler core.Autoscaler, tracerProvider tracing.TracerProvider) {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGQUIT)
 	klog.V(1).Info("Registered cleanup signal handler")
@@ -449,13 +602,18 @@ func registerSignalHandlers(autoscaler core.Autoscaler) {
 		<-sigs
 		klog.V(1).Info("Received signal, attempting cleanup")
 		autoscaler.ExitCleanUp()
+		if tracerProvider != nil {
+			if err := tracerProvider.Shutdown(ctx.Background()); err != nil {
+				klog.Warningf("Failed to flush spans: %v", err)
+			}
+		}
 		klog.V(1).Info("Cleaned up, exiting...")
 		klog.Flush()
 		os.Exit(0)
 	}()
 }
 
-func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter) (core.Autoscaler, error) {
+func buildAutoscaler(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter, unremovableNodesStatusProcessor *status.UnremovableNodesStatusProcessor) (core.Autoscaler, error) {
 	// Create basic config from flags.
 	autoscalingOptions := createAutoscalingOptions()
 
@@ -472,7 +630,7 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	}
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithTransform(trim))
 
-	predicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(informerFactory, autoscalingOptions.SchedulerConfig)
+	predicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(informerFactory, autoscalingOptions.SchedulerConfig, autoscalingOptions.AdditionalSchedulers)
 	if err != nil {
 		return nil, err
 	}
@@ -491,7 +649,19 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		ScaleUpOrchestrator:  orchestrator.New(),
 	}
 
+	if autoscalingOptions.WriteStatusResource {
+		statusResourceClient, err := dynamic.NewForConfig(kube_util.GetKubeConfig(autoscalingOptions.KubeClientOpts))
+		if err != nil {
+			return nil, err
+		}
+		opts.StatusResourceClient = statusResourceClient
+	}
+
 	opts.Processors = ca_processors.DefaultProcessors(autoscalingOptions)
+	opts.Processors.ScaleDownStatusProcessor = unremovableNodesStatusProcessor
+	if autoscalingOptions.AggregateScaleUpEvents {
+		opts.Processors.ScaleUpStatusProcessor = status.NewWorkloadEventingScaleUpStatusProcessor()
+	}
 	opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nodeInfoCacheExpireTime, *forceDaemonSets)
 	podListProcessor := podlistprocessor.NewDefaultPodListProcessor(opts.PredicateChecker)
 
@@ -510,7 +680,7 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		scaleUpOrchestrator := provreqorchestrator.NewWrapperOrchestrator(provreqOrchestrator)
 
 		opts.ScaleUpOrchestrator = scaleUpOrchestrator
-		provreqProcesor := provreq.NewProvReqProcessor(client)
+		provreqProcesor := provreq.NewProvReqProcessor(client, autoscalingOptions.ProvisioningRequestExpirationTime, autoscalingOptions.ProvisioningRequestRetentionTime)
 		if err != nil {
 			return nil, err
 		}
@@ -521,6 +691,30 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		}
 		podListProcessor.AddProcessor(injector)
 	}
+	if autoscalingOptions.CapacityBufferEnabled {
+		dynamicClient, err := dynamic.NewForConfig(kube_util.GetKubeConfig(autoscalingOptions.KubeClientOpts))
+		if err != nil {
+			return nil, err
+		}
+		client, err := capacitybuffer.NewCapacityBufferClient(dynamicClient, make(chan struct{}))
+		if err != nil {
+			return nil, err
+		}
+		// The buffer pods are injected first, so that the ones fitting on the existing nodes are filtered out.
+		podListProcessor.AddProcessor(capacitybufferprocessor.NewCapacityBufferStatusProcessor(client))
+		podListProcessor = pods.NewCombinedPodListProcessor([]pods.PodListProcessor{
+			capacitybufferprocessor.NewCapacityBufferPodsInjector(client),
+			podListProcessor,
+		})
+	}
+	if autoscalingOptions.PredictiveScaleUpAggressiveness > 0 {
+		// The arrivals are counted before the pods schedulable on the existing nodes are filtered out,
+		// and so are the predicted pods.
+		podListProcessor = pods.NewCombinedPodListProcessor([]pods.PodListProcessor{
+			predictive.NewPodArrivalPredictor(autoscalingOptions.PredictiveScaleUpAggressiveness, autoscalingOptions.PredictiveScaleUpWindow),
+			podListProcessor,
+		})
+	}
 	opts.Processors.PodListProcessor = podListProcessor
 	scaleDownCandidatesComparers := []scaledowncandidates.CandidatesComparer{}
 	if autoscalingOptions.ParallelDrain {
@@ -533,6 +727,14 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	}
 
 	cp := scaledowncandidates.NewCombinedScaleDownCandidatesProcessor()
+	if autoscalingOptions.ScaleDownOrder != "" {
+		// The candidates are ordered first, the sorting processor keeps their order where its comparers tie.
+		orderProcessor, err := scaledowncandidates.NewScaleDownCandidatesOrderProcessor(autoscalingOptions.ScaleDownOrder)
+		if err != nil {
+			return nil, err
+		}
+		cp.Register(orderProcessor)
+	}
 	cp.Register(scaledowncandidates.NewScaleDownCandidatesSortingProcessor(scaleDownCandidatesComparers))
 
 	if autoscalingOptions.ScaleDownDelayTypeLocal {
@@ -543,23 +745,52 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	}
 	opts.Processors.ScaleDownNodeProcessor = cp
 
+	// Cloud providers may register their node info comparators when they're built.
+	opts.CloudProvider = cloudBuilder.NewCloudProvider(autoscalingOptions, informerFactory)
+	if autoscalingOptions.ScheduledScalingConfigMapName != "" {
+		// Like the priority expander, the lister never receives the termination msg on the ch.
+		configMapLister := kube_util.NewConfigMapListerForNamespace(kubeClient, make(chan struct{}), autoscalingOptions.ConfigNamespace)
+		opts.CloudProvider = scheduledscaling.NewCloudProvider(opts.CloudProvider,
+			configMapLister.ConfigMaps(autoscalingOptions.ConfigNamespace), autoscalingOptions.ScheduledScalingConfigMapName)
+	}
 	var nodeInfoComparator nodegroupset.NodeInfoComparator
 	if len(autoscalingOptions.BalancingLabels) > 0 {
 		nodeInfoComparator = nodegroupset.CreateLabelNodeInfoComparator(autoscalingOptions.BalancingLabels)
 	} else {
-		nodeInfoComparatorBuilder := nodegroupset.CreateGenericNodeInfoComparator
-		if autoscalingOptions.CloudProviderName == cloudprovider.AzureProviderName {
-			nodeInfoComparatorBuilder = nodegroupset.CreateAzureNodeInfoComparator
-		} else if autoscalingOptions.CloudProviderName == cloudprovider.AwsProviderName {
-			nodeInfoComparatorBuilder = nodegroupset.CreateAwsNodeInfoComparator
+		if autoscalingOptions.CloudProviderName == cloudprovider.AwsProviderName {
 			opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewAsgTagResourceNodeInfoProvider(nodeInfoCacheExpireTime, *forceDaemonSets)
 		} else if autoscalingOptions.CloudProviderName == cloudprovider.GceProviderName {
-			nodeInfoComparatorBuilder = nodegroupset.CreateGceNodeInfoComparator
 			opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewAnnotationNodeInfoProvider(nodeInfoCacheExpireTime, *forceDaemonSets)
 		}
+		nodeInfoComparatorBuilder, found := nodegroupset.GetNodeInfoComparatorBuilder(autoscalingOptions.CloudProviderName)
+		if autoscalingOptions.NodeInfoComparator != "" {
+			nodeInfoComparatorBuilder, found = nodegroupset.GetNodeInfoComparatorBuilder(autoscalingOptions.NodeInfoComparator)
+			if !found {
+				return nil, fmt.Errorf("unknown node info comparator %q", autoscalingOptions.NodeInfoComparator)
+			}
+		}
+		if !found {
+			nodeInfoComparatorBuilder = nodegroupset.CreateGenericNodeInfoComparator
+		}
 		nodeInfoComparator = nodeInfoComparatorBuilder(autoscalingOptions.BalancingExtraIgnoredLabels, autoscalingOptions.NodeGroupSetRatios)
 	}
 
+	if autoscalingOptions.ActuationWebhookURL != "" {
+		actuationHooks, err := actuationhooks.NewWebhookActuationHooks(autoscalingOptions.ActuationWebhookURL,
+			autoscalingOptions.ActuationWebhookTimeout, actuationhooks.FailurePolicy(autoscalingOptions.ActuationWebhookFailurePolicy))
+		if err != nil {
+			return nil, err
+		}
+		opts.Processors.ActuationHooks = actuationHooks
+	}
+
+	if autoscalingOptions.TemplateInjectionConfigMapName != "" {
+		// Like the priority expander, the lister never receives the termination msg on the ch.
+		configMapLister := kube_util.NewConfigMapListerForNamespace(kubeClient, make(chan struct{}), autoscalingOptions.ConfigNamespace)
+		opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewInjectingNodeInfoProvider(opts.Processors.TemplateNodeInfoProvider,
+			configMapLister.ConfigMaps(autoscalingOptions.ConfigNamespace), autoscalingOptions.TemplateInjectionConfigMapName)
+	}
+
 	opts.Processors.NodeGroupSetProcessor = &nodegroupset.BalancingNodeGroupSetProcessor{
 		Comparator: nodeInfoComparator,
 	}
@@ -579,20 +810,76 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	// additional informers might have been registered in the factory during NewAutoscaler.
 	stop := make(chan struct{})
 	informerFactory.Start(stop)
+	healthCheck.AddReadinessCheck("informer-sync", func() error {
+		// A closed channel makes WaitForCacheSync return the current state of the informers right away.
+		closed := make(chan struct{})
+		close(closed)
+		for informerType, synced := range informerFactory.WaitForCacheSync(closed) {
+			if !synced {
+				return fmt.Errorf("informer for %v hasn't synced", informerType)
+			}
+		}
+		return nil
+	})
 
 	return autoscaler, nil
 }
 
-func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter) {
+// runNodeTemplatesVerification compares the templates of node groups with their nodes and exits.
+func runNodeTemplatesVerification() {
+	autoscalingOptions := createAutoscalingOptions()
+	kubeClient := kube_util.CreateKubeClient(autoscalingOptions.KubeClientOpts)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	cloudProvider := cloudBuilder.NewCloudProvider(autoscalingOptions, informerFactory)
+	stop := make(chan struct{})
+	informerFactory.Start(stop)
+	informerFactory.WaitForCacheSync(stop)
+
+	nodeList, err := kubeClient.CoreV1().Nodes().List(ctx.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Fatalf("Failed to get nodes from apiserver: %v", err)
+	}
+	if err := cloudProvider.Refresh(); err != nil {
+		klog.Fatalf("Failed to refresh cloud provider: %v", err)
+	}
+	nodes := make([]*apiv1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	mismatches, aErr := nodeinfosprovider.VerifyNodeGroupTemplates(cloudProvider, nodes, *verifyNodeTemplatesSampleSize, autoscalingOptions.BalancingExtraIgnoredLabels)
+	if aErr != nil {
+		klog.Fatalf("Failed to verify node templates: %v", aErr)
+	}
+	for _, mismatch := range mismatches {
+		klog.Warningf("Node template mismatch: %v", mismatch)
+	}
+	klog.V(1).Infof("Verified node templates, found %d mismatches", len(mismatches))
+	close(stop)
+	klog.Flush()
+	if len(mismatches) > 0 {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter, unremovableNodesStatusProcessor *status.UnremovableNodesStatusProcessor) {
 	metrics.RegisterAll(*emitPerNodeGroupMetrics)
 
-	autoscaler, err := buildAutoscaler(debuggingSnapshotter)
+	autoscaler, err := buildAutoscaler(healthCheck, debuggingSnapshotter, unremovableNodesStatusProcessor)
 	if err != nil {
 		klog.Fatalf("Failed to create autoscaler: %v", err)
 	}
 
+	var tracerProvider tracing.TracerProvider
+	if *tracingEndpoint != "" {
+		tracerProvider, err = tracing.Setup(*tracingEndpoint, int32(*tracingSamplingRatePerMillion))
+		if err != nil {
+			klog.Fatalf("Failed to set up tracing: %v", err)
+		}
+	}
+
 	// Register signal handlers for graceful shutdown.
-	registerSignalHandlers(autoscaler)
+	registerSignalHandlers(autoscaler, tracerProvider)
 
 	// Start updating health check endpoint.
 	healthCheck.StartMonitoring()
@@ -646,10 +933,31 @@ func main() {
 	}
 
 	healthCheck := metrics.NewHealthCheck(*maxInactivityTimeFlag, *maxFailingTimeFlag)
+	healthCheck.SetReadinessTimeouts(*readinessMaxFailingTimeFlag, *readinessMaxCloudFailingTimeFlag)
 
 	klog.V(1).Infof("Cluster Autoscaler %s", version.ClusterAutoscalerVersion)
 
 	debuggingSnapshotter := debuggingsnapshot.NewDebuggingSnapshotter(*debuggingSnapshotEnabled)
+	if *debuggingSnapshotCaptureDest != "" {
+		if !*debuggingSnapshotEnabled {
+			klog.Fatalf("--debugging-snapshot-capture-destination requires --debugging-snapshot-enabled")
+		}
+		sink := debuggingsnapshot.NewSnapshotSink(*debuggingSnapshotCaptureDest)
+		debuggingSnapshotter = debuggingsnapshot.NewAutoCapturingDebuggingSnapshotter(sink, *debuggingSnapshotCaptureInterval)
+	}
+	debuggingSnapshotHandler := debuggingSnapshotter.ResponseHandler
+	if *debuggingSnapshotTokenFile != "" {
+		token, err := os.ReadFile(*debuggingSnapshotTokenFile)
+		if err != nil {
+			klog.Fatalf("Failed to read debugging snapshot token file: %v", err)
+		}
+		if strings.TrimSpace(string(token)) == "" {
+			klog.Fatalf("Debugging snapshot token file %s is empty", *debuggingSnapshotTokenFile)
+		}
+		debuggingSnapshotHandler = debuggingsnapshot.AuthenticatedHandler(strings.TrimSpace(string(token)), debuggingSnapshotter.ResponseHandler)
+	}
+
+	unremovableNodesStatusProcessor := status.NewUnremovableNodesStatusProcessor()
 
 	go func() {
 		pathRecorderMux := mux.NewPathRecorderMux("cluster-autoscaler")
@@ -658,9 +966,12 @@ func main() {
 			defaultMetricsHandler(w, req)
 		})
 		if *debuggingSnapshotEnabled {
-			pathRecorderMux.HandleFunc("/snapshotz", debuggingSnapshotter.ResponseHandler)
+			pathRecorderMux.HandleFunc("/snapshotz", debuggingSnapshotHandler)
 		}
 		pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+		pathRecorderMux.HandleFunc("/livez", healthCheck.ServeLivez)
+		pathRecorderMux.HandleFunc("/readyz", healthCheck.ServeReadyz)
+		pathRecorderMux.HandleFunc("/unremovablez", unremovableNodesStatusProcessor.ServeHTTP)
 		if *enableProfiling {
 			routes.Profiling{}.Install(pathRecorderMux)
 		}
@@ -668,8 +979,12 @@ func main() {
 		klog.Fatalf("Failed to start metrics: %v", err)
 	}()
 
+	if *verifyNodeTemplates {
+		runNodeTemplatesVerification()
+	}
+
 	if !leaderElection.LeaderElect {
-		run(healthCheck, debuggingSnapshotter)
+		run(healthCheck, debuggingSnapshotter, unremovableNodesStatusProcessor)
 	} else {
 		id, err := os.Hostname()
 		if err != nil {
@@ -709,7 +1024,7 @@ func main() {
 				OnStartedLeading: func(_ ctx.Context) {
 					// Since we are committing a suicide after losing
 					// mastership, we can safely ignore the argument.
-					run(healthCheck, debuggingSnapshotter)
+					run(healthCheck, debuggingSnapshotter, unremovableNodesStatusProcessor)
 				},
 				OnStoppedLeading: func() {
 					klog.Fatalf("lost master")
@@ -816,3 +1131,139 @@ func parseSingleGpuLimit(limits string) (config.GpuLimits, error) {
 	}
 	return parsedGpuLimits, nil
 }
+
+func parseMultipleScopedResourceLimits(flags MultiStringFlag) ([]config.ScopedResourceLimit, error) {
+	parsedFlags := make([]config.ScopedResourceLimit, 0, len(flags))
+	for _, flag := range flags {
+		parsedFlag, err := parseSingleScopedResourceLimit(flag)
+		if err != nil {
+			return nil, err
+		}
+		parsedFlags = append(parsedFlags, parsedFlag)
+	}
+	return parsedFlags, nil
+}
+
+func parseSingleScopedResourceLimit(limit string) (config.ScopedResourceLimit, error) {
+	parts := strings.SplitN(limit, ":", 3)
+	if len(parts) != 3 {
+		return config.ScopedResourceLimit{}, fmt.Errorf("incorrect scoped resource limit specification: %v", limit)
+	}
+	maxVal, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return config.ScopedResourceLimit{}, fmt.Errorf("incorrect scoped resource limit - max is not integer: %v", limit)
+	}
+	if maxVal < 0 {
+		return config.ScopedResourceLimit{}, fmt.Errorf("incorrect scoped resource limit - max is less than 0; %v", limit)
+	}
+	selector, err := labels.Parse(parts[2])
+	if err != nil {
+		return config.ScopedResourceLimit{}, fmt.Errorf("incorrect scoped resource limit - invalid label selector: %v: %v", limit, err)
+	}
+	parsedLimit := config.ScopedResourceLimit{Max: maxVal, Selector: selector}
+	switch parts[0] {
+	case "cores":
+		parsedLimit.Resource = cloudprovider.ResourceNameCores
+	case "memory":
+		parsedLimit.Resource = cloudprovider.ResourceNameMemory
+		parsedLimit.Max = maxVal * units.GiB
+	default:
+		return config.ScopedResourceLimit{}, fmt.Errorf("incorrect scoped resource limit - resource is not cores or memory: %v", limit)
+	}
+	return parsedLimit, nil
+}
+
+func parseNamespaceExpendableCutoffs(flags MultiStringFlag) (map[string]int, error) {
+	cutoffs := make(map[string]int, len(flags))
+	for _, flag := range flags {
+		namespace, cutoff, found := strings.Cut(flag, ":")
+		if !found || namespace == "" {
+			return nil, fmt.Errorf("incorrect namespace expendable pods priority cutoff specification: %v", flag)
+		}
+		parsedCutoff, err := strconv.Atoi(cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect namespace expendable pods priority cutoff - cutoff is not integer: %v", flag)
+		}
+		if _, found := cutoffs[namespace]; found {
+			return nil, fmt.Errorf("incorrect namespace expendable pods priority cutoff - namespace %s is set more than once", namespace)
+		}
+		cutoffs[namespace] = parsedCutoff
+	}
+	return cutoffs, nil
+}
+
+func parseNodeGroupHeadroom(flags MultiStringFlag) (map[string]config.NodeGroupHeadroom, error) {
+	headroom := make(map[string]config.NodeGroupHeadroom, len(flags))
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			return nil, fmt.Errorf("incorrect node group headroom specification: %v", flag)
+		}
+		units, err := strconv.Atoi(parts[0])
+		if err != nil || units <= 0 {
+			return nil, fmt.Errorf("incorrect node group headroom - units is not a positive integer: %v", flag)
+		}
+		unit := apiv1.ResourceList{}
+		for _, r := range strings.Split(parts[1], ",") {
+			name, value, found := strings.Cut(r, "=")
+			if !found || name == "" {
+				return nil, fmt.Errorf("incorrect node group headroom - unit resource is not in the format <resource>=<quantity>: %v", flag)
+			}
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect node group headroom - invalid quantity of %s: %v", name, flag)
+			}
+			unit[apiv1.ResourceName(name)] = quantity
+		}
+		if _, found := headroom[parts[2]]; found {
+			return nil, fmt.Errorf("incorrect node group headroom - node group %s is set more than once", parts[2])
+		}
+		headroom[parts[2]] = config.NodeGroupHeadroom{Units: units, Unit: unit}
+	}
+	return headroom, nil
+}
+
+func parseNodeGroupGangs(flags MultiStringFlag) ([][]string, error) {
+	var gangs [][]string
+	inGang := map[string]bool{}
+	for _, flag := range flags {
+		gang := strings.Split(flag, ",")
+		if len(gang) < 2 {
+			return nil, fmt.Errorf("incorrect node group gang - it needs at least 2 node groups: %v", flag)
+		}
+		for _, id := range gang {
+			if id == "" {
+				return nil, fmt.Errorf("incorrect node group gang specification: %v", flag)
+			}
+			if inGang[id] {
+				return nil, fmt.Errorf("incorrect node group gang - node group %s is set more than once", id)
+			}
+			inGang[id] = true
+		}
+		gangs = append(gangs, gang)
+	}
+	return gangs, nil
+}
+
+func parseNodeGroupBackoffPolicies(flags MultiStringFlag) (map[string]config.NodeGroupBackoffPolicy, error) {
+	policies := make(map[string]config.NodeGroupBackoffPolicy, len(flags))
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			return nil, fmt.Errorf("incorrect node group backoff policy specification: %v", flag)
+		}
+		initialBackoff, err := time.ParseDuration(parts[0])
+		if err != nil || initialBackoff <= 0 {
+			return nil, fmt.Errorf("incorrect node group backoff policy - initial backoff duration is not a positive duration: %v", flag)
+		}
+		maxBackoff, err := time.ParseDuration(parts[1])
+		if err != nil || maxBackoff < initialBackoff {
+			return nil, fmt.Errorf("incorrect node group backoff policy - max backoff duration is not a duration of at least the initial one: %v", flag)
+		}
+		if _, found := policies[parts[2]]; found {
+			return nil, fmt.Errorf("incorrect node group backoff policy - error code or class %s is set more than once", parts[2])
+		}
+		policies[parts[2]] = config.NodeGroupBackoffPolicy{InitialBackoffDuration: initialBackoff, MaxBackoffDuration: maxBackoff}
+	}
+	return policies, nil
+}
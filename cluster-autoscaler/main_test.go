@@ -18,8 +18,14 @@ package main
 
 import (
 	"testing"
+	"time"
 
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -91,3 +97,246 @@ func TestParseSingleGpuLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSingleScopedResourceLimit(t *testing.T) {
+	gpuSelector, err := labels.Parse("nvidia.com/gpu.present=true")
+	assert.NoError(t, err)
+	poolSelector, err := labels.Parse("pool in (general,batch)")
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		input                string
+		expectedLimit        config.ScopedResourceLimit
+		expectedErrorMessage string
+	}{
+		{
+			input:         "cores:256:nvidia.com/gpu.present=true",
+			expectedLimit: config.ScopedResourceLimit{Resource: cloudprovider.ResourceNameCores, Max: 256, Selector: gpuSelector},
+		},
+		{
+			input:         "memory:2:pool in (general,batch)",
+			expectedLimit: config.ScopedResourceLimit{Resource: cloudprovider.ResourceNameMemory, Max: 2 * units.GiB, Selector: poolSelector},
+		},
+		{
+			input:                "cores:256",
+			expectedErrorMessage: "incorrect scoped resource limit specification: cores:256",
+		},
+		{
+			input:                "cores:x:pool=general",
+			expectedErrorMessage: "incorrect scoped resource limit - max is not integer: cores:x:pool=general",
+		},
+		{
+			input:                "cores:-1:pool=general",
+			expectedErrorMessage: "incorrect scoped resource limit - max is less than 0; cores:-1:pool=general",
+		},
+		{
+			input:                "gpu:1:pool=general",
+			expectedErrorMessage: "incorrect scoped resource limit - resource is not cores or memory: gpu:1:pool=general",
+		},
+	}
+
+	for _, testcase := range testcases {
+		limit, err := parseSingleScopedResourceLimit(testcase.input)
+		if testcase.expectedErrorMessage != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, testcase.expectedErrorMessage, err.Error())
+			}
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedLimit, limit)
+		}
+	}
+}
+
+func TestParseNamespaceExpendableCutoffs(t *testing.T) {
+	testcases := []struct {
+		input                []string
+		expectedCutoffs      map[string]int
+		expectedErrorMessage string
+	}{
+		{
+			input:           nil,
+			expectedCutoffs: map[string]int{},
+		},
+		{
+			input:           []string{"fillers:100", "critical:-20"},
+			expectedCutoffs: map[string]int{"fillers": 100, "critical": -20},
+		},
+		{
+			input:                []string{"fillers"},
+			expectedErrorMessage: "incorrect namespace expendable pods priority cutoff specification: fillers",
+		},
+		{
+			input:                []string{":100"},
+			expectedErrorMessage: "incorrect namespace expendable pods priority cutoff specification: :100",
+		},
+		{
+			input:                []string{"fillers:x"},
+			expectedErrorMessage: "incorrect namespace expendable pods priority cutoff - cutoff is not integer: fillers:x",
+		},
+		{
+			input:                []string{"fillers:100", "fillers:10"},
+			expectedErrorMessage: "incorrect namespace expendable pods priority cutoff - namespace fillers is set more than once",
+		},
+	}
+
+	for _, testcase := range testcases {
+		cutoffs, err := parseNamespaceExpendableCutoffs(testcase.input)
+		if testcase.expectedErrorMessage != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, testcase.expectedErrorMessage, err.Error())
+			}
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedCutoffs, cutoffs)
+		}
+	}
+}
+
+func TestParseNodeGroupHeadroom(t *testing.T) {
+	testcases := []struct {
+		input                []string
+		expectedHeadroom     map[string]config.NodeGroupHeadroom
+		expectedErrorMessage string
+	}{
+		{
+			input:            nil,
+			expectedHeadroom: map[string]config.NodeGroupHeadroom{},
+		},
+		{
+			input: []string{"3:cpu=2,memory=4Gi:ng1", "1:nvidia.com/gpu=1:ng2"},
+			expectedHeadroom: map[string]config.NodeGroupHeadroom{
+				"ng1": {Units: 3, Unit: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("4Gi")}},
+				"ng2": {Units: 1, Unit: apiv1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}},
+			},
+		},
+		{
+			input:                []string{"3:cpu=2"},
+			expectedErrorMessage: "incorrect node group headroom specification: 3:cpu=2",
+		},
+		{
+			input:                []string{"0:cpu=2:ng1"},
+			expectedErrorMessage: "incorrect node group headroom - units is not a positive integer: 0:cpu=2:ng1",
+		},
+		{
+			input:                []string{"3:cpu:ng1"},
+			expectedErrorMessage: "incorrect node group headroom - unit resource is not in the format <resource>=<quantity>: 3:cpu:ng1",
+		},
+		{
+			input:                []string{"3:cpu=x:ng1"},
+			expectedErrorMessage: "incorrect node group headroom - invalid quantity of cpu: 3:cpu=x:ng1",
+		},
+		{
+			input:                []string{"3:cpu=2:ng1", "1:cpu=1:ng1"},
+			expectedErrorMessage: "incorrect node group headroom - node group ng1 is set more than once",
+		},
+	}
+
+	for _, testcase := range testcases {
+		headroom, err := parseNodeGroupHeadroom(testcase.input)
+		if testcase.expectedErrorMessage != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, testcase.expectedErrorMessage, err.Error())
+			}
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedHeadroom, headroom)
+		}
+	}
+}
+
+func TestParseNodeGroupGangs(t *testing.T) {
+	testcases := []struct {
+		input                []string
+		expectedGangs        [][]string
+		expectedErrorMessage string
+	}{
+		{
+			input: nil,
+		},
+		{
+			input:         []string{"cpu,gpu", "a,b,c"},
+			expectedGangs: [][]string{{"cpu", "gpu"}, {"a", "b", "c"}},
+		},
+		{
+			input:                []string{"cpu"},
+			expectedErrorMessage: "incorrect node group gang - it needs at least 2 node groups: cpu",
+		},
+		{
+			input:                []string{"cpu,,gpu"},
+			expectedErrorMessage: "incorrect node group gang specification: cpu,,gpu",
+		},
+		{
+			input:                []string{"cpu,gpu", "gpu,tpu"},
+			expectedErrorMessage: "incorrect node group gang - node group gpu is set more than once",
+		},
+		{
+			input:                []string{"cpu,cpu"},
+			expectedErrorMessage: "incorrect node group gang - node group cpu is set more than once",
+		},
+	}
+
+	for _, testcase := range testcases {
+		gangs, err := parseNodeGroupGangs(testcase.input)
+		if testcase.expectedErrorMessage != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, testcase.expectedErrorMessage, err.Error())
+			}
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedGangs, gangs)
+		}
+	}
+}
+
+func TestParseNodeGroupBackoffPolicies(t *testing.T) {
+	testcases := []struct {
+		input                []string
+		expectedPolicies     map[string]config.NodeGroupBackoffPolicy
+		expectedErrorMessage string
+	}{
+		{
+			input:            nil,
+			expectedPolicies: map[string]config.NodeGroupBackoffPolicy{},
+		},
+		{
+			input: []string{"30m:3h:QUOTA_EXCEEDED", "1m:1m:Other"},
+			expectedPolicies: map[string]config.NodeGroupBackoffPolicy{
+				"QUOTA_EXCEEDED": {InitialBackoffDuration: 30 * time.Minute, MaxBackoffDuration: 3 * time.Hour},
+				"Other":          {InitialBackoffDuration: time.Minute, MaxBackoffDuration: time.Minute},
+			},
+		},
+		{
+			input:                []string{"30m:3h"},
+			expectedErrorMessage: "incorrect node group backoff policy specification: 30m:3h",
+		},
+		{
+			input:                []string{"0s:3h:Other"},
+			expectedErrorMessage: "incorrect node group backoff policy - initial backoff duration is not a positive duration: 0s:3h:Other",
+		},
+		{
+			input:                []string{"30m:1m:Other"},
+			expectedErrorMessage: "incorrect node group backoff policy - max backoff duration is not a duration of at least the initial one: 30m:1m:Other",
+		},
+		{
+			input:                []string{"30m:x:Other"},
+			expectedErrorMessage: "incorrect node group backoff policy - max backoff duration is not a duration of at least the initial one: 30m:x:Other",
+		},
+		{
+			input:                []string{"30m:3h:Other", "1m:5m:Other"},
+			expectedErrorMessage: "incorrect node group backoff policy - error code or class Other is set more than once",
+		},
+	}
+
+	for _, testcase := range testcases {
+		policies, err := parseNodeGroupBackoffPolicies(testcase.input)
+		if testcase.expectedErrorMessage != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, testcase.expectedErrorMessage, err.Error())
+			}
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedPolicies, policies)
+		}
+	}
+}
@@ -31,6 +31,13 @@ type HealthCheck struct {
 	activityTimeout   time.Duration
 	successTimeout    time.Duration
 	checkTimeout      bool
+
+	readinessSuccessTimeout  time.Duration
+	cloudProviderTimeout     time.Duration
+	lastCloudProviderSuccess time.Time
+	lastCloudProviderFailure time.Time
+	lastCloudProviderError   string
+	readinessChecks          []namedCheck
 }
 
 // NewHealthCheck builds new HealthCheck object with given timeout
@@ -43,6 +50,10 @@ func NewHealthCheck(activityTimeout, successTimeout time.Duration) *HealthCheck
 		activityTimeout:   activityTimeout,
 		successTimeout:    successTimeout,
 		checkTimeout:      false,
+
+		readinessSuccessTimeout:  successTimeout,
+		cloudProviderTimeout:     successTimeout,
+		lastCloudProviderSuccess: now,
 	}
 }
 
@@ -58,6 +69,9 @@ func (hc *HealthCheck) StartMonitoring() {
 	if now.After(hc.lastSuccessfulRun) {
 		hc.lastSuccessfulRun = now
 	}
+	if now.After(hc.lastCloudProviderSuccess) {
+		hc.lastCloudProviderSuccess = now
+	}
 }
 
 // ServeHTTP implements http.Handler interface to provide a health-check endpoint
@@ -82,6 +96,25 @@ func (hc *HealthCheck) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ServeLivez implements a liveness endpoint, which fails when the autoscaler wasn't active or didn't
+// run successfully for longer than the timeouts, and lists the result of each check.
+func (hc *HealthCheck) ServeLivez(w http.ResponseWriter, r *http.Request) {
+	hc.mutex.Lock()
+	now := time.Now()
+	checks := []namedCheck{
+		{name: "activity", check: func() error {
+			return checkTimeout(hc.checkTimeout, "activity", hc.lastActivity, hc.activityTimeout, now)
+		}},
+		{name: "successful-run", check: func() error {
+			return checkTimeout(hc.checkTimeout, "successful run", hc.lastSuccessfulRun, hc.successTimeout, now)
+		}},
+	}
+	results := runChecks(checks)
+	hc.mutex.Unlock()
+
+	writeCheckResults(w, "livez", results)
+}
+
 // UpdateLastActivity updates last time of activity
 func (hc *HealthCheck) UpdateLastActivity(timestamp time.Time) {
 	hc.mutex.Lock()
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	_ "k8s.io/component-base/metrics/prometheus/restclient" // for client-go metrics registration
@@ -232,6 +233,30 @@ var (
 		}, []string{"node_group", "reason"},
 	)
 
+	nodesGroupNodesCount = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "node_group_nodes_count",
+			Help:      "Number of nodes in the node group.",
+		}, []string{"node_group", "state"},
+	)
+
+	nodeGroupLastScaleTime = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "node_group_last_scale_time",
+			Help:      "Last time CA scaled the node group, by direction.",
+		}, []string{"node_group", "direction"},
+	)
+
+	nodeGroupFailedScaleUpCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "node_group_failed_scale_ups_total",
+			Help:      "Number of times scale-up operation of the node group has failed.",
+		}, []string{"node_group", "reason"},
+	)
+
 	/**** Metrics related to autoscaler execution ****/
 	lastActivity = k8smetrics.NewGaugeVec(
 		&k8smetrics.GaugeOpts{
@@ -349,6 +374,15 @@ var (
 		[]string{"reason"},
 	)
 
+	unremovableNodesBlockedByPodCount = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "unremovable_nodes_blocked_by_pod_count",
+			Help:      "Number of nodes currently considered unremovable by CA because of a pod, by the reason the pod blocks them.",
+		},
+		[]string{"reason"},
+	)
+
 	scaleDownInCooldown = k8smetrics.NewGauge(
 		&k8smetrics.GaugeOpts{
 			Namespace: caNamespace,
@@ -357,6 +391,30 @@ var (
 		},
 	)
 
+	scaleDownDryRunNodesCount = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "scale_down_dry_run_nodes_count",
+			Help:      "Number of nodes CA would have removed in the last scale-down dry run.",
+		},
+	)
+
+	scaleDownDryRunMonthlySavings = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "scale_down_dry_run_estimated_monthly_savings",
+			Help:      "Estimated monthly cost of the nodes CA would have removed in the last scale-down dry run, in the currency of the cloud provider pricing.",
+		},
+	)
+
+	hourlyCostBudgetLeft = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "hourly_cost_budget_left",
+			Help:      "Hourly cost that can still be added to the cluster before reaching the max hourly cost, in the currency of the cloud provider pricing.",
+		},
+	)
+
 	oldUnregisteredNodesRemovedCount = k8smetrics.NewCounter(
 		&k8smetrics.CounterOpts{
 			Namespace: caNamespace,
@@ -419,8 +477,13 @@ var (
 	)
 )
 
+// perNodeGroupMetricsEnabled is set by RegisterAll if per node group metrics are registered. Per node group
+// metrics that are updated on every loop are only updated if it is set.
+var perNodeGroupMetricsEnabled bool
+
 // RegisterAll registers all metrics.
 func RegisterAll(emitPerNodeGroupMetrics bool) {
+	perNodeGroupMetricsEnabled = emitPerNodeGroupMetrics
 	legacyregistry.MustRegister(clusterSafeToAutoscale)
 	legacyregistry.MustRegister(nodesCount)
 	legacyregistry.MustRegister(nodeGroupsCount)
@@ -443,7 +506,11 @@ func RegisterAll(emitPerNodeGroupMetrics bool) {
 	legacyregistry.MustRegister(evictionsCount)
 	legacyregistry.MustRegister(unneededNodesCount)
 	legacyregistry.MustRegister(unremovableNodesCount)
+	legacyregistry.MustRegister(unremovableNodesBlockedByPodCount)
 	legacyregistry.MustRegister(scaleDownInCooldown)
+	legacyregistry.MustRegister(scaleDownDryRunNodesCount)
+	legacyregistry.MustRegister(scaleDownDryRunMonthlySavings)
+	legacyregistry.MustRegister(hourlyCostBudgetLeft)
 	legacyregistry.MustRegister(oldUnregisteredNodesRemovedCount)
 	legacyregistry.MustRegister(overflowingControllersCount)
 	legacyregistry.MustRegister(skippedScaleEventsCount)
@@ -459,6 +526,9 @@ func RegisterAll(emitPerNodeGroupMetrics bool) {
 		legacyregistry.MustRegister(nodesGroupTargetSize)
 		legacyregistry.MustRegister(nodesGroupHealthiness)
 		legacyregistry.MustRegister(nodeGroupBackOffStatus)
+		legacyregistry.MustRegister(nodesGroupNodesCount)
+		legacyregistry.MustRegister(nodeGroupLastScaleTime)
+		legacyregistry.MustRegister(nodeGroupFailedScaleUpCount)
 	}
 }
 
@@ -503,6 +573,18 @@ func UpdateNodesCount(ready, unready, starting, longUnregistered, unregistered i
 	nodesCount.WithLabelValues(unregisteredLabel).Set(float64(unregistered))
 }
 
+// UpdateNodeGroupNodesCount records the number of nodes in the node group
+func UpdateNodeGroupNodesCount(nodeGroup string, ready, unready, starting, longUnregistered, unregistered int) {
+	if !perNodeGroupMetricsEnabled {
+		return
+	}
+	nodesGroupNodesCount.WithLabelValues(nodeGroup, readyLabel).Set(float64(ready))
+	nodesGroupNodesCount.WithLabelValues(nodeGroup, unreadyLabel).Set(float64(unready))
+	nodesGroupNodesCount.WithLabelValues(nodeGroup, startingLabel).Set(float64(starting))
+	nodesGroupNodesCount.WithLabelValues(nodeGroup, longUnregisteredLabel).Set(float64(longUnregistered))
+	nodesGroupNodesCount.WithLabelValues(nodeGroup, unregisteredLabel).Set(float64(unregistered))
+}
+
 // UpdateNodeGroupsCount records the number of node groups managed by CA
 func UpdateNodeGroupsCount(autoscaled, autoprovisioned int) {
 	nodeGroupsCount.WithLabelValues(string(autoscaledGroup)).Set(float64(autoscaled))
@@ -610,6 +692,22 @@ func RegisterFailedScaleUp(reason FailedScaleUpReason, gpuResourceName, gpuType
 	}
 }
 
+// RegisterNodeGroupFailedScaleUp records a failed scale-up operation of the node group
+func RegisterNodeGroupFailedScaleUp(nodeGroup string, reason FailedScaleUpReason) {
+	if !perNodeGroupMetricsEnabled {
+		return
+	}
+	nodeGroupFailedScaleUpCount.WithLabelValues(nodeGroup, string(reason)).Inc()
+}
+
+// UpdateNodeGroupLastScaleTime records the last time the node group was scaled in the given direction
+func UpdateNodeGroupLastScaleTime(nodeGroup string, direction string, scaleTime time.Time) {
+	if !perNodeGroupMetricsEnabled {
+		return
+	}
+	nodeGroupLastScaleTime.WithLabelValues(nodeGroup, direction).Set(float64(scaleTime.Unix()))
+}
+
 // RegisterScaleDown records number of nodes removed by scale down
 func RegisterScaleDown(nodesCount int, gpuResourceName, gpuType string, reason NodeScaleDownReason) {
 	scaleDownCount.WithLabelValues(string(reason)).Add(float64(nodesCount))
@@ -631,7 +729,16 @@ func UpdateUnneededNodesCount(nodesCount int) {
 // UpdateUnremovableNodesCount records number of currently unremovable nodes
 func UpdateUnremovableNodesCount(unremovableReasonCounts map[simulator.UnremovableReason]int) {
 	for reason, count := range unremovableReasonCounts {
-		unremovableNodesCount.WithLabelValues(fmt.Sprintf("%v", reason)).Set(float64(count))
+		unremovableNodesCount.WithLabelValues(fmt.Sprintf("%d", reason)).Set(float64(count))
+	}
+}
+
+// UpdateUnremovableNodesBlockedByPodCount records number of currently unremovable nodes
+// blocked by a pod, by the reason why the pod blocks them
+func UpdateUnremovableNodesBlockedByPodCount(blockingPodReasonCounts map[drain.BlockingPodReason]int) {
+	unremovableNodesBlockedByPodCount.Reset()
+	for reason, count := range blockingPodReasonCounts {
+		unremovableNodesBlockedByPodCount.WithLabelValues(reason.String()).Set(float64(count))
 	}
 }
 
@@ -674,6 +781,19 @@ func UpdateScaleDownInCooldown(inCooldown bool) {
 	}
 }
 
+// UpdateScaleDownDryRun records the number of nodes CA would have removed in the last
+// scale-down dry run and their estimated monthly cost
+func UpdateScaleDownDryRun(nodesCount int, monthlySavings float64) {
+	scaleDownDryRunNodesCount.Set(float64(nodesCount))
+	scaleDownDryRunMonthlySavings.Set(monthlySavings)
+}
+
+// UpdateHourlyCostBudgetLeft records the hourly cost that can still be added to the cluster
+// before reaching the max hourly cost
+func UpdateHourlyCostBudgetLeft(budgetLeft float64) {
+	hourlyCostBudgetLeft.Set(budgetLeft)
+}
+
 // RegisterOldUnregisteredNodesRemoved records number of old unregistered
 // nodes that have been removed by the cluster autoscaler
 func RegisterOldUnregisteredNodesRemoved(nodesCount int) {
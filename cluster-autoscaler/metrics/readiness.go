@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type namedCheck struct {
+	name  string
+	check func() error
+}
+
+type checkResult struct {
+	name string
+	err  error
+}
+
+// SetReadinessTimeouts sets how long ago the last successful run may have ended and for how long
+// the cloud provider may have been failing for the autoscaler to be ready.
+func (hc *HealthCheck) SetReadinessTimeouts(successTimeout, cloudProviderTimeout time.Duration) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.readinessSuccessTimeout = successTimeout
+	hc.cloudProviderTimeout = cloudProviderTimeout
+}
+
+// AddReadinessCheck adds a check that has to pass for the autoscaler to be ready.
+func (hc *HealthCheck) AddReadinessCheck(name string, check func() error) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.readinessChecks = append(hc.readinessChecks, namedCheck{name: name, check: check})
+}
+
+// UpdateCloudProviderSuccess records that the cloud provider was reachable at the given time.
+func (hc *HealthCheck) UpdateCloudProviderSuccess(timestamp time.Time) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	if timestamp.After(hc.lastCloudProviderSuccess) {
+		hc.lastCloudProviderSuccess = timestamp
+	}
+}
+
+// UpdateCloudProviderFailure records that the cloud provider failed with the given error at the given time.
+func (hc *HealthCheck) UpdateCloudProviderFailure(timestamp time.Time, err error) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	if timestamp.After(hc.lastCloudProviderFailure) {
+		hc.lastCloudProviderFailure = timestamp
+		hc.lastCloudProviderError = err.Error()
+	}
+}
+
+// ServeReadyz implements a readiness endpoint, which fails until the autoscaler starts running, when it
+// didn't run successfully recently, when the cloud provider has been failing for too long or when any of
+// the added readiness checks fails. It lists the result of each check.
+func (hc *HealthCheck) ServeReadyz(w http.ResponseWriter, r *http.Request) {
+	hc.mutex.Lock()
+	now := time.Now()
+	checks := []namedCheck{
+		{name: "started", check: func() error {
+			if !hc.checkTimeout {
+				return fmt.Errorf("autoscaler isn't running, e.g. because it isn't the leader")
+			}
+			return nil
+		}},
+		{name: "successful-run", check: func() error {
+			return checkTimeout(hc.checkTimeout, "successful run", hc.lastSuccessfulRun, hc.readinessSuccessTimeout, now)
+		}},
+		{name: "cloud-provider", check: func() error {
+			if !hc.checkTimeout || !hc.lastCloudProviderFailure.After(hc.lastCloudProviderSuccess) {
+				return nil
+			}
+			if err := checkTimeout(true, "cloud provider success", hc.lastCloudProviderSuccess, hc.cloudProviderTimeout, now); err != nil {
+				return fmt.Errorf("%v, last error: %s", err, hc.lastCloudProviderError)
+			}
+			return nil
+		}},
+	}
+	results := runChecks(checks)
+	readinessChecks := hc.readinessChecks
+	hc.mutex.Unlock()
+
+	results = append(results, runChecks(readinessChecks)...)
+	writeCheckResults(w, "readyz", results)
+}
+
+func checkTimeout(enabled bool, what string, last time.Time, timeout time.Duration, now time.Time) error {
+	if enabled && now.After(last.Add(timeout)) {
+		return fmt.Errorf("last %s %v ago, more than %v", what, now.Sub(last).Round(time.Second), timeout)
+	}
+	return nil
+}
+
+func runChecks(checks []namedCheck) []checkResult {
+	results := make([]checkResult, 0, len(checks))
+	for _, c := range checks {
+		results = append(results, checkResult{name: c.name, err: c.check()})
+	}
+	return results
+}
+
+func writeCheckResults(w http.ResponseWriter, endpoint string, results []checkResult) {
+	var body strings.Builder
+	failed := false
+	for _, result := range results {
+		if result.err != nil {
+			failed = true
+			fmt.Fprintf(&body, "[-]%s failed: %v\n", result.name, result.err)
+		} else {
+			fmt.Fprintf(&body, "[+]%s ok\n", result.name)
+		}
+	}
+	if failed {
+		fmt.Fprintf(&body, "%s check failed\n", endpoint)
+		w.WriteHeader(500)
+	} else {
+		fmt.Fprintf(&body, "%s check passed\n", endpoint)
+		w.WriteHeader(200)
+	}
+	w.Write([]byte(body.String()))
+}
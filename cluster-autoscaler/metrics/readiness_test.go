@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeReadyz(t *testing.T) {
+	timeout := time.Minute
+	testCases := []struct {
+		name            string
+		started         bool
+		lastSuccess     time.Duration
+		cloudSuccess    time.Duration
+		cloudFailure    time.Duration
+		extraCheckErr   error
+		wantCode        int
+		wantBodyContain []string
+	}{
+		{
+			name:            "not started",
+			wantCode:        500,
+			wantBodyContain: []string{"[-]started failed", "[+]successful-run ok", "[+]cloud-provider ok", "readyz check failed"},
+		},
+		{
+			name:            "ready",
+			started:         true,
+			wantCode:        200,
+			wantBodyContain: []string{"[+]started ok", "[+]successful-run ok", "[+]cloud-provider ok", "[+]extra ok", "readyz check passed"},
+		},
+		{
+			name:            "no recent successful run",
+			started:         true,
+			lastSuccess:     -2 * timeout,
+			wantCode:        500,
+			wantBodyContain: []string{"[-]successful-run failed"},
+		},
+		{
+			name:            "cloud provider failing for too long",
+			started:         true,
+			cloudSuccess:    -2 * timeout,
+			cloudFailure:    -time.Second,
+			wantCode:        500,
+			wantBodyContain: []string{"[-]cloud-provider failed", "last error: cloud provider error"},
+		},
+		{
+			name:            "cloud provider failing recently",
+			started:         true,
+			cloudSuccess:    -time.Second,
+			cloudFailure:    -time.Millisecond,
+			wantCode:        200,
+			wantBodyContain: []string{"[+]cloud-provider ok"},
+		},
+		{
+			name:            "cloud provider recovered",
+			started:         true,
+			cloudSuccess:    -time.Millisecond,
+			cloudFailure:    -2 * timeout,
+			wantCode:        200,
+			wantBodyContain: []string{"[+]cloud-provider ok"},
+		},
+		{
+			name:            "failing readiness check",
+			started:         true,
+			extraCheckErr:   fmt.Errorf("not synced"),
+			wantCode:        500,
+			wantBodyContain: []string{"[-]extra failed: not synced"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			healthCheck := NewHealthCheck(timeout, timeout)
+			healthCheck.SetReadinessTimeouts(timeout, timeout)
+			healthCheck.AddReadinessCheck("extra", func() error { return tc.extraCheckErr })
+			if tc.started {
+				healthCheck.StartMonitoring()
+			}
+			now := time.Now()
+			healthCheck.lastSuccessfulRun = now.Add(tc.lastSuccess)
+			healthCheck.lastCloudProviderSuccess = now.Add(tc.cloudSuccess)
+			if tc.cloudFailure != 0 {
+				healthCheck.UpdateCloudProviderFailure(now.Add(tc.cloudFailure), fmt.Errorf("cloud provider error"))
+			}
+
+			w := httptest.NewRecorder()
+			healthCheck.ServeReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+			assert.Equal(t, tc.wantCode, w.Code)
+			for _, want := range tc.wantBodyContain {
+				assert.Contains(t, w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestServeLivez(t *testing.T) {
+	timeout := time.Second
+	healthCheck := NewHealthCheck(timeout, timeout)
+	healthCheck.StartMonitoring()
+
+	w := httptest.NewRecorder()
+	healthCheck.ServeLivez(w, httptest.NewRequest("GET", "/livez", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "[+]activity ok\n[+]successful-run ok\nlivez check passed\n", w.Body.String())
+
+	healthCheck.lastSuccessfulRun = time.Now().Add(-2 * timeout)
+	w = httptest.NewRecorder()
+	healthCheck.ServeLivez(w, httptest.NewRequest("GET", "/livez", nil))
+	assert.Equal(t, 500, w.Code)
+	assert.Contains(t, w.Body.String(), "[+]activity ok\n[-]successful-run failed: last successful run 2s ago, more than 1s\n")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actuationhooks
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	scaledownstatus "k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+)
+
+// ActuationHooks is called around the actuation of scale-up and scale-down decisions.
+type ActuationHooks interface {
+	// BeforeScaleUp is called before executing the scale-up plan. A non-nil error vetoes the scale-up.
+	BeforeScaleUp(ctx *context.AutoscalingContext, scaleUpInfos []nodegroupset.ScaleUpInfo) error
+	// AfterScaleUp is called after executing an allowed scale-up plan with the node groups that failed
+	// to scale up and the error, if any.
+	AfterScaleUp(ctx *context.AutoscalingContext, scaleUpInfos []nodegroupset.ScaleUpInfo, failedNodeGroups []cloudprovider.NodeGroup, err error)
+	// BeforeScaleDown is called before deleting the empty nodes and draining the others.
+	// A non-nil error vetoes the scale-down.
+	BeforeScaleDown(ctx *context.AutoscalingContext, empty, needDrain []*apiv1.Node) error
+	// AfterScaleDown is called with the results of the node deletions that completed since the previous call.
+	AfterScaleDown(ctx *context.AutoscalingContext, nodeDeleteResults map[string]scaledownstatus.NodeDeleteResult)
+	// CleanUp cleans up processor's internal structures.
+	CleanUp()
+}
+
+// NoOpActuationHooks allows every scale-up and scale-down.
+type NoOpActuationHooks struct{}
+
+// NewDefaultActuationHooks returns the default ActuationHooks.
+func NewDefaultActuationHooks() ActuationHooks {
+	return &NoOpActuationHooks{}
+}
+
+// BeforeScaleUp allows the scale-up.
+func (*NoOpActuationHooks) BeforeScaleUp(*context.AutoscalingContext, []nodegroupset.ScaleUpInfo) error {
+	return nil
+}
+
+// AfterScaleUp does nothing.
+func (*NoOpActuationHooks) AfterScaleUp(*context.AutoscalingContext, []nodegroupset.ScaleUpInfo, []cloudprovider.NodeGroup, error) {
+}
+
+// BeforeScaleDown allows the scale-down.
+func (*NoOpActuationHooks) BeforeScaleDown(*context.AutoscalingContext, []*apiv1.Node, []*apiv1.Node) error {
+	return nil
+}
+
+// AfterScaleDown does nothing.
+func (*NoOpActuationHooks) AfterScaleDown(*context.AutoscalingContext, map[string]scaledownstatus.NodeDeleteResult) {
+}
+
+// CleanUp does nothing.
+func (*NoOpActuationHooks) CleanUp() {}
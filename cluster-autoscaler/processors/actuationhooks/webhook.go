@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actuationhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	scaledownstatus "k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	klog "k8s.io/klog/v2"
+)
+
+// Phase is the phase of the actuation the webhook is called in.
+type Phase string
+
+const (
+	// PreScaleUp - the webhook is called before a scale-up and may veto or delay it.
+	PreScaleUp Phase = "PreScaleUp"
+	// PostScaleUp - the webhook is called after a scale-up.
+	PostScaleUp Phase = "PostScaleUp"
+	// PreScaleDown - the webhook is called before a scale-down and may veto or delay it.
+	PreScaleDown Phase = "PreScaleDown"
+	// PostScaleDown - the webhook is called after node deletions completed.
+	PostScaleDown Phase = "PostScaleDown"
+)
+
+// FailurePolicy defines how webhook call failures in the pre phases are handled.
+type FailurePolicy string
+
+const (
+	// FailurePolicyIgnore allows the actuation if the webhook can't be called.
+	FailurePolicyIgnore FailurePolicy = "Ignore"
+	// FailurePolicyFail vetoes the actuation if the webhook can't be called.
+	FailurePolicyFail FailurePolicy = "Fail"
+)
+
+// Review is the body of the requests sent to the webhook.
+type Review struct {
+	Phase Phase `json:"phase"`
+	// ScaleUps are the node group scale-ups of the PreScaleUp and PostScaleUp phases.
+	ScaleUps []NodeGroupScaleUp `json:"scaleUps,omitempty"`
+	// Nodes are the nodes of the PreScaleDown and PostScaleDown phases.
+	Nodes []NodeScaleDown `json:"nodes,omitempty"`
+	// Error is the error of the actuation in the post phases.
+	Error string `json:"error,omitempty"`
+}
+
+// NodeGroupScaleUp is a scale-up of a single node group.
+type NodeGroupScaleUp struct {
+	NodeGroup   string `json:"nodeGroup"`
+	CurrentSize int    `json:"currentSize"`
+	NewSize     int    `json:"newSize"`
+	Failed      bool   `json:"failed,omitempty"`
+}
+
+// NodeScaleDown is the scale-down of a single node.
+type NodeScaleDown struct {
+	Name      string `json:"name"`
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	Drain     bool   `json:"drain,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Response is the body of the responses expected from the webhook in the pre phases.
+// Responses in the post phases are ignored.
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	// RetryAfterSeconds delays the actuation: if set in a response that doesn't allow it,
+	// the webhook isn't called again in the same phase and the actuation is vetoed until then.
+	RetryAfterSeconds int `json:"retryAfterSeconds,omitempty"`
+}
+
+// WebhookActuationHooks calls an HTTP webhook around the actuation of scaling decisions.
+type WebhookActuationHooks struct {
+	url           string
+	client        *http.Client
+	failurePolicy FailurePolicy
+	delayedUntil  map[Phase]time.Time
+	now           func() time.Time
+}
+
+// NewWebhookActuationHooks returns WebhookActuationHooks posting a Review to the given URL.
+func NewWebhookActuationHooks(url string, timeout time.Duration, failurePolicy FailurePolicy) (*WebhookActuationHooks, error) {
+	if failurePolicy != FailurePolicyIgnore && failurePolicy != FailurePolicyFail {
+		return nil, fmt.Errorf("unknown actuation webhook failure policy %q, must be %s or %s", failurePolicy, FailurePolicyIgnore, FailurePolicyFail)
+	}
+	return &WebhookActuationHooks{
+		url:           url,
+		client:        &http.Client{Timeout: timeout},
+		failurePolicy: failurePolicy,
+		delayedUntil:  make(map[Phase]time.Time),
+		now:           time.Now,
+	}, nil
+}
+
+// BeforeScaleUp asks the webhook whether the scale-up is allowed.
+func (w *WebhookActuationHooks) BeforeScaleUp(_ *context.AutoscalingContext, scaleUpInfos []nodegroupset.ScaleUpInfo) error {
+	return w.review(Review{Phase: PreScaleUp, ScaleUps: nodeGroupScaleUps(scaleUpInfos, nil)})
+}
+
+// AfterScaleUp notifies the webhook about the scale-up.
+func (w *WebhookActuationHooks) AfterScaleUp(_ *context.AutoscalingContext, scaleUpInfos []nodegroupset.ScaleUpInfo, failedNodeGroups []cloudprovider.NodeGroup, err error) {
+	review := Review{Phase: PostScaleUp, ScaleUps: nodeGroupScaleUps(scaleUpInfos, failedNodeGroups)}
+	if err != nil {
+		review.Error = err.Error()
+	}
+	w.notify(review)
+}
+
+// BeforeScaleDown asks the webhook whether the scale-down is allowed.
+func (w *WebhookActuationHooks) BeforeScaleDown(ctx *context.AutoscalingContext, empty, needDrain []*apiv1.Node) error {
+	review := Review{Phase: PreScaleDown}
+	for _, node := range empty {
+		review.Nodes = append(review.Nodes, NodeScaleDown{Name: node.Name, NodeGroup: nodeGroupId(ctx, node)})
+	}
+	for _, node := range needDrain {
+		review.Nodes = append(review.Nodes, NodeScaleDown{Name: node.Name, NodeGroup: nodeGroupId(ctx, node), Drain: true})
+	}
+	return w.review(review)
+}
+
+// AfterScaleDown notifies the webhook about the completed node deletions.
+func (w *WebhookActuationHooks) AfterScaleDown(_ *context.AutoscalingContext, nodeDeleteResults map[string]scaledownstatus.NodeDeleteResult) {
+	if len(nodeDeleteResults) == 0 {
+		return
+	}
+	review := Review{Phase: PostScaleDown}
+	for name, result := range nodeDeleteResults {
+		node := NodeScaleDown{Name: name}
+		if result.Err != nil {
+			node.Error = result.Err.Error()
+		}
+		review.Nodes = append(review.Nodes, node)
+	}
+	sort.Slice(review.Nodes, func(i, j int) bool { return review.Nodes[i].Name < review.Nodes[j].Name })
+	w.notify(review)
+}
+
+// CleanUp cleans up processor's internal structures.
+func (w *WebhookActuationHooks) CleanUp() {
+}
+
+func (w *WebhookActuationHooks) review(review Review) error {
+	if until, found := w.delayedUntil[review.Phase]; found {
+		if w.now().Before(until) {
+			return fmt.Errorf("%s delayed by actuation webhook until %v", review.Phase, until)
+		}
+		delete(w.delayedUntil, review.Phase)
+	}
+	response, err := w.call(review)
+	if err != nil {
+		if w.failurePolicy == FailurePolicyFail {
+			return fmt.Errorf("failed to call actuation webhook in %s phase: %v", review.Phase, err)
+		}
+		klog.Warningf("Failed to call actuation webhook in %s phase, ignoring: %v", review.Phase, err)
+		return nil
+	}
+	if response.Allowed {
+		return nil
+	}
+	if response.RetryAfterSeconds > 0 {
+		w.delayedUntil[review.Phase] = w.now().Add(time.Duration(response.RetryAfterSeconds) * time.Second)
+	}
+	return fmt.Errorf("%s vetoed by actuation webhook: %s", review.Phase, response.Reason)
+}
+
+func (w *WebhookActuationHooks) notify(review Review) {
+	if _, err := w.call(review); err != nil {
+		klog.Warningf("Failed to call actuation webhook in %s phase: %v", review.Phase, err)
+	}
+}
+
+func (w *WebhookActuationHooks) call(review Review) (*Response, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, respBody)
+	}
+	var response Response
+	if review.Phase == PreScaleUp || review.Phase == PreScaleDown {
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return nil, fmt.Errorf("can't parse response: %v", err)
+		}
+	}
+	return &response, nil
+}
+
+func nodeGroupScaleUps(scaleUpInfos []nodegroupset.ScaleUpInfo, failedNodeGroups []cloudprovider.NodeGroup) []NodeGroupScaleUp {
+	failed := make(map[string]bool)
+	for _, ng := range failedNodeGroups {
+		failed[ng.Id()] = true
+	}
+	var result []NodeGroupScaleUp
+	for _, info := range scaleUpInfos {
+		result = append(result, NodeGroupScaleUp{
+			NodeGroup:   info.Group.Id(),
+			CurrentSize: info.CurrentSize,
+			NewSize:     info.NewSize,
+			Failed:      failed[info.Group.Id()],
+		})
+	}
+	return result
+}
+
+func nodeGroupId(ctx *context.AutoscalingContext, node *apiv1.Node) string {
+	nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+	if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+		return ""
+	}
+	return nodeGroup.Id()
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actuationhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	scaledownstatus "k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type fakeWebhook struct {
+	reviews   []Review
+	responses []Response
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.reviews = append(f.reviews, review)
+	response := Response{Allowed: true}
+	if len(f.responses) > 0 {
+		response, f.responses = f.responses[0], f.responses[1:]
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestWebhookScaleUp(t *testing.T) {
+	webhook := &fakeWebhook{responses: []Response{
+		{Allowed: true},
+		// Responses in the post phases are ignored.
+		{},
+		{Allowed: false, Reason: "change freeze", RetryAfterSeconds: 60},
+	}}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	ng1 := provider.GetNodeGroup("ng1")
+	scaleUpInfos := []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 1, NewSize: 3, MaxSize: 10}}
+	ctx := &context.AutoscalingContext{CloudProvider: provider}
+
+	hooks, err := NewWebhookActuationHooks(server.URL, time.Second, FailurePolicyIgnore)
+	assert.NoError(t, err)
+	now := time.Now()
+	hooks.now = func() time.Time { return now }
+
+	assert.NoError(t, hooks.BeforeScaleUp(ctx, scaleUpInfos))
+	hooks.AfterScaleUp(ctx, scaleUpInfos, []cloudprovider.NodeGroup{ng1}, fmt.Errorf("quota exceeded"))
+	assert.EqualError(t, hooks.BeforeScaleUp(ctx, scaleUpInfos), "PreScaleUp vetoed by actuation webhook: change freeze")
+
+	// Delayed scale-ups are vetoed without calling the webhook.
+	now = now.Add(30 * time.Second)
+	assert.Error(t, hooks.BeforeScaleUp(ctx, scaleUpInfos))
+	assert.Equal(t, 3, len(webhook.reviews))
+	now = now.Add(time.Minute)
+	assert.NoError(t, hooks.BeforeScaleUp(ctx, scaleUpInfos))
+	assert.Equal(t, 4, len(webhook.reviews))
+
+	assert.Equal(t, Review{Phase: PreScaleUp, ScaleUps: []NodeGroupScaleUp{{NodeGroup: "ng1", CurrentSize: 1, NewSize: 3}}}, webhook.reviews[0])
+	assert.Equal(t, Review{
+		Phase:    PostScaleUp,
+		ScaleUps: []NodeGroupScaleUp{{NodeGroup: "ng1", CurrentSize: 1, NewSize: 3, Failed: true}},
+		Error:    "quota exceeded",
+	}, webhook.reviews[1])
+}
+
+func TestWebhookScaleDown(t *testing.T) {
+	webhook := &fakeWebhook{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 2)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng1", n2)
+	ctx := &context.AutoscalingContext{CloudProvider: provider}
+
+	hooks, err := NewWebhookActuationHooks(server.URL, time.Second, FailurePolicyIgnore)
+	assert.NoError(t, err)
+	assert.NoError(t, hooks.BeforeScaleDown(ctx, []*apiv1.Node{n1}, []*apiv1.Node{n2}))
+	hooks.AfterScaleDown(ctx, nil)
+	hooks.AfterScaleDown(ctx, map[string]scaledownstatus.NodeDeleteResult{
+		"n2": {ResultType: scaledownstatus.NodeDeleteErrorFailedToEvictPods, Err: fmt.Errorf("eviction failed")},
+		"n1": {ResultType: scaledownstatus.NodeDeleteOk},
+	})
+
+	assert.Equal(t, []Review{
+		{Phase: PreScaleDown, Nodes: []NodeScaleDown{{Name: "n1", NodeGroup: "ng1"}, {Name: "n2", NodeGroup: "ng1", Drain: true}}},
+		{Phase: PostScaleDown, Nodes: []NodeScaleDown{{Name: "n1"}, {Name: "n2", Error: "eviction failed"}}},
+	}, webhook.reviews)
+}
+
+func TestWebhookFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	ctx := &context.AutoscalingContext{CloudProvider: testprovider.NewTestCloudProvider(nil, nil)}
+
+	ignore, err := NewWebhookActuationHooks(server.URL, time.Second, FailurePolicyIgnore)
+	assert.NoError(t, err)
+	assert.NoError(t, ignore.BeforeScaleDown(ctx, nil, nil))
+
+	fail, err := NewWebhookActuationHooks(server.URL, time.Second, FailurePolicyFail)
+	assert.NoError(t, err)
+	assert.Error(t, fail.BeforeScaleDown(ctx, nil, nil))
+
+	_, err = NewWebhookActuationHooks(server.URL, time.Second, "Retry")
+	assert.Error(t, err)
+}
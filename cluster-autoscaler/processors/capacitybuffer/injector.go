@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/klog/v2"
+)
+
+// CapacityBufferPodsInjector creates in-memory pods from CapacityBuffers and injects them to unschedulable pods list.
+// It has to run before the pods schedulable on the existing nodes are filtered out, so that only the
+// units of the buffers that don't fit trigger a scale-up.
+type CapacityBufferPodsInjector struct {
+	client *capacitybuffer.CapacityBufferClient
+}
+
+// NewCapacityBufferPodsInjector creates a CapacityBuffer pods injector processor.
+func NewCapacityBufferPodsInjector(client *capacitybuffer.CapacityBufferClient) pods.PodListProcessor {
+	return &CapacityBufferPodsInjector{client: client}
+}
+
+// Process injects the pods of all CapacityBuffers to unschedulable pods list.
+func (p *CapacityBufferPodsInjector) Process(
+	_ *context.AutoscalingContext,
+	unschedulablePods []*apiv1.Pod,
+) ([]*apiv1.Pod, error) {
+	cbs, err := p.client.CapacityBuffers()
+	if err != nil {
+		// Pending pods can still be helped without the buffers.
+		klog.Errorf("Failed to list CapacityBuffers: %v", err)
+		return unschedulablePods, nil
+	}
+	for _, cb := range cbs {
+		unschedulablePods = append(unschedulablePods, capacitybuffer.PodsForCapacityBuffer(cb)...)
+	}
+	return unschedulablePods, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *CapacityBufferPodsInjector) CleanUp() {}
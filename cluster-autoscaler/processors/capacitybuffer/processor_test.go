@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/package capacitybuffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+	"k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/podlistprocessor"
+	"k8s.io/autoscaler/cluster-autoscaler/debuggingsnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+	clock "k8s.io/utils/clock/testing"
+)
+
+func TestCapacityBufferProcessors(t *testing.T) {
+	now := time.Now()
+	cb := &v1alpha1.CapacityBuffer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cb", UID: "cb-uid", Generation: 1},
+		Spec: v1alpha1.CapacityBufferSpec{
+			Replicas:  3,
+			Resources: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2")},
+		},
+	}
+	client, dynamicClient := capacitybuffer.NewFakeCapacityBufferClient(t, cb)
+	statusProcessor := &CapacityBufferStatusProcessor{client: client, clock: clock.NewFakePassiveClock(now)}
+	predicateChecker, err := predicatechecker.NewTestPredicateChecker()
+	assert.NoError(t, err)
+	processor := pods.NewCombinedPodListProcessor([]pods.PodListProcessor{
+		NewCapacityBufferPodsInjector(client),
+		podlistprocessor.NewFilterOutSchedulablePodListProcessor(predicateChecker),
+		statusProcessor,
+	})
+
+	// One unit of the buffer fits on the existing node and one on the upcoming node.
+	snapshot := clustersnapshot.NewBasicClusterSnapshot()
+	assert.NoError(t, snapshot.AddNode(BuildTestNode("n1", 3000, 10*units.GiB)))
+	upcoming := BuildTestNode("u1", 3000, 10*units.GiB)
+	upcoming.Annotations = map[string]string{upcomingNodeAnnotation: "true"}
+	assert.NoError(t, snapshot.AddNode(upcoming))
+	ctx := &context.AutoscalingContext{
+		ClusterSnapshot:      snapshot,
+		DebuggingSnapshotter: debuggingsnapshot.NewDebuggingSnapshotter(false),
+	}
+	pending := BuildTestPod("pending", 500, 0)
+	unschedulablePods, err := processor.Process(ctx, []*apiv1.Pod{pending})
+	assert.NoError(t, err)
+	assert.Len(t, unschedulablePods, 1)
+	for _, pod := range unschedulablePods {
+		assert.Equal(t, "cb", pod.Annotations[capacitybuffer.CapacityBufferPodAnnotationKey])
+	}
+
+	got := capacitybuffer.GetCapacityBuffer(t, dynamicClient, "ns", "cb")
+	assert.Equal(t, int32(1), got.Status.ProvisionedReplicas)
+	condition := apimeta.FindStatusCondition(got.Status.Conditions, v1alpha1.Provisioned)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, NotProvisionedReason, condition.Reason)
+		assert.Equal(t, "1 of 3 units of the buffer fit on the nodes of the cluster", condition.Message)
+		assert.Equal(t, int64(1), condition.ObservedGeneration)
+	}
+
+	// The status isn't updated if it didn't change.
+	client, dynamicClient = capacitybuffer.NewFakeCapacityBufferClient(t, got)
+	statusProcessor.client = client
+	_, err = statusProcessor.Process(ctx, nil)
+	assert.NoError(t, err)
+	for _, action := range dynamicClient.Actions() {
+		assert.NotEqual(t, "update", action.GetVerb())
+	}
+}
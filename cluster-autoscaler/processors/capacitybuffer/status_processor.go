@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/apis/capacitybuffer/autoscaling.x-k8s.io/v1alpha1"
+	"k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// upcomingNodeAnnotation is set on the upcoming nodes injected into the cluster snapshot.
+	upcomingNodeAnnotation = "cluster-autoscaler.k8s.io/upcoming-node"
+	// ProvisionedReason is the reason of the Provisioned condition if all the units of the buffer fit.
+	ProvisionedReason = "Provisioned"
+	// NotProvisionedReason is the reason of the Provisioned condition if some units of the buffer don't fit.
+	NotProvisionedReason = "NotProvisioned"
+)
+
+// CapacityBufferStatusProcessor reports how much of the CapacityBuffers is provisioned in their status.
+// It has to run after the pods schedulable on the existing nodes are filtered out, which adds the
+// pods of the buffers that fit to the cluster snapshot.
+type CapacityBufferStatusProcessor struct {
+	client *capacitybuffer.CapacityBufferClient
+	clock  clock.PassiveClock
+}
+
+// NewCapacityBufferStatusProcessor creates a CapacityBuffer status processor.
+func NewCapacityBufferStatusProcessor(client *capacitybuffer.CapacityBufferClient) pods.PodListProcessor {
+	return &CapacityBufferStatusProcessor{client: client, clock: clock.RealClock{}}
+}
+
+// Process counts the pods of the CapacityBuffers on the nodes of the cluster snapshot and updates
+// the status of the buffers. The unschedulable pods are returned unchanged.
+func (p *CapacityBufferStatusProcessor) Process(
+	context *context.AutoscalingContext,
+	unschedulablePods []*apiv1.Pod,
+) ([]*apiv1.Pod, error) {
+	cbs, err := p.client.CapacityBuffers()
+	if err != nil {
+		klog.Errorf("Failed to list CapacityBuffers: %v", err)
+		return unschedulablePods, nil
+	}
+	if len(cbs) == 0 {
+		return unschedulablePods, nil
+	}
+	nodeInfos, err := context.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("Failed to list nodes from cluster snapshot: %v", err)
+		return unschedulablePods, nil
+	}
+	provisioned := make(map[string]int32)
+	for _, nodeInfo := range nodeInfos {
+		// Capacity that is still being provisioned doesn't count.
+		if nodeInfo.Node().Annotations[upcomingNodeAnnotation] == "true" {
+			continue
+		}
+		for _, podInfo := range nodeInfo.Pods {
+			if name, found := podInfo.Pod.Annotations[capacitybuffer.CapacityBufferPodAnnotationKey]; found {
+				provisioned[podInfo.Pod.Namespace+"/"+name]++
+			}
+		}
+	}
+	for _, cb := range cbs {
+		updated := cb.DeepCopy()
+		updated.Status.ProvisionedReplicas = provisioned[cb.Namespace+"/"+cb.Name]
+		condition := metav1.Condition{
+			Type:               v1alpha1.Provisioned,
+			Status:             metav1.ConditionTrue,
+			Reason:             ProvisionedReason,
+			Message:            "All units of the buffer fit on the nodes of the cluster",
+			ObservedGeneration: cb.Generation,
+			LastTransitionTime: metav1.NewTime(p.clock.Now()),
+		}
+		if updated.Status.ProvisionedReplicas < cb.Spec.Replicas {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = NotProvisionedReason
+			condition.Message = fmt.Sprintf("%d of %d units of the buffer fit on the nodes of the cluster", updated.Status.ProvisionedReplicas, cb.Spec.Replicas)
+		}
+		apimeta.SetStatusCondition(&updated.Status.Conditions, condition)
+		if equality.Semantic.DeepEqual(cb.Status, updated.Status) {
+			continue
+		}
+		if _, err := p.client.UpdateCapacityBufferStatus(updated); err != nil {
+			klog.Errorf("Failed to update status of CapacityBuffer %s/%s: %v", cb.Namespace, cb.Name, err)
+		}
+	}
+	return unschedulablePods, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *CapacityBufferStatusProcessor) CleanUp() {}
@@ -37,10 +37,23 @@ type NodeGroupConfigProcessor interface {
 	GetMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) (time.Duration, error)
 	// GetIgnoreDaemonSetsUtilization returns IgnoreDaemonSetsUtilization value that should be used for a given NodeGroup.
 	GetIgnoreDaemonSetsUtilization(nodeGroup cloudprovider.NodeGroup) (bool, error)
+	// GetScaleDownDelayAfterAdd returns ScaleDownDelayAfterAdd value that should be used for a given NodeGroup.
+	GetScaleDownDelayAfterAdd(nodeGroup cloudprovider.NodeGroup) (time.Duration, error)
+	// GetMaxNewNodesPerMinute returns MaxNewNodesPerMinute value that should be used for a given NodeGroup.
+	GetMaxNewNodesPerMinute(nodeGroup cloudprovider.NodeGroup) (int, error)
+	// GetZeroOrMaxNodeScaling returns ZeroOrMaxNodeScaling value that should be used for a given NodeGroup.
+	GetZeroOrMaxNodeScaling(nodeGroup cloudprovider.NodeGroup) (bool, error)
 	// CleanUp cleans up processor's internal structures.
 	CleanUp()
 }
 
+// NodeGroupDefaultsUpdater is implemented by NodeGroupConfigProcessors whose default
+// config can be changed while the autoscaler is running.
+type NodeGroupDefaultsUpdater interface {
+	// SetNodeGroupDefaults replaces the config used for NodeGroups that don't provide their own.
+	SetNodeGroupDefaults(nodeGroupDefaults config.NodeGroupAutoscalingOptions)
+}
+
 // DelegatingNodeGroupConfigProcessor calls NodeGroup.GetOptions to get config
 // for each NodeGroup. If NodeGroup doesn't return a value default config is
 // used instead.
@@ -120,6 +133,47 @@ func (p *DelegatingNodeGroupConfigProcessor) GetIgnoreDaemonSetsUtilization(node
 	return ngConfig.IgnoreDaemonSetsUtilization, nil
 }
 
+// GetScaleDownDelayAfterAdd returns ScaleDownDelayAfterAdd value that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetScaleDownDelayAfterAdd(nodeGroup cloudprovider.NodeGroup) (time.Duration, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return time.Duration(0), err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.ScaleDownDelayAfterAdd, nil
+	}
+	return ngConfig.ScaleDownDelayAfterAdd, nil
+}
+
+// GetMaxNewNodesPerMinute returns MaxNewNodesPerMinute value that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetMaxNewNodesPerMinute(nodeGroup cloudprovider.NodeGroup) (int, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return 0, err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.MaxNewNodesPerMinute, nil
+	}
+	return ngConfig.MaxNewNodesPerMinute, nil
+}
+
+// GetZeroOrMaxNodeScaling returns ZeroOrMaxNodeScaling value that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetZeroOrMaxNodeScaling(nodeGroup cloudprovider.NodeGroup) (bool, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return false, err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.ZeroOrMaxNodeScaling, nil
+	}
+	return ngConfig.ZeroOrMaxNodeScaling, nil
+}
+
+// SetNodeGroupDefaults replaces the config used for NodeGroups that don't provide their own.
+func (p *DelegatingNodeGroupConfigProcessor) SetNodeGroupDefaults(nodeGroupDefaults config.NodeGroupAutoscalingOptions) {
+	p.nodeGroupDefaults = nodeGroupDefaults
+}
+
 // CleanUp cleans up processor's internal structures.
 func (p *DelegatingNodeGroupConfigProcessor) CleanUp() {
 }
@@ -48,6 +48,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		ScaleDownUtilizationThreshold:    0.5,
 		MaxNodeProvisionTime:             15 * time.Minute,
 		IgnoreDaemonSetsUtilization:      true,
+		ScaleDownDelayAfterAdd:           5 * time.Minute,
+		MaxNewNodesPerMinute:             20,
+		ZeroOrMaxNodeScaling:             true,
 	}
 	ngOpts := &config.NodeGroupAutoscalingOptions{
 		ScaleDownUnneededTime:            10 * time.Minute,
@@ -56,6 +59,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		ScaleDownUtilizationThreshold:    0.75,
 		MaxNodeProvisionTime:             60 * time.Minute,
 		IgnoreDaemonSetsUtilization:      false,
+		ScaleDownDelayAfterAdd:           2 * time.Minute,
+		MaxNewNodesPerMinute:             5,
+		ZeroOrMaxNodeScaling:             false,
 	}
 
 	testUnneededTime := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
@@ -108,6 +114,36 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		}
 		assert.Equal(t, res, results[w])
 	}
+	testScaleDownDelayAfterAdd := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetScaleDownDelayAfterAdd(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]time.Duration{
+			NIL:    time.Duration(0),
+			GLOBAL: 5 * time.Minute,
+			NG:     2 * time.Minute,
+		}
+		assert.Equal(t, res, results[w])
+	}
+	testMaxNewNodesPerMinute := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetMaxNewNodesPerMinute(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]int{
+			NIL:    0,
+			GLOBAL: 20,
+			NG:     5,
+		}
+		assert.Equal(t, res, results[w])
+	}
+	testZeroOrMaxNodeScaling := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetZeroOrMaxNodeScaling(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]bool{
+			NIL:    false,
+			GLOBAL: true,
+			NG:     false,
+		}
+		assert.Equal(t, res, results[w])
+	}
 
 	// for IgnoreDaemonSetsUtilization
 	testIgnoreDSUtilization := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
@@ -128,6 +164,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		"ScaleDownGpuUtilizationThreshold": testGpuThreshold,
 		"MaxNodeProvisionTime":             testMaxNodeProvisionTime,
 		"IgnoreDaemonSetsUtilization":      testIgnoreDSUtilization,
+		"ScaleDownDelayAfterAdd":           testScaleDownDelayAfterAdd,
+		"MaxNewNodesPerMinute":             testMaxNewNodesPerMinute,
+		"ZeroOrMaxNodeScaling":             testZeroOrMaxNodeScaling,
 		"MultipleOptions": func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
 			testUnneededTime(t, p, ng, w, we)
 			testUnreadyTime(t, p, ng, w, we)
@@ -135,6 +174,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 			testGpuThreshold(t, p, ng, w, we)
 			testMaxNodeProvisionTime(t, p, ng, w, we)
 			testIgnoreDSUtilization(t, p, ng, w, we)
+			testScaleDownDelayAfterAdd(t, p, ng, w, we)
+			testMaxNewNodesPerMinute(t, p, ng, w, we)
+			testZeroOrMaxNodeScaling(t, p, ng, w, we)
 		},
 		"RepeatingTheSameCallGivesConsistentResults": func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
 			testUnneededTime(t, p, ng, w, we)
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"sync"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+// GenericNodeInfoComparatorName is the name the generic NodeInfoComparator is registered under.
+const GenericNodeInfoComparatorName = "generic"
+
+// NodeInfoComparatorBuilder builds a NodeInfoComparator ignoring the given labels in addition
+// to its own ones and tolerating the given differences of resources.
+type NodeInfoComparatorBuilder func(extraIgnoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) NodeInfoComparator
+
+var (
+	nodeInfoComparatorBuildersLock sync.Mutex
+	nodeInfoComparatorBuilders     = map[string]NodeInfoComparatorBuilder{
+		GenericNodeInfoComparatorName:         CreateGenericNodeInfoComparator,
+		cloudprovider.AwsProviderName:         CreateAwsNodeInfoComparator,
+		cloudprovider.AzureProviderName:       CreateAzureNodeInfoComparator,
+		cloudprovider.GceProviderName:         CreateGceNodeInfoComparator,
+		cloudprovider.OracleCloudProviderName: CreateOciNodeInfoComparator,
+	}
+)
+
+// RegisterNodeInfoComparatorBuilder registers a NodeInfoComparatorBuilder under the given name,
+// replacing the one registered before if any. Cloud providers register their comparators under
+// their names, so that they're used by default with them.
+func RegisterNodeInfoComparatorBuilder(name string, builder NodeInfoComparatorBuilder) {
+	nodeInfoComparatorBuildersLock.Lock()
+	defer nodeInfoComparatorBuildersLock.Unlock()
+	nodeInfoComparatorBuilders[name] = builder
+}
+
+// GetNodeInfoComparatorBuilder returns the NodeInfoComparatorBuilder registered under the given name.
+func GetNodeInfoComparatorBuilder(name string) (NodeInfoComparatorBuilder, bool) {
+	nodeInfoComparatorBuildersLock.Lock()
+	defer nodeInfoComparatorBuildersLock.Unlock()
+	builder, found := nodeInfoComparatorBuilders[name]
+	return builder, found
+}
@@ -96,6 +96,26 @@ func CreateGenericNodeInfoComparator(extraIgnoredLabels []string, ratioOpts conf
 	}
 }
 
+// CreateCustomNodeInfoComparator returns a comparator that checks for node group similarity like
+// the generic one, additionally ignoring the given labels and resources.
+func CreateCustomNodeInfoComparator(ignoredLabels, ignoredResources []string, ratioOpts config.NodeGroupDifferenceRatios) NodeInfoComparator {
+	customIgnoredLabels := make(map[string]bool)
+	for k, v := range BasicIgnoredLabels {
+		customIgnoredLabels[k] = v
+	}
+	for _, k := range ignoredLabels {
+		customIgnoredLabels[k] = true
+	}
+	customIgnoredResources := make(map[apiv1.ResourceName]bool)
+	for _, r := range ignoredResources {
+		customIgnoredResources[apiv1.ResourceName(r)] = true
+	}
+
+	return func(n1, n2 *schedulerframework.NodeInfo) bool {
+		return isNodeInfoSimilar(n1, n2, customIgnoredLabels, customIgnoredResources, ratioOpts)
+	}
+}
+
 // IsCloudProviderNodeInfoSimilar returns true if two NodeInfos are similar enough to consider
 // that the NodeGroups they come from are part of the same NodeGroupSet. The criteria are
 // somewhat arbitrary, but generally we check if resources provided by both nodes
@@ -103,18 +123,30 @@ func CreateGenericNodeInfoComparator(extraIgnoredLabels []string, ratioOpts conf
 // is the same (except for a set of labels passed in to be ignored like hostname or zone).
 func IsCloudProviderNodeInfoSimilar(
 	n1, n2 *schedulerframework.NodeInfo, ignoredLabels map[string]bool, ratioOpts config.NodeGroupDifferenceRatios) bool {
+	return isNodeInfoSimilar(n1, n2, ignoredLabels, nil, ratioOpts)
+}
+
+func isNodeInfoSimilar(
+	n1, n2 *schedulerframework.NodeInfo, ignoredLabels map[string]bool, ignoredResources map[apiv1.ResourceName]bool, ratioOpts config.NodeGroupDifferenceRatios) bool {
 	capacity := make(map[apiv1.ResourceName][]resource.Quantity)
 	allocatable := make(map[apiv1.ResourceName][]resource.Quantity)
 	free := make(map[apiv1.ResourceName][]resource.Quantity)
 	nodes := []*schedulerframework.NodeInfo{n1, n2}
 	for _, node := range nodes {
 		for res, quantity := range node.Node().Status.Capacity {
-			capacity[res] = append(capacity[res], quantity)
+			if !ignoredResources[res] {
+				capacity[res] = append(capacity[res], quantity)
+			}
 		}
 		for res, quantity := range node.Node().Status.Allocatable {
-			allocatable[res] = append(allocatable[res], quantity)
+			if !ignoredResources[res] {
+				allocatable[res] = append(allocatable[res], quantity)
+			}
 		}
 		for res, quantity := range scheduler.ResourceToResourceList(node.Requested) {
+			if ignoredResources[res] {
+				continue
+			}
 			freeRes := node.Node().Status.Allocatable[res].DeepCopy()
 			freeRes.Sub(quantity)
 			free[res] = append(free[res], freeRes)
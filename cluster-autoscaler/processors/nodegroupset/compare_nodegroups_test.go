@@ -198,3 +198,45 @@ func TestNodesSimilarVariousLabels(t *testing.T) {
 	n2.ObjectMeta.Labels["example.com/ready"] = "false"
 	checkNodesSimilar(t, n1, n2, comparator, true)
 }
+
+func TestCustomNodeInfoComparator(t *testing.T) {
+	comparator := CreateCustomNodeInfoComparator([]string{"example.com/ready"}, []string{"example.com/dongle"}, config.NewDefaultNodeGroupDifferenceRatios())
+	n1 := BuildTestNode("node1", 1000, 2000)
+	n2 := BuildTestNode("node2", 1000, 2000)
+	checkNodesSimilar(t, n1, n2, comparator, true)
+
+	// Different custom labels should not matter
+	n1.ObjectMeta.Labels["example.com/ready"] = "true"
+	n2.ObjectMeta.Labels["example.com/ready"] = "false"
+	checkNodesSimilar(t, n1, n2, comparator, true)
+
+	// Different custom resources should not matter
+	n1.Status.Capacity["example.com/dongle"] = *resource.NewQuantity(1, resource.DecimalSI)
+	n1.Status.Allocatable["example.com/dongle"] = *resource.NewQuantity(1, resource.DecimalSI)
+	checkNodesSimilar(t, n1, n2, comparator, true)
+
+	// Other resources still matter
+	n1.Status.Capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	n1.Status.Allocatable[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	checkNodesSimilar(t, n1, n2, comparator, false)
+}
+
+func TestNodeInfoComparatorBuilders(t *testing.T) {
+	builder, found := GetNodeInfoComparatorBuilder(GenericNodeInfoComparatorName)
+	assert.True(t, found)
+	assert.NotNil(t, builder)
+
+	_, found = GetNodeInfoComparatorBuilder("custom")
+	assert.False(t, found)
+
+	RegisterNodeInfoComparatorBuilder("custom", func(extraIgnoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) NodeInfoComparator {
+		return CreateCustomNodeInfoComparator(extraIgnoredLabels, []string{"example.com/dongle"}, ratioOpts)
+	})
+	builder, found = GetNodeInfoComparatorBuilder("custom")
+	assert.True(t, found)
+	n1 := BuildTestNode("node1", 1000, 2000)
+	n1.Status.Capacity["example.com/dongle"] = *resource.NewQuantity(1, resource.DecimalSI)
+	n1.Status.Allocatable["example.com/dongle"] = *resource.NewQuantity(1, resource.DecimalSI)
+	n2 := BuildTestNode("node2", 1000, 2000)
+	checkNodesSimilar(t, n1, n2, builder(nil, config.NewDefaultNodeGroupDifferenceRatios()), true)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ociLaunchConfigHashLabel is the label the OCI cloud provider sets on template nodes to a digest of the image and user
+// data of the node group.
+const ociLaunchConfigHashLabel = "oci.oraclecloud.com/launch-config-hash"
+
+// ociNumaNodesPerSocketLabel is the label the OCI cloud provider sets on template nodes to the NUMA nodes per socket
+// setting of bare metal node groups.
+const ociNumaNodesPerSocketLabel = "oci.oraclecloud.com/numa-nodes-per-socket"
+
+// ociTemplateOnlyLabels are the labels only template nodes carry, so they are compared only if both nodes have them.
+var ociTemplateOnlyLabels = []string{ociLaunchConfigHashLabel, ociNumaNodesPerSocketLabel}
+
+// CreateOciNodeInfoComparator returns a comparator that checks if two nodes should be considered
+// part of the same NodeGroupSet. This is true if they match usual conditions checked by IsCloudProviderNodeInfoSimilar,
+// even if they have different OCI-specific labels, i.e. they are placed in different availability or fault domains.
+// Nodes launched from different images, with different user data or with different NUMA nodes per socket are never
+// similar. Only template nodes carry these labels, so they are compared only if both nodes have them.
+func CreateOciNodeInfoComparator(extraIgnoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) NodeInfoComparator {
+	ociIgnoredLabels := map[string]bool{
+		"instancepool-id_prefix":           true, // this is a label used by the OCI cloud provider to identify the instance pool.
+		"instancepool-id_suffix":           true, // this is a label used by the OCI cloud provider to identify the instance pool.
+		"instance-id_prefix":               true, // this is a label used by the OCI cloud provider to identify the instance.
+		"instance-id_suffix":               true, // this is a label used by the OCI cloud provider to identify the instance.
+		"oci.oraclecloud.com/fault-domain": true, // this is a label used by OKE to identify the fault domain of the node.
+		"displayName":                      true, // this is a label used by OKE to identify the instance name of the node.
+		"hostname":                         true, // this is a label used by OKE to identify the hostname of the node.
+		"internal_addr":                    true, // this is a label used by OKE to identify the private IP address of the node.
+		"topology.blockvolume.csi.oraclecloud.com/availability-domain": true, // this is a label used by the OCI Block Volume CSI driver as a target for Persistent Volume Node Affinity.
+	}
+	// These are compared separately below, as only template nodes have them.
+	for _, k := range ociTemplateOnlyLabels {
+		ociIgnoredLabels[k] = true
+	}
+
+	for k, v := range BasicIgnoredLabels {
+		ociIgnoredLabels[k] = v
+	}
+
+	for _, k := range extraIgnoredLabels {
+		ociIgnoredLabels[k] = true
+	}
+
+	return func(n1, n2 *schedulerframework.NodeInfo) bool {
+		for _, k := range ociTemplateOnlyLabels {
+			value1, ok1 := n1.Node().Labels[k]
+			value2, ok2 := n2.Node().Labels[k]
+			if ok1 && ok2 && value1 != value2 {
+				return false
+			}
+		}
+		return IsCloudProviderNodeInfoSimilar(n1, n2, ociIgnoredLabels, ratioOpts)
+	}
+}
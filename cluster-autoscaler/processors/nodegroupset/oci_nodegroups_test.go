@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestIsOciNodeInfoSimilar(t *testing.T) {
+	comparator := CreateOciNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{})
+	node1 := BuildTestNode("node1", 1000, 2000)
+	node2 := BuildTestNode("node2", 1000, 2000)
+
+	for _, label := range []string{
+		"instancepool-id_prefix",
+		"instancepool-id_suffix",
+		"instance-id_prefix",
+		"instance-id_suffix",
+		"oci.oraclecloud.com/fault-domain",
+		"displayName",
+		"hostname",
+		"internal_addr",
+		"topology.blockvolume.csi.oraclecloud.com/availability-domain",
+	} {
+		t.Run(label+" different values", func(t *testing.T) {
+			node1.ObjectMeta.Labels[label] = "foo"
+			node2.ObjectMeta.Labels[label] = "bar"
+			checkNodesSimilar(t, node1, node2, comparator, true)
+		})
+		t.Run(label+" one node labeled", func(t *testing.T) {
+			node1.ObjectMeta.Labels[label] = "foo"
+			delete(node2.ObjectMeta.Labels, label)
+			checkNodesSimilar(t, node1, node2, comparator, true)
+		})
+	}
+}
+
+func TestIsOciNodeInfoSimilarExtendedMemory(t *testing.T) {
+	comparator := CreateOciNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{})
+	// Same OCPUs, but one flexible shape is configured with extended memory.
+	standard := BuildTestNode("standard", 4000, 64*1024*1024*1024)
+	extended := BuildTestNode("extended", 4000, 256*1024*1024*1024)
+	checkNodesSimilar(t, standard, extended, comparator, false)
+}
+
+func TestIsOciNodeInfoSimilarLaunchConfigHash(t *testing.T) {
+	comparator := CreateOciNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{})
+	node1 := BuildTestNode("node1", 1000, 2000)
+	node2 := BuildTestNode("node2", 1000, 2000)
+
+	node1.ObjectMeta.Labels[ociLaunchConfigHashLabel] = "foo"
+	node2.ObjectMeta.Labels[ociLaunchConfigHashLabel] = "foo"
+	checkNodesSimilar(t, node1, node2, comparator, true)
+
+	node2.ObjectMeta.Labels[ociLaunchConfigHashLabel] = "bar"
+	checkNodesSimilar(t, node1, node2, comparator, false)
+
+	delete(node2.ObjectMeta.Labels, ociLaunchConfigHashLabel)
+	checkNodesSimilar(t, node1, node2, comparator, true)
+}
+
+func TestIsOciNodeInfoSimilarNumaNodesPerSocket(t *testing.T) {
+	comparator := CreateOciNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{})
+	node1 := BuildTestNode("node1", 1000, 2000)
+	node2 := BuildTestNode("node2", 1000, 2000)
+
+	node1.ObjectMeta.Labels[ociNumaNodesPerSocketLabel] = "NPS1"
+	node2.ObjectMeta.Labels[ociNumaNodesPerSocketLabel] = "NPS1"
+	checkNodesSimilar(t, node1, node2, comparator, true)
+
+	node2.ObjectMeta.Labels[ociNumaNodesPerSocketLabel] = "NPS4"
+	checkNodesSimilar(t, node1, node2, comparator, false)
+
+	delete(node2.ObjectMeta.Labels, ociNumaNodesPerSocketLabel)
+	checkNodesSimilar(t, node1, node2, comparator, true)
+}
+
+func TestFindSimilarNodeGroupsOciBasic(t *testing.T) {
+	context := &context.AutoscalingContext{}
+	ni1, ni2, ni3 := buildBasicNodeGroups(context)
+	processor := &BalancingNodeGroupSetProcessor{Comparator: CreateOciNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{})}
+	basicSimilarNodeGroupsTest(t, context, processor, ni1, ni2, ni3)
+}
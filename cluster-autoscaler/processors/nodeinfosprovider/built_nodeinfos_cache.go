@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/core/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// nodeInfoInputs are what a NodeInfo of a node group is built from. A NodeInfo built from
+// the same inputs in a previous loop can be reused instead of being built again.
+type nodeInfoInputs struct {
+	// node is the part of the node, or of the template node, that matters for scheduling. Fields that change
+	// all the time, like heartbeats, or that are random in templates, like the name, are left out.
+	node *apiv1.Node
+	// pods are the uids and resource versions of the pods of the node, empty for templates.
+	pods string
+	// templatePods are the pods of the template node, without their names, empty for nodes.
+	templatePods []*apiv1.Pod
+	// daemonSets are the names, uids and generations of the daemonsets, which determine the daemonset pods added.
+	daemonSets  string
+	taintConfig taints.TaintConfig
+}
+
+func (i nodeInfoInputs) equal(other nodeInfoInputs) bool {
+	return i.pods == other.pods &&
+		i.daemonSets == other.daemonSets &&
+		reflect.DeepEqual(i.taintConfig, other.taintConfig) &&
+		apiequality.Semantic.DeepEqual(i.node, other.node) &&
+		apiequality.Semantic.DeepEqual(i.templatePods, other.templatePods)
+}
+
+type builtNodeInfo struct {
+	nodeInfo *schedulerframework.NodeInfo
+	inputs   nodeInfoInputs
+}
+
+// builtNodeInfosCache keeps the NodeInfos of node groups across loops, so that they're only built
+// again when the node or template they're built from, the daemonsets or the taint config change.
+type builtNodeInfosCache struct {
+	items map[string]builtNodeInfo
+}
+
+func newBuiltNodeInfosCache() *builtNodeInfosCache {
+	return &builtNodeInfosCache{items: make(map[string]builtNodeInfo)}
+}
+
+// get returns a copy of the NodeInfo of the node group if it was built from the same inputs.
+func (c *builtNodeInfosCache) get(id string, inputs nodeInfoInputs) (*schedulerframework.NodeInfo, bool) {
+	item, found := c.items[id]
+	if !found || !item.inputs.equal(inputs) {
+		return nil, false
+	}
+	return utils.DeepCopyNodeInfo(item.nodeInfo), true
+}
+
+func (c *builtNodeInfosCache) put(id string, inputs nodeInfoInputs, nodeInfo *schedulerframework.NodeInfo) {
+	c.items[id] = builtNodeInfo{nodeInfo: utils.DeepCopyNodeInfo(nodeInfo), inputs: inputs}
+}
+
+// retain removes the NodeInfos of node groups that aren't in ids.
+func (c *builtNodeInfosCache) retain(ids map[string]bool) {
+	for id := range c.items {
+		if !ids[id] {
+			delete(c.items, id)
+		}
+	}
+}
+
+// nodeInputs returns the inputs of a NodeInfo built from the node. Pods without a resource version,
+// e.g. ones that weren't read from the API server, can't be told apart, so their NodeInfos aren't cached.
+func nodeInputs(node *apiv1.Node, pods []*apiv1.Pod, daemonSets string, taintConfig taints.TaintConfig) (nodeInfoInputs, bool) {
+	podVersions := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		if pod.ResourceVersion == "" {
+			return nodeInfoInputs{}, false
+		}
+		podVersions = append(podVersions, fmt.Sprintf("%s/%s", pod.UID, pod.ResourceVersion))
+	}
+	sort.Strings(podVersions)
+	schedulingNode := schedulingFields(node)
+	schedulingNode.Name = node.Name
+	return nodeInfoInputs{
+		node:        schedulingNode,
+		pods:        strings.Join(podVersions, ","),
+		daemonSets:  daemonSets,
+		taintConfig: taintConfig,
+	}, true
+}
+
+// templateInputs returns the inputs of a NodeInfo built from the template node and its pods. Cloud providers
+// give template nodes, and their pods, random names, so the names are left out.
+func templateInputs(template *schedulerframework.NodeInfo, daemonSets string, taintConfig taints.TaintConfig) nodeInfoInputs {
+	templatePods := make([]*apiv1.Pod, 0, len(template.Pods))
+	for _, podInfo := range template.Pods {
+		pod := podInfo.Pod
+		templatePods = append(templatePods, &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       pod.Namespace,
+				Labels:          pod.Labels,
+				Annotations:     pod.Annotations,
+				OwnerReferences: pod.OwnerReferences,
+			},
+			Spec: *pod.Spec.DeepCopy(),
+		})
+		templatePods[len(templatePods)-1].Spec.NodeName = ""
+	}
+	return nodeInfoInputs{
+		node:         schedulingFields(template.Node()),
+		templatePods: templatePods,
+		daemonSets:   daemonSets,
+		taintConfig:  taintConfig,
+	}
+}
+
+// schedulingFields returns a copy of the fields of the node that matter for scheduling, without its name and
+// hostname label.
+func schedulingFields(node *apiv1.Node) *apiv1.Node {
+	result := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      make(map[string]string, len(node.Labels)),
+			Annotations: node.Annotations,
+		},
+		Spec: apiv1.NodeSpec{
+			Taints:        node.Spec.Taints,
+			Unschedulable: node.Spec.Unschedulable,
+		},
+		Status: apiv1.NodeStatus{
+			Capacity:    node.Status.Capacity,
+			Allocatable: node.Status.Allocatable,
+		},
+	}
+	for k, v := range node.Labels {
+		if k != apiv1.LabelHostname {
+			result.Labels[k] = v
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		result.Status.Conditions = append(result.Status.Conditions, apiv1.NodeCondition{Type: condition.Type, Status: condition.Status})
+	}
+	return result.DeepCopy()
+}
+
+func daemonSetsFingerprint(daemonsets []*appsv1.DaemonSet) string {
+	versions := make([]string, 0, len(daemonsets))
+	for _, ds := range daemonsets {
+		versions = append(versions, fmt.Sprintf("%s/%s/%s/%d", ds.Namespace, ds.Name, ds.UID, ds.Generation))
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// InjectionConfigMapKey is the key of the injection rules in their ConfigMap.
+const InjectionConfigMapKey = "rules"
+
+// injectionRule adds extended resources and labels to the templates of the node groups
+// whose id matches NodeGroups, e.g.
+//
+//	nodeGroups: ".*-hugepages-.*"
+//	resources:
+//	  hugepages-1Gi: 8Gi
+//	labels:
+//	  hugepages: "true"
+type injectionRule struct {
+	NodeGroups string            `yaml:"nodeGroups"`
+	Resources  map[string]string `yaml:"resources"`
+	Labels     map[string]string `yaml:"labels"`
+}
+
+type injection struct {
+	nodeGroups *regexp.Regexp
+	resources  apiv1.ResourceList
+	labels     map[string]string
+}
+
+// InjectingNodeInfoProvider is a wrapper for a TemplateNodeInfoProvider that injects the extended
+// resources and labels configured in a ConfigMap into the templates of matching node groups.
+// Cloud providers rarely know about resources like hugepages or devices of device plugins, so
+// without them node groups with these resources can't be scaled up from zero.
+// Resources and labels already present in a template are kept.
+type InjectingNodeInfoProvider struct {
+	templateNodeInfoProvider TemplateNodeInfoProvider
+	configMapLister          v1lister.ConfigMapNamespaceLister
+	configMapName            string
+	// lastValue is the last injection configuration that was processed.
+	lastValue  string
+	injections []injection
+}
+
+// NewInjectingNodeInfoProvider returns InjectingNodeInfoProvider.
+func NewInjectingNodeInfoProvider(templateNodeInfoProvider TemplateNodeInfoProvider, configMapLister v1lister.ConfigMapNamespaceLister, configMapName string) *InjectingNodeInfoProvider {
+	return &InjectingNodeInfoProvider{
+		templateNodeInfoProvider: templateNodeInfoProvider,
+		configMapLister:          configMapLister,
+		configMapName:            configMapName,
+	}
+}
+
+// Process returns the nodeInfos set for this cluster.
+func (p *InjectingNodeInfoProvider) Process(ctx *context.AutoscalingContext, nodes []*apiv1.Node, daemonsets []*appsv1.DaemonSet, taintConfig taints.TaintConfig, currentTime time.Time) (map[string]*schedulerframework.NodeInfo, errors.AutoscalerError) {
+	nodeInfos, err := p.templateNodeInfoProvider.Process(ctx, nodes, daemonsets, taintConfig, currentTime)
+	if err != nil {
+		return nil, err
+	}
+	p.reloadConfigMap(ctx)
+	for id, nodeInfo := range nodeInfos {
+		for _, inj := range p.injections {
+			if inj.nodeGroups.MatchString(id) {
+				inject(nodeInfo, inj)
+			}
+		}
+	}
+	return nodeInfos, nil
+}
+
+// CleanUp cleans up processor's internal structures.
+func (p *InjectingNodeInfoProvider) CleanUp() {
+	p.templateNodeInfoProvider.CleanUp()
+}
+
+// reloadConfigMap parses the injection rules if they changed since the last call. Invalid rules
+// are ignored and the previous ones are kept.
+func (p *InjectingNodeInfoProvider) reloadConfigMap(ctx *context.AutoscalingContext) {
+	value := ""
+	cm, err := p.configMapLister.Get(p.configMapName)
+	if err != nil && !kube_errors.IsNotFound(err) {
+		klog.Warningf("Failed to get node template injection configmap %s: %v", p.configMapName, err)
+		return
+	}
+	if err == nil {
+		value = cm.Data[InjectionConfigMapKey]
+	}
+	if value == p.lastValue {
+		return
+	}
+	p.lastValue = value
+
+	injections, err := parseInjectionRules(value)
+	if err != nil {
+		msg := fmt.Sprintf("Wrong node template injection configuration in configmap %s: %v. Ignoring update.", p.configMapName, err)
+		if cm != nil && ctx.Recorder != nil {
+			ctx.Recorder.Event(cm, apiv1.EventTypeWarning, "NodeTemplateInjectionConfigMapInvalid", msg)
+		}
+		klog.Warning(msg)
+		return
+	}
+	p.injections = injections
+	klog.V(4).Infof("Loaded %d node template injection rules from configmap %s", len(injections), p.configMapName)
+}
+
+func parseInjectionRules(value string) ([]injection, error) {
+	var rules []injectionRule
+	if err := yaml.UnmarshalStrict([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("can't parse YAML: %v", err)
+	}
+	injections := make([]injection, 0, len(rules))
+	for _, rule := range rules {
+		nodeGroups, err := regexp.Compile(rule.NodeGroups)
+		if err != nil {
+			return nil, fmt.Errorf("can't compile node groups regexp %s: %v", rule.NodeGroups, err)
+		}
+		resources := apiv1.ResourceList{}
+		for name, value := range rule.Resources {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse quantity %s of resource %s: %v", value, name, err)
+			}
+			resources[apiv1.ResourceName(name)] = quantity
+		}
+		injections = append(injections, injection{nodeGroups: nodeGroups, resources: resources, labels: rule.Labels})
+	}
+	return injections, nil
+}
+
+func inject(nodeInfo *schedulerframework.NodeInfo, inj injection) {
+	node := nodeInfo.Node()
+	if node == nil {
+		return
+	}
+	if node.Status.Capacity == nil {
+		node.Status.Capacity = apiv1.ResourceList{}
+	}
+	if node.Status.Allocatable == nil {
+		node.Status.Allocatable = apiv1.ResourceList{}
+	}
+	for name, quantity := range inj.resources {
+		if _, ok := node.Status.Capacity[name]; !ok {
+			node.Status.Capacity[name] = quantity.DeepCopy()
+		}
+		if _, ok := node.Status.Allocatable[name]; !ok {
+			node.Status.Allocatable[name] = quantity.DeepCopy()
+		}
+	}
+	if len(inj.labels) > 0 && node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	for key, val := range inj.labels {
+		if _, ok := node.Labels[key]; !ok {
+			node.Labels[key] = val
+		}
+	}
+	// Recompute the allocatable resources of the NodeInfo.
+	nodeInfo.SetNode(node)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type staticTemplateNodeInfoProvider struct {
+	nodes map[string]*apiv1.Node
+}
+
+func (p *staticTemplateNodeInfoProvider) Process(_ *context.AutoscalingContext, _ []*apiv1.Node, _ []*appsv1.DaemonSet, _ taints.TaintConfig, _ time.Time) (map[string]*schedulerframework.NodeInfo, errors.AutoscalerError) {
+	result := make(map[string]*schedulerframework.NodeInfo)
+	for id, node := range p.nodes {
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node.DeepCopy())
+		result[id] = nodeInfo
+	}
+	return result, nil
+}
+
+func (p *staticTemplateNodeInfoProvider) CleanUp() {}
+
+func TestInjectingNodeInfoProvider(t *testing.T) {
+	const (
+		namespace     = "kube-system"
+		configMapName = "cluster-autoscaler-node-templates"
+	)
+	hugepages := apiv1.ResourceName("hugepages-1Gi")
+	ngHugepages := BuildTestNode("ng-hugepages-template", 1000, 1000)
+	ngHugepages.Labels = map[string]string{"hugepages": "false"}
+	ngPlain := BuildTestNode("ng-plain-template", 1000, 1000)
+	base := &staticTemplateNodeInfoProvider{nodes: map[string]*apiv1.Node{"ng-hugepages": ngHugepages, "ng-plain": ngPlain}}
+	configMap := func(rules string) *apiv1.ConfigMap {
+		return &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: namespace},
+			Data:       map[string]string{InjectionConfigMapKey: rules},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		configMap     *apiv1.ConfigMap
+		wantHugepages string
+		wantLabels    map[string]string
+	}{
+		{
+			name: "no configmap",
+		},
+		{
+			name: "resources and labels injected",
+			configMap: configMap(`
+- nodeGroups: ".*-hugepages"
+  resources:
+    hugepages-1Gi: 8Gi
+  labels:
+    hugepages: "true"
+    vendor.com/device: present
+`),
+			wantHugepages: "8Gi",
+			wantLabels:    map[string]string{"hugepages": "false", "vendor.com/device": "present"},
+		},
+		{
+			name:          "invalid configuration is ignored",
+			configMap:     configMap(`- nodeGroups: "("`),
+			wantHugepages: "8Gi",
+			wantLabels:    map[string]string{"hugepages": "false", "vendor.com/device": "present"},
+		},
+		{
+			name: "configmap removed",
+		},
+	}
+
+	recorder := kube_record.NewFakeRecorder(10)
+	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: recorder}}
+	p := NewInjectingNodeInfoProvider(base, nil, configMapName)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cms []*apiv1.ConfigMap
+			if tc.configMap != nil {
+				cms = append(cms, tc.configMap)
+			}
+			lister, err := kube_util.NewTestConfigMapLister(cms)
+			assert.NoError(t, err)
+			p.configMapLister = lister.ConfigMaps(namespace)
+
+			nodeInfos, autoscalerErr := p.Process(ctx, nil, nil, taints.TaintConfig{}, time.Now())
+			assert.NoError(t, autoscalerErr)
+
+			node := nodeInfos["ng-hugepages"].Node()
+			if tc.wantHugepages == "" {
+				assert.NotContains(t, node.Status.Allocatable, hugepages)
+				assert.Equal(t, map[string]string{"hugepages": "false"}, node.Labels)
+			} else {
+				want := resource.MustParse(tc.wantHugepages)
+				assert.True(t, want.Equal(node.Status.Allocatable[hugepages]))
+				assert.True(t, want.Equal(node.Status.Capacity[hugepages]))
+				assert.Equal(t, want.Value(), nodeInfos["ng-hugepages"].Allocatable.ScalarResources[hugepages])
+				assert.Equal(t, tc.wantLabels, node.Labels)
+			}
+			assert.NotContains(t, nodeInfos["ng-plain"].Node().Status.Allocatable, hugepages)
+		})
+	}
+	assert.Equal(t, 1, len(recorder.Events))
+}
@@ -45,6 +45,7 @@ type cacheItem struct {
 // MixedTemplateNodeInfoProvider build nodeInfos from the cluster's nodes and node groups.
 type MixedTemplateNodeInfoProvider struct {
 	nodeInfoCache   map[string]cacheItem
+	builtNodeInfos  *builtNodeInfosCache
 	ttl             time.Duration
 	forceDaemonSets bool
 }
@@ -58,6 +59,7 @@ func NewMixedTemplateNodeInfoProvider(t *time.Duration, forceDaemonSets bool) *M
 	}
 	return &MixedTemplateNodeInfoProvider{
 		nodeInfoCache:   make(map[string]cacheItem),
+		builtNodeInfos:  newBuiltNodeInfosCache(),
 		ttl:             ttl,
 		forceDaemonSets: forceDaemonSets,
 	}
@@ -82,6 +84,7 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 	if err != nil {
 		return map[string]*schedulerframework.NodeInfo{}, err
 	}
+	daemonSets := daemonSetsFingerprint(daemonsets)
 
 	// processNode returns information whether the nodeTemplate was generated and if there was an error.
 	processNode := func(node *apiv1.Node) (bool, string, errors.AutoscalerError) {
@@ -94,6 +97,14 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 		}
 		id := nodeGroup.Id()
 		if _, found := result[id]; !found {
+			inputs, cacheable := nodeInputs(node, podsForNodes[node.Name], daemonSets, taintConfig)
+			if cacheable {
+				if nodeInfo, found := p.builtNodeInfos.get(id, inputs); found {
+					result[id] = nodeInfo
+					return true, id, nil
+				}
+			}
+
 			// Build nodeInfo.
 			sanitizedNode, err := utils.SanitizeNode(node, id, taintConfig)
 			if err != nil {
@@ -112,6 +123,9 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 			sanitizedNodeInfo := schedulerframework.NewNodeInfo(utils.SanitizePods(pods, sanitizedNode)...)
 			sanitizedNodeInfo.SetNode(sanitizedNode)
 			result[id] = sanitizedNodeInfo
+			if cacheable {
+				p.builtNodeInfos.put(id, inputs, sanitizedNodeInfo)
+			}
 			return true, id, nil
 		}
 		return false, "", nil
@@ -152,7 +166,7 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 
 		// No good template, trying to generate one. This is called only if there are no
 		// working nodes in the node groups. By default CA tries to use a real-world example.
-		nodeInfo, err := utils.GetNodeInfoFromTemplate(nodeGroup, daemonsets, taintConfig)
+		baseNodeInfo, err := nodeGroup.TemplateNodeInfo()
 		if err != nil {
 			if err == cloudprovider.ErrNotImplemented {
 				continue
@@ -161,7 +175,19 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 				return map[string]*schedulerframework.NodeInfo{}, errors.ToAutoscalerError(errors.CloudProviderError, err)
 			}
 		}
+		// Templates of node groups rarely change, reuse the NodeInfo built in a previous loop if possible.
+		inputs := templateInputs(baseNodeInfo, daemonSets, taintConfig)
+		if nodeInfo, found := p.builtNodeInfos.get(id, inputs); found {
+			result[id] = nodeInfo
+			continue
+		}
+		nodeInfo, typedErr := utils.BuildNodeInfoFromTemplate(id, baseNodeInfo, daemonsets, taintConfig)
+		if typedErr != nil {
+			klog.Errorf("Unable to build proper template node for %s: %v", id, typedErr)
+			return map[string]*schedulerframework.NodeInfo{}, typedErr
+		}
 		result[id] = nodeInfo
+		p.builtNodeInfos.put(id, inputs, nodeInfo)
 	}
 
 	// Remove invalid node groups from cache
@@ -170,6 +196,7 @@ func (p *MixedTemplateNodeInfoProvider) Process(ctx *context.AutoscalingContext,
 			delete(p.nodeInfoCache, id)
 		}
 	}
+	p.builtNodeInfos.retain(seenGroups)
 
 	// Last resort - unready/unschedulable nodes.
 	for _, node := range nodes {
@@ -30,6 +30,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
@@ -290,6 +292,85 @@ func TestGetNodeInfosCacheExpired(t *testing.T) {
 
 }
 
+func TestBuiltNodeInfosReuse(t *testing.T) {
+	now := time.Now()
+	ready1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(ready1, true, now.Add(-2*time.Minute))
+	ready1.ResourceVersion = "1"
+	tn := BuildTestNode("tn", 5000, 5000)
+	tni := schedulerframework.NewNodeInfo()
+	tni.SetNode(tn)
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ds", Namespace: "kube-system", Generation: 1},
+		Spec: appsv1.DaemonSetSpec{
+			Template: apiv1.PodTemplateSpec{Spec: apiv1.PodSpec{NodeSelector: map[string]string{"missing": "label"}}},
+		},
+	}
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil, nil, nil, map[string]*schedulerframework.NodeInfo{"ng2": tni})
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", ready1)
+	provider.AddNodeGroup("ng2", 0, 10, 0)
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+	ctx := context.AutoscalingContext{
+		CloudProvider: provider,
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ListerRegistry: registry,
+		},
+	}
+	niProcessor := NewMixedTemplateNodeInfoProvider(&cacheTtl, false)
+	process := func() map[string]*schedulerframework.NodeInfo {
+		res, err := niProcessor.Process(&ctx, []*apiv1.Node{ready1}, []*appsv1.DaemonSet{ds}, taints.TaintConfig{}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(res))
+		return res
+	}
+
+	res := process()
+	assertEqualNodeCapacities(t, ready1, res["ng1"].Node())
+	assertEqualNodeCapacities(t, tn, res["ng2"].Node())
+
+	// Changes to the node that don't matter for scheduling, like heartbeats, don't invalidate its NodeInfo.
+	ready1.ResourceVersion = "2"
+	ready1.Status.NodeInfo.KernelVersion = "heartbeat"
+	ready1.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(now)
+	res = process()
+	assert.Empty(t, res["ng1"].Node().Status.NodeInfo.KernelVersion)
+
+	// Other changes do.
+	ready1.Status.Capacity[apiv1.ResourceCPU] = *resource.NewMilliQuantity(2000, resource.DecimalSI)
+	res = process()
+	assertEqualNodeCapacities(t, ready1, res["ng1"].Node())
+
+	// So does a new version of a daemonset.
+	ready1.Status.Capacity[apiv1.ResourceCPU] = *resource.NewMilliQuantity(3000, resource.DecimalSI)
+	ds.Generation = 2
+	res = process()
+	assertEqualNodeCapacities(t, ready1, res["ng1"].Node())
+
+	// Templates are compared without their random names.
+	tni.Node().Name = "tn-random"
+	tni.Node().Labels[apiv1.LabelHostname] = "tn-random"
+	tni.Node().Status.NodeInfo.KernelVersion = "random"
+	res = process()
+	assert.Empty(t, res["ng2"].Node().Status.NodeInfo.KernelVersion)
+
+	// But by the rest of their content, including their pods.
+	tni.Node().Status.Capacity[apiv1.ResourceCPU] = *resource.NewMilliQuantity(6000, resource.DecimalSI)
+	res = process()
+	assertEqualNodeCapacities(t, tni.Node(), res["ng2"].Node())
+	tni.AddPod(BuildTestPod("kube-proxy-tn-random", 100, 0))
+	res = process()
+	assert.Equal(t, 1, len(res["ng2"].Pods))
+
+	// Node groups that are gone are forgotten.
+	provider.DeleteNodeGroup("ng2")
+	_, err := niProcessor.Process(&ctx, []*apiv1.Node{ready1}, []*appsv1.DaemonSet{ds}, taints.TaintConfig{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(niProcessor.builtNodeInfos.items))
+}
+
 func assertEqualNodeCapacities(t *testing.T, expected, actual *apiv1.Node) {
 	t.Helper()
 	assert.NotEqual(t, actual.Status, nil, "")
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	klog "k8s.io/klog/v2"
+)
+
+// TemplateMismatch is a difference between the template of a node group and one of its nodes.
+type TemplateMismatch struct {
+	NodeGroup string
+	Node      string
+	// Kind is either "label" or "resource".
+	Kind          string
+	Name          string
+	TemplateValue string
+	NodeValue     string
+}
+
+func (m TemplateMismatch) String() string {
+	return fmt.Sprintf("node group %s, node %s: %s %s is %q in the template, %q on the node", m.NodeGroup, m.Node, m.Kind, m.Name, m.TemplateValue, m.NodeValue)
+}
+
+// VerifyNodeGroupTemplates compares the template of each node group, as used to scale it up from zero,
+// with up to samplesPerNodeGroup of its nodes and returns the differences of labels and allocatable
+// resources. Labels that differ between nodes of a node group, like the hostname or the zone, and the
+// extraIgnoredLabels are ignored. Resources may differ by up to config.DefaultMaxAllocatableDifferenceRatio.
+// Node groups whose templates aren't implemented are skipped.
+func VerifyNodeGroupTemplates(cloudProvider cloudprovider.CloudProvider, nodes []*apiv1.Node, samplesPerNodeGroup int, extraIgnoredLabels []string) ([]TemplateMismatch, errors.AutoscalerError) {
+	ignoredLabels := make(map[string]bool)
+	for label := range nodegroupset.BasicIgnoredLabels {
+		ignoredLabels[label] = true
+	}
+	for _, label := range extraIgnoredLabels {
+		ignoredLabels[label] = true
+	}
+
+	sortedNodes := make([]*apiv1.Node, len(nodes))
+	copy(sortedNodes, nodes)
+	sort.Slice(sortedNodes, func(i, j int) bool { return sortedNodes[i].Name < sortedNodes[j].Name })
+	samples := make(map[string][]*apiv1.Node)
+	for _, node := range sortedNodes {
+		nodeGroup, err := cloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			continue
+		}
+		if len(samples[nodeGroup.Id()]) < samplesPerNodeGroup {
+			samples[nodeGroup.Id()] = append(samples[nodeGroup.Id()], node)
+		}
+	}
+
+	var mismatches []TemplateMismatch
+	for _, nodeGroup := range cloudProvider.NodeGroups() {
+		sampledNodes := samples[nodeGroup.Id()]
+		if len(sampledNodes) == 0 {
+			klog.V(1).Infof("Skipping verification of the template of node group %s: no nodes", nodeGroup.Id())
+			continue
+		}
+		template, err := nodeGroup.TemplateNodeInfo()
+		if err == cloudprovider.ErrNotImplemented {
+			klog.V(1).Infof("Skipping verification of the template of node group %s: templates not implemented", nodeGroup.Id())
+			continue
+		}
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err).AddPrefix("failed to build the template of node group %s: ", nodeGroup.Id())
+		}
+		for _, node := range sampledNodes {
+			mismatches = append(mismatches, compareTemplateWithNode(nodeGroup.Id(), template.Node(), node, ignoredLabels)...)
+		}
+	}
+	return mismatches, nil
+}
+
+func compareTemplateWithNode(nodeGroupId string, template, node *apiv1.Node, ignoredLabels map[string]bool) []TemplateMismatch {
+	var mismatches []TemplateMismatch
+	labels := make(map[string]bool)
+	for label := range template.Labels {
+		labels[label] = true
+	}
+	for label := range node.Labels {
+		labels[label] = true
+	}
+	for _, label := range sortedKeys(labels) {
+		templateValue, inTemplate := template.Labels[label]
+		nodeValue, onNode := node.Labels[label]
+		if ignoredLabels[label] || (inTemplate && onNode && templateValue == nodeValue) {
+			continue
+		}
+		mismatches = append(mismatches, TemplateMismatch{NodeGroup: nodeGroupId, Node: node.Name, Kind: "label", Name: label, TemplateValue: templateValue, NodeValue: nodeValue})
+	}
+
+	resources := make(map[string]bool)
+	for resource := range template.Status.Allocatable {
+		resources[string(resource)] = true
+	}
+	for resource := range node.Status.Allocatable {
+		resources[string(resource)] = true
+	}
+	for _, resource := range sortedKeys(resources) {
+		templateQuantity, inTemplate := template.Status.Allocatable[apiv1.ResourceName(resource)]
+		nodeQuantity, onNode := node.Status.Allocatable[apiv1.ResourceName(resource)]
+		if inTemplate && onNode && withinTolerance(templateQuantity.MilliValue(), nodeQuantity.MilliValue(), config.DefaultMaxAllocatableDifferenceRatio) {
+			continue
+		}
+		mismatch := TemplateMismatch{NodeGroup: nodeGroupId, Node: node.Name, Kind: "resource", Name: resource}
+		if inTemplate {
+			mismatch.TemplateValue = templateQuantity.String()
+		}
+		if onNode {
+			mismatch.NodeValue = nodeQuantity.String()
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return mismatches
+}
+
+func withinTolerance(a, b int64, maxDifferenceRatio float64) bool {
+	larger := math.Max(float64(a), float64(b))
+	return math.Abs(float64(a-b)) <= larger*maxDifferenceRatio
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
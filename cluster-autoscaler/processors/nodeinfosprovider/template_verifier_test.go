@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestVerifyNodeGroupTemplates(t *testing.T) {
+	withLabels := func(node *apiv1.Node, labels map[string]string) *apiv1.Node {
+		node.Labels = labels
+		return node
+	}
+	templateInfo := func(node *apiv1.Node) *schedulerframework.NodeInfo {
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		return nodeInfo
+	}
+
+	testCases := []struct {
+		name               string
+		templates          map[string]*schedulerframework.NodeInfo
+		nodes              map[string][]*apiv1.Node
+		samples            int
+		extraIgnoredLabels []string
+		want               []TemplateMismatch
+	}{
+		{
+			name:      "matching template",
+			templates: map[string]*schedulerframework.NodeInfo{"ng1": templateInfo(withLabels(BuildTestNode("t1", 1000, 1000), map[string]string{"pool": "a", apiv1.LabelHostname: "t1"}))},
+			nodes:     map[string][]*apiv1.Node{"ng1": {withLabels(BuildTestNode("n1", 1020, 990), map[string]string{"pool": "a", apiv1.LabelHostname: "n1"})}},
+			samples:   3,
+		},
+		{
+			name:      "label mismatches",
+			templates: map[string]*schedulerframework.NodeInfo{"ng1": templateInfo(withLabels(BuildTestNode("t1", 1000, 1000), map[string]string{"pool": "a", "gpu": "true"}))},
+			nodes:     map[string][]*apiv1.Node{"ng1": {withLabels(BuildTestNode("n1", 1000, 1000), map[string]string{"pool": "b", "arch": "arm64"})}},
+			samples:   3,
+			want: []TemplateMismatch{
+				{NodeGroup: "ng1", Node: "n1", Kind: "label", Name: "arch", NodeValue: "arm64"},
+				{NodeGroup: "ng1", Node: "n1", Kind: "label", Name: "gpu", TemplateValue: "true"},
+				{NodeGroup: "ng1", Node: "n1", Kind: "label", Name: "pool", TemplateValue: "a", NodeValue: "b"},
+			},
+		},
+		{
+			name:               "extra ignored labels",
+			templates:          map[string]*schedulerframework.NodeInfo{"ng1": templateInfo(withLabels(BuildTestNode("t1", 1000, 1000), map[string]string{"pool": "a"}))},
+			nodes:              map[string][]*apiv1.Node{"ng1": {withLabels(BuildTestNode("n1", 1000, 1000), map[string]string{"pool": "b"})}},
+			samples:            3,
+			extraIgnoredLabels: []string{"pool"},
+		},
+		{
+			name:      "resource mismatches",
+			templates: map[string]*schedulerframework.NodeInfo{"ng1": templateInfo(BuildTestNode("t1", 1000, 1000))},
+			nodes:     map[string][]*apiv1.Node{"ng1": {BuildTestNode("n1", 2000, 1000)}},
+			samples:   3,
+			want: []TemplateMismatch{
+				{NodeGroup: "ng1", Node: "n1", Kind: "resource", Name: "cpu", TemplateValue: "1", NodeValue: "2"},
+			},
+		},
+		{
+			name:      "sampled nodes",
+			templates: map[string]*schedulerframework.NodeInfo{"ng1": templateInfo(BuildTestNode("t1", 1000, 1000))},
+			nodes:     map[string][]*apiv1.Node{"ng1": {BuildTestNode("n3", 2000, 1000), BuildTestNode("n1", 2000, 1000), BuildTestNode("n2", 2000, 1000)}},
+			samples:   2,
+			want: []TemplateMismatch{
+				{NodeGroup: "ng1", Node: "n1", Kind: "resource", Name: "cpu", TemplateValue: "1", NodeValue: "2"},
+				{NodeGroup: "ng1", Node: "n2", Kind: "resource", Name: "cpu", TemplateValue: "1", NodeValue: "2"},
+			},
+		},
+		{
+			name:    "templates not implemented",
+			nodes:   map[string][]*apiv1.Node{"ng1": {BuildTestNode("n1", 2000, 1000)}},
+			samples: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil, nil, nil, tc.templates)
+			var nodes []*apiv1.Node
+			for ng, ngNodes := range tc.nodes {
+				provider.AddNodeGroup(ng, 0, 10, len(ngNodes))
+				for _, node := range ngNodes {
+					provider.AddNode(ng, node)
+					nodes = append(nodes, node)
+				}
+			}
+			got, err := VerifyNodeGroupTemplates(provider, nodes, tc.samples, tc.extraIgnoredLabels)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
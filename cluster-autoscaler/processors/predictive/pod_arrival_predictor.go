@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predictive
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	klog "k8s.io/klog/v2"
+)
+
+// PredictedPodAnnotationKey is a key used to annotate the virtual pods predicted to arrive, set to the
+// namespace and name of the pending pod they are a copy of.
+const PredictedPodAnnotationKey = "cluster-autoscaler.kubernetes.io/predicted-pod"
+
+// PodArrivalPredictor tracks the rate at which newly created pods become pending and injects virtual copies
+// of the most recent ones into the unschedulable pods list, so that capacity for the pods expected to
+// arrive next is requested ahead of them, e.g. during waves of job submissions. It has to run before
+// the pods schedulable on the existing nodes are filtered out, so that all arrivals are counted and
+// only the predicted pods that don't fit trigger a scale-up.
+type PodArrivalPredictor struct {
+	// aggressiveness is the number of pods predicted to arrive per pod that arrived in the window.
+	aggressiveness float64
+	// window is how long after their creation pending pods are counted as arrivals.
+	window time.Duration
+	now    func() time.Time
+	// arrivals are the pods created in the window that were pending, by uid.
+	arrivals map[types.UID]*apiv1.Pod
+}
+
+// NewPodArrivalPredictor creates a pod arrival predictor processor.
+func NewPodArrivalPredictor(aggressiveness float64, window time.Duration) pods.PodListProcessor {
+	return &PodArrivalPredictor{
+		aggressiveness: aggressiveness,
+		window:         window,
+		now:            time.Now,
+		arrivals:       make(map[types.UID]*apiv1.Pod),
+	}
+}
+
+// Process records the newly pending pods and injects the pods predicted to arrive to unschedulable pods list.
+func (p *PodArrivalPredictor) Process(
+	_ *context.AutoscalingContext,
+	unschedulablePods []*apiv1.Pod,
+) ([]*apiv1.Pod, error) {
+	now := p.now()
+	for uid, pod := range p.arrivals {
+		if now.Sub(pod.CreationTimestamp.Time) > p.window {
+			delete(p.arrivals, uid)
+		}
+	}
+	for _, pod := range unschedulablePods {
+		// Pods pending since before the window, e.g. when the autoscaler restarts, aren't arrivals.
+		if pod_util.IsHeadroomPlaceholderPod(pod) || now.Sub(pod.CreationTimestamp.Time) > p.window {
+			continue
+		}
+		p.arrivals[pod.UID] = pod
+	}
+
+	predicted := p.predictedPods()
+	if len(predicted) > 0 {
+		klog.V(2).Infof("%d pods became pending in the last %v, injecting %d pods predicted to arrive", len(p.arrivals), p.window, len(predicted))
+	}
+	return append(unschedulablePods, predicted...), nil
+}
+
+// predictedPods returns copies of the most recent arrivals, aggressiveness times as many as the arrivals.
+func (p *PodArrivalPredictor) predictedPods() []*apiv1.Pod {
+	count := int(p.aggressiveness * float64(len(p.arrivals)))
+	if count == 0 {
+		return nil
+	}
+	recent := make([]*apiv1.Pod, 0, len(p.arrivals))
+	for _, pod := range p.arrivals {
+		recent = append(recent, pod)
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		if !recent[i].CreationTimestamp.Equal(&recent[j].CreationTimestamp) {
+			return recent[j].CreationTimestamp.Before(&recent[i].CreationTimestamp)
+		}
+		return recent[i].UID < recent[j].UID
+	})
+
+	predicted := make([]*apiv1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		predicted = append(predicted, predictedPod(recent[i%len(recent)], i))
+	}
+	return predicted
+}
+
+// predictedPod returns a virtual copy of the pending pod. It is never created in the cluster.
+func predictedPod(pod *apiv1.Pod, i int) *apiv1.Pod {
+	predicted := pod.DeepCopy()
+	predicted.Name = fmt.Sprintf("predicted-%s-%d", pod.Name, i)
+	predicted.UID = types.UID(fmt.Sprintf("%s/%s", pod.Namespace, predicted.Name))
+	predicted.Spec.NodeName = ""
+	if predicted.Annotations == nil {
+		predicted.Annotations = make(map[string]string)
+	}
+	source := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	// Scale-down keeps room for the pods like for node group headroom placeholders.
+	predicted.Annotations[pod_util.HeadroomPlaceholderAnnotationKey] = source
+	predicted.Annotations[PredictedPodAnnotationKey] = source
+	return predicted
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *PodArrivalPredictor) CleanUp() {}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predictive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestPodArrivalPredictor(t *testing.T) {
+	now := time.Now()
+	pod := func(name string, createdAgo time.Duration) *apiv1.Pod {
+		p := BuildTestPod(name, 100, 1000)
+		p.CreationTimestamp = metav1.NewTime(now.Add(-createdAgo))
+		return p
+	}
+	names := func(pods []*apiv1.Pod) []string {
+		var result []string
+		for _, p := range pods {
+			result = append(result, p.Name)
+		}
+		return result
+	}
+
+	predictor := NewPodArrivalPredictor(1.5, 5*time.Minute).(*PodArrivalPredictor)
+	predictor.now = func() time.Time { return now }
+
+	// Pods pending since before the window and placeholders aren't arrivals.
+	placeholder := pod("placeholder", 0)
+	placeholder.Annotations = map[string]string{pod_util.HeadroomPlaceholderAnnotationKey: "ng1"}
+	old := pod("old", 10*time.Minute)
+	result, err := predictor.Process(nil, []*apiv1.Pod{old, placeholder})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old", "placeholder"}, names(result))
+
+	// Copies of the most recent arrivals are injected.
+	p1 := pod("p1", 3*time.Minute)
+	p2 := pod("p2", 2*time.Minute)
+	result, err = predictor.Process(nil, []*apiv1.Pod{old, p1, p2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old", "p1", "p2", "predicted-p2-0", "predicted-p1-1", "predicted-p2-2"}, names(result))
+	predicted := result[3]
+	assert.Equal(t, p2.Spec, predicted.Spec)
+	assert.NotEqual(t, p2.UID, predicted.UID)
+	assert.True(t, pod_util.IsHeadroomPlaceholderPod(predicted))
+	assert.Equal(t, "default/p2", predicted.Annotations[PredictedPodAnnotationKey])
+
+	// Arrivals that were scheduled are still counted while in the window.
+	p3 := pod("p3", time.Minute)
+	result, err = predictor.Process(nil, []*apiv1.Pod{p3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p3", "predicted-p3-0", "predicted-p2-1", "predicted-p1-2", "predicted-p3-3"}, names(result))
+
+	// Arrivals older than the window are forgotten.
+	predictor.now = func() time.Time { return now.Add(150 * time.Second) }
+	result, err = predictor.Process(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"predicted-p3-0", "predicted-p2-1", "predicted-p3-2"}, names(result))
+	predictor.now = func() time.Time { return now.Add(10 * time.Minute) }
+	result, err = predictor.Process(nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
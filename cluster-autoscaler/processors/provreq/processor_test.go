@@ -17,13 +17,17 @@ limitations under the License.
 package provreq
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/autoscaler/cluster-autoscaler/apis/provisioningrequest/autoscaling.x-k8s.io/v1beta1"
+	"k8s.io/autoscaler/cluster-autoscaler/provisioningrequest"
 	"k8s.io/autoscaler/cluster-autoscaler/provisioningrequest/conditions"
 	"k8s.io/autoscaler/cluster-autoscaler/provisioningrequest/provreqclient"
 	"k8s.io/autoscaler/cluster-autoscaler/provisioningrequest/provreqwrapper"
@@ -146,7 +150,7 @@ func TestProcess(t *testing.T) {
 		additionalPr := provreqclient.ProvisioningRequestWrapperForTesting("namespace", "additional")
 		additionalPr.CreationTimestamp = metav1.NewTime(weekAgo)
 		additionalPr.Spec.ProvisioningClassName = v1beta1.ProvisioningClassCheckCapacity
-		processor := provReqProcessor{func() time.Time { return now }, 1, provreqclient.NewFakeProvisioningRequestClient(nil, t, pr, additionalPr)}
+		processor := provReqProcessor{now: func() time.Time { return now }, maxUpdated: 1, client: provreqclient.NewFakeProvisioningRequestClient(nil, t, pr, additionalPr), expirationTime: defaultExpirationTime}
 		processor.Process([]*provreqwrapper.ProvisioningRequest{pr, additionalPr})
 		assert.ElementsMatch(t, test.wantConditions, pr.Status.Conditions)
 		if len(test.conditions) == len(test.wantConditions) {
@@ -164,3 +168,140 @@ func TestProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessHoldTTL(t *testing.T) {
+	now := time.Now()
+	provisionedAt := now.Add(-3 * time.Minute)
+
+	testCases := []struct {
+		name        string
+		class       string
+		parameters  map[string]v1beta1.Parameter
+		wantExpired bool
+	}{
+		{
+			name:  "check capacity class books capacity for the default time",
+			class: v1beta1.ProvisioningClassCheckCapacity,
+			parameters: map[string]v1beta1.Parameter{
+				provisioningrequest.HoldTTLSecondsParameter: "120",
+			},
+		},
+		{
+			name:  "hold without a hold time books capacity for the default time",
+			class: v1beta1.ProvisioningClassCheckCapacityWithHold,
+		},
+		{
+			name:  "hold with an invalid hold time books capacity for the default time",
+			class: v1beta1.ProvisioningClassCheckCapacityWithHold,
+			parameters: map[string]v1beta1.Parameter{
+				provisioningrequest.HoldTTLSecondsParameter: "two minutes",
+			},
+		},
+		{
+			name:  "hold expires after its hold time",
+			class: v1beta1.ProvisioningClassCheckCapacityWithHold,
+			parameters: map[string]v1beta1.Parameter{
+				provisioningrequest.HoldTTLSecondsParameter: "120",
+			},
+			wantExpired: true,
+		},
+		{
+			name:  "hold doesn't expire before its hold time",
+			class: v1beta1.ProvisioningClassCheckCapacityWithHold,
+			parameters: map[string]v1beta1.Parameter{
+				provisioningrequest.HoldTTLSecondsParameter: "600",
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			pr := provreqclient.ProvisioningRequestWrapperForTesting("namespace", "name-1")
+			pr.CreationTimestamp = metav1.NewTime(provisionedAt)
+			pr.Spec.ProvisioningClassName = test.class
+			pr.Spec.Parameters = test.parameters
+			pr.Status.Conditions = []metav1.Condition{
+				{
+					Type:               v1beta1.Provisioned,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(provisionedAt),
+					Reason:             conditions.CapacityIsFoundReason,
+					Message:            conditions.CapacityIsFoundMsg,
+				},
+			}
+			processor := provReqProcessor{now: func() time.Time { return now }, maxUpdated: 1, client: provreqclient.NewFakeProvisioningRequestClient(nil, t, pr), expirationTime: defaultExpirationTime}
+			processor.Process([]*provreqwrapper.ProvisioningRequest{pr})
+			assert.Equal(t, test.wantExpired, apimeta.IsStatusConditionTrue(pr.Status.Conditions, v1beta1.BookingExpired))
+		})
+	}
+}
+
+func TestProcessRetention(t *testing.T) {
+	now := time.Now()
+	hourAgo := now.Add(-1 * time.Hour)
+	dayAgo := now.Add(-1 * 24 * time.Hour)
+
+	testCases := []struct {
+		name          string
+		conditions    []metav1.Condition
+		retentionTime time.Duration
+		wantDeleted   bool
+	}{
+		{
+			name: "failed ProvisioningRequest past retention time is deleted",
+			conditions: []metav1.Condition{
+				{Type: v1beta1.Failed, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(dayAgo)},
+			},
+			retentionTime: 2 * time.Hour,
+			wantDeleted:   true,
+		},
+		{
+			name: "booking expired ProvisioningRequest past retention time is deleted",
+			conditions: []metav1.Condition{
+				{Type: v1beta1.Provisioned, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(dayAgo)},
+				{Type: v1beta1.BookingExpired, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(dayAgo)},
+			},
+			retentionTime: 2 * time.Hour,
+			wantDeleted:   true,
+		},
+		{
+			name: "failed ProvisioningRequest within retention time is kept",
+			conditions: []metav1.Condition{
+				{Type: v1beta1.Failed, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(hourAgo)},
+			},
+			retentionTime: 2 * time.Hour,
+		},
+		{
+			name: "failed ProvisioningRequest is kept without retention time",
+			conditions: []metav1.Condition{
+				{Type: v1beta1.Failed, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(dayAgo)},
+			},
+		},
+		{
+			name: "provisioned ProvisioningRequest is kept",
+			conditions: []metav1.Condition{
+				{Type: v1beta1.Provisioned, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(now)},
+			},
+			retentionTime: time.Nanosecond,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			pr := provreqclient.ProvisioningRequestWrapperForTesting("namespace", "name-1")
+			pr.CreationTimestamp = metav1.NewTime(dayAgo)
+			pr.Spec.ProvisioningClassName = v1beta1.ProvisioningClassCheckCapacity
+			pr.Status.Conditions = test.conditions
+			client := provreqclient.NewFakeProvisioningRequestClient(context.Background(), t, pr)
+			processor := provReqProcessor{now: func() time.Time { return now }, maxUpdated: 1, client: client, expirationTime: defaultExpirationTime, retentionTime: test.retentionTime}
+			processor.Process([]*provreqwrapper.ProvisioningRequest{pr})
+			if test.wantDeleted {
+				assert.Eventually(t, func() bool {
+					_, err := client.ProvisioningRequest(pr.Namespace, pr.Name)
+					return apierrors.IsNotFound(err)
+				}, 5*time.Second, 10*time.Millisecond)
+			} else {
+				_, err := client.ProvisioningRequest(pr.Namespace, pr.Name)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
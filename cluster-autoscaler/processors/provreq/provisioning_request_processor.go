@@ -32,7 +32,7 @@ import (
 const (
 	defaultReservationTime = 10 * time.Minute
 	defaultExpirationTime  = 7 * 24 * time.Hour // 7 days
-	// defaultMaxUpdated is a limit for ProvisioningRequest to update conditions in one ClusterAutoscaler loop.
+	// defaultMaxUpdated is a limit for ProvisioningRequest to update conditions or delete in one ClusterAutoscaler loop.
 	defaultMaxUpdated = 20
 )
 
@@ -40,11 +40,18 @@ type provReqProcessor struct {
 	now        func() time.Time
 	maxUpdated int
 	client     *provreqclient.ProvisioningRequestClient
+	// expirationTime is the time since creation after which ProvisioningRequests that weren't provisioned fail.
+	expirationTime time.Duration
+	// retentionTime is the time after which failed or booking expired ProvisioningRequests are deleted.
+	// They are never deleted if it is 0.
+	retentionTime time.Duration
 }
 
-// NewProvReqProcessor return ProvisioningRequestProcessor.
-func NewProvReqProcessor(client *provreqclient.ProvisioningRequestClient) *provReqProcessor {
-	return &provReqProcessor{now: time.Now, maxUpdated: defaultMaxUpdated, client: client}
+// NewProvReqProcessor return ProvisioningRequestProcessor. ProvisioningRequests that weren't provisioned
+// within expirationTime since creation fail, and failed or booking expired ProvisioningRequests are deleted
+// after retentionTime, unless it is 0.
+func NewProvReqProcessor(client *provreqclient.ProvisioningRequestClient, expirationTime, retentionTime time.Duration) *provReqProcessor {
+	return &provReqProcessor{now: time.Now, maxUpdated: defaultMaxUpdated, client: client, expirationTime: expirationTime, retentionTime: retentionTime}
 }
 
 // Refresh implements loop.Observer interface and will be run at the start
@@ -62,13 +69,15 @@ func (p *provReqProcessor) Refresh() {
 
 // Process iterates over ProvisioningRequests and apply:
 // -BookingExpired condition for Provisioned ProvisioningRequest if capacity reservation time is expired.
-// -Failed condition for ProvisioningRequest that were not provisioned during defaultExpirationTime.
+// -Failed condition for ProvisioningRequest that were not provisioned during expirationTime.
+// -Deletion of ProvisioningRequest that failed or whose booking expired more than retentionTime ago.
 // TODO(yaroslava): fetch reservation and expiration time from ProvisioningRequest
 func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningRequest) {
 	expiredProvReq := []*provreqwrapper.ProvisioningRequest{}
 	failedProvReq := []*provreqwrapper.ProvisioningRequest{}
+	deletedProvReq := []*provreqwrapper.ProvisioningRequest{}
 	for _, provReq := range provReqs {
-		if len(expiredProvReq) >= p.maxUpdated {
+		if len(expiredProvReq)+len(failedProvReq)+len(deletedProvReq) >= p.maxUpdated {
 			break
 		}
 		if ok, found := provisioningrequest.SupportedProvisioningClasses[provReq.Spec.ProvisioningClassName]; !ok || !found {
@@ -76,16 +85,23 @@ func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningReques
 		}
 		conditions := provReq.Status.Conditions
 		if apimeta.IsStatusConditionTrue(conditions, v1beta1.BookingExpired) || apimeta.IsStatusConditionTrue(conditions, v1beta1.Failed) {
+			if p.retentionTime > 0 && finishedAt(conditions).Add(p.retentionTime).Before(p.now()) {
+				deletedProvReq = append(deletedProvReq, provReq)
+			}
 			continue
 		}
 		provisioned := apimeta.FindStatusCondition(conditions, v1beta1.Provisioned)
 		if provisioned != nil && provisioned.Status == metav1.ConditionTrue {
-			if provisioned.LastTransitionTime.Add(defaultReservationTime).Before(p.now()) {
+			reservationTime := defaultReservationTime
+			if provReq.Spec.ProvisioningClassName == v1beta1.ProvisioningClassCheckCapacityWithHold {
+				reservationTime = provisioningrequest.HoldTTL(provReq.ProvisioningRequest, defaultReservationTime)
+			}
+			if provisioned.LastTransitionTime.Add(reservationTime).Before(p.now()) {
 				expiredProvReq = append(expiredProvReq, provReq)
 			}
-		} else if len(failedProvReq) < p.maxUpdated-len(expiredProvReq) {
+		} else {
 			created := provReq.CreationTimestamp
-			if created.Add(defaultExpirationTime).Before(p.now()) {
+			if created.Add(p.expirationTime).Before(p.now()) {
 				failedProvReq = append(failedProvReq, provReq)
 			}
 		}
@@ -106,6 +122,25 @@ func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningReques
 			continue
 		}
 	}
+	for _, provReq := range deletedProvReq {
+		if err := p.client.DeleteProvisioningRequest(provReq.ProvisioningRequest); err != nil {
+			klog.Errorf("failed to delete ProvReq %s/%s past its retention time, err: %v", provReq.Namespace, provReq.Name, err)
+			continue
+		}
+		klog.V(2).Infof("Deleted ProvReq %s/%s past its retention time", provReq.Namespace, provReq.Name)
+	}
+}
+
+// finishedAt returns when the ProvisioningRequest failed or its booking expired.
+func finishedAt(conditions []metav1.Condition) time.Time {
+	var finished time.Time
+	for _, conditionType := range []string{v1beta1.BookingExpired, v1beta1.Failed} {
+		condition := apimeta.FindStatusCondition(conditions, conditionType)
+		if condition != nil && condition.Status == metav1.ConditionTrue && condition.LastTransitionTime.After(finished) {
+			finished = condition.LastTransitionTime.Time
+		}
+	}
+	return finished
 }
 
 // Cleanup cleans up internal state.
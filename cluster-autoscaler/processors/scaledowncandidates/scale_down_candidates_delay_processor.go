@@ -70,7 +70,7 @@ func (p *ScaleDownCandidatesDelayProcessor) GetScaleDownCandidates(ctx *context.
 			return false
 		}
 
-		if recent(p.scaleUps, ctx.ScaleDownDelayAfterAdd, "scaled up") {
+		if recent(p.scaleUps, scaleDownDelayAfterAdd(ctx, nodeGroup), "scaled up") {
 			continue
 		}
 
@@ -87,6 +87,19 @@ func (p *ScaleDownCandidatesDelayProcessor) GetScaleDownCandidates(ctx *context.
 	return result, nil
 }
 
+// scaleDownDelayAfterAdd returns the scale down delay after scale up of the nodegroup,
+// falling back to the global one if the nodegroup doesn't override it.
+func scaleDownDelayAfterAdd(ctx *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup) time.Duration {
+	opts, err := nodeGroup.GetOptions(ctx.NodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		klog.Warningf("Failed to get autoscaling options for node group %s: %v", nodeGroup.Id(), err)
+	}
+	if err != nil || opts == nil {
+		return ctx.ScaleDownDelayAfterAdd
+	}
+	return opts.ScaleDownDelayAfterAdd
+}
+
 // CleanUp is called at CA termination.
 func (p *ScaleDownCandidatesDelayProcessor) CleanUp() {
 }
@@ -62,6 +62,7 @@ func TestGetScaleDownCandidates(t *testing.T) {
 		candidates         []*v1.Node
 		expected           []*v1.Node
 		setupProcessor     func(p *ScaleDownCandidatesDelayProcessor) *ScaleDownCandidatesDelayProcessor
+		ngOptions          map[string]*config.NodeGroupAutoscalingOptions
 	}{
 		// Expectation: no nodegroups should be filtered out
 		"no scale ups - no scale downs - no scale down failures": {
@@ -104,6 +105,27 @@ func TestGetScaleDownCandidates(t *testing.T) {
 				return p
 			},
 		},
+		// Expectation: nodegroups in cool-down according to their own scale down delay after add should be filtered out
+		"2 scale ups - per nodegroup scale down delay after add": {
+			autoscalingContext: ctx,
+			candidates:         []*v1.Node{n1, n2, n3},
+			expected:           []*v1.Node{n1, n2},
+			setupProcessor: func(p *ScaleDownCandidatesDelayProcessor) *ScaleDownCandidatesDelayProcessor {
+				// fake nodegroups for calling `RegisterScaleUp`
+				ng2 := test.NewTestNodeGroup("ng-2", 0, 0, 0, false, false, "", nil, nil)
+				ng3 := test.NewTestNodeGroup("ng-3", 0, 0, 0, false, false, "", nil, nil)
+
+				// not in cool down anymore with a shorter delay
+				p.RegisterScaleUp(ng2, 0, time.Now().Add(-time.Minute*5))
+				// still in cool down with a longer delay
+				p.RegisterScaleUp(ng3, 0, time.Now().Add(-time.Minute*11))
+				return p
+			},
+			ngOptions: map[string]*config.NodeGroupAutoscalingOptions{
+				"ng-2": {ScaleDownDelayAfterAdd: time.Minute * 2},
+				"ng-3": {ScaleDownDelayAfterAdd: time.Minute * 30},
+			},
+		},
 		// Expectation: only nodegroups in cool-down should be filtered out
 		"no scale up - no scale down - 1 scale down failure": {
 			autoscalingContext: ctx,
@@ -140,6 +162,10 @@ func TestGetScaleDownCandidates(t *testing.T) {
 			provider.AddNodeGroup("ng-3", 1, 3, 2)
 			provider.AddNode("ng-3", n3)
 
+			for id, opts := range testCase.ngOptions {
+				provider.GetNodeGroup(id).(*testprovider.TestNodeGroup).SetOptions(opts)
+			}
+
 			testCase.autoscalingContext.CloudProvider = provider
 
 			no, err := p.GetScaleDownCandidates(&testCase.autoscalingContext, testCase.candidates)
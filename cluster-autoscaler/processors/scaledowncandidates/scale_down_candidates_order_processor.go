@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaledowncandidates
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/utilization"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+const (
+	// CostScaleDownOrder considers the most expensive nodes for scale down first, using the pricing model of the cloud
+	// provider.
+	CostScaleDownOrder = "cost"
+	// UtilizationScaleDownOrder considers the least utilized nodes for scale down first.
+	UtilizationScaleDownOrder = "utilization"
+	// AgeScaleDownOrder considers the oldest nodes for scale down first.
+	AgeScaleDownOrder = "age"
+)
+
+// AvailableScaleDownOrders is a list of available scale down orders.
+var AvailableScaleDownOrders = []string{CostScaleDownOrder, UtilizationScaleDownOrder, AgeScaleDownOrder}
+
+// ScaleDownCandidatesOrderProcessor is a processor ordering scale down candidates by cost, utilization or age, so
+// that the nodes removing which saves the most are considered first. Nodes the order cannot be determined for are
+// moved to the end of the list, the relative order of the other nodes is kept.
+type ScaleDownCandidatesOrderProcessor struct {
+	order string
+}
+
+// GetPodDestinationCandidates returns nodes as is no processing is required here
+func (p *ScaleDownCandidatesOrderProcessor) GetPodDestinationCandidates(ctx *context.AutoscalingContext,
+	nodes []*apiv1.Node) ([]*apiv1.Node, errors.AutoscalerError) {
+	return nodes, nil
+}
+
+// GetScaleDownCandidates returns the nodes in scale down order.
+func (p *ScaleDownCandidatesOrderProcessor) GetScaleDownCandidates(ctx *context.AutoscalingContext,
+	nodes []*apiv1.Node) ([]*apiv1.Node, errors.AutoscalerError) {
+	now := time.Now()
+	keys := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		key, err := p.orderKey(ctx, node, now)
+		if err != nil {
+			klog.V(4).Infof("Unable to determine the %s of node %s, considering it for scale down last: %v", p.order, node.Name, err)
+			continue
+		}
+		keys[node.Name] = key
+	}
+
+	result := make([]*apiv1.Node, len(nodes))
+	copy(result, nodes)
+	sort.SliceStable(result, func(i, j int) bool {
+		key1, found1 := keys[result[i].Name]
+		key2, found2 := keys[result[j].Name]
+		if found1 != found2 {
+			return found1
+		}
+		return key1 < key2
+	})
+	return result, nil
+}
+
+// orderKey returns the key of the node, nodes with lower keys are considered for scale down first.
+func (p *ScaleDownCandidatesOrderProcessor) orderKey(ctx *context.AutoscalingContext, node *apiv1.Node, now time.Time) (float64, error) {
+	switch p.order {
+	case CostScaleDownOrder:
+		pricingModel, pricingErr := ctx.CloudProvider.Pricing()
+		if pricingErr != nil {
+			return 0, pricingErr
+		}
+		price, err := pricingModel.NodePrice(node, now, now.Add(time.Hour))
+		if err != nil {
+			return 0, err
+		}
+		return -price, nil
+	case UtilizationScaleDownOrder:
+		nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(node.Name)
+		if err != nil {
+			return 0, err
+		}
+		utilInfo, err := utilization.Calculate(nodeInfo, ctx.NodeGroupDefaults.IgnoreDaemonSetsUtilization, ctx.IgnoreMirrorPodsUtilization, ctx.CloudProvider.GetNodeGpuConfig(node), now)
+		if err != nil {
+			return 0, err
+		}
+		return utilInfo.Utilization, nil
+	case AgeScaleDownOrder:
+		if node.CreationTimestamp.IsZero() {
+			return 0, fmt.Errorf("node has no creation timestamp")
+		}
+		return float64(node.CreationTimestamp.Unix()), nil
+	}
+	return 0, fmt.Errorf("unknown scale down order %q", p.order)
+}
+
+// CleanUp is called at CA termination.
+func (p *ScaleDownCandidatesOrderProcessor) CleanUp() {
+}
+
+// NewScaleDownCandidatesOrderProcessor returns a new ScaleDownCandidatesOrderProcessor for one of
+// AvailableScaleDownOrders.
+func NewScaleDownCandidatesOrderProcessor(order string) (*ScaleDownCandidatesOrderProcessor, error) {
+	for _, available := range AvailableScaleDownOrders {
+		if order == available {
+			return &ScaleDownCandidatesOrderProcessor{order: order}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown scale down order %q, available orders are %v", order, AvailableScaleDownOrders)
+}
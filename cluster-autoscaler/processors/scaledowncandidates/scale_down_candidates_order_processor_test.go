@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaledowncandidates
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type testPricingModel struct {
+	nodePrices map[string]float64
+}
+
+func (tpm *testPricingModel) NodePrice(node *apiv1.Node, _, _ time.Time) (float64, error) {
+	if price, found := tpm.nodePrices[node.Name]; found {
+		return price, nil
+	}
+	return 0, fmt.Errorf("unknown node %s", node.Name)
+}
+
+func (tpm *testPricingModel) PodPrice(*apiv1.Pod, time.Time, time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestScaleDownCandidatesOrderProcessor(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n1.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n2.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	n3 := BuildTestNode("n3", 1000, 1000)
+	n3.CreationTimestamp = metav1.NewTime(now.Add(-3 * time.Hour))
+	n4 := BuildTestNode("n4", 1000, 1000)
+
+	p1 := BuildTestPod("p1", 500, 0)
+	p1.Spec.NodeName = "n1"
+	p3 := BuildTestPod("p3", 200, 0)
+	p3.Spec.NodeName = "n3"
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.SetPricingModel(&testPricingModel{nodePrices: map[string]float64{"n1": 3, "n2": 1, "n3": 2}})
+	snapshot := clustersnapshot.NewBasicClusterSnapshot()
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, snapshot, []*apiv1.Node{n1, n2, n3}, []*apiv1.Pod{p1, p3})
+	ctx := &context.AutoscalingContext{CloudProvider: provider, ClusterSnapshot: snapshot}
+
+	testCases := []struct {
+		order    string
+		expected []*apiv1.Node
+	}{
+		{
+			order:    CostScaleDownOrder,
+			expected: []*apiv1.Node{n1, n3, n2, n4},
+		},
+		{
+			order:    UtilizationScaleDownOrder,
+			expected: []*apiv1.Node{n2, n3, n1, n4},
+		},
+		{
+			order:    AgeScaleDownOrder,
+			expected: []*apiv1.Node{n3, n1, n2, n4},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.order, func(t *testing.T) {
+			processor, err := NewScaleDownCandidatesOrderProcessor(tc.order)
+			assert.NoError(t, err)
+
+			// Nodes without price, utilization or age, like n4, are considered last.
+			candidates, err := processor.GetScaleDownCandidates(ctx, []*apiv1.Node{n4, n1, n2, n3})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, candidates)
+
+			destinations, err := processor.GetPodDestinationCandidates(ctx, []*apiv1.Node{n4, n1, n2, n3})
+			assert.NoError(t, err)
+			assert.Equal(t, []*apiv1.Node{n4, n1, n2, n3}, destinations)
+		})
+	}
+}
+
+func TestNewScaleDownCandidatesOrderProcessor(t *testing.T) {
+	_, err := NewScaleDownCandidatesOrderProcessor("random")
+	assert.Error(t, err)
+}
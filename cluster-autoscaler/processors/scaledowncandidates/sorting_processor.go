@@ -34,12 +34,12 @@ type NodeSorter struct {
 	processors []CandidatesComparer
 }
 
-// Sort return list of nodes in descending order.
+// Sort return list of nodes in descending order. Nodes the processors do not order keep their relative order.
 func (n *NodeSorter) Sort() []*apiv1.Node {
 	if len(n.processors) == 0 {
 		return n.nodes
 	}
-	sort.Sort(n)
+	sort.Stable(n)
 	return n.nodes
 }
 
@@ -38,13 +38,15 @@ type MetricsAutoscalingStatusProcessor struct {
 	backoffReasonStatus map[string]BackoffReasonStatus
 }
 
-// Process queries the health status and backoff situation of all node groups and updates metrics after each autoscaling iteration.
+// Process queries the health status, node counts and backoff situation of all node groups and updates metrics after each autoscaling iteration.
 func (p *MetricsAutoscalingStatusProcessor) Process(context *context.AutoscalingContext, csr *clusterstate.ClusterStateRegistry, now time.Time) error {
 	for _, nodeGroup := range context.CloudProvider.NodeGroups() {
 		if !nodeGroup.Exist() {
 			continue
 		}
 		metrics.UpdateNodeGroupHealthStatus(nodeGroup.Id(), csr.IsNodeGroupHealthy(nodeGroup.Id()))
+		readiness := csr.GetNodeGroupReadiness(nodeGroup.Id())
+		metrics.UpdateNodeGroupNodesCount(nodeGroup.Id(), len(readiness.Ready), len(readiness.Unready), len(readiness.NotStarted), len(readiness.LongUnregistered), len(readiness.Unregistered))
 		backoffStatus := csr.BackoffStatusForNodeGroup(nodeGroup, now)
 		p.updateNodeGroupBackoffStatusMetrics(nodeGroup.Id(), backoffStatus)
 	}
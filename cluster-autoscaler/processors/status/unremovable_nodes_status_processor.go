@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	klog "k8s.io/klog/v2"
+)
+
+// UnremovableNodesReport lists the nodes found unremovable in the last autoscaling loop.
+type UnremovableNodesReport struct {
+	Time  time.Time                `json:"time"`
+	Nodes []UnremovableNodeSummary `json:"nodes"`
+}
+
+// UnremovableNodeSummary describes why a node can't be removed.
+type UnremovableNodeSummary struct {
+	Name      string `json:"name"`
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	Reason    string `json:"reason"`
+	// Utilization is the utilization of the node, if it was computed.
+	Utilization *float64            `json:"utilization,omitempty"`
+	BlockingPod *BlockingPodSummary `json:"blockingPod,omitempty"`
+}
+
+// BlockingPodSummary describes the pod that blocks the removal of a node.
+type BlockingPodSummary struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// UnremovableNodesStatusProcessor keeps the unremovable nodes of the last scale-down status and serves
+// them over HTTP, so that it's possible to find out why nodes aren't scaled down without reading logs.
+// Requests can filter the nodes with the reason and nodeGroup query parameters.
+type UnremovableNodesStatusProcessor struct {
+	mutex  sync.Mutex
+	report UnremovableNodesReport
+}
+
+// NewUnremovableNodesStatusProcessor returns a new UnremovableNodesStatusProcessor.
+func NewUnremovableNodesStatusProcessor() *UnremovableNodesStatusProcessor {
+	return &UnremovableNodesStatusProcessor{}
+}
+
+// Process records the unremovable nodes of the scale-down status.
+func (p *UnremovableNodesStatusProcessor) Process(_ *context.AutoscalingContext, status *status.ScaleDownStatus) {
+	nodes := make([]UnremovableNodeSummary, 0, len(status.UnremovableNodes))
+	for _, unremovableNode := range status.UnremovableNodes {
+		summary := UnremovableNodeSummary{
+			Name:   unremovableNode.Node.Name,
+			Reason: unremovableNode.Reason.String(),
+		}
+		if unremovableNode.NodeGroup != nil {
+			summary.NodeGroup = unremovableNode.NodeGroup.Id()
+		}
+		if unremovableNode.UtilInfo != nil {
+			utilization := unremovableNode.UtilInfo.Utilization
+			summary.Utilization = &utilization
+		}
+		if blockingPod := unremovableNode.BlockingPod; blockingPod != nil && blockingPod.Pod != nil {
+			summary.BlockingPod = &BlockingPodSummary{
+				Namespace: blockingPod.Pod.Namespace,
+				Name:      blockingPod.Pod.Name,
+				Reason:    blockingPod.Reason.String(),
+			}
+		}
+		nodes = append(nodes, summary)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.report = UnremovableNodesReport{Time: time.Now(), Nodes: nodes}
+}
+
+// Report returns the unremovable nodes with the given reason and in the given node group,
+// the filters are ignored if empty.
+func (p *UnremovableNodesStatusProcessor) Report(reason, nodeGroup string) UnremovableNodesReport {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	report := UnremovableNodesReport{Time: p.report.Time, Nodes: []UnremovableNodeSummary{}}
+	for _, node := range p.report.Nodes {
+		if (reason == "" || node.Reason == reason) && (nodeGroup == "" || node.NodeGroup == nodeGroup) {
+			report.Nodes = append(report.Nodes, node)
+		}
+	}
+	return report
+}
+
+// ServeHTTP writes the unremovable nodes as JSON.
+func (p *UnremovableNodesStatusProcessor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := p.Report(r.URL.Query().Get("reason"), r.URL.Query().Get("nodeGroup"))
+	body, err := json.Marshal(report)
+	if err != nil {
+		klog.Errorf("Failed to marshal unremovable nodes: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		klog.Errorf("Failed to write unremovable nodes: %v", err)
+	}
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *UnremovableNodesStatusProcessor) CleanUp() {
+}
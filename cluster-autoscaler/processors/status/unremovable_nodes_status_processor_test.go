@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	cp_test "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/utilization"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnremovableNodesStatusProcessor(t *testing.T) {
+	provider := cp_test.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 2)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+	ng1, ng2 := provider.GetNodeGroup("ng1"), provider.GetNodeGroup("ng2")
+	pod := BuildTestPod("p1", 100, 100)
+	pod.Namespace = "ns"
+
+	p := NewUnremovableNodesStatusProcessor()
+	p.Process(nil, &status.ScaleDownStatus{
+		UnremovableNodes: []*status.UnremovableNode{
+			{Node: BuildTestNode("n3", 1000, 1000), NodeGroup: ng2, Reason: simulator.NotUnderutilized, UtilInfo: &utilization.Info{Utilization: 0.75}},
+			{Node: BuildTestNode("n1", 1000, 1000), NodeGroup: ng1, Reason: simulator.BlockedByPod, BlockingPod: &drain.BlockingPod{Pod: pod, Reason: drain.NotReplicated}},
+			{Node: BuildTestNode("n2", 1000, 1000), NodeGroup: ng1, Reason: simulator.NotUnderutilized, UtilInfo: &utilization.Info{Utilization: 0.5}},
+		},
+	})
+
+	utilization := func(u float64) *float64 { return &u }
+	testCases := []struct {
+		name  string
+		query string
+		want  []UnremovableNodeSummary
+	}{
+		{
+			name: "all nodes",
+			want: []UnremovableNodeSummary{
+				{Name: "n1", NodeGroup: "ng1", Reason: "BlockedByPod", BlockingPod: &BlockingPodSummary{Namespace: "ns", Name: "p1", Reason: "NotReplicated"}},
+				{Name: "n2", NodeGroup: "ng1", Reason: "NotUnderutilized", Utilization: utilization(0.5)},
+				{Name: "n3", NodeGroup: "ng2", Reason: "NotUnderutilized", Utilization: utilization(0.75)},
+			},
+		},
+		{
+			name:  "by reason",
+			query: "?reason=NotUnderutilized",
+			want: []UnremovableNodeSummary{
+				{Name: "n2", NodeGroup: "ng1", Reason: "NotUnderutilized", Utilization: utilization(0.5)},
+				{Name: "n3", NodeGroup: "ng2", Reason: "NotUnderutilized", Utilization: utilization(0.75)},
+			},
+		},
+		{
+			name:  "by reason and node group",
+			query: "?reason=NotUnderutilized&nodeGroup=ng2",
+			want: []UnremovableNodeSummary{
+				{Name: "n3", NodeGroup: "ng2", Reason: "NotUnderutilized", Utilization: utilization(0.75)},
+			},
+		},
+		{
+			name:  "no match",
+			query: "?nodeGroup=ng3",
+			want:  []UnremovableNodeSummary{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			p.ServeHTTP(recorder, httptest.NewRequest("GET", "/unremovablez"+tc.query, nil))
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			var report UnremovableNodesReport
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.False(t, report.Time.IsZero())
+			assert.Equal(t, tc.want, report.Nodes)
+		})
+	}
+}
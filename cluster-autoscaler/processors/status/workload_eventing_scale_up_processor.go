@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+)
+
+// WorkloadEventingScaleUpStatusProcessor processes the state of the cluster after
+// a scale-up by emitting NotTriggerScaleUp and TriggeredScaleUp events aggregated
+// per owning workload instead of one event per pod. Pods of a ReplicaSet are
+// attributed to its Deployment. Pods without a controller get their own events.
+type WorkloadEventingScaleUpStatusProcessor struct{}
+
+// NewWorkloadEventingScaleUpStatusProcessor returns WorkloadEventingScaleUpStatusProcessor.
+func NewWorkloadEventingScaleUpStatusProcessor() *WorkloadEventingScaleUpStatusProcessor {
+	return &WorkloadEventingScaleUpStatusProcessor{}
+}
+
+// workload identifies the owner of pods, it's comparable to be used as a map key.
+type workload struct {
+	apiVersion string
+	kind       string
+	namespace  string
+	name       string
+	uid        types.UID
+}
+
+// Process processes the state of the cluster after a scale-up by emitting
+// relevant events for workloads depending on the post scale-up status of their pods.
+func (p *WorkloadEventingScaleUpStatusProcessor) Process(context *context.AutoscalingContext, status *ScaleUpStatus) {
+	consideredNodeGroupsMap := nodeGroupListToMapById(status.ConsideredNodeGroups)
+	if status.Result != ScaleUpSuccessful && status.Result != ScaleUpError {
+		var workloads []workload
+		noScaleUpInfos := map[workload][]NoScaleUpInfo{}
+		for _, noScaleUpInfo := range status.PodsRemainUnschedulable {
+			w, found := workloadForPod(context, noScaleUpInfo.Pod)
+			if !found {
+				context.Recorder.Event(noScaleUpInfo.Pod, apiv1.EventTypeNormal, "NotTriggerScaleUp",
+					fmt.Sprintf("pod didn't trigger scale-up: %s",
+						ReasonsMessage(noScaleUpInfo, consideredNodeGroupsMap)))
+				continue
+			}
+			if _, seen := noScaleUpInfos[w]; !seen {
+				workloads = append(workloads, w)
+			}
+			noScaleUpInfos[w] = append(noScaleUpInfos[w], noScaleUpInfo)
+		}
+		for _, w := range workloads {
+			context.Recorder.Eventf(w.objectReference(), apiv1.EventTypeNormal, "NotTriggerScaleUp",
+				"%d pods didn't trigger scale-up: %s", len(noScaleUpInfos[w]),
+				NodeGroupReasonsMessage(noScaleUpInfos[w], consideredNodeGroupsMap))
+		}
+	} else {
+		klog.V(4).Infof("Skipping event processing for unschedulable pods since there is a" +
+			" ScaleUp attempt this loop")
+	}
+	if len(status.ScaleUpInfos) > 0 {
+		var workloads []workload
+		triggered := map[workload]int{}
+		for _, pod := range status.PodsTriggeredScaleUp {
+			w, found := workloadForPod(context, pod)
+			if !found {
+				context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "TriggeredScaleUp",
+					"pod triggered scale-up: %v", status.ScaleUpInfos)
+				continue
+			}
+			if _, seen := triggered[w]; !seen {
+				workloads = append(workloads, w)
+			}
+			triggered[w]++
+		}
+		for _, w := range workloads {
+			context.Recorder.Eventf(w.objectReference(), apiv1.EventTypeNormal, "TriggeredScaleUp",
+				"%d pods triggered scale-up: %v", triggered[w], status.ScaleUpInfos)
+		}
+	}
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *WorkloadEventingScaleUpStatusProcessor) CleanUp() {
+}
+
+// NodeGroupReasonsMessage summarizes which node groups were rejected or skipped for
+// any of the pods and why, e.g. "ng1, ng2: Insufficient cpu; ng3: max node group size reached".
+func NodeGroupReasonsMessage(noScaleUpInfos []NoScaleUpInfo, consideredNodeGroups map[string]cloudprovider.NodeGroup) string {
+	nodeGroupReasons := map[string]map[string]bool{}
+	add := func(reasonsByNodeGroup map[string]Reasons) {
+		for nodeGroupId, reasons := range reasonsByNodeGroup {
+			if nodeGroup, present := consideredNodeGroups[nodeGroupId]; !present || !nodeGroup.Exist() {
+				continue
+			}
+			if nodeGroupReasons[nodeGroupId] == nil {
+				nodeGroupReasons[nodeGroupId] = map[string]bool{}
+			}
+			for _, reason := range reasons.Reasons() {
+				nodeGroupReasons[nodeGroupId][reason] = true
+			}
+		}
+	}
+	for _, noScaleUpInfo := range noScaleUpInfos {
+		add(noScaleUpInfo.RejectedNodeGroups)
+		add(noScaleUpInfo.SkippedNodeGroups)
+	}
+
+	// Node groups rejected for the same reasons are listed together.
+	nodeGroupsByReasons := map[string][]string{}
+	for nodeGroupId, reasonSet := range nodeGroupReasons {
+		var reasons []string
+		for reason := range reasonSet {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		key := strings.Join(reasons, ", ")
+		nodeGroupsByReasons[key] = append(nodeGroupsByReasons[key], nodeGroupId)
+	}
+	var messages []string
+	for reasons, nodeGroupIds := range nodeGroupsByReasons {
+		sort.Strings(nodeGroupIds)
+		messages = append(messages, fmt.Sprintf("%s: %s", strings.Join(nodeGroupIds, ", "), reasons))
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+// workloadForPod returns the workload owning the pod, i.e. its controller or the
+// Deployment of its ReplicaSet.
+func workloadForPod(context *context.AutoscalingContext, pod *apiv1.Pod) (workload, bool) {
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil {
+		return workload{}, false
+	}
+	w := newWorkload(pod.Namespace, controllerRef)
+	if controllerRef.Kind != "ReplicaSet" || context.ListerRegistry == nil {
+		return w, true
+	}
+	rs, err := context.ListerRegistry.ReplicaSetLister().ReplicaSets(pod.Namespace).Get(controllerRef.Name)
+	if err != nil {
+		klog.V(4).Infof("Failed to get ReplicaSet %s/%s of pod %s: %v", pod.Namespace, controllerRef.Name, pod.Name, err)
+		return w, true
+	}
+	if deploymentRef := metav1.GetControllerOf(rs); deploymentRef != nil && deploymentRef.Kind == "Deployment" {
+		w = newWorkload(pod.Namespace, deploymentRef)
+	}
+	return w, true
+}
+
+func newWorkload(namespace string, ref *metav1.OwnerReference) workload {
+	return workload{apiVersion: ref.APIVersion, kind: ref.Kind, namespace: namespace, name: ref.Name, uid: ref.UID}
+}
+
+func (w workload) objectReference() *apiv1.ObjectReference {
+	return &apiv1.ObjectReference{
+		APIVersion: w.apiVersion,
+		Kind:       w.kind,
+		Namespace:  w.namespace,
+		Name:       w.name,
+		UID:        w.uid,
+	}
+}
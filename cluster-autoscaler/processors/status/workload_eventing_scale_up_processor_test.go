@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	cp_test "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+func TestWorkloadEventingScaleUpStatusProcessor(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "rs",
+		Namespace:       "default",
+		OwnerReferences: GenerateOwnerReferences("deployment", "Deployment", "apps/v1", "deployment-uid"),
+	}}
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{rs})
+	assert.NoError(t, err)
+	listerRegistry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, rsLister, nil)
+
+	var deploymentPods, jobPods []*apiv1.Pod
+	for _, name := range []string{"d1", "d2", "d3"} {
+		pod := BuildTestPod(name, 0, 0)
+		pod.OwnerReferences = GenerateOwnerReferences("rs", "ReplicaSet", "apps/v1", "rs-uid")
+		deploymentPods = append(deploymentPods, pod)
+	}
+	for _, name := range []string{"j1", "j2"} {
+		pod := BuildTestPod(name, 0, 0)
+		pod.OwnerReferences = GenerateOwnerReferences("job", "Job", "batch/v1", "job-uid")
+		jobPods = append(jobPods, pod)
+	}
+	standalone := BuildTestPod("standalone", 0, 0)
+
+	considered := []cloudprovider.NodeGroup{
+		cp_test.NewTestNodeGroup("ng1", 1, 1, 1, true, false, "", nil, nil),
+		cp_test.NewTestNodeGroup("ng2", 1, 1, 1, true, false, "", nil, nil),
+	}
+	rejected := map[string]Reasons{"ng1": &testReason{"Insufficient cpu"}}
+	skipped := map[string]Reasons{"ng2": &testReason{"max node group size reached"}}
+
+	testCases := []struct {
+		name           string
+		state          *ScaleUpStatus
+		expectedEvents []string
+	}{
+		{
+			name: "no scale-up",
+			state: &ScaleUpStatus{
+				Result:               ScaleUpNoOptionsAvailable,
+				ConsideredNodeGroups: considered,
+				PodsRemainUnschedulable: []NoScaleUpInfo{
+					{deploymentPods[0], rejected, nil},
+					{deploymentPods[1], rejected, nil},
+					{deploymentPods[2], nil, skipped},
+					{jobPods[0], rejected, skipped},
+					{standalone, rejected, nil},
+				},
+			},
+			expectedEvents: []string{
+				"Normal NotTriggerScaleUp 1 pods didn't trigger scale-up: ng1: Insufficient cpu; ng2: max node group size reached",
+				"Normal NotTriggerScaleUp 3 pods didn't trigger scale-up: ng1: Insufficient cpu; ng2: max node group size reached",
+				"Normal NotTriggerScaleUp pod didn't trigger scale-up: 1 Insufficient cpu",
+			},
+		},
+		{
+			name: "scale-up",
+			state: &ScaleUpStatus{
+				Result:               ScaleUpSuccessful,
+				ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: considered[0], CurrentSize: 1, NewSize: 3, MaxSize: 10}},
+				PodsTriggeredScaleUp: append(append([]*apiv1.Pod{standalone}, deploymentPods...), jobPods...),
+				PodsRemainUnschedulable: []NoScaleUpInfo{
+					{deploymentPods[0], rejected, nil},
+				},
+			},
+			expectedEvents: []string{
+				"Normal TriggeredScaleUp 2 pods triggered scale-up: [{ng1 1->3 (max: 10)}]",
+				"Normal TriggeredScaleUp 3 pods triggered scale-up: [{ng1 1->3 (max: 10)}]",
+				"Normal TriggeredScaleUp pod triggered scale-up: [{ng1 1->3 (max: 10)}]",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeRecorder := kube_record.NewFakeRecorder(10)
+			ctx := &context.AutoscalingContext{
+				AutoscalingKubeClients: context.AutoscalingKubeClients{
+					Recorder:       fakeRecorder,
+					ListerRegistry: listerRegistry,
+				},
+			}
+			NewWorkloadEventingScaleUpStatusProcessor().Process(ctx, tc.state)
+			close(fakeRecorder.Events)
+			var events []string
+			for event := range fakeRecorder.Events {
+				events = append(events, event)
+			}
+			sort.Strings(events)
+			assert.Equal(t, tc.expectedEvents, events)
+		})
+	}
+}
+
+func TestWorkloadForPod(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "rs",
+		Namespace:       "default",
+		OwnerReferences: GenerateOwnerReferences("deployment", "Deployment", "apps/v1", "deployment-uid"),
+	}}
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{rs})
+	assert.NoError(t, err)
+	ctx := &context.AutoscalingContext{
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ListerRegistry: kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, rsLister, nil),
+		},
+	}
+
+	deploymentPod := BuildTestPod("p1", 0, 0)
+	deploymentPod.OwnerReferences = GenerateOwnerReferences("rs", "ReplicaSet", "apps/v1", "rs-uid")
+	w, found := workloadForPod(ctx, deploymentPod)
+	assert.True(t, found)
+	assert.Equal(t, workload{apiVersion: "apps/v1", kind: "Deployment", namespace: "default", name: "deployment", uid: "deployment-uid"}, w)
+
+	orphanedPod := BuildTestPod("p2", 0, 0)
+	orphanedPod.OwnerReferences = GenerateOwnerReferences("orphaned-rs", "ReplicaSet", "apps/v1", "orphaned-rs-uid")
+	w, found = workloadForPod(ctx, orphanedPod)
+	assert.True(t, found)
+	assert.Equal(t, workload{apiVersion: "apps/v1", kind: "ReplicaSet", namespace: "default", name: "orphaned-rs", uid: "orphaned-rs-uid"}, w)
+
+	_, found = workloadForPod(ctx, BuildTestPod("p3", 0, 0))
+	assert.False(t, found)
+}
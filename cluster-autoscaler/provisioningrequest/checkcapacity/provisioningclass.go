@@ -44,7 +44,9 @@ type checkCapacityProvClass struct {
 	injector *scheduling.HintingSimulator
 }
 
-// New create check-capacity scale-up mode.
+// New create check-capacity scale-up mode. It handles both the check-capacity
+// and the check-capacity-with-hold classes, they differ only in how long the
+// found capacity is booked for.
 func New(
 	client *provreqclient.ProvisioningRequestClient,
 ) *checkCapacityProvClass {
@@ -77,7 +79,7 @@ func (o *checkCapacityProvClass) Provision(
 	if err != nil {
 		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, errors.NewAutoscalerError(errors.InternalError, err.Error()))
 	}
-	if pr.Spec.ProvisioningClassName != v1beta1.ProvisioningClassCheckCapacity {
+	if pr.Spec.ProvisioningClassName != v1beta1.ProvisioningClassCheckCapacity && pr.Spec.ProvisioningClassName != v1beta1.ProvisioningClassCheckCapacityWithHold {
 		return &status.ScaleUpStatus{Result: status.ScaleUpNotTried}, nil
 	}
 
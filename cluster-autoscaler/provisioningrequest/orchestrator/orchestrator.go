@@ -116,12 +116,22 @@ func (o *provReqOrchestrator) ScaleUpToNodeGroupMinSize(
 	return nil, nil
 }
 
+// ScaleUpForHeadroom doesn't have implementation for ProvisioningRequest Orchestrator.
+func (o *provReqOrchestrator) ScaleUpForHeadroom(
+	placeholders map[string][]*apiv1.Pod,
+	nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, ca_errors.AutoscalerError) {
+	return nil, nil
+}
+
 func (o *provReqOrchestrator) bookCapacity() error {
 	provReqs, err := o.client.ProvisioningRequests()
 	if err != nil {
 		return fmt.Errorf("couldn't fetch ProvisioningRequests in the cluster: %v", err)
 	}
 	podsToCreate := []*apiv1.Pod{}
+	var consumingPods map[string]int
 	for _, provReq := range provReqs {
 		if conditions.ShouldCapacityBeBooked(provReq) {
 			pods, err := provreq_pods.PodsForProvisioningRequest(provReq)
@@ -135,6 +145,13 @@ func (o *provReqOrchestrator) bookCapacity() error {
 				}
 				continue
 			}
+			if provReq.Spec.ProvisioningClassName == v1beta1.ProvisioningClassCheckCapacityWithHold {
+				if consumingPods == nil {
+					consumingPods = o.consumingPodCounts()
+				}
+				// Capacity is held only for the pods that weren't created yet, the created ones already use it.
+				pods = pods[min(consumingPods[provReq.Namespace+"/"+provReq.Name], len(pods)):]
+			}
 			podsToCreate = append(podsToCreate, pods...)
 		}
 	}
@@ -147,3 +164,19 @@ func (o *provReqOrchestrator) bookCapacity() error {
 	}
 	return nil
 }
+
+// consumingPodCounts returns the number of pods consuming each ProvisioningRequest, keyed by its namespace and name.
+func (o *provReqOrchestrator) consumingPodCounts() map[string]int {
+	counts := make(map[string]int)
+	pods, err := o.context.AllPodLister().List()
+	if err != nil {
+		klog.Warningf("Failed to list pods consuming ProvisioningRequests, holding all their capacity: %v", err)
+		return counts
+	}
+	for _, pod := range pods {
+		if prName, found := pod.Annotations[provreq_pods.ProvisioningRequestPodAnnotationKey]; found {
+			counts[pod.Namespace+"/"+prName]++
+		}
+	}
+	return counts
+}
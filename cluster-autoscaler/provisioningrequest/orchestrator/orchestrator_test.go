@@ -141,6 +141,28 @@ func TestScaleUp(t *testing.T) {
 		})
 	bookedCapacityProvReq.SetConditions([]metav1.Condition{{Type: v1beta1.Provisioned, Status: metav1.ConditionTrue, LastTransitionTime: metav1.Now()}})
 
+	// Check capacity request with hold - capacity should be held until pods consuming it are created.
+	// Holds 20 out of 100 high-memory nodes.
+	heldCapacityProvReq := provreqwrapper.BuildValidTestProvisioningRequestFromOptions(
+		provreqwrapper.TestProvReqOptions{
+			Name:     "heldCapacityProvReq",
+			CPU:      "1m",
+			Memory:   "200",
+			PodCount: int32(100),
+			Class:    v1beta1.ProvisioningClassCheckCapacityWithHold,
+		})
+	heldCapacityProvReq.SetConditions([]metav1.Condition{{Type: v1beta1.Provisioned, Status: metav1.ConditionTrue, LastTransitionTime: metav1.Now()}})
+	heldCapacityConsumingPods, err := pods.PodsForProvisioningRequest(heldCapacityProvReq)
+	assert.NoError(t, err)
+	newCheckCapacityWithHoldProvReq := provreqwrapper.BuildValidTestProvisioningRequestFromOptions(
+		provreqwrapper.TestProvReqOptions{
+			Name:     "newCheckCapacityWithHoldProvReq",
+			CPU:      "5m",
+			Memory:   "5",
+			PodCount: int32(100),
+			Class:    v1beta1.ProvisioningClassCheckCapacityWithHold,
+		})
+
 	// Expired provisioning request - should be ignored.
 	expiredProvReq := provreqwrapper.BuildValidTestProvisioningRequestFromOptions(
 		provreqwrapper.TestProvReqOptions{
@@ -166,6 +188,7 @@ func TestScaleUp(t *testing.T) {
 		name             string
 		provReqs         []*provreqwrapper.ProvisioningRequest
 		provReqToScaleUp *provreqwrapper.ProvisioningRequest
+		consumingPods    []*apiv1.Pod
 		scaleUpResult    status.ScaleUpResult
 		autoprovisioning bool
 		err              bool
@@ -193,6 +216,25 @@ func TestScaleUp(t *testing.T) {
 			provReqToScaleUp: newCheckCapacityMemProvReq,
 			scaleUpResult:    status.ScaleUpNoOptionsAvailable,
 		},
+		{
+			name:             "one ProvisioningRequest of check capacity with hold class",
+			provReqs:         []*provreqwrapper.ProvisioningRequest{newCheckCapacityWithHoldProvReq},
+			provReqToScaleUp: newCheckCapacityWithHoldProvReq,
+			scaleUpResult:    status.ScaleUpSuccessful,
+		},
+		{
+			name:             "capacity in the cluster is held",
+			provReqs:         []*provreqwrapper.ProvisioningRequest{newCheckCapacityMemProvReq, heldCapacityProvReq},
+			provReqToScaleUp: newCheckCapacityMemProvReq,
+			scaleUpResult:    status.ScaleUpNoOptionsAvailable,
+		},
+		{
+			name:             "held capacity is released once pods consuming it are created",
+			provReqs:         []*provreqwrapper.ProvisioningRequest{newCheckCapacityMemProvReq, heldCapacityProvReq},
+			provReqToScaleUp: newCheckCapacityMemProvReq,
+			consumingPods:    heldCapacityConsumingPods,
+			scaleUpResult:    status.ScaleUpSuccessful,
+		},
 		{
 			name:             "unsupported ProvisioningRequest is ignored",
 			provReqs:         []*provreqwrapper.ProvisioningRequest{newCheckCapacityCpuProvReq, bookedCapacityProvReq, atomicScaleUpProvReq, unsupportedProvReq},
@@ -258,7 +300,7 @@ func TestScaleUp(t *testing.T) {
 				}
 				return fmt.Errorf("unexpected scale-up of %s by %d", name, n)
 			}
-			orchestrator, nodeInfos := setupTest(t, allNodes, tc.provReqs, onScaleUpFunc, tc.autoprovisioning)
+			orchestrator, nodeInfos := setupTest(t, allNodes, tc.provReqs, tc.consumingPods, onScaleUpFunc, tc.autoprovisioning)
 
 			st, err := orchestrator.ScaleUp(prPods, []*apiv1.Node{}, []*v1.DaemonSet{}, nodeInfos, false)
 			if !tc.err {
@@ -276,7 +318,7 @@ func TestScaleUp(t *testing.T) {
 	}
 }
 
-func setupTest(t *testing.T, nodes []*apiv1.Node, prs []*provreqwrapper.ProvisioningRequest, onScaleUpFunc func(string, int) error, autoprovisioning bool) (*provReqOrchestrator, map[string]*schedulerframework.NodeInfo) {
+func setupTest(t *testing.T, nodes []*apiv1.Node, prs []*provreqwrapper.ProvisioningRequest, consumingPods []*apiv1.Pod, onScaleUpFunc func(string, int) error, autoprovisioning bool) (*provReqOrchestrator, map[string]*schedulerframework.NodeInfo) {
 	provider := testprovider.NewTestCloudProvider(onScaleUpFunc, nil)
 	if autoprovisioning {
 		machineTypes := []string{"large-machine"}
@@ -296,7 +338,7 @@ func setupTest(t *testing.T, nodes []*apiv1.Node, prs []*provreqwrapper.Provisio
 		provider.AddNode("test-cpu", n)
 	}
 
-	podLister := kube_util.NewTestPodLister(nil)
+	podLister := kube_util.NewTestPodLister(consumingPods)
 	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
 	autoscalingContext, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, &fake.Clientset{}, listers, provider, nil, nil)
 	assert.NoError(t, err)
@@ -106,3 +106,15 @@ func (o *WrapperOrchestrator) ScaleUpToNodeGroupMinSize(
 ) (*status.ScaleUpStatus, errors.AutoscalerError) {
 	return o.podsOrchestrator.ScaleUpToNodeGroupMinSize(nodes, nodeInfos)
 }
+
+// ScaleUpForHeadroom tries to scale up node groups by the number of nodes
+// needed to fit the placeholder pods reserving their headroom that don't
+// fit in the cluster. Returns appropriate status or error if an unexpected
+// error occurred.
+func (o *WrapperOrchestrator) ScaleUpForHeadroom(
+	placeholders map[string][]*apiv1.Pod,
+	nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	return o.podsOrchestrator.ScaleUpForHeadroom(placeholders, nodes, nodeInfos)
+}
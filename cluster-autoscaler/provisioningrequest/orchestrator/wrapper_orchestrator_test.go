@@ -91,3 +91,11 @@ func (f *fakeScaleUp) ScaleUpToNodeGroupMinSize(
 ) (*status.ScaleUpStatus, errors.AutoscalerError) {
 	return nil, nil
 }
+
+func (f *fakeScaleUp) ScaleUpForHeadroom(
+	placeholders map[string][]*apiv1.Pod,
+	nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	return nil, nil
+}
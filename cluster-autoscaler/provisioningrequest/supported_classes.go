@@ -17,12 +17,36 @@ limitations under the License.
 package provisioningrequest
 
 import (
+	"strconv"
+	"time"
+
 	"k8s.io/autoscaler/cluster-autoscaler/apis/provisioningrequest/autoscaling.x-k8s.io/v1beta1"
+	"k8s.io/klog/v2"
 )
 
+// HoldTTLSecondsParameter is the Parameters key of a check-capacity-with-hold
+// ProvisioningRequest setting for how many seconds the found capacity is held.
+const HoldTTLSecondsParameter = "HoldTTLSeconds"
+
 // SupportedProvisioningClasses is a set of ProvisioningRequest classes
 // supported by Cluster Autoscaler.
 var SupportedProvisioningClasses = map[string]bool{
 	v1beta1.ProvisioningClassCheckCapacity:           true,
+	v1beta1.ProvisioningClassCheckCapacityWithHold:   true,
 	v1beta1.ProvisioningClassBestEffortAtomicScaleUp: true,
 }
+
+// HoldTTL returns for how long the capacity found for a ProvisioningRequest
+// is held, or defaultTTL if the request doesn't set a valid HoldTTLSeconds parameter.
+func HoldTTL(pr *v1beta1.ProvisioningRequest, defaultTTL time.Duration) time.Duration {
+	value, found := pr.Spec.Parameters[HoldTTLSecondsParameter]
+	if !found {
+		return defaultTTL
+	}
+	seconds, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		klog.Warningf("Invalid %s parameter %q of ProvisioningRequest %s/%s, using the default hold time %v", HoldTTLSecondsParameter, value, pr.Namespace, pr.Name, defaultTTL)
+		return defaultTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
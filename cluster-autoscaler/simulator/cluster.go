@@ -89,8 +89,49 @@ const (
 	BlockedByPod
 	// UnexpectedError - node can't be removed because of an unexpected error.
 	UnexpectedError
+	// ScaleDownDeferred - node can't be removed because it wasn't empty for as long as its "scale down defer" annotation asks for.
+	ScaleDownDeferred
 )
 
+func (r UnremovableReason) String() string {
+	switch r {
+	case NoReason:
+		return "NoReason"
+	case ScaleDownDisabledAnnotation:
+		return "ScaleDownDisabledAnnotation"
+	case ScaleDownUnreadyDisabled:
+		return "ScaleDownUnreadyDisabled"
+	case NotAutoscaled:
+		return "NotAutoscaled"
+	case NotUnneededLongEnough:
+		return "NotUnneededLongEnough"
+	case NotUnreadyLongEnough:
+		return "NotUnreadyLongEnough"
+	case NodeGroupMinSizeReached:
+		return "NodeGroupMinSizeReached"
+	case MinimalResourceLimitExceeded:
+		return "MinimalResourceLimitExceeded"
+	case CurrentlyBeingDeleted:
+		return "CurrentlyBeingDeleted"
+	case NotUnderutilized:
+		return "NotUnderutilized"
+	case NotUnneededOtherReason:
+		return "NotUnneededOtherReason"
+	case RecentlyUnremovable:
+		return "RecentlyUnremovable"
+	case NoPlaceToMovePods:
+		return "NoPlaceToMovePods"
+	case BlockedByPod:
+		return "BlockedByPod"
+	case UnexpectedError:
+		return "UnexpectedError"
+	case ScaleDownDeferred:
+		return "ScaleDownDeferred"
+	default:
+		return fmt.Sprintf("unrecognized reason: %d", int(r))
+	}
+}
+
 // RemovalSimulator is a helper object for simulating node removal scenarios.
 type RemovalSimulator struct {
 	listers             kube_util.ListerRegistry
@@ -52,6 +52,11 @@ type ClusterSnapshot interface {
 	Commit() error
 	// Clear reset cluster snapshot to empty, unforked state.
 	Clear()
+	// ConcurrentFork returns a new snapshot with the current state of the snapshot. Forks can be used and modified
+	// concurrently with each other, e.g. to run simulations in parallel, but the snapshot itself must not be modified
+	// while any of its forks is in use. Changes done to a fork are never committed back to the snapshot.
+	// ConcurrentFork itself must not be called concurrently.
+	ConcurrentFork() ClusterSnapshot
 }
 
 // ErrNodeNotFound means that a node wasn't found in the snapshot.
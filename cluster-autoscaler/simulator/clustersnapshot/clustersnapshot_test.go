@@ -19,6 +19,7 @@ package clustersnapshot
 import (
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -278,6 +279,42 @@ func TestForking(t *testing.T) {
 	}
 }
 
+func TestConcurrentFork(t *testing.T) {
+	testCases := validTestCases(t)
+	for name, snapshotFactory := range snapshots {
+		for _, tc := range testCases {
+			t.Run(fmt.Sprintf("%s: %s concurrent forks", name, tc.name), func(t *testing.T) {
+				snapshot := startSnapshot(t, snapshotFactory, tc.state)
+				snapshot.Fork()
+
+				forks := make([]ClusterSnapshot, 4)
+				for i := range forks {
+					forks[i] = snapshot.ConcurrentFork()
+				}
+				var wg sync.WaitGroup
+				for _, fork := range forks {
+					wg.Add(1)
+					go func(fork ClusterSnapshot) {
+						defer wg.Done()
+						tc.op(fork)
+					}(fork)
+				}
+				wg.Wait()
+
+				// Modifications should be applied to the forks only.
+				for _, fork := range forks {
+					compareStates(t, tc.modifiedState, getSnapshotState(t, fork))
+				}
+				compareStates(t, tc.state, getSnapshotState(t, snapshot))
+
+				// Reverting the snapshot should not affect forks taken before.
+				snapshot.Revert()
+				compareStates(t, tc.modifiedState, getSnapshotState(t, forks[0]))
+			})
+		}
+	}
+}
+
 func TestClear(t *testing.T) {
 	// Run with -count=1 to avoid caching.
 	localRand := rand.New(rand.NewSource(time.Now().Unix()))
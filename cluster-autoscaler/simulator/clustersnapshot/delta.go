@@ -470,6 +470,15 @@ func (snapshot *DeltaClusterSnapshot) Commit() error {
 	return nil
 }
 
+// ConcurrentFork returns a new snapshot forked on top of the current state of the snapshot. Forks share the layers of
+// the snapshot, which are only read once their node info lists are built.
+// Time: O(n) the first time after the snapshot was modified, O(1) afterwards
+func (snapshot *DeltaClusterSnapshot) ConcurrentFork() ClusterSnapshot {
+	// The node info lists are built lazily, so they are built before forking to not be built concurrently by the forks.
+	snapshot.data.getNodeInfoList()
+	return &DeltaClusterSnapshot{data: snapshot.data.fork()}
+}
+
 // Clear reset cluster snapshot to empty, unforked state
 // Time: O(1)
 func (snapshot *DeltaClusterSnapshot) Clear() {
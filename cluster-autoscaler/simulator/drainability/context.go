@@ -19,6 +19,7 @@ package drainability
 import (
 	"time"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 )
@@ -29,3 +30,15 @@ type DrainContext struct {
 	Listers             kube_util.ListerRegistry
 	Timestamp           time.Time
 }
+
+// Namespace returns the namespace with the given name, or nil if it isn't known.
+func (c *DrainContext) Namespace(name string) *apiv1.Namespace {
+	if c == nil || c.Listers == nil || c.Listers.NamespaceLister() == nil {
+		return nil
+	}
+	namespace, err := c.Listers.NamespaceLister().Get(name)
+	if err != nil {
+		return nil
+	}
+	return namespace
+}
@@ -38,9 +38,10 @@ func (r *Rule) Name() string {
 	return "NotSafeToEvict"
 }
 
-// Drainable decides what to do with not safe to evict pods on node drain.
+// Drainable decides what to do with not safe to evict pods on node drain. Pods without
+// the safe to evict annotation default to the annotation of their namespace.
 func (Rule) Drainable(drainCtx *drainability.DrainContext, pod *apiv1.Pod, _ *framework.NodeInfo) drainability.Status {
-	if drain.HasNotSafeToEvictAnnotation(pod) {
+	if drain.IsNotSafeToEvict(pod, drainCtx.Namespace(pod.Namespace)) {
 		return drainability.NewBlockedStatus(drain.NotSafeToEvictAnnotation, fmt.Errorf("pod annotated as not safe to evict present: %s", pod.Name))
 	}
 	return drainability.NewUndefinedStatus()
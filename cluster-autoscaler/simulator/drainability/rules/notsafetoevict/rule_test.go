@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/test"
 
 	"github.com/stretchr/testify/assert"
@@ -133,9 +134,35 @@ func TestDrainable(t *testing.T) {
 			wantReason: drain.NotSafeToEvictAnnotation,
 			wantError:  true,
 		},
+		"pod in a namespace with PodSafeToEvict=false annotation": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar",
+					Namespace: "not-evictable",
+				},
+			},
+			wantReason: drain.NotSafeToEvictAnnotation,
+			wantError:  true,
+		},
+		"pod with PodSafeToEvict annotation in a namespace with PodSafeToEvict=false annotation": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar",
+					Namespace: "not-evictable",
+					Annotations: map[string]string{
+						drain.PodSafeToEvictKey: "true",
+					},
+				},
+			},
+		},
 	} {
 		t.Run(desc, func(t *testing.T) {
+			namespaceLister, err := kube_util.NewTestNamespaceLister([]*apiv1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "not-evictable", Annotations: map[string]string{drain.PodSafeToEvictKey: "false"}}},
+			})
+			assert.NoError(t, err)
 			drainCtx := &drainability.DrainContext{
+				Listers:   kube_util.NewListerRegistryWithNamespaceLister(nil, nil, nil, nil, nil, nil, nil, nil, nil, namespaceLister),
 				Timestamp: testTime,
 			}
 			status := New().Drainable(drainCtx, test.pod, nil)
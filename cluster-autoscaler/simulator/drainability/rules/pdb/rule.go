@@ -41,7 +41,7 @@ func (r *Rule) Name() string {
 // Drainable decides how to handle pods with pdbs on node drain.
 func (Rule) Drainable(drainCtx *drainability.DrainContext, pod *apiv1.Pod, _ *framework.NodeInfo) drainability.Status {
 	for _, pdb := range drainCtx.RemainingPdbTracker.MatchingPdbs(pod) {
-		if pdb.Status.DisruptionsAllowed < 1 {
+		if pdb.Status.DisruptionsAllowed < 1 && !drain.IsUnhealthyPodEvictionAllowed(pod, pdb) {
 			return drainability.NewBlockedStatus(drain.NotEnoughPdb, fmt.Errorf("not enough pod disruption budget to move %s/%s", pod.Namespace, pod.Name))
 		}
 	}
@@ -32,6 +32,21 @@ import (
 
 func TestDrainable(t *testing.T) {
 	one := intstr.FromInt(1)
+	alwaysAllow := policyv1.AlwaysAllow
+	alwaysAllowPdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "good",
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &one,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"label": "true",
+				},
+			},
+			UnhealthyPodEvictionPolicy: &alwaysAllow,
+		},
+	}
 
 	for desc, tc := range map[string]struct {
 		pod         *apiv1.Pod
@@ -135,6 +150,38 @@ func TestDrainable(t *testing.T) {
 			wantOutcome: drainability.BlockDrain,
 			wantReason:  drain.NotEnoughPdb,
 		},
+		"pdb always allowing eviction of unhealthy pods doesn't prevent scale-down": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crashlooping",
+					Namespace: "good",
+					Labels: map[string]string{
+						"label": "true",
+					},
+				},
+				Status: apiv1.PodStatus{
+					Conditions: []apiv1.PodCondition{{Type: apiv1.PodReady, Status: apiv1.ConditionFalse}},
+				},
+			},
+			pdbs: []*policyv1.PodDisruptionBudget{alwaysAllowPdb},
+		},
+		"pdb always allowing eviction of unhealthy pods prevents scale-down of healthy pods": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "healthy",
+					Namespace: "good",
+					Labels: map[string]string{
+						"label": "true",
+					},
+				},
+				Status: apiv1.PodStatus{
+					Conditions: []apiv1.PodCondition{{Type: apiv1.PodReady, Status: apiv1.ConditionTrue}},
+				},
+			},
+			pdbs:        []*policyv1.PodDisruptionBudget{alwaysAllowPdb},
+			wantOutcome: drainability.BlockDrain,
+			wantReason:  drain.NotEnoughPdb,
+		},
 	} {
 		t.Run(desc, func(t *testing.T) {
 			tracker := pdb.NewBasicRemainingPdbTracker()
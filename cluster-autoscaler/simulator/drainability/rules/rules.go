@@ -21,6 +21,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/daemonset"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/headroom"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/localstorage"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/longterminating"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/mirror"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/safetoevict"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/system"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/terminal"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/webhook"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -55,8 +57,10 @@ func Default(deleteOptions options.NodeDeleteOptions) Rules {
 		skip bool
 	}{
 		{rule: mirror.New()},
+		{rule: headroom.New()},
 		{rule: longterminating.New()},
 		{rule: replicacount.New(deleteOptions.MinReplicaCount), skip: !deleteOptions.SkipNodesWithCustomControllerPods},
+		{rule: webhook.New(deleteOptions.DrainabilityWebhookURL, deleteOptions.DrainabilityWebhookTimeout, deleteOptions.DrainabilityWebhookCacheTTL, deleteOptions.DrainabilityWebhookFailClosed), skip: deleteOptions.DrainabilityWebhookURL == ""},
 
 		// Interrupting checks
 		{rule: daemonset.New()},
@@ -36,9 +36,10 @@ func (r *Rule) Name() string {
 	return "SafeToEvict"
 }
 
-// Drainable decides what to do with safe to evict pods on node drain.
+// Drainable decides what to do with safe to evict pods on node drain. Pods without
+// the safe to evict annotation default to the annotation of their namespace.
 func (r *Rule) Drainable(drainCtx *drainability.DrainContext, pod *apiv1.Pod, _ *framework.NodeInfo) drainability.Status {
-	if drain.HasSafeToEvictAnnotation(pod) {
+	if drain.IsSafeToEvict(pod, drainCtx.Namespace(pod.Namespace)) {
 		return drainability.NewDrainableStatus()
 	}
 	return drainability.NewUndefinedStatus()
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 )
 
 func TestDrainable(t *testing.T) {
@@ -52,9 +53,39 @@ func TestDrainable(t *testing.T) {
 			},
 			want: drainability.NewDrainableStatus(),
 		},
+		"pod in a safe to evict namespace": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar",
+					Namespace: "ci",
+				},
+			},
+			want: drainability.NewDrainableStatus(),
+		},
+		"not safe to evict pod in a safe to evict namespace": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar",
+					Namespace: "ci",
+					Annotations: map[string]string{
+						drain.PodSafeToEvictKey: "false",
+					},
+				},
+			},
+			want: drainability.NewUndefinedStatus(),
+		},
 	} {
 		t.Run(desc, func(t *testing.T) {
-			got := New().Drainable(nil, tc.pod, nil)
+			namespaceLister, err := kube_util.NewTestNamespaceLister([]*apiv1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ci", Annotations: map[string]string{drain.PodSafeToEvictKey: "true"}}},
+			})
+			if err != nil {
+				t.Fatalf("NewTestNamespaceLister() unexpected error: %v", err)
+			}
+			drainCtx := &drainability.DrainContext{
+				Listers: kube_util.NewListerRegistryWithNamespaceLister(nil, nil, nil, nil, nil, nil, nil, nil, nil, namespaceLister),
+			}
+			got := New().Drainable(drainCtx, tc.pod, nil)
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("Rule.Drainable(%v): got status diff (-want +got):\n%s", tc.pod.Name, diff)
 			}
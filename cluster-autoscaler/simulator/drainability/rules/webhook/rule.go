@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Decision is the drainability decision of the webhook for a single pod.
+type Decision string
+
+const (
+	// NoDecision means the webhook has no opinion on the pod, the remaining rules decide.
+	NoDecision Decision = ""
+	// DrainDecision means the pod can be drained.
+	DrainDecision Decision = "drain"
+	// BlockDecision means the pod blocks drain of its entire node.
+	BlockDecision Decision = "block"
+	// SkipDecision means the pod doesn't block drain of its node, but should not be drained itself.
+	SkipDecision Decision = "skip"
+)
+
+// Request is the body POSTed to the webhook for a pod of a node considered for scale down.
+type Request struct {
+	// Pod is the pod to be drained.
+	Pod *apiv1.Pod `json:"pod"`
+	// NodeName is the name of the node the pod is running on.
+	NodeName string `json:"nodeName"`
+	// NodeLabels are the labels of the node the pod is running on.
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+}
+
+// Response is the body the webhook replies with.
+type Response struct {
+	// Decision is the drainability decision for the pod.
+	Decision Decision `json:"decision"`
+	// Reason explains a block decision, it is reported in scale down status and logs.
+	Reason string `json:"reason,omitempty"`
+}
+
+type cachedResponse struct {
+	response  Response
+	expiresAt time.Time
+}
+
+// Rule is a drainability rule consulting an external webhook on whether pods
+// can be drained, so that custom "do not evict" policies can be enforced
+// without changes to the autoscaler.
+type Rule struct {
+	url        string
+	client     *http.Client
+	cacheTTL   time.Duration
+	failClosed bool
+
+	mu    sync.Mutex
+	cache map[string]cachedResponse
+	now   func() time.Time
+}
+
+// New creates a new Rule. Responses of the webhook are cached per pod for
+// cacheTTL. If failClosed is true, pods block drain of their node when the
+// webhook cannot be reached or replies with an invalid response, otherwise
+// the remaining rules decide.
+func New(url string, timeout, cacheTTL time.Duration, failClosed bool) *Rule {
+	return &Rule{
+		url:        url,
+		client:     &http.Client{Timeout: timeout},
+		cacheTTL:   cacheTTL,
+		failClosed: failClosed,
+		cache:      make(map[string]cachedResponse),
+		now:        time.Now,
+	}
+}
+
+// Name returns the name of the rule.
+func (r *Rule) Name() string {
+	return "Webhook"
+}
+
+// Drainable decides what to do with pods on node drain based on the response of the webhook.
+func (r *Rule) Drainable(_ *drainability.DrainContext, pod *apiv1.Pod, nodeInfo *framework.NodeInfo) drainability.Status {
+	resp, err := r.response(pod, nodeInfo)
+	if err != nil {
+		if r.failClosed {
+			return drainability.NewBlockedStatus(drain.UnexpectedError, fmt.Errorf("drainability webhook failed for pod %s/%s: %v", pod.Namespace, pod.Name, err))
+		}
+		klog.Warningf("Drainability webhook failed for pod %s/%s, ignoring it: %v", pod.Namespace, pod.Name, err)
+		return drainability.NewUndefinedStatus()
+	}
+	switch resp.Decision {
+	case DrainDecision:
+		return drainability.NewDrainableStatus()
+	case BlockDecision:
+		return drainability.NewBlockedStatus(drain.BlockedByWebhook, fmt.Errorf("pod %s/%s blocked by drainability webhook: %s", pod.Namespace, pod.Name, resp.Reason))
+	case SkipDecision:
+		return drainability.NewSkipStatus()
+	}
+	return drainability.NewUndefinedStatus()
+}
+
+func (r *Rule) response(pod *apiv1.Pod, nodeInfo *framework.NodeInfo) (Response, error) {
+	key := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, pod.UID)
+	now := r.now()
+
+	r.mu.Lock()
+	cached, found := r.cache[key]
+	r.mu.Unlock()
+	if found && now.Before(cached.expiresAt) {
+		return cached.response, nil
+	}
+
+	resp, err := r.call(pod, nodeInfo)
+	if err != nil {
+		return Response{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, c := range r.cache {
+		if !now.Before(c.expiresAt) {
+			delete(r.cache, k)
+		}
+	}
+	r.cache[key] = cachedResponse{response: resp, expiresAt: now.Add(r.cacheTTL)}
+	return resp, nil
+}
+
+func (r *Rule) call(pod *apiv1.Pod, nodeInfo *framework.NodeInfo) (Response, error) {
+	req := Request{Pod: pod, NodeName: pod.Spec.NodeName}
+	if nodeInfo != nil && nodeInfo.Node() != nil {
+		req.NodeName = nodeInfo.Node().Name
+		req.NodeLabels = nodeInfo.Node().Labels
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+	httpResp, err := r.client.Post(r.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("unexpected status %s", httpResp.Status)
+	}
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return Response{}, err
+	}
+	var resp Response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return Response{}, err
+	}
+	switch resp.Decision {
+	case NoDecision, DrainDecision, BlockDecision, SkipDecision:
+		return resp, nil
+	}
+	return Response{}, fmt.Errorf("unknown decision %q", resp.Decision)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestDrainable(t *testing.T) {
+	for desc, tc := range map[string]struct {
+		status      int
+		body        string
+		failClosed  bool
+		wantOutcome drainability.OutcomeType
+		wantReason  drain.BlockingPodReason
+	}{
+		"no decision": {
+			status:      http.StatusOK,
+			body:        `{}`,
+			wantOutcome: drainability.UndefinedOutcome,
+		},
+		"drain": {
+			status:      http.StatusOK,
+			body:        `{"decision": "drain"}`,
+			wantOutcome: drainability.DrainOk,
+		},
+		"block": {
+			status:      http.StatusOK,
+			body:        `{"decision": "block", "reason": "license checked out"}`,
+			wantOutcome: drainability.BlockDrain,
+			wantReason:  drain.BlockedByWebhook,
+		},
+		"skip": {
+			status:      http.StatusOK,
+			body:        `{"decision": "skip"}`,
+			wantOutcome: drainability.SkipDrain,
+		},
+		"unknown decision is ignored": {
+			status:      http.StatusOK,
+			body:        `{"decision": "maybe"}`,
+			wantOutcome: drainability.UndefinedOutcome,
+		},
+		"error is ignored": {
+			status:      http.StatusInternalServerError,
+			wantOutcome: drainability.UndefinedOutcome,
+		},
+		"error blocks drain when failing closed": {
+			status:      http.StatusInternalServerError,
+			failClosed:  true,
+			wantOutcome: drainability.BlockDrain,
+			wantReason:  drain.UnexpectedError,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			node := BuildTestNode("n1", 1000, 1000)
+			node.Labels = map[string]string{"pool": "batch"}
+			pod := BuildTestPod("p1", 100, 100)
+			pod.Namespace = "ns"
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req Request
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "p1", req.Pod.Name)
+				assert.Equal(t, "n1", req.NodeName)
+				assert.Equal(t, node.Labels, req.NodeLabels)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			nodeInfo := framework.NewNodeInfo(pod)
+			nodeInfo.SetNode(node)
+			status := New(server.URL, time.Second, time.Minute, tc.failClosed).Drainable(nil, pod, nodeInfo)
+			assert.Equal(t, tc.wantOutcome, status.Outcome)
+			assert.Equal(t, tc.wantReason, status.BlockingReason)
+		})
+	}
+}
+
+func TestDrainableCache(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"decision": "block"}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	r := New(server.URL, time.Second, time.Minute, false)
+	r.now = func() time.Time { return now }
+
+	p1 := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns", UID: "uid1"}}
+	p2 := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "ns", UID: "uid2"}}
+
+	r.Drainable(nil, p1, nil)
+	r.Drainable(nil, p1, nil)
+	assert.Equal(t, 1, calls)
+
+	r.Drainable(nil, p2, nil)
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(2 * time.Minute)
+	status := r.Drainable(nil, p1, nil)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, drainability.BlockDrain, status.Outcome)
+}
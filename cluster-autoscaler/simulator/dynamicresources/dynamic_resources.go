@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicresources
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	resourcelisters "k8s.io/client-go/listers/resource/v1alpha2"
+	klog "k8s.io/klog/v2"
+)
+
+// ResourcePrefix prefixes the extended resources that stand for the devices of DRA drivers
+// in the scheduling simulation.
+const ResourcePrefix = "dra.autoscaling.x-k8s.io/"
+
+// ResourceName returns the extended resource standing for the devices of the given DRA driver.
+// Node group templates built by cloud providers advertise it to allow scale-up from zero.
+func ResourceName(driverName string) apiv1.ResourceName {
+	return apiv1.ResourceName(ResourcePrefix + driverName)
+}
+
+// Provider lists the DRA objects from the informers.
+type Provider struct {
+	claims    resourcelisters.ResourceClaimLister
+	templates resourcelisters.ResourceClaimTemplateLister
+	classes   resourcelisters.ResourceClassLister
+	slices    resourcelisters.ResourceSliceLister
+}
+
+// NewProviderFromInformers returns a Provider using the listers of the given informer factory.
+// The informers are registered in the factory, so it has to be started afterwards.
+func NewProviderFromInformers(informerFactory informers.SharedInformerFactory) *Provider {
+	resourceInformers := informerFactory.Resource().V1alpha2()
+	return &Provider{
+		claims:    resourceInformers.ResourceClaims().Lister(),
+		templates: resourceInformers.ResourceClaimTemplates().Lister(),
+		classes:   resourceInformers.ResourceClasses().Lister(),
+		slices:    resourceInformers.ResourceSlices().Lister(),
+	}
+}
+
+// Snapshot lists the DRA objects currently in the cluster.
+func (p *Provider) Snapshot() (*Snapshot, error) {
+	claims, err := p.claims.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource claims: %v", err)
+	}
+	templates, err := p.templates.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource claim templates: %v", err)
+	}
+	classes, err := p.classes.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource classes: %v", err)
+	}
+	slices, err := p.slices.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource slices: %v", err)
+	}
+	return NewSnapshot(claims, templates, classes, slices), nil
+}
+
+// Snapshot translates the DRA objects into extended resources the scheduling simulation understands.
+// Every claim of a pod requests one device of the driver of its resource class, and every named
+// resource instance published by a driver in the ResourceSlices of a node is one allocatable device.
+type Snapshot struct {
+	claims       map[string]*resourceapi.ResourceClaim
+	templates    map[string]*resourceapi.ResourceClaimTemplate
+	classDrivers map[string]string
+	nodeDevices  map[string]map[string]int64
+	// nodes caches the translated nodes, so that all node lists refer to the same copies.
+	nodes map[string]*apiv1.Node
+}
+
+// NewSnapshot returns a Snapshot of the given DRA objects.
+func NewSnapshot(claims []*resourceapi.ResourceClaim, templates []*resourceapi.ResourceClaimTemplate,
+	classes []*resourceapi.ResourceClass, slices []*resourceapi.ResourceSlice) *Snapshot {
+	s := &Snapshot{
+		claims:       make(map[string]*resourceapi.ResourceClaim),
+		templates:    make(map[string]*resourceapi.ResourceClaimTemplate),
+		classDrivers: make(map[string]string),
+		nodeDevices:  make(map[string]map[string]int64),
+		nodes:        make(map[string]*apiv1.Node),
+	}
+	for _, claim := range claims {
+		s.claims[claim.Namespace+"/"+claim.Name] = claim
+	}
+	for _, template := range templates {
+		s.templates[template.Namespace+"/"+template.Name] = template
+	}
+	for _, class := range classes {
+		s.classDrivers[class.Name] = class.DriverName
+	}
+	for _, slice := range slices {
+		if slice.NodeName == "" || slice.NamedResources == nil {
+			continue
+		}
+		if s.nodeDevices[slice.NodeName] == nil {
+			s.nodeDevices[slice.NodeName] = make(map[string]int64)
+		}
+		s.nodeDevices[slice.NodeName][slice.DriverName] += int64(len(slice.NamedResources.Instances))
+	}
+	return s
+}
+
+// Pods returns the pods with the devices of their resource claims added to the requests.
+// Pods without resource claims are returned as they are.
+func (s *Snapshot) Pods(pods []*apiv1.Pod) []*apiv1.Pod {
+	result := make([]*apiv1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		result = append(result, s.pod(pod))
+	}
+	return result
+}
+
+func (s *Snapshot) pod(pod *apiv1.Pod) *apiv1.Pod {
+	if len(pod.Spec.ResourceClaims) == 0 || len(pod.Spec.Containers) == 0 {
+		return pod
+	}
+	devices := make(map[string]int64)
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		className, found := s.claimClassName(pod, podClaim)
+		if !found {
+			klog.V(4).Infof("Resource claim %s of pod %s/%s not found, ignoring it in the simulation", podClaim.Name, pod.Namespace, pod.Name)
+			continue
+		}
+		driverName, found := s.classDrivers[className]
+		if !found {
+			klog.V(4).Infof("Resource class %s of pod %s/%s not found, ignoring it in the simulation", className, pod.Namespace, pod.Name)
+			continue
+		}
+		devices[driverName]++
+	}
+	if len(devices) == 0 {
+		return pod
+	}
+	podCopy := pod.DeepCopy()
+	container := &podCopy.Spec.Containers[0]
+	if container.Resources.Requests == nil {
+		container.Resources.Requests = apiv1.ResourceList{}
+	}
+	for driverName, count := range devices {
+		container.Resources.Requests[ResourceName(driverName)] = *resource.NewQuantity(count, resource.DecimalSI)
+	}
+	return podCopy
+}
+
+// claimClassName returns the resource class of a claim of the pod. Claims created from
+// a template that were not generated yet use the resource class of the template.
+func (s *Snapshot) claimClassName(pod *apiv1.Pod, podClaim apiv1.PodResourceClaim) (string, bool) {
+	claimName := podClaim.Source.ResourceClaimName
+	if podClaim.Source.ResourceClaimTemplateName != nil {
+		for _, status := range pod.Status.ResourceClaimStatuses {
+			if status.Name == podClaim.Name {
+				claimName = status.ResourceClaimName
+			}
+		}
+		if claimName == nil {
+			template, found := s.templates[pod.Namespace+"/"+*podClaim.Source.ResourceClaimTemplateName]
+			if !found {
+				return "", false
+			}
+			return template.Spec.Spec.ResourceClassName, true
+		}
+	}
+	if claimName == nil {
+		return "", false
+	}
+	claim, found := s.claims[pod.Namespace+"/"+*claimName]
+	if !found {
+		return "", false
+	}
+	return claim.Spec.ResourceClassName, true
+}
+
+// Nodes returns the nodes with the devices published in their ResourceSlices added to the
+// capacity and allocatable resources. Calls with the same node return the same copy.
+func (s *Snapshot) Nodes(nodes []*apiv1.Node) []*apiv1.Node {
+	result := make([]*apiv1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, s.node(node))
+	}
+	return result
+}
+
+func (s *Snapshot) node(node *apiv1.Node) *apiv1.Node {
+	devices, found := s.nodeDevices[node.Name]
+	if !found {
+		return node
+	}
+	if nodeCopy, found := s.nodes[node.Name]; found {
+		return nodeCopy
+	}
+	nodeCopy := node.DeepCopy()
+	if nodeCopy.Status.Capacity == nil {
+		nodeCopy.Status.Capacity = apiv1.ResourceList{}
+	}
+	if nodeCopy.Status.Allocatable == nil {
+		nodeCopy.Status.Allocatable = apiv1.ResourceList{}
+	}
+	for driverName, count := range devices {
+		nodeCopy.Status.Capacity[ResourceName(driverName)] = *resource.NewQuantity(count, resource.DecimalSI)
+		nodeCopy.Status.Allocatable[ResourceName(driverName)] = *resource.NewQuantity(count, resource.DecimalSI)
+	}
+	s.nodes[node.Name] = nodeCopy
+	return nodeCopy
+}
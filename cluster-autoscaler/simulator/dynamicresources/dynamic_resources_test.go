@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicresources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+const gpuDriver = "gpu.example.com"
+
+func testSnapshot() *Snapshot {
+	claims := []*resourceapi.ResourceClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "shared-gpu", Namespace: "default"}, Spec: resourceapi.ResourceClaimSpec{ResourceClassName: "gpu"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p3-gpu", Namespace: "default"}, Spec: resourceapi.ResourceClaimSpec{ResourceClassName: "gpu"}},
+	}
+	templates := []*resourceapi.ResourceClaimTemplate{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-template", Namespace: "default"},
+			Spec:       resourceapi.ResourceClaimTemplateSpec{Spec: resourceapi.ResourceClaimSpec{ResourceClassName: "gpu"}},
+		},
+	}
+	classes := []*resourceapi.ResourceClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}, DriverName: gpuDriver},
+	}
+	slices := []*resourceapi.ResourceSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1-gpu"},
+			NodeName:   "n1",
+			DriverName: gpuDriver,
+			ResourceModel: resourceapi.ResourceModel{NamedResources: &resourceapi.NamedResourcesResources{
+				Instances: []resourceapi.NamedResourcesInstance{{Name: "gpu-0"}, {Name: "gpu-1"}},
+			}},
+		},
+	}
+	return NewSnapshot(claims, templates, classes, slices)
+}
+
+func podWithClaims(name string, claims ...apiv1.PodResourceClaim) *apiv1.Pod {
+	pod := BuildTestPod(name, 100, 0)
+	pod.Spec.ResourceClaims = claims
+	return pod
+}
+
+func TestSnapshotPods(t *testing.T) {
+	claimName := func(name string) *string { return &name }
+	generated := podWithClaims("p3", apiv1.PodResourceClaim{Name: "gpu", Source: apiv1.ClaimSource{ResourceClaimTemplateName: claimName("gpu-template")}})
+	generated.Status.ResourceClaimStatuses = []apiv1.PodResourceClaimStatus{{Name: "gpu", ResourceClaimName: claimName("p3-gpu")}}
+
+	testCases := []struct {
+		name        string
+		pod         *apiv1.Pod
+		wantDevices int64
+	}{
+		{
+			name: "no claims",
+			pod:  BuildTestPod("p1", 100, 0),
+		},
+		{
+			name:        "existing claim",
+			pod:         podWithClaims("p1", apiv1.PodResourceClaim{Name: "gpu", Source: apiv1.ClaimSource{ResourceClaimName: claimName("shared-gpu")}}),
+			wantDevices: 1,
+		},
+		{
+			name: "claims from template not generated yet",
+			pod: podWithClaims("p2",
+				apiv1.PodResourceClaim{Name: "gpu-a", Source: apiv1.ClaimSource{ResourceClaimTemplateName: claimName("gpu-template")}},
+				apiv1.PodResourceClaim{Name: "gpu-b", Source: apiv1.ClaimSource{ResourceClaimTemplateName: claimName("gpu-template")}}),
+			wantDevices: 2,
+		},
+		{
+			name:        "claim generated from template",
+			pod:         generated,
+			wantDevices: 1,
+		},
+		{
+			name:        "unknown claim",
+			pod:         podWithClaims("p4", apiv1.PodResourceClaim{Name: "gpu", Source: apiv1.ClaimSource{ResourceClaimName: claimName("missing")}}),
+			wantDevices: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pods := testSnapshot().Pods([]*apiv1.Pod{tc.pod})
+			assert.Equal(t, 1, len(pods))
+			requests := pods[0].Spec.Containers[0].Resources.Requests
+			if tc.wantDevices == 0 {
+				assert.Same(t, tc.pod, pods[0])
+				return
+			}
+			devices := requests[ResourceName(gpuDriver)]
+			assert.Equal(t, tc.wantDevices, devices.Value())
+			_, found := tc.pod.Spec.Containers[0].Resources.Requests[ResourceName(gpuDriver)]
+			assert.False(t, found, "original pod modified")
+		})
+	}
+}
+
+func TestSnapshotNodes(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	s := testSnapshot()
+
+	allNodes := s.Nodes([]*apiv1.Node{n1, n2})
+	readyNodes := s.Nodes([]*apiv1.Node{n1})
+
+	assert.Same(t, allNodes[0], readyNodes[0])
+	assert.Same(t, n2, allNodes[1])
+	allocatable := allNodes[0].Status.Allocatable[ResourceName(gpuDriver)]
+	assert.Equal(t, int64(2), allocatable.Value())
+	capacity := allNodes[0].Status.Capacity[ResourceName(gpuDriver)]
+	assert.Equal(t, int64(2), capacity.Value())
+	_, found := n1.Status.Allocatable[ResourceName(gpuDriver)]
+	assert.False(t, found, "original node modified")
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 )
 
@@ -35,6 +37,16 @@ type NodeDeleteOptions struct {
 	// set or replication controller should have to allow pod deletion during
 	// scale down.
 	MinReplicaCount int
+	// DrainabilityWebhookURL is the URL of a webhook consulted on whether pods
+	// can be drained. The webhook isn't used if it is empty.
+	DrainabilityWebhookURL string
+	// DrainabilityWebhookTimeout is the timeout of a single webhook call.
+	DrainabilityWebhookTimeout time.Duration
+	// DrainabilityWebhookCacheTTL is how long webhook responses are cached per pod.
+	DrainabilityWebhookCacheTTL time.Duration
+	// DrainabilityWebhookFailClosed is true if pods should block drain of their
+	// node when the webhook fails.
+	DrainabilityWebhookFailClosed bool
 }
 
 // NewNodeDeleteOptions returns new node delete options extracted from autoscaling options.
@@ -44,5 +56,9 @@ func NewNodeDeleteOptions(opts config.AutoscalingOptions) NodeDeleteOptions {
 		SkipNodesWithLocalStorage:         opts.SkipNodesWithLocalStorage,
 		SkipNodesWithCustomControllerPods: opts.SkipNodesWithCustomControllerPods,
 		MinReplicaCount:                   opts.MinReplicaCount,
+		DrainabilityWebhookURL:            opts.DrainabilityWebhookURL,
+		DrainabilityWebhookTimeout:        opts.DrainabilityWebhookTimeout,
+		DrainabilityWebhookCacheTTL:       opts.DrainabilityWebhookCacheTTL,
+		DrainabilityWebhookFailClosed:     opts.DrainabilityWebhookFailClosed,
 	}
 }
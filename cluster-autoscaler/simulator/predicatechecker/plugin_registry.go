@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicatechecker
+
+import (
+	"sync"
+
+	scheduler_plugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
+	schedulerframeworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+var (
+	outOfTreePluginsLock sync.Mutex
+	outOfTreePlugins     = schedulerframeworkruntime.Registry{}
+)
+
+// RegisterPlugin registers a scheduler plugin compiled into Cluster Autoscaler under the given name,
+// replacing the one registered before if any. Like in-tree plugins, it runs in the simulations if it's
+// enabled by the profiles of the scheduler config. The name can't be the one of an in-tree plugin.
+func RegisterPlugin(name string, factory schedulerframeworkruntime.PluginFactory) {
+	outOfTreePluginsLock.Lock()
+	defer outOfTreePluginsLock.Unlock()
+	outOfTreePlugins[name] = factory
+}
+
+// pluginRegistry returns the in-tree scheduler plugins and the registered ones.
+func pluginRegistry() (schedulerframeworkruntime.Registry, error) {
+	outOfTreePluginsLock.Lock()
+	defer outOfTreePluginsLock.Unlock()
+	registry := scheduler_plugins.NewInTreeRegistry()
+	if err := registry.Merge(outOfTreePlugins); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicatechecker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testconfig "k8s.io/autoscaler/cluster-autoscaler/config/test"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	scheduler "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// testCustomPlugin rejects pods with the "reject" label.
+type testCustomPlugin struct{}
+
+func (p *testCustomPlugin) Name() string {
+	return "TestCustomPlugin"
+}
+
+func (p *testCustomPlugin) Filter(_ context.Context, _ *schedulerframework.CycleState, pod *apiv1.Pod, _ *schedulerframework.NodeInfo) *schedulerframework.Status {
+	if _, found := pod.Labels["reject"]; found {
+		return schedulerframework.NewStatus(schedulerframework.Unschedulable, "rejected")
+	}
+	return nil
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	RegisterPlugin("TestCustomPlugin", func(context.Context, runtime.Object, schedulerframework.Handle) (schedulerframework.Plugin, error) {
+		return &testCustomPlugin{}, nil
+	})
+
+	tmpDir, err := os.MkdirTemp("", "scheduler-configs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	configFile := filepath.Join(tmpDir, "custom_plugin_config.yaml")
+	if err := os.WriteFile(configFile,
+		[]byte(testconfig.SchedulerConfigCustomPluginEnabled),
+		os.FileMode(0600)); err != nil {
+		t.Fatal(err)
+	}
+	schedConfig, err := scheduler.ConfigFromPath(configFile)
+	assert.NoError(t, err)
+	predicateChecker, err := NewTestPredicateCheckerWithCustomConfig(schedConfig)
+	assert.NoError(t, err)
+
+	n1000 := BuildTestNode("n1000", 1000, 2000000)
+	SetNodeReadyState(n1000, true, time.Time{})
+	clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
+	assert.NoError(t, clusterSnapshot.AddNode(n1000))
+
+	accepted := BuildTestPod("accepted", 100, 1000)
+	assert.Nil(t, predicateChecker.CheckPredicates(clusterSnapshot, accepted, "n1000"))
+
+	rejected := BuildTestPod("rejected", 100, 1000)
+	rejected.Labels = map[string]string{"reject": "true"}
+	predicateError := predicateChecker.CheckPredicates(clusterSnapshot, rejected, "n1000")
+	if assert.NotNil(t, predicateError) {
+		assert.Equal(t, "TestCustomPlugin", predicateError.PredicateName())
+	}
+}
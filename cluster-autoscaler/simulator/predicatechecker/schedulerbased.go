@@ -29,22 +29,37 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
 	scheduler_config "k8s.io/kubernetes/pkg/scheduler/apis/config/latest"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
-	scheduler_plugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
 	schedulerframeworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 )
 
+// maxIdleFrameworks is the maximum number of idle scheduler frameworks kept by a SchedulerBasedPredicateChecker
+// for concurrent checks.
+const maxIdleFrameworks = 64
+
 // SchedulerBasedPredicateChecker checks whether all required predicates pass for given Pod and Node.
-// The verification is done by calling out to scheduler code.
+// The verification is done by calling out to scheduler code. Checks can be run concurrently, each concurrent
+// check runs in a scheduler framework of its own.
 type SchedulerBasedPredicateChecker struct {
+	informerFactory informers.SharedInformerFactory
+	profile         *config.KubeSchedulerProfile
+	frameworks      chan *schedulerFramework
+	nodeLister      v1listers.NodeLister
+	podLister       v1listers.PodLister
+	// schedulerCheckers run the checks of pods of the schedulers simulated with other profiles than the default one.
+	schedulerCheckers map[string]*SchedulerBasedPredicateChecker
+}
+
+// schedulerFramework is a scheduler framework running the checks against the snapshot delegated to by its lister.
+type schedulerFramework struct {
 	framework              schedulerframework.Framework
 	delegatingSharedLister *DelegatingSchedulerSharedLister
-	nodeLister             v1listers.NodeLister
-	podLister              v1listers.PodLister
 	lastIndex              int
 }
 
-// NewSchedulerBasedPredicateChecker builds scheduler based PredicateChecker.
-func NewSchedulerBasedPredicateChecker(informerFactory informers.SharedInformerFactory, schedConfig *config.KubeSchedulerConfiguration) (*SchedulerBasedPredicateChecker, error) {
+// NewSchedulerBasedPredicateChecker builds scheduler based PredicateChecker. Pods are simulated with the first
+// profile of the scheduler config, unless their scheduler is the one of another profile or schedulerProfiles maps
+// their scheduler to the scheduler name of another profile.
+func NewSchedulerBasedPredicateChecker(informerFactory informers.SharedInformerFactory, schedConfig *config.KubeSchedulerConfiguration, schedulerProfiles map[string]string) (*SchedulerBasedPredicateChecker, error) {
 	if schedConfig == nil {
 		var err error
 		schedConfig, err = scheduler_config.Default()
@@ -53,29 +68,99 @@ func NewSchedulerBasedPredicateChecker(informerFactory informers.SharedInformerF
 		}
 	}
 
-	if len(schedConfig.Profiles) != 1 {
-		return nil, fmt.Errorf("unexpected scheduler config: expected one scheduler profile only (found %d profiles)", len(schedConfig.Profiles))
+	if len(schedConfig.Profiles) == 0 {
+		return nil, fmt.Errorf("unexpected scheduler config: expected at least one scheduler profile")
+	}
+
+	checker, err := newProfileChecker(informerFactory, &schedConfig.Profiles[0])
+	if err != nil {
+		return nil, err
 	}
+	profileCheckers := make(map[string]*SchedulerBasedPredicateChecker)
+	for i := range schedConfig.Profiles[1:] {
+		profile := &schedConfig.Profiles[i+1]
+		profileChecker, err := newProfileChecker(informerFactory, profile)
+		if err != nil {
+			return nil, err
+		}
+		profileCheckers[profile.SchedulerName] = profileChecker
+		checker.schedulerCheckers[profile.SchedulerName] = profileChecker
+	}
+	for schedulerName, profileName := range schedulerProfiles {
+		if profileName == "" || profileName == checker.profile.SchedulerName {
+			continue
+		}
+		profileChecker, found := profileCheckers[profileName]
+		if !found {
+			return nil, fmt.Errorf("unknown scheduler profile %s of scheduler %s", profileName, schedulerName)
+		}
+		checker.schedulerCheckers[schedulerName] = profileChecker
+	}
+	return checker, nil
+}
+
+func newProfileChecker(informerFactory informers.SharedInformerFactory, profile *config.KubeSchedulerProfile) (*SchedulerBasedPredicateChecker, error) {
+	checker := &SchedulerBasedPredicateChecker{
+		informerFactory:   informerFactory,
+		profile:           profile,
+		frameworks:        make(chan *schedulerFramework, maxIdleFrameworks),
+		schedulerCheckers: make(map[string]*SchedulerBasedPredicateChecker),
+	}
+	framework, err := checker.newFramework()
+	if err != nil {
+		return nil, err
+	}
+	checker.frameworks <- framework
+	return checker, nil
+}
+
+func (p *SchedulerBasedPredicateChecker) newFramework() (*schedulerFramework, error) {
 	sharedLister := NewDelegatingSchedulerSharedLister()
+	registry, err := pluginRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create scheduler plugin registry; %v", err)
+	}
 
 	framework, err := schedulerframeworkruntime.NewFramework(
 		context.TODO(),
-		scheduler_plugins.NewInTreeRegistry(),
-		&schedConfig.Profiles[0],
-		schedulerframeworkruntime.WithInformerFactory(informerFactory),
+		registry,
+		p.profile,
+		schedulerframeworkruntime.WithInformerFactory(p.informerFactory),
 		schedulerframeworkruntime.WithSnapshotSharedLister(sharedLister),
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("couldn't create scheduler framework; %v", err)
 	}
-
-	checker := &SchedulerBasedPredicateChecker{
+	return &schedulerFramework{
 		framework:              framework,
 		delegatingSharedLister: sharedLister,
+	}, nil
+}
+
+// acquireFramework returns an idle scheduler framework delegating to the snapshot, or a new one if all of them are
+// busy running concurrent checks.
+func (p *SchedulerBasedPredicateChecker) acquireFramework(clusterSnapshot clustersnapshot.ClusterSnapshot) (*schedulerFramework, error) {
+	var framework *schedulerFramework
+	select {
+	case framework = <-p.frameworks:
+	default:
+		var err error
+		if framework, err = p.newFramework(); err != nil {
+			return nil, err
+		}
 	}
+	framework.delegatingSharedLister.UpdateDelegate(clusterSnapshot)
+	return framework, nil
+}
 
-	return checker, nil
+// releaseFramework makes the scheduler framework idle again. Frameworks exceeding maxIdleFrameworks are dropped.
+func (p *SchedulerBasedPredicateChecker) releaseFramework(framework *schedulerFramework) {
+	framework.delegatingSharedLister.ResetDelegate()
+	select {
+	case p.frameworks <- framework:
+	default:
+	}
 }
 
 // FitsAnyNode checks if the given pod can be placed on any of the given nodes.
@@ -87,6 +172,9 @@ func (p *SchedulerBasedPredicateChecker) FitsAnyNode(clusterSnapshot clustersnap
 
 // FitsAnyNodeMatching checks if the given pod can be placed on any of the given nodes matching the provided function.
 func (p *SchedulerBasedPredicateChecker) FitsAnyNodeMatching(clusterSnapshot clustersnapshot.ClusterSnapshot, pod *apiv1.Pod, nodeMatches func(*schedulerframework.NodeInfo) bool) (string, error) {
+	if checker, found := p.schedulerCheckers[pod.Spec.SchedulerName]; found {
+		return checker.FitsAnyNodeMatching(clusterSnapshot, pod, nodeMatches)
+	}
 	if clusterSnapshot == nil {
 		return "", fmt.Errorf("ClusterSnapshot not provided")
 	}
@@ -101,17 +189,20 @@ func (p *SchedulerBasedPredicateChecker) FitsAnyNodeMatching(clusterSnapshot clu
 		return "", fmt.Errorf("error obtaining nodeInfos from schedulerLister")
 	}
 
-	p.delegatingSharedLister.UpdateDelegate(clusterSnapshot)
-	defer p.delegatingSharedLister.ResetDelegate()
+	f, err := p.acquireFramework(clusterSnapshot)
+	if err != nil {
+		return "", err
+	}
+	defer p.releaseFramework(f)
 
 	state := schedulerframework.NewCycleState()
-	preFilterResult, preFilterStatus := p.framework.RunPreFilterPlugins(context.TODO(), state, pod)
+	preFilterResult, preFilterStatus := f.framework.RunPreFilterPlugins(context.TODO(), state, pod)
 	if !preFilterStatus.IsSuccess() {
 		return "", fmt.Errorf("error running pre filter plugins for pod %s; %s", pod.Name, preFilterStatus.Message())
 	}
 
 	for i := range nodeInfosList {
-		nodeInfo := nodeInfosList[(p.lastIndex+i)%len(nodeInfosList)]
+		nodeInfo := nodeInfosList[(f.lastIndex+i)%len(nodeInfosList)]
 		if !nodeMatches(nodeInfo) {
 			continue
 		}
@@ -125,9 +216,9 @@ func (p *SchedulerBasedPredicateChecker) FitsAnyNodeMatching(clusterSnapshot clu
 			continue
 		}
 
-		filterStatus := p.framework.RunFilterPlugins(context.TODO(), state, pod, nodeInfo)
+		filterStatus := f.framework.RunFilterPlugins(context.TODO(), state, pod, nodeInfo)
 		if filterStatus.IsSuccess() {
-			p.lastIndex = (p.lastIndex + i + 1) % len(nodeInfosList)
+			f.lastIndex = (f.lastIndex + i + 1) % len(nodeInfosList)
 			return nodeInfo.Node().Name, nil
 		}
 	}
@@ -136,6 +227,9 @@ func (p *SchedulerBasedPredicateChecker) FitsAnyNodeMatching(clusterSnapshot clu
 
 // CheckPredicates checks if the given pod can be placed on the given node.
 func (p *SchedulerBasedPredicateChecker) CheckPredicates(clusterSnapshot clustersnapshot.ClusterSnapshot, pod *apiv1.Pod, nodeName string) *PredicateError {
+	if checker, found := p.schedulerCheckers[pod.Spec.SchedulerName]; found {
+		return checker.CheckPredicates(clusterSnapshot, pod, nodeName)
+	}
 	if clusterSnapshot == nil {
 		return NewPredicateError(InternalPredicateError, "", "ClusterSnapshot not provided", nil, emptyString)
 	}
@@ -145,11 +239,14 @@ func (p *SchedulerBasedPredicateChecker) CheckPredicates(clusterSnapshot cluster
 		return NewPredicateError(InternalPredicateError, "", errorMessage, nil, emptyString)
 	}
 
-	p.delegatingSharedLister.UpdateDelegate(clusterSnapshot)
-	defer p.delegatingSharedLister.ResetDelegate()
+	f, err := p.acquireFramework(clusterSnapshot)
+	if err != nil {
+		return NewPredicateError(InternalPredicateError, "", err.Error(), nil, emptyString)
+	}
+	defer p.releaseFramework(f)
 
 	state := schedulerframework.NewCycleState()
-	_, preFilterStatus := p.framework.RunPreFilterPlugins(context.TODO(), state, pod)
+	_, preFilterStatus := f.framework.RunPreFilterPlugins(context.TODO(), state, pod)
 	if !preFilterStatus.IsSuccess() {
 		return NewPredicateError(
 			InternalPredicateError,
@@ -159,7 +256,7 @@ func (p *SchedulerBasedPredicateChecker) CheckPredicates(clusterSnapshot cluster
 			emptyString)
 	}
 
-	filterStatus := p.framework.RunFilterPlugins(context.TODO(), state, pod, nodeInfo)
+	filterStatus := f.framework.RunFilterPlugins(context.TODO(), state, pod, nodeInfo)
 
 	if !filterStatus.IsSuccess() {
 		filterName := filterStatus.Plugin()
@@ -17,8 +17,10 @@ limitations under the License.
 package predicatechecker
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,6 +32,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestCheckPredicate(t *testing.T) {
@@ -263,6 +267,90 @@ func TestFitsAnyNode(t *testing.T) {
 
 }
 
+func TestSchedulerProfiles(t *testing.T) {
+	n1000 := BuildTestNode("n1000", 1000, 2000000)
+	SetNodeReadyState(n1000, true, time.Time{})
+	p8000 := func(schedulerName string) *apiv1.Pod {
+		return BuildTestPod("p8000", 8000, 0, AddSchedulerName(schedulerName))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "scheduler-configs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	configFile := filepath.Join(tmpDir, "two_profiles_config.yaml")
+	if err := os.WriteFile(configFile,
+		[]byte(testconfig.SchedulerConfigTwoProfiles),
+		os.FileMode(0600)); err != nil {
+		t.Fatal(err)
+	}
+	schedConfig, err := scheduler.ConfigFromPath(configFile)
+	assert.NoError(t, err)
+
+	predicateChecker, err := NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig,
+		map[string]string{"my-scheduler": "custom-scheduler", "other-scheduler": ""})
+	assert.NoError(t, err)
+	clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
+	assert.NoError(t, clusterSnapshot.AddNode(n1000))
+
+	tests := []struct {
+		schedulerName string
+		expectError   bool
+	}{
+		{schedulerName: "", expectError: true},
+		{schedulerName: "default-scheduler", expectError: true},
+		{schedulerName: "custom-scheduler", expectError: false},
+		{schedulerName: "my-scheduler", expectError: false},
+		{schedulerName: "other-scheduler", expectError: true},
+		{schedulerName: "unknown-scheduler", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedulerName, func(t *testing.T) {
+			predicateError := predicateChecker.CheckPredicates(clusterSnapshot, p8000(tt.schedulerName), "n1000")
+			assert.Equal(t, tt.expectError, predicateError != nil)
+			_, err := predicateChecker.FitsAnyNode(clusterSnapshot, p8000(tt.schedulerName))
+			assert.Equal(t, tt.expectError, err != nil)
+		})
+	}
+
+	_, err = NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig,
+		map[string]string{"my-scheduler": "missing-profile"})
+	assert.Error(t, err)
+}
+
+func TestFitsAnyNodeConcurrently(t *testing.T) {
+	p900 := BuildTestPod("p900", 900, 1000)
+	predicateChecker, err := NewTestPredicateChecker()
+	assert.NoError(t, err)
+
+	// Each snapshot has room for a single pod, on a node of its own.
+	const snapshotCount = 8
+	snapshots := make([]clustersnapshot.ClusterSnapshot, snapshotCount)
+	for i := range snapshots {
+		snapshots[i] = clustersnapshot.NewBasicClusterSnapshot()
+		err := snapshots[i].AddNode(BuildTestNode(fmt.Sprintf("n%d", i), 1000, 2000000))
+		assert.NoError(t, err)
+	}
+
+	nodeNames := make([]string, snapshotCount)
+	errs := make([]error, snapshotCount)
+	var wg sync.WaitGroup
+	for i := range snapshots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodeNames[i], errs[i] = predicateChecker.FitsAnyNode(snapshots[i], p900)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range snapshots {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, fmt.Sprintf("n%d", i), nodeNames[i])
+	}
+}
+
 func TestDebugInfo(t *testing.T) {
 	p1 := BuildTestPod("p1", 0, 0)
 	node1 := BuildTestNode("n1", 1000, 2000000)
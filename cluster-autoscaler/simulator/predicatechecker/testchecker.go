@@ -31,14 +31,14 @@ func NewTestPredicateChecker() (PredicateChecker, error) {
 	}
 
 	// just call out to NewSchedulerBasedPredicateChecker but use fake kubeClient
-	return NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig)
+	return NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig, nil)
 }
 
 // NewTestPredicateCheckerWithCustomConfig builds test version of PredicateChecker with custom scheduler config.
 func NewTestPredicateCheckerWithCustomConfig(schedConfig *config.KubeSchedulerConfiguration) (PredicateChecker, error) {
 	if schedConfig != nil {
 		// just call out to NewSchedulerBasedPredicateChecker but use fake kubeClient
-		return NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig)
+		return NewSchedulerBasedPredicateChecker(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0), schedConfig, nil)
 	}
 
 	return NewTestPredicateChecker()
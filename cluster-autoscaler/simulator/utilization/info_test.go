@@ -23,13 +23,73 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"k8s.io/kubernetes/pkg/features"
 	"k8s.io/kubernetes/pkg/kubelet/types"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestCalculateWithInPlacePodResize(t *testing.T) {
+	testTime := time.Date(2020, time.December, 18, 17, 0, 0, 0, time.UTC)
+	node := BuildTestNode("node1", 2000, 2000000)
+	SetNodeReadyState(node, true, time.Time{})
+	resizedPod := func(allocatedCpu int64, resize apiv1.PodResizeStatus) *apiv1.Pod {
+		pod := BuildTestPod("p1", 100, 200000)
+		pod.Status.Resize = resize
+		pod.Status.ContainerStatuses = []apiv1.ContainerStatus{{
+			Name: pod.Spec.Containers[0].Name,
+			AllocatedResources: apiv1.ResourceList{
+				apiv1.ResourceCPU:    *resource.NewMilliQuantity(allocatedCpu, resource.DecimalSI),
+				apiv1.ResourceMemory: *resource.NewQuantity(200000, resource.DecimalSI),
+			},
+		}}
+		return pod
+	}
+
+	testCases := []struct {
+		name        string
+		featureGate bool
+		pod         *apiv1.Pod
+		wantCpuUtil float64
+	}{
+		{
+			name:        "feature gate disabled",
+			pod:         resizedPod(500, apiv1.PodResizeStatusInProgress),
+			wantCpuUtil: 100.0 / 2000,
+		},
+		{
+			name:        "shrinking",
+			featureGate: true,
+			pod:         resizedPod(500, apiv1.PodResizeStatusInProgress),
+			wantCpuUtil: 500.0 / 2000,
+		},
+		{
+			name:        "growing",
+			featureGate: true,
+			pod:         resizedPod(50, apiv1.PodResizeStatusInProgress),
+			wantCpuUtil: 100.0 / 2000,
+		},
+		{
+			name:        "infeasible",
+			featureGate: true,
+			pod:         resizedPod(50, apiv1.PodResizeStatusInfeasible),
+			wantCpuUtil: 50.0 / 2000,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.InPlacePodVerticalScaling, tc.featureGate)
+			utilInfo, err := Calculate(newNodeInfo(node, tc.pod), false, false, nil, testTime)
+			assert.NoError(t, err)
+			assert.InEpsilon(t, tc.wantCpuUtil, utilInfo.CpuUtil, 0.01)
+		})
+	}
+}
+
 func TestCalculate(t *testing.T) {
 	testTime := time.Date(2020, time.December, 18, 17, 0, 0, 0, time.UTC)
 	pod := BuildTestPod("p1", 100, 200000)
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	basetracing "k8s.io/component-base/tracing"
+	tracingapi "k8s.io/component-base/tracing/api/v1"
+)
+
+const (
+	// serviceName is the name spans are exported with.
+	serviceName = "cluster-autoscaler"
+	// instrumentationScope is the name of the tracer spans are created with.
+	instrumentationScope = "k8s.io/autoscaler/cluster-autoscaler"
+)
+
+var (
+	loopLock sync.Mutex
+	// loopContext holds the span of the current iteration of the autoscaling loop. Spans started
+	// outside of an iteration, e.g. by node deletions running in the background, have no parent.
+	loopContext = context.Background()
+)
+
+// TracerProvider is a provider of OpenTelemetry tracers, which must be shut down to flush
+// the spans that have not been exported yet.
+type TracerProvider = basetracing.TracerProvider
+
+// Setup installs a TracerProvider exporting spans via OTLP gRPC to the given endpoint as the
+// global tracer provider. Until it is called, spans are not recorded. samplingRatePerMillion
+// is the number of loop iterations out of a million that are traced.
+func Setup(endpoint string, samplingRatePerMillion int32) (TracerProvider, error) {
+	tp, err := basetracing.NewProvider(context.Background(), &tracingapi.TracingConfiguration{
+		Endpoint:               &endpoint,
+		SamplingRatePerMillion: &samplingRatePerMillion,
+	}, nil, []resource.Option{resource.WithAttributes(semconv.ServiceNameKey.String(serviceName))})
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(tp)
+	return tp, nil
+}
+
+// Span is a span of the autoscaling loop.
+type Span struct {
+	span trace.Span
+}
+
+// StartLoop starts the root span of an iteration of the autoscaling loop. The spans started
+// until it ends are its children.
+func StartLoop(loopStart time.Time) *Span {
+	ctx, span := otel.Tracer(instrumentationScope).Start(context.Background(), "RunOnce", trace.WithTimestamp(loopStart))
+	loopLock.Lock()
+	defer loopLock.Unlock()
+	loopContext = ctx
+	return &Span{span: span}
+}
+
+// EndLoop ends the root span of an iteration of the autoscaling loop.
+func (s *Span) EndLoop(err error) {
+	loopLock.Lock()
+	loopContext = context.Background()
+	loopLock.Unlock()
+	s.End(err)
+}
+
+// Start starts a span of an operation of the current iteration of the autoscaling loop.
+func Start(name string, attributes ...attribute.KeyValue) *Span {
+	loopLock.Lock()
+	ctx := loopContext
+	loopLock.Unlock()
+	_, span := otel.Tracer(instrumentationScope).Start(ctx, name, trace.WithAttributes(attributes...))
+	return &Span{span: span}
+}
+
+// End ends the span, recording the error of the operation if there is one.
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// NodeGroup returns the attribute identifying the node group an operation is performed on.
+func NodeGroup(id string) attribute.KeyValue {
+	return attribute.String("node_group", id)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	loopStart := time.Now().Add(-time.Second)
+	loop := StartLoop(loopStart)
+	Start("ScaleUp").End(nil)
+	Start("NodeGroup.IncreaseSize", NodeGroup("ng1")).End(fmt.Errorf("quota exceeded"))
+	loop.EndLoop(nil)
+	Start("NodeGroup.DeleteNodes").End(nil)
+
+	spans := recorder.Ended()
+	assert.Equal(t, 4, len(spans))
+	scaleUp, increaseSize, runOnce, deleteNodes := spans[0], spans[1], spans[2], spans[3]
+
+	assert.Equal(t, "RunOnce", runOnce.Name())
+	assert.Equal(t, loopStart.UnixNano(), runOnce.StartTime().UnixNano())
+	assert.False(t, runOnce.Parent().IsValid())
+	assert.Equal(t, codes.Unset, runOnce.Status().Code)
+
+	assert.Equal(t, "ScaleUp", scaleUp.Name())
+	assert.Equal(t, runOnce.SpanContext().SpanID(), scaleUp.Parent().SpanID())
+
+	assert.Equal(t, "NodeGroup.IncreaseSize", increaseSize.Name())
+	assert.Equal(t, runOnce.SpanContext().SpanID(), increaseSize.Parent().SpanID())
+	assert.Equal(t, codes.Error, increaseSize.Status().Code)
+	assert.Equal(t, "quota exceeded", increaseSize.Status().Description)
+	assert.Contains(t, increaseSize.Attributes(), NodeGroup("ng1"))
+
+	assert.Equal(t, "NodeGroup.DeleteNodes", deleteNodes.Name())
+	assert.False(t, deleteNodes.Parent().IsValid())
+}
@@ -40,3 +40,20 @@ type Backoff interface {
 	// RemoveStaleBackoffData removes stale backoff data.
 	RemoveStaleBackoffData(currentTime time.Time)
 }
+
+// Checkpoint is the backoff state of a node group, which can be saved and restored.
+type Checkpoint struct {
+	Duration            time.Duration                   `json:"duration"`
+	BackoffUntil        time.Time                       `json:"backoffUntil"`
+	LastFailedExecution time.Time                       `json:"lastFailedExecution"`
+	ErrorInfo           cloudprovider.InstanceErrorInfo `json:"errorInfo"`
+}
+
+// Checkpointer is implemented by backoffs whose state can be saved and restored, e.g. by a new leader.
+type Checkpointer interface {
+	// Checkpoint returns the backoff state of node groups by their keys.
+	Checkpoint() map[string]Checkpoint
+	// Restore restores the backoff state of node groups returned by Checkpoint. The current state of
+	// node groups is kept.
+	Restore(checkpoints map[string]Checkpoint)
+}
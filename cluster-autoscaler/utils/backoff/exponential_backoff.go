@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -31,6 +32,8 @@ type exponentialBackoff struct {
 	backoffResetTimeout    time.Duration
 	backoffInfo            map[string]exponentialBackoffInfo
 	nodeGroupKey           func(nodeGroup cloudprovider.NodeGroup) string
+	// policies override the initial and max backoff duration by error code or class.
+	policies map[string]config.NodeGroupBackoffPolicy
 }
 
 type exponentialBackoffInfo struct {
@@ -46,10 +49,23 @@ func NewExponentialBackoff(
 	maxBackoffDuration time.Duration,
 	backoffResetTimeout time.Duration,
 	nodeGroupKey func(nodeGroup cloudprovider.NodeGroup) string) Backoff {
+	return NewExponentialBackoffWithPolicies(initialBackoffDuration, maxBackoffDuration, backoffResetTimeout, nil, nodeGroupKey)
+}
+
+// NewExponentialBackoffWithPolicies creates an instance of exponential backoff with the initial and max backoff
+// duration overridden by policies for some error codes or classes. The policy of the error code is used if
+// there is one, then the one of the error class, e.g. OutOfResource.
+func NewExponentialBackoffWithPolicies(
+	initialBackoffDuration time.Duration,
+	maxBackoffDuration time.Duration,
+	backoffResetTimeout time.Duration,
+	policies map[string]config.NodeGroupBackoffPolicy,
+	nodeGroupKey func(nodeGroup cloudprovider.NodeGroup) string) Backoff {
 	return &exponentialBackoff{
 		maxBackoffDuration:     maxBackoffDuration,
 		initialBackoffDuration: initialBackoffDuration,
 		backoffResetTimeout:    backoffResetTimeout,
+		policies:               policies,
 		backoffInfo:            make(map[string]exponentialBackoffInfo),
 		nodeGroupKey:           nodeGroupKey,
 	}
@@ -66,9 +82,23 @@ func NewIdBasedExponentialBackoff(initialBackoffDuration time.Duration, maxBacko
 		})
 }
 
+// NewIdBasedExponentialBackoffWithPolicies creates an instance of exponential backoff with node group Id used as a key
+// and the initial and max backoff duration overridden by policies for some error codes or classes.
+func NewIdBasedExponentialBackoffWithPolicies(initialBackoffDuration time.Duration, maxBackoffDuration time.Duration, backoffResetTimeout time.Duration, policies map[string]config.NodeGroupBackoffPolicy) Backoff {
+	return NewExponentialBackoffWithPolicies(
+		initialBackoffDuration,
+		maxBackoffDuration,
+		backoffResetTimeout,
+		policies,
+		func(nodeGroup cloudprovider.NodeGroup) string {
+			return nodeGroup.Id()
+		})
+}
+
 // Backoff execution for the given node group. Returns time till execution is backed off.
 func (b *exponentialBackoff) Backoff(nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, errorInfo cloudprovider.InstanceErrorInfo, currentTime time.Time) time.Time {
-	duration := b.initialBackoffDuration
+	initialBackoffDuration, maxBackoffDuration := b.backoffDurations(errorInfo)
+	duration := initialBackoffDuration
 	key := b.nodeGroupKey(nodeGroup)
 	if backoffInfo, found := b.backoffInfo[key]; found {
 		// Multiple concurrent scale-ups failing shouldn't cause
@@ -78,9 +108,11 @@ func (b *exponentialBackoff) Backoff(nodeGroup cloudprovider.NodeGroup, nodeInfo
 			// NodeGroup is not currently in backoff, but was recently
 			// Increase backoff duration exponentially
 			duration = 2 * backoffInfo.duration
-			if duration > b.maxBackoffDuration {
-				duration = b.maxBackoffDuration
-			}
+		}
+		// The previous failure may have had another error with another policy.
+		duration = max(duration, initialBackoffDuration)
+		if duration > maxBackoffDuration {
+			duration = maxBackoffDuration
 		}
 	}
 	backoffUntil := currentTime.Add(duration)
@@ -93,6 +125,16 @@ func (b *exponentialBackoff) Backoff(nodeGroup cloudprovider.NodeGroup, nodeInfo
 	return backoffUntil
 }
 
+// backoffDurations returns the initial and max backoff duration after the error.
+func (b *exponentialBackoff) backoffDurations(errorInfo cloudprovider.InstanceErrorInfo) (time.Duration, time.Duration) {
+	for _, key := range []string{errorInfo.ErrorCode, errorInfo.ErrorClass.String()} {
+		if policy, found := b.policies[key]; found {
+			return policy.InitialBackoffDuration, policy.MaxBackoffDuration
+		}
+	}
+	return b.initialBackoffDuration, b.maxBackoffDuration
+}
+
 // BackoffStatus returns whether the execution is backed off for the given node group and error info when the node group is backed off.
 func (b *exponentialBackoff) BackoffStatus(nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, currentTime time.Time) Status {
 	backoffInfo, found := b.backoffInfo[b.nodeGroupKey(nodeGroup)]
@@ -118,3 +160,33 @@ func (b *exponentialBackoff) RemoveStaleBackoffData(currentTime time.Time) {
 		}
 	}
 }
+
+// Checkpoint returns the backoff state of node groups by their keys.
+func (b *exponentialBackoff) Checkpoint() map[string]Checkpoint {
+	checkpoints := make(map[string]Checkpoint, len(b.backoffInfo))
+	for key, backoffInfo := range b.backoffInfo {
+		checkpoints[key] = Checkpoint{
+			Duration:            backoffInfo.duration,
+			BackoffUntil:        backoffInfo.backoffUntil,
+			LastFailedExecution: backoffInfo.lastFailedExecution,
+			ErrorInfo:           backoffInfo.errorInfo,
+		}
+	}
+	return checkpoints
+}
+
+// Restore restores the backoff state of node groups returned by Checkpoint. The current state of
+// node groups is kept.
+func (b *exponentialBackoff) Restore(checkpoints map[string]Checkpoint) {
+	for key, checkpoint := range checkpoints {
+		if _, found := b.backoffInfo[key]; found {
+			continue
+		}
+		b.backoffInfo[key] = exponentialBackoffInfo{
+			duration:            checkpoint.Duration,
+			backoffUntil:        checkpoint.BackoffUntil,
+			lastFailedExecution: checkpoint.LastFailedExecution,
+			errorInfo:           checkpoint.ErrorInfo,
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -116,3 +117,57 @@ func TestIncreaseExistingBackoff(t *testing.T) {
 	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup1, nil, currentTime))
 	// Result: existing backoff duration was scaled up beyond initial duration
 }
+
+func TestBackoffCheckpointRestore(t *testing.T) {
+	startTime := time.Now()
+	oldBackoff := NewIdBasedExponentialBackoff(10*time.Minute, time.Hour, 3*time.Hour)
+	oldBackoff.Backoff(nodeGroup1, nil, quotaError, startTime)
+	oldBackoff.Backoff(nodeGroup1, nil, quotaError, startTime.Add(11*time.Minute))
+	oldBackoff.Backoff(nodeGroup2, nil, quotaError, startTime)
+	checkpoints := oldBackoff.(Checkpointer).Checkpoint()
+	assert.Len(t, checkpoints, 2)
+
+	newBackoff := NewIdBasedExponentialBackoff(10*time.Minute, time.Hour, 3*time.Hour)
+	newBackoff.Backoff(nodeGroup2, nil, ipSpaceExhaustedError, startTime.Add(time.Minute))
+	newBackoff.(Checkpointer).Restore(checkpoints)
+
+	// The backoff of nodeGroup1 was doubled before the checkpoint.
+	assert.Equal(t, backoffWithQuotaError, newBackoff.BackoffStatus(nodeGroup1, nil, startTime.Add(30*time.Minute)))
+	assert.Equal(t, noBackOff, newBackoff.BackoffStatus(nodeGroup1, nil, startTime.Add(31*time.Minute+time.Millisecond)))
+	// The current backoff of nodeGroup2 is kept.
+	assert.Equal(t, backoffWithIpSpaceExhaustedError, newBackoff.BackoffStatus(nodeGroup2, nil, startTime.Add(2*time.Minute)))
+	assert.Equal(t, checkpoints[nodeGroup1.Id()], newBackoff.(Checkpointer).Checkpoint()[nodeGroup1.Id()])
+}
+
+func TestBackoffPolicies(t *testing.T) {
+	backoff := NewIdBasedExponentialBackoffWithPolicies(10*time.Minute, time.Hour, 3*time.Hour, map[string]config.NodeGroupBackoffPolicy{
+		"QUOTA_EXCEEDED": {InitialBackoffDuration: time.Hour, MaxBackoffDuration: 3 * time.Hour},
+		"Other":          {InitialBackoffDuration: time.Minute, MaxBackoffDuration: 2 * time.Minute},
+	})
+	startTime := time.Now()
+
+	// The policy of the error code is used.
+	backoff.Backoff(nodeGroup1, nil, quotaError, startTime)
+	assert.Equal(t, backoffWithQuotaError, backoff.BackoffStatus(nodeGroup1, nil, startTime.Add(time.Hour)))
+	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup1, nil, startTime.Add(time.Hour+time.Millisecond)))
+
+	// The policy of the error class is used if there's none for the error code.
+	backoff.Backoff(nodeGroup2, nil, ipSpaceExhaustedError, startTime)
+	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup2, nil, startTime.Add(time.Minute+time.Millisecond)))
+	backoff.Backoff(nodeGroup2, nil, ipSpaceExhaustedError, startTime.Add(2*time.Minute))
+	backoff.Backoff(nodeGroup2, nil, ipSpaceExhaustedError, startTime.Add(5*time.Minute))
+	assert.Equal(t, backoffWithIpSpaceExhaustedError, backoff.BackoffStatus(nodeGroup2, nil, startTime.Add(7*time.Minute)))
+	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup2, nil, startTime.Add(7*time.Minute+time.Millisecond)))
+
+	// The backoff duration is raised to the initial duration of the policy of a new error.
+	backoff.Backoff(nodeGroup2, nil, quotaError, startTime.Add(8*time.Minute))
+	assert.Equal(t, backoffWithQuotaError, backoff.BackoffStatus(nodeGroup2, nil, startTime.Add(68*time.Minute)))
+	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup2, nil, startTime.Add(68*time.Minute+time.Millisecond)))
+
+	// The default durations are used for errors without a policy.
+	otherError := cloudprovider.InstanceErrorInfo{ErrorClass: cloudprovider.OutOfResourcesErrorClass, ErrorCode: "STOCKOUT"}
+	nodeGroup3 := nodeGroup("id3")
+	backoff.Backoff(nodeGroup3, nil, otherError, startTime)
+	assert.True(t, backoff.BackoffStatus(nodeGroup3, nil, startTime.Add(10*time.Minute)).IsBackedOff)
+	assert.False(t, backoff.BackoffStatus(nodeGroup3, nil, startTime.Add(10*time.Minute+time.Millisecond)).IsBackedOff)
+}
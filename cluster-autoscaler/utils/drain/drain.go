@@ -22,6 +22,7 @@ import (
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,6 +37,9 @@ const (
 	PodSafeToEvictKey = "cluster-autoscaler.kubernetes.io/safe-to-evict"
 	// SafeToEvictLocalVolumesKey - annotation that ignores (doesn't block on) a local storage volume during node scale down
 	SafeToEvictLocalVolumesKey = "cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes"
+	// PodDrainTimeoutKey - annotation that overrides the max graceful termination time of a pod during node scale down,
+	// e.g. to let it drain connections or checkpoint for longer.
+	PodDrainTimeoutKey = "cluster-autoscaler.kubernetes.io/drain-timeout"
 )
 
 // BlockingPod represents a pod which is blocking the scale down of a node.
@@ -67,6 +71,8 @@ const (
 	NotEnoughPdb
 	// UnexpectedError - pod is blocking scale down because of an unexpected error.
 	UnexpectedError
+	// BlockedByWebhook - pod is blocking scale down because the drainability webhook blocked it.
+	BlockedByWebhook
 )
 
 func (e BlockingPodReason) String() string {
@@ -89,6 +95,8 @@ func (e BlockingPodReason) String() string {
 		return "NotEnoughPdb"
 	case UnexpectedError:
 		return "UnexpectedError"
+	case BlockedByWebhook:
+		return "BlockedByWebhook"
 	default:
 		return fmt.Sprintf("unrecognized reason: %d", int(e))
 	}
@@ -113,6 +121,21 @@ func IsPodTerminal(pod *apiv1.Pod) bool {
 	return pod.Status.Phase == apiv1.PodFailed
 }
 
+// IsUnhealthyPodEvictionAllowed checks whether the pod can be evicted regardless of the disruptions allowed
+// by the pdb, because the pod isn't ready and the unhealthy pod eviction policy of the pdb is AlwaysAllow.
+// Evicting such a pod doesn't use the disruptions allowed by the pdb either.
+func IsUnhealthyPodEvictionAllowed(pod *apiv1.Pod, pdb *policyv1.PodDisruptionBudget) bool {
+	if pdb.Spec.UnhealthyPodEvictionPolicy == nil || *pdb.Spec.UnhealthyPodEvictionPolicy != policyv1.AlwaysAllow {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == apiv1.PodReady {
+			return condition.Status != apiv1.ConditionTrue
+		}
+	}
+	return true
+}
+
 // HasBlockingLocalStorage returns true if pod has any local storage
 // without pod annotation `<SafeToEvictLocalVolumeKey>: <volume-name-1>,<volume-name-2>...`
 func HasBlockingLocalStorage(pod *apiv1.Pod) bool {
@@ -152,6 +175,42 @@ func HasNotSafeToEvictAnnotation(pod *apiv1.Pod) bool {
 	return pod.GetAnnotations()[PodSafeToEvictKey] == "false"
 }
 
+// IsSafeToEvict checks if pod has PodSafeToEvictKey annotation set to true or, if the pod
+// isn't annotated, its namespace has. The namespace may be nil.
+func IsSafeToEvict(pod *apiv1.Pod, namespace *apiv1.Namespace) bool {
+	return safeToEvictValue(pod, namespace) == "true"
+}
+
+// IsNotSafeToEvict checks if pod has PodSafeToEvictKey annotation set to false or, if the pod
+// isn't annotated, its namespace has. The namespace may be nil.
+func IsNotSafeToEvict(pod *apiv1.Pod, namespace *apiv1.Namespace) bool {
+	return safeToEvictValue(pod, namespace) == "false"
+}
+
+// PodDrainTimeout returns the max graceful termination time of the pod set with the PodDrainTimeoutKey
+// annotation. Invalid values are ignored.
+func PodDrainTimeout(pod *apiv1.Pod) (time.Duration, bool) {
+	value, found := pod.GetAnnotations()[PodDrainTimeoutKey]
+	if !found {
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, false
+	}
+	return timeout, true
+}
+
+func safeToEvictValue(pod *apiv1.Pod, namespace *apiv1.Namespace) string {
+	if value, found := pod.GetAnnotations()[PodSafeToEvictKey]; found {
+		return value
+	}
+	if namespace != nil {
+		return namespace.GetAnnotations()[PodSafeToEvictKey]
+	}
+	return ""
+}
+
 // IsPodLongTerminating checks if a pod has been terminating for a long time (pod's terminationGracePeriod + an additional const buffer)
 func IsPodLongTerminating(pod *apiv1.Pod, currentTime time.Time) bool {
 	// pod has not even been deleted
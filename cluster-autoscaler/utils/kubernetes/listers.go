@@ -44,6 +44,7 @@ type ListerRegistry interface {
 	JobLister() v1batchlister.JobLister
 	ReplicaSetLister() v1appslister.ReplicaSetLister
 	StatefulSetLister() v1appslister.StatefulSetLister
+	NamespaceLister() v1lister.NamespaceLister
 }
 
 type listerRegistryImpl struct {
@@ -56,6 +57,7 @@ type listerRegistryImpl struct {
 	jobLister                   v1batchlister.JobLister
 	replicaSetLister            v1appslister.ReplicaSetLister
 	statefulSetLister           v1appslister.StatefulSetLister
+	namespaceLister             v1lister.NamespaceLister
 }
 
 // NewListerRegistry returns a registry providing various listers to list pods or nodes matching conditions
@@ -76,6 +78,18 @@ func NewListerRegistry(allNode NodeLister, readyNode NodeLister, allPodLister Po
 	}
 }
 
+// NewListerRegistryWithNamespaceLister returns a registry providing various listers to list pods or nodes matching
+// conditions, as well as namespaces
+func NewListerRegistryWithNamespaceLister(allNode NodeLister, readyNode NodeLister, allPodLister PodLister, podDisruptionBudgetLister PodDisruptionBudgetLister,
+	daemonSetLister v1appslister.DaemonSetLister, replicationControllerLister v1lister.ReplicationControllerLister,
+	jobLister v1batchlister.JobLister, replicaSetLister v1appslister.ReplicaSetLister,
+	statefulSetLister v1appslister.StatefulSetLister, namespaceLister v1lister.NamespaceLister) ListerRegistry {
+	registry := NewListerRegistry(allNode, readyNode, allPodLister, podDisruptionBudgetLister, daemonSetLister,
+		replicationControllerLister, jobLister, replicaSetLister, statefulSetLister).(listerRegistryImpl)
+	registry.namespaceLister = namespaceLister
+	return registry
+}
+
 // NewListerRegistryWithDefaultListers returns a registry filled with listers of the default implementations
 func NewListerRegistryWithDefaultListers(informerFactory informers.SharedInformerFactory) ListerRegistry {
 	allPodLister := NewAllPodLister(informerFactory.Core().V1().Pods().Lister())
@@ -88,9 +102,10 @@ func NewListerRegistryWithDefaultListers(informerFactory informers.SharedInforme
 	jobLister := informerFactory.Batch().V1().Jobs().Lister()
 	replicaSetLister := informerFactory.Apps().V1().ReplicaSets().Lister()
 	statefulSetLister := informerFactory.Apps().V1().StatefulSets().Lister()
-	return NewListerRegistry(allNodeLister, readyNodeLister, allPodLister,
+	namespaceLister := informerFactory.Core().V1().Namespaces().Lister()
+	return NewListerRegistryWithNamespaceLister(allNodeLister, readyNodeLister, allPodLister,
 		podDisruptionBudgetLister, daemonSetLister, replicationControllerLister,
-		jobLister, replicaSetLister, statefulSetLister)
+		jobLister, replicaSetLister, statefulSetLister, namespaceLister)
 }
 
 // AllPodLister returns the AllPodLister registered to this registry
@@ -138,6 +153,11 @@ func (r listerRegistryImpl) StatefulSetLister() v1appslister.StatefulSetLister {
 	return r.statefulSetLister
 }
 
+// NamespaceLister returns the namespaceLister registered to this registry
+func (r listerRegistryImpl) NamespaceLister() v1lister.NamespaceLister {
+	return r.namespaceLister
+}
+
 // PodLister lists all pods.
 // To filter out the scheduled or unschedulable pods the helper methods ScheduledPods and UnschedulablePods should be used.
 type PodLister interface {
@@ -204,6 +224,19 @@ func SchedulerUnprocessedPods(allPods []*apiv1.Pod, bypassedSchedulers map[strin
 	return unprocessedPods
 }
 
+// PodsOfSchedulers is a helper method that returns the pods of the default scheduler and of the given schedulers.
+func PodsOfSchedulers(allPods []*apiv1.Pod, schedulers map[string]string) []*apiv1.Pod {
+	var pods []*apiv1.Pod
+	for _, pod := range allPods {
+		schedulerName := pod.Spec.SchedulerName
+		if _, found := schedulers[schedulerName]; !found && schedulerName != "" && schedulerName != apiv1.DefaultSchedulerName {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
 // UnschedulablePods is a helper method that returns all unschedulable pods from given pod list.
 func UnschedulablePods(allPods []*apiv1.Pod) []*apiv1.Pod {
 	var unschedulablePods []*apiv1.Pod
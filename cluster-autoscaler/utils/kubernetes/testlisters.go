@@ -149,6 +149,18 @@ func NewTestStatefulSetLister(sss []*appsv1.StatefulSet) (v1appslister.StatefulS
 	return v1appslister.NewStatefulSetLister(store), nil
 }
 
+// NewTestNamespaceLister returns a lister that returns provided Namespaces
+func NewTestNamespaceLister(nss []*apiv1.Namespace) (v1lister.NamespaceLister, error) {
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range nss {
+		err := store.Add(ns)
+		if err != nil {
+			return nil, fmt.Errorf("Error adding object to cache: %v", err)
+		}
+	}
+	return v1lister.NewNamespaceLister(store), nil
+}
+
 // NewTestConfigMapLister returns a lister that returns provided ConfigMaps
 func NewTestConfigMapLister(cms []*apiv1.ConfigMap) (v1lister.ConfigMapLister, error) {
 	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
@@ -26,6 +26,9 @@ import (
 const (
 	// DaemonSetPodAnnotationKey - annotation use to informs the cluster-autoscaler controller when a pod needs to be considered as a Daemonset's Pod.
 	DaemonSetPodAnnotationKey = "cluster-autoscaler.kubernetes.io/daemonset-pod"
+	// HeadroomPlaceholderAnnotationKey - annotation marking the virtual pods reserving the headroom of a node group or a capacity buffer, set to the node group id or the capacity buffer name,
+	// and the virtual pods predicted to arrive, set to the namespace and name of the pod they are a copy of.
+	HeadroomPlaceholderAnnotationKey = "cluster-autoscaler.kubernetes.io/headroom-placeholder"
 )
 
 // IsDaemonSetPod returns true if the Pod should be considered as Pod managed by a DaemonSet
@@ -38,6 +41,12 @@ func IsDaemonSetPod(pod *apiv1.Pod) bool {
 	return pod.Annotations[DaemonSetPodAnnotationKey] == "true"
 }
 
+// IsHeadroomPlaceholderPod checks whether the pod is a virtual pod reserving the headroom of a node group or a capacity buffer, or predicted to arrive.
+func IsHeadroomPlaceholderPod(pod *apiv1.Pod) bool {
+	_, found := pod.Annotations[HeadroomPlaceholderAnnotationKey]
+	return found
+}
+
 // IsMirrorPod checks whether the pod is a mirror pod.
 func IsMirrorPod(pod *apiv1.Pod) bool {
 	if pod.ObjectMeta.Annotations == nil {
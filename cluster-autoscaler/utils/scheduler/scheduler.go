@@ -160,3 +160,18 @@ func GetBypassedSchedulersMap(bypassedSchedulers []string) map[string]bool {
 	}
 	return bypassedSchedulersMap
 }
+
+// GetAdditionalSchedulersMap parses the additional scheduler names, each optionally followed by ':' and the scheduler
+// name of the profile its pods are simulated with. It returns a map from scheduler names to profile names, which are
+// empty for schedulers simulated with the default profile.
+func GetAdditionalSchedulersMap(additionalSchedulers []string) (map[string]string, error) {
+	additionalSchedulersMap := make(map[string]string, len(additionalSchedulers))
+	for _, entry := range additionalSchedulers {
+		schedulerName, profileName, _ := strings.Cut(entry, ":")
+		if schedulerName == "" {
+			return nil, fmt.Errorf("invalid additional scheduler %q: empty scheduler name", entry)
+		}
+		additionalSchedulersMap[schedulerName] = profileName
+	}
+	return additionalSchedulersMap, nil
+}
@@ -189,3 +189,12 @@ func TestConfigFromPath(t *testing.T) {
 
 	}
 }
+
+func TestGetAdditionalSchedulersMap(t *testing.T) {
+	got, err := GetAdditionalSchedulersMap([]string{"my-scheduler", "gpu-scheduler:gpu-profile"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"my-scheduler": "", "gpu-scheduler": "gpu-profile"}, got)
+
+	_, err = GetAdditionalSchedulersMap([]string{":gpu-profile"})
+	assert.Error(t, err)
+}
@@ -96,6 +96,10 @@ type TaintConfig struct {
 	startupTaintPrefixes     []string
 	statusTaintPrefixes      []string
 	explicitlyReportedTaints TaintKeySet
+	// templateTaintPrefixes and templateLabelPrefixes are the prefixes of taints and labels that are only
+	// removed from node templates.
+	templateTaintPrefixes []string
+	templateLabelPrefixes []string
 }
 
 // NewTaintConfig returns the taint config extracted from options
@@ -127,6 +131,8 @@ func NewTaintConfig(opts config.AutoscalingOptions) TaintConfig {
 		startupTaintPrefixes:     []string{IgnoreTaintPrefix, StartupTaintPrefix},
 		statusTaintPrefixes:      []string{StatusTaintPrefix},
 		explicitlyReportedTaints: explicitlyReportedTaints,
+		templateTaintPrefixes:    opts.IgnoredTemplateTaintPrefixes,
+		templateLabelPrefixes:    opts.IgnoredTemplateLabelPrefixes,
 	}
 }
 
@@ -146,6 +152,11 @@ func (tc TaintConfig) IsStatusTaint(taint string) bool {
 	return matchesAnyPrefix(tc.statusTaintPrefixes, taint)
 }
 
+// IsIgnoredTemplateLabel checks whether given label should be removed from node templates.
+func (tc TaintConfig) IsIgnoredTemplateLabel(label string) bool {
+	return matchesAnyPrefix(tc.templateLabelPrefixes, label)
+}
+
 func (tc TaintConfig) isExplicitlyReportedTaint(taint string) bool {
 	_, ok := tc.explicitlyReportedTaints[taint]
 	return ok
@@ -418,6 +429,11 @@ func SanitizeTaints(taints []apiv1.Taint, taintConfig TaintConfig) []apiv1.Taint
 			continue
 		}
 
+		if matchesAnyPrefix(taintConfig.templateTaintPrefixes, taint.Key) {
+			klog.V(4).Infof("Removing ignored template taint %s, when creating template from node", taint.Key)
+			continue
+		}
+
 		newTaints = append(newTaints, taint)
 	}
 	return newTaints
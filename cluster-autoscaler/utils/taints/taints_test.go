@@ -607,6 +607,11 @@ func TestSanitizeTaints(t *testing.T) {
 					Value:  "I-am-the-invisible-man-Incredible-how-you-can",
 					Effect: apiv1.TaintEffectNoSchedule,
 				},
+				{
+					Key:    "vendor.example.com/agent-not-ready",
+					Value:  "1",
+					Effect: apiv1.TaintEffectNoSchedule,
+				},
 			},
 		},
 		Status: apiv1.NodeStatus{
@@ -614,9 +619,10 @@ func TestSanitizeTaints(t *testing.T) {
 		},
 	}
 	taintConfig := TaintConfig{
-		startupTaints:        map[string]bool{"ignore-me": true},
-		statusTaints:         map[string]bool{"status-me": true},
-		startupTaintPrefixes: []string{IgnoreTaintPrefix, StartupTaintPrefix},
+		startupTaints:         map[string]bool{"ignore-me": true},
+		statusTaints:          map[string]bool{"status-me": true},
+		startupTaintPrefixes:  []string{IgnoreTaintPrefix, StartupTaintPrefix},
+		templateTaintPrefixes: []string{"vendor.example.com/"},
 	}
 
 	newTaints := SanitizeTaints(node.Spec.Taints, taintConfig)